@@ -12,36 +12,83 @@ import (
 var version = "dev"
 
 func main() {
+	var profile string
 	rootCmd := &cobra.Command{
 		Use:     "hermes",
 		Short:   "Hermes Autonomous Agent",
 		Long:    "AI-powered autonomous application development system",
 		Version: version,
+		PersistentPreRunE: func(c *cobra.Command, args []string) error {
+			if err := cmd.ConfigureProfile(profile); err != nil {
+				return err
+			}
+			// Only commands starting providers or integrations may ask for the passphrase
+			if cmd.UsesSecrets(c) {
+				cmd.ConfigureSecrets()
+			}
+			if err := cmd.ConfigureSandbox(); err != nil {
+				return err
+			}
+			if err := cmd.ConfigureHistory(); err != nil {
+				return err
+			}
+			cmd.ConfigureTaskTracking()
+			cmd.ConfigureLogging()
+			cmd.ConfigureJiraSync()
+			cmd.ConfigureWebhooks()
+			cmd.ConfigureHooks()
+			cmd.ConfigureTracing()
+			cmd.ConfigureAudit()
+			return nil
+		},
 		Run: func(c *cobra.Command, args []string) {
 			fmt.Println("Hermes Autonomous Agent", version)
 			fmt.Println("Use 'hermes --help' for available commands")
 		},
 	}
 
+	rootCmd.PersistentFlags().StringVar(&profile, "profile", "", "Config profile overlaid on the configuration, e.g. ci (default: $HERMES_PROFILE)")
+
 	// Add subcommands
 	rootCmd.AddCommand(cmd.NewRunCmd())
+	rootCmd.AddCommand(cmd.NewResumeCmd())
+	rootCmd.AddCommand(cmd.NewRollbackCmd())
+	rootCmd.AddCommand(cmd.NewSnapshotCmd())
 	rootCmd.AddCommand(cmd.NewPrdCmd())
 	rootCmd.AddCommand(cmd.NewAddCmd())
 	rootCmd.AddCommand(cmd.NewInitCmd())
 	rootCmd.AddCommand(cmd.NewStatusCmd())
+	rootCmd.AddCommand(cmd.NewValidateCmd())
+	rootCmd.AddCommand(cmd.NewConfigCmd())
+	rootCmd.AddCommand(cmd.NewSecretsCmd())
 	rootCmd.AddCommand(cmd.NewTuiCmd())
 	rootCmd.AddCommand(cmd.NewResetCmd())
 	rootCmd.AddCommand(cmd.NewTaskCmd())
 	rootCmd.AddCommand(cmd.NewLogCmd())
 	rootCmd.AddCommand(cmd.NewIdeaCmd())
 	rootCmd.AddCommand(cmd.NewConvertPrdCmd())
+	rootCmd.AddCommand(cmd.NewReportCmd())
+	rootCmd.AddCommand(cmd.NewStatsCmd())
+	rootCmd.AddCommand(cmd.NewChangelogCmd())
+	rootCmd.AddCommand(cmd.NewReplayCmd())
+	rootCmd.AddCommand(cmd.NewExplainCmd())
+	rootCmd.AddCommand(cmd.NewFeatureCmd())
+	rootCmd.AddCommand(cmd.NewWatchCmd())
+	rootCmd.AddCommand(cmd.NewServeCmd())
+	rootCmd.AddCommand(cmd.NewJiraCmd())
+	rootCmd.AddCommand(cmd.NewCleanCmd())
+	rootCmd.AddCommand(cmd.NewAuditCmd())
+	rootCmd.AddCommand(cmd.NewCompletionCmd())
 
 	// Set version for update command
 	cmd.SetUpdateVersion(version)
 	rootCmd.AddCommand(cmd.NewUpdateCmd())
 	rootCmd.AddCommand(cmd.NewInstallCmd())
 
-	if err := rootCmd.Execute(); err != nil {
+	err := rootCmd.Execute()
+	cmd.FlushWebhooks()
+	cmd.ShutdownTracing()
+	if err != nil {
 		os.Exit(1)
 	}
 }
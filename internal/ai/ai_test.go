@@ -1,8 +1,16 @@
 package ai
 
 import (
+	"context"
+	"errors"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"runtime"
+	"strings"
 	"testing"
 
+	"hermes/internal/prompt"
 	"hermes/internal/task"
 )
 
@@ -51,33 +59,71 @@ func TestDefaultRetryConfig(t *testing.T) {
 	}
 }
 
-func TestFormatFiles(t *testing.T) {
-	// Empty files
-	result := formatFiles(nil)
-	if result != "- (none specified)" {
-		t.Errorf("expected '- (none specified)', got %s", result)
+func TestBuildTaskPromptLists(t *testing.T) {
+	executor := NewTaskExecutor(NewClaudeProvider(), t.TempDir())
+
+	// Empty files and criteria
+	prompt, err := executor.buildTaskPrompt(&task.Task{ID: "T001", Name: "Test Task"}, "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(prompt, "**Files to Touch:**\n- (none specified)\n\n**Success Criteria:**\n- (none specified)\n") {
+		t.Errorf("expected '- (none specified)' lists, got %q", prompt)
+	}
+
+	// With files and criteria
+	prompt, err = executor.buildTaskPrompt(&task.Task{
+		ID:              "T001",
+		Name:            "Test Task",
+		FilesToTouch:    []string{"file1.go", "file2.go"},
+		SuccessCriteria: []string{"Criterion 1", "Criterion 2"},
+	}, "")
+	if err != nil {
+		t.Fatal(err)
 	}
+	for _, expected := range []string{"- file1.go\n- file2.go\n", "- Criterion 1\n- Criterion 2\n"} {
+		if !strings.Contains(prompt, expected) {
+			t.Errorf("expected %q in %q", expected, prompt)
+		}
+	}
+}
 
-	// With files
-	result = formatFiles([]string{"file1.go", "file2.go"})
-	expected := "- file1.go\n- file2.go\n"
-	if result != expected {
-		t.Errorf("expected %q, got %q", expected, result)
+func TestBuildTaskPromptProjectContext(t *testing.T) {
+	executor := NewTaskExecutor(NewClaudeProvider(), t.TempDir())
+	executor.SetProjectContext("## Project Context\n\n**Project:** shop (Go Application)\n")
+
+	prompt, err := executor.buildTaskPrompt(&task.Task{ID: "T001", Name: "Test Task"}, "# Instructions")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.HasPrefix(prompt, "## Project Context\n\n**Project:** shop (Go Application)\n\n# Instructions\n") {
+		t.Errorf("expected the project context before the instructions, got %q", prompt)
 	}
 }
 
-func TestFormatCriteria(t *testing.T) {
-	// Empty criteria
-	result := formatCriteria(nil)
-	if result != "- (none specified)" {
-		t.Errorf("expected '- (none specified)', got %s", result)
+func TestBuildTaskPromptMemory(t *testing.T) {
+	dir := t.TempDir()
+	executor := NewTaskExecutor(NewClaudeProvider(), dir)
+	testTask := &task.Task{ID: "T001", Name: "Test Task"}
+
+	out, err := executor.buildTaskPrompt(testTask, "# Instructions")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if strings.Contains(out, prompt.MemoryStart) {
+		t.Error("expected no memory instructions without ai.memory")
 	}
 
-	// With criteria
-	result = formatCriteria([]string{"Criterion 1", "Criterion 2"})
-	expected := "- Criterion 1\n- Criterion 2\n"
-	if result != expected {
-		t.Errorf("expected %q, got %q", expected, result)
+	executor.SetMemory(dir)
+	executor.recordMemory("T001", prompt.MemoryStart+"\n- Run make generate first\n"+prompt.MemoryEnd)
+	out, err = executor.buildTaskPrompt(testTask, "# Instructions")
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, want := range []string{"## Learnings From Earlier Tasks\n\n- Run make generate first (T001)\n\n# Instructions", prompt.MemoryStart} {
+		if !strings.Contains(out, want) {
+			t.Errorf("expected prompt to contain %q:\n%s", want, out)
+		}
 	}
 }
 
@@ -124,3 +170,182 @@ func TestStreamDisplay(t *testing.T) {
 		t.Error("expected showCost = false")
 	}
 }
+
+func TestApplySandbox(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("sandbox is Unix only")
+	}
+	defer SetSandbox(Sandbox{})
+
+	// No sandbox leaves the command untouched
+	cmd := exec.CommandContext(context.Background(), "claude", "--print")
+	applySandbox(cmd)
+	if strings.Join(cmd.Args, " ") != "claude --print" {
+		t.Errorf("expected unchanged command, got %v", cmd.Args)
+	}
+
+	if err := SetSandbox(Sandbox{Mode: SandboxSudo}); err == nil {
+		t.Error("expected error for sudo sandbox without user")
+	}
+	if err := SetSandbox(Sandbox{Mode: "chroot"}); err == nil {
+		t.Error("expected error for unknown sandbox mode")
+	}
+
+	t.Setenv("HERMES_TEST_KEY", "secret")
+	if err := SetSandbox(Sandbox{Mode: SandboxSudo, User: "hermes", PassEnv: []string{"HERMES_TEST_KEY"}}); err != nil {
+		t.Fatal(err)
+	}
+	cmd = exec.CommandContext(context.Background(), "claude", "--print")
+	cmd.Env = append(cmd.Environ(), "CI=true")
+	applySandbox(cmd)
+	got := strings.Join(cmd.Args, " ")
+	want := "sudo -n -u hermes -H -- env CI=true HERMES_TEST_KEY=secret claude --print"
+	if got != want {
+		t.Errorf("expected %q, got %q", want, got)
+	}
+
+	if runtime.GOOS == "linux" {
+		if err := SetSandbox(Sandbox{Mode: SandboxSystemd, Properties: []string{"MemoryMax=2G"}}); err != nil {
+			t.Fatal(err)
+		}
+		cmd = exec.CommandContext(context.Background(), "gemini")
+		cmd.Dir = "/srv/project"
+		applySandbox(cmd)
+		got = strings.Join(cmd.Args, " ")
+		for _, part := range []string{"systemd-run", "--user", "--working-directory=/srv/project", "ReadWritePaths=/srv/project", "MemoryMax=2G", "-- gemini"} {
+			if !strings.Contains(got, part) {
+				t.Errorf("expected %q in %q", part, got)
+			}
+		}
+	}
+
+	if err := SetSandbox(Sandbox{Mode: SandboxContainer}); err == nil {
+		t.Error("expected error for container sandbox without image")
+	}
+
+	// A worktree gets its own container with the main git directory mounted
+	repo := t.TempDir()
+	workDir := filepath.Join(repo, ".hermes", "worktrees", "wt-T001")
+	if err := os.MkdirAll(workDir, 0755); err != nil {
+		t.Fatal(err)
+	}
+	gitDir := filepath.Join(repo, ".git")
+	if err := os.WriteFile(filepath.Join(workDir, ".git"), []byte("gitdir: "+filepath.Join(gitDir, "worktrees", "wt-T001")+"\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if err := SetSandbox(Sandbox{Mode: SandboxContainer, Image: "hermes-worker", RunArgs: []string{"--memory=4g"}, PassEnv: []string{"HERMES_TEST_KEY"}}); err != nil {
+		t.Fatal(err)
+	}
+	cmd = exec.CommandContext(context.Background(), "claude", "--print")
+	cmd.Dir = workDir
+	applySandbox(cmd)
+	got = strings.Join(cmd.Args, " ")
+	want = "docker run --rm -i --init -v " + workDir + ":" + workDir + " -w " + workDir + " -v " + gitDir + ":" + gitDir +
+		" -e HERMES_TEST_KEY --memory=4g hermes-worker claude --print"
+	if got != want {
+		t.Errorf("expected %q, got %q", want, got)
+	}
+}
+
+func TestSandboxEnv(t *testing.T) {
+	defer SetSandbox(Sandbox{})
+
+	if err := SetSandbox(Sandbox{EnvAllow: []string{"["}}); err == nil {
+		t.Error("expected error for invalid environment pattern")
+	}
+
+	t.Setenv("ANTHROPIC_API_KEY", "key")
+	t.Setenv("AWS_SECRET_ACCESS_KEY", "secret")
+	t.Setenv("HERMES_TEST_VAR", "value")
+	if err := SetSandbox(Sandbox{EnvAllow: []string{"ANTHROPIC_*", "AWS_*", "CI"}, EnvStrip: []string{"AWS_SECRET_*"}}); err != nil {
+		t.Fatal(err)
+	}
+	cmd := exec.CommandContext(context.Background(), "claude", "--print")
+	cmd.Env = append(cmd.Environ(), "CI=true")
+	applySandbox(cmd)
+
+	env := strings.Join(cmd.Env, "\n")
+	for _, want := range []string{"ANTHROPIC_API_KEY=key", "CI=true", "PATH="} {
+		if !strings.Contains(env, want) {
+			t.Errorf("expected %q in the provider environment", want)
+		}
+	}
+	for _, unwanted := range []string{"AWS_SECRET_ACCESS_KEY", "HERMES_TEST_VAR"} {
+		if strings.Contains(env, unwanted) {
+			t.Errorf("expected %q to be removed from the provider environment", unwanted)
+		}
+	}
+
+	// Stripping everything must not fall back to the inherited environment
+	if err := SetSandbox(Sandbox{EnvStrip: []string{"*"}}); err != nil {
+		t.Fatal(err)
+	}
+	cmd = exec.CommandContext(context.Background(), "claude", "--print")
+	applySandbox(cmd)
+	if cmd.Env == nil || len(cmd.Env) != 0 {
+		t.Errorf("expected an empty provider environment, got %v", cmd.Env)
+	}
+}
+
+// stubProvider answers every prompt with a fixed output
+type stubProvider struct {
+	output string
+}
+
+func (p *stubProvider) Name() string      { return "stub" }
+func (p *stubProvider) IsAvailable() bool { return true }
+func (p *stubProvider) Execute(ctx context.Context, opts *ExecuteOptions) (*ExecuteResult, error) {
+	return &ExecuteResult{Output: p.output, Model: "stub-1", Cost: 0.5, Success: true}, nil
+}
+func (p *stubProvider) ExecuteStream(ctx context.Context, opts *ExecuteOptions) (<-chan StreamEvent, error) {
+	return nil, nil
+}
+
+func TestOnExecution(t *testing.T) {
+	var executions []*Execution
+	OnExecution(func(e *Execution) {
+		executions = append(executions, e)
+	})
+	defer func() { executionHooks = nil }()
+
+	executor := NewTaskExecutor(&stubProvider{output: "done"}, t.TempDir())
+	if _, err := executor.ExecuteTask(context.Background(), &task.Task{ID: "T007", Name: "Stub"}, "", false); err != nil {
+		t.Fatal(err)
+	}
+
+	// The output lacks a status block, so a second execution asks for it
+	if len(executions) != 2 {
+		t.Fatalf("expected 2 executions, got %d", len(executions))
+	}
+	first := executions[0]
+	if first.TaskID != "T007" || first.Provider != "stub" || !strings.Contains(first.Prompt, "Stub") {
+		t.Errorf("unexpected execution %+v", first)
+	}
+	if first.Result == nil || first.Result.Model != "stub-1" || first.EndTime.Before(first.StartTime) {
+		t.Errorf("unexpected result %+v", first.Result)
+	}
+	if executions[1].TaskID != "T007" || executions[1].Prompt != statusReminderPrompt {
+		t.Errorf("expected the status block request for T007, got %+v", executions[1])
+	}
+}
+
+func TestIsProviderError(t *testing.T) {
+	tests := []struct {
+		name   string
+		result *ExecuteResult
+		err    error
+		want   bool
+	}{
+		{"timeout", nil, context.DeadlineExceeded, true},
+		{"rate limited", &ExecuteResult{Error: "exit status 1: API Error: 429 Too Many Requests"}, nil, true},
+		{"overloaded", nil, errors.New("Overloaded, try again later"), true},
+		{"task failure", &ExecuteResult{Error: "exit status 1", Output: "tests failed"}, nil, false},
+		{"output mentioning rate limit", &ExecuteResult{Error: "exit status 1", Output: "handle 429 Too Many Requests"}, nil, false},
+		{"success mentioning quota", &ExecuteResult{Success: true, Output: "added quota checks"}, nil, false},
+	}
+	for _, tt := range tests {
+		if got := IsProviderError(tt.result, tt.err); got != tt.want {
+			t.Errorf("%s: IsProviderError() = %v, want %v", tt.name, got, tt.want)
+		}
+	}
+}
@@ -52,9 +52,11 @@ type claudeStreamEvent struct {
 			IsError   bool                   `json:"is_error,omitempty"`
 		} `json:"content,omitempty"`
 	} `json:"message,omitempty"`
+	Model      string  `json:"model,omitempty"`
 	CostUSD    float64 `json:"cost_usd,omitempty"`
 	DurationMs int64   `json:"duration_ms,omitempty"`
 	Result     string  `json:"result,omitempty"`
+	IsError    bool    `json:"is_error,omitempty"`
 }
 
 // Execute runs a prompt and returns the result
@@ -69,6 +71,7 @@ func (p *ClaudeProvider) Execute(ctx context.Context, opts *ExecuteOptions) (*Ex
 	if opts.WorkDir != "" {
 		cmd.Dir = opts.WorkDir
 	}
+	applySandbox(cmd)
 
 	// Get stdin pipe to send prompt
 	stdin, err := cmd.StdinPipe()
@@ -109,6 +112,10 @@ func (p *ClaudeProvider) Execute(ctx context.Context, opts *ExecuteOptions) (*Ex
 		}
 
 		switch event.Type {
+		case "system":
+			if event.Model != "" {
+				result.Model = event.Model
+			}
 		case "assistant":
 			for _, content := range event.Message.Content {
 				if content.Type == "text" && content.Text != "" {
@@ -119,6 +126,9 @@ func (p *ClaudeProvider) Execute(ctx context.Context, opts *ExecuteOptions) (*Ex
 			if event.Result != "" {
 				result.Output = event.Result
 			}
+			if event.IsError {
+				result.Error = event.Result
+			}
 			result.Cost = event.CostUSD
 			result.Duration = float64(event.DurationMs) / 1000
 		}
@@ -126,7 +136,11 @@ func (p *ClaudeProvider) Execute(ctx context.Context, opts *ExecuteOptions) (*Ex
 
 	if err := cmd.Wait(); err != nil {
 		result.Success = false
-		result.Error = err.Error()
+		if result.Error != "" {
+			result.Error = err.Error() + ": " + result.Error
+		} else {
+			result.Error = err.Error()
+		}
 	}
 
 	if result.Duration == 0 {
@@ -151,6 +165,7 @@ func (p *ClaudeProvider) ExecuteStream(ctx context.Context, opts *ExecuteOptions
 		if opts.WorkDir != "" {
 			cmd.Dir = opts.WorkDir
 		}
+		applySandbox(cmd)
 
 		stdin, err := cmd.StdinPipe()
 		if err != nil {
@@ -190,9 +205,13 @@ func (p *ClaudeProvider) ExecuteStream(ctx context.Context, opts *ExecuteOptions
 
 			switch cEvent.Type {
 			case "system":
+				model := cEvent.Model
+				if model == "" {
+					model = cEvent.Subtype
+				}
 				events <- StreamEvent{
 					Type:  "system",
-					Model: cEvent.Subtype,
+					Model: model,
 				}
 			case "assistant":
 				for _, content := range cEvent.Message.Content {
@@ -83,6 +83,7 @@ func buildDroidCommand(ctx context.Context, droidArgs []string, workDir string)
 		"FORCE_COLOR=0",
 		"INK_DISABLE_INPUT=1",
 	)
+	applySandbox(cmd)
 
 	return cmd
 }
@@ -141,6 +142,9 @@ func (p *DroidProvider) Execute(ctx context.Context, opts *ExecuteOptions) (*Exe
 			continue
 		}
 
+		if event.Model != "" {
+			result.Model = event.Model
+		}
 		switch event.Type {
 		case "message":
 			if event.Role == "assistant" && event.Text != "" {
@@ -0,0 +1,82 @@
+package ai
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"time"
+
+	"go.opentelemetry.io/otel/attribute"
+	"hermes/internal/tracing"
+)
+
+// Execution is a finished provider execution with its prompt and result
+type Execution struct {
+	Provider  string
+	TaskID    string
+	Prompt    string
+	Result    *ExecuteResult // nil when the provider could not run
+	Err       error
+	StartTime time.Time
+	EndTime   time.Time
+}
+
+// ExecutionHook is called after every provider execution
+type ExecutionHook func(e *Execution)
+
+var (
+	executionHooksMu sync.Mutex
+	executionHooks   []ExecutionHook
+)
+
+// OnExecution registers a hook called after every provider execution
+func OnExecution(hook ExecutionHook) {
+	executionHooksMu.Lock()
+	defer executionHooksMu.Unlock()
+	executionHooks = append(executionHooks, hook)
+}
+
+func notifyExecution(e *Execution) {
+	executionHooksMu.Lock()
+	hooks := append([]ExecutionHook{}, executionHooks...)
+	executionHooksMu.Unlock()
+	for _, hook := range hooks {
+		hook(e)
+	}
+}
+
+// observeExecution runs one provider execution inside a span carrying its
+// provider, duration, cost and token counts, and reports it to the hooks
+func observeExecution(ctx context.Context, provider Provider, opts *ExecuteOptions, execute func(ctx context.Context) (*ExecuteResult, error)) (*ExecuteResult, error) {
+	ctx, span := tracing.Start(ctx, "provider.execute", attribute.String("provider", provider.Name()))
+	start := time.Now()
+	result, err := execute(ctx)
+
+	spanErr := err
+	if result != nil {
+		span.SetAttributes(
+			attribute.Float64("duration_seconds", result.Duration),
+			attribute.Float64("cost_usd", result.Cost),
+			attribute.Int("tokens.input", result.TokensIn),
+			attribute.Int("tokens.output", result.TokensOut),
+		)
+		if result.Model != "" {
+			span.SetAttributes(attribute.String("model", result.Model))
+		}
+		if spanErr == nil && !result.Success {
+			spanErr = errors.New(result.Error)
+		}
+	}
+	tracing.End(span, spanErr)
+
+	notifyExecution(&Execution{
+		Provider:  provider.Name(),
+		TaskID:    opts.TaskID,
+		Prompt:    opts.Prompt,
+		Result:    result,
+		Err:       err,
+		StartTime: start,
+		EndTime:   time.Now(),
+	})
+	return result, err
+}
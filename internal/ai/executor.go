@@ -3,8 +3,11 @@ package ai
 import (
 	"context"
 	"fmt"
+	"io"
+	"os"
 	"strings"
 
+	"hermes/internal/prompt"
 	"hermes/internal/task"
 )
 
@@ -43,10 +46,61 @@ RECOMMENDATION: <describe next steps>
 
 Output ONLY the status block, nothing else.`
 
+// defaultExecuteTemplate is the prompt of a task when the project has no
+// .hermes/prompts/execute.tmpl
+const defaultExecuteTemplate = `{{with .ProjectContext}}{{.}}
+{{end}}{{with .GitHistory}}{{.}}
+{{end}}{{with .Memory}}## Learnings From Earlier Tasks
+
+{{.}}
+
+{{end}}{{.Instructions}}
+
+## Current Task: {{.Task.ID}}
+
+**Task:** {{.Task.ID}}: {{.Task.Name}}
+{{if .Task.Workspace}}
+**Workspace:** {{.Task.Workspace}} (your working directory; keep all changes inside it, files to touch are relative to the repository root)
+{{end}}
+**Files to Touch:**
+{{list .Task.FilesToTouch}}
+
+**Success Criteria:**
+{{list .Task.SuccessCriteria}}
+{{if .RecordMemory}}
+If you learn something later tasks should know, like a command that has to run first or a convention the code follows, record it before the status block, one learning per line:
+
+` + "```" + `
+---HERMES_MEMORY---
+- <learning>
+---END_HERMES_MEMORY---
+` + "```" + `
+{{end}}
+Complete this task and output the HERMES_STATUS block when done:
+
+` + "```" + `
+---HERMES_STATUS---
+STATUS: COMPLETE
+EXIT_SIGNAL: true
+RECOMMENDATION: Move to next task
+---END_HERMES_STATUS---
+` + "```"
+
+// Transcript receives the prompts and AI output of task executions
+type Transcript interface {
+	io.Writer
+	Section(title string)
+}
+
 // TaskExecutor executes tasks using an AI provider
 type TaskExecutor struct {
-	provider Provider
-	workDir  string
+	provider       Provider
+	workDir        string
+	transcript     Transcript
+	projectContext string
+	gitHistory     string
+	warn           func(format string, args ...interface{})
+	memoryDir      string
 }
 
 // NewTaskExecutor creates a new task executor
@@ -57,34 +111,86 @@ func NewTaskExecutor(provider Provider, workDir string) *TaskExecutor {
 	}
 }
 
+// SetTranscript sets where prompts and AI output are written as they happen
+func (e *TaskExecutor) SetTranscript(t Transcript) {
+	e.transcript = t
+}
+
+// SetProjectContext sets the overview of the project prepended to task
+// prompts, see converter.ProjectContext
+func (e *TaskExecutor) SetProjectContext(context string) {
+	e.projectContext = context
+}
+
+// SetGitHistory sets the recent task commits added to task prompts, see
+// git.TaskHistory
+func (e *TaskExecutor) SetGitHistory(history string) {
+	e.gitHistory = history
+}
+
+// SetMemory adds the learnings recorded in .hermes/MEMORY.md of the project
+// at basePath to task prompts and records the ones the AI outputs there
+func (e *TaskExecutor) SetMemory(basePath string) {
+	e.memoryDir = basePath
+}
+
+// SetWarn sets where problems that don't stop a task are reported, like a
+// prompt shortened to fit ai.maxPromptTokens
+func (e *TaskExecutor) SetWarn(warn func(format string, args ...interface{})) {
+	e.warn = warn
+}
+
 // ExecuteTask executes a single task
 func (e *TaskExecutor) ExecuteTask(ctx context.Context, t *task.Task, promptContent string, streamOutput bool) (*ExecuteResult, error) {
-	prompt := e.buildTaskPrompt(t, promptContent)
+	workDir, err := e.taskWorkDir(t)
+	if err != nil {
+		return nil, err
+	}
+	prompt, err := e.buildTaskPrompt(t, promptContent)
+	if err != nil {
+		return nil, err
+	}
 
 	opts := &ExecuteOptions{
 		Prompt:       prompt,
-		WorkDir:      e.workDir,
+		WorkDir:      workDir,
 		Tools:        []string{"Read", "Write", "Edit", "Bash", "Glob", "Grep"},
 		StreamOutput: streamOutput,
+		TaskID:       t.ID,
 	}
-
-	var result *ExecuteResult
-	var err error
-
-	if streamOutput {
-		result, err = e.executeWithStreaming(ctx, opts)
-	} else {
-		result, err = e.provider.Execute(ctx, opts)
+	if e.transcript != nil {
+		e.transcript.Section("Prompt")
+		io.WriteString(e.transcript, prompt+"\n")
+		e.transcript.Section("AI Output (" + e.provider.Name() + ")")
 	}
 
+	result, err := observeExecution(ctx, e.provider, opts, func(ctx context.Context) (*ExecuteResult, error) {
+		if streamOutput {
+			return e.executeWithStreaming(ctx, opts)
+		}
+		return e.provider.Execute(ctx, opts)
+	})
 	if err != nil {
+		if e.transcript != nil {
+			fmt.Fprintf(e.transcript, "\nExecution failed: %v\n", err)
+		}
 		return nil, err
 	}
+	if e.transcript != nil && !streamOutput {
+		io.WriteString(e.transcript, result.Output+"\n")
+	}
+	e.recordMemory(t.ID, result.Output)
 
 	// Check if HERMES_STATUS block is present
 	if !strings.Contains(result.Output, statusBlockMarker) {
 		// Ask AI to provide the status block
-		statusResult, statusErr := e.requestStatusBlock(ctx)
+		if e.transcript != nil {
+			e.transcript.Section("Status Block Request")
+		}
+		statusResult, statusErr := e.requestStatusBlock(ctx, t.ID)
+		if e.transcript != nil && statusErr == nil {
+			io.WriteString(e.transcript, statusResult.Output+"\n")
+		}
 		if statusErr == nil && strings.Contains(statusResult.Output, statusBlockMarker) {
 			// Append status block to original output
 			result.Output = result.Output + "\n\n" + statusResult.Output
@@ -95,14 +201,17 @@ func (e *TaskExecutor) ExecuteTask(ctx context.Context, t *task.Task, promptCont
 }
 
 // requestStatusBlock asks AI to provide the missing status block
-func (e *TaskExecutor) requestStatusBlock(ctx context.Context) (*ExecuteResult, error) {
+func (e *TaskExecutor) requestStatusBlock(ctx context.Context, taskID string) (*ExecuteResult, error) {
 	opts := &ExecuteOptions{
 		Prompt:  statusReminderPrompt,
 		WorkDir: e.workDir,
 		Tools:   []string{}, // No tools needed for status block
+		TaskID:  taskID,
 	}
 
-	return e.provider.Execute(ctx, opts)
+	return observeExecution(ctx, e.provider, opts, func(ctx context.Context) (*ExecuteResult, error) {
+		return e.provider.Execute(ctx, opts)
+	})
 }
 
 // executeWithStreaming executes with real-time output to console
@@ -112,29 +221,45 @@ func (e *TaskExecutor) executeWithStreaming(ctx context.Context, opts *ExecuteOp
 		return nil, err
 	}
 
-	var output string
+	var output, model string
+	var cost, duration float64
 	for event := range events {
 		switch event.Type {
+		case "system":
+			model = event.Model
 		case "text":
 			fmt.Print(event.Text)
 			output += event.Text
+			if e.transcript != nil {
+				io.WriteString(e.transcript, event.Text)
+			}
+		case "result":
+			cost = event.Cost
+			duration = event.Duration
 		case "error":
-			return &ExecuteResult{Success: false, Output: output, Error: event.Text}, nil
+			return &ExecuteResult{Success: false, Output: output, Model: model, Error: event.Text}, nil
 		case "done":
 			fmt.Println()
 		}
 	}
 
-	return &ExecuteResult{Success: true, Output: output}, nil
+	return &ExecuteResult{Success: true, Output: output, Model: model, Cost: cost, Duration: duration}, nil
 }
 
 // ExecuteTaskStream executes a task with streaming output
 func (e *TaskExecutor) ExecuteTaskStream(ctx context.Context, t *task.Task, promptContent string) (<-chan StreamEvent, error) {
-	prompt := e.buildTaskPrompt(t, promptContent)
+	workDir, err := e.taskWorkDir(t)
+	if err != nil {
+		return nil, err
+	}
+	prompt, err := e.buildTaskPrompt(t, promptContent)
+	if err != nil {
+		return nil, err
+	}
 
 	opts := &ExecuteOptions{
 		Prompt:  prompt,
-		WorkDir: e.workDir,
+		WorkDir: workDir,
 		Tools:   []string{"Read", "Write", "Edit", "Bash", "Glob", "Grep"},
 	}
 
@@ -147,59 +272,77 @@ func (e *TaskExecutor) ExecutePrompt(ctx context.Context, prompt string, taskID
 		Prompt:  prompt,
 		WorkDir: e.workDir,
 		Tools:   []string{"Read"}, // Limited tools for merge operations
+		TaskID:  taskID,
 	}
 
-	return e.provider.Execute(ctx, opts)
+	return observeExecution(ctx, e.provider, opts, func(ctx context.Context) (*ExecuteResult, error) {
+		return e.provider.Execute(ctx, opts)
+	})
 }
 
-func (e *TaskExecutor) buildTaskPrompt(t *task.Task, promptContent string) string {
-	return fmt.Sprintf(`%s
-
-## Current Task: %s
-
-**Task:** %s: %s
-
-**Files to Touch:**
-%s
-
-**Success Criteria:**
-%s
-
-Complete this task and output the HERMES_STATUS block when done:
-
-` + "```" + `
----HERMES_STATUS---
-STATUS: COMPLETE
-EXIT_SIGNAL: true
-RECOMMENDATION: Move to next task
----END_HERMES_STATUS---
-` + "```",
-		promptContent,
-		t.ID,
-		t.ID, t.Name,
-		formatFiles(t.FilesToTouch),
-		formatCriteria(t.SuccessCriteria),
-	)
+// recordMemory adds the learnings of a HERMES_MEMORY block in the output of
+// a task to .hermes/MEMORY.md
+func (e *TaskExecutor) recordMemory(taskID, output string) {
+	if e.memoryDir == "" {
+		return
+	}
+	notes := prompt.ParseMemory(output)
+	if len(notes) == 0 {
+		return
+	}
+	added, err := prompt.AppendMemory(e.memoryDir, taskID, notes)
+	if err != nil {
+		if e.warn != nil {
+			e.warn("Task %s: failed to record learnings: %v", taskID, err)
+		}
+		return
+	}
+	if e.transcript != nil && added > 0 {
+		fmt.Fprintf(e.transcript, "\nRecorded %d learning(s) in %s\n", added, prompt.MemoryPath(e.memoryDir))
+	}
 }
 
-func formatFiles(files []string) string {
-	if len(files) == 0 {
-		return "- (none specified)"
+// taskWorkDir returns the directory the provider works in for a task, the
+// task's workspace in monorepos
+func (e *TaskExecutor) taskWorkDir(t *task.Task) (string, error) {
+	if t.Workspace == "" {
+		return e.workDir, nil
+	}
+	if !task.ValidWorkspace(t.Workspace) {
+		return "", fmt.Errorf("workspace %s of task %s is outside the repository", t.Workspace, t.ID)
 	}
-	result := ""
-	for _, f := range files {
-		result += fmt.Sprintf("- %s\n", f)
+	dir := t.WorkspaceDir(e.workDir)
+	if info, err := os.Stat(dir); err != nil || !info.IsDir() {
+		return "", fmt.Errorf("workspace %s of task %s does not exist", t.Workspace, t.ID)
 	}
-	return result
+	return dir, nil
 }
 
-func formatCriteria(criteria []string) string {
-	if len(criteria) == 0 {
-		return "- (none specified)"
+// buildTaskPrompt renders the prompt of a task from the project's execute
+// template or defaultExecuteTemplate, shortened to fit ai.maxPromptTokens
+func (e *TaskExecutor) buildTaskPrompt(t *task.Task, promptContent string) (string, error) {
+	data, err := prompt.NewTemplateData(e.workDir, t)
+	if err != nil {
+		return "", err
+	}
+	data.Instructions = promptContent
+	data.ProjectContext = e.projectContext
+	data.GitHistory = e.gitHistory
+	if e.memoryDir != "" {
+		data.Memory = prompt.ReadMemory(e.memoryDir)
+		data.RecordMemory = true
 	}
-	result := ""
-	for _, c := range criteria {
-		result += fmt.Sprintf("- %s\n", c)
+	out, notes, err := prompt.RenderWithin(e.workDir, prompt.ExecuteTemplate, defaultExecuteTemplate, data, data.Config.AI.MaxPromptTokens)
+	if err != nil {
+		return "", err
+	}
+	for _, note := range notes {
+		if e.transcript != nil {
+			fmt.Fprintf(e.transcript, "Prompt budget: %s\n", note)
+		}
+		if e.warn != nil {
+			e.warn("Task %s: %s", t.ID, note)
+		}
 	}
-	return result
+	return out, nil
 }
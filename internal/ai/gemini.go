@@ -7,6 +7,8 @@ import (
 	"fmt"
 	"os"
 	"os/exec"
+	"sort"
+	"strings"
 	"time"
 )
 
@@ -116,6 +118,7 @@ func (p *GeminiProvider) Execute(ctx context.Context, opts *ExecuteOptions) (*Ex
 	if opts.WorkDir != "" {
 		cmd.Dir = opts.WorkDir
 	}
+	applySandbox(cmd)
 
 	output, err := cmd.Output()
 	if err != nil {
@@ -151,13 +154,17 @@ func (p *GeminiProvider) Execute(ctx context.Context, opts *ExecuteOptions) (*Ex
 
 	// Calculate total tokens from all models
 	var totalIn, totalOut int
-	for _, model := range resp.Stats.Models {
+	var models []string
+	for name, model := range resp.Stats.Models {
 		totalIn += model.Tokens.Input
 		totalOut += model.Tokens.Candidates
+		models = append(models, name)
 	}
+	sort.Strings(models)
 
 	return &ExecuteResult{
 		Output:    resp.Response,
+		Model:     strings.Join(models, ","),
 		TokensIn:  totalIn,
 		TokensOut: totalOut,
 		Success:   true,
@@ -202,6 +209,7 @@ func (p *GeminiProvider) ExecuteStream(ctx context.Context, opts *ExecuteOptions
 		if opts.WorkDir != "" {
 			cmd.Dir = opts.WorkDir
 		}
+		applySandbox(cmd)
 
 		stdout, err := cmd.StdoutPipe()
 		if err != nil {
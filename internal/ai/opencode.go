@@ -64,6 +64,7 @@ func (p *OpenCodeProvider) Execute(ctx context.Context, opts *ExecuteOptions) (*
 	if opts.WorkDir != "" {
 		cmd.Dir = opts.WorkDir
 	}
+	applySandbox(cmd)
 
 	stdout, err := cmd.StdoutPipe()
 	if err != nil {
@@ -132,6 +133,7 @@ func (p *OpenCodeProvider) ExecuteStream(ctx context.Context, opts *ExecuteOptio
 		if opts.WorkDir != "" {
 			cmd.Dir = opts.WorkDir
 		}
+		applySandbox(cmd)
 
 		stdout, err := cmd.StdoutPipe()
 		if err != nil {
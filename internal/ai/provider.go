@@ -2,6 +2,7 @@ package ai
 
 import (
 	"context"
+	"strings"
 	"time"
 )
 
@@ -23,13 +24,15 @@ type ExecuteOptions struct {
 	Tools        []string // Allowed tools: "Read", "Write", "Bash", etc.
 	MaxTurns     int
 	SystemPrompt string
-	Timeout      int  // Timeout in seconds
-	StreamOutput bool // Enable streaming
+	Timeout      int    // Timeout in seconds
+	StreamOutput bool   // Enable streaming
+	TaskID       string // Task the execution works on, if any (for the audit trail)
 }
 
 // ExecuteResult contains the result of AI execution
 type ExecuteResult struct {
 	Output    string
+	Model     string // Model reported by the provider, if any
 	Duration  float64
 	Cost      float64
 	TokensIn  int
@@ -149,6 +152,18 @@ func AutoDetectProvider() Provider {
 	return nil
 }
 
+// FallbackProviders returns the available providers other than the named one,
+// in auto-detection order, to retry on when the named provider fails
+func FallbackProviders(name string) []Provider {
+	var providers []Provider
+	for _, available := range GetAvailableProviders() {
+		if !strings.EqualFold(available, name) {
+			providers = append(providers, GetProvider(available))
+		}
+	}
+	return providers
+}
+
 // GetAvailableProviders returns a list of available provider names
 func GetAvailableProviders() []string {
 	var providers []string
@@ -2,7 +2,9 @@ package ai
 
 import (
 	"context"
+	"errors"
 	"fmt"
+	"strings"
 	"time"
 )
 
@@ -32,15 +34,13 @@ func ExecuteWithRetry(ctx context.Context, provider Provider, opts *ExecuteOptio
 	delay := cfg.Delay
 
 	for attempt := 1; attempt <= cfg.MaxRetries; attempt++ {
-		var result *ExecuteResult
-		var err error
-
-		// Use streaming if enabled
-		if opts.StreamOutput {
-			result, err = executeWithStreaming(ctx, provider, opts)
-		} else {
-			result, err = provider.Execute(ctx, opts)
-		}
+		result, err := observeExecution(ctx, provider, opts, func(ctx context.Context) (*ExecuteResult, error) {
+			// Use streaming if enabled
+			if opts.StreamOutput {
+				return executeWithStreaming(ctx, provider, opts)
+			}
+			return provider.Execute(ctx, opts)
+		})
 
 		if err == nil && result.Success {
 			return result, nil
@@ -77,11 +77,13 @@ func executeWithStreaming(ctx context.Context, provider Provider, opts *ExecuteO
 		return nil, err
 	}
 
-	var output string
+	var output, model string
 	display := NewStreamDisplay(true, true, provider.Name())
 
 	for event := range events {
 		switch event.Type {
+		case "system":
+			model = event.Model
 		case "text", "assistant":
 			display.Handle(event)
 			output += event.Text
@@ -92,11 +94,47 @@ func executeWithStreaming(ctx context.Context, provider Provider, opts *ExecuteO
 		case "result":
 			display.Handle(event)
 		case "error":
-			return &ExecuteResult{Success: false, Output: output, Error: event.Text}, nil
+			return &ExecuteResult{Success: false, Output: output, Model: model, Error: event.Text}, nil
 		case "done":
 			fmt.Println()
 		}
 	}
 
-	return &ExecuteResult{Success: true, Output: output}, nil
+	return &ExecuteResult{Success: true, Output: output, Model: model}, nil
+}
+
+// providerErrorPatterns are signs in a failed execution that the provider was
+// unavailable or rate limited, rather than that the task was too hard
+var providerErrorPatterns = []string{
+	"rate limit",
+	"rate_limit",
+	"too many requests",
+	"429",
+	"overloaded",
+	"usage limit",
+	"quota",
+}
+
+// IsProviderError reports whether an execution failed because of the provider,
+// because it timed out or was rate limited, so another provider may succeed.
+// Only the error is checked: the output is the task's own work and may mention
+// rate limits or quotas without the provider failing
+func IsProviderError(result *ExecuteResult, err error) bool {
+	if errors.Is(err, context.DeadlineExceeded) {
+		return true
+	}
+	var text string
+	if err != nil {
+		text = err.Error()
+	}
+	if result != nil && !result.Success {
+		text += "\n" + result.Error
+	}
+	text = strings.ToLower(text)
+	for _, pattern := range providerErrorPatterns {
+		if strings.Contains(text, pattern) {
+			return true
+		}
+	}
+	return false
 }
@@ -0,0 +1,291 @@
+package ai
+
+import (
+	"fmt"
+	"os"
+	"os/exec"
+	"path"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"sync"
+)
+
+// Sandbox modes for provider subprocesses
+const (
+	SandboxNone      = "none"
+	SandboxSudo      = "sudo"        // sudo -n -u <user>
+	SandboxSystemd   = "systemd-run" // transient systemd service with filesystem protection
+	SandboxContainer = "container"   // container from Image with only the work directory mounted
+)
+
+// Sandbox describes how provider subprocesses are confined. Providers run
+// with --dangerously-skip-permissions, so this limits what they can touch
+// outside the project directory.
+type Sandbox struct {
+	Mode string
+	// User is the low-privilege account to run as; optional for systemd-run,
+	// which then uses the calling user's service manager
+	User string
+	// Properties are extra systemd unit properties, e.g. "MemoryMax=4G"
+	Properties []string
+	// PassEnv lists environment variables forwarded into the sandbox (API keys etc.)
+	PassEnv []string
+	// Image is the container image providers run in, with the provider CLIs installed
+	Image string
+	// Runtime is the container CLI, docker unless set, e.g. podman
+	Runtime string
+	// RunArgs are extra container run arguments, e.g. "--memory=4g"
+	RunArgs []string
+	// EnvAllow limits the environment of providers to these variables, given
+	// as names or patterns like "AWS_*"; PATH and HOME are always kept
+	EnvAllow []string
+	// EnvStrip removes these variables or patterns from the environment, even
+	// when they are allowed
+	EnvStrip []string
+}
+
+var (
+	sandboxMu sync.RWMutex
+	sandbox   Sandbox
+)
+
+// SetSandbox configures the sandbox applied to every provider subprocess
+func SetSandbox(s Sandbox) error {
+	if s.Mode == "" {
+		s.Mode = SandboxNone
+	}
+	for _, pattern := range append(append([]string{}, s.EnvAllow...), s.EnvStrip...) {
+		if _, err := path.Match(pattern, ""); err != nil {
+			return fmt.Errorf("invalid environment pattern %q: %w", pattern, err)
+		}
+	}
+	switch s.Mode {
+	case SandboxNone:
+	case SandboxSudo:
+		if s.User == "" {
+			return fmt.Errorf("sandbox mode %s requires a user", s.Mode)
+		}
+		if runtime.GOOS == "windows" {
+			return fmt.Errorf("sandbox mode %s is only supported on Unix", s.Mode)
+		}
+	case SandboxSystemd:
+		if runtime.GOOS != "linux" {
+			return fmt.Errorf("sandbox mode %s is only supported on Linux", s.Mode)
+		}
+	case SandboxContainer:
+		if s.Image == "" {
+			return fmt.Errorf("sandbox mode %s requires an image", s.Mode)
+		}
+		if s.Runtime == "" {
+			s.Runtime = "docker"
+		}
+	default:
+		return fmt.Errorf("unknown sandbox mode: %s (use none, sudo, systemd-run or container)", s.Mode)
+	}
+
+	sandboxMu.Lock()
+	sandbox = s
+	sandboxMu.Unlock()
+	return nil
+}
+
+// GetSandbox returns the configured sandbox
+func GetSandbox() Sandbox {
+	sandboxMu.RLock()
+	defer sandboxMu.RUnlock()
+	return sandbox
+}
+
+// ApplySandbox confines a command Hermes runs in the project on behalf of a
+// task, such as a verification command, like provider subprocesses
+func ApplySandbox(cmd *exec.Cmd) {
+	applySandbox(cmd)
+}
+
+// applySandbox rewrites a prepared provider command to run inside the
+// configured sandbox. It must be called after Dir and Env are set.
+func applySandbox(cmd *exec.Cmd) {
+	s := GetSandbox()
+	if len(s.EnvAllow) > 0 || len(s.EnvStrip) > 0 {
+		cmd.Env = s.FilterEnv(cmd.Environ())
+	}
+	if s.Mode == "" || s.Mode == SandboxNone {
+		return
+	}
+
+	var wrapper []string
+	switch s.Mode {
+	case SandboxSudo:
+		// sudo resets the environment, so re-apply what the provider needs via env
+		wrapper = []string{"sudo", "-n", "-u", s.User, "-H", "--", "env"}
+		wrapper = append(wrapper, sandboxEnv(cmd, s)...)
+	case SandboxSystemd:
+		wrapper = systemdRunArgs(cmd, s)
+	case SandboxContainer:
+		wrapper = containerRunArgs(cmd, s)
+	}
+
+	path, err := exec.LookPath(wrapper[0])
+	cmd.Path = path
+	cmd.Err = err
+	cmd.Args = append(wrapper, cmd.Args...)
+}
+
+// systemdRunArgs builds a systemd-run invocation that only allows writes to the work directory
+func systemdRunArgs(cmd *exec.Cmd, s Sandbox) []string {
+	workDir := cmd.Dir
+	if workDir == "" {
+		workDir = "."
+	}
+	if abs, err := filepath.Abs(workDir); err == nil {
+		workDir = abs
+	}
+
+	args := []string{"systemd-run", "--pipe", "--wait", "--quiet", "--collect"}
+	if s.User == "" {
+		args = append(args, "--user")
+	} else {
+		args = append(args, "--uid="+s.User)
+	}
+	args = append(args,
+		"--working-directory="+workDir,
+		"-p", "ProtectSystem=strict",
+		"-p", "ProtectHome=read-only",
+		"-p", "ReadWritePaths="+workDir,
+		"-p", "PrivateTmp=yes",
+		"-p", "NoNewPrivileges=yes",
+	)
+	for _, prop := range s.Properties {
+		args = append(args, "-p", prop)
+	}
+	for _, kv := range sandboxEnv(cmd, s) {
+		args = append(args, "--setenv="+kv)
+	}
+	return append(args, "--")
+}
+
+// containerRunArgs builds a container run invocation that mounts only the work
+// directory, plus the repository's git directory when the work directory is a
+// worktree, so each task of a parallel run gets a container of its own
+func containerRunArgs(cmd *exec.Cmd, s Sandbox) []string {
+	workDir := cmd.Dir
+	if workDir == "" {
+		workDir = "."
+	}
+	if abs, err := filepath.Abs(workDir); err == nil {
+		workDir = abs
+	}
+
+	args := []string{s.Runtime, "run", "--rm", "-i", "--init",
+		"-v", workDir + ":" + workDir,
+		"-w", workDir,
+	}
+	if gitDir := worktreeCommonDir(workDir); gitDir != "" {
+		args = append(args, "-v", gitDir+":"+gitDir)
+	}
+	if s.User != "" {
+		args = append(args, "--user", s.User)
+	}
+	// Variables are named only, the runtime takes their values from its own
+	// environment so that API keys do not show up in the process list
+	for _, kv := range sandboxEnv(cmd, s) {
+		name, _, _ := strings.Cut(kv, "=")
+		args = append(args, "-e", name)
+	}
+	args = append(args, s.RunArgs...)
+	return append(args, s.Image)
+}
+
+// worktreeCommonDir returns the git directory of the main repository when
+// workDir is a git worktree, whose .git file points into it
+func worktreeCommonDir(workDir string) string {
+	data, err := os.ReadFile(filepath.Join(workDir, ".git"))
+	if err != nil {
+		return "" // No worktree, or .git is the directory itself
+	}
+	gitDir, ok := strings.CutPrefix(strings.TrimSpace(string(data)), "gitdir:")
+	if !ok {
+		return ""
+	}
+	gitDir = strings.TrimSpace(gitDir)
+	if !filepath.IsAbs(gitDir) {
+		gitDir = filepath.Join(workDir, gitDir)
+	}
+	// <repo>/.git/worktrees/<name>
+	if filepath.Base(filepath.Dir(gitDir)) != "worktrees" {
+		return gitDir
+	}
+	return filepath.Dir(filepath.Dir(gitDir))
+}
+
+// sandboxEnv returns the variables to set inside the sandbox: the ones the
+// provider added on top of the parent environment plus the PassEnv names
+func sandboxEnv(cmd *exec.Cmd, s Sandbox) []string {
+	parent := make(map[string]bool)
+	for _, kv := range os.Environ() {
+		parent[kv] = true
+	}
+
+	var env []string
+	for _, kv := range cmd.Env {
+		if !parent[kv] {
+			env = append(env, kv)
+		}
+	}
+	for _, name := range s.PassEnv {
+		if !s.envAllowed(name) {
+			continue
+		}
+		if value, ok := os.LookupEnv(name); ok {
+			env = append(env, name+"="+value)
+		}
+	}
+	return env
+}
+
+// FilterEnv returns the NAME=value entries of env that EnvAllow and EnvStrip
+// let through to a provider. It never returns nil, which exec.Cmd would take
+// as the whole environment of Hermes.
+func (s Sandbox) FilterEnv(env []string) []string {
+	filtered := []string{}
+	for _, kv := range env {
+		name, _, _ := strings.Cut(kv, "=")
+		if s.envAllowed(name) {
+			filtered = append(filtered, kv)
+		}
+	}
+	return filtered
+}
+
+// envAllowed reports whether a variable passes the allow and strip lists
+func (s Sandbox) envAllowed(name string) bool {
+	if matchEnv(s.EnvStrip, name) {
+		return false
+	}
+	return len(s.EnvAllow) == 0 || name == "PATH" || name == "HOME" || matchEnv(s.EnvAllow, name)
+}
+
+// matchEnv reports whether name matches one of the patterns
+func matchEnv(patterns []string, name string) bool {
+	for _, pattern := range patterns {
+		if ok, _ := path.Match(pattern, name); ok {
+			return true
+		}
+	}
+	return false
+}
+
+// String returns a short description of the sandbox for logs
+func (s Sandbox) String() string {
+	if s.Mode == "" || s.Mode == SandboxNone {
+		return SandboxNone
+	}
+	if s.Mode == SandboxContainer {
+		return s.Mode + " " + s.Image
+	}
+	if s.User != "" {
+		return s.Mode + " as " + s.User
+	}
+	return s.Mode
+}
@@ -17,23 +17,20 @@ func NewFeatureAnalyzer(basePath string) *FeatureAnalyzer {
 	return &FeatureAnalyzer{basePath: basePath}
 }
 
-// GetHighestFeatureID returns the highest feature ID number
+// GetHighestFeatureID returns the highest feature ID number, including archived features
 func (a *FeatureAnalyzer) GetHighestFeatureID() (int, error) {
 	reader := task.NewReader(a.basePath)
-	files, err := reader.GetFeatureFiles()
+	features, err := reader.GetAllFeatures()
 	if err != nil {
 		return 0, err
 	}
+	archived, _ := reader.GetArchivedFeatures()
+	features = append(features, archived...)
 
 	highest := 0
 	re := regexp.MustCompile(`F(\d+)`)
 
-	for _, file := range files {
-		feature, err := reader.ReadFeature(file)
-		if err != nil {
-			continue
-		}
-
+	for _, feature := range features {
 		if m := re.FindStringSubmatch(feature.ID); len(m) > 1 {
 			if n, _ := strconv.Atoi(m[1]); n > highest {
 				highest = n
@@ -44,13 +41,17 @@ func (a *FeatureAnalyzer) GetHighestFeatureID() (int, error) {
 	return highest, nil
 }
 
-// GetHighestTaskID returns the highest task ID number
+// GetHighestTaskID returns the highest task ID number, including archived tasks
 func (a *FeatureAnalyzer) GetHighestTaskID() (int, error) {
 	reader := task.NewReader(a.basePath)
 	tasks, err := reader.GetAllTasks()
 	if err != nil {
 		return 0, err
 	}
+	archived, _ := reader.GetArchivedFeatures()
+	for _, f := range archived {
+		tasks = append(tasks, f.Tasks...)
+	}
 
 	highest := 0
 	re := regexp.MustCompile(`T(\d+)`)
@@ -3,42 +3,108 @@ package analyzer
 import (
 	"regexp"
 	"strings"
+
+	"hermes/internal/config"
 )
 
 var (
 	hermesStatusRegex = regexp.MustCompile(`---HERMES_STATUS---\s*([\s\S]*?)\s*---END_HERMES_STATUS---`)
-	statusRegex       = regexp.MustCompile(`STATUS:\s*(\w+)`)
+	statusRegex       = regexp.MustCompile(`STATUS:\s*([\p{L}\p{N}_]+)`)
 	exitSignalRegex   = regexp.MustCompile(`EXIT_SIGNAL:\s*(true|false)`)
 	workTypeRegex     = regexp.MustCompile(`WORK_TYPE:\s*(\w+)`)
 	recommendRegex    = regexp.MustCompile(`RECOMMENDATION:\s*(.+)`)
+)
 
-	completionKeywords = []string{
-		"done", "complete", "finished", "implemented",
-		"all tasks complete", "project complete",
-	}
-
-	testOnlyPatterns = []string{
-		"npm test", "pytest", "go test", "jest",
-		"running tests", "test passed", "tests passed",
-	}
-
-	noWorkPatterns = []string{
-		"nothing to do", "no changes needed",
-		"already implemented", "already exists",
-	}
+// Keywords holds the phrase sets used to detect progress, completion and errors.
+// Phrases are matched case-insensitively as substrings of the AI output.
+type Keywords struct {
+	Completion     []string
+	TestOnly       []string
+	NoWork         []string
+	Implementation []string
+	Error          []string
+	// StatusAliases maps custom STATUS values to COMPLETE, BLOCKED, AT_RISK, PAUSED or IN_PROGRESS
+	StatusAliases map[string]string
+}
 
-	implementationPatterns = []string{
-		"created", "modified", "updated", "added",
-		"func ", "function ", "class ", "def ",
+// DefaultKeywords returns the built-in English keyword sets
+func DefaultKeywords() Keywords {
+	return Keywords{
+		Completion: []string{
+			"done", "complete", "finished", "implemented",
+			"all tasks complete", "project complete",
+		},
+		TestOnly: []string{
+			"npm test", "pytest", "go test", "jest",
+			"running tests", "test passed", "tests passed",
+		},
+		NoWork: []string{
+			"nothing to do", "no changes needed",
+			"already implemented", "already exists",
+		},
+		Implementation: []string{
+			"created", "modified", "updated", "added",
+			"func ", "function ", "class ", "def ",
+		},
+		Error: []string{"error"},
 	}
-)
+}
 
 // ResponseAnalyzer analyzes AI responses
-type ResponseAnalyzer struct{}
+type ResponseAnalyzer struct {
+	keywords Keywords
+}
 
 // NewResponseAnalyzer creates a new response analyzer
 func NewResponseAnalyzer() *ResponseAnalyzer {
-	return &ResponseAnalyzer{}
+	return &ResponseAnalyzer{keywords: DefaultKeywords()}
+}
+
+// NewResponseAnalyzerWithConfig creates a response analyzer using the keyword
+// sets from config; sets left empty keep their defaults
+func NewResponseAnalyzerWithConfig(cfg *config.AnalyzerConfig) *ResponseAnalyzer {
+	a := NewResponseAnalyzer()
+	if cfg != nil {
+		a.SetKeywords(Keywords{
+			Completion:     cfg.CompletionKeywords,
+			TestOnly:       cfg.TestOnlyPatterns,
+			NoWork:         cfg.NoWorkPatterns,
+			Implementation: cfg.ImplementationPatterns,
+			Error:          cfg.ErrorKeywords,
+			StatusAliases:  cfg.StatusAliases,
+		})
+	}
+	return a
+}
+
+// SetKeywords replaces the keyword sets that are non-empty in k
+func (a *ResponseAnalyzer) SetKeywords(k Keywords) {
+	override := func(dst *[]string, src []string) {
+		if len(src) == 0 {
+			return
+		}
+		*dst = make([]string, len(src))
+		for i, kw := range src {
+			(*dst)[i] = strings.ToLower(kw)
+		}
+	}
+	override(&a.keywords.Completion, k.Completion)
+	override(&a.keywords.TestOnly, k.TestOnly)
+	override(&a.keywords.NoWork, k.NoWork)
+	override(&a.keywords.Implementation, k.Implementation)
+	override(&a.keywords.Error, k.Error)
+
+	if len(k.StatusAliases) > 0 {
+		a.keywords.StatusAliases = make(map[string]string, len(k.StatusAliases))
+		for alias, status := range k.StatusAliases {
+			a.keywords.StatusAliases[strings.ToUpper(alias)] = strings.ToUpper(status)
+		}
+	}
+}
+
+// GetKeywords returns the keyword sets in use
+func (a *ResponseAnalyzer) GetKeywords() Keywords {
+	return a.keywords
 }
 
 // Analyze analyzes an AI response and returns the result
@@ -54,7 +120,7 @@ func (a *ResponseAnalyzer) Analyze(output string) *AnalysisResult {
 	a.parseStatusBlock(output, result)
 
 	// Detect completion keywords
-	for _, kw := range completionKeywords {
+	for _, kw := range a.keywords.Completion {
 		if strings.Contains(outputLower, kw) {
 			result.CompletionKeyword = kw
 			result.Confidence += 0.2
@@ -66,7 +132,7 @@ func (a *ResponseAnalyzer) Analyze(output string) *AnalysisResult {
 	hasTestPattern := false
 	hasImplementation := false
 
-	for _, pattern := range testOnlyPatterns {
+	for _, pattern := range a.keywords.TestOnly {
 		if strings.Contains(outputLower, pattern) {
 			hasTestPattern = true
 			break
@@ -74,7 +140,7 @@ func (a *ResponseAnalyzer) Analyze(output string) *AnalysisResult {
 	}
 
 	// Check for implementation work
-	for _, pattern := range implementationPatterns {
+	for _, pattern := range a.keywords.Implementation {
 		if strings.Contains(outputLower, pattern) {
 			hasImplementation = true
 			break
@@ -84,7 +150,7 @@ func (a *ResponseAnalyzer) Analyze(output string) *AnalysisResult {
 	result.IsTestOnly = hasTestPattern && !hasImplementation
 
 	// Detect no-work patterns
-	for _, pattern := range noWorkPatterns {
+	for _, pattern := range a.keywords.NoWork {
 		if strings.Contains(outputLower, pattern) {
 			result.HasProgress = false
 			break
@@ -92,7 +158,9 @@ func (a *ResponseAnalyzer) Analyze(output string) *AnalysisResult {
 	}
 
 	// Count errors
-	result.ErrorCount = strings.Count(outputLower, "error")
+	for _, kw := range a.keywords.Error {
+		result.ErrorCount += strings.Count(outputLower, kw)
+	}
 	result.IsStuck = result.ErrorCount > 5
 
 	// Determine status flags based on parsed status
@@ -151,6 +219,9 @@ func (a *ResponseAnalyzer) parseStatusBlock(output string, result *AnalysisResul
 
 	if m := statusRegex.FindStringSubmatch(block); len(m) > 1 {
 		result.Status = m[1]
+		if status, ok := a.keywords.StatusAliases[strings.ToUpper(m[1])]; ok {
+			result.Status = status
+		}
 	}
 
 	if m := exitSignalRegex.FindStringSubmatch(block); len(m) > 1 {
@@ -2,6 +2,8 @@ package analyzer
 
 import (
 	"testing"
+
+	"hermes/internal/config"
 )
 
 func TestAnalyzeStatusBlock(t *testing.T) {
@@ -219,3 +221,36 @@ func TestAnalyzeProgress(t *testing.T) {
 		})
 	}
 }
+
+func TestAnalyzerCustomKeywords(t *testing.T) {
+	a := NewResponseAnalyzerWithConfig(&config.AnalyzerConfig{
+		CompletionKeywords: []string{"Fertig"},
+		NoWorkPatterns:     []string{"nichts zu tun"},
+		StatusAliases:      map[string]string{"erledigt": "COMPLETE", "blockiert": "BLOCKED"},
+	})
+
+	result := a.Analyze("Die Aufgabe ist fertig und alles wurde umgesetzt, die Tests laufen jetzt ohne Probleme durch.")
+	if result.CompletionKeyword != "fertig" {
+		t.Errorf("expected custom completion keyword, got %q", result.CompletionKeyword)
+	}
+
+	// English defaults are replaced, not extended
+	result = a.Analyze("All work is done here and nothing is left over, the feature is ready for review now.")
+	if result.CompletionKeyword != "" {
+		t.Errorf("expected default completion keywords to be replaced, got %q", result.CompletionKeyword)
+	}
+
+	if result := a.Analyze("Es gibt nichts zu tun"); result.HasProgress {
+		t.Error("expected custom no-work pattern to mark no progress")
+	}
+
+	// Sets that are not configured keep their defaults
+	if len(a.GetKeywords().Implementation) != len(DefaultKeywords().Implementation) {
+		t.Error("expected unconfigured keyword sets to keep defaults")
+	}
+
+	result = a.Analyze("---HERMES_STATUS---\nSTATUS: BLOCKIERT\nEXIT_SIGNAL: false\nRECOMMENDATION: API-Schlüssel fehlt\n---END_HERMES_STATUS---")
+	if result.Status != "BLOCKED" || !result.IsBlocked {
+		t.Errorf("expected status alias to map to BLOCKED, got %q", result.Status)
+	}
+}
@@ -0,0 +1,150 @@
+package audit
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"sync"
+	"time"
+
+	"hermes/internal/ai"
+)
+
+// Entry is one prompt sent to a provider and the response received
+type Entry struct {
+	Time      time.Time `json:"time"`
+	EndTime   time.Time `json:"endTime"`
+	TaskID    string    `json:"taskId,omitempty"`
+	Provider  string    `json:"provider"`
+	Model     string    `json:"model,omitempty"`
+	Success   bool      `json:"success"`
+	Error     string    `json:"error,omitempty"`
+	Cost      float64   `json:"cost"`
+	TokensIn  int       `json:"tokensIn,omitempty"`
+	TokensOut int       `json:"tokensOut,omitempty"`
+	Prompt    string    `json:"prompt"`
+	Response  string    `json:"response"`
+}
+
+// Duration returns how long the execution took
+func (e *Entry) Duration() time.Duration {
+	return e.EndTime.Sub(e.Time)
+}
+
+// FromExecution converts a provider execution to an audit entry
+func FromExecution(x *ai.Execution) *Entry {
+	e := &Entry{
+		Time:     x.StartTime,
+		EndTime:  x.EndTime,
+		TaskID:   x.TaskID,
+		Provider: x.Provider,
+		Prompt:   x.Prompt,
+	}
+	if r := x.Result; r != nil {
+		e.Model = r.Model
+		e.Success = r.Success
+		e.Error = r.Error
+		e.Cost = r.Cost
+		e.TokensIn = r.TokensIn
+		e.TokensOut = r.TokensOut
+		e.Response = r.Output
+	}
+	if x.Err != nil {
+		e.Success = false
+		e.Error = x.Err.Error()
+	}
+	return e
+}
+
+// Dir returns the directory holding the audit log
+func Dir(basePath string) string {
+	return filepath.Join(basePath, ".hermes", "audit")
+}
+
+// Log appends entries to one JSON Lines file per day. Files are only ever
+// appended to; nothing in Hermes rewrites or deletes them.
+type Log struct {
+	basePath string
+	onError  func(err error)
+	mu       sync.Mutex
+}
+
+// NewLog creates an audit log for a project
+func NewLog(basePath string) *Log {
+	return &Log{basePath: basePath}
+}
+
+// SetErrorHandler sets the function called when recording an execution fails
+func (l *Log) SetErrorHandler(fn func(err error)) {
+	l.onError = fn
+}
+
+// Append writes an entry to the file of its day
+func (l *Log) Append(e *Entry) error {
+	data, err := json.Marshal(e)
+	if err != nil {
+		return err
+	}
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	if err := os.MkdirAll(Dir(l.basePath), 0755); err != nil {
+		return err
+	}
+	path := filepath.Join(Dir(l.basePath), fmt.Sprintf("audit-%s.jsonl", e.Time.Format("2006-01-02")))
+	file, err := os.OpenFile(path, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0644)
+	if err != nil {
+		return err
+	}
+	if _, err := file.Write(append(data, '\n')); err != nil {
+		file.Close()
+		return err
+	}
+	return file.Close()
+}
+
+// Record is an ai.ExecutionHook appending every execution
+func (l *Log) Record(x *ai.Execution) {
+	if err := l.Append(FromExecution(x)); err != nil && l.onError != nil {
+		l.onError(err)
+	}
+}
+
+// Read returns the entries of a task in chronological order, or all entries
+// when taskID is empty
+func Read(basePath, taskID string) ([]Entry, error) {
+	files, err := filepath.Glob(filepath.Join(Dir(basePath), "audit-*.jsonl"))
+	if err != nil {
+		return nil, err
+	}
+	sort.Strings(files)
+
+	var entries []Entry
+	for _, path := range files {
+		file, err := os.Open(path)
+		if err != nil {
+			return nil, err
+		}
+		scanner := bufio.NewScanner(file)
+		scanner.Buffer(make([]byte, 0, 64*1024), 64*1024*1024)
+		for scanner.Scan() {
+			var e Entry
+			if err := json.Unmarshal(scanner.Bytes(), &e); err != nil {
+				continue
+			}
+			if taskID == "" || e.TaskID == taskID {
+				entries = append(entries, e)
+			}
+		}
+		err = scanner.Err()
+		file.Close()
+		if err != nil {
+			return nil, fmt.Errorf("failed to read %s: %w", path, err)
+		}
+	}
+	return entries, nil
+}
@@ -0,0 +1,78 @@
+package audit
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"hermes/internal/ai"
+)
+
+func TestRecordAndRead(t *testing.T) {
+	dir := t.TempDir()
+	log := NewLog(dir)
+	var failures int
+	log.SetErrorHandler(func(err error) { failures++ })
+
+	day1 := time.Date(2026, 3, 1, 10, 0, 0, 0, time.Local)
+	day2 := day1.Add(24 * time.Hour)
+	log.Record(&ai.Execution{
+		Provider: "claude", TaskID: "T001", Prompt: "implement login",
+		Result:    &ai.ExecuteResult{Output: "done", Model: "sonnet", Cost: 0.12, Success: true},
+		StartTime: day1, EndTime: day1.Add(30 * time.Second),
+	})
+	log.Record(&ai.Execution{
+		Provider: "claude", TaskID: "T002", Prompt: "add tests",
+		Err:       errors.New("timeout"),
+		StartTime: day1.Add(time.Minute), EndTime: day1.Add(2 * time.Minute),
+	})
+	log.Record(&ai.Execution{
+		Provider: "gemini", TaskID: "T001", Prompt: "retry login",
+		Result:    &ai.ExecuteResult{Output: "partial", Success: false, Error: "exit status 1"},
+		StartTime: day2, EndTime: day2.Add(time.Second),
+	})
+	if failures != 0 {
+		t.Fatalf("expected no failures, got %d", failures)
+	}
+
+	files, _ := filepath.Glob(filepath.Join(Dir(dir), "audit-*.jsonl"))
+	if len(files) != 2 {
+		t.Errorf("expected one file per day, got %v", files)
+	}
+
+	entries, err := Read(dir, "T001")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(entries) != 2 {
+		t.Fatalf("expected 2 entries for T001, got %d", len(entries))
+	}
+	first := entries[0]
+	if first.Prompt != "implement login" || first.Response != "done" || first.Model != "sonnet" || !first.Success {
+		t.Errorf("unexpected entry %+v", first)
+	}
+	if first.Duration() != 30*time.Second {
+		t.Errorf("expected 30s, got %v", first.Duration())
+	}
+	if entries[1].Provider != "gemini" || entries[1].Success || entries[1].Error != "exit status 1" {
+		t.Errorf("unexpected entry %+v", entries[1])
+	}
+
+	all, _ := Read(dir, "")
+	if len(all) != 3 || all[1].Error != "timeout" {
+		t.Errorf("expected all 3 entries in order, got %+v", all)
+	}
+}
+
+func TestReadWithoutAuditLog(t *testing.T) {
+	dir := t.TempDir()
+	entries, err := Read(dir, "T001")
+	if err != nil || len(entries) != 0 {
+		t.Errorf("expected no entries, got %v (%v)", entries, err)
+	}
+	if _, err := os.Stat(Dir(dir)); !os.IsNotExist(err) {
+		t.Error("expected reading not to create the audit directory")
+	}
+}
@@ -0,0 +1,99 @@
+package checkpoint
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+
+	"hermes/internal/circuit"
+)
+
+// Run modes stored in a checkpoint
+const (
+	ModeSequential = "sequential"
+	ModeParallel   = "parallel"
+)
+
+// Options are the effective run options, so a resumed run behaves like the original
+type Options struct {
+	AutoBranch bool `json:"autoBranch"`
+	AutoCommit bool `json:"autoCommit"`
+	Autonomous bool `json:"autonomous"`
+	Workers    int  `json:"workers,omitempty"`
+	// Until is the milestone version the run stops at, see 'hermes run --until'
+	Until string `json:"until,omitempty"`
+}
+
+// Checkpoint records where a run stopped so 'hermes resume' can continue it
+// after Ctrl+C, a crash or a power loss
+type Checkpoint struct {
+	RunID     string                `json:"runId"`
+	Mode      string                `json:"mode"`
+	Provider  string                `json:"provider"`
+	Options   Options               `json:"options"`
+	Loop      int                   `json:"loop"`              // Loop that was running when the checkpoint was written
+	TaskID    string                `json:"taskId,omitempty"`  // Task in progress
+	Batches   [][]string            `json:"batches,omitempty"` // Remaining parallel batches, the first one in progress
+	Breaker   *circuit.BreakerState `json:"breaker,omitempty"`
+	Cost      float64               `json:"cost"`    // Cost spent so far, for run budget limits
+	Elapsed   time.Duration         `json:"elapsed"` // Run time so far, for run duration limits
+	StartTime time.Time             `json:"startTime"`
+	UpdatedAt time.Time             `json:"updatedAt"`
+}
+
+// GetCheckpointPath returns the file the checkpoint of the current run is stored in
+func GetCheckpointPath(basePath string) string {
+	return filepath.Join(basePath, ".hermes", "checkpoint.json")
+}
+
+// Save writes the checkpoint. The file is replaced atomically so an interruption
+// while saving never leaves a truncated checkpoint behind.
+func Save(basePath string, cp *Checkpoint) error {
+	cp.UpdatedAt = time.Now()
+	data, err := json.MarshalIndent(cp, "", "  ")
+	if err != nil {
+		return err
+	}
+	path := GetCheckpointPath(basePath)
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return err
+	}
+	tmp := path + ".tmp"
+	if err := os.WriteFile(tmp, data, 0644); err != nil {
+		return err
+	}
+	return os.Rename(tmp, path)
+}
+
+// Load reads the checkpoint of an interrupted run
+func Load(basePath string) (*Checkpoint, error) {
+	data, err := os.ReadFile(GetCheckpointPath(basePath))
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, fmt.Errorf("no interrupted run to resume")
+		}
+		return nil, err
+	}
+	var cp Checkpoint
+	if err := json.Unmarshal(data, &cp); err != nil {
+		return nil, fmt.Errorf("invalid checkpoint: %w", err)
+	}
+	return &cp, nil
+}
+
+// Exists returns true if an interrupted run left a checkpoint
+func Exists(basePath string) bool {
+	_, err := os.Stat(GetCheckpointPath(basePath))
+	return err == nil
+}
+
+// Clear removes the checkpoint, e.g. after a run ended normally
+func Clear(basePath string) error {
+	err := os.Remove(GetCheckpointPath(basePath))
+	if os.IsNotExist(err) {
+		return nil
+	}
+	return err
+}
@@ -0,0 +1,63 @@
+package checkpoint
+
+import (
+	"os"
+	"testing"
+	"time"
+
+	"hermes/internal/circuit"
+)
+
+func TestSaveLoadClear(t *testing.T) {
+	tmpDir, err := os.MkdirTemp("", "hermes-checkpoint-test-*")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(tmpDir)
+
+	if Exists(tmpDir) {
+		t.Fatal("expected no checkpoint in an empty project")
+	}
+	if _, err := Load(tmpDir); err == nil {
+		t.Fatal("expected an error without a checkpoint")
+	}
+
+	cp := &Checkpoint{
+		RunID:    "20250101-120000",
+		Mode:     ModeParallel,
+		Provider: "claude",
+		Options:  Options{AutoCommit: true, Workers: 3},
+		Loop:     2,
+		Batches:  [][]string{{"T003", "T004"}, {"T005"}},
+		Breaker:  &circuit.BreakerState{State: circuit.StateHalfOpen, ConsecutiveNoProgress: 2},
+		Cost:     1.5,
+		Elapsed:  10 * time.Minute,
+	}
+	if err := Save(tmpDir, cp); err != nil {
+		t.Fatal(err)
+	}
+
+	loaded, err := Load(tmpDir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if loaded.RunID != cp.RunID || loaded.Loop != 2 || len(loaded.Batches) != 2 || loaded.Batches[0][1] != "T004" {
+		t.Errorf("unexpected checkpoint: %+v", loaded)
+	}
+	if loaded.Breaker == nil || loaded.Breaker.State != circuit.StateHalfOpen {
+		t.Errorf("expected breaker state to be saved, got %+v", loaded.Breaker)
+	}
+	if loaded.Options.Workers != 3 || loaded.Elapsed != 10*time.Minute || loaded.UpdatedAt.IsZero() {
+		t.Errorf("unexpected options or timing: %+v", loaded)
+	}
+
+	if err := Clear(tmpDir); err != nil {
+		t.Fatal(err)
+	}
+	if Exists(tmpDir) {
+		t.Error("expected checkpoint to be removed")
+	}
+	if err := Clear(tmpDir); err != nil {
+		t.Errorf("expected clearing a missing checkpoint to succeed, got %v", err)
+	}
+}
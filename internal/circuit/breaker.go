@@ -5,23 +5,39 @@ import (
 	"fmt"
 	"os"
 	"path/filepath"
+	"sync"
 	"time"
 )
 
 const (
-	HalfOpenThreshold = 2 // Loops without progress before HALF_OPEN
+	MaxHistoryEntries = 100 // Transitions kept in circuit-history.json
+	HalfOpenThreshold = 2   // Loops without progress before HALF_OPEN
 	OpenThreshold     = 3 // Loops without progress before OPEN
 )
 
 // StateChangeCallback is called when circuit breaker state changes
 type StateChangeCallback func(fromState, toState State, reason string)
 
+var (
+	stateHooksMu sync.Mutex
+	stateHooks   []StateChangeCallback
+)
+
+// OnStateChange registers a callback for state changes of every breaker, in
+// addition to the callback set with SetStateChangeCallback
+func OnStateChange(cb StateChangeCallback) {
+	stateHooksMu.Lock()
+	defer stateHooksMu.Unlock()
+	stateHooks = append(stateHooks, cb)
+}
+
 // Breaker implements the circuit breaker pattern
 type Breaker struct {
 	basePath        string
 	stateFile       string
 	historyFile     string
 	onStateChange   StateChangeCallback
+	cooldown        time.Duration
 }
 
 // New creates a new circuit breaker
@@ -39,6 +55,25 @@ func (b *Breaker) SetStateChangeCallback(cb StateChangeCallback) {
 	b.onStateChange = cb
 }
 
+// SetCooldown enables auto-recovery: once the breaker has been OPEN for the
+// cooldown, it moves to HALF_OPEN to probe one loop. 0 keeps it OPEN until reset.
+func (b *Breaker) SetCooldown(cooldown time.Duration) {
+	b.cooldown = cooldown
+}
+
+// notifyStateChange calls the breaker's callback and the registered hooks
+func (b *Breaker) notifyStateChange(fromState, toState State, reason string) {
+	if b.onStateChange != nil {
+		b.onStateChange(fromState, toState, reason)
+	}
+	stateHooksMu.Lock()
+	hooks := append([]StateChangeCallback{}, stateHooks...)
+	stateHooksMu.Unlock()
+	for _, hook := range hooks {
+		hook(fromState, toState, reason)
+	}
+}
+
 // Initialize creates the state file if it doesn't exist
 func (b *Breaker) Initialize() error {
 	dir := filepath.Dir(b.stateFile)
@@ -90,15 +125,53 @@ func (b *Breaker) saveState(state *BreakerState) error {
 	return os.WriteFile(b.stateFile, data, 0644)
 }
 
-// CanExecute returns true if execution is allowed
+// CanExecute returns true if execution is allowed. An OPEN breaker whose
+// cooldown has passed moves to HALF_OPEN to probe one loop.
 func (b *Breaker) CanExecute() (bool, error) {
 	state, err := b.GetState()
 	if err != nil {
 		return false, err
 	}
+	if at, ok := b.recoveryAt(state); ok && !time.Now().Before(at) {
+		state.State = StateHalfOpen
+		state.Probing = true
+		state.Reason = fmt.Sprintf("Cooldown of %v passed, probing one loop", b.cooldown)
+		b.addHistory(&HistoryEntry{
+			Timestamp:  time.Now(),
+			LoopNumber: state.CurrentLoop,
+			FromState:  StateOpen,
+			ToState:    StateHalfOpen,
+			Reason:     state.Reason,
+		})
+		b.notifyStateChange(StateOpen, StateHalfOpen, state.Reason)
+		if err := b.saveState(state); err != nil {
+			return false, err
+		}
+	}
 	return state.State != StateOpen, nil
 }
 
+// RecoveryAt returns when an OPEN breaker will probe again, and false if it
+// is not OPEN or has no cooldown
+func (b *Breaker) RecoveryAt() (time.Time, bool) {
+	state, err := b.GetState()
+	if err != nil {
+		return time.Time{}, false
+	}
+	return b.recoveryAt(state)
+}
+
+func (b *Breaker) recoveryAt(state *BreakerState) (time.Time, bool) {
+	if state.State != StateOpen || b.cooldown <= 0 {
+		return time.Time{}, false
+	}
+	opened := state.OpenedAt
+	if opened.IsZero() {
+		opened = state.LastUpdated
+	}
+	return opened.Add(b.cooldown), true
+}
+
 // AddLoopResult records a loop result and updates state
 func (b *Breaker) AddLoopResult(hasProgress, hasError bool, loopNumber int) (bool, error) {
 	return b.AddLoopResultWithErrorLimit(hasProgress, hasError, loopNumber, 0)
@@ -110,7 +183,49 @@ func (b *Breaker) AddLoopResultWithErrorLimit(hasProgress, hasError bool, loopNu
 	if err != nil {
 		return false, err
 	}
+	return b.record(state, hasProgress, !hasProgress, hasError, loopNumber, maxConsecutiveErrors, "loops")
+}
+
+// AddTaskLoopResult records the result of a loop working on a task. With
+// maxTaskFailures set, loops without progress count against the task instead
+// of the run, so one stubborn task does not open the breaker: the run is only
+// charged once the task is blocked (see TaskBlocked), or if it fails more than
+// maxTaskFailures times in a row without being blocked.
+func (b *Breaker) AddTaskLoopResult(taskID string, hasProgress, hasError bool, loopNumber, maxConsecutiveErrors, maxTaskFailures int) (bool, error) {
+	if taskID == "" || maxTaskFailures <= 0 {
+		return b.AddLoopResultWithErrorLimit(hasProgress, hasError, loopNumber, maxConsecutiveErrors)
+	}
+	state, err := b.GetState()
+	if err != nil {
+		return false, err
+	}
+	if hasProgress {
+		delete(state.TaskFailures, taskID)
+		return b.record(state, true, false, hasError, loopNumber, maxConsecutiveErrors, "loops")
+	}
+	if state.TaskFailures == nil {
+		state.TaskFailures = make(map[string]int)
+	}
+	state.TaskFailures[taskID]++
+	exhausted := state.TaskFailures[taskID] > maxTaskFailures
+	return b.record(state, false, exhausted || state.Probing, hasError, loopNumber, maxConsecutiveErrors, "loops")
+}
 
+// TaskBlocked records that a task was blocked after failing repeatedly. It
+// counts as one loop without progress for the run, so the breaker still opens
+// when task after task gets blocked.
+func (b *Breaker) TaskBlocked(taskID string, loopNumber int) (bool, error) {
+	state, err := b.GetState()
+	if err != nil {
+		return false, err
+	}
+	delete(state.TaskFailures, taskID)
+	return b.record(state, false, true, false, loopNumber, 0, "blocked tasks")
+}
+
+// record applies a loop result to the state and saves it. noProgress counts
+// one unit, loops or blocked tasks, towards opening the breaker.
+func (b *Breaker) record(state *BreakerState, hasProgress, noProgress, hasError bool, loopNumber, maxConsecutiveErrors int, unit string) (bool, error) {
 	oldState := state.State
 	state.CurrentLoop = loopNumber
 
@@ -123,7 +238,13 @@ func (b *Breaker) AddLoopResultWithErrorLimit(hasProgress, hasError bool, loopNu
 			state.State = StateClosed
 			state.Reason = "Progress detected, circuit recovered"
 		}
-	} else {
+	} else if state.Probing && noProgress {
+		// The probe after a cooldown made no progress
+		state.ConsecutiveNoProgress++
+		state.State = StateOpen
+		state.TotalOpens++
+		state.Reason = "No progress in the probe after the cooldown, opening circuit"
+	} else if noProgress {
 		// No progress
 		state.ConsecutiveNoProgress++
 
@@ -131,12 +252,12 @@ func (b *Breaker) AddLoopResultWithErrorLimit(hasProgress, hasError bool, loopNu
 			if state.State != StateOpen {
 				state.State = StateOpen
 				state.TotalOpens++
-				state.Reason = fmt.Sprintf("No progress for %d loops, opening circuit", state.ConsecutiveNoProgress)
+				state.Reason = fmt.Sprintf("No progress for %d %s, opening circuit", state.ConsecutiveNoProgress, unit)
 			}
 		} else if state.ConsecutiveNoProgress >= HalfOpenThreshold {
 			if state.State == StateClosed {
 				state.State = StateHalfOpen
-				state.Reason = fmt.Sprintf("Monitoring: %d loops without progress", state.ConsecutiveNoProgress)
+				state.Reason = fmt.Sprintf("Monitoring: %d %s without progress", state.ConsecutiveNoProgress, unit)
 			}
 		}
 	}
@@ -154,6 +275,10 @@ func (b *Breaker) AddLoopResultWithErrorLimit(hasProgress, hasError bool, loopNu
 	} else {
 		state.ConsecutiveErrors = 0
 	}
+	state.Probing = false
+	if state.State == StateOpen && oldState != StateOpen {
+		state.OpenedAt = time.Now()
+	}
 
 	// Log state transition
 	if oldState != state.State {
@@ -166,10 +291,7 @@ func (b *Breaker) AddLoopResultWithErrorLimit(hasProgress, hasError bool, loopNu
 			Progress:   hasProgress,
 			HasError:   hasError,
 		})
-		// Call state change callback
-		if b.onStateChange != nil {
-			b.onStateChange(oldState, state.State, state.Reason)
-		}
+		b.notifyStateChange(oldState, state.State, state.Reason)
 	}
 
 	if err := b.saveState(state); err != nil {
@@ -197,15 +319,27 @@ func (b *Breaker) Reset(reason string) error {
 			ToState:   StateClosed,
 			Reason:    reason,
 		})
-		// Call state change callback
-		if b.onStateChange != nil {
-			b.onStateChange(oldState.State, StateClosed, reason)
-		}
+		b.notifyStateChange(oldState.State, StateClosed, reason)
 	}
 
 	return b.saveState(state)
 }
 
+// Restore writes a saved state back when the state on disk is older, e.g. when it
+// was lost or corrupted by a crash. A state changed since, for example by a
+// reset, is kept. It returns true if the saved state was restored.
+func (b *Breaker) Restore(saved *BreakerState) (bool, error) {
+	current, err := b.GetState()
+	if err != nil {
+		return false, err
+	}
+	if !current.LastUpdated.Before(saved.LastUpdated) {
+		return false, nil
+	}
+	restored := *saved
+	return true, b.saveState(&restored)
+}
+
 // ShouldHalt returns true if execution should stop
 func (b *Breaker) ShouldHalt() (bool, error) {
 	state, err := b.GetState()
@@ -225,9 +359,9 @@ func (b *Breaker) addHistory(entry *HistoryEntry) error {
 
 	history = append(history, *entry)
 
-	// Keep last 100 entries
-	if len(history) > 100 {
-		history = history[len(history)-100:]
+	// Keep a rolling window of the latest entries
+	if len(history) > MaxHistoryEntries {
+		history = history[len(history)-MaxHistoryEntries:]
 	}
 
 	data, err = json.MarshalIndent(history, "", "  ")
@@ -255,3 +389,19 @@ func (b *Breaker) GetHistory() ([]HistoryEntry, error) {
 
 	return history, nil
 }
+
+// RecentHistory returns the latest n state transitions, newest first
+func (b *Breaker) RecentHistory(n int) ([]HistoryEntry, error) {
+	history, err := b.GetHistory()
+	if err != nil {
+		return nil, err
+	}
+	if len(history) > n {
+		history = history[len(history)-n:]
+	}
+	recent := make([]HistoryEntry, len(history))
+	for i, e := range history {
+		recent[len(history)-1-i] = e
+	}
+	return recent, nil
+}
@@ -3,6 +3,7 @@ package circuit
 import (
 	"os"
 	"testing"
+	"time"
 )
 
 func setupTestDir(t *testing.T) (string, func()) {
@@ -223,6 +224,28 @@ func TestHistory(t *testing.T) {
 	if history[1].FromState != StateHalfOpen || history[1].ToState != StateOpen {
 		t.Errorf("expected HALF_OPEN->OPEN, got %s->%s", history[1].FromState, history[1].ToState)
 	}
+	if history[1].LoopNumber != 3 || history[1].Reason == "" {
+		t.Errorf("expected loop and reason of the transition, got %+v", history[1])
+	}
+
+	// Recent history is newest first
+	recent, err := b.RecentHistory(1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(recent) != 1 || recent[0].ToState != StateOpen {
+		t.Errorf("expected latest transition only, got %+v", recent)
+	}
+
+	// The history keeps a rolling window
+	for i := 0; i < MaxHistoryEntries; i++ {
+		b.Reset("test")
+		b.AddLoopResult(false, false, 1)
+		b.AddLoopResult(false, false, 2)
+	}
+	if history, _ := b.GetHistory(); len(history) != MaxHistoryEntries {
+		t.Errorf("expected %d history entries, got %d", MaxHistoryEntries, len(history))
+	}
 }
 
 func TestErrorTracking(t *testing.T) {
@@ -319,3 +342,158 @@ func TestConsecutiveErrorLimit(t *testing.T) {
 		t.Errorf("expected ConsecutiveErrors = 3, got %d", state.ConsecutiveErrors)
 	}
 }
+
+func TestRestore(t *testing.T) {
+	tmpDir, cleanup := setupTestDir(t)
+	defer cleanup()
+
+	b := New(tmpDir)
+	b.Initialize()
+	b.AddLoopResult(false, false, 1)
+	b.AddLoopResult(false, false, 2)
+	saved, _ := b.GetState()
+
+	// A crash left a corrupted state file
+	os.WriteFile(b.stateFile, []byte("{"), 0644)
+	restored, err := b.Restore(saved)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !restored {
+		t.Fatal("expected the saved state to be restored")
+	}
+	state, _ := b.GetState()
+	if state.State != StateHalfOpen || state.ConsecutiveNoProgress != 2 || state.CurrentLoop != 2 {
+		t.Errorf("unexpected restored state: %+v", state)
+	}
+
+	// A reset after the interruption wins over the saved state
+	b.Reset("manual reset")
+	if restored, _ := b.Restore(saved); restored {
+		t.Error("expected a newer state to be kept")
+	}
+	if state, _ := b.GetState(); state.State != StateClosed {
+		t.Errorf("expected CLOSED after reset, got %s", state.State)
+	}
+}
+
+func TestOnStateChange(t *testing.T) {
+	tmpDir, cleanup := setupTestDir(t)
+	defer cleanup()
+
+	var changes []State
+	OnStateChange(func(fromState, toState State, reason string) {
+		changes = append(changes, toState)
+	})
+	defer func() { stateHooks = nil }()
+
+	b := New(tmpDir)
+	b.Initialize()
+	b.AddLoopResult(false, false, 1)
+	b.AddLoopResult(false, false, 2)
+	b.Reset("Manual reset")
+
+	if len(changes) != 2 || changes[0] != StateHalfOpen || changes[1] != StateClosed {
+		t.Errorf("expected HALF_OPEN then CLOSED, got %v", changes)
+	}
+}
+
+func TestCooldownRecovery(t *testing.T) {
+	tmpDir, cleanup := setupTestDir(t)
+	defer cleanup()
+
+	b := New(tmpDir)
+	b.Initialize()
+	for i := 1; i <= OpenThreshold; i++ {
+		b.AddLoopResult(false, false, i)
+	}
+	if _, ok := b.RecoveryAt(); ok {
+		t.Error("expected no recovery without a cooldown")
+	}
+
+	b.SetCooldown(time.Hour)
+	at, ok := b.RecoveryAt()
+	if !ok || time.Until(at) < 59*time.Minute {
+		t.Fatalf("expected recovery in an hour, got %v %v", at, ok)
+	}
+	if canExecute, _ := b.CanExecute(); canExecute {
+		t.Fatal("expected breaker to stay open during the cooldown")
+	}
+
+	// A probe without progress reopens the breaker at once
+	b.SetCooldown(time.Nanosecond)
+	if canExecute, _ := b.CanExecute(); !canExecute {
+		t.Fatal("expected breaker to probe after the cooldown")
+	}
+	state, _ := b.GetState()
+	if state.State != StateHalfOpen || !state.Probing {
+		t.Fatalf("expected probing HALF_OPEN state, got %+v", state)
+	}
+	if canExecute, _ := b.AddLoopResult(false, false, 4); canExecute {
+		t.Fatal("expected failed probe to reopen the breaker")
+	}
+	state, _ = b.GetState()
+	if state.TotalOpens != 2 || state.Probing {
+		t.Errorf("expected second open, got %+v", state)
+	}
+
+	// A probe with progress closes it
+	b.CanExecute()
+	if canExecute, _ := b.AddLoopResult(true, false, 5); !canExecute {
+		t.Fatal("expected successful probe to close the breaker")
+	}
+	state, _ = b.GetState()
+	if state.State != StateClosed || state.Probing {
+		t.Errorf("expected closed breaker, got %+v", state)
+	}
+}
+
+func TestTaskFailures(t *testing.T) {
+	tmpDir, cleanup := setupTestDir(t)
+	defer cleanup()
+
+	b := New(tmpDir)
+	b.Initialize()
+
+	// Failures of one task within its limit don't open the breaker
+	for i := 1; i <= 5; i++ {
+		if canExecute, _ := b.AddTaskLoopResult("T001", false, false, i, 0, 5); !canExecute {
+			t.Fatalf("expected breaker to stay closed after %d task failures", i)
+		}
+	}
+	state, _ := b.GetState()
+	if state.TaskFailures["T001"] != 5 || state.ConsecutiveNoProgress != 0 {
+		t.Fatalf("expected failures counted against the task, got %+v", state)
+	}
+
+	// Progress forgets the task's failures
+	b.AddTaskLoopResult("T001", true, false, 6, 0, 5)
+	state, _ = b.GetState()
+	if _, ok := state.TaskFailures["T001"]; ok {
+		t.Error("expected progress to clear the task's failures")
+	}
+
+	// Blocked tasks count as loops without progress for the run
+	for i, id := range []string{"T002", "T003"} {
+		b.AddTaskLoopResult(id, false, false, 7+i, 0, 5)
+		if canExecute, _ := b.TaskBlocked(id, 7+i); !canExecute {
+			t.Fatalf("expected breaker to stay open after blocking %s", id)
+		}
+	}
+	state, _ = b.GetState()
+	if state.State != StateHalfOpen || len(state.TaskFailures) != 0 {
+		t.Errorf("expected HALF_OPEN after two blocked tasks, got %+v", state)
+	}
+	if canExecute, _ := b.TaskBlocked("T004", 9); canExecute {
+		t.Error("expected breaker to open after three blocked tasks in a row")
+	}
+
+	// A task failing past its limit without being blocked is charged to the run
+	b.Reset("test")
+	for i := 1; i <= 8; i++ {
+		b.AddTaskLoopResult("T005", false, false, i, 0, 5)
+	}
+	if state, _ := b.GetState(); state.ConsecutiveNoProgress != 3 || state.State != StateOpen {
+		t.Errorf("expected failures past the limit to open the breaker, got %+v", state)
+	}
+}
@@ -92,3 +92,25 @@ func GetStateColor(state State) *color.Color {
 		return color.New(color.FgWhite)
 	}
 }
+
+// PrintHistory prints the latest n state transitions, newest first
+func (b *Breaker) PrintHistory(n int) error {
+	history, err := b.RecentHistory(n)
+	if err != nil {
+		return err
+	}
+
+	fmt.Println("Recent transitions:")
+	if len(history) == 0 {
+		fmt.Println("  none")
+		return nil
+	}
+	for _, e := range history {
+		fmt.Printf("  %s  loop %-4d  ", e.Timestamp.Format("2006-01-02 15:04:05"), e.LoopNumber)
+		GetStateColor(e.FromState).Printf("%-9s", e.FromState)
+		fmt.Print(" -> ")
+		GetStateColor(e.ToState).Printf("%-9s", e.ToState)
+		fmt.Printf("  %s\n", e.Reason)
+	}
+	return nil
+}
@@ -21,6 +21,10 @@ type BreakerState struct {
 	TotalOpens            int       `json:"totalOpens"`
 	LastUpdated           time.Time `json:"lastUpdated"`
 	Reason                string    `json:"reason"`
+	OpenedAt              time.Time `json:"openedAt,omitzero"` // When the breaker last opened
+	Probing               bool      `json:"probing,omitempty"` // HALF_OPEN after a cooldown, reopens unless the next loop makes progress
+	// Loops in a row without progress of each task, when failures are counted per task
+	TaskFailures map[string]int `json:"taskFailures,omitempty"`
 }
 
 // HistoryEntry records a state transition
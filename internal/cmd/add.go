@@ -5,13 +5,13 @@ import (
 	"fmt"
 	"os"
 	"path/filepath"
-	"strings"
 	"time"
 
 	"github.com/spf13/cobra"
 	"hermes/internal/ai"
 	"hermes/internal/analyzer"
 	"hermes/internal/config"
+	"hermes/internal/task"
 	"hermes/internal/ui"
 )
 
@@ -19,6 +19,7 @@ type addOptions struct {
 	dryRun  bool
 	timeout int
 	debug   bool
+	format  string
 }
 
 // NewAddCmd creates the add subcommand
@@ -31,7 +32,8 @@ func NewAddCmd() *cobra.Command {
 		Long:  "Add a new feature to the task plan using AI",
 		Example: `  hermes add "user authentication with JWT"
   hermes add "dark mode toggle" --dry-run
-  hermes add "API rate limiting"`,
+  hermes add "API rate limiting"
+  hermes add "CSV export" --format json`,
 		Args: cobra.ExactArgs(1),
 		RunE: func(cmd *cobra.Command, args []string) error {
 			return addExecute(args[0], opts)
@@ -41,13 +43,18 @@ func NewAddCmd() *cobra.Command {
 	cmd.Flags().BoolVar(&opts.dryRun, "dry-run", false, "Show output without writing")
 	cmd.Flags().IntVar(&opts.timeout, "timeout", 300, "Timeout in seconds")
 	cmd.Flags().BoolVar(&opts.debug, "debug", false, "Enable debug output")
+	cmd.Flags().StringVar(&opts.format, "format", formatMarkdown, "Output format requested from the AI (markdown, json)")
 
-	return cmd
+	return usesSecrets(cmd)
 }
 
 func addExecute(featureDesc string, opts *addOptions) error {
 	ctx := context.Background()
 
+	if err := checkPlanFormat(opts.format); err != nil {
+		return err
+	}
+
 	ui.PrintBanner(GetVersion())
 	ui.PrintHeader("Feature Add")
 
@@ -56,7 +63,7 @@ func addExecute(featureDesc string, opts *addOptions) error {
 	// Load config
 	cfg, err := config.Load(".")
 	if err != nil {
-		cfg = config.DefaultConfig()
+		return err
 	}
 
 	// Get next IDs
@@ -85,6 +92,9 @@ func addExecute(featureDesc string, opts *addOptions) error {
 
 	// Build prompt
 	prompt := buildAddPrompt(featureDesc, nextFeatureID, nextTaskID)
+	if opts.format == formatJSON {
+		prompt = buildAddJSONPrompt(featureDesc, nextFeatureID, nextTaskID)
+	}
 
 	// Execute with retry
 	result, err := ai.ExecuteWithRetry(ctx, provider, &ai.ExecuteOptions{
@@ -100,6 +110,10 @@ func addExecute(featureDesc string, opts *addOptions) error {
 		return fmt.Errorf("failed to add feature: %w", err)
 	}
 
+	if opts.format == formatJSON {
+		return addFromJSON(result.Output, nextFeatureID, opts.dryRun)
+	}
+
 	if opts.dryRun {
 		fmt.Println("\n--- DRY RUN OUTPUT ---")
 		fmt.Println(result.Output)
@@ -107,7 +121,8 @@ func addExecute(featureDesc string, opts *addOptions) error {
 	}
 
 	// Write task file
-	return writeFeatureFile(result.Output, nextFeatureID, featureDesc)
+	_, err = writeFeatureFile(result.Output, nextFeatureID, featureDesc)
+	return err
 }
 
 func buildAddPrompt(desc string, featureID, taskID int) string {
@@ -149,6 +164,7 @@ Create the feature file with this EXACT format:
 **Status:** NOT_STARTED
 **Priority:** P2
 **Estimated Effort:** 1 day
+**PRD Section:** <PRD heading this task implements, only if a PRD section was given>
 
 #### Description
 
@@ -205,6 +221,7 @@ RULES:
 5. Do NOT use vague dependencies like "All backend features" or "Previous tasks"
 6. Success criteria must be specific and measurable
 7. Analyze the project structure to suggest correct file paths
+8. Omit the PRD Section line when the input is a plain description
 
 FILE CREATION RULES:
 - Create the feature file ONLY in .hermes/tasks/ directory
@@ -214,35 +231,38 @@ FILE CREATION RULES:
 Output only the markdown content, no additional explanation.`, desc, featureID, taskID, featureID, featureID, taskID, taskID+4, taskID, featureID)
 }
 
-func writeFeatureFile(output string, featureID int, desc string) error {
+func writeFeatureFile(output string, featureID int, desc string) (string, error) {
 	// Create tasks directory
 	tasksDir := filepath.Join(".hermes", "tasks")
 	if err := os.MkdirAll(tasksDir, 0755); err != nil {
-		return err
+		return "", err
 	}
 
-	// Generate filename
-	safeName := strings.ToLower(desc)
-	safeName = strings.ReplaceAll(safeName, " ", "-")
-	// Keep only alphanumeric and hyphens
-	var result strings.Builder
-	for _, r := range safeName {
-		if (r >= 'a' && r <= 'z') || (r >= '0' && r <= '9') || r == '-' {
-			result.WriteRune(r)
-		}
-	}
-	safeName = result.String()
-	if len(safeName) > 30 {
-		safeName = safeName[:30]
+	filePath := filepath.Join(tasksDir, task.FeatureFileName(featureID, desc))
+
+	if err := os.WriteFile(filePath, []byte(output), 0644); err != nil {
+		return "", err
 	}
 
-	fileName := fmt.Sprintf("%03d-%s.md", featureID, safeName)
-	filePath := filepath.Join(tasksDir, fileName)
+	fmt.Printf("Created: %s\n", filePath)
+	return filePath, nil
+}
 
-	if err := os.WriteFile(filePath, []byte(output), 0644); err != nil {
+// addFromJSON validates a JSON plan for a single feature and writes it as a
+// markdown feature file
+func addFromJSON(output string, featureID int, dryRun bool) error {
+	features, err := task.ParsePlanJSON(output, knownTaskIDs())
+	if err != nil {
 		return err
 	}
+	if len(features) != 1 || features[0].ID != fmt.Sprintf("F%03d", featureID) {
+		return fmt.Errorf("expected a single feature F%03d in the JSON plan", featureID)
+	}
 
-	fmt.Printf("Created: %s\n", filePath)
-	return nil
+	if dryRun {
+		fmt.Println("\n--- DRY RUN OUTPUT ---")
+		return printPlanJSON(features)
+	}
+	_, err = writePlanFeatures(features)
+	return err
 }
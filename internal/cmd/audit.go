@@ -0,0 +1,127 @@
+package cmd
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/fatih/color"
+	"github.com/spf13/cobra"
+	"hermes/internal/ai"
+	"hermes/internal/audit"
+	"hermes/internal/config"
+	"hermes/internal/ui"
+)
+
+// auditPreviewLines is how many lines of each prompt and response are shown without --full
+const auditPreviewLines = 15
+
+type auditShowOptions struct {
+	full bool
+	last int
+}
+
+// NewAuditCmd creates the audit command
+func NewAuditCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "audit",
+		Short: "Inspect the audit trail of AI executions",
+		Long: `Inspect the audit trail in .hermes/audit. With "audit": {"enabled": true} in
+.hermes/config.json, every prompt sent to an AI provider and the response
+received are appended there with the provider, model, cost and timestamps.`,
+	}
+	cmd.AddCommand(newAuditShowCmd())
+	return cmd
+}
+
+// newAuditShowCmd creates the audit show subcommand
+func newAuditShowCmd() *cobra.Command {
+	opts := &auditShowOptions{}
+
+	cmd := &cobra.Command{
+		Use:   "show <taskID>",
+		Short: "Show the prompts and responses of a task",
+		Example: `  hermes audit show T012
+  hermes audit show T012 --last 1 --full`,
+		Args:              cobra.ExactArgs(1),
+		ValidArgsFunction: completeTaskIDs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return auditShowExecute(strings.ToUpper(args[0]), opts)
+		},
+	}
+
+	cmd.Flags().BoolVar(&opts.full, "full", false, "Print prompts and responses in full")
+	cmd.Flags().IntVar(&opts.last, "last", 0, "Only show the last N executions")
+
+	return cmd
+}
+
+func auditShowExecute(taskID string, opts *auditShowOptions) error {
+	entries, err := audit.Read(".", taskID)
+	if err != nil {
+		return err
+	}
+	if len(entries) == 0 {
+		ui.PrintInfo(fmt.Sprintf("No audit entries for %s", taskID))
+		return nil
+	}
+	if opts.last > 0 && len(entries) > opts.last {
+		entries = entries[len(entries)-opts.last:]
+	}
+
+	ui.PrintHeader(fmt.Sprintf("Audit Trail: %s", taskID))
+	var totalCost float64
+	for i, e := range entries {
+		totalCost += e.Cost
+		model := e.Model
+		if model == "" {
+			model = "unknown model"
+		}
+		status := color.GreenString("ok")
+		if !e.Success {
+			status = color.RedString("failed")
+		}
+		fmt.Printf("\n#%d  %s  %s (%s)  %.1fs  $%.4f  %s\n", i+1,
+			e.Time.Format("2006-01-02 15:04:05"), e.Provider, model, e.Duration().Seconds(), e.Cost, status)
+		if e.TokensIn > 0 || e.TokensOut > 0 {
+			fmt.Printf("    Tokens: %d in, %d out\n", e.TokensIn, e.TokensOut)
+		}
+		if e.Error != "" {
+			color.Red("    Error: %s", e.Error)
+		}
+		printAuditText("Prompt", e.Prompt, opts.full)
+		printAuditText("Response", e.Response, opts.full)
+	}
+	fmt.Printf("\n%d executions, total cost $%.4f\n", len(entries), totalCost)
+	return nil
+}
+
+// printAuditText prints a prompt or response, shortened unless full is set
+func printAuditText(label, text string, full bool) {
+	color.Cyan("  %s:", label)
+	lines := strings.Split(strings.TrimRight(text, "\n"), "\n")
+	omitted := 0
+	if !full && len(lines) > auditPreviewLines {
+		omitted = len(lines) - auditPreviewLines
+		lines = lines[:auditPreviewLines]
+	}
+	for _, line := range lines {
+		fmt.Printf("    %s\n", line)
+	}
+	if omitted > 0 {
+		color.HiBlack("    ... %d more lines (use --full)", omitted)
+	}
+}
+
+// ConfigureAudit records every AI execution to the audit trail when
+// audit.enabled is set. Failures never stop an execution; they are logged.
+func ConfigureAudit() {
+	cfg, err := config.Load(".")
+	if err != nil || !cfg.Audit.Enabled {
+		return
+	}
+	log := audit.NewLog(".")
+	log.SetErrorHandler(func(err error) {
+		logBackgroundWarning("Failed to write audit entry: %v", err)
+	})
+	ai.OnExecution(log.Record)
+}
@@ -0,0 +1,161 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/spf13/cobra"
+	"hermes/internal/config"
+	"hermes/internal/git"
+	"hermes/internal/task"
+)
+
+type changelogOptions struct {
+	since   string
+	version string
+	write   bool
+	file    string
+}
+
+// NewChangelogCmd creates the changelog subcommand
+func NewChangelogCmd() *cobra.Command {
+	opts := &changelogOptions{}
+
+	cmd := &cobra.Command{
+		Use:   "changelog",
+		Short: "Build a changelog section from completed tasks",
+		Long: `Build a changelog section listing the tasks completed since the last tag,
+grouped by target version and feature. A completed task is listed when a commit
+after the tag mentions its ID, as task commits do; without a tag all completed
+tasks are listed. The section is printed, or with --write added to the top of
+the changelog file (git.changelog, default CHANGELOG.md).`,
+		Example: `  hermes changelog
+  hermes changelog --since v1.2.0 --version v1.3.0
+  hermes changelog --version v1.3.0 --write`,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return changelogExecute(opts)
+		},
+	}
+
+	cmd.Flags().StringVar(&opts.since, "since", "", "Tag or commit to list completed tasks after (default: latest tag)")
+	cmd.Flags().StringVar(&opts.version, "version", "Unreleased", "Heading of the changelog section")
+	cmd.Flags().BoolVar(&opts.write, "write", false, "Add the section to the changelog file instead of printing it")
+	cmd.Flags().StringVar(&opts.file, "file", "", "Changelog file to write (default: git.changelog or CHANGELOG.md)")
+
+	return cmd
+}
+
+func changelogExecute(opts *changelogOptions) error {
+	reader := task.NewReader(".")
+	if !reader.HasTasks() {
+		fmt.Println("No tasks found. Run 'hermes prd <file>' first.")
+		return nil
+	}
+	features, err := reader.GetAllFeatures()
+	if err != nil {
+		return err
+	}
+
+	var completed []string
+	for _, f := range features {
+		for _, t := range f.Tasks {
+			if t.Status == task.StatusCompleted {
+				completed = append(completed, t.ID)
+			}
+		}
+	}
+
+	since := opts.since
+	gitOps := git.New(".")
+	if since == "" && gitOps.IsRepository() {
+		since = gitOps.LatestTag()
+	}
+	var committed map[string]bool
+	if since != "" {
+		if committed, err = gitOps.TasksCommittedSince(since, completed); err != nil {
+			return err
+		}
+	}
+
+	notes := buildChangelogSection(features, func(t *task.Task) bool {
+		return t.Status == task.StatusCompleted && (committed == nil || committed[t.ID])
+	})
+	if notes == "" {
+		if since != "" {
+			fmt.Printf("No tasks completed since %s.\n", since)
+		} else {
+			fmt.Println("No completed tasks.")
+		}
+		return nil
+	}
+
+	date := time.Now().Format("2006-01-02")
+	if !opts.write {
+		fmt.Print(git.ChangelogEntry(opts.version, date, notes))
+		return nil
+	}
+
+	file := opts.file
+	if file == "" {
+		if cfg, err := config.Load("."); err == nil && cfg.Git.Changelog != "" {
+			file = cfg.Git.Changelog
+		} else {
+			file = "CHANGELOG.md"
+		}
+	}
+	previous, err := os.ReadFile(file)
+	if err != nil && !os.IsNotExist(err) {
+		return err
+	}
+	if err := os.WriteFile(file, []byte(git.PrependChangelog(string(previous), opts.version, date, notes)), 0644); err != nil {
+		return fmt.Errorf("failed to write %s: %w", file, err)
+	}
+	fmt.Printf("Added %s to %s\n", opts.version, file)
+	return nil
+}
+
+// buildChangelogSection lists the included tasks of each feature, grouped
+// under the features' target versions when they have any
+func buildChangelogSection(features []task.Feature, include func(*task.Task) bool) string {
+	var versions []string
+	byVersion := make(map[string][]string)
+	versioned := false
+	for _, f := range features {
+		var lines []string
+		for i := range f.Tasks {
+			if t := &f.Tasks[i]; include(t) {
+				lines = append(lines, fmt.Sprintf("- %s (%s)", t.Name, t.ID))
+			}
+		}
+		if len(lines) == 0 {
+			continue
+		}
+		version := git.FeatureTagName(f.TargetVersion)
+		if _, ok := byVersion[version]; !ok {
+			versions = append(versions, version)
+		}
+		versioned = versioned || version != ""
+		byVersion[version] = append(byVersion[version], fmt.Sprintf("%s: %s\n\n%s", f.ID, f.Name, strings.Join(lines, "\n")))
+	}
+
+	var sections []string
+	for _, version := range versions {
+		if !versioned {
+			for _, feature := range byVersion[version] {
+				sections = append(sections, "### "+feature)
+			}
+			continue
+		}
+		heading := version
+		if heading == "" {
+			heading = "Other"
+		}
+		sections = append(sections, "### "+heading)
+		for _, feature := range byVersion[version] {
+			sections = append(sections, "#### "+feature)
+		}
+	}
+	return strings.Join(sections, "\n\n")
+}
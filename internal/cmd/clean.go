@@ -0,0 +1,284 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/spf13/cobra"
+	"hermes/internal/git"
+	"hermes/internal/tasklog"
+)
+
+// tempPromptPatterns match the prompt files AI providers write to the temp directory
+var tempPromptPatterns = []string{"hermes-gemini-*.md", "hermes-droid-*.md"}
+
+type cleanOptions struct {
+	dryRun    bool
+	olderThan string
+}
+
+// cleanTarget is a leftover file, directory, worktree or branch to remove
+type cleanTarget struct {
+	kind   string
+	name   string
+	remove func() error
+}
+
+// NewCleanCmd creates the clean subcommand
+func NewCleanCmd() *cobra.Command {
+	opts := &cleanOptions{}
+
+	cmd := &cobra.Command{
+		Use:   "clean",
+		Short: "Remove leftovers of previous runs",
+		Long: `Remove leftovers of previous runs: git worktrees in .hermes/worktrees, task
+branches that are fully merged into the current branch, parallel log
+directories, rotated log files and prompt files AI providers left in the temp
+directory. Only items not modified within --older-than are removed; use
+--older-than 0 to remove everything.`,
+		Example: `  hermes clean --dry-run
+  hermes clean
+  hermes clean --older-than 7d
+  hermes clean --older-than 0`,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return cleanExecute(opts)
+		},
+	}
+
+	cmd.Flags().BoolVar(&opts.dryRun, "dry-run", false, "Show what would be removed without removing anything")
+	cmd.Flags().StringVar(&opts.olderThan, "older-than", "1d", "Only remove items older than this age (e.g. 12h, 7d, 0)")
+
+	return cmd
+}
+
+func cleanExecute(opts *cleanOptions) error {
+	age, err := parseAge(opts.olderThan)
+	if err != nil {
+		return err
+	}
+	cutoff := time.Now().Add(-age)
+
+	var targets []cleanTarget
+	gitOps := git.New(".")
+	if gitOps.IsRepository() {
+		worktrees, err := staleWorktrees(gitOps, ".", cutoff)
+		if err != nil {
+			return err
+		}
+		targets = append(targets, worktrees...)
+	}
+	targets = append(targets, staleLogs(".", cutoff)...)
+	targets = append(targets, staleTempFiles(os.TempDir(), cutoff)...)
+
+	var removed, failed int
+	run := func(targets []cleanTarget) {
+		for _, t := range targets {
+			if opts.dryRun {
+				fmt.Printf("Would remove %s %s\n", t.kind, t.name)
+				continue
+			}
+			if err := t.remove(); err != nil {
+				fmt.Printf("Failed to remove %s %s: %v\n", t.kind, t.name, err)
+				failed++
+				continue
+			}
+			fmt.Printf("Removed %s %s\n", t.kind, t.name)
+			removed++
+		}
+	}
+	run(targets)
+	count := len(targets)
+
+	// Branches are collected last: removing their worktrees above releases them
+	if gitOps.IsRepository() {
+		if !opts.dryRun {
+			gitOps.PruneWorktrees()
+		}
+		branches, err := staleBranches(gitOps, cutoff)
+		if err != nil {
+			return err
+		}
+		run(branches)
+		count += len(branches)
+	}
+
+	switch {
+	case count == 0:
+		fmt.Println("Nothing to clean.")
+	case opts.dryRun:
+		fmt.Printf("\n%d items would be removed. Run without --dry-run to remove them.\n", count)
+	case failed > 0:
+		fmt.Printf("\nRemoved %d items, %d failed.\n", removed, failed)
+	default:
+		fmt.Printf("\nRemoved %d items.\n", removed)
+	}
+	return nil
+}
+
+// parseAge parses an age such as "7d", "12h" or "0". Days are accepted in
+// addition to the units of time.ParseDuration.
+func parseAge(s string) (time.Duration, error) {
+	s = strings.TrimSpace(s)
+	if s == "0" {
+		return 0, nil
+	}
+	if days, ok := strings.CutSuffix(s, "d"); ok {
+		n, err := strconv.ParseFloat(days, 64)
+		if err != nil || n < 0 {
+			return 0, fmt.Errorf("invalid age %q", s)
+		}
+		return time.Duration(n * float64(24*time.Hour)), nil
+	}
+	d, err := time.ParseDuration(s)
+	if err != nil || d < 0 {
+		return 0, fmt.Errorf("invalid age %q (use e.g. 12h or 7d)", s)
+	}
+	return d, nil
+}
+
+// staleWorktrees returns hermes worktrees not modified since cutoff, including
+// directories in .hermes/worktrees that git no longer knows about
+func staleWorktrees(gitOps *git.Git, basePath string, cutoff time.Time) ([]cleanTarget, error) {
+	worktrees, err := gitOps.ListWorktrees()
+	if err != nil {
+		return nil, err
+	}
+	registered := make(map[string]bool)
+	var targets []cleanTarget
+	for _, wt := range worktrees {
+		if !strings.HasPrefix(filepath.Base(wt.Path), "wt-") {
+			continue
+		}
+		registered[filepath.Base(wt.Path)] = true
+		if !newestModTime(wt.Path).Before(cutoff) {
+			continue
+		}
+		path := wt.Path
+		targets = append(targets, cleanTarget{
+			kind:   "worktree",
+			name:   path,
+			remove: func() error { return gitOps.RemoveWorktree(path) },
+		})
+	}
+
+	dir := filepath.Join(basePath, ".hermes", "worktrees")
+	entries, _ := os.ReadDir(dir)
+	for _, e := range entries {
+		if !e.IsDir() || registered[e.Name()] {
+			continue
+		}
+		path := filepath.Join(dir, e.Name())
+		if !newestModTime(path).Before(cutoff) {
+			continue
+		}
+		targets = append(targets, cleanTarget{
+			kind:   "orphaned worktree",
+			name:   path,
+			remove: func() error { return os.RemoveAll(path) },
+		})
+	}
+	return targets, nil
+}
+
+// staleBranches returns task branches merged into the current branch whose last
+// commit is older than cutoff
+func staleBranches(gitOps *git.Git, cutoff time.Time) ([]cleanTarget, error) {
+	branches, err := gitOps.ListMergedBranches("task/*", "hermes/*")
+	if err != nil {
+		return nil, err
+	}
+	var targets []cleanTarget
+	for _, b := range branches {
+		if !b.CommitTime.Before(cutoff) {
+			continue
+		}
+		name := b.Name
+		targets = append(targets, cleanTarget{
+			kind:   "merged branch",
+			name:   name,
+			remove: func() error { return gitOps.DeleteBranch(name) },
+		})
+	}
+	return targets, nil
+}
+
+// staleLogs returns parallel log directories and rotated log files in
+// .hermes/logs not modified since cutoff. The active hermes.log is kept.
+func staleLogs(basePath string, cutoff time.Time) []cleanTarget {
+	dir := filepath.Join(basePath, ".hermes", "logs")
+	entries, _ := os.ReadDir(dir)
+	var targets []cleanTarget
+	for _, e := range entries {
+		path := filepath.Join(dir, e.Name())
+		var kind string
+		switch {
+		case e.IsDir() && strings.HasPrefix(e.Name(), "parallel"):
+			kind = "parallel logs"
+		case !e.IsDir() && strings.Contains(e.Name(), ".log."):
+			kind = "rotated log"
+		default:
+			continue
+		}
+		if !newestModTime(path).Before(cutoff) {
+			continue
+		}
+		targets = append(targets, cleanTarget{
+			kind:   kind,
+			name:   path,
+			remove: func() error { return os.RemoveAll(path) },
+		})
+	}
+
+	taskLogs, _ := filepath.Glob(filepath.Join(tasklog.Dir(basePath), "*.log"))
+	for _, path := range taskLogs {
+		if !newestModTime(path).Before(cutoff) {
+			continue
+		}
+		targets = append(targets, cleanTarget{
+			kind:   "task log",
+			name:   path,
+			remove: func() error { return os.Remove(path) },
+		})
+	}
+	return targets
+}
+
+// staleTempFiles returns prompt files in tmpDir not modified since cutoff
+func staleTempFiles(tmpDir string, cutoff time.Time) []cleanTarget {
+	var targets []cleanTarget
+	for _, pattern := range tempPromptPatterns {
+		matches, _ := filepath.Glob(filepath.Join(tmpDir, pattern))
+		for _, path := range matches {
+			if !newestModTime(path).Before(cutoff) {
+				continue
+			}
+			targets = append(targets, cleanTarget{
+				kind:   "temp prompt file",
+				name:   path,
+				remove: func() error { return os.Remove(path) },
+			})
+		}
+	}
+	return targets
+}
+
+// newestModTime returns the latest modification time of path and, for
+// directories, of everything below it. Appending to a log file does not touch
+// its directory, so the directory time alone would make active logs look stale.
+func newestModTime(path string) time.Time {
+	var newest time.Time
+	filepath.WalkDir(path, func(_ string, d os.DirEntry, err error) error {
+		if err != nil {
+			return nil
+		}
+		if info, err := d.Info(); err == nil && info.ModTime().After(newest) {
+			newest = info.ModTime()
+		}
+		return nil
+	})
+	return newest
+}
@@ -1,10 +1,26 @@
 package cmd
 
 import (
+	"context"
+	"errors"
 	"os"
 	"path/filepath"
 	"strings"
+	"sync/atomic"
 	"testing"
+	"time"
+
+	"github.com/fsnotify/fsnotify"
+	"github.com/spf13/cobra"
+	"hermes/internal/checkpoint"
+	"hermes/internal/circuit"
+	"hermes/internal/config"
+	"hermes/internal/git"
+	"hermes/internal/prd"
+	"hermes/internal/report"
+	"hermes/internal/scheduler"
+	"hermes/internal/task"
+	"hermes/internal/ui"
 )
 
 func TestCreateGitignore(t *testing.T) {
@@ -68,7 +84,10 @@ func TestCreateGitignoreAppend(t *testing.T) {
 
 func TestBuildPrdPrompt(t *testing.T) {
 	prdContent := "This is my PRD content"
-	prompt := buildPrdPrompt(prdContent)
+	prompt, err := buildPrdPrompt(t.TempDir(), prdContent)
+	if err != nil {
+		t.Fatal(err)
+	}
 
 	if !strings.Contains(prompt, prdContent) {
 		t.Error("expected prompt to contain PRD content")
@@ -81,6 +100,22 @@ func TestBuildPrdPrompt(t *testing.T) {
 	}
 }
 
+func TestBuildChangedSections(t *testing.T) {
+	preamble, sections := prd.Split("# Shop\n\nAn online shop.\n\n## Login\n\nUsers log in.\n\n## Cart\n\nUsers add items.\n", 0)
+	features := []task.Feature{{ID: "F001", Tasks: []task.Task{{ID: "T001", Name: "Login form", PRDSection: "Login"}}}}
+
+	content := buildChangedSections(preamble, sections[:1], features)
+	if !strings.Contains(content, "An online shop.") || !strings.Contains(content, "Users log in.") {
+		t.Errorf("expected the preamble and the changed section, got:\n%s", content)
+	}
+	if strings.Contains(content, "Users add items.") {
+		t.Errorf("expected unchanged sections to be left out, got:\n%s", content)
+	}
+	if !strings.Contains(content, "Tasks T001 were created") {
+		t.Errorf("expected the tasks traced to the section, got:\n%s", content)
+	}
+}
+
 func TestBuildAddPrompt(t *testing.T) {
 	prompt := buildAddPrompt("user authentication", 5, 42)
 
@@ -188,7 +223,7 @@ func TestWriteFeatureFile(t *testing.T) {
 	defer os.Chdir(oldWd)
 
 	output := "# Feature 5: User Auth\n**Feature ID:** F005"
-	err = writeFeatureFile(output, 5, "user authentication with jwt")
+	_, err = writeFeatureFile(output, 5, "user authentication with jwt")
 	if err != nil {
 		t.Fatal(err)
 	}
@@ -260,3 +295,438 @@ func TestWriteTaskFilesConsecutiveMarkers(t *testing.T) {
 		t.Error("expected second file to contain F002")
 	}
 }
+
+func TestIsWatchRelevant(t *testing.T) {
+	tasksDir := filepath.Join(".hermes", "tasks")
+	tests := []struct {
+		event    fsnotify.Event
+		expected bool
+	}{
+		{fsnotify.Event{Name: filepath.Join(tasksDir, "001-auth.md"), Op: fsnotify.Write}, true},
+		{fsnotify.Event{Name: filepath.Join(tasksDir, "002-api.md"), Op: fsnotify.Create}, true},
+		{fsnotify.Event{Name: filepath.Join(tasksDir, "003-jobs.yaml"), Op: fsnotify.Write}, true},
+		{fsnotify.Event{Name: filepath.Join(tasksDir, "status.log"), Op: fsnotify.Write}, true},
+		{fsnotify.Event{Name: filepath.Join(tasksDir, "001-auth.md"), Op: fsnotify.Chmod}, false},
+		{fsnotify.Event{Name: filepath.Join(tasksDir, "notes.txt"), Op: fsnotify.Write}, false},
+		{fsnotify.Event{Name: filepath.Join(".hermes", "circuit-state.json"), Op: fsnotify.Write}, true},
+		{fsnotify.Event{Name: filepath.Join(".hermes", "circuit-history.json"), Op: fsnotify.Write}, false},
+	}
+
+	for _, tt := range tests {
+		if got := isWatchRelevant(tt.event, tasksDir); got != tt.expected {
+			t.Errorf("isWatchRelevant(%s %s) = %v, want %v", tt.event.Op, tt.event.Name, got, tt.expected)
+		}
+	}
+}
+
+func TestWatchAfterRunLimit(t *testing.T) {
+	tmpDir := t.TempDir()
+	tasksDir := filepath.Join(tmpDir, ".hermes", "tasks")
+	os.MkdirAll(tasksDir, 0755)
+	os.WriteFile(filepath.Join(tasksDir, "001-auth.md"), []byte("# Feature 1: Auth\n\n**Feature ID:** F001\n**Status:** NOT_STARTED\n\n## Tasks\n\n### T001: Login endpoint\n\n**Status:** NOT_STARTED\n"), 0644)
+	breaker := circuit.New(tmpDir)
+	if err := breaker.Initialize(); err != nil {
+		t.Fatal(err)
+	}
+	logger, err := ui.NewLogger(tmpDir, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer logger.Close()
+	defer func(quiet time.Duration) { watchQuiet = quiet }(watchQuiet)
+	watchQuiet = 50 * time.Millisecond
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	events := make(chan fsnotify.Event, 10)
+	var runs atomic.Int32
+	w := &taskWatcher{ctx: ctx, reader: task.NewReader(tmpDir), breaker: breaker, logger: logger}
+	w.run = func() error {
+		// The run writes task statuses and stops on its budget
+		runs.Add(1)
+		events <- fsnotify.Event{Name: filepath.Join(tasksDir, "001-auth.md"), Op: fsnotify.Write}
+		events <- fsnotify.Event{Name: filepath.Join(tasksDir, task.StatusSidecarFile), Op: fsnotify.Write}
+		w.limited = true
+		return nil
+	}
+	done := make(chan error, 1)
+	go func() { done <- w.watch(events, nil, tasksDir, 10*time.Millisecond) }()
+
+	waitRuns := func(want int32) {
+		t.Helper()
+		deadline := time.Now().Add(2 * time.Second)
+		for runs.Load() < want && time.Now().Before(deadline) {
+			time.Sleep(10 * time.Millisecond)
+		}
+		time.Sleep(200 * time.Millisecond)
+		if got := runs.Load(); got != want {
+			t.Fatalf("expected %d runs, got %d", want, got)
+		}
+	}
+
+	// The writes of the run and a change of the breaker state start no run
+	waitRuns(1)
+	events <- fsnotify.Event{Name: filepath.Join(tmpDir, ".hermes", "circuit-state.json"), Op: fsnotify.Write}
+	waitRuns(1)
+
+	// A task file edited after the run starts the next one
+	events <- fsnotify.Event{Name: filepath.Join(tasksDir, "001-auth.md"), Op: fsnotify.Write}
+	waitRuns(2)
+
+	cancel()
+	if err := <-done; err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestBuildReplayPrompt(t *testing.T) {
+	attempt := &report.Attempt{
+		TaskID:  "T001",
+		Outcome: report.OutcomeFailed,
+		Error:   "go test failed",
+		Prompt:  "Implement the API\n",
+		Output:  strings.Repeat("x", replayOutputTail+100) + "END",
+	}
+
+	prompt := buildReplayPrompt(attempt)
+	if !strings.HasPrefix(prompt, "Implement the API\n\n## Previous Attempt") {
+		t.Errorf("expected original prompt first, got %q", prompt[:40])
+	}
+	if !strings.Contains(prompt, "go test failed") || !strings.Contains(prompt, "FAILED") {
+		t.Error("expected error and outcome in replay prompt")
+	}
+	if !strings.Contains(prompt, "END") || strings.Count(prompt, "x") > replayOutputTail {
+		t.Error("expected only the tail of the previous output")
+	}
+}
+
+func TestNormalizeTaskID(t *testing.T) {
+	for in, want := range map[string]string{"5": "T005", "t012": "T012", "T100": "T100"} {
+		if got := normalizeTaskID(in); got != want {
+			t.Errorf("normalizeTaskID(%q) = %q, want %q", in, got, want)
+		}
+	}
+}
+
+func TestBuildSplitTasks(t *testing.T) {
+	original := &task.Task{ID: "T003", FeatureID: "F001", Priority: task.PriorityP1, Dependencies: []string{"T001"}}
+	generated := []task.Task{
+		{ID: "T010", Name: "Model", Dependencies: []string{"T001", "T999"}},
+		{ID: "T011", Name: "Endpoint", Dependencies: []string{"T010", "T012"}},
+		{ID: "T012", Name: "Docs"},
+	}
+
+	parts, err := buildSplitTasks(original, generated, 20)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := map[string]string{"T020": "T001", "T021": "T020", "T022": "T001"}
+	for _, p := range parts {
+		if got := strings.Join(p.Dependencies, ","); got != want[p.ID] {
+			t.Errorf("%s: expected dependencies %s, got %s", p.ID, want[p.ID], got)
+		}
+		if p.FeatureID != "F001" || p.Priority != task.PriorityP1 || p.Status != task.StatusNotStarted {
+			t.Errorf("%s: unexpected metadata %+v", p.ID, p)
+		}
+	}
+	if sinks := strings.Join(splitSinks(parts), ","); sinks != "T021,T022" {
+		t.Errorf("expected sinks T021,T022, got %s", sinks)
+	}
+
+	if _, err := buildSplitTasks(original, generated[:1], 20); err == nil {
+		t.Error("expected error for a single generated task")
+	}
+
+	tasks := []task.Task{
+		{ID: "T003"},
+		{ID: "T004", Dependencies: []string{"T002", "T003"}},
+		{ID: "T005", Dependencies: []string{"T002"}},
+	}
+	dependents := rewireDependents(tasks, "T003", []string{"T021", "T022"})
+	if len(dependents) != 1 || strings.Join(dependents[0].Dependencies, ",") != "T002,T021,T022" {
+		t.Errorf("unexpected dependents: %+v", dependents)
+	}
+}
+
+func TestBuildExplainContext(t *testing.T) {
+	tmpDir := t.TempDir()
+	tasksDir := filepath.Join(tmpDir, ".hermes", "tasks")
+	os.MkdirAll(tasksDir, 0755)
+	os.WriteFile(filepath.Join(tasksDir, "001-auth.md"), []byte(`# Feature 1: Auth
+
+**Feature ID:** F001
+**Status:** IN_PROGRESS
+
+## Overview
+
+Login and sessions.
+
+## Tasks
+
+### T001: Login endpoint
+
+**Status:** BLOCKED
+**Priority:** P1
+**Files to Touch:** api/login.go
+
+#### Description
+
+Add login.
+
+### T002: Sessions
+
+**Status:** NOT_STARTED
+**Dependencies:** T001
+`), 0644)
+	report.SaveAttempt(tmpDir, &report.Attempt{TaskID: "T001", Outcome: report.OutcomeBlocked, Error: "tests failed", Output: "FAIL TestLogin"})
+
+	taskIDs, featureIDs := explainMentions("why did t1 and T001 fail in f1?")
+	if strings.Join(taskIDs, ",") != "T001" || strings.Join(featureIDs, ",") != "F001" {
+		t.Fatalf("unexpected mentions %v %v", taskIDs, featureIDs)
+	}
+
+	got, err := buildExplainContext(tmpDir, task.NewReader(tmpDir), "why did T001 fail?", &explainOptions{runs: 3, logLines: 10})
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, want := range []string{
+		"0/2 tasks completed",
+		"- T002: Sessions [NOT_STARTED, P2] depends on T001",
+		"## Task T001",
+		"- api/login.go (missing)",
+		"Error: tests failed",
+		"FAIL TestLogin",
+	} {
+		if !strings.Contains(got, want) {
+			t.Errorf("expected context to contain %q", want)
+		}
+	}
+	if strings.Contains(got, "## Task T002") {
+		t.Error("expected only mentioned tasks in full")
+	}
+}
+
+func TestCompleteTaskIDs(t *testing.T) {
+	tmpDir := t.TempDir()
+	oldWd, _ := os.Getwd()
+	os.Chdir(tmpDir)
+	defer os.Chdir(oldWd)
+
+	tasksDir := filepath.Join(tmpDir, ".hermes", "tasks")
+	os.MkdirAll(tasksDir, 0755)
+	os.WriteFile(filepath.Join(tasksDir, "001-auth.md"), []byte(`# Feature 1: Auth
+
+**Feature ID:** F001
+**Status:** IN_PROGRESS
+
+### T001: Login
+
+**Status:** COMPLETED
+
+### T012: Logout
+
+**Status:** NOT_STARTED
+`), 0644)
+
+	got, _ := completeTaskIDs(nil, nil, "t01")
+	if len(got) != 1 || got[0] != "T012\tLogout" {
+		t.Errorf("expected T012 completion, got %v", got)
+	}
+	if got, _ := completeTaskIDs(nil, []string{"T001"}, ""); len(got) != 0 {
+		t.Errorf("expected no completions after the first argument, got %v", got)
+	}
+
+	got, _ = completeTaskIDFlag(nil, nil, "T001,T0")
+	if len(got) != 2 || got[1] != "T001,T012\tLogout" {
+		t.Errorf("expected comma-separated completions, got %v", got)
+	}
+
+	if got, _ := completeArchivableFeatureIDs(nil, nil, ""); len(got) != 0 {
+		t.Errorf("expected no archivable features, got %v", got)
+	}
+	if got, _ := completeFeatureIDs(nil, nil, "F"); len(got) != 1 || got[0] != "F001\tAuth" {
+		t.Errorf("expected F001 completion, got %v", got)
+	}
+}
+
+func TestStaleLogs(t *testing.T) {
+	tmpDir := t.TempDir()
+	logsDir := filepath.Join(tmpDir, ".hermes", "logs")
+	os.MkdirAll(filepath.Join(logsDir, "parallel"), 0755)
+	os.WriteFile(filepath.Join(logsDir, "hermes.log"), []byte("active"), 0644)
+	os.WriteFile(filepath.Join(logsDir, "hermes.log.1"), []byte("rotated"), 0644)
+	os.WriteFile(filepath.Join(logsDir, "parallel", "worker-1.log"), []byte("worker"), 0644)
+
+	old := time.Now().Add(-48 * time.Hour)
+	os.Chtimes(filepath.Join(logsDir, "hermes.log.1"), old, old)
+	os.Chtimes(filepath.Join(logsDir, "parallel"), old, old)
+
+	age, err := parseAge("1d")
+	if err != nil || age != 24*time.Hour {
+		t.Fatalf("expected 24h, got %v (%v)", age, err)
+	}
+	if _, err := parseAge("soon"); err == nil {
+		t.Error("expected an error for an invalid age")
+	}
+
+	// The parallel directory is old, but a worker log in it was just written
+	targets := staleLogs(tmpDir, time.Now().Add(-age))
+	if len(targets) != 1 || targets[0].kind != "rotated log" {
+		t.Fatalf("expected only the rotated log, got %+v", targets)
+	}
+	if err := targets[0].remove(); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := os.Stat(filepath.Join(logsDir, "hermes.log")); err != nil {
+		t.Error("expected the active log to be kept")
+	}
+
+	if targets := staleLogs(tmpDir, time.Now().Add(time.Minute)); len(targets) != 1 || targets[0].kind != "parallel logs" {
+		t.Errorf("expected the parallel logs with age 0, got %+v", targets)
+	}
+}
+
+func TestResumeRunOptions(t *testing.T) {
+	cfg := config.DefaultConfig()
+	cp := &checkpoint.Checkpoint{
+		Mode:     checkpoint.ModeSequential,
+		Provider: "droid",
+		Options:  checkpoint.Options{AutoCommit: true, Autonomous: true},
+	}
+
+	opts := resumeRunOptions(cfg, cp, "", true)
+	if opts.aiProvider != "droid" || !opts.autoCommit || opts.autoBranch || !opts.autonomous || !opts.debug || opts.parallel {
+		t.Errorf("expected the options of the interrupted run, got %+v", opts)
+	}
+	if opts.workers != cfg.Parallel.MaxWorkers {
+		t.Errorf("expected the configured workers, got %d", opts.workers)
+	}
+
+	cp.Mode, cp.Options.Workers, cp.Options.Until = checkpoint.ModeParallel, 5, "v1.0.0"
+	opts = resumeRunOptions(cfg, cp, "gemini", false)
+	if opts.aiProvider != "gemini" || !opts.parallel || opts.workers != 5 || opts.until != "v1.0.0" {
+		t.Errorf("expected a parallel gemini run with 5 workers, got %+v", opts)
+	}
+}
+
+func TestBuildJSONPrompts(t *testing.T) {
+	prompt := buildPrdJSONPrompt("This is my PRD content")
+	if !strings.Contains(prompt, "This is my PRD content") || !strings.Contains(prompt, `"features"`) {
+		t.Error("expected prompt to contain the PRD and the JSON plan format")
+	}
+
+	prompt = buildAddJSONPrompt("user authentication", 5, 42)
+	if !strings.Contains(prompt, "user authentication") || !strings.Contains(prompt, `"id": "F005"`) || !strings.Contains(prompt, `"id": "T042"`) {
+		t.Error("expected prompt to contain the description and the next IDs")
+	}
+
+	if err := checkPlanFormat("xml"); err == nil {
+		t.Error("expected an unknown format to be rejected")
+	}
+}
+
+func TestBuildChangelogSection(t *testing.T) {
+	features := []task.Feature{
+		{ID: "F001", Name: "Orders", TargetVersion: "1.2.0", Tasks: []task.Task{
+			{ID: "T001", Name: "List orders", Status: task.StatusCompleted},
+			{ID: "T002", Name: "Cancel orders", Status: task.StatusNotStarted},
+		}},
+		{ID: "F002", Name: "Search", Tasks: []task.Task{{ID: "T010", Name: "Index orders", Status: task.StatusCompleted}}},
+		{ID: "F003", Name: "Billing", TargetVersion: "v1.2.0", Tasks: []task.Task{{ID: "T020", Name: "Send invoices", Status: task.StatusCompleted}}},
+	}
+	completed := func(t *task.Task) bool { return t.Status == task.StatusCompleted }
+
+	want := "### v1.2.0\n\n#### F001: Orders\n\n- List orders (T001)\n\n#### F003: Billing\n\n- Send invoices (T020)\n\n### Other\n\n#### F002: Search\n\n- Index orders (T010)"
+	if got := buildChangelogSection(features, completed); got != want {
+		t.Errorf("unexpected section:\n%s", got)
+	}
+
+	want = "### F002: Search\n\n- Index orders (T010)"
+	if got := buildChangelogSection(features[1:2], completed); got != want {
+		t.Errorf("expected features without versions under their own headings, got:\n%s", got)
+	}
+}
+
+func TestApplyConfigChanges(t *testing.T) {
+	cfg := config.DefaultConfig()
+	updated := config.DefaultConfig()
+	updated.AI.StreamOutput = !cfg.AI.StreamOutput
+	updated.Loop.MaxRunCost = 5
+	updated.Parallel.MaxWorkers = 8
+	updated.AI.Coding = "droid"
+
+	applied, ignored := applyConfigChanges(cfg, updated, sequentialReloadable)
+	if strings.Join(applied, ",") != "ai.streamOutput,loop.maxRunCost" {
+		t.Errorf("unexpected applied options %v", applied)
+	}
+	if strings.Join(ignored, ",") != "ai.coding,parallel.maxWorkers" {
+		t.Errorf("unexpected ignored options %v", ignored)
+	}
+	if cfg.AI.StreamOutput != updated.AI.StreamOutput || cfg.Loop.MaxRunCost != 5 {
+		t.Error("expected the reloadable options to be applied")
+	}
+	if cfg.Parallel.MaxWorkers == 8 || cfg.AI.Coding == "droid" {
+		t.Error("expected the other options to be kept")
+	}
+
+	if !isConfigFile(fsnotify.Event{Name: filepath.Join(".hermes", "config.yaml"), Op: fsnotify.Write}) {
+		t.Error("expected a write to config.yaml to reload the config")
+	}
+	if isConfigFile(fsnotify.Event{Name: filepath.Join(".hermes", "checkpoint.json"), Op: fsnotify.Write}) {
+		t.Error("expected other files not to reload the config")
+	}
+}
+
+func TestConfigureSandboxInvalidConfig(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+	t.Setenv("XDG_CONFIG_HOME", "")
+	tmpDir := t.TempDir()
+	oldWd, _ := os.Getwd()
+	os.Chdir(tmpDir)
+	defer os.Chdir(oldWd)
+
+	os.MkdirAll(filepath.Join(tmpDir, ".hermes"), 0755)
+	os.WriteFile(filepath.Join(tmpDir, ".hermes", "config.json"), []byte(`{"ai": {"sandbox": {"mode": "container", "image": "hermes-worker",}}}`), 0644)
+
+	if err := ConfigureSandbox(); err == nil {
+		t.Error("expected an unreadable config to fail instead of running unsandboxed")
+	}
+}
+
+func TestUsesSecrets(t *testing.T) {
+	if !UsesSecrets(NewRunCmd()) {
+		t.Error("expected run to resolve secrets")
+	}
+	jira := NewJiraCmd()
+	for _, sub := range jira.Commands() {
+		if !UsesSecrets(sub) {
+			t.Errorf("expected jira %s to resolve secrets", sub.Name())
+		}
+	}
+	for _, c := range []*cobra.Command{NewStatusCmd(), NewConfigCmd(), NewCompletionCmd()} {
+		if UsesSecrets(c) {
+			t.Errorf("expected %s not to resolve secrets", c.Name())
+		}
+	}
+}
+
+func TestRecordParallelRunCapturesAttempts(t *testing.T) {
+	tmpDir := t.TempDir()
+	oldWd, _ := os.Getwd()
+	os.Chdir(tmpDir)
+	defer os.Chdir(oldWd)
+
+	recorder := report.NewRecorder(tmpDir, "parallel", "claude")
+	result := &scheduler.ExecutionResult{Results: []*scheduler.TaskResult{
+		{TaskID: "T001", TaskName: "Add login", Success: false, Error: errors.New("tests failed"), Prompt: "do it", Output: "ran tests"},
+	}}
+	recordParallelRun(recorder, git.New(tmpDir), result, nil, "", nil)
+
+	attempt, err := report.LoadAttempt(tmpDir, "T001")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if attempt.Prompt != "do it" || attempt.Output != "ran tests" {
+		t.Errorf("expected the prompt and output of the attempt, got %+v", attempt)
+	}
+}
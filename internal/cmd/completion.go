@@ -0,0 +1,131 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/spf13/cobra"
+	"hermes/internal/task"
+)
+
+// NewCompletionCmd creates the completion subcommand
+func NewCompletionCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "completion bash|zsh|fish|powershell",
+		Short: "Generate shell completion scripts",
+		Long: `Generate a completion script for the given shell. Besides commands and flags,
+task and feature IDs are completed from the task files of the current project.`,
+		Example: `  source <(hermes completion bash)
+  hermes completion zsh > "${fpath[1]}/_hermes"
+  hermes completion fish > ~/.config/fish/completions/hermes.fish
+  hermes completion powershell | Out-String | Invoke-Expression`,
+		ValidArgs:             []string{"bash", "zsh", "fish", "powershell"},
+		Args:                  cobra.MatchAll(cobra.ExactArgs(1), cobra.OnlyValidArgs),
+		DisableFlagsInUseLine: true,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			root := cmd.Root()
+			switch args[0] {
+			case "bash":
+				return root.GenBashCompletionV2(os.Stdout, true)
+			case "zsh":
+				return root.GenZshCompletion(os.Stdout)
+			case "fish":
+				return root.GenFishCompletion(os.Stdout, true)
+			case "powershell":
+				return root.GenPowerShellCompletionWithDesc(os.Stdout)
+			}
+			return fmt.Errorf("unsupported shell: %s", args[0])
+		},
+	}
+	return cmd
+}
+
+// completeTaskIDs completes the first argument with the IDs of the project's tasks
+func completeTaskIDs(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+	if len(args) > 0 {
+		return nil, cobra.ShellCompDirectiveNoFileComp
+	}
+	return taskIDCompletions(toComplete), cobra.ShellCompDirectiveNoFileComp
+}
+
+// completeTaskIDFlag completes a comma-separated list of task IDs in a flag value
+func completeTaskIDFlag(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+	prefix := ""
+	if i := strings.LastIndex(toComplete, ","); i >= 0 {
+		prefix, toComplete = toComplete[:i+1], toComplete[i+1:]
+	}
+	var completions []string
+	for _, c := range taskIDCompletions(toComplete) {
+		completions = append(completions, prefix+c)
+	}
+	return completions, cobra.ShellCompDirectiveNoFileComp
+}
+
+// completeFeatureIDs completes the first argument with the IDs of the project's features
+func completeFeatureIDs(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+	if len(args) > 0 {
+		return nil, cobra.ShellCompDirectiveNoFileComp
+	}
+	return featureIDCompletions(toComplete, nil), cobra.ShellCompDirectiveNoFileComp
+}
+
+// completeArchivableFeatureIDs completes fully completed features not yet given as arguments
+func completeArchivableFeatureIDs(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+	return featureIDCompletions(toComplete, func(f *task.Feature) bool {
+		for _, arg := range args {
+			if normalizeFeatureID(arg) == f.ID {
+				return false
+			}
+		}
+		return f.IsArchivable()
+	}), cobra.ShellCompDirectiveNoFileComp
+}
+
+// taskIDCompletions returns "ID\tName" entries for tasks whose ID starts with prefix
+func taskIDCompletions(prefix string) []string {
+	tasks, err := task.NewReader(".").GetAllTasks()
+	if err != nil {
+		return nil
+	}
+	var completions []string
+	for _, t := range tasks {
+		if strings.HasPrefix(t.ID, strings.ToUpper(prefix)) {
+			completions = append(completions, t.ID+"\t"+t.Name)
+		}
+	}
+	return completions
+}
+
+// featureIDCompletions returns "ID\tName" entries for features whose ID starts with
+// prefix and that pass the optional filter
+func featureIDCompletions(prefix string, filter func(*task.Feature) bool) []string {
+	features, err := task.NewReader(".").GetAllFeatures()
+	if err != nil {
+		return nil
+	}
+	var completions []string
+	for i := range features {
+		f := &features[i]
+		if !strings.HasPrefix(f.ID, strings.ToUpper(prefix)) || (filter != nil && !filter(f)) {
+			continue
+		}
+		completions = append(completions, f.ID+"\t"+f.Name)
+	}
+	return completions
+}
+
+// completeTemplateNames completes task template names for the --template flag
+func completeTemplateNames(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+	templates, err := task.ListTemplates(".")
+	if err != nil {
+		return nil, cobra.ShellCompDirectiveNoFileComp
+	}
+	var names []string
+	for _, tmpl := range templates {
+		if strings.HasPrefix(tmpl.Name, toComplete) {
+			names = append(names, tmpl.Name)
+		}
+	}
+	return names, cobra.ShellCompDirectiveNoFileComp
+}
@@ -0,0 +1,58 @@
+package cmd
+
+import (
+	"fmt"
+
+	"github.com/fatih/color"
+	"github.com/spf13/cobra"
+	"hermes/internal/config"
+)
+
+// NewConfigCmd creates the config command
+func NewConfigCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "config",
+		Short: "Inspect the configuration",
+		// Only the profile is needed; loading the configuration for the
+		// integrations would report its problems before the validation does
+		PersistentPreRunE: func(c *cobra.Command, args []string) error {
+			profile, _ := c.Flags().GetString("profile")
+			return ConfigureProfile(profile)
+		},
+	}
+	cmd.AddCommand(newConfigValidateCmd())
+	return cmd
+}
+
+// newConfigValidateCmd creates the config validate subcommand
+func newConfigValidateCmd() *cobra.Command {
+	return &cobra.Command{
+		Use:   "validate",
+		Short: "Check the configuration for problems",
+		Long: `Check the user and project config files and the configuration they result
+in, with the selected profile and HERMES_* environment variables: syntax
+errors, unknown options (with the option probably meant), values of the wrong
+type, unknown choices such as a misspelled parallel.strategy and out-of-range
+numbers such as parallel.maxWorkers below 1. Other commands stop at a file
+that cannot be parsed or a value of the wrong type and report the other
+problems as warnings.`,
+		Example: `  hermes config validate
+  hermes config validate --profile ci`,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return configValidateExecute()
+		},
+	}
+}
+
+func configValidateExecute() error {
+	problems := config.Check(".")
+	for _, p := range problems {
+		color.Red("%s", p)
+	}
+	if len(problems) > 0 {
+		fmt.Printf("\n%d problems\n", len(problems))
+		return fmt.Errorf("configuration has problems")
+	}
+	color.Green("Configuration is valid")
+	return nil
+}
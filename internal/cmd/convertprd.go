@@ -51,7 +51,7 @@ func NewConvertPrdCmd() *cobra.Command {
 	cmd.Flags().IntVar(&opts.timeout, "timeout", 900, "AI timeout in seconds")
 	cmd.Flags().BoolVar(&opts.debug, "debug", false, "Enable debug output")
 
-	return cmd
+	return usesSecrets(cmd)
 }
 
 func convertPrdExecute(opts *convertPrdOptions) error {
@@ -77,10 +77,10 @@ func convertPrdExecute(opts *convertPrdOptions) error {
 	// Load config
 	cfg, err := config.Load(".")
 	if err != nil {
-		cfg = config.DefaultConfig()
+		return err
 	}
 
-	ctx, cancel := context.WithTimeout(context.Background(), time.Duration(cfg.AI.PrdTimeout)*time.Second)
+	ctx, cancel := context.WithTimeout(context.Background(), time.Duration(cfg.AI.PlanningTimeout)*time.Second)
 	defer cancel()
 
 	// Create logger
@@ -130,7 +130,7 @@ func convertPrdExecute(opts *convertPrdOptions) error {
 		Language:    opts.language,
 		Depth:       opts.depth,
 		ExcludeDirs: excludeDirs,
-		Timeout:     cfg.AI.PrdTimeout,
+		Timeout:     cfg.AI.PlanningTimeout,
 	})
 	if err != nil {
 		return err
@@ -0,0 +1,306 @@
+package cmd
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/spf13/cobra"
+	"hermes/internal/ai"
+	"hermes/internal/circuit"
+	"hermes/internal/config"
+	"hermes/internal/report"
+	"hermes/internal/task"
+)
+
+// explainOutputTail limits how much captured AI output per failed attempt is included
+const explainOutputTail = 3000
+
+var (
+	explainTaskIDRegex    = regexp.MustCompile(`(?i)\bT(\d+)\b`)
+	explainFeatureIDRegex = regexp.MustCompile(`(?i)\bF(\d+)\b`)
+)
+
+type explainOptions struct {
+	runs        int
+	logLines    int
+	showContext bool
+}
+
+// NewExplainCmd creates the explain subcommand
+func NewExplainCmd() *cobra.Command {
+	opts := &explainOptions{}
+
+	cmd := &cobra.Command{
+		Use:   "explain <question>",
+		Short: "Ask a question about the project state",
+		Long: `Answer a natural-language question about the project using the planning AI
+provider. Hermes assembles progress, the task backlog, recent runs, failed
+attempts, circuit breaker history and log lines; tasks and features mentioned
+in the question are included in full together with their files.`,
+		Example: `  hermes explain "why did T014 keep failing?"
+  hermes explain "what's left for v1.0?"
+  hermes explain "what is blocking F003?" --context`,
+		Args: cobra.MinimumNArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return explainExecute(strings.Join(args, " "), opts)
+		},
+	}
+
+	cmd.Flags().IntVar(&opts.runs, "runs", 3, "Number of recent runs to include")
+	cmd.Flags().IntVar(&opts.logLines, "log-lines", 40, "Number of recent log lines to include")
+	cmd.Flags().BoolVar(&opts.showContext, "context", false, "Print the assembled context without asking the AI")
+
+	return usesSecrets(cmd)
+}
+
+func explainExecute(question string, opts *explainOptions) error {
+	reader := task.NewReader(".")
+	if !reader.HasTasks() {
+		return fmt.Errorf("no tasks found, run 'hermes prd <file>' first")
+	}
+
+	projectContext, err := buildExplainContext(".", reader, question, opts)
+	if err != nil {
+		return err
+	}
+	if opts.showContext {
+		fmt.Println(projectContext)
+		return nil
+	}
+
+	cfg, err := config.Load(".")
+	if err != nil {
+		return err
+	}
+
+	var provider ai.Provider
+	if cfg.AI.Planning != "" && cfg.AI.Planning != "auto" {
+		provider = ai.GetProvider(cfg.AI.Planning)
+	}
+	if provider == nil || !provider.IsAvailable() {
+		provider = ai.AutoDetectProvider()
+	}
+	if provider == nil {
+		return fmt.Errorf("no AI provider available")
+	}
+	fmt.Printf("Asking %s...\n\n", provider.Name())
+
+	result, err := ai.ExecuteWithRetry(context.Background(), provider, &ai.ExecuteOptions{
+		Prompt:       buildExplainPrompt(question, projectContext),
+		Timeout:      cfg.AI.Timeout,
+		StreamOutput: cfg.AI.StreamOutput,
+	}, &ai.RetryConfig{
+		MaxRetries: cfg.AI.MaxRetries,
+		Delay:      time.Duration(cfg.AI.RetryDelay) * time.Second,
+	})
+	if err != nil {
+		return fmt.Errorf("failed to get an answer: %w", err)
+	}
+	if !cfg.AI.StreamOutput {
+		fmt.Println(strings.TrimSpace(result.Output))
+	}
+	return nil
+}
+
+// buildExplainContext assembles the project state relevant to a question
+func buildExplainContext(basePath string, reader *task.Reader, question string, opts *explainOptions) (string, error) {
+	features, err := reader.GetAllFeatures()
+	if err != nil {
+		return "", fmt.Errorf("failed to read tasks: %w", err)
+	}
+	progress, err := reader.GetProgress()
+	if err != nil {
+		return "", err
+	}
+
+	var sb strings.Builder
+
+	fmt.Fprintf(&sb, "## Progress\n\n%d/%d tasks completed (%.0f%%), %d in progress, %d not started, %d blocked\n\n",
+		progress.Completed, progress.Total, progress.Percentage, progress.InProgress, progress.NotStarted, progress.Blocked)
+
+	sb.WriteString("## Backlog\n\n")
+	for _, f := range features {
+		fmt.Fprintf(&sb, "### %s: %s (%s, %s", f.ID, f.Name, f.Status, f.Priority)
+		if f.TargetVersion != "" {
+			fmt.Fprintf(&sb, ", target %s", f.TargetVersion)
+		}
+		sb.WriteString(")\n\n")
+		for _, t := range f.Tasks {
+			fmt.Fprintf(&sb, "- %s: %s [%s, %s]", t.ID, t.Name, t.Status, t.Priority)
+			if len(t.Dependencies) > 0 {
+				fmt.Fprintf(&sb, " depends on %s", strings.Join(t.Dependencies, ", "))
+			}
+			sb.WriteString("\n")
+		}
+		sb.WriteString("\n")
+	}
+
+	taskIDs, featureIDs := explainMentions(question)
+	for _, f := range features {
+		for _, id := range featureIDs {
+			if f.ID == id {
+				fmt.Fprintf(&sb, "## Feature %s\n\n%s\n\n", f.ID, strings.TrimSpace(f.Overview))
+			}
+		}
+		for i := range f.Tasks {
+			for _, id := range taskIDs {
+				if f.Tasks[i].ID == id {
+					writeExplainTask(&sb, basePath, &f.Tasks[i])
+				}
+			}
+		}
+	}
+
+	if runs, _ := report.LoadRuns(basePath); len(runs) > 0 && opts.runs > 0 {
+		if len(runs) > opts.runs {
+			runs = runs[len(runs)-opts.runs:]
+		}
+		sb.WriteString("## Recent Runs\n\n")
+		for _, run := range runs {
+			s := run.Summarize()
+			fmt.Fprintf(&sb, "### %s (%s, %s)\n\n%d completed, %d failed, %d blocked, $%.4f\n\n",
+				run.ID, run.Mode, run.Provider, s.Completed, s.Failed, s.Blocked, s.TotalCost)
+			for _, t := range run.Tasks {
+				fmt.Fprintf(&sb, "- %s %s", t.TaskID, t.Outcome)
+				if t.Error != "" {
+					fmt.Fprintf(&sb, ": %s", t.Error)
+				}
+				sb.WriteString("\n")
+			}
+			for _, c := range run.Commits {
+				fmt.Fprintf(&sb, "- commit %s %s\n", c.Hash, c.Message)
+			}
+			sb.WriteString("\n")
+		}
+	}
+
+	breaker := circuit.New(basePath)
+	if state, _ := breaker.GetState(); state != nil {
+		fmt.Fprintf(&sb, "## Circuit Breaker\n\nState: %s", state.State)
+		if state.Reason != "" {
+			fmt.Fprintf(&sb, " (%s)", state.Reason)
+		}
+		fmt.Fprintf(&sb, ", opened %d times\n", state.TotalOpens)
+		if history, _ := breaker.GetHistory(); len(history) > 0 {
+			if len(history) > 5 {
+				history = history[len(history)-5:]
+			}
+			for _, e := range history {
+				fmt.Fprintf(&sb, "- %s: %s -> %s %s\n",
+					e.Timestamp.Format("2006-01-02 15:04"), e.FromState, e.ToState, e.Reason)
+			}
+		}
+		sb.WriteString("\n")
+	}
+
+	if lines := tailLines(filepath.Join(basePath, ".hermes", "logs", "hermes.log"), opts.logLines); len(lines) > 0 {
+		fmt.Fprintf(&sb, "## Recent Log\n\n```\n%s\n```\n", strings.Join(lines, "\n"))
+	}
+
+	return sb.String(), nil
+}
+
+// writeExplainTask writes the full details of a task mentioned in the question,
+// including its last failed attempt and the state of its files
+func writeExplainTask(sb *strings.Builder, basePath string, t *task.Task) {
+	fmt.Fprintf(sb, "## Task %s\n\n%s\n", t.ID, task.FormatTask(t))
+
+	if len(t.FilesToTouch) > 0 {
+		sb.WriteString("Files on disk:\n")
+		for _, f := range t.FilesToTouch {
+			fields := strings.Fields(f)
+			if len(fields) == 0 {
+				continue
+			}
+			path := strings.Trim(fields[0], "`")
+			state := "missing"
+			if _, err := os.Stat(filepath.Join(basePath, path)); err == nil {
+				state = "exists"
+			}
+			fmt.Fprintf(sb, "- %s (%s)\n", path, state)
+		}
+		sb.WriteString("\n")
+	}
+
+	if attempt, err := report.LoadAttempt(basePath, t.ID); err == nil && attempt != nil {
+		fmt.Fprintf(sb, "Last failed attempt (%s, %s):\n", attempt.Outcome, attempt.Time.Format("2006-01-02 15:04"))
+		if attempt.Error != "" {
+			fmt.Fprintf(sb, "Error: %s\n", attempt.Error)
+		}
+		output := attempt.Output
+		if len(output) > explainOutputTail {
+			output = "..." + output[len(output)-explainOutputTail:]
+		}
+		if output != "" {
+			fmt.Fprintf(sb, "```\n%s\n```\n", strings.TrimSpace(output))
+		}
+		sb.WriteString("\n")
+	}
+}
+
+// explainMentions extracts normalized task and feature IDs from a question
+func explainMentions(question string) (taskIDs, featureIDs []string) {
+	for _, m := range explainTaskIDRegex.FindAllStringSubmatch(question, -1) {
+		taskIDs = appendUnique(taskIDs, normalizeTaskID(m[1]))
+	}
+	for _, m := range explainFeatureIDRegex.FindAllStringSubmatch(question, -1) {
+		featureIDs = appendUnique(featureIDs, normalizeFeatureID(m[1]))
+	}
+	return taskIDs, featureIDs
+}
+
+func appendUnique(list []string, s string) []string {
+	for _, existing := range list {
+		if existing == s {
+			return list
+		}
+	}
+	return append(list, s)
+}
+
+// tailLines returns the last n lines of a file
+func tailLines(path string, n int) []string {
+	if n <= 0 {
+		return nil
+	}
+	file, err := os.Open(path)
+	if err != nil {
+		return nil
+	}
+	defer file.Close()
+
+	var lines []string
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		lines = append(lines, scanner.Text())
+		if len(lines) > n {
+			lines = lines[1:]
+		}
+	}
+	return lines
+}
+
+func buildExplainPrompt(question, projectContext string) string {
+	return fmt.Sprintf(`You are answering a question about a software project managed by Hermes,
+an autonomous agent that implements tasks from feature files in .hermes/tasks/.
+
+# Project State
+
+%s
+# Question
+
+%s
+
+RULES:
+1. Answer from the project state above; read source files in the repository when the question needs code context
+2. Cite task IDs, feature IDs, run IDs and file paths where relevant
+3. When asked about failures, name the most likely cause and a concrete next step
+4. Be concise and say so when the available information is not enough to answer
+5. Do NOT create or modify any files`, projectContext, question)
+}
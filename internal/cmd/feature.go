@@ -0,0 +1,101 @@
+package cmd
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/spf13/cobra"
+	"hermes/internal/task"
+)
+
+// NewFeatureCmd creates the feature command
+func NewFeatureCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "feature",
+		Short: "Manage feature files",
+	}
+	cmd.AddCommand(newFeatureArchiveCmd())
+	return cmd
+}
+
+// newFeatureArchiveCmd creates the feature archive subcommand
+func newFeatureArchiveCmd() *cobra.Command {
+	var dryRun bool
+
+	cmd := &cobra.Command{
+		Use:   "archive [feature-id...]",
+		Short: "Archive fully completed features",
+		Long: `Move fully completed feature files into .hermes/tasks/archive/ so they are no
+longer parsed during runs or listed on the Tasks screen. Without arguments all
+completed features are archived. Dependencies on archived tasks are removed
+from the remaining tasks; archived IDs are never reused.`,
+		Example: `  hermes feature archive
+  hermes feature archive F001 F003
+  hermes feature archive --dry-run`,
+		ValidArgsFunction: completeArchivableFeatureIDs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return featureArchiveExecute(args, dryRun)
+		},
+	}
+
+	cmd.Flags().BoolVar(&dryRun, "dry-run", false, "Show features that would be archived")
+
+	return cmd
+}
+
+func featureArchiveExecute(ids []string, dryRun bool) error {
+	features, err := task.NewReader(".").GetAllFeatures()
+	if err != nil {
+		return fmt.Errorf("failed to read tasks: %w", err)
+	}
+
+	var selected []task.Feature
+	if len(ids) == 0 {
+		for _, f := range features {
+			if f.IsArchivable() {
+				selected = append(selected, f)
+			}
+		}
+		if len(selected) == 0 {
+			fmt.Println("No completed features to archive.")
+			return nil
+		}
+	}
+	for _, id := range ids {
+		id = normalizeFeatureID(id)
+		var feature *task.Feature
+		for i := range features {
+			if features[i].ID == id {
+				feature = &features[i]
+			}
+		}
+		if feature == nil {
+			return fmt.Errorf("feature %s not found", id)
+		}
+		if !feature.IsArchivable() {
+			return fmt.Errorf("feature %s has unfinished tasks", id)
+		}
+		selected = append(selected, *feature)
+	}
+
+	for i := range selected {
+		f := &selected[i]
+		if dryRun {
+			fmt.Printf("Would archive %s: %s (%d tasks)\n", f.ID, f.Name, len(f.Tasks))
+			continue
+		}
+		updated, err := task.ArchiveFeature(".", f)
+		if err != nil {
+			return err
+		}
+		fmt.Printf("Archived %s: %s (%d tasks)\n", f.ID, f.Name, len(f.Tasks))
+		if len(updated) > 0 {
+			fmt.Printf("  Removed satisfied dependencies from %s\n", strings.Join(updated, ", "))
+		}
+	}
+
+	if !dryRun {
+		fmt.Printf("\nArchived features are in %s\n", task.GetArchiveDir("."))
+	}
+	return nil
+}
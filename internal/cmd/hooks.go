@@ -0,0 +1,35 @@
+package cmd
+
+import (
+	"context"
+	"io"
+	"time"
+
+	"hermes/internal/circuit"
+	"hermes/internal/config"
+	"hermes/internal/hooks"
+)
+
+// ConfigureHooks runs the breaker-open hooks of the config whenever a circuit
+// breaker opens, so a halted run is noticed without watching the terminal
+func ConfigureHooks() {
+	cfg, err := config.Load(".")
+	if err != nil || len(cfg.Hooks.BreakerOpen) == 0 {
+		return
+	}
+
+	commands := cfg.Hooks.BreakerOpen
+	timeout := time.Duration(cfg.Hooks.Timeout) * time.Second
+	circuit.OnStateChange(func(fromState, toState circuit.State, reason string) {
+		if toState != circuit.StateOpen {
+			return
+		}
+		env := []string{
+			"HERMES_BREAKER_FROM=" + string(fromState),
+			"HERMES_BREAKER_REASON=" + reason,
+		}
+		if err := hooks.Run(context.Background(), ".", hooks.BreakerOpen, commands, env, timeout, io.Discard); err != nil {
+			logBackgroundWarning("%v", err)
+		}
+	})
+}
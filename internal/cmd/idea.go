@@ -50,7 +50,7 @@ func NewIdeaCmd() *cobra.Command {
 	cmd.Flags().IntVar(&opts.timeout, "timeout", 600, "AI timeout in seconds")
 	cmd.Flags().BoolVar(&opts.debug, "debug", false, "Enable debug output")
 
-	return cmd
+	return usesSecrets(cmd)
 }
 
 func ideaExecute(ideaText string, opts *ideaOptions) error {
@@ -65,10 +65,10 @@ func ideaExecute(ideaText string, opts *ideaOptions) error {
 	// Load config
 	cfg, err := config.Load(".")
 	if err != nil {
-		cfg = config.DefaultConfig()
+		return err
 	}
 
-	ctx, cancel := context.WithTimeout(context.Background(), time.Duration(cfg.AI.PrdTimeout)*time.Second)
+	ctx, cancel := context.WithTimeout(context.Background(), time.Duration(cfg.AI.PlanningTimeout)*time.Second)
 	defer cancel()
 
 	// Create logger
@@ -122,7 +122,7 @@ func ideaExecute(ideaText string, opts *ideaOptions) error {
 		DryRun:            opts.dryRun,
 		Interactive:       opts.interactive,
 		Language:          opts.language,
-		Timeout:           cfg.AI.PrdTimeout,
+		Timeout:           cfg.AI.PlanningTimeout,
 		AdditionalContext: additionalContext,
 	})
 	if err != nil {
@@ -71,7 +71,7 @@ func initExecute(projectPath string) error {
 
 	// Create default config
 	configPath := filepath.Join(projectPath, ".hermes", "config.json")
-	if _, err := os.Stat(configPath); os.IsNotExist(err) {
+	if config.FindFile(filepath.Dir(configPath)) == "" {
 		cfg := config.DefaultConfig()
 		if err := config.Save(configPath, cfg); err != nil {
 			return err
@@ -0,0 +1,274 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/spf13/cobra"
+	"hermes/internal/analyzer"
+	"hermes/internal/config"
+	"hermes/internal/jira"
+	"hermes/internal/task"
+)
+
+type jiraImportOptions struct {
+	jql       string
+	featureID string
+	max       int
+	dryRun    bool
+}
+
+// NewJiraCmd creates the jira command
+func NewJiraCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "jira",
+		Short: "Synchronize tasks with Jira",
+		Long: `Import Jira issues as tasks and push task status changes back to Jira.
+
+Configure the "jira" section of .hermes/config.json and set JIRA_API_TOKEN,
+e.g. from a secret (see 'hermes secrets').
+With "enabled": true, every status change of an imported task (IN_PROGRESS,
+COMPLETED, BLOCKED) moves its issue through the matching Jira transition.`,
+	}
+	cmd.AddCommand(newJiraImportCmd())
+	cmd.AddCommand(newJiraPushCmd())
+	return usesSecrets(cmd)
+}
+
+// newJiraImportCmd creates the jira import subcommand
+func newJiraImportCmd() *cobra.Command {
+	opts := &jiraImportOptions{}
+
+	cmd := &cobra.Command{
+		Use:   "import",
+		Short: "Import Jira issues as tasks",
+		Long: `Import the issues matched by a JQL query as tasks. Without --feature a new
+feature file is created for them. Issues that were imported before are skipped,
+so the command can be run repeatedly to pick up new issues.`,
+		Example: `  hermes jira import
+  hermes jira import --jql "project = APP AND sprint in openSprints()"
+  hermes jira import --feature F003 --dry-run`,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return jiraImportExecute(opts)
+		},
+	}
+
+	cmd.Flags().StringVar(&opts.jql, "jql", "", "JQL query (default: jira.jql, or the open issues of jira.project)")
+	cmd.Flags().StringVar(&opts.featureID, "feature", "", "Add the tasks to an existing feature")
+	cmd.Flags().IntVar(&opts.max, "max", 50, "Maximum number of issues to import")
+	cmd.Flags().BoolVar(&opts.dryRun, "dry-run", false, "Show the issues that would be imported")
+	cmd.RegisterFlagCompletionFunc("feature", completeFeatureIDs)
+
+	return cmd
+}
+
+// newJiraPushCmd creates the jira push subcommand
+func newJiraPushCmd() *cobra.Command {
+	return &cobra.Command{
+		Use:   "push",
+		Short: "Push the status of all imported tasks to Jira",
+		Long: `Move the Jira issue of every imported task to the status matching the task,
+e.g. after status changes made while Jira sync was disabled.`,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return jiraPushExecute()
+		},
+	}
+}
+
+// newJiraClient creates a client from the project config
+func newJiraClient(cfg *config.Config) (*jira.Client, error) {
+	if cfg.Jira.URL == "" {
+		return nil, fmt.Errorf("jira.url is not set in .hermes/config.json")
+	}
+	token := os.Getenv("JIRA_API_TOKEN")
+	if token == "" {
+		return nil, fmt.Errorf("JIRA_API_TOKEN is not set")
+	}
+	return jira.NewClient(cfg.Jira.URL, cfg.Jira.Email, token), nil
+}
+
+func jiraImportExecute(opts *jiraImportOptions) error {
+	cfg, err := config.Load(".")
+	if err != nil {
+		return err
+	}
+	client, err := newJiraClient(cfg)
+	if err != nil {
+		return err
+	}
+
+	jql := opts.jql
+	if jql == "" {
+		jql = cfg.Jira.JQL
+	}
+	if jql == "" {
+		if cfg.Jira.Project == "" {
+			return fmt.Errorf("set --jql, jira.jql or jira.project")
+		}
+		jql = fmt.Sprintf("project = %q AND statusCategory != Done ORDER BY rank", cfg.Jira.Project)
+	}
+
+	issues, err := client.Search(jql, opts.max)
+	if err != nil {
+		return err
+	}
+
+	reader := task.NewReader(".")
+	existing, _ := reader.GetAllTasks()
+	archived, _ := reader.GetArchivedFeatures()
+	for _, f := range archived {
+		existing = append(existing, f.Tasks...)
+	}
+	imported := make(map[string]string)
+	for _, t := range existing {
+		if t.JiraKey != "" {
+			imported[t.JiraKey] = t.ID
+		}
+	}
+
+	var feature *task.Feature
+	if opts.featureID != "" {
+		if feature, err = reader.GetFeatureByID(normalizeFeatureID(opts.featureID)); err != nil || feature == nil {
+			return fmt.Errorf("feature %s not found", opts.featureID)
+		}
+	}
+
+	nextFeatureID, nextTaskID, err := analyzer.NewFeatureAnalyzer(".").GetNextIDs()
+	if err != nil {
+		nextFeatureID, nextTaskID = 1, 1
+	}
+	featureID := fmt.Sprintf("F%03d", nextFeatureID)
+	if feature != nil {
+		featureID = feature.ID
+	}
+
+	var tasks []*task.Task
+	for i := range issues {
+		if id, ok := imported[issues[i].Key]; ok {
+			fmt.Printf("Skipping %s, already imported as %s\n", issues[i].Key, id)
+			continue
+		}
+		t := jira.ToTask(&issues[i], fmt.Sprintf("T%03d", nextTaskID), featureID)
+		tasks = append(tasks, t)
+		nextTaskID++
+	}
+	if len(tasks) == 0 {
+		fmt.Println("No new issues to import.")
+		return nil
+	}
+
+	for _, t := range tasks {
+		fmt.Printf("%s  %-10s %-12s %s\n", t.ID, t.JiraKey, t.Status, t.Name)
+	}
+	if opts.dryRun {
+		fmt.Printf("\n%d issues would be imported into %s.\n", len(tasks), featureID)
+		return nil
+	}
+
+	filePath := ""
+	if feature != nil {
+		filePath = feature.FilePath
+	} else {
+		name := "Jira import"
+		if cfg.Jira.Project != "" {
+			name = "Jira " + cfg.Jira.Project + " import"
+		}
+		if filePath, err = writeJiraFeatureFile(nextFeatureID, name, jql); err != nil {
+			return err
+		}
+	}
+	for _, t := range tasks {
+		if err := task.AppendTask(filePath, t); err != nil {
+			return err
+		}
+	}
+
+	fmt.Printf("\nImported %d issues into %s (%s)\n", len(tasks), featureID, filePath)
+	return nil
+}
+
+// writeJiraFeatureFile creates an empty feature file for imported issues
+func writeJiraFeatureFile(featureID int, name, jql string) (string, error) {
+	tasksDir := filepath.Join(".hermes", "tasks")
+	if err := os.MkdirAll(tasksDir, 0755); err != nil {
+		return "", err
+	}
+
+	var sb strings.Builder
+	fmt.Fprintf(&sb, "# Feature %d: %s\n\n", featureID, name)
+	fmt.Fprintf(&sb, "**Feature ID:** F%03d\n", featureID)
+	sb.WriteString("**Priority:** P2 - HIGH\n")
+	sb.WriteString("**Status:** NOT_STARTED\n\n")
+	sb.WriteString("## Overview\n\n")
+	fmt.Fprintf(&sb, "Issues imported from Jira with: `%s`\n\n", jql)
+	sb.WriteString("## Tasks\n")
+
+	filePath := filepath.Join(tasksDir, task.FeatureFileName(featureID, name))
+	if _, err := os.Stat(filePath); err == nil {
+		return "", fmt.Errorf("%s already exists", filePath)
+	}
+	if err := os.WriteFile(filePath, []byte(sb.String()), 0644); err != nil {
+		return "", err
+	}
+	fmt.Printf("Created: %s\n", filePath)
+	return filePath, nil
+}
+
+func jiraPushExecute() error {
+	cfg, err := config.Load(".")
+	if err != nil {
+		return err
+	}
+	client, err := newJiraClient(cfg)
+	if err != nil {
+		return err
+	}
+	syncer := jira.NewSyncer(client, cfg.Jira.Transitions)
+
+	tasks, err := task.NewReader(".").GetAllTasks()
+	if err != nil {
+		return fmt.Errorf("failed to read tasks: %w", err)
+	}
+
+	var pushed, failed int
+	for i := range tasks {
+		t := &tasks[i]
+		if t.JiraKey == "" {
+			continue
+		}
+		if err := syncer.Push(t); err != nil {
+			fmt.Printf("%s: %v\n", t.ID, err)
+			failed++
+			continue
+		}
+		pushed++
+	}
+
+	fmt.Printf("Pushed the status of %d tasks", pushed)
+	if failed > 0 {
+		fmt.Printf(", %d failed", failed)
+	}
+	fmt.Println()
+	return nil
+}
+
+// ConfigureJiraSync mirrors task status changes to Jira when jira.enabled is
+// set. Failures never fail a status update; they are written to the log.
+func ConfigureJiraSync() {
+	cfg, err := config.Load(".")
+	if err != nil || !cfg.Jira.Enabled {
+		return
+	}
+	client, err := newJiraClient(cfg)
+	if err != nil {
+		return
+	}
+
+	syncer := jira.NewSyncer(client, cfg.Jira.Transitions)
+	syncer.SetErrorHandler(func(taskID string, err error) {
+		logBackgroundWarning("Jira sync of %s failed: %v", taskID, err)
+	})
+	task.OnStatusChange(syncer.HandleStatusChange)
+}
@@ -10,6 +10,8 @@ import (
 
 	"github.com/fatih/color"
 	"github.com/spf13/cobra"
+	"hermes/internal/config"
+	"hermes/internal/logfile"
 )
 
 // NewLogCmd creates the log command
@@ -81,7 +83,7 @@ func followLog(logPath string, level string) error {
 	if err != nil {
 		return err
 	}
-	defer file.Close()
+	defer func() { file.Close() }()
 
 	// Go to end of file
 	file.Seek(0, 2)
@@ -93,6 +95,13 @@ func followLog(logPath string, level string) error {
 	for {
 		line, err := reader.ReadString('\n')
 		if err != nil {
+			// Continue with the new file once the log was rotated
+			if rotated, reopened := reopenIfRotated(file, logPath); rotated {
+				file.Close()
+				file = reopened
+				reader = bufio.NewReader(file)
+				continue
+			}
 			time.Sleep(100 * time.Millisecond)
 			continue
 		}
@@ -117,3 +126,37 @@ func printColoredLine(line string) {
 		fmt.Println(line)
 	}
 }
+
+// reopenIfRotated opens the file at logPath from the start when it is no
+// longer the file being followed
+func reopenIfRotated(file *os.File, logPath string) (bool, *os.File) {
+	current, err := file.Stat()
+	if err != nil {
+		return false, nil
+	}
+	latest, err := os.Stat(logPath)
+	if err != nil || os.SameFile(current, latest) {
+		return false, nil
+	}
+	reopened, err := os.Open(logPath)
+	if err != nil {
+		return false, nil
+	}
+	return true, reopened
+}
+
+// ConfigureLogging applies the rotation and retention settings of the config
+// to every log file opened afterwards
+func ConfigureLogging() {
+	cfg, err := config.Load(".")
+	if err != nil {
+		return
+	}
+	logfile.SetDefaultPolicy(logfile.Policy{
+		MaxSize:    int64(cfg.Logging.MaxSizeMB) * 1024 * 1024,
+		Daily:      cfg.Logging.Daily,
+		MaxBackups: cfg.Logging.MaxFiles,
+		MaxAge:     time.Duration(cfg.Logging.MaxAgeDays) * 24 * time.Hour,
+		Compress:   cfg.Logging.Compress,
+	})
+}
@@ -0,0 +1,137 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"hermes/internal/task"
+)
+
+// Task output formats the AI can be asked for by 'hermes prd' and 'hermes add'
+const (
+	formatMarkdown = "markdown"
+	formatJSON     = "json"
+)
+
+// checkPlanFormat validates a --format value
+func checkPlanFormat(format string) error {
+	if format != formatMarkdown && format != formatJSON {
+		return fmt.Errorf("unknown format %q, use %s or %s", format, formatMarkdown, formatJSON)
+	}
+	return nil
+}
+
+// planJSONSchema describes the JSON plan format in prompts
+const planJSONSchema = `{
+  "features": [
+    {
+      "id": "F%03d",
+      "name": "Feature name",
+      "priority": "P1|P2|P3|P4",
+      "targetVersion": "v1.0.0",
+      "estimatedDuration": "1-2 weeks",
+      "overview": "2-3 paragraphs describing the feature",
+      "goals": ["Specific, measurable goal"],
+      "tasks": [
+        {
+          "id": "T%03d",
+          "name": "Task name",
+          "priority": "P1|P2|P3|P4",
+          "estimatedEffort": "1 day",
+          "prdSection": "PRD heading this task implements (optional)",
+          "description": "What this task accomplishes",
+          "technicalDetails": "Implementation notes",
+          "filesToTouch": ["path/to/file.go"],
+          "dependencies": ["T001"],
+          "successCriteria": ["Specific deliverable"]
+        }
+      ],
+      "performanceTarget": "Optional performance targets",
+      "riskAssessment": "Optional risks and mitigations"
+    }
+  ]
+}`
+
+// planJSONRules are the rules shared by the JSON prompts
+const planJSONRules = `RULES:
+1. Output ONLY one JSON document in exactly this shape, no markdown and no explanation
+2. Feature IDs look like F001, task IDs like T001, and every ID is unique
+3. Each task is atomic, testable and 0.5-2 days of work
+4. "dependencies" lists IDs of tasks that must be finished first, or is empty
+5. Success criteria must be specific and measurable
+6. Do NOT create or modify any files`
+
+func buildPrdJSONPrompt(prdContent string) string {
+	return fmt.Sprintf(`Parse this PRD into a task plan in JSON.
+
+Group the requirements into features, numbered from F001, and split each
+feature into tasks, numbered from T001 across all features.
+
+`+planJSONSchema+`
+
+`+planJSONRules+`
+
+PRD Content:
+
+%s`, 1, 1, prdContent)
+}
+
+func buildAddJSONPrompt(desc string, featureID, taskID int) string {
+	return fmt.Sprintf(`Create a task plan in JSON for this feature: %s
+
+Create exactly one feature with ID F%03d and 3-5 tasks numbered from T%03d.
+Analyze the project structure to suggest correct file paths.
+
+`+planJSONSchema+`
+
+`+planJSONRules+`
+7. Dependencies may also name existing task IDs of the project`, desc, featureID, taskID, featureID, taskID)
+}
+
+// printPlanJSON prints validated features as a JSON plan
+func printPlanJSON(features []task.Feature) error {
+	data, err := task.FormatPlanJSON(features)
+	if err != nil {
+		return err
+	}
+	fmt.Println(string(data))
+	return nil
+}
+
+// writePlanFeatures writes each feature of a JSON plan as a markdown feature file
+func writePlanFeatures(features []task.Feature) ([]string, error) {
+	tasksDir := filepath.Join(".hermes", "tasks")
+	if err := os.MkdirAll(tasksDir, 0755); err != nil {
+		return nil, err
+	}
+
+	var files []string
+	for i := range features {
+		f := &features[i]
+		filePath := filepath.Join(tasksDir, task.FeatureFileName(task.FeatureNumber(f.ID), f.Name))
+		if _, err := os.Stat(filePath); err == nil {
+			return files, fmt.Errorf("%s already exists", filePath)
+		}
+		if err := os.WriteFile(filePath, []byte(task.FormatFeature(f)), 0644); err != nil {
+			return files, err
+		}
+		fmt.Printf("Created: %s\n", filePath)
+		files = append(files, filePath)
+	}
+	return files, nil
+}
+
+// knownTaskIDs returns the IDs of the project's existing tasks, including archived ones
+func knownTaskIDs() map[string]bool {
+	reader := task.NewReader(".")
+	features, _ := reader.GetAllFeatures()
+	archived, _ := reader.GetArchivedFeatures()
+	known := make(map[string]bool)
+	for _, f := range append(features, archived...) {
+		for _, t := range f.Tasks {
+			known[t.ID] = true
+		}
+	}
+	return known
+}
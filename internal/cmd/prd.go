@@ -11,7 +11,11 @@ import (
 
 	"github.com/spf13/cobra"
 	"hermes/internal/ai"
+	"hermes/internal/analyzer"
 	"hermes/internal/config"
+	"hermes/internal/prd"
+	"hermes/internal/prompt"
+	"hermes/internal/task"
 	"hermes/internal/ui"
 )
 
@@ -20,6 +24,8 @@ type prdOptions struct {
 	timeout    int
 	maxRetries int
 	debug      bool
+	update     bool
+	format     string
 }
 
 // NewPrdCmd creates the prd subcommand
@@ -32,7 +38,10 @@ func NewPrdCmd() *cobra.Command {
 		Long:  "Parse a Product Requirements Document and generate task files",
 		Example: `  hermes prd docs/PRD.md
   hermes prd requirements.md --dry-run
-  hermes prd spec.md --timeout 1200`,
+  hermes prd spec.md --timeout 1200
+  hermes prd docs/PRD.md --update --dry-run
+  hermes prd docs/PRD.md --format json
+  hermes prd split docs/PRD.md`,
 		Args: cobra.ExactArgs(1),
 		RunE: func(cmd *cobra.Command, args []string) error {
 			return prdExecute(args[0], opts)
@@ -43,20 +52,31 @@ func NewPrdCmd() *cobra.Command {
 	cmd.Flags().IntVar(&opts.timeout, "timeout", 1200, "Timeout in seconds")
 	cmd.Flags().IntVar(&opts.maxRetries, "max-retries", 10, "Max retry attempts")
 	cmd.Flags().BoolVar(&opts.debug, "debug", false, "Enable debug output")
+	cmd.Flags().BoolVar(&opts.update, "update", false, "Re-parse the PRD sections changed since the last parse and only add new features and tasks")
+	cmd.Flags().StringVar(&opts.format, "format", formatMarkdown, "Output format requested from the AI (markdown, json)")
 
-	return cmd
+	cmd.AddCommand(newPrdSplitCmd())
+
+	return usesSecrets(cmd)
 }
 
 func prdExecute(prdFile string, opts *prdOptions) error {
 	ctx := context.Background()
 
+	if err := checkPlanFormat(opts.format); err != nil {
+		return err
+	}
+	if opts.format == formatJSON && opts.update {
+		return fmt.Errorf("--format json cannot be combined with --update")
+	}
+
 	ui.PrintBanner(GetVersion())
 	ui.PrintHeader("PRD Parser")
 
 	// Load config
 	cfg, err := config.Load(".")
 	if err != nil {
-		cfg = config.DefaultConfig()
+		return err
 	}
 
 	// Initialize logger
@@ -94,14 +114,23 @@ func prdExecute(prdFile string, opts *prdOptions) error {
 		logger.Info("Using AI provider: %s", provider.Name())
 	}
 
+	if opts.update {
+		return prdUpdate(ctx, cfg, provider, prdFile, string(prdContent), opts)
+	}
+
 	// Build prompt
-	prompt := buildPrdPrompt(string(prdContent))
+	var prompt string
+	if opts.format == formatJSON {
+		prompt = buildPrdJSONPrompt(string(prdContent))
+	} else if prompt, err = buildPrdPrompt(".", string(prdContent)); err != nil {
+		return err
+	}
 
 	// Execute with retry
 	startTime := time.Now()
 	result, err := ai.ExecuteWithRetry(ctx, provider, &ai.ExecuteOptions{
 		Prompt:       prompt,
-		Timeout:      cfg.AI.PrdTimeout,
+		Timeout:      cfg.AI.PlanningTimeout,
 		StreamOutput: cfg.AI.StreamOutput,
 	}, &ai.RetryConfig{
 		MaxRetries: cfg.AI.MaxRetries,
@@ -121,14 +150,26 @@ func prdExecute(prdFile string, opts *prdOptions) error {
 		logger.Success("PRD parsed successfully in %v", duration.Round(time.Second))
 	}
 
-	if opts.dryRun {
+	if opts.format == formatJSON {
+		features, err := task.ParsePlanJSON(result.Output, nil)
+		if err != nil {
+			if logger != nil {
+				logger.Error("Invalid JSON plan: %v", err)
+			}
+			return err
+		}
+		if opts.dryRun {
+			fmt.Println("\n--- DRY RUN OUTPUT ---")
+			return printPlanJSON(features)
+		}
+		if _, err := writePlanFeatures(features); err != nil {
+			return err
+		}
+	} else if opts.dryRun {
 		fmt.Println("\n--- DRY RUN OUTPUT ---")
 		fmt.Println(result.Output)
 		return nil
-	}
-
-	// Write task files
-	if err := writeTaskFiles(result.Output); err != nil {
+	} else if err := writeTaskFiles(result.Output); err != nil {
 		if logger != nil {
 			logger.Error("Failed to write task files: %v", err)
 		}
@@ -139,11 +180,159 @@ func prdExecute(prdFile string, opts *prdOptions) error {
 		logger.Success("Task files created successfully")
 	}
 
+	// Remember PRD sections so later runs with --update only re-parse changed ones
+	_, sections := prd.Split(string(prdContent), 0)
+	if err := prd.NewSnapshot(prdFile, sections).Save("."); err != nil && logger != nil {
+		logger.Warn("Failed to save PRD snapshot: %v", err)
+	}
+
 	return nil
 }
 
-func buildPrdPrompt(prdContent string) string {
-	return fmt.Sprintf(`Parse this PRD into comprehensive task files.
+// prdUpdate re-parses the PRD and merges the result into the existing task
+// files, adding only new features and tasks. When an earlier parse saved the
+// PRD's sections, only the sections changed since are re-parsed.
+func prdUpdate(ctx context.Context, cfg *config.Config, provider ai.Provider, prdFile, content string, opts *prdOptions) error {
+	reader := task.NewReader(".")
+	existing, err := reader.GetAllFeatures()
+	if err != nil {
+		return err
+	}
+	archived, _ := reader.GetArchivedFeatures()
+	existing = append(existing, archived...)
+	fmt.Printf("Updating %d existing features from %s\n", len(existing), prdFile)
+
+	snapshot, err := prd.LoadSnapshot(".")
+	if err != nil {
+		return err
+	}
+	level := 0
+	if snapshot != nil {
+		level = snapshot.Level
+	}
+	preamble, sections := prd.Split(content, level)
+	if snapshot != nil {
+		changed := changedSections(snapshot.Diff(sections), existing)
+		if len(changed) == 0 {
+			fmt.Println("\nNo changed sections to parse.")
+			return nil
+		}
+		content = buildChangedSections(preamble, changed, existing)
+	}
+
+	nextFeatureID, nextTaskID, err := analyzer.NewFeatureAnalyzer(".").GetNextIDs()
+	if err != nil {
+		nextFeatureID, nextTaskID = 1, 1
+	}
+
+	result, err := ai.ExecuteWithRetry(ctx, provider, &ai.ExecuteOptions{
+		Prompt:       buildPrdUpdatePrompt(content, existing, nextFeatureID, nextTaskID),
+		Timeout:      cfg.AI.PlanningTimeout,
+		StreamOutput: cfg.AI.StreamOutput,
+	}, &ai.RetryConfig{
+		MaxRetries: cfg.AI.MaxRetries,
+		Delay:      time.Duration(cfg.AI.RetryDelay) * time.Second,
+	})
+	if err != nil {
+		return fmt.Errorf("failed to parse PRD: %w", err)
+	}
+
+	contents := parseFileContents(result.Output)
+	if len(contents) == 0 {
+		return fmt.Errorf("AI output did not contain valid file markers (---FILE: ... ---END_FILE---). Please try again")
+	}
+
+	plan, err := task.PlanMerge(".", contents, nextFeatureID, nextTaskID)
+	if err != nil {
+		return err
+	}
+	printMergePlan(plan)
+
+	if opts.dryRun {
+		return nil
+	}
+	if !plan.IsEmpty() {
+		files, err := task.ApplyMerge(".", plan)
+		if err != nil {
+			return err
+		}
+		fmt.Printf("\nUpdated %d task files\n", len(files))
+	}
+
+	return prd.NewSnapshot(prdFile, sections).Save(".")
+}
+
+// printMergePlan prints the features and tasks a PRD update adds
+func printMergePlan(plan *task.MergePlan) {
+	fmt.Println()
+	for _, f := range plan.NewFeatures {
+		fmt.Printf("  new feature  %s: %s (%d tasks)\n", f.ID, f.Name, f.Tasks)
+	}
+	for _, p := range plan.NewTasks {
+		fmt.Printf("  new task     %s: %s -> %s\n", p.Task.ID, p.Task.Name, p.Feature.ID)
+	}
+	for _, w := range plan.Warnings {
+		fmt.Printf("  Warning: %s\n", w)
+	}
+	if plan.IsEmpty() {
+		fmt.Printf("No new features or tasks, %d existing tasks unchanged.\n", plan.Kept)
+	} else {
+		fmt.Printf("\n%d new features, %d new tasks, %d existing tasks unchanged\n", len(plan.NewFeatures), len(plan.NewTasks), plan.Kept)
+	}
+}
+
+// changedSections prints how each PRD section changed since the last parse,
+// with the tasks traced to it, and returns the new and changed sections
+func changedSections(changes []prd.SectionChange, features []task.Feature) []prd.Section {
+	var changed []prd.Section
+	for _, c := range changes {
+		fmt.Printf("  %-9s %s", c.Change, c.Section.Heading)
+		if ids := tracedTaskIDs(features, c.Section); len(ids) > 0 {
+			fmt.Printf(" (tasks: %s)", strings.Join(ids, ", "))
+		}
+		fmt.Println()
+		if c.Change == prd.ChangeNew || c.Change == prd.ChangeModified {
+			changed = append(changed, c.Section)
+		}
+	}
+	return changed
+}
+
+// tracedTaskIDs returns the tasks whose PRD Section points into the given section
+func tracedTaskIDs(features []task.Feature, section prd.Section) []string {
+	var ids []string
+	for _, f := range features {
+		for _, t := range f.Tasks {
+			if prd.MatchesSection(t.PRDSection, section) {
+				ids = append(ids, t.ID)
+			}
+		}
+	}
+	return ids
+}
+
+// buildChangedSections builds the PRD content of an update scoped to the
+// changed sections, naming the tasks already created from each
+func buildChangedSections(preamble string, changed []prd.Section, features []task.Feature) string {
+	var sb strings.Builder
+	if preamble != "" {
+		sb.WriteString(preamble)
+		sb.WriteString("\n\n")
+	}
+	for _, section := range changed {
+		sb.WriteString(section.Content)
+		sb.WriteString("\n")
+		if ids := tracedTaskIDs(features, section); len(ids) > 0 {
+			fmt.Fprintf(&sb, "\n(Tasks %s were created from an earlier version of this section; only add tasks for requirements they do not cover.)\n", strings.Join(ids, ", "))
+		}
+		sb.WriteString("\n")
+	}
+	return sb.String()
+}
+
+// prdPromptTemplate is the prompt parsing a PRD into Markdown task files when
+// the project has no .hermes/prompts/prd.tmpl
+const prdPromptTemplate = `Parse this PRD into comprehensive task files.
 
 For each feature, create a markdown file with this EXACT format:
 
@@ -178,6 +367,7 @@ For each feature, create a markdown file with this EXACT format:
 **Status:** NOT_STARTED
 **Priority:** P[1-4]
 **Estimated Effort:** X days
+**PRD Section:** [Exact PRD heading this task was derived from]
 
 #### Description
 
@@ -238,6 +428,7 @@ IMPORTANT RULES:
 6. Success criteria must be specific and measurable
 7. Technical details should guide implementation
 8. Priority levels: P1=Critical, P2=High, P3=Medium, P4=Low
+9. PRD Section must be the exact heading text of the PRD section the task was derived from
 
 FILE CREATION RULES:
 - Create task files ONLY in the .hermes/tasks/ directory
@@ -247,9 +438,109 @@ FILE CREATION RULES:
 
 PRD Content:
 
+{{.PRD}}
+
+Create each feature file directly in .hermes/tasks/ directory with proper naming (001-xxx.md, 002-xxx.md, etc).`
+
+// buildPrdPrompt renders the prompt parsing a PRD from the project's prd
+// template or prdPromptTemplate
+func buildPrdPrompt(basePath, prdContent string) (string, error) {
+	data, err := prompt.NewTemplateData(basePath, nil)
+	if err != nil {
+		return "", err
+	}
+	data.PRD = prdContent
+	return prompt.Render(basePath, prompt.PrdTemplate, prdPromptTemplate, data)
+}
+
+func buildPrdUpdatePrompt(prdContent string, existing []task.Feature, nextFeatureID, nextTaskID int) string {
+	var backlog strings.Builder
+	for _, f := range existing {
+		fmt.Fprintf(&backlog, "%s: %s\n", f.ID, f.Name)
+		for _, t := range f.Tasks {
+			fmt.Fprintf(&backlog, "  - %s: %s\n", t.ID, t.Name)
+		}
+	}
+
+	return fmt.Sprintf(`Parse this PRD into feature files. Task files already exist for an earlier
+version of this PRD; they are listed below and will be merged with your output.
+
+Existing features and tasks:
+
 %s
+MERGE RULES:
+1. Output every feature the PRD describes, including existing ones
+2. Reuse the EXACT names of existing features and tasks that still apply, so they can be matched
+3. Add new tasks for requirements the existing tasks do not cover
+4. Number new features from F%03d and new tasks from T%03d
+5. Dependencies may reference existing task IDs or new task IDs
+
+Use this format for each feature file:
+
+# Feature N: Feature Name
+
+**Feature ID:** FXXX
+**Priority:** P[1-4]
+**Status:** NOT_STARTED
+
+## Overview
 
-Create each feature file directly in .hermes/tasks/ directory with proper naming (001-xxx.md, 002-xxx.md, etc).`, prdContent)
+[Feature description]
+
+## Tasks
+
+### TXXX: Task Name
+
+**Status:** NOT_STARTED
+**Priority:** P[1-4]
+**Estimated Effort:** X days
+**PRD Section:** [Exact PRD heading this task was derived from]
+
+#### Description
+
+[Clear description of what this task accomplishes]
+
+#### Technical Details
+
+[Implementation notes]
+
+#### Files to Touch
+
+- `+"`path/to/file.go`"+` (new)
+
+#### Dependencies
+
+- TYYY or None
+
+#### Success Criteria
+
+- [ ] [Specific deliverable]
+
+---
+
+Do NOT create or modify any files. Output each feature in this format:
+---FILE: .hermes/tasks/001-feature-name.md---
+[content]
+---END_FILE---
+
+PRD Content:
+
+%s`, backlog.String(), nextFeatureID, nextTaskID, prdContent)
+}
+
+// parseFileContents returns the contents of the ---FILE: markers in AI output
+func parseFileContents(output string) []string {
+	fileRegex := regexp.MustCompile(`---FILE:\s*(.+?)---\s*([\s\S]*?)---END_FILE---`)
+	matches := fileRegex.FindAllStringSubmatch(output, -1)
+	if len(matches) == 0 {
+		matches = parseConsecutiveFileMarkers(output)
+	}
+
+	var contents []string
+	for _, match := range matches {
+		contents = append(contents, strings.TrimSpace(match[2]))
+	}
+	return contents
 }
 
 func writeTaskFiles(output string) error {
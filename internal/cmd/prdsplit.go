@@ -0,0 +1,249 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/spf13/cobra"
+	"hermes/internal/ai"
+	"hermes/internal/analyzer"
+	"hermes/internal/config"
+	"hermes/internal/prd"
+	"hermes/internal/task"
+	"hermes/internal/ui"
+)
+
+type prdSplitOptions struct {
+	level  int
+	parse  bool
+	dryRun bool
+}
+
+// newPrdSplitCmd creates the prd split subcommand
+func newPrdSplitCmd() *cobra.Command {
+	opts := &prdSplitOptions{}
+
+	cmd := &cobra.Command{
+		Use:   "split <file>",
+		Short: "Split a PRD into per-feature documents",
+		Long: `Split a monolithic PRD into per-feature sub-PRDs under .hermes/docs/features/.
+With --parse, task files are generated for new and changed sections only and
+linked back to their sub-PRD via a **Source:** line.`,
+		Example: `  hermes prd split docs/PRD.md
+  hermes prd split docs/PRD.md --level 3
+  hermes prd split docs/PRD.md --parse`,
+		Args: cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return prdSplitExecute(args[0], opts)
+		},
+	}
+
+	cmd.Flags().IntVar(&opts.level, "level", 0, "Heading level that starts a feature section (0 = auto-detect)")
+	cmd.Flags().BoolVar(&opts.parse, "parse", false, "Generate task files for new and changed sections")
+	cmd.Flags().BoolVar(&opts.dryRun, "dry-run", false, "Show sections without writing files")
+
+	return usesSecrets(cmd)
+}
+
+func prdSplitExecute(prdFile string, opts *prdSplitOptions) error {
+	ui.PrintBanner(GetVersion())
+	ui.PrintHeader("PRD Split")
+
+	content, err := os.ReadFile(prdFile)
+	if err != nil {
+		return fmt.Errorf("failed to read PRD: %w", err)
+	}
+
+	preamble, sections := prd.Split(string(content), opts.level)
+	if len(sections) == 0 {
+		return fmt.Errorf("no sections found in %s", prdFile)
+	}
+
+	manifest, err := prd.LoadManifest(".")
+	if err != nil {
+		return err
+	}
+	if manifest.Source != "" && filepath.Clean(manifest.Source) != filepath.Clean(prdFile) {
+		fmt.Printf("Warning: previous split was created from %s\n", manifest.Source)
+	}
+
+	changes := manifest.Diff(sections)
+	next := &prd.Manifest{Source: filepath.ToSlash(prdFile)}
+
+	fmt.Printf("PRD file: %s (%d sections)\n\n", prdFile, len(sections))
+	for _, c := range changes {
+		if c.Change == prd.ChangeRemoved {
+			fmt.Printf("  %-9s %s", c.Change, c.Section.Heading)
+			if c.Previous.FeatureID != "" {
+				fmt.Printf(" (feature %s left in place)", c.Previous.FeatureID)
+			}
+			fmt.Println()
+			continue
+		}
+
+		entry := prd.ManifestEntry{Heading: c.Section.Heading, Hash: c.Section.Hash}
+		if c.Previous != nil {
+			entry.FeatureID = c.Previous.FeatureID
+			entry.TaskFile = c.Previous.TaskFile
+		}
+
+		if !opts.dryRun {
+			path, err := prd.WriteSection(".", prdFile, preamble, c.Section)
+			if err != nil {
+				return err
+			}
+			entry.File = filepath.ToSlash(path)
+		}
+		next.Sections = append(next.Sections, entry)
+		fmt.Printf("  %-9s %02d %s\n", c.Change, c.Section.Index, c.Section.Heading)
+	}
+
+	if opts.dryRun {
+		return nil
+	}
+
+	// Remove sub-PRDs whose section was removed or renumbered
+	written := make(map[string]bool)
+	for _, e := range next.Sections {
+		written[e.File] = true
+	}
+	for _, e := range manifest.Sections {
+		if e.File != "" && !written[e.File] {
+			os.Remove(e.File)
+		}
+	}
+
+	fmt.Printf("\nWrote %d sub-PRDs to %s\n", len(next.Sections), prd.GetFeaturesDir("."))
+
+	if opts.parse {
+		if err := parseSplitSections(changes, next); err != nil {
+			next.Save(".")
+			return err
+		}
+	}
+
+	return next.Save(".")
+}
+
+// parseSplitSections generates task files for sections that are new, changed
+// or have never been parsed, leaving unchanged sections untouched
+func parseSplitSections(changes []prd.SectionChange, manifest *prd.Manifest) error {
+	ctx := context.Background()
+
+	cfg, err := config.Load(".")
+	if err != nil {
+		return err
+	}
+
+	var provider ai.Provider
+	if cfg.AI.Planning != "" && cfg.AI.Planning != "auto" {
+		provider = ai.GetProvider(cfg.AI.Planning)
+	}
+	if provider == nil || !provider.IsAvailable() {
+		provider = ai.AutoDetectProvider()
+	}
+	if provider == nil {
+		return fmt.Errorf("no AI provider available")
+	}
+	fmt.Printf("\nUsing AI: %s\n", provider.Name())
+
+	for i := range manifest.Sections {
+		entry := &manifest.Sections[i]
+		change := findSectionChange(changes, entry.Heading)
+		if change == prd.ChangeUnchanged && entry.TaskFile != "" {
+			continue
+		}
+
+		previous, err := reusableFeature(entry)
+		if err != nil {
+			fmt.Printf("Skipping %s: %v\n", entry.Heading, err)
+			continue
+		}
+
+		featureAnalyzer := analyzer.NewFeatureAnalyzer(".")
+		nextFeatureID, nextTaskID, err := featureAnalyzer.GetNextIDs()
+		if err != nil {
+			nextFeatureID, nextTaskID = 1, 1
+		}
+		featureNum := nextFeatureID
+		if previous != nil {
+			fmt.Sscanf(previous.ID, "F%d", &featureNum)
+		}
+
+		subPrd, err := os.ReadFile(entry.File)
+		if err != nil {
+			return err
+		}
+		desc := string(subPrd)
+		if previous != nil && len(previous.Tasks) > 0 {
+			desc += "\n\nTasks of the previous version of this section; reuse the EXACT names of those that still apply:\n\n"
+			for _, t := range previous.Tasks {
+				desc += fmt.Sprintf("- %s: %s\n", t.ID, t.Name)
+			}
+		}
+
+		fmt.Printf("\nParsing %s as F%03d...\n", entry.Heading, featureNum)
+		result, err := ai.ExecuteWithRetry(ctx, provider, &ai.ExecuteOptions{
+			Prompt:       buildAddPrompt(desc, featureNum, nextTaskID),
+			Timeout:      cfg.AI.PlanningTimeout,
+			StreamOutput: cfg.AI.StreamOutput,
+		}, &ai.RetryConfig{
+			MaxRetries: cfg.AI.MaxRetries,
+			Delay:      time.Duration(cfg.AI.RetryDelay) * time.Second,
+		})
+		if err != nil {
+			return fmt.Errorf("failed to parse section %s: %w", entry.Heading, err)
+		}
+
+		output := result.Output
+		if previous != nil {
+			// Tasks that are regenerated keep their IDs
+			output = task.KeepTaskIDs(output, previous.Tasks)
+			os.Remove(previous.FilePath)
+		}
+		taskFile, err := writeFeatureFile(output, featureNum, entry.Heading)
+		if err != nil {
+			return err
+		}
+		if err := task.SetFeatureSource(taskFile, entry.File); err != nil {
+			fmt.Printf("Warning: failed to link %s to %s: %v\n", taskFile, entry.File, err)
+		}
+
+		entry.FeatureID = fmt.Sprintf("F%03d", featureNum)
+		entry.TaskFile = filepath.ToSlash(taskFile)
+	}
+
+	return nil
+}
+
+// reusableFeature returns the feature previously generated for a section so
+// it can be regenerated under the same feature and task IDs. Features with
+// started tasks are never replaced.
+func reusableFeature(entry *prd.ManifestEntry) (*task.Feature, error) {
+	if entry.FeatureID == "" {
+		return nil, nil
+	}
+
+	feature, _ := task.NewReader(".").GetFeatureByID(entry.FeatureID)
+	if feature == nil {
+		return nil, nil
+	}
+	for _, t := range feature.Tasks {
+		if t.Status != task.StatusNotStarted {
+			return nil, fmt.Errorf("feature %s has started tasks, update it manually", feature.ID)
+		}
+	}
+	return feature, nil
+}
+
+func findSectionChange(changes []prd.SectionChange, heading string) prd.Change {
+	for _, c := range changes {
+		if c.Change != prd.ChangeRemoved && c.Section.Heading == heading {
+			return c.Change
+		}
+	}
+	return prd.ChangeNew
+}
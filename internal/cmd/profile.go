@@ -0,0 +1,36 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"strings"
+
+	"hermes/internal/config"
+)
+
+// ConfigureProfile selects the config profile of --profile, or of the
+// HERMES_PROFILE environment variable when the flag is not given, for every
+// later config load. An undefined profile is an error rather than silently
+// running with the base configuration.
+func ConfigureProfile(name string) error {
+	if name == "" {
+		name = os.Getenv("HERMES_PROFILE")
+	}
+	config.SetProfile(name)
+	if config.Profile() == "" {
+		return nil
+	}
+
+	profiles := config.Profiles(".")
+	for _, p := range profiles {
+		if p == config.Profile() {
+			return nil
+		}
+	}
+	defined := "none"
+	if len(profiles) > 0 {
+		defined = strings.Join(profiles, ", ")
+	}
+	config.SetProfile("")
+	return fmt.Errorf("unknown config profile %q (defined: %s)", name, defined)
+}
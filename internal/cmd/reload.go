@@ -0,0 +1,120 @@
+package cmd
+
+import (
+	"context"
+	"path/filepath"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/fsnotify/fsnotify"
+	"hermes/internal/config"
+	"hermes/internal/ui"
+)
+
+// configReloadDelay is how long config file changes must settle before the
+// configuration is reloaded, so an editor's save is read once and complete
+const configReloadDelay = time.Second
+
+// The options a sequential and a parallel run apply when the config files
+// change while they run. Other changes take effect with the next run.
+var (
+	sequentialReloadable = []string{"ai.streamOutput", "loop.maxRunMinutes", "loop.maxRunCost"}
+	parallelReloadable   = []string{"parallel.maxWorkers", "parallel.maxCostPerHour"}
+)
+
+// watchConfig watches the project and user config files until ctx is done
+// and sends the configuration on the returned channel each time they change.
+// A configuration not received yet is replaced by a newer one; a change that
+// leaves a file unreadable or the configuration invalid is logged and skipped.
+func watchConfig(ctx context.Context, basePath string, logger *ui.Logger) <-chan *config.Config {
+	reloads := make(chan *config.Config, 1)
+	watcher, err := fsnotify.NewWatcher()
+	if err != nil {
+		logger.Warn("Config changes will not be applied during the run: %v", err)
+		return reloads
+	}
+	// Watch the directories since editors replace files rather than write them
+	for _, dir := range append(config.GlobalDirs(), filepath.Join(basePath, ".hermes")) {
+		watcher.Add(dir) // Directories that don't exist have no config file to change
+	}
+
+	go func() {
+		defer watcher.Close()
+		var settle <-chan time.Time
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case event, ok := <-watcher.Events:
+				if !ok {
+					return
+				}
+				if isConfigFile(event) {
+					settle = time.After(configReloadDelay)
+				}
+			case err, ok := <-watcher.Errors:
+				if !ok {
+					return
+				}
+				logger.Warn("Config watcher error: %v", err)
+			case <-settle:
+				settle = nil
+				cfg, err := config.Reload(basePath)
+				if err != nil {
+					logger.Warn("Config changed but was not reloaded: %v", err)
+					continue
+				}
+				select {
+				case <-reloads:
+				default:
+				}
+				reloads <- cfg
+			}
+		}
+	}()
+	return reloads
+}
+
+// isConfigFile reports whether a file event changes a config file
+func isConfigFile(event fsnotify.Event) bool {
+	if event.Op == fsnotify.Chmod {
+		return false
+	}
+	return slices.Contains(config.FileNames, filepath.Base(event.Name))
+}
+
+// applyConfigChanges copies the options of reloadable that changed from
+// updated to cfg and returns them, and the changed options that need a new run
+func applyConfigChanges(cfg, updated *config.Config, reloadable []string) (applied, ignored []string) {
+	for _, key := range config.Changes(cfg, updated) {
+		if !slices.Contains(reloadable, key) {
+			ignored = append(ignored, key)
+			continue
+		}
+		applied = append(applied, key)
+		switch key {
+		case "ai.streamOutput":
+			cfg.AI.StreamOutput = updated.AI.StreamOutput
+		case "loop.maxRunMinutes":
+			cfg.Loop.MaxRunMinutes = updated.Loop.MaxRunMinutes
+		case "loop.maxRunCost":
+			cfg.Loop.MaxRunCost = updated.Loop.MaxRunCost
+		case "parallel.maxWorkers":
+			cfg.Parallel.MaxWorkers = updated.Parallel.MaxWorkers
+		case "parallel.maxCostPerHour":
+			cfg.Parallel.MaxCostPerHour = updated.Parallel.MaxCostPerHour
+		}
+	}
+	return applied, ignored
+}
+
+// logConfigChanges reports the options a reload changed
+func logConfigChanges(logger *ui.Logger, applied, ignored []string) {
+	if len(applied) > 0 {
+		logger.Info("Config reloaded, applied %s", strings.Join(applied, ", "))
+	}
+	if len(ignored) > 0 {
+		logger.Warn("Config changes to %s take effect with the next run", strings.Join(ignored, ", "))
+	}
+}
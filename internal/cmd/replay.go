@@ -0,0 +1,254 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"os/signal"
+	"strings"
+	"syscall"
+	"time"
+
+	"github.com/spf13/cobra"
+	"hermes/internal/ai"
+	"hermes/internal/analyzer"
+	"hermes/internal/config"
+	"hermes/internal/converter"
+	"hermes/internal/git"
+	"hermes/internal/report"
+	"hermes/internal/task"
+	"hermes/internal/ui"
+)
+
+// replayOutputTail is how much of the previous AI output is fed back on replay
+const replayOutputTail = 6000
+
+type replayOptions struct {
+	aiProvider string
+	autoCommit bool
+	// autoCommitSet is true when --auto-commit overrides the config
+	autoCommitSet bool
+	dryRun        bool
+	debug         bool
+}
+
+// NewReplayCmd creates the replay subcommand
+func NewReplayCmd() *cobra.Command {
+	opts := &replayOptions{}
+
+	cmd := &cobra.Command{
+		Use:   "replay <taskID>",
+		Short: "Retry a failed task with its previous context",
+		Long: `Re-run a task that previously failed, using the prompt captured during
+that attempt with the last AI output and error appended, so the AI continues
+from where it stopped instead of starting from scratch.`,
+		Example: `  hermes replay T005
+  hermes replay 5 --dry-run
+  hermes replay T005 --auto-commit`,
+		Args:              cobra.ExactArgs(1),
+		ValidArgsFunction: completeTaskIDs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			opts.autoCommitSet = cmd.Flags().Changed("auto-commit")
+			return replayExecute(normalizeTaskID(args[0]), opts)
+		},
+	}
+
+	cmd.Flags().StringVar(&opts.aiProvider, "ai", "", "AI provider: claude, droid, gemini, auto (default: from config or auto)")
+	cmd.Flags().BoolVar(&opts.autoCommit, "auto-commit", false, "Commit on task completion (overrides config)")
+	cmd.Flags().BoolVar(&opts.dryRun, "dry-run", false, "Print the replay prompt without executing it")
+	cmd.Flags().BoolVar(&opts.debug, "debug", false, "Enable debug output")
+
+	return usesSecrets(cmd)
+}
+
+// normalizeTaskID pads numeric IDs with zeros (1 -> T001, 12 -> T012)
+func normalizeTaskID(id string) string {
+	id = strings.ToUpper(id)
+	if !strings.HasPrefix(id, "T") {
+		id = fmt.Sprintf("T%03s", id)
+	}
+	return id
+}
+
+func replayExecute(taskID string, opts *replayOptions) error {
+	attempt, err := report.LoadAttempt(".", taskID)
+	if err != nil {
+		return err
+	}
+
+	reader := task.NewReader(".")
+	t, err := reader.GetTaskByID(taskID)
+	if err != nil {
+		return err
+	}
+	if t == nil {
+		return fmt.Errorf("task %s not found", taskID)
+	}
+	if t.Status == task.StatusCompleted {
+		return fmt.Errorf("task %s is already completed", taskID)
+	}
+
+	replayPrompt := buildReplayPrompt(attempt)
+	if opts.dryRun {
+		fmt.Println(replayPrompt)
+		return nil
+	}
+
+	cfg, err := config.Load(".")
+	if err != nil {
+		return err
+	}
+	if !opts.autoCommitSet {
+		opts.autoCommit = cfg.TaskMode.AutoCommit
+	}
+
+	logger, err := ui.NewLogger(".", opts.debug)
+	if err != nil {
+		return err
+	}
+	defer logger.Close()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	sigChan := make(chan os.Signal, 1)
+	signal.Notify(sigChan, os.Interrupt, syscall.SIGTERM)
+	go func() {
+		<-sigChan
+		fmt.Println("\nReceived interrupt, shutting down...")
+		cancel()
+	}()
+
+	ui.PrintBanner(GetVersion())
+	ui.PrintHeader("Task Replay")
+
+	provider, err := selectCodingProvider(opts.aiProvider, cfg)
+	if err != nil {
+		return err
+	}
+	logger.Info("Replaying task %s (%s attempt from run %s) with %s", t.ID, attempt.Outcome, attempt.RunID, provider.Name())
+
+	statusUpdater := task.NewStatusUpdater(".")
+	if err := statusUpdater.UpdateTaskStatus(t.ID, task.StatusInProgress); err != nil {
+		logger.Warn("Failed to set task IN_PROGRESS: %v", err)
+	}
+
+	recorder := report.NewRecorder(".", "replay", provider.Name())
+	defer recorder.Finish()
+
+	ui.PrintTaskHeader(t)
+	executor := ai.NewTaskExecutor(provider, ".")
+	executor.SetProjectContext(converter.ProjectContext(".", cfg.AI.ProjectContext))
+	executor.SetGitHistory(git.New(".").TaskHistory(cfg.AI.GitHistory))
+	executor.SetWarn(logger.Warn)
+	if cfg.AI.Memory {
+		executor.SetMemory(".")
+	}
+	taskStart := time.Now()
+	result, err := executor.ExecuteTask(ctx, t, replayPrompt, cfg.AI.StreamOutput)
+	taskRecord := report.TaskRecord{
+		TaskID:    t.ID,
+		TaskName:  t.Name,
+		FeatureID: t.FeatureID,
+		Effort:    t.EstimatedEffort,
+		Duration:  time.Since(taskStart),
+		// Keep the original prompt so repeated replays don't nest previous attempts
+		Prompt: attempt.Prompt,
+	}
+	if err != nil {
+		taskRecord.Outcome = report.OutcomeFailed
+		taskRecord.Error = err.Error()
+		taskRecord.Output = attempt.Output
+		recorder.RecordTask(taskRecord)
+		return fmt.Errorf("replay failed: %w", err)
+	}
+	taskRecord.Cost = result.Cost
+	taskRecord.Output = result.Output
+
+	respAnalyzer := analyzer.NewResponseAnalyzerWithConfig(&cfg.Analyzer)
+	if !respAnalyzer.HasStatusBlock(result.Output) {
+		taskRecord.Outcome = report.OutcomeFailed
+		taskRecord.Error = "missing HERMES_STATUS block"
+		recorder.RecordTask(taskRecord)
+		return fmt.Errorf("task %s replay did not report a HERMES_STATUS block", t.ID)
+	}
+
+	analysis := respAnalyzer.AnalyzeWithCriteria(result.Output, t.SuccessCriteria)
+	switch {
+	case analysis.IsComplete:
+		if err := statusUpdater.UpdateTaskStatus(t.ID, task.StatusCompleted); err != nil {
+			logger.Warn("Failed to update task status: %v", err)
+		}
+		if opts.autoCommit {
+			gitOps := git.New(".")
+			gitOps.SetStageExcludes(cfg.GetStageExcludes()...)
+			gitOps.SetCommitPolicy(git.NewCommitPolicy(cfg.Git))
+			gitOps.SetSigning(git.NewSigning(cfg.Git))
+			if err := gitOps.SetCommitFormat(git.NewCommitFormat(cfg.Git)); err != nil {
+				logger.Warn("%v, using the default commit message", err)
+			}
+			if gitOps.HasUncommittedChanges() {
+				if outside, err := gitOps.StageTask(t.ScopeFiles()); err == nil {
+					if len(outside) > 0 {
+						logger.Warn("Not committing changes outside the files of task %s: %s", t.ID, strings.Join(outside, ", "))
+					}
+					if err := gitOps.CommitTaskInScope(t); err != nil {
+						logger.Warn("Failed to commit: %v", err)
+					} else {
+						logger.Success("Committed task %s", t.ID)
+						if stat := commitStat(gitOps, "HEAD"); stat != "" {
+							logger.Info("Changes: %s", stat)
+						}
+					}
+				}
+			}
+			if cfg.Git.TrackTasks {
+				commitTaskFiles(gitOps, cfg, logger)
+			}
+		}
+		taskRecord.Outcome = report.OutcomeCompleted
+		logger.Success("Task %s completed on replay", t.ID)
+	case analysis.IsBlocked:
+		statusUpdater.BlockTask(t.ID, analysis.Recommendation)
+		taskRecord.Outcome = report.OutcomeBlocked
+		taskRecord.Error = analysis.Recommendation
+		logger.Warn("Task %s is BLOCKED: %s", t.ID, analysis.Recommendation)
+	case analysis.IsPaused:
+		statusUpdater.UpdateTaskStatus(t.ID, task.StatusPaused)
+		taskRecord.Outcome = report.OutcomePaused
+		logger.Info("Task %s is PAUSED: %s", t.ID, analysis.Recommendation)
+	case analysis.IsAtRisk:
+		statusUpdater.UpdateTaskStatus(t.ID, task.StatusAtRisk)
+		taskRecord.Outcome = report.OutcomeAtRisk
+		taskRecord.Error = analysis.Recommendation
+		logger.Warn("Task %s is AT RISK: %s", t.ID, analysis.Recommendation)
+	default:
+		taskRecord.Outcome = report.OutcomeIncomplete
+		logger.Info("Task %s still in progress: %s", t.ID, analysis.Recommendation)
+	}
+	recorder.RecordTask(taskRecord)
+
+	return nil
+}
+
+// buildReplayPrompt appends the previous attempt's output and error to its prompt
+func buildReplayPrompt(attempt *report.Attempt) string {
+	var sb strings.Builder
+	sb.WriteString(strings.TrimRight(attempt.Prompt, "\n"))
+	sb.WriteString("\n\n## Previous Attempt\n\n")
+	fmt.Fprintf(&sb, "This task was attempted before and ended with outcome %s. ", attempt.Outcome)
+	sb.WriteString("Do not start from scratch: review what was already done, fix what went wrong and finish the task.\n")
+
+	if attempt.Error != "" {
+		fmt.Fprintf(&sb, "\n### Error\n\n```\n%s\n```\n", strings.TrimSpace(attempt.Error))
+	}
+
+	output := strings.TrimSpace(attempt.Output)
+	if output != "" {
+		if len(output) > replayOutputTail {
+			output = "..." + output[len(output)-replayOutputTail:]
+		}
+		fmt.Fprintf(&sb, "\n### Last AI Output\n\n```\n%s\n```\n", output)
+	}
+
+	return sb.String()
+}
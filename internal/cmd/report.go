@@ -0,0 +1,110 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/spf13/cobra"
+	"hermes/internal/config"
+	"hermes/internal/report"
+)
+
+type reportOptions struct {
+	runID  string
+	format string
+	output string
+	list   bool
+}
+
+// NewReportCmd creates the report subcommand
+func NewReportCmd() *cobra.Command {
+	opts := &reportOptions{}
+
+	cmd := &cobra.Command{
+		Use:   "report",
+		Short: "Generate run report",
+		Long:  "Generate a Markdown or HTML report summarizing a recorded run",
+		Example: `  hermes report
+  hermes report --format html -o report.html
+  hermes report --run 20250101-120000.123456
+  hermes report --run 20250101-1200
+  hermes report --list`,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return reportExecute(opts)
+		},
+	}
+
+	cmd.Flags().StringVar(&opts.runID, "run", "", "Run ID, or a unique prefix of it, to report on (default: latest run)")
+	cmd.Flags().StringVar(&opts.format, "format", "markdown", "Output format: markdown, html")
+	cmd.Flags().StringVarP(&opts.output, "output", "o", "", "Write report to file instead of stdout")
+	cmd.Flags().BoolVar(&opts.list, "list", false, "List recorded runs")
+
+	return cmd
+}
+
+func reportExecute(opts *reportOptions) error {
+	if opts.list {
+		return listRuns()
+	}
+
+	run, err := report.LoadRun(".", opts.runID)
+	if err != nil {
+		return err
+	}
+
+	rep := report.New(".", run)
+
+	var content string
+	switch strings.ToLower(opts.format) {
+	case "markdown", "md":
+		content = rep.Markdown()
+	case "html":
+		content, err = rep.HTML()
+		if err != nil {
+			return fmt.Errorf("failed to render report: %w", err)
+		}
+	default:
+		return fmt.Errorf("unknown format: %s (use markdown or html)", opts.format)
+	}
+
+	if opts.output == "" {
+		fmt.Print(content)
+		return nil
+	}
+
+	if err := os.WriteFile(opts.output, []byte(content), 0644); err != nil {
+		return err
+	}
+	fmt.Printf("Report written to: %s\n", opts.output)
+	return nil
+}
+
+func listRuns() error {
+	runs, err := report.LoadRuns(".")
+	if err != nil {
+		return err
+	}
+	if len(runs) == 0 {
+		fmt.Println("No recorded runs found.")
+		return nil
+	}
+
+	fmt.Printf("%-22s %-10s %-10s %-10s %s\n", "RUN", "MODE", "PROVIDER", "DURATION", "COMPLETED/FAILED")
+	for _, run := range runs {
+		s := run.Summarize()
+		fmt.Printf("%-22s %-10s %-10s %-10s %d/%d\n",
+			run.ID, run.Mode, run.Provider, run.Elapsed().Round(time.Second), s.Completed, s.Failed)
+	}
+	return nil
+}
+
+// ConfigureHistory selects the run history store configured in history.store
+func ConfigureHistory() error {
+	cfg, err := config.Load(".")
+	if err != nil {
+		return err
+	}
+	return report.SetStore(cfg.History.Store)
+}
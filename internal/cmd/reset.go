@@ -5,6 +5,7 @@ import (
 
 	"github.com/spf13/cobra"
 	"hermes/internal/circuit"
+	"hermes/internal/recovery"
 )
 
 // NewResetCmd creates the reset subcommand
@@ -40,6 +41,7 @@ func resetExecute() error {
 	if err := breaker.Reset("Manual reset via CLI"); err != nil {
 		return err
 	}
+	recovery.Clear(".")
 
 	fmt.Println("\nCircuit breaker reset successfully.")
 	fmt.Println("You can now run 'hermes run' to continue.")
@@ -0,0 +1,114 @@
+package cmd
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/spf13/cobra"
+	"hermes/internal/checkpoint"
+	"hermes/internal/config"
+	"hermes/internal/lock"
+	"hermes/internal/scheduler"
+)
+
+type resumeOptions struct {
+	aiProvider string
+	debug      bool
+	discard    bool
+}
+
+// NewResumeCmd creates the resume subcommand
+func NewResumeCmd() *cobra.Command {
+	opts := &resumeOptions{}
+
+	cmd := &cobra.Command{
+		Use:   "resume",
+		Short: "Resume an interrupted run",
+		Long: `Continue a run that was interrupted by Ctrl+C, a crash or a power loss from the
+checkpoint in .hermes/checkpoint.json. The loop counter, the task in progress,
+the circuit breaker state and, in parallel mode, the remaining batch queue are
+restored, and the run keeps its original run ID in reports. Interrupted parallel
+tasks continue in the worktrees under .hermes/worktrees they were running in.`,
+		Example: `  hermes resume
+  hermes resume --ai droid
+  hermes resume --discard`,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return resumeExecute(opts)
+		},
+	}
+
+	cmd.Flags().StringVar(&opts.aiProvider, "ai", "", "AI provider (default: the provider of the interrupted run)")
+	cmd.Flags().BoolVar(&opts.debug, "debug", false, "Enable debug output")
+	cmd.Flags().BoolVar(&opts.discard, "discard", false, "Delete the checkpoint instead of resuming")
+
+	return usesSecrets(cmd)
+}
+
+func resumeExecute(opts *resumeOptions) error {
+	l, err := lock.Acquire(".", "resume")
+	if err != nil {
+		return err
+	}
+	defer l.Release()
+
+	cp, err := checkpoint.Load(".")
+	if err != nil {
+		return err
+	}
+
+	if opts.discard {
+		if err := checkpoint.Clear("."); err != nil {
+			return err
+		}
+		if cp.Mode == checkpoint.ModeParallel {
+			scheduler.NewRollback(".").CleanupWorktrees()
+		}
+		fmt.Printf("Discarded checkpoint of run %s\n", cp.RunID)
+		return nil
+	}
+
+	cfg, err := config.Load(".")
+	if err != nil {
+		return err
+	}
+
+	fmt.Println(describeCheckpoint(cp))
+
+	return startRun(cfg, resumeRunOptions(cfg, cp, opts.aiProvider, opts.debug), cp)
+}
+
+// resumeRunOptions returns the options of the interrupted run, using
+// aiProvider instead of its provider when set
+func resumeRunOptions(cfg *config.Config, cp *checkpoint.Checkpoint, aiProvider string, debug bool) runOptions {
+	runOpts := runOptions{
+		aiProvider: cp.Provider,
+		autoBranch: cp.Options.AutoBranch,
+		autoCommit: cp.Options.AutoCommit,
+		autonomous: cp.Options.Autonomous,
+		debug:      debug,
+		parallel:   cp.Mode == checkpoint.ModeParallel,
+		workers:    cp.Options.Workers,
+		until:      cp.Options.Until,
+	}
+	if aiProvider != "" {
+		runOpts.aiProvider = aiProvider
+	}
+	if runOpts.workers == 0 {
+		runOpts.workers = cfg.Parallel.MaxWorkers
+	}
+	return runOpts
+}
+
+// describeCheckpoint summarizes where an interrupted run stopped
+func describeCheckpoint(cp *checkpoint.Checkpoint) string {
+	s := fmt.Sprintf("Interrupted %s run %s", cp.Mode, cp.RunID)
+	if cp.Mode == checkpoint.ModeParallel {
+		s += fmt.Sprintf(" at batch %d, %d batches remaining", cp.Loop, len(cp.Batches))
+	} else {
+		s += fmt.Sprintf(" at loop %d", cp.Loop)
+		if cp.TaskID != "" {
+			s += fmt.Sprintf(" (task %s)", cp.TaskID)
+		}
+	}
+	return s + fmt.Sprintf(", last checkpoint %s ago", time.Since(cp.UpdatedAt).Round(time.Second))
+}
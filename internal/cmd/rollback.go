@@ -0,0 +1,251 @@
+package cmd
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/spf13/cobra"
+	"hermes/internal/git"
+	"hermes/internal/scheduler"
+	"hermes/internal/task"
+)
+
+type rollbackOptions struct {
+	list   bool
+	to     string
+	taskID string
+	yes    bool
+}
+
+// NewRollbackCmd creates the rollback subcommand
+func NewRollbackCmd() *cobra.Command {
+	opts := &rollbackOptions{}
+
+	cmd := &cobra.Command{
+		Use:   "rollback",
+		Short: "Inspect snapshots and revert AI changes",
+		Long: `List the repository snapshots taken before tasks and runs, and revert changes
+after a run has finished. Parallel runs take a snapshot before each task branch
+is merged. --to resets the current branch to a snapshot, discarding later
+commits and uncommitted changes. --task reverts the commits of a single task
+with new commits, keeping the rest of the history; later commits changing the
+same files are listed first, and if the revert conflicts with them nothing is
+reverted. Tasks whose changes are undone are set back to NOT_STARTED.`,
+		Example: `  hermes rollback --list
+  hermes rollback --to 12
+  hermes rollback --to T005
+  hermes rollback --task T007 --yes`,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return rollbackExecute(opts)
+		},
+	}
+
+	cmd.Flags().BoolVar(&opts.list, "list", false, "List saved snapshots (default)")
+	cmd.Flags().StringVar(&opts.to, "to", "", "Reset to a snapshot by ID, task ID or commit")
+	cmd.Flags().StringVar(&opts.taskID, "task", "", "Revert the commits of a task")
+	cmd.Flags().BoolVarP(&opts.yes, "yes", "y", false, "Do not ask for confirmation")
+	cmd.MarkFlagsMutuallyExclusive("list", "to", "task")
+	cmd.RegisterFlagCompletionFunc("task", completeTaskIDFlag)
+
+	return cmd
+}
+
+func rollbackExecute(opts *rollbackOptions) error {
+	gitOps := git.New(".")
+	if !gitOps.IsRepository() {
+		return fmt.Errorf("not a git repository")
+	}
+
+	switch {
+	case opts.to != "":
+		return rollbackToSnapshot(gitOps, opts.to, opts.yes)
+	case opts.taskID != "":
+		return rollbackTask(gitOps, normalizeTaskID(opts.taskID), opts.yes)
+	default:
+		return listSnapshots(gitOps)
+	}
+}
+
+func listSnapshots(gitOps *git.Git) error {
+	snapshots, err := scheduler.LoadSnapshots(".")
+	if err != nil {
+		return err
+	}
+	if len(snapshots) == 0 {
+		fmt.Println("No snapshots saved yet. Snapshots are taken before tasks during 'hermes run'.")
+		return nil
+	}
+
+	head, _ := gitOps.GetLastCommitHash()
+	fmt.Printf("%-5s %-10s %-9s %-24s %s\n", "ID", "BEFORE", "COMMIT", "BRANCH", "TIME")
+	for _, s := range snapshots {
+		marker := ""
+		if s.Commit == head {
+			marker = "  (HEAD)"
+		}
+		fmt.Printf("%-5d %-10s %-9s %-24s %s%s\n",
+			s.ID, s.Name, shortHash(s.Commit), s.Branch, s.Time.Format("2006-01-02 15:04"), marker)
+	}
+	return nil
+}
+
+func rollbackToSnapshot(gitOps *git.Git, ref string, yes bool) error {
+	snapshot, err := scheduler.FindSnapshot(".", ref)
+	if err != nil {
+		return err
+	}
+
+	if branch, _ := gitOps.GetCurrentBranch(); snapshot.Branch != "" && branch != snapshot.Branch {
+		return fmt.Errorf("snapshot %d was taken on branch %s, but %s is checked out", snapshot.ID, snapshot.Branch, branch)
+	}
+
+	commits, err := gitOps.GetCommitsSince(snapshot.Commit)
+	if err != nil {
+		return fmt.Errorf("snapshot commit %s is not in the current history", shortHash(snapshot.Commit))
+	}
+
+	fmt.Printf("Reset to snapshot %d (before %s, %s)\n", snapshot.ID, snapshot.Name, shortHash(snapshot.Commit))
+	if len(commits) > 0 {
+		fmt.Printf("\nCommits that will be discarded:\n")
+		for _, c := range commits {
+			fmt.Printf("  %s\n", c)
+		}
+	}
+	if gitOps.HasUncommittedChanges() {
+		fmt.Println("\nUncommitted changes will be discarded as well.")
+	}
+	if !yes && !confirm("\nContinue?") {
+		fmt.Println("Aborted.")
+		return nil
+	}
+
+	if err := gitOps.ResetHard(snapshot.Commit); err != nil {
+		return err
+	}
+	fmt.Printf("Reset to %s\n", shortHash(snapshot.Commit))
+
+	var subjects []string
+	for _, c := range commits {
+		_, subject, _ := strings.Cut(c, " ")
+		subjects = append(subjects, subject)
+	}
+	resetRolledBackTasks(subjects)
+	return nil
+}
+
+func rollbackTask(gitOps *git.Git, taskID string, yes bool) error {
+	commits, err := gitOps.FindTaskCommits(taskID)
+	if err != nil {
+		return err
+	}
+	if len(commits) == 0 {
+		if snapshot, err := scheduler.FindSnapshot(".", taskID); err == nil {
+			return fmt.Errorf("no commits found for task %s on the current branch, use 'hermes rollback --to %d' to reset to the snapshot taken before it", taskID, snapshot.ID)
+		}
+		return fmt.Errorf("no commits found for task %s on the current branch", taskID)
+	}
+	if gitOps.HasUncommittedChanges() {
+		return fmt.Errorf("working tree has uncommitted changes, commit or stash them first")
+	}
+
+	fmt.Printf("Commits of %s that will be reverted:\n", taskID)
+	for _, c := range commits {
+		fmt.Printf("  %s %s\n", shortHash(c.Hash), c.Subject)
+	}
+	if later := laterOverlappingCommits(gitOps, commits); len(later) > 0 {
+		fmt.Printf("\nLater commits changed the same files, the revert may conflict:\n")
+		for _, c := range later {
+			fmt.Printf("  %s %s\n", shortHash(c.Hash), c.Subject)
+		}
+	}
+	if !yes && !confirm("\nContinue?") {
+		fmt.Println("Aborted.")
+		return nil
+	}
+
+	// Commits are newest first, so later changes are undone before earlier ones.
+	// A conflict undoes the reverts made so far, so later work is never left
+	// half reverted.
+	head, err := gitOps.GetLastCommitHash()
+	if err != nil {
+		return err
+	}
+	for _, c := range commits {
+		if err := gitOps.RevertCommit(c); err != nil {
+			if resetErr := gitOps.ResetHard(head); resetErr != nil {
+				return fmt.Errorf("%w (restoring %s failed: %v)", err, shortHash(head), resetErr)
+			}
+			return fmt.Errorf("%w; later changes depend on %s, nothing was reverted", err, taskID)
+		}
+		fmt.Printf("Reverted %s %s\n", shortHash(c.Hash), c.Subject)
+	}
+
+	resetRolledBackTasks([]string{"(" + taskID + ")"})
+	return nil
+}
+
+// laterOverlappingCommits returns the commits of other tasks made after the
+// first commit of a task that changed files the task changed
+func laterOverlappingCommits(gitOps *git.Git, commits []git.TaskCommit) []git.TaskCommit {
+	own := make(map[string]bool)
+	var files []string
+	for _, c := range commits {
+		own[c.Hash] = true
+		changed, err := gitOps.ChangedFiles(c)
+		if err != nil {
+			return nil
+		}
+		files = append(files, changed...)
+	}
+
+	// Commits are newest first, so the last one is the earliest
+	touching, err := gitOps.FindCommitsTouching(commits[len(commits)-1].Hash, files)
+	if err != nil {
+		return nil
+	}
+	var later []git.TaskCommit
+	for _, c := range touching {
+		if !own[c.Hash] {
+			later = append(later, c)
+		}
+	}
+	return later
+}
+
+// resetRolledBackTasks sets tasks mentioned in the subjects of undone commits
+// back to NOT_STARTED
+func resetRolledBackTasks(subjects []string) {
+	tasks, err := task.NewReader(".").GetAllTasks()
+	if err != nil {
+		return
+	}
+	updater := task.NewStatusUpdater(".")
+	for _, t := range tasks {
+		if t.Status == task.StatusNotStarted {
+			continue
+		}
+		for _, subject := range subjects {
+			if strings.Contains(subject, "("+t.ID+")") || strings.Contains(subject, "(task "+t.ID+")") {
+				if err := updater.UpdateTaskStatus(t.ID, task.StatusNotStarted); err == nil {
+					fmt.Printf("Set %s back to NOT_STARTED\n", t.ID)
+				}
+				break
+			}
+		}
+	}
+}
+
+// confirm asks a yes/no question on stdin
+func confirm(question string) bool {
+	fmt.Printf("%s (y/n) ", question)
+	var response string
+	fmt.Scanln(&response)
+	return response == "y" || response == "Y"
+}
+
+func shortHash(hash string) string {
+	if len(hash) > 8 {
+		return hash[:8]
+	}
+	return hash
+}
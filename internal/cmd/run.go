@@ -6,19 +6,34 @@ import (
 	"fmt"
 	"os"
 	"os/signal"
+	"path/filepath"
+	"slices"
+	"strings"
 	"syscall"
 	"time"
 
 	"github.com/spf13/cobra"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
 	"hermes/internal/ai"
 	"hermes/internal/analyzer"
+	"hermes/internal/checkpoint"
 	"hermes/internal/circuit"
 	"hermes/internal/config"
+	"hermes/internal/converter"
 	"hermes/internal/git"
+	"hermes/internal/github"
+	"hermes/internal/hooks"
+	"hermes/internal/lock"
 	"hermes/internal/prompt"
+	"hermes/internal/recovery"
+	"hermes/internal/report"
 	"hermes/internal/scheduler"
 	"hermes/internal/task"
+	"hermes/internal/tasklog"
+	"hermes/internal/tracing"
 	"hermes/internal/ui"
+	"hermes/internal/verify"
 )
 
 // NewRunCmd creates the run subcommand
@@ -31,6 +46,8 @@ func NewRunCmd() *cobra.Command {
   hermes run --auto-branch --auto-commit
   hermes run --autonomous=false
   hermes run --dry-run
+  hermes run --resume
+  hermes run --until v1.0.0
   hermes run --parallel --workers 3
   hermes run --parallel --dry-run`,
 		RunE: runExecute,
@@ -42,57 +59,148 @@ func NewRunCmd() *cobra.Command {
 	cmd.Flags().Int("timeout", 0, "AI timeout in seconds (0 = use config)")
 	cmd.Flags().Bool("debug", false, "Enable debug output")
 	cmd.Flags().String("ai", "", "AI provider: claude, droid, gemini, auto (default: from config or auto)")
+	cmd.Flags().String("until", "", "Only run tasks of features targeting this version or earlier, then tag it")
 	// Parallel execution flags
 	cmd.Flags().Bool("parallel", false, "Enable parallel task execution")
 	cmd.Flags().Int("workers", 3, "Number of parallel workers (default: 3)")
 	cmd.Flags().Bool("dry-run", false, "Show execution plan without running")
+	// Interrupted run handling
+	cmd.Flags().Bool("resume", false, "Resume an interrupted run without asking")
+	cmd.Flags().Bool("fresh", false, "Discard an interrupted run and start a new one without asking")
 
-	return cmd
+	return usesSecrets(cmd)
 }
 
-func runExecute(cmd *cobra.Command, args []string) error {
-	ctx, cancel := context.WithCancel(context.Background())
-	defer cancel()
+// runOptions holds the effective run options after CLI flags are applied to the config
+type runOptions struct {
+	aiProvider string
+	autoBranch bool
+	autoCommit bool
+	autonomous bool
+	debug      bool
+	parallel   bool
+	workers    int
+	dryRun     bool
+	until      string // Milestone version to stop at
+}
 
+func runExecute(cmd *cobra.Command, args []string) error {
 	// Load config first
 	cfg, err := config.Load(".")
 	if err != nil {
-		cfg = config.DefaultConfig()
+		return err
 	}
 
 	// Apply CLI flags (override config if flag was explicitly set)
-	autoBranch := cfg.TaskMode.AutoBranch
-	autoCommit := cfg.TaskMode.AutoCommit
-	autonomous := cfg.TaskMode.Autonomous
-	debug := false
+	opts := runOptions{
+		autoBranch: cfg.TaskMode.AutoBranch,
+		autoCommit: cfg.TaskMode.AutoCommit,
+		autonomous: cfg.TaskMode.Autonomous,
+		parallel:   cfg.Parallel.Enabled,
+		workers:    cfg.Parallel.MaxWorkers,
+	}
 
 	if cmd.Flags().Changed("auto-branch") {
-		autoBranch, _ = cmd.Flags().GetBool("auto-branch")
+		opts.autoBranch, _ = cmd.Flags().GetBool("auto-branch")
 	}
 	if cmd.Flags().Changed("auto-commit") {
-		autoCommit, _ = cmd.Flags().GetBool("auto-commit")
+		opts.autoCommit, _ = cmd.Flags().GetBool("auto-commit")
 	}
 	if cmd.Flags().Changed("autonomous") {
-		autonomous, _ = cmd.Flags().GetBool("autonomous")
+		opts.autonomous, _ = cmd.Flags().GetBool("autonomous")
 	}
 	if cmd.Flags().Changed("debug") {
-		debug, _ = cmd.Flags().GetBool("debug")
+		opts.debug, _ = cmd.Flags().GetBool("debug")
+	}
+	if cmd.Flags().Changed("parallel") {
+		opts.parallel, _ = cmd.Flags().GetBool("parallel")
+	}
+	if cmd.Flags().Changed("workers") {
+		opts.workers, _ = cmd.Flags().GetInt("workers")
+	}
+	opts.aiProvider, _ = cmd.Flags().GetString("ai")
+	opts.dryRun, _ = cmd.Flags().GetBool("dry-run")
+	opts.until, _ = cmd.Flags().GetString("until")
+
+	// Refuse to run next to another runner in the same project, before its
+	// checkpoint could be mistaken for an interrupted run
+	if !opts.dryRun {
+		l, err := lock.Acquire(".", "run")
+		if err != nil {
+			return err
+		}
+		defer l.Release()
+	}
+
+	// Offer to continue a run that was interrupted in this project
+	if cp, err := checkpoint.Load("."); err == nil && !opts.dryRun {
+		resume, _ := cmd.Flags().GetBool("resume")
+		fresh, _ := cmd.Flags().GetBool("fresh")
+		fmt.Println(describeCheckpoint(cp))
+		if resume || (!fresh && confirm("Resume it instead of starting a new run?")) {
+			aiProvider := ""
+			if cmd.Flags().Changed("ai") {
+				aiProvider = opts.aiProvider
+			}
+			return startRun(cfg, resumeRunOptions(cfg, cp, aiProvider, opts.debug), cp)
+		}
+		if err := checkpoint.Clear("."); err != nil {
+			return err
+		}
+		if cp.Mode == checkpoint.ModeParallel {
+			scheduler.NewRollback(".").CleanupWorktrees()
+		}
+		fmt.Printf("Discarded checkpoint of run %s\n", cp.RunID)
 	}
 
+	return startRun(cfg, opts, nil)
+}
+
+// startRun sets up logging, the circuit breaker and the AI provider and runs the
+// task loop. When resume is set, the interrupted run it describes is continued.
+func startRun(cfg *config.Config, opts runOptions, resume *checkpoint.Checkpoint) (runErr error) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
 	// Initialize logger early so it can be used in signal handler
-	logger, err := ui.NewLogger(".", debug)
+	logger, err := ui.NewLogger(".", opts.debug)
 	if err != nil {
 		return err
 	}
 	defer logger.Close()
 
-	// Handle Ctrl+C
-	sigChan := make(chan os.Signal, 1)
+	// Handle Ctrl+C: the first signal drains the run, starting no further
+	// task and giving running ones loop.drainTimeout to finish, a second one
+	// stops them at once
+	drain := make(chan struct{})
+	sigChan := make(chan os.Signal, 2)
 	signal.Notify(sigChan, os.Interrupt, syscall.SIGTERM)
+	defer signal.Stop(sigChan)
 	go func() {
-		<-sigChan
-		fmt.Println("\nReceived interrupt, shutting down...")
-		logger.Info("Execution interrupted by user (SIGINT/SIGTERM)")
+		select {
+		case <-sigChan:
+		case <-ctx.Done():
+			return
+		}
+		timeout := cfg.DrainTimeout()
+		if timeout <= 0 {
+			fmt.Println("\nReceived interrupt, shutting down...")
+			logger.Info("Execution interrupted by user (SIGINT/SIGTERM)")
+			cancel()
+			return
+		}
+		fmt.Printf("\nReceived interrupt, finishing running tasks for up to %v (interrupt again to stop now)...\n", timeout)
+		logger.Info("Execution interrupted by user (SIGINT/SIGTERM), draining running tasks")
+		close(drain)
+		select {
+		case <-sigChan:
+			fmt.Println("\nReceived second interrupt, shutting down...")
+			logger.Info("Execution stopped by second interrupt")
+		case <-time.After(timeout):
+			logger.Warn("Running tasks did not finish within %v, stopping them", timeout)
+		case <-ctx.Done():
+			return
+		}
 		cancel()
 	}()
 
@@ -102,120 +210,369 @@ func runExecute(cmd *cobra.Command, args []string) error {
 	// Initialize components
 	reader := task.NewReader(".")
 	reader.SetImplicitDocDependencies(cfg.Parallel.ImplicitDocDependencies)
+	reader.SetPriorityAging(cfg.TaskMode.PriorityAgingLoops)
+	reader.SetMilestone(opts.until)
 	breaker := circuit.New(".")
 	gitOps := git.New(".")
-	injector := prompt.NewInjector(".")
-	respAnalyzer := analyzer.NewResponseAnalyzer()
+	gitOps.SetStageExcludes(cfg.GetStageExcludes()...)
+	gitOps.SetCommitPolicy(git.NewCommitPolicy(cfg.Git))
+	gitOps.SetSigning(git.NewSigning(cfg.Git))
+	if err := gitOps.SetCommitFormat(git.NewCommitFormat(cfg.Git)); err != nil {
+		return err
+	}
+	if cfg.Git.TrackTasks {
+		enableTaskTracking(gitOps, cfg, logger)
+	}
 
 	// Initialize circuit breaker
 	if err := breaker.Initialize(); err != nil {
 		return err
 	}
+	if resume != nil && resume.Breaker != nil {
+		if restored, err := breaker.Restore(resume.Breaker); err != nil {
+			logger.Warn("Failed to restore circuit breaker state: %v", err)
+		} else if restored {
+			logger.Info("Restored circuit breaker state from checkpoint")
+		}
+	}
 
 	// Set up circuit breaker state change logging
 	breaker.SetStateChangeCallback(func(fromState, toState circuit.State, reason string) {
 		logger.Info("Circuit breaker: %s -> %s (%s)", fromState, toState, reason)
 	})
+	breaker.SetCooldown(cfg.BreakerCooldown())
 
 	// Check for tasks
 	if !reader.HasTasks() {
 		return fmt.Errorf("no tasks found, run 'hermes prd <file>' first")
 	}
-
-	// Get AI provider
-	aiFlag, _ := cmd.Flags().GetString("ai")
-	var provider ai.Provider
-
-	if aiFlag != "" && aiFlag != "auto" {
-		provider = ai.GetProvider(aiFlag)
-		if provider == nil {
-			return fmt.Errorf("unknown AI provider: %s", aiFlag)
-		}
-		if !provider.IsAvailable() {
-			return fmt.Errorf("AI provider %s is not available (not installed)", aiFlag)
+	if opts.until != "" {
+		if err := checkMilestone(reader, opts.until); err != nil {
+			return err
 		}
-	} else {
-		// Use config or auto-detect
-		if cfg.AI.Coding != "" && cfg.AI.Coding != "auto" {
-			provider = ai.GetProvider(cfg.AI.Coding)
+		logger.Info("Running tasks of features targeting %s or earlier", opts.until)
+	}
+
+	// Without auto-branch every task commit lands on the current branch
+	if opts.autoCommit && !opts.autoBranch && !opts.parallel && !opts.dryRun && gitOps.IsRepository() {
+		if err := gitOps.CheckBranchPolicy(); err != nil {
+			return fmt.Errorf("auto-commit refused: %w (use --auto-branch)", err)
 		}
-		if provider == nil || !provider.IsAvailable() {
-			provider = ai.AutoDetectProvider()
+	}
+
+	// Keep changes made outside Hermes out of task commits. A resumed run's
+	// changes are the interrupted task's own.
+	if resume == nil && !opts.dryRun && gitOps.IsRepository() {
+		restore, err := handleDirtyTree(gitOps, cfg.Git.DirtyTree, logger)
+		if err != nil {
+			return err
 		}
+		defer restore()
 	}
 
-	if provider == nil {
-		return fmt.Errorf("no AI provider available (install claude or droid)")
+	// Get AI provider
+	provider, err := selectCodingProvider(opts.aiProvider, cfg)
+	if err != nil {
+		return err
 	}
 
 	logger.Info("Using AI provider: %s", provider.Name())
+	if profile := config.Profile(); profile != "" {
+		logger.Info("Config profile: %s", profile)
+	}
+	sb := ai.GetSandbox()
+	if sb.Mode != ai.SandboxNone && sb.Mode != "" {
+		logger.Info("Provider sandbox: %s", sb)
+	}
+	if len(sb.EnvAllow) > 0 || len(sb.EnvStrip) > 0 {
+		logger.Info("Provider environment: %d of %d variables passed", len(sb.FilterEnv(os.Environ())), len(os.Environ()))
+	}
 
-	// Check for parallel execution mode
-	parallel, _ := cmd.Flags().GetBool("parallel")
-	dryRun, _ := cmd.Flags().GetBool("dry-run")
-	workers, _ := cmd.Flags().GetInt("workers")
-
-	// Override with config if flag not set
-	if !cmd.Flags().Changed("parallel") {
-		parallel = cfg.Parallel.Enabled
+	// Run the pre-run hooks, e.g. to start services the tasks need, and the
+	// post-run hooks once the run ends
+	if !opts.dryRun {
+		hookTimeout := time.Duration(cfg.Hooks.Timeout) * time.Second
+		defer func() {
+			status := "success"
+			if runErr != nil {
+				status = "failed"
+			}
+			if len(cfg.Hooks.PostRun) > 0 {
+				logger.Info("Running post-run hooks")
+			}
+			if err := hooks.Run(context.Background(), ".", hooks.PostRun, cfg.Hooks.PostRun, []string{"HERMES_RUN_STATUS=" + status}, hookTimeout, os.Stdout); err != nil {
+				logger.Warn("%v", err)
+			}
+		}()
+		if len(cfg.Hooks.PreRun) > 0 {
+			logger.Info("Running pre-run hooks")
+			if err := hooks.Run(ctx, ".", hooks.PreRun, cfg.Hooks.PreRun, nil, hookTimeout, os.Stdout); err != nil {
+				return err
+			}
+		}
 	}
-	if !cmd.Flags().Changed("workers") {
-		workers = cfg.Parallel.MaxWorkers
+
+	// Apply changes to the stream output, worker and cost options while the
+	// run is in progress, e.g. to lower a budget during an overnight run
+	var reload <-chan *config.Config
+	if !opts.dryRun {
+		reload = watchConfig(ctx, ".", logger)
 	}
 
 	// Handle parallel execution
-	if parallel {
-		return runParallel(ctx, cfg, provider, reader, logger, workers, dryRun)
+	if opts.parallel {
+		return runParallel(ctx, drain, reload, cfg, provider, reader, logger, opts.workers, opts.dryRun, resume)
 	}
 
 	// Handle dry-run for sequential mode
-	if dryRun {
-		return runSequentialDryRun(reader, logger, breaker, gitOps, autoBranch)
+	if opts.dryRun {
+		return runSequentialDryRun(reader, logger, breaker, gitOps, opts.autoBranch)
 	}
 
+	return runSequential(ctx, cfg, provider, reader, breaker, gitOps, logger, sequentialOptions{
+		autoBranch: opts.autoBranch,
+		autoCommit: opts.autoCommit,
+		autonomous: opts.autonomous,
+		resume:     resume,
+		drain:      drain,
+		reload:     reload,
+	})
+}
+
+// sequentialOptions holds the CLI overrides for the sequential run loop
+type sequentialOptions struct {
+	autoBranch bool
+	autoCommit bool
+	autonomous bool
+	// waitIfPaused, when set, is called before each task and blocks while the run is paused
+	waitIfPaused func(ctx context.Context) error
+	// resume, when set, is the checkpoint of the interrupted run to continue
+	resume *checkpoint.Checkpoint
+	// drain, when closed, stops the run before the next task
+	drain <-chan struct{}
+	// reload, when set, receives the configuration after the config files
+	// changed; its reloadable options apply from the next task
+	reload <-chan *config.Config
+	// onLimit, when set, is called when the run stops on its duration or
+	// budget limit
+	onLimit func(reason string)
+}
+
+// runSequential executes tasks one at a time until all are complete,
+// the circuit breaker opens or ctx is cancelled
+func runSequential(ctx context.Context, cfg *config.Config, provider ai.Provider, reader *task.Reader, breaker *circuit.Breaker, gitOps *git.Git, logger *ui.Logger, opts sequentialOptions) error {
+	autoBranch, autoCommit, autonomous := opts.autoBranch, opts.autoCommit, opts.autonomous
+	injector := prompt.NewInjector(".")
+	injector.SetStepSubtasks(true)
+	respAnalyzer := analyzer.NewResponseAnalyzerWithConfig(&cfg.Analyzer)
+
+	// Record run data for 'hermes report'
+	recorder := newRunRecorder(opts.resume, checkpoint.ModeSequential, provider.Name())
+	defer recorder.Finish()
+
+	ctx, runSpan := tracing.Start(ctx, "run",
+		attribute.String("run.id", recorder.GetRun().ID),
+		attribute.String("run.mode", checkpoint.ModeSequential),
+		attribute.String("provider", provider.Name()))
+	defer runSpan.End()
+
 	// Sequential execution (original behavior)
 	logger.Info("Starting sequential execution")
 	loopNumber := 0
+	runStart := time.Now()
+	runCost := 0.0
+	resumeTaskID := ""
+	if opts.resume != nil {
+		loopNumber = opts.resume.Loop - 1
+		runStart = runStart.Add(-opts.resume.Elapsed)
+		runCost = opts.resume.Cost
+		resumeTaskID = opts.resume.TaskID
+		logger.Info("Resuming run %s at loop %d", recorder.GetRun().ID, opts.resume.Loop)
+	}
+
+	// The checkpoint is kept when the run is interrupted so 'hermes resume' can continue it
+	cp := &checkpoint.Checkpoint{
+		RunID:     recorder.GetRun().ID,
+		Mode:      checkpoint.ModeSequential,
+		Provider:  provider.Name(),
+		Options:   checkpoint.Options{AutoBranch: autoBranch, AutoCommit: autoCommit, Autonomous: autonomous, Until: reader.Milestone()},
+		StartTime: recorder.GetRun().StartTime,
+	}
+	defer func() {
+		if ctx.Err() == nil && !scheduler.IsClosed(opts.drain) {
+			checkpoint.Clear(".")
+		}
+	}()
+
+	// Snapshot the repository before each task so 'hermes rollback' can return to it
+	var rollback *scheduler.Rollback
+	if gitOps.IsRepository() {
+		rollback = scheduler.NewRollback(".")
+	}
+	snapshotted := make(map[string]bool)
+	feedback := make(map[string]string) // Why the last attempt of a task failed, see prompt.Failure
+
+	saveCheckpoint := func() {
+		cp.Cost, cp.Elapsed = runCost, time.Since(runStart)
+		cp.Breaker, _ = breaker.GetState()
+		if err := checkpoint.Save(".", cp); err != nil {
+			logger.Warn("Failed to save checkpoint: %v", err)
+		}
+	}
+
+	// Each loop is traced as one span and written to the log of its task
+	// attempt; both end when the next loop starts. A checkpoint is written
+	// when a task starts and again when its loop ends, so a crash at any
+	// point loses at most the loop in progress.
+	var loopSpan trace.Span
+	var taskLog *tasklog.Log
+	endLoop := func() {
+		if cp.Loop > 0 {
+			saveCheckpoint()
+		}
+		if loopSpan != nil {
+			loopSpan.End()
+			loopSpan = nil
+		}
+		if taskLog != nil {
+			gitOps.SetCommandLog(nil)
+			taskLog.Close()
+			taskLog = nil
+		}
+	}
+	defer endLoop()
+
 	for {
+		endLoop()
 		select {
 		case <-ctx.Done():
 			return ctx.Err()
+		case <-opts.drain:
+			logger.Info("Run drained, stopping before the next task")
+			return context.Canceled
+		case updated := <-opts.reload:
+			applied, ignored := applyConfigChanges(cfg, updated, sequentialReloadable)
+			logConfigChanges(logger, applied, ignored)
 		default:
 		}
 
+		if opts.waitIfPaused != nil {
+			if err := opts.waitIfPaused(ctx); err != nil {
+				return err
+			}
+		}
+
+		// Check run duration and budget limits
+		if reason := cfg.RunLimitReached(time.Since(runStart), runCost); reason != "" {
+			logger.Warn("Stopping run: %s", reason)
+			if opts.onLimit != nil {
+				opts.onLimit(reason)
+			}
+			return nil
+		}
+
 		loopNumber++
 		ui.PrintLoopHeader(loopNumber)
+		var loopCtx context.Context
+		loopCtx, loopSpan = tracing.Start(ctx, "loop", attribute.Int("loop", loopNumber))
+		gitOps.SetTraceContext(loopCtx)
 
 		// Check circuit breaker
 		canExecute, err := breaker.CanExecute()
 		if err != nil {
 			return err
 		}
+		if at, ok := breaker.RecoveryAt(); !canExecute && ok {
+			state, _ := breaker.GetState()
+			logger.Warn("Circuit breaker OPEN: %s, probing again at %s", state.Reason, at.Format("15:04:05"))
+			select {
+			case <-ctx.Done():
+				return ctx.Err()
+			case <-opts.drain:
+				logger.Info("Run drained, stopping before the next task")
+				return context.Canceled
+			case <-time.After(time.Until(at)):
+			}
+			if canExecute, err = breaker.CanExecute(); err != nil {
+				return err
+			}
+		}
 		if !canExecute {
 			state, _ := breaker.GetState()
 			logger.Error("Circuit breaker OPEN: %s", state.Reason)
 			breaker.PrintHaltMessage()
+			if d := recovery.ForBreaker(".", state); d != nil {
+				d.Print()
+				logger.Info("Suggested remediations: %s", d.Summary())
+			}
 			return nil
 		}
 
-		// Get next task
-		nextTask, err := reader.GetNextTask()
-		if err != nil {
-			return err
+		// Get next task, continuing the checkpointed task first when resuming
+		var nextTask *task.Task
+		if resumeTaskID != "" {
+			nextTask, _ = reader.GetTaskByID(resumeTaskID)
+			if nextTask != nil && nextTask.Status != task.StatusInProgress && nextTask.Status != task.StatusAtRisk {
+				nextTask = nil
+			}
+			resumeTaskID = ""
 		}
 		if nextTask == nil {
+			nextTask, err = reader.GetNextTask()
+			if err != nil {
+				return err
+			}
+		}
+		if nextTask == nil {
+			if reader.Milestone() != "" {
+				return finishMilestone(reader, gitOps, logger, recorder)
+			}
 			logger.Success("All tasks completed!")
 			return nil
 		}
 
+		// Block a task that keeps failing, counting the attempts of earlier runs
+		if history, err := report.LoadTaskHistory(".", nextTask.ID); err == nil && history.RetriesExhausted(cfg.TaskMode.MaxRetries) {
+			reason := fmt.Sprintf("%d attempts in a row failed (taskMode.maxRetries is %d)", history.Failures(), cfg.TaskMode.MaxRetries)
+			logger.Warn("Task %s is BLOCKED: %s", nextTask.ID, reason)
+			if err := task.NewStatusUpdater(".").BlockTask(nextTask.ID, reason); err != nil {
+				return fmt.Errorf("failed to block task %s: %w", nextTask.ID, err)
+			}
+			breaker.TaskBlocked(nextTask.ID, loopNumber)
+			continue
+		}
+
 		ui.PrintTaskHeader(nextTask)
 		logger.Info("Working on task: %s - %s", nextTask.ID, nextTask.Name)
+		loopSpan.SetAttributes(attribute.String("task.id", nextTask.ID), attribute.String("task.name", nextTask.Name))
 
 		// Set task status to IN_PROGRESS before starting
 		statusUpdater := task.NewStatusUpdater(".")
 		if err := statusUpdater.UpdateTaskStatus(nextTask.ID, task.StatusInProgress); err != nil {
 			logger.Warn("Failed to set task IN_PROGRESS: %v", err)
 		}
+		if nextTask.BlockedReason != "" {
+			if err := statusUpdater.SetBlockedReason(nextTask.ID, ""); err != nil {
+				logger.Warn("Failed to clear blocked reason: %v", err)
+			}
+		}
+
+		cp.Loop, cp.TaskID = loopNumber, nextTask.ID
+		saveCheckpoint()
+		if rollback != nil && !snapshotted[nextTask.ID] {
+			if err := rollback.SaveSnapshot(nextTask.ID); err != nil {
+				logger.Debug("Failed to save snapshot: %v", err)
+			}
+			snapshotted[nextTask.ID] = true
+		}
+
+		if taskLog, err = tasklog.Create(".", nextTask.ID); err != nil {
+			logger.Warn("Failed to create task log: %v", err)
+		} else {
+			logger.Debug("Task log: %s", taskLog.Path())
+			gitOps.SetCommandLog(taskLog)
+			taskLog.Section("Git")
+		}
 
 		// Handle branching
 		if autoBranch && gitOps.IsRepository() {
@@ -229,18 +586,49 @@ func runExecute(cmd *cobra.Command, args []string) error {
 		}
 
 		// Inject task into prompt
-		if err := injector.AddTask(nextTask); err != nil {
+		if err := injector.AddTaskWithFeedback(nextTask, feedback[nextTask.ID]); err != nil {
 			logger.Warn("Failed to inject task: %v", err)
 		}
 		promptContent, _ := injector.Read()
 
 		// Execute AI
 		executor := ai.NewTaskExecutor(provider, ".")
-		result, err := executor.ExecuteTask(ctx, nextTask, promptContent, cfg.AI.StreamOutput)
+		executor.SetProjectContext(converter.ProjectContext(".", cfg.AI.ProjectContext))
+		executor.SetGitHistory(gitOps.TaskHistory(cfg.AI.GitHistory))
+		executor.SetWarn(logger.Warn)
+		if cfg.AI.Memory {
+			executor.SetMemory(".")
+		}
+		if taskLog != nil {
+			executor.SetTranscript(taskLog)
+		}
+		// Only a timeout set for the task or its effort limits the AI here
+		taskCtx := func() (context.Context, context.CancelFunc) {
+			if timeout := nextTask.ResolveTimeout(0, cfg.AI.EffortTimeouts); timeout > 0 {
+				return context.WithTimeout(loopCtx, timeout)
+			}
+			return context.WithCancel(loopCtx)
+		}
+		taskStart := time.Now()
+		execCtx, execCancel := taskCtx()
+		result, err := executor.ExecuteTask(execCtx, nextTask, promptContent, cfg.AI.StreamOutput)
+		execCancel()
+		taskRecord := report.TaskRecord{
+			TaskID:    nextTask.ID,
+			TaskName:  nextTask.Name,
+			FeatureID: nextTask.FeatureID,
+			Effort:    nextTask.EstimatedEffort,
+			Duration:  time.Since(taskStart),
+			Prompt:    promptContent,
+		}
 
 		if err != nil {
 			logger.Error("AI execution failed: %v", err)
-			breaker.AddLoopResultWithErrorLimit(false, true, loopNumber, cfg.TaskMode.MaxConsecutiveErrors)
+			taskRecord.Outcome = report.OutcomeFailed
+			taskRecord.Error = err.Error()
+			recorder.RecordTask(taskRecord)
+			feedback[nextTask.ID] = prompt.Failure{Reason: prompt.ReasonError, Details: err.Error()}.Feedback()
+			breaker.AddTaskLoopResult(nextTask.ID, false, true, loopNumber, cfg.TaskMode.MaxConsecutiveErrors, cfg.TaskMode.MaxRetries)
 
 			// Wait before retry
 			time.Sleep(time.Duration(cfg.Loop.ErrorDelay) * time.Second)
@@ -249,24 +637,38 @@ func runExecute(cmd *cobra.Command, args []string) error {
 
 		// Analyze response
 		// Check if HERMES_STATUS block is present - if not, treat as error and retry
+		taskRecord.Cost = result.Cost
+		taskRecord.Output = result.Output
+		runCost += result.Cost
 		if !respAnalyzer.HasStatusBlock(result.Output) {
 			logger.Warn("Task %s missing HERMES_STATUS block - will retry", nextTask.ID)
-			breaker.AddLoopResultWithErrorLimit(false, true, loopNumber, cfg.TaskMode.MaxConsecutiveErrors)
+			taskLog.Section("Analysis")
+			taskLog.Printf("Missing HERMES_STATUS block, the task will be retried\n")
+			taskRecord.Outcome = report.OutcomeFailed
+			taskRecord.Error = "missing HERMES_STATUS block"
+			recorder.RecordTask(taskRecord)
+			feedback[nextTask.ID] = prompt.Failure{Reason: prompt.ReasonNoStatusBlock, Output: result.Output}.Feedback()
+			breaker.AddTaskLoopResult(nextTask.ID, false, true, loopNumber, cfg.TaskMode.MaxConsecutiveErrors, cfg.TaskMode.MaxRetries)
 			time.Sleep(time.Duration(cfg.Loop.ErrorDelay) * time.Second)
 			continue
 		}
 
+		delete(feedback, nextTask.ID)
 		analysis := respAnalyzer.AnalyzeWithCriteria(result.Output, nextTask.SuccessCriteria)
 		logger.Debug("Analysis: progress=%v complete=%v blocked=%v atRisk=%v paused=%v confidence=%.2f criteria=%d/%d",
 			analysis.HasProgress, analysis.IsComplete, analysis.IsBlocked, analysis.IsAtRisk, analysis.IsPaused, analysis.Confidence, analysis.CriteriaMet, analysis.CriteriaTotal)
+		taskLog.Analysis(analysis)
 
 		// Update circuit breaker
-		breaker.AddLoopResultWithErrorLimit(analysis.HasProgress, false, loopNumber, cfg.TaskMode.MaxConsecutiveErrors)
+		breaker.AddTaskLoopResult(nextTask.ID, analysis.HasProgress, false, loopNumber, cfg.TaskMode.MaxConsecutiveErrors, cfg.TaskMode.MaxRetries)
 
 		// Handle blocked status
 		if analysis.IsBlocked {
 			logger.Warn("Task %s is BLOCKED: %s", nextTask.ID, analysis.Recommendation)
-			if err := statusUpdater.UpdateTaskStatus(nextTask.ID, task.StatusBlocked); err != nil {
+			taskRecord.Outcome = report.OutcomeBlocked
+			taskRecord.Error = analysis.Recommendation
+			recorder.RecordTask(taskRecord)
+			if err := statusUpdater.BlockTask(nextTask.ID, analysis.Recommendation); err != nil {
 				logger.Warn("Failed to update task status: %v", err)
 			}
 			injector.RemoveTask()
@@ -285,6 +687,8 @@ func runExecute(cmd *cobra.Command, args []string) error {
 		// Handle paused status
 		if analysis.IsPaused {
 			logger.Info("Task %s is PAUSED: %s", nextTask.ID, analysis.Recommendation)
+			taskRecord.Outcome = report.OutcomePaused
+			recorder.RecordTask(taskRecord)
 			if err := statusUpdater.UpdateTaskStatus(nextTask.ID, task.StatusPaused); err != nil {
 				logger.Warn("Failed to update task status: %v", err)
 			}
@@ -292,28 +696,111 @@ func runExecute(cmd *cobra.Command, args []string) error {
 			continue // Move to next task
 		}
 
+		// A task with subtasks completes one subtask per loop and stays
+		// IN_PROGRESS until its last subtask is done
+		if analysis.IsComplete {
+			if sub, remaining, err := statusUpdater.CompleteNextSubtask(nextTask); err != nil {
+				logger.Warn("Failed to update subtask: %v", err)
+			} else if sub != nil && remaining > 0 {
+				logger.Success("Subtask %s completed, %d left", sub.ID, remaining)
+				taskRecord.Subtask = sub.ID
+				analysis.IsComplete = false
+			}
+		}
+
+		// Format the changes, then run the build, lint and test commands and the
+		// task's command criteria before accepting COMPLETE, letting the AI fix
+		// failures a bounded number of times, and check the coverage
+		verifyTimeout := time.Duration(cfg.TaskMode.VerifyTimeout) * time.Second
+		format := func() {
+			if err := verify.Format(loopCtx, ".", cfg.TaskMode.FormatCommand, verifyTimeout, taskLog); err != nil {
+				logger.Warn("Format command failed: %v", err)
+			}
+		}
+		if analysis.IsComplete && cfg.TaskMode.FormatCommand != "" {
+			taskLog.Section("Format")
+			format()
+		}
+		if commands := nextTask.GateCommands(cfg.TaskMode.TestCommand, cfg.TaskMode.BuildCommand, cfg.TaskMode.LintCommand); analysis.IsComplete && (len(commands) > 0 || cfg.TaskMode.CoverageThreshold > 0) {
+			logger.Info("Verifying task %s: %s", nextTask.ID, strings.Join(commands, ", "))
+			taskLog.Section("Verification")
+			fix := func(checks string) error {
+				logger.Warn("Task %s failed verification, asking the AI to fix it", nextTask.ID)
+				failure := prompt.Failure{Reason: prompt.ReasonVerification, Details: checks}
+				if err := injector.AddTaskWithFeedback(nextTask, failure.Feedback()); err != nil {
+					return err
+				}
+				fixPrompt, _ := injector.Read()
+				fixCtx, fixCancel := taskCtx()
+				defer fixCancel()
+				fixResult, err := executor.ExecuteTask(fixCtx, nextTask, fixPrompt, cfg.AI.StreamOutput)
+				if err != nil {
+					return err
+				}
+				taskRecord.Cost += fixResult.Cost
+				runCost += fixResult.Cost
+				format()
+				return nil
+			}
+			failed, _ := verify.Gate(loopCtx, ".", commands, verifyTimeout, taskLog, cfg.TaskMode.TestFixAttempts, fix)
+			if tm := cfg.TaskMode; len(failed) == 0 && tm.CoverageThreshold > 0 {
+				taskLog.Section("Coverage")
+				if r := verify.Coverage(loopCtx, ".", tm.CoverageCommand, tm.CoverageProfile, tm.CoverageThreshold, verifyTimeout, taskLog); r != nil {
+					failed = []verify.Result{*r}
+				}
+			}
+			taskRecord.Duration = time.Since(taskStart)
+			if len(failed) > 0 {
+				logger.Warn("Task %s failed verification, it will be retried: %s", nextTask.ID, verify.Summary(failed))
+				feedback[nextTask.ID] = prompt.Failure{Reason: prompt.ReasonVerification, Details: verify.Feedback(failed), Analysis: analysis}.Feedback()
+				taskRecord.Error = verify.Summary(failed)
+				analysis.IsComplete = false
+				analysis.Status = "IN_PROGRESS"
+			}
+		}
+
 		// Update task status if complete
 		if analysis.IsComplete {
 			// Remove task from prompt
 			injector.RemoveTask()
 
-			// Set task status to COMPLETED before commit
+			// Record the time of all attempts and set task status to COMPLETED before commit
+			if err := statusUpdater.SetActualDuration(nextTask.ID, recorder.PendingDuration(nextTask.ID)+taskRecord.Duration); err != nil {
+				logger.Warn("Failed to record actual duration: %v", err)
+			}
 			if err := statusUpdater.UpdateTaskStatus(nextTask.ID, task.StatusCompleted); err != nil {
 				logger.Warn("Failed to update task status: %v", err)
 			}
 
 			// Auto-commit (includes the status update)
+			taskLog.Section("Git")
 			if autoCommit && gitOps.HasUncommittedChanges() {
-				if err := gitOps.StageAll(); err == nil {
-					if err := gitOps.CommitTask(nextTask.ID, nextTask.Name); err != nil {
+				if outside, err := gitOps.StageTask(nextTask.ScopeFiles()); err == nil {
+					if len(outside) > 0 {
+						logger.Warn("Not committing changes outside the files of task %s: %s", nextTask.ID, strings.Join(outside, ", "))
+					}
+					if err := gitOps.CommitTaskInScope(nextTask); err != nil {
 						logger.Warn("Failed to commit: %v", err)
 					} else {
 						logger.Success("Committed task %s", nextTask.ID)
+						if hash, err := gitOps.GetLastCommitShortHash(); err == nil {
+							msg, _ := gitOps.GetLastCommitMessage()
+							stat := commitStat(gitOps, hash)
+							if stat != "" {
+								logger.Info("Changes: %s", stat)
+							}
+							recorder.RecordCommit(hash, msg, stat)
+						}
 					}
 				}
 			}
+			if autoCommit && cfg.Git.TrackTasks {
+				commitTaskFiles(gitOps, cfg, logger)
+			}
 
 			logger.Success("Task %s completed", nextTask.ID)
+			taskRecord.Outcome = report.OutcomeCompleted
+			recorder.RecordTask(taskRecord)
 
 			// Check if feature is complete and create tag
 			if featureComplete, _ := reader.IsFeatureComplete(nextTask.FeatureID); featureComplete {
@@ -321,31 +808,55 @@ func runExecute(cmd *cobra.Command, args []string) error {
 				if feature != nil {
 					logger.Success("Feature %s completed: %s", feature.ID, feature.Name)
 
-					// Merge feature branch to main if auto-branch is enabled
+					// Merge feature branch to main, or open a pull request for it, if auto-branch is enabled
+					prOpened := false
 					if autoBranch && gitOps.IsRepository() {
-						if err := gitOps.MergeFeatureBranch(feature.ID, feature.Name); err != nil {
+						if cfg.Git.PullRequests {
+							if url, err := openFeaturePullRequest(loopCtx, cfg, gitOps, feature); err != nil {
+								logger.Warn("Failed to open pull request: %v", err)
+							} else {
+								logger.Success("Opened pull request: %s", url)
+								prOpened = true
+							}
+						} else if err := gitOps.MergeFeatureBranch(feature.ID, feature.Name); err != nil {
 							logger.Warn("Failed to merge feature branch: %v", err)
 						} else {
 							logger.Success("Merged feature branch to %s", gitOps.GetMainBranch())
 						}
 					}
 
-					// Create git tag if TargetVersion is set
-					if feature.TargetVersion != "" && gitOps.IsRepository() {
-						if err := gitOps.CreateFeatureTag(feature.ID, feature.Name, feature.TargetVersion); err != nil {
+					// Create git tag if TargetVersion is set; with a pull request the
+					// feature is not on the main branch yet, so tagging is left to the merge
+					if prOpened && feature.TargetVersion != "" {
+						logger.Info("Skipping tag %s until the pull request is merged", feature.TargetVersion)
+					} else if feature.TargetVersion != "" && gitOps.IsRepository() {
+						if err := gitOps.CreateFeatureRelease(feature, featureRelease(loopCtx, cfg)); err != nil {
 							logger.Warn("Failed to create tag: %v", err)
 						} else {
 							logger.Success("Created tag: %s", feature.TargetVersion)
+							recorder.RecordTag(feature.TargetVersion)
 						}
 					}
 				}
 			}
 
+			// Push the task commit and any feature merge and tag for CI
+			if autoCommit && cfg.Git.AutoPush && gitOps.IsRepository() {
+				pushToRemote(loopCtx, gitOps, cfg, logger)
+			}
+
 			// Show progress
 			if progress, err := reader.GetProgress(); err == nil {
 				bar := ui.FormatProgressBar(progress.Percentage, 30)
 				fmt.Printf("\nProgress: %s\n", bar)
 			}
+		} else {
+			taskRecord.Outcome = report.OutcomeIncomplete
+			if analysis.IsAtRisk {
+				taskRecord.Outcome = report.OutcomeAtRisk
+				taskRecord.Error = analysis.Recommendation
+			}
+			recorder.RecordTask(taskRecord)
 		}
 
 		// Pause between tasks if not autonomous
@@ -356,8 +867,81 @@ func runExecute(cmd *cobra.Command, args []string) error {
 	}
 }
 
-// runParallel executes tasks in parallel mode
-func runParallel(ctx context.Context, cfg *config.Config, provider ai.Provider, reader *task.Reader, logger *ui.Logger, workers int, dryRun bool) error {
+// newRunRecorder creates the run recorder, continuing the interrupted run's
+// record when resuming
+func newRunRecorder(resume *checkpoint.Checkpoint, mode, provider string) *report.Recorder {
+	if resume != nil {
+		if recorder, err := report.ResumeRecorder(".", resume.RunID); err == nil {
+			return recorder
+		}
+	}
+	return report.NewRecorder(".", mode, provider)
+}
+
+// selectCodingProvider returns the provider named by aiFlag, falling back to
+// the configured coding provider and then auto-detection
+func selectCodingProvider(aiFlag string, cfg *config.Config) (ai.Provider, error) {
+	var provider ai.Provider
+
+	if aiFlag != "" && aiFlag != "auto" {
+		provider = ai.GetProvider(aiFlag)
+		if provider == nil {
+			return nil, fmt.Errorf("unknown AI provider: %s", aiFlag)
+		}
+		if !provider.IsAvailable() {
+			return nil, fmt.Errorf("AI provider %s is not available (not installed)", aiFlag)
+		}
+		return provider, nil
+	}
+
+	// Use config or auto-detect
+	if cfg.AI.Coding != "" && cfg.AI.Coding != "auto" {
+		provider = ai.GetProvider(cfg.AI.Coding)
+	}
+	if provider == nil || !provider.IsAvailable() {
+		provider = ai.AutoDetectProvider()
+	}
+	if provider == nil {
+		return nil, fmt.Errorf("no AI provider available (install claude or droid)")
+	}
+	return provider, nil
+}
+
+// commitStat returns the compact diffstat of a commit, or "" if it cannot be read
+func commitStat(gitOps *git.Git, hash string) string {
+	stat, err := gitOps.GetCommitDiffStat(hash)
+	if err != nil {
+		return ""
+	}
+	return stat.String()
+}
+
+// ConfigureSandbox applies the provider sandbox from the project config so
+// every command that spawns an AI provider runs it confined. It fails when
+// the config cannot be loaded, as providers would otherwise run unconfined.
+func ConfigureSandbox() error {
+	cfg, err := config.Load(".")
+	if err != nil {
+		return err
+	}
+	sb := cfg.AI.Sandbox
+	return ai.SetSandbox(ai.Sandbox{
+		Mode:       sb.Mode,
+		User:       sb.User,
+		Properties: sb.Properties,
+		PassEnv:    append(sb.PassEnv, secretEnvNames(cfg)...),
+		Image:      sb.Image,
+		Runtime:    sb.Runtime,
+		RunArgs:    sb.RunArgs,
+		EnvAllow:   sb.EnvAllow,
+		EnvStrip:   sb.EnvStrip,
+	})
+}
+
+// runParallel executes tasks in parallel mode. When resume is set, only the
+// remaining batch queue of the interrupted run is executed. Closing drain
+// lets the running tasks finish without starting further ones.
+func runParallel(ctx context.Context, drain <-chan struct{}, reload <-chan *config.Config, cfg *config.Config, provider ai.Provider, reader *task.Reader, logger *ui.Logger, workers int, dryRun bool, resume *checkpoint.Checkpoint) error {
 	ui.PrintHeader("Parallel Task Execution")
 
 	// Get all tasks (including completed for dependency resolution)
@@ -365,16 +949,51 @@ func runParallel(ctx context.Context, cfg *config.Config, provider ai.Provider,
 	if err != nil {
 		return fmt.Errorf("failed to get tasks: %w", err)
 	}
+	if allTasks, err = reader.FilterMilestone(allTasks); err != nil {
+		return fmt.Errorf("failed to get tasks: %w", err)
+	}
+
+	// Block tasks that keep failing, counting the attempts of earlier runs
+	statusUpdater := task.NewStatusUpdater(".")
+	for i := range allTasks {
+		t := &allTasks[i]
+		if t.Status == task.StatusCompleted || t.Status == task.StatusBlocked {
+			continue
+		}
+		if history, err := report.LoadTaskHistory(".", t.ID); err == nil && history.RetriesExhausted(cfg.TaskMode.MaxRetries) {
+			reason := fmt.Sprintf("%d attempts in a row failed (taskMode.maxRetries is %d)", history.Failures(), cfg.TaskMode.MaxRetries)
+			logger.Warn("Task %s is BLOCKED: %s", t.ID, reason)
+			if err := statusUpdater.BlockTask(t.ID, reason); err != nil {
+				return fmt.Errorf("failed to block task %s: %w", t.ID, err)
+			}
+			t.Status = task.StatusBlocked
+		}
+	}
 
 	// Count pending tasks
 	pendingCount := 0
+	queued := make(map[string]bool)
+	if resume != nil {
+		for _, batch := range resume.Batches {
+			for _, id := range batch {
+				queued[id] = true
+			}
+		}
+	}
 	for i := range allTasks {
-		if allTasks[i].Status == task.StatusNotStarted {
+		if resume != nil {
+			if queued[allTasks[i].ID] && allTasks[i].Status != task.StatusCompleted {
+				pendingCount++
+			}
+		} else if allTasks[i].Status == task.StatusNotStarted {
 			pendingCount++
 		}
 	}
 
 	if pendingCount == 0 {
+		if resume != nil {
+			checkpoint.Clear(".")
+		}
 		logger.Success("No pending tasks to execute!")
 		return nil
 	}
@@ -395,15 +1014,36 @@ func runParallel(ctx context.Context, cfg *config.Config, provider ai.Provider,
 	// Create scheduler with task timeout from config
 	taskTimeout := time.Duration(cfg.AI.Timeout) * time.Second
 	sched := scheduler.NewWithTimeout(&parallelCfg, provider, ".", logger, taskTimeout)
+	sched.SetEffortTimeouts(cfg.AI.EffortTimeouts)
+	if cfg.Parallel.ProviderFallback {
+		sched.SetFallbackProviders(ai.FallbackProviders(provider.Name()))
+	}
+	sched.SetAnalyzerConfig(&cfg.Analyzer)
+	sched.SetTaskModeConfig(&cfg.TaskMode)
+	sched.SetProjectContext(&cfg.AI.ProjectContext)
+	sched.SetMemory(cfg.AI.Memory)
+	sched.SetGitHistory(cfg.AI.GitHistory)
+	sched.SetRelease(featureRelease(ctx, cfg))
+	sched.SetCommitPolicy(git.NewCommitPolicy(cfg.Git))
+	sched.SetSigning(git.NewSigning(cfg.Git))
+	sched.SetCommitFormat(git.NewCommitFormat(cfg.Git))
+	sched.SetBreakerCooldown(cfg.BreakerCooldown())
 
 	// Get execution plan (uses all tasks for dependency resolution, but only executes pending)
-	plan, err := sched.GetExecutionPlan(allTaskPtrs)
-	if err != nil {
-		return fmt.Errorf("failed to create execution plan: %w", err)
-	}
+	var batchCount int
+	if resume != nil {
+		batchCount = len(resume.Batches)
+		logger.Info("Resuming run %s with %d remaining batches", resume.RunID, batchCount)
+	} else {
+		plan, err := sched.GetExecutionPlan(allTaskPtrs)
+		if err != nil {
+			return fmt.Errorf("failed to create execution plan: %w", err)
+		}
+		batchCount = len(plan.Batches)
 
-	// Print execution plan
-	sched.PrintExecutionPlan(plan)
+		// Print execution plan
+		sched.PrintExecutionPlan(plan)
+	}
 
 	// If dry-run, stop here
 	if dryRun {
@@ -424,19 +1064,41 @@ func runParallel(ctx context.Context, cfg *config.Config, provider ai.Provider,
 
 	// Initialize resource monitor
 	resourceMonitor := scheduler.NewResourceMonitor(
-		0, // No memory limit
-		0, // No CPU limit
+		cfg.Parallel.MaxMemoryMB,
+		cfg.Parallel.MaxCPUPercent,
 		cfg.Loop.MaxCallsPerHour,
 	)
 	if cfg.Parallel.MaxCostPerHour > 0 {
 		resourceMonitor.SetCostLimit(cfg.Parallel.MaxCostPerHour)
 	}
+	sched.SetResourceMonitor(resourceMonitor)
+	sched.SetDrain(drain)
+
+	// Apply reloaded worker and cost limits to the execution in progress
+	go func() {
+		current := *cfg
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case updated := <-reload:
+				applied, ignored := applyConfigChanges(&current, updated, parallelReloadable)
+				logConfigChanges(logger, applied, ignored)
+				if slices.Contains(applied, "parallel.maxWorkers") {
+					sched.SetMaxWorkers(current.Parallel.MaxWorkers)
+				}
+				if slices.Contains(applied, "parallel.maxCostPerHour") {
+					resourceMonitor.SetCostLimit(current.Parallel.MaxCostPerHour)
+				}
+			}
+		}
+	}()
 
 	// Initialize rollback manager
 	rollback := scheduler.NewRollback(".")
 	defer func() {
-		// Cleanup on exit
-		if rollback.HasSnapshots() {
+		// Cleanup on exit, keeping the worktrees of an interrupted run for 'hermes resume'
+		if rollback.HasSnapshots() && ctx.Err() == nil && !scheduler.IsClosed(drain) {
 			rollback.CleanupWorktrees()
 		}
 	}()
@@ -449,21 +1111,79 @@ func runParallel(ctx context.Context, cfg *config.Config, provider ai.Provider,
 	if err := rollback.SaveSnapshot("INITIAL"); err != nil {
 		logger.Warn("Failed to save initial snapshot: %v", err)
 	}
+	sched.SetRollback(rollback)
 
 	// Log execution start
 	if parallelLogger != nil {
 		parallelLogger.Main("Starting parallel execution with %d workers", workers)
-		parallelLogger.Main("Total tasks: %d, Batches: %d", pendingCount, len(plan.Batches))
+		parallelLogger.Main("Total tasks: %d, Batches: %d", pendingCount, batchCount)
+	}
+
+	// Record run data for 'hermes report'
+	recorder := newRunRecorder(resume, checkpoint.ModeParallel, provider.Name())
+	defer recorder.Finish()
+
+	// Checkpoint the batch queue before each batch so 'hermes resume' can continue it
+	breaker := circuit.New(".")
+	cp := &checkpoint.Checkpoint{
+		RunID:     recorder.GetRun().ID,
+		Mode:      checkpoint.ModeParallel,
+		Provider:  provider.Name(),
+		Options:   checkpoint.Options{AutoCommit: cfg.TaskMode.AutoCommit, Workers: workers, Until: reader.Milestone()},
+		StartTime: recorder.GetRun().StartTime,
+	}
+	sched.SetBatchCallback(func(batchNum int, remaining [][]*task.Task) {
+		cp.Loop = batchNum
+		cp.Batches = nil
+		for _, batch := range remaining {
+			var ids []string
+			for _, t := range batch {
+				ids = append(ids, t.ID)
+			}
+			cp.Batches = append(cp.Batches, ids)
+		}
+		cp.Elapsed = time.Since(cp.StartTime)
+		cp.Breaker, _ = breaker.GetState()
+		if err := checkpoint.Save(".", cp); err != nil {
+			logger.Warn("Failed to save checkpoint: %v", err)
+		}
+	})
+	gitOps := git.New(".")
+	startHead, _ := gitOps.GetLastCommitHash()
+	tagsBefore, _ := gitOps.ListTags()
+	if cfg.Git.TrackTasks {
+		enableTaskTracking(gitOps, cfg, logger)
 	}
 
 	// Execute tasks
 	logger.Info("Starting parallel execution...")
 	startTime := time.Now()
-
-	result, err := sched.Execute(ctx, allTaskPtrs)
+	traceCtx, runSpan := tracing.Start(ctx, "run",
+		attribute.String("run.id", recorder.GetRun().ID),
+		attribute.String("run.mode", checkpoint.ModeParallel),
+		attribute.String("provider", provider.Name()))
+
+	var result *scheduler.ExecutionResult
+	if resume != nil {
+		result, err = sched.Resume(traceCtx, allTaskPtrs, resume.Batches)
+	} else {
+		result, err = sched.Execute(traceCtx, allTaskPtrs)
+	}
+	tracing.End(runSpan, err)
+	if ctx.Err() == nil && !scheduler.IsClosed(drain) {
+		checkpoint.Clear(".")
+	}
 	
 	executionTime := time.Since(startTime)
 
+	if cfg.Git.TrackTasks && cfg.TaskMode.AutoCommit {
+		commitTaskFiles(gitOps, cfg, logger)
+	}
+
+	if result != nil {
+		recordParallelRun(recorder, gitOps, result, allTaskPtrs, startHead, tagsBefore)
+	}
+
 	if err != nil {
 		logger.Error("Parallel execution failed: %v", err)
 		if parallelLogger != nil {
@@ -504,6 +1224,10 @@ func runParallel(ctx context.Context, cfg *config.Config, provider ai.Provider,
 	logger.Info("Total execution time: %v", executionTime.Round(time.Second))
 	fmt.Printf("\n⏱️  Total execution time: %v\n", executionTime.Round(time.Second))
 
+	if cfg.Git.AutoPush && result.Successful > 0 && gitOps.IsRepository() {
+		pushToRemote(ctx, gitOps, cfg, logger)
+	}
+
 	// Note: Task status updates and tag creation are handled in scheduler.go
 	// after each merge to ensure tags are created on the correct commit
 
@@ -521,9 +1245,120 @@ func runParallel(ctx context.Context, cfg *config.Config, provider ai.Provider,
 	}
 
 	logger.Success("All %d tasks completed successfully!", result.Successful)
+	if reader.Milestone() != "" {
+		return finishMilestone(reader, gitOps, logger, recorder)
+	}
+	return nil
+}
+
+// checkMilestone returns an error if no feature targets the milestone version or an earlier one
+func checkMilestone(reader *task.Reader, version string) error {
+	features, err := reader.GetAllFeatures()
+	if err != nil {
+		return fmt.Errorf("failed to read features: %w", err)
+	}
+	for i := range features {
+		if features[i].InMilestone(version) {
+			return nil
+		}
+	}
+	return fmt.Errorf("no feature targets version %s or earlier, set **Target Version:** in its feature file", version)
+}
+
+// finishMilestone tags the milestone version of a 'hermes run --until' once
+// all tasks of its features are completed
+func finishMilestone(reader *task.Reader, gitOps *git.Git, logger *ui.Logger, recorder *report.Recorder) error {
+	version := reader.Milestone()
+	allTasks, err := reader.GetAllTasks()
+	if err != nil {
+		return err
+	}
+	tasks, err := reader.FilterMilestone(allTasks)
+	if err != nil {
+		return err
+	}
+	var open []string
+	for _, t := range tasks {
+		if t.Status != task.StatusCompleted {
+			open = append(open, t.ID)
+		}
+	}
+	if len(open) > 0 {
+		logger.Warn("Milestone %s not reached, %d tasks are not completed: %s", version, len(open), strings.Join(open, ", "))
+		return nil
+	}
+	logger.Success("Milestone %s reached!", version)
+
+	// Normalize the tag like feature tags
+	tag := version
+	if tag[0] != 'v' {
+		tag = "v" + tag
+	}
+	if !gitOps.IsRepository() || gitOps.TagExists(tag) {
+		return nil
+	}
+	if err := gitOps.CreateTag(tag, fmt.Sprintf("Milestone %s", tag)); err != nil {
+		return fmt.Errorf("failed to create tag %s: %w", tag, err)
+	}
+	logger.Success("Created tag: %s", tag)
+	recorder.RecordTag(tag)
 	return nil
 }
 
+// recordParallelRun records task results, commits and tags of a parallel run
+func recordParallelRun(recorder *report.Recorder, gitOps *git.Git, result *scheduler.ExecutionResult, tasks []*task.Task, startHead string, tagsBefore []string) {
+	featureIDs := make(map[string]string)
+	efforts := make(map[string]string)
+	for _, t := range tasks {
+		featureIDs[t.ID] = t.FeatureID
+		efforts[t.ID] = t.EstimatedEffort
+	}
+
+	// Commits first, so completed task events can carry their diffstat
+	if startHead != "" {
+		commits, _ := gitOps.GetCommitsSince(startHead)
+		for _, c := range commits {
+			hash, msg, _ := strings.Cut(c, " ")
+			recorder.RecordCommit(hash, msg, commitStat(gitOps, hash))
+		}
+	}
+
+	statusUpdater := task.NewStatusUpdater(".")
+	for _, r := range result.Results {
+		rec := report.TaskRecord{
+			TaskID:    r.TaskID,
+			TaskName:  r.TaskName,
+			FeatureID: featureIDs[r.TaskID],
+			Effort:    efforts[r.TaskID],
+			Outcome:   report.OutcomeCompleted,
+			Duration:  r.Duration,
+			Time:      r.EndTime,
+			Prompt:    r.Prompt,
+			Output:    r.Output,
+		}
+		if !r.Success {
+			rec.Outcome = report.OutcomeFailed
+			if r.Error != nil {
+				rec.Error = r.Error.Error()
+			}
+		} else {
+			statusUpdater.SetActualDuration(r.TaskID, recorder.PendingDuration(r.TaskID)+r.Duration)
+		}
+		recorder.RecordTask(rec)
+	}
+
+	existing := make(map[string]bool)
+	for _, tag := range tagsBefore {
+		existing[tag] = true
+	}
+	tagsAfter, _ := gitOps.ListTags()
+	for _, tag := range tagsAfter {
+		if !existing[tag] {
+			recorder.RecordTag(tag)
+		}
+	}
+}
+
 // runSequentialDryRun shows execution plan for sequential mode without running
 func runSequentialDryRun(reader *task.Reader, logger *ui.Logger, breaker *circuit.Breaker, gitOps *git.Git, autoBranch bool) error {
 	ui.PrintHeader("Sequential Execution Plan (Dry Run)")
@@ -533,6 +1368,9 @@ func runSequentialDryRun(reader *task.Reader, logger *ui.Logger, breaker *circui
 	if err != nil {
 		return fmt.Errorf("failed to get tasks: %w", err)
 	}
+	if allTasks, err = reader.FilterMilestone(allTasks); err != nil {
+		return fmt.Errorf("failed to get tasks: %w", err)
+	}
 
 	// Get progress
 	progress, _ := reader.GetProgress()
@@ -611,3 +1449,145 @@ func runSequentialDryRun(reader *task.Reader, logger *ui.Logger, breaker *circui
 
 	return nil
 }
+
+// handleDirtyTree applies the git.dirtyTree policy to uncommitted changes
+// found before a run. The returned function restores stashed changes.
+func handleDirtyTree(gitOps *git.Git, policy string, logger *ui.Logger) (func(), error) {
+	noop := func() {}
+	files, err := gitOps.DirtyFiles()
+	if err != nil || len(files) == 0 {
+		return noop, nil
+	}
+	listed := strings.Join(files, ", ")
+	if len(files) > 5 {
+		listed = fmt.Sprintf("%s and %d more", strings.Join(files[:5], ", "), len(files)-5)
+	}
+
+	if policy == "prompt" {
+		fmt.Printf("\nThe working tree has uncommitted changes: %s\n", listed)
+		fmt.Print("Stash them until the run ends (s), continue with them (c) or abort (a)? [s/c/A] ")
+		var response string
+		fmt.Scanln(&response)
+		switch strings.ToLower(strings.TrimSpace(response)) {
+		case "s":
+			policy = "stash"
+		case "c":
+			policy = "warn"
+		default:
+			policy = "abort"
+		}
+	}
+
+	switch policy {
+	case "abort":
+		return nil, fmt.Errorf("the working tree has uncommitted changes (%s), commit or stash them first", listed)
+	case "stash":
+		hash, err := gitOps.Stash("hermes: changes before run")
+		if err != nil {
+			return nil, err
+		}
+		logger.Info("Stashed uncommitted changes until the run ends: %s", listed)
+		return func() {
+			if err := gitOps.RestoreStash(hash); err != nil {
+				logger.Warn("%v", err)
+			} else {
+				logger.Info("Restored the stashed changes")
+			}
+		}, nil
+	default:
+		logger.Warn("The working tree has uncommitted changes, task commits may include them: %s", listed)
+		return noop, nil
+	}
+}
+
+// pushToRemote pushes the current branch and its tags to the configured remote
+func pushToRemote(ctx context.Context, gitOps *git.Git, cfg *config.Config, logger *ui.Logger) {
+	if err := gitOps.PushHead(ctx, cfg.Git.Remote, cfg.Git.PushRetries); err != nil {
+		logger.Warn("%v", err)
+		return
+	}
+	logger.Success("Pushed to %s", cfg.Git.Remote)
+}
+
+// ConfigureTaskTracking reads task statuses from the status sidecar when
+// git.trackTasks is set, so every command sees the statuses of runs
+func ConfigureTaskTracking() {
+	cfg, err := config.Load(".")
+	if err != nil || !cfg.Git.TrackTasks {
+		return
+	}
+	task.UseStatusSidecar(cfg.Paths.TasksDir)
+}
+
+// enableTaskTracking switches status updates to the union-merged sidecar so
+// task files can be versioned without status conflicts
+func enableTaskTracking(gitOps *git.Git, cfg *config.Config, logger *ui.Logger) {
+	if err := task.EnableStatusSidecar(".", cfg.Paths.TasksDir); err != nil {
+		logger.Warn("Failed to create task status sidecar: %v", err)
+		return
+	}
+	sidecar := filepath.ToSlash(filepath.Join(cfg.Paths.TasksDir, task.StatusSidecarFile))
+	if err := gitOps.EnsureAttribute(sidecar, "merge=union"); err != nil {
+		logger.Warn("Failed to update .gitattributes: %v", err)
+	}
+}
+
+// commitTaskFiles commits task files and statuses separately from code
+func commitTaskFiles(gitOps *git.Git, cfg *config.Config, logger *ui.Logger) {
+	tasksDir := cfg.Paths.TasksDir
+	if err := gitOps.CommitPaths("chore(tasks): update task status", tasksDir, ".gitattributes"); err != nil {
+		logger.Warn("Failed to commit task files: %v", err)
+	}
+}
+
+// openFeaturePullRequest opens a pull request for a completed feature branch,
+// described by the planning AI
+func openFeaturePullRequest(ctx context.Context, cfg *config.Config, gitOps *git.Git, feature *task.Feature) (string, error) {
+	return github.OpenFeaturePullRequest(gitOps, &github.FeatureRequest{
+		Feature:   feature,
+		Remote:    cfg.Git.Remote,
+		Draft:     cfg.Git.PullRequestDraft,
+		Summarize: planningSummarizer(ctx, cfg),
+	})
+}
+
+// featureRelease returns the release notes settings of feature tags, nil
+// when tags keep their plain message
+func featureRelease(ctx context.Context, cfg *config.Config) *git.Release {
+	if !cfg.Git.ReleaseNotes && cfg.Git.Changelog == "" {
+		return nil
+	}
+	release := &git.Release{Changelog: cfg.Git.Changelog}
+	if cfg.Git.ReleaseNotes {
+		release.Summarize = planningSummarizer(ctx, cfg)
+	}
+	return release
+}
+
+// planningSummarizer returns a function running a prompt on the planning
+// provider, or any available one, and returning its output
+func planningSummarizer(ctx context.Context, cfg *config.Config) func(prompt string) (string, error) {
+	return func(prompt string) (string, error) {
+		var provider ai.Provider
+		if cfg.AI.Planning != "" && cfg.AI.Planning != "auto" {
+			provider = ai.GetProvider(cfg.AI.Planning)
+		}
+		if provider == nil || !provider.IsAvailable() {
+			provider = ai.AutoDetectProvider()
+		}
+		if provider == nil {
+			return "", fmt.Errorf("no AI provider available")
+		}
+		result, err := ai.ExecuteWithRetry(ctx, provider, &ai.ExecuteOptions{
+			Prompt:  prompt,
+			Timeout: cfg.AI.Timeout,
+		}, &ai.RetryConfig{
+			MaxRetries: cfg.AI.MaxRetries,
+			Delay:      time.Duration(cfg.AI.RetryDelay) * time.Second,
+		})
+		if err != nil {
+			return "", err
+		}
+		return result.Output, nil
+	}
+}
@@ -0,0 +1,312 @@
+package cmd
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+
+	"github.com/charmbracelet/x/term"
+	"github.com/fatih/color"
+	"github.com/spf13/cobra"
+	"hermes/internal/config"
+	"hermes/internal/secrets"
+)
+
+// passphraseEnv holds the passphrase of the encrypted store for runs nobody
+// is at the terminal for
+const passphraseEnv = "HERMES_SECRETS_PASSPHRASE"
+
+// NewSecretsCmd creates the secrets command
+func NewSecretsCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "secrets",
+		Short: "Manage provider credentials",
+		Long: `Store API keys in the encrypted .hermes/secrets.enc or the OS keychain and
+reference them by name from the "secrets" section of the config, which sets
+environment variables for the providers and integrations:
+
+  "secrets": {
+    "ANTHROPIC_API_KEY": "store:anthropic",
+    "GEMINI_API_KEY": "keychain:gemini",
+    "JIRA_API_TOKEN": "env:CI_JIRA_TOKEN"
+  }
+
+The store is unlocked with $HERMES_SECRETS_PASSPHRASE or a passphrase prompt.`,
+		// The secrets of the config are not needed to manage them
+		PersistentPreRunE: func(c *cobra.Command, args []string) error {
+			profile, _ := c.Flags().GetString("profile")
+			return ConfigureProfile(profile)
+		},
+	}
+	cmd.AddCommand(newSecretsSetCmd())
+	cmd.AddCommand(newSecretsListCmd())
+	cmd.AddCommand(newSecretsRemoveCmd())
+	return cmd
+}
+
+// newSecretsSetCmd creates the secrets set subcommand
+func newSecretsSetCmd() *cobra.Command {
+	var keychain bool
+	cmd := &cobra.Command{
+		Use:   "set <name>",
+		Short: "Store a secret",
+		Long: `Store a secret, read from a hidden prompt or from stdin, in the encrypted
+store or with --keychain in the OS keychain (macOS keychain, or the Secret
+Service through secret-tool on Linux). An existing secret is replaced.`,
+		Example: `  hermes secrets set anthropic
+  echo "$KEY" | hermes secrets set gemini --keychain`,
+		Args: cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return secretsSetExecute(args[0], keychain)
+		},
+	}
+	cmd.Flags().BoolVar(&keychain, "keychain", false, "Store the secret in the OS keychain")
+	return cmd
+}
+
+// newSecretsListCmd creates the secrets list subcommand
+func newSecretsListCmd() *cobra.Command {
+	return &cobra.Command{
+		Use:   "list",
+		Short: "List the secrets of the config and the store",
+		Long: `List the environment variables the config sets from secrets and whether
+each can be resolved, and the names of the secrets in the encrypted store.
+Secrets themselves are never shown.`,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return secretsListExecute()
+		},
+	}
+}
+
+// newSecretsRemoveCmd creates the secrets remove subcommand
+func newSecretsRemoveCmd() *cobra.Command {
+	var keychain bool
+	cmd := &cobra.Command{
+		Use:   "remove <name>",
+		Short: "Remove a secret",
+		Args:  cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if keychain {
+				if err := secrets.KeychainDelete(args[0]); err != nil {
+					return err
+				}
+			} else if err := updateStore(func(store map[string]string) error {
+				if _, ok := store[args[0]]; !ok {
+					return fmt.Errorf("secret %q is not in %s", args[0], secrets.StorePath("."))
+				}
+				delete(store, args[0])
+				return nil
+			}); err != nil {
+				return err
+			}
+			color.Green("Removed secret %s", args[0])
+			return nil
+		},
+	}
+	cmd.Flags().BoolVar(&keychain, "keychain", false, "Remove the secret from the OS keychain")
+	return cmd
+}
+
+func secretsSetExecute(name string, keychain bool) error {
+	if strings.ContainsAny(name, " \t:") {
+		return fmt.Errorf("invalid secret name %q", name)
+	}
+	value, err := readSecret(fmt.Sprintf("Value of %s: ", name))
+	if err != nil {
+		return err
+	}
+	if value == "" {
+		return fmt.Errorf("the secret must not be empty")
+	}
+
+	source := secrets.SourceStore
+	if keychain {
+		source = secrets.SourceKeychain
+		err = secrets.KeychainSet(name, value)
+	} else {
+		err = updateStore(func(store map[string]string) error {
+			store[name] = value
+			return nil
+		})
+	}
+	if err != nil {
+		return err
+	}
+	color.Green("Stored secret %s", name)
+	fmt.Printf("Reference it from the config, e.g. \"secrets\": {\"ANTHROPIC_API_KEY\": \"%s:%s\"}\n", source, name)
+	return nil
+}
+
+func secretsListExecute() error {
+	cfg, err := config.Load(".")
+	if err != nil {
+		return err
+	}
+
+	resolver := secrets.NewResolver(secrets.StorePath("."), storePassphrase)
+	fmt.Println("Config secrets:")
+	if len(cfg.Secrets) == 0 {
+		fmt.Println("  none")
+	}
+	for _, name := range sortedKeys(cfg.Secrets) {
+		ref := cfg.Secrets[name]
+		env := strings.ToUpper(name)
+		if _, _, err := secrets.ParseRef(ref); err != nil {
+			fmt.Printf("  %-24s %s\n", env, color.RedString("(%v)", err)) // The reference may be a pasted key
+		} else if _, set := os.LookupEnv(env); set {
+			fmt.Printf("  %-24s %s (set in the environment)\n", env, ref)
+		} else if _, err := resolver.Resolve(ref); err != nil {
+			fmt.Printf("  %-24s %s %s\n", env, ref, color.RedString("(%v)", err))
+		} else {
+			fmt.Printf("  %-24s %s %s\n", env, ref, color.GreenString("(ok)"))
+		}
+	}
+
+	path := secrets.StorePath(".")
+	if _, err := os.Stat(path); err != nil {
+		return nil
+	}
+	passphrase, err := storePassphrase()
+	if err != nil {
+		return err
+	}
+	store, err := secrets.LoadStore(path, passphrase)
+	if err != nil {
+		return err
+	}
+	fmt.Printf("\nSecrets in %s:\n", path)
+	for _, name := range sortedKeys(store) {
+		fmt.Printf("  %s\n", name)
+	}
+	return nil
+}
+
+// secretsAnnotation marks the commands ConfigureSecrets runs for
+const secretsAnnotation = "hermes/secrets"
+
+// usesSecrets marks a command that starts AI providers or integrations, which
+// need the credentials of the "secrets" config section
+func usesSecrets(c *cobra.Command) *cobra.Command {
+	if c.Annotations == nil {
+		c.Annotations = make(map[string]string)
+	}
+	c.Annotations[secretsAnnotation] = "true"
+	return c
+}
+
+// UsesSecrets reports whether a command or one of its parents starts AI
+// providers or integrations, so ConfigureSecrets has to run before it. Other
+// commands never ask for the passphrase of the secrets store.
+func UsesSecrets(c *cobra.Command) bool {
+	for ; c != nil; c = c.Parent() {
+		if c.Annotations[secretsAnnotation] != "" {
+			return true
+		}
+	}
+	return false
+}
+
+// ConfigureSecrets sets the environment variables of the "secrets" config
+// section from the secrets they reference, so provider CLIs and integrations
+// find their credentials while the config only names them. A variable that
+// is already set is kept; one whose secret cannot be resolved is reported
+// and left unset.
+func ConfigureSecrets() {
+	cfg, err := config.Load(".")
+	if err != nil || len(cfg.Secrets) == 0 {
+		return
+	}
+	resolver := secrets.NewResolver(secrets.StorePath("."), storePassphrase)
+	for _, name := range sortedKeys(cfg.Secrets) {
+		// Config keys are lower case, environment variables upper case
+		env := strings.ToUpper(name)
+		if _, set := os.LookupEnv(env); set {
+			continue
+		}
+		value, err := resolver.Resolve(cfg.Secrets[name])
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "warning: %s not set: %v\n", env, err)
+			continue
+		}
+		os.Setenv(env, value)
+	}
+}
+
+// secretEnvNames returns the environment variables set from secrets, which
+// a provider sandbox passes on like its passEnv variables
+func secretEnvNames(cfg *config.Config) []string {
+	var names []string
+	for _, name := range sortedKeys(cfg.Secrets) {
+		names = append(names, strings.ToUpper(name))
+	}
+	return names
+}
+
+// updateStore changes the encrypted store, creating it when it doesn't exist
+func updateStore(change func(store map[string]string) error) error {
+	path := secrets.StorePath(".")
+	_, statErr := os.Stat(path)
+	passphrase, err := storePassphrase()
+	if err != nil {
+		return err
+	}
+	if os.IsNotExist(statErr) && os.Getenv(passphraseEnv) == "" {
+		confirm, err := readSecret("Repeat the passphrase: ")
+		if err != nil {
+			return err
+		}
+		if confirm != passphrase {
+			return fmt.Errorf("the passphrases do not match")
+		}
+	}
+
+	store, err := secrets.LoadStore(path, passphrase)
+	if err != nil {
+		return err
+	}
+	if err := change(store); err != nil {
+		return err
+	}
+	return secrets.SaveStore(path, passphrase, store)
+}
+
+// storePassphrase returns the passphrase of the encrypted store from the
+// environment, or asks for it at the terminal
+func storePassphrase() (string, error) {
+	if passphrase := os.Getenv(passphraseEnv); passphrase != "" {
+		return passphrase, nil
+	}
+	if !term.IsTerminal(os.Stdin.Fd()) {
+		return "", fmt.Errorf("set %s to unlock %s", passphraseEnv, secrets.StorePath("."))
+	}
+	return readSecret(fmt.Sprintf("Passphrase of %s: ", secrets.StorePath(".")))
+}
+
+// readSecret reads a line from stdin, without echoing it at a terminal
+func readSecret(prompt string) (string, error) {
+	if !term.IsTerminal(os.Stdin.Fd()) {
+		line, err := bufio.NewReader(os.Stdin).ReadString('\n')
+		if err != nil && line == "" {
+			return "", fmt.Errorf("failed to read the secret: %w", err)
+		}
+		return strings.TrimRight(line, "\r\n"), nil
+	}
+	fmt.Fprint(os.Stderr, prompt)
+	value, err := term.ReadPassword(os.Stdin.Fd())
+	fmt.Fprintln(os.Stderr)
+	if err != nil {
+		return "", err
+	}
+	return string(value), nil
+}
+
+func sortedKeys(m map[string]string) []string {
+	keys := make([]string, 0, len(m))
+	for key := range m {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
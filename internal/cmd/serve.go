@@ -0,0 +1,165 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"os/signal"
+	"strings"
+	"syscall"
+
+	"github.com/spf13/cobra"
+	"hermes/internal/circuit"
+	"hermes/internal/config"
+	"hermes/internal/git"
+	"hermes/internal/lock"
+	"hermes/internal/server"
+	"hermes/internal/task"
+	"hermes/internal/ui"
+)
+
+// NewServeCmd creates the serve subcommand
+func NewServeCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "serve",
+		Short: "Start web dashboard and REST API",
+		Long: `Serve a web dashboard and REST API showing progress, the current task,
+circuit breaker state and live logs, with start/stop/pause controls for the run loop.`,
+		Example: `  hermes serve
+  hermes serve --addr 0.0.0.0:8080 --token secret
+  hermes serve --start --auto-commit`,
+		RunE: serveExecute,
+	}
+
+	cmd.Flags().String("addr", "127.0.0.1:8080", "Address to listen on")
+	cmd.Flags().String("token", "", "Require this bearer token for API requests (default: $HERMES_SERVE_TOKEN, or a generated one for run controls)")
+	cmd.Flags().Bool("start", false, "Start the run loop immediately")
+	cmd.Flags().Bool("auto-branch", false, "Create feature branches (overrides config)")
+	cmd.Flags().Bool("auto-commit", false, "Commit on task completion (overrides config)")
+	cmd.Flags().Bool("debug", false, "Enable debug output")
+	cmd.Flags().String("ai", "", "AI provider: claude, droid, gemini, auto (default: from config or auto)")
+
+	return usesSecrets(cmd)
+}
+
+func serveExecute(cmd *cobra.Command, args []string) error {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	cfg, err := config.Load(".")
+	if err != nil {
+		return err
+	}
+
+	opts := sequentialOptions{
+		autoBranch: cfg.TaskMode.AutoBranch,
+		autoCommit: cfg.TaskMode.AutoCommit,
+		autonomous: true, // Runs are controlled from the dashboard, not the terminal
+	}
+	if cmd.Flags().Changed("auto-branch") {
+		opts.autoBranch, _ = cmd.Flags().GetBool("auto-branch")
+	}
+	if cmd.Flags().Changed("auto-commit") {
+		opts.autoCommit, _ = cmd.Flags().GetBool("auto-commit")
+	}
+	debug, _ := cmd.Flags().GetBool("debug")
+	addr, _ := cmd.Flags().GetString("addr")
+	token, _ := cmd.Flags().GetString("token")
+	if token == "" {
+		token = os.Getenv("HERMES_SERVE_TOKEN")
+	}
+
+	logger, err := ui.NewLogger(".", debug)
+	if err != nil {
+		return err
+	}
+	defer logger.Close()
+
+	aiFlag, _ := cmd.Flags().GetString("ai")
+	provider, err := selectCodingProvider(aiFlag, cfg)
+	if err != nil {
+		return err
+	}
+
+	controller := server.NewController(func(runCtx context.Context, gate *server.Gate) error {
+		l, err := lock.Acquire(".", "serve")
+		if err != nil {
+			return err
+		}
+		defer l.Release()
+
+		reader := task.NewReader(".")
+		reader.SetImplicitDocDependencies(cfg.Parallel.ImplicitDocDependencies)
+		breaker := circuit.New(".")
+		if err := breaker.Initialize(); err != nil {
+			return err
+		}
+		breaker.SetStateChangeCallback(func(fromState, toState circuit.State, reason string) {
+			logger.Info("Circuit breaker: %s -> %s (%s)", fromState, toState, reason)
+		})
+		breaker.SetCooldown(cfg.BreakerCooldown())
+		gitOps := git.New(".")
+		gitOps.SetStageExcludes(cfg.GetStageExcludes()...)
+		gitOps.SetCommitPolicy(git.NewCommitPolicy(cfg.Git))
+		gitOps.SetSigning(git.NewSigning(cfg.Git))
+		if err := gitOps.SetCommitFormat(git.NewCommitFormat(cfg.Git)); err != nil {
+			return err
+		}
+		if cfg.Git.TrackTasks {
+			enableTaskTracking(gitOps, cfg, logger)
+		}
+		if !reader.HasTasks() {
+			return fmt.Errorf("no tasks found, run 'hermes prd <file>' first")
+		}
+
+		runOpts := opts
+		runOpts.waitIfPaused = gate.Wait
+		logger.Info("Run started from dashboard using %s", provider.Name())
+		return runSequential(runCtx, cfg, provider, reader, breaker, gitOps, logger, runOpts)
+	})
+
+	srv := server.New(".", controller)
+	srv.SetToken(token)
+
+	sigChan := make(chan os.Signal, 1)
+	signal.Notify(sigChan, os.Interrupt, syscall.SIGTERM)
+	go func() {
+		<-sigChan
+		fmt.Println("\nReceived interrupt, shutting down...")
+		if controller.Status().State != server.RunIdle {
+			controller.Stop()
+		}
+		cancel()
+	}()
+
+	ui.PrintBanner(GetVersion())
+	ui.PrintHeader("Hermes Server")
+
+	if token == "" && !isLoopbackAddr(addr) {
+		logger.Warn("Serving on %s without a token, anyone who can reach it can read progress and logs", addr)
+	}
+
+	if start, _ := cmd.Flags().GetBool("start"); start {
+		if err := controller.Start(); err != nil {
+			return err
+		}
+	}
+
+	if token == "" {
+		// The run controls need the generated token, which the dashboard reads from its URL
+		logger.Info("Dashboard available at http://%s/?token=%s", addr, srv.ControlToken())
+	} else {
+		logger.Info("Dashboard available at http://%s/", addr)
+	}
+	return srv.ListenAndServe(ctx, addr)
+}
+
+// isLoopbackAddr reports whether addr only listens on the local machine
+func isLoopbackAddr(addr string) bool {
+	for _, prefix := range []string{"127.0.0.1:", "localhost:", "[::1]:"} {
+		if strings.HasPrefix(addr, prefix) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,182 @@
+package cmd
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/spf13/cobra"
+	"hermes/internal/git"
+	"hermes/internal/scheduler"
+	"hermes/internal/task"
+)
+
+// NewSnapshotCmd creates the snapshot command
+func NewSnapshotCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "snapshot",
+		Short: "Manage the snapshots taken before tasks",
+		Long: `Manage the repository snapshots in .hermes/snapshots.json. A snapshot records
+the commit and branch before a task started, or before its branch was merged in
+parallel runs, and is kept after the run so it can be inspected and restored
+later. Snapshots are referenced by ID, task ID or commit hash prefix.`,
+	}
+	cmd.AddCommand(newSnapshotListCmd())
+	cmd.AddCommand(newSnapshotShowCmd())
+	cmd.AddCommand(newSnapshotRestoreCmd())
+	cmd.AddCommand(newSnapshotDeleteCmd())
+	return cmd
+}
+
+// newSnapshotListCmd creates the snapshot list subcommand
+func newSnapshotListCmd() *cobra.Command {
+	return &cobra.Command{
+		Use:   "list",
+		Short: "List saved snapshots",
+		RunE: func(cmd *cobra.Command, args []string) error {
+			gitOps, err := snapshotGit()
+			if err != nil {
+				return err
+			}
+			return listSnapshots(gitOps)
+		},
+	}
+}
+
+// newSnapshotShowCmd creates the snapshot show subcommand
+func newSnapshotShowCmd() *cobra.Command {
+	return &cobra.Command{
+		Use:   "show <ref>",
+		Short: "Show a snapshot and the changes made since",
+		Example: `  hermes snapshot show 12
+  hermes snapshot show T005`,
+		Args: cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return snapshotShowExecute(args[0])
+		},
+	}
+}
+
+// newSnapshotRestoreCmd creates the snapshot restore subcommand
+func newSnapshotRestoreCmd() *cobra.Command {
+	var yes bool
+
+	cmd := &cobra.Command{
+		Use:   "restore <ref>",
+		Short: "Reset the current branch to a snapshot",
+		Long: `Reset the current branch to a snapshot, discarding later commits and
+uncommitted changes. Tasks whose commits are discarded are set back to
+NOT_STARTED. This is the same as 'hermes rollback --to'.`,
+		Example: `  hermes snapshot restore 12
+  hermes snapshot restore T005 --yes`,
+		Args: cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			gitOps, err := snapshotGit()
+			if err != nil {
+				return err
+			}
+			return rollbackToSnapshot(gitOps, args[0], yes)
+		},
+	}
+
+	cmd.Flags().BoolVarP(&yes, "yes", "y", false, "Do not ask for confirmation")
+
+	return cmd
+}
+
+// newSnapshotDeleteCmd creates the snapshot delete subcommand
+func newSnapshotDeleteCmd() *cobra.Command {
+	var all bool
+
+	cmd := &cobra.Command{
+		Use:   "delete <ref>...",
+		Short: "Delete saved snapshots",
+		Long: `Delete snapshots from the saved history. Only the records are removed, the
+commits they point to stay in the repository.`,
+		Example: `  hermes snapshot delete 3 4
+  hermes snapshot delete --all`,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if all == (len(args) > 0) {
+				return fmt.Errorf("specify snapshots to delete or --all")
+			}
+			return snapshotDeleteExecute(args, all)
+		},
+	}
+
+	cmd.Flags().BoolVar(&all, "all", false, "Delete all snapshots")
+
+	return cmd
+}
+
+// snapshotGit returns the git operations of the project, which snapshots need
+func snapshotGit() (*git.Git, error) {
+	gitOps := git.New(".")
+	if !gitOps.IsRepository() {
+		return nil, fmt.Errorf("not a git repository")
+	}
+	return gitOps, nil
+}
+
+func snapshotShowExecute(ref string) error {
+	gitOps, err := snapshotGit()
+	if err != nil {
+		return err
+	}
+	snapshot, err := scheduler.FindSnapshot(".", ref)
+	if err != nil {
+		return err
+	}
+
+	fmt.Printf("Snapshot %d\n", snapshot.ID)
+	fmt.Println(strings.Repeat("-", 50))
+	before := snapshot.Name
+	if t, err := task.NewReader(".").GetTaskByID(snapshot.Name); err == nil && t != nil {
+		before += " " + t.Name
+	}
+	fmt.Printf("Before:   %s\n", before)
+	fmt.Printf("Commit:   %s\n", snapshot.Commit)
+	fmt.Printf("Branch:   %s\n", snapshot.Branch)
+	fmt.Printf("Time:     %s\n", snapshot.Time.Format("2006-01-02 15:04:05"))
+
+	commits, err := gitOps.GetCommitsSince(snapshot.Commit)
+	if err != nil {
+		fmt.Printf("\nCommit %s is not in the current history.\n", shortHash(snapshot.Commit))
+		return nil
+	}
+	if stat, err := gitOps.GetRangeDiffStat(snapshot.Commit, "HEAD"); err == nil {
+		fmt.Printf("Changes:  %s since the snapshot\n", stat)
+	}
+	if len(commits) > 0 {
+		fmt.Printf("\nCommits since the snapshot:\n")
+		for _, c := range commits {
+			fmt.Printf("  %s\n", c)
+		}
+	}
+	return nil
+}
+
+func snapshotDeleteExecute(refs []string, all bool) error {
+	var ids []int
+	if all {
+		snapshots, err := scheduler.LoadSnapshots(".")
+		if err != nil {
+			return err
+		}
+		for _, s := range snapshots {
+			ids = append(ids, s.ID)
+		}
+	}
+	for _, ref := range refs {
+		snapshot, err := scheduler.FindSnapshot(".", ref)
+		if err != nil {
+			return err
+		}
+		ids = append(ids, snapshot.ID)
+	}
+
+	removed, err := scheduler.DeleteSnapshots(".", ids...)
+	if err != nil {
+		return err
+	}
+	fmt.Printf("Deleted %d snapshots\n", removed)
+	return nil
+}
@@ -0,0 +1,98 @@
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/fatih/color"
+	"github.com/spf13/cobra"
+	"hermes/internal/report"
+)
+
+type statsOptions struct {
+	runs    int
+	jsonOut bool
+}
+
+// NewStatsCmd creates the stats subcommand
+func NewStatsCmd() *cobra.Command {
+	opts := &statsOptions{}
+
+	cmd := &cobra.Command{
+		Use:   "stats",
+		Short: "Show historical run metrics",
+		Long: `Summarize the recorded runs in .hermes/runs: task throughput, success rate,
+retries, and the average time completed tasks took per effort estimate. The
+accuracy column is that time as a multiple of the estimate, with 8-hour days,
+which helps calibrate estimates in future PRDs.`,
+		Example: `  hermes stats
+  hermes stats --runs 10
+  hermes stats --json`,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return statsExecute(opts)
+		},
+	}
+
+	cmd.Flags().IntVar(&opts.runs, "runs", 0, "Only include the last N runs (0 = all)")
+	cmd.Flags().BoolVar(&opts.jsonOut, "json", false, "Print statistics as JSON")
+
+	return cmd
+}
+
+func statsExecute(opts *statsOptions) error {
+	runs, err := report.LoadRuns(".")
+	if err != nil {
+		return err
+	}
+	if len(runs) == 0 {
+		fmt.Println("No recorded runs found, run 'hermes run' first.")
+		return nil
+	}
+	if opts.runs > 0 && len(runs) > opts.runs {
+		runs = runs[len(runs)-opts.runs:]
+	}
+
+	stats := report.ComputeStats(runs)
+	if opts.jsonOut {
+		data, err := json.MarshalIndent(stats, "", "  ")
+		if err != nil {
+			return err
+		}
+		fmt.Println(string(data))
+		return nil
+	}
+
+	bold := color.New(color.Bold)
+
+	fmt.Println()
+	bold.Printf("Runs: %d since %s\n", len(stats.Runs), stats.Runs[0].StartTime.Format("2006-01-02"))
+	fmt.Printf("Attempts: %d  Completed: %d  Success rate: %.0f%%  Retries: %d\n",
+		stats.Attempts, stats.Completed, stats.SuccessRate, stats.Retries)
+	fmt.Printf("Attempts per task: %.1f  Agent time: %s  Cost: $%.2f\n",
+		stats.AvgAttempts, stats.TotalDuration.Round(time.Second), stats.TotalCost)
+
+	fmt.Println()
+	bold.Println("Runs")
+	fmt.Printf("%-22s %-10s %8s %6s %8s %8s %8s %10s\n", "RUN", "PROVIDER", "ATTEMPTS", "DONE", "RETRIES", "SUCCESS", "TASKS/H", "AVG TIME")
+	for _, rs := range stats.Runs {
+		fmt.Printf("%-22s %-10s %8d %6d %8d %7.0f%% %8.1f %10s\n",
+			rs.ID, rs.Provider, rs.Attempts, rs.Completed, rs.Retries, rs.SuccessRate, rs.Throughput, rs.AvgDuration.Round(time.Second))
+	}
+
+	if len(stats.Efforts) > 0 {
+		fmt.Println()
+		bold.Println("Completed Tasks by Estimate")
+		fmt.Printf("%-12s %6s %10s %9s %9s\n", "ESTIMATE", "TASKS", "AVG TIME", "ATTEMPTS", "ACCURACY")
+		for _, e := range stats.Efforts {
+			accuracy := "-"
+			if e.Accuracy > 0 {
+				accuracy = fmt.Sprintf("%.2fx", e.Accuracy)
+			}
+			fmt.Printf("%-12s %6d %10s %9.1f %9s\n", e.Effort, e.Tasks, e.AvgDuration.Round(time.Second), e.AvgAttempts, accuracy)
+		}
+	}
+	fmt.Println()
+
+	return nil
+}
@@ -2,9 +2,15 @@ package cmd
 
 import (
 	"fmt"
+	"time"
 
 	"github.com/spf13/cobra"
+	"hermes/internal/checkpoint"
 	"hermes/internal/circuit"
+	"hermes/internal/config"
+	"hermes/internal/lock"
+	"hermes/internal/recovery"
+	"hermes/internal/report"
 	"hermes/internal/task"
 	"hermes/internal/ui"
 )
@@ -12,8 +18,12 @@ import (
 type statusOptions struct {
 	filter   string
 	priority string
+	circuit  bool
 }
 
+// circuitHistoryLimit is the number of breaker transitions 'hermes status --circuit' shows
+const circuitHistoryLimit = 20
+
 // NewStatusCmd creates the status subcommand
 func NewStatusCmd() *cobra.Command {
 	opts := &statusOptions{}
@@ -24,7 +34,8 @@ func NewStatusCmd() *cobra.Command {
 		Long:  "Display task progress table and statistics",
 		Example: `  hermes status
   hermes status --filter IN_PROGRESS
-  hermes status --priority P1`,
+  hermes status --priority P1
+  hermes status --circuit`,
 		RunE: func(cmd *cobra.Command, args []string) error {
 			return statusExecute(opts)
 		},
@@ -32,11 +43,16 @@ func NewStatusCmd() *cobra.Command {
 
 	cmd.Flags().StringVar(&opts.filter, "filter", "", "Filter by status (NOT_STARTED, IN_PROGRESS, COMPLETED, BLOCKED)")
 	cmd.Flags().StringVar(&opts.priority, "priority", "", "Filter by priority (P1, P2, P3, P4)")
+	cmd.Flags().BoolVar(&opts.circuit, "circuit", false, "Show the circuit breaker state and its recent transitions")
 
 	return cmd
 }
 
 func statusExecute(opts *statusOptions) error {
+	if opts.circuit {
+		return circuitStatusExecute()
+	}
+
 	reader := task.NewReader(".")
 
 	if !reader.HasTasks() {
@@ -49,6 +65,8 @@ func statusExecute(opts *statusOptions) error {
 		return err
 	}
 
+	all := tasks
+
 	// Apply filters
 	if opts.filter != "" {
 		tasks = ui.FilterTasksByStatus(tasks, task.Status(opts.filter))
@@ -59,6 +77,7 @@ func statusExecute(opts *statusOptions) error {
 
 	// Display table
 	ui.PrintTaskTable(tasks)
+	ui.PrintBlockedReasons(tasks)
 
 	// Show progress
 	progress, err := reader.GetProgress()
@@ -67,13 +86,87 @@ func statusExecute(opts *statusOptions) error {
 	}
 	ui.PrintProgress(progress)
 
+	holder, lockErr := lock.Read(".")
+	running := lockErr == nil && !holder.Stale()
+	var runStart time.Time
+	if running {
+		runStart = holder.StartTime
+	}
+	if eta, ok := estimateRemaining(all, runStart); ok {
+		fmt.Printf("ETA: %s\n", report.FormatETA(eta))
+	}
+
 	// Show circuit breaker status
 	breaker := circuit.New(".")
 	state, _ := breaker.GetState()
 	if state != nil && state.State != circuit.StateClosed {
 		fmt.Println()
 		breaker.PrintStatus()
+		if d := recovery.ForBreaker(".", state); d != nil {
+			d.Print()
+		}
+	}
+
+	// A live runner holds the project lock; otherwise a checkpoint means the
+	// run was interrupted and points to 'hermes resume'
+	if running {
+		fmt.Printf("\nRunning: hermes %s (PID %d on %s) for %s\n", holder.Command, holder.PID, holder.Host,
+			time.Since(holder.StartTime).Round(time.Second))
+	} else if cp, err := checkpoint.Load("."); err == nil {
+		fmt.Printf("\n%s\nRun 'hermes resume' to continue it or 'hermes resume --discard' to drop it.\n", describeCheckpoint(cp))
 	}
 
 	return nil
 }
+
+// estimateRemaining estimates the time needed to finish the pending tasks
+// from recorded runs and, when a run started at runStart is in progress, from
+// the tasks it has completed so far
+func estimateRemaining(tasks []task.Task, runStart time.Time) (time.Duration, bool) {
+	var pending []task.Task
+	completed := 0
+	for _, t := range tasks {
+		switch {
+		case t.Status == task.StatusCompleted:
+			if !runStart.IsZero() && t.UpdatedAt.After(runStart) {
+				completed++
+			}
+		case t.Status != task.StatusBlocked:
+			pending = append(pending, t)
+		}
+	}
+	if len(pending) == 0 {
+		return 0, false
+	}
+
+	workers := 1
+	if cfg, err := config.Load("."); err == nil && cfg.Parallel.Enabled {
+		workers = cfg.Parallel.MaxWorkers
+	}
+	var stats *report.Stats
+	if runs, err := report.LoadRuns("."); err == nil && len(runs) > 0 {
+		stats = report.ComputeStats(runs)
+	}
+	var elapsed time.Duration
+	if !runStart.IsZero() {
+		elapsed = time.Since(runStart)
+	}
+	return report.NewEstimate(stats, pending, workers).Remaining(completed, elapsed)
+}
+
+// circuitStatusExecute shows the circuit breaker state, its diagnosis when
+// it is not closed, and its recent transitions
+func circuitStatusExecute() error {
+	breaker := circuit.New(".")
+	if err := breaker.PrintStatus(); err != nil {
+		return err
+	}
+	state, _ := breaker.GetState()
+	if state != nil && state.State != circuit.StateClosed {
+		if d := recovery.ForBreaker(".", state); d != nil {
+			d.Print()
+		}
+	}
+	fmt.Println()
+	return breaker.PrintHistory(circuitHistoryLimit)
+}
@@ -17,16 +17,19 @@ func NewTaskCmd() *cobra.Command {
 		Long:  "Display detailed information about a specific task",
 		Args:  cobra.ExactArgs(1),
 		RunE:  runTask,
+		// Task IDs are completed from the current project's task files
+		ValidArgsFunction: completeTaskIDs,
 	}
+	cmd.AddCommand(newTaskEditCmd())
+	cmd.AddCommand(newTaskAddCmd())
+	cmd.AddCommand(newTaskSplitCmd())
+	cmd.AddCommand(newTaskListCmd())
+	cmd.AddCommand(newTaskNewCmd())
 	return cmd
 }
 
 func runTask(cmd *cobra.Command, args []string) error {
-	taskID := strings.ToUpper(args[0])
-	if !strings.HasPrefix(taskID, "T") {
-		// Pad with zeros if numeric (1 -> T001, 12 -> T012)
-		taskID = fmt.Sprintf("T%03s", taskID)
-	}
+	taskID := normalizeTaskID(args[0])
 
 	reader := task.NewReader(".")
 	tasks, err := reader.GetAllTasks()
@@ -68,6 +71,9 @@ func runTask(cmd *cobra.Command, args []string) error {
 	default:
 		fmt.Printf("%s\n", found.Status)
 	}
+	if found.BlockedReason != "" {
+		fmt.Printf("Blocked:  %s\n", found.BlockedReason)
+	}
 	
 	// Priority with color
 	fmt.Print("Priority: ")
@@ -81,6 +87,9 @@ func runTask(cmd *cobra.Command, args []string) error {
 	}
 	
 	fmt.Printf("Feature:  %s\n", found.FeatureID)
+	if found.PRDSection != "" {
+		fmt.Printf("PRD:      %s\n", found.PRDSection)
+	}
 	
 	// Files
 	if len(found.FilesToTouch) > 0 {
@@ -108,6 +117,16 @@ func runTask(cmd *cobra.Command, args []string) error {
 			fmt.Printf("  - %s\n", c)
 		}
 	}
+
+	// Subtasks
+	if len(found.Subtasks) > 0 {
+		done, total := found.SubtaskProgress()
+		fmt.Println()
+		cyan.Printf("Subtasks (%d/%d):\n", done, total)
+		for _, s := range found.Subtasks {
+			fmt.Printf("  - %s: %s [%s]\n", s.ID, s.Name, s.Status)
+		}
+	}
 	
 	fmt.Println()
 	return nil
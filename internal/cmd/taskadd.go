@@ -0,0 +1,260 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/spf13/cobra"
+	"hermes/internal/ai"
+	"hermes/internal/analyzer"
+	"hermes/internal/config"
+	"hermes/internal/prd"
+	"hermes/internal/task"
+)
+
+type taskAddOptions struct {
+	priority    string
+	effort      string
+	description string
+	deps        []string
+	files       []string
+	criteria    []string
+	useAI       bool
+	dryRun      bool
+	template    string
+}
+
+// newTaskAddCmd creates the task add subcommand
+func newTaskAddCmd() *cobra.Command {
+	opts := &taskAddOptions{}
+
+	cmd := &cobra.Command{
+		Use:   "add <feature-id> <task-name>",
+		Short: "Add a task to an existing feature",
+		Long: `Append a new task to an existing feature file using the next free task ID.
+With --ai, the description, files and success criteria are generated by the
+planning AI provider.`,
+		Example: `  hermes task add F002 "Implement rate limiter"
+  hermes task add F002 "Implement rate limiter" --ai
+  hermes task add 2 "Add metrics" --priority P1 --dep T010 --criteria "Exposes /metrics"`,
+		Args:              cobra.ExactArgs(2),
+		ValidArgsFunction: completeFeatureIDs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return taskAddExecute(normalizeFeatureID(args[0]), args[1], opts)
+		},
+	}
+
+	cmd.Flags().StringVar(&opts.priority, "priority", "P2", "Task priority: P1, P2, P3, P4")
+	cmd.Flags().StringVar(&opts.effort, "effort", "", "Estimated effort (e.g. \"1 day\")")
+	cmd.Flags().StringVar(&opts.description, "description", "", "Task description")
+	cmd.Flags().StringSliceVar(&opts.deps, "dep", nil, "Dependency task ID (repeatable)")
+	cmd.Flags().StringSliceVar(&opts.files, "file", nil, "File to touch (repeatable)")
+	cmd.RegisterFlagCompletionFunc("dep", completeTaskIDFlag)
+	cmd.Flags().StringArrayVar(&opts.criteria, "criteria", nil, "Success criterion (repeatable)")
+	cmd.Flags().BoolVar(&opts.useAI, "ai", false, "Generate description, files and criteria with AI")
+	cmd.Flags().BoolVar(&opts.dryRun, "dry-run", false, "Print the task without writing it")
+
+	return usesSecrets(cmd)
+}
+
+// normalizeFeatureID pads numeric IDs with zeros (2 -> F002)
+func normalizeFeatureID(id string) string {
+	id = strings.ToUpper(id)
+	if !strings.HasPrefix(id, "F") {
+		id = fmt.Sprintf("F%03s", id)
+	}
+	return id
+}
+
+func taskAddExecute(featureID, name string, opts *taskAddOptions) error {
+	reader := task.NewReader(".")
+	feature, err := reader.GetFeatureByID(featureID)
+	if err != nil {
+		return fmt.Errorf("failed to read tasks: %w", err)
+	}
+	if feature == nil {
+		return fmt.Errorf("feature %s not found", featureID)
+	}
+
+	_, nextTaskID, err := analyzer.NewFeatureAnalyzer(".").GetNextIDs()
+	if err != nil {
+		return err
+	}
+
+	t := &task.Task{
+		ID:              fmt.Sprintf("T%03d", nextTaskID),
+		Name:            name,
+		FeatureID:       feature.ID,
+		Status:          task.StatusNotStarted,
+		Priority:        task.Priority(strings.ToUpper(opts.priority)),
+		EstimatedEffort: opts.effort,
+		Description:     opts.description,
+		FilesToTouch:    opts.files,
+		SuccessCriteria: opts.criteria,
+	}
+
+	// Flags win over the template
+	if opts.template != "" {
+		tmpl, err := task.LoadTemplate(".", opts.template)
+		if err != nil {
+			return err
+		}
+		vars := map[string]string{"name": name, "slug": prd.Slug(name), "feature": feature.Name}
+		if err := tmpl.Apply(t, vars); err != nil {
+			return err
+		}
+	}
+
+	switch t.Priority {
+	case task.PriorityP1, task.PriorityP2, task.PriorityP3, task.PriorityP4:
+	default:
+		return fmt.Errorf("invalid priority %q", t.Priority)
+	}
+
+	allTasks, err := reader.GetAllTasks()
+	if err != nil {
+		return err
+	}
+	for _, dep := range opts.deps {
+		dep = normalizeTaskID(strings.TrimSpace(dep))
+		if findTask(allTasks, dep) == nil {
+			return fmt.Errorf("dependency %s not found", dep)
+		}
+		t.Dependencies = append(t.Dependencies, dep)
+	}
+
+	if opts.useAI {
+		if err := generateTaskDetails(feature, t); err != nil {
+			return err
+		}
+	}
+
+	if opts.dryRun {
+		fmt.Println(task.FormatTask(t))
+		return nil
+	}
+
+	if err := task.AppendTask(feature.FilePath, t); err != nil {
+		return err
+	}
+	fmt.Printf("Added %s: %s to %s (%s)\n", t.ID, t.Name, feature.ID, feature.FilePath)
+
+	// A new task reopens a completed feature
+	if feature.Status == task.StatusCompleted {
+		if err := task.NewStatusUpdater(".").UpdateFeatureStatus(feature.ID, task.StatusInProgress); err != nil {
+			fmt.Printf("Warning: failed to reopen feature %s: %v\n", feature.ID, err)
+		}
+	}
+	return nil
+}
+
+// generateTaskDetails asks the planning provider to fill in the parts of t the
+// user did not provide
+func generateTaskDetails(feature *task.Feature, t *task.Task) error {
+	cfg, err := config.Load(".")
+	if err != nil {
+		return err
+	}
+
+	var provider ai.Provider
+	if cfg.AI.Planning != "" && cfg.AI.Planning != "auto" {
+		provider = ai.GetProvider(cfg.AI.Planning)
+	}
+	if provider == nil || !provider.IsAvailable() {
+		provider = ai.AutoDetectProvider()
+	}
+	if provider == nil {
+		return fmt.Errorf("no AI provider available")
+	}
+	fmt.Printf("Generating task details with %s...\n", provider.Name())
+
+	result, err := ai.ExecuteWithRetry(context.Background(), provider, &ai.ExecuteOptions{
+		Prompt:  buildTaskAddPrompt(feature, t),
+		Timeout: cfg.AI.Timeout,
+	}, &ai.RetryConfig{
+		MaxRetries: cfg.AI.MaxRetries,
+		Delay:      time.Duration(cfg.AI.RetryDelay) * time.Second,
+	})
+	if err != nil {
+		return fmt.Errorf("failed to generate task details: %w", err)
+	}
+
+	generated, err := task.ParseTaskBlock(result.Output, feature.ID)
+	if err != nil {
+		return fmt.Errorf("AI output did not contain a task block: %w", err)
+	}
+
+	// Flags win over generated values; ID, name and status are never taken from AI
+	if t.Description == "" {
+		t.Description = generated.Description
+	}
+	if t.EstimatedEffort == "" {
+		t.EstimatedEffort = generated.EstimatedEffort
+	}
+	if len(t.FilesToTouch) == 0 {
+		t.FilesToTouch = generated.FilesToTouch
+	}
+	if len(t.SuccessCriteria) == 0 {
+		t.SuccessCriteria = generated.SuccessCriteria
+	}
+	t.TechnicalDetails = generated.TechnicalDetails
+	return nil
+}
+
+func buildTaskAddPrompt(feature *task.Feature, t *task.Task) string {
+	var existing strings.Builder
+	for _, ft := range feature.Tasks {
+		fmt.Fprintf(&existing, "- %s: %s (%s)\n", ft.ID, ft.Name, ft.Status)
+	}
+
+	return fmt.Sprintf(`Write a single task for an existing feature.
+
+Feature %s: %s
+
+%s
+
+Existing tasks:
+%s
+New task: %s: %s
+
+Output ONLY the task block in this EXACT format:
+
+### %s: %s
+
+**Status:** NOT_STARTED
+**Priority:** %s
+**Estimated Effort:** X days
+
+#### Description
+
+[Clear description of what this task accomplishes]
+
+#### Technical Details
+
+[Implementation notes, patterns to follow, architectural decisions]
+
+#### Files to Touch
+
+- `+"`path/to/file.go`"+` (new)
+
+#### Dependencies
+
+- None
+
+#### Success Criteria
+
+- [ ] [Specific deliverable 1]
+- [ ] [Specific deliverable 2]
+
+RULES:
+1. The task must be atomic, testable and 0.5-2 days of work
+2. Do not repeat work covered by the existing tasks
+3. Analyze the project structure to suggest correct file paths
+4. Do NOT create or modify any files
+
+Output only the markdown content, no additional explanation.`,
+		feature.ID, feature.Name, strings.TrimSpace(feature.Overview), existing.String(),
+		t.ID, t.Name, t.ID, t.Name, t.Priority)
+}
@@ -0,0 +1,194 @@
+package cmd
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/spf13/cobra"
+	"hermes/internal/report"
+	"hermes/internal/scheduler"
+	"hermes/internal/task"
+)
+
+type taskEditOptions struct {
+	status     string
+	priority   string
+	addDeps    []string
+	removeDeps []string
+}
+
+// newTaskEditCmd creates the task edit subcommand
+func newTaskEditCmd() *cobra.Command {
+	opts := &taskEditOptions{}
+
+	cmd := &cobra.Command{
+		Use:   "edit <id>",
+		Short: "Edit task metadata",
+		Long:  "Change a task's status, priority or dependencies without hand-editing its feature file",
+		Example: `  hermes task edit T012 --status NOT_STARTED
+  hermes task edit T012 --priority P1 --add-dep T010
+  hermes task edit 12 --remove-dep T008,T009`,
+		Args:              cobra.ExactArgs(1),
+		ValidArgsFunction: completeTaskIDs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return taskEditExecute(normalizeTaskID(args[0]), opts)
+		},
+	}
+
+	cmd.Flags().StringVar(&opts.status, "status", "", "New status: NOT_STARTED, IN_PROGRESS, COMPLETED, BLOCKED, AT_RISK, PAUSED")
+	cmd.Flags().StringVar(&opts.priority, "priority", "", "New priority: P1, P2, P3, P4")
+	cmd.Flags().StringSliceVar(&opts.addDeps, "add-dep", nil, "Add dependency on task ID (repeatable)")
+	cmd.Flags().StringSliceVar(&opts.removeDeps, "remove-dep", nil, "Remove dependency on task ID (repeatable)")
+	cmd.RegisterFlagCompletionFunc("add-dep", completeTaskIDFlag)
+	cmd.RegisterFlagCompletionFunc("remove-dep", completeTaskIDFlag)
+
+	return cmd
+}
+
+func taskEditExecute(taskID string, opts *taskEditOptions) error {
+	if opts.status == "" && opts.priority == "" && len(opts.addDeps) == 0 && len(opts.removeDeps) == 0 {
+		return fmt.Errorf("nothing to edit, use --status, --priority, --add-dep or --remove-dep")
+	}
+
+	var status task.Status
+	if opts.status != "" {
+		status = task.Status(strings.ToUpper(opts.status))
+		if !isValidStatus(status) {
+			return fmt.Errorf("invalid status %q", opts.status)
+		}
+	}
+	var priority task.Priority
+	if opts.priority != "" {
+		priority = task.Priority(strings.ToUpper(opts.priority))
+		switch priority {
+		case task.PriorityP1, task.PriorityP2, task.PriorityP3, task.PriorityP4:
+		default:
+			return fmt.Errorf("invalid priority %q", opts.priority)
+		}
+	}
+
+	reader := task.NewReader(".")
+	tasks, err := reader.GetAllTasks()
+	if err != nil {
+		return fmt.Errorf("failed to read tasks: %w", err)
+	}
+	t := findTask(tasks, taskID)
+	if t == nil {
+		return fmt.Errorf("task %s not found", taskID)
+	}
+
+	updater := task.NewStatusUpdater(".")
+
+	if len(opts.addDeps) > 0 || len(opts.removeDeps) > 0 {
+		deps, err := editDependencies(tasks, t, opts.addDeps, opts.removeDeps)
+		if err != nil {
+			return err
+		}
+		if err := updater.UpdateTaskDependencies(taskID, deps); err != nil {
+			return err
+		}
+		fmt.Printf("%s dependencies: %s\n", taskID, formatDeps(deps))
+	}
+
+	if priority != "" {
+		if err := updater.UpdateTaskPriority(taskID, priority); err != nil {
+			return err
+		}
+		fmt.Printf("%s priority: %s -> %s\n", taskID, t.Priority, priority)
+	}
+
+	if status != "" {
+		if err := updater.UpdateTaskStatus(taskID, status); err != nil {
+			return err
+		}
+		fmt.Printf("%s status: %s -> %s\n", taskID, t.Status, status)
+		// A task put back in line starts over with its retries
+		if status == task.StatusNotStarted || status == task.StatusInProgress {
+			if err := report.ResetTaskHistory(".", taskID); err != nil {
+				return err
+			}
+		}
+		if status != task.StatusBlocked && t.BlockedReason != "" {
+			if err := updater.SetBlockedReason(taskID, ""); err != nil {
+				return err
+			}
+		}
+	}
+
+	return nil
+}
+
+// editDependencies applies additions and removals to a task's dependencies,
+// rejecting unknown tasks and changes that would create a cycle
+func editDependencies(tasks []task.Task, t *task.Task, add, remove []string) ([]string, error) {
+	removed := make(map[string]bool)
+	for _, id := range remove {
+		removed[normalizeTaskID(strings.TrimSpace(id))] = true
+	}
+
+	var deps []string
+	seen := make(map[string]bool)
+	for _, id := range t.Dependencies {
+		if !removed[id] && !seen[id] {
+			deps = append(deps, id)
+			seen[id] = true
+		}
+	}
+	for _, id := range add {
+		id = normalizeTaskID(strings.TrimSpace(id))
+		if id == t.ID {
+			return nil, fmt.Errorf("task %s cannot depend on itself", t.ID)
+		}
+		if findTask(tasks, id) == nil {
+			return nil, fmt.Errorf("dependency %s not found", id)
+		}
+		if !seen[id] {
+			deps = append(deps, id)
+			seen[id] = true
+		}
+	}
+
+	if len(add) > 0 {
+		ptrs := make([]*task.Task, len(tasks))
+		for i := range tasks {
+			ptrs[i] = &tasks[i]
+			if tasks[i].ID == t.ID {
+				// Check the graph with the edited dependencies
+				edited := tasks[i]
+				edited.DependsOn = nil
+				edited.Dependencies = deps
+				ptrs[i] = &edited
+			}
+		}
+		if _, err := scheduler.NewTaskGraph(ptrs); err != nil && strings.Contains(err.Error(), "circular") {
+			return nil, fmt.Errorf("adding dependencies to %s would create a cycle", t.ID)
+		}
+	}
+
+	return deps, nil
+}
+
+func findTask(tasks []task.Task, id string) *task.Task {
+	for i := range tasks {
+		if tasks[i].ID == id {
+			return &tasks[i]
+		}
+	}
+	return nil
+}
+
+func isValidStatus(status task.Status) bool {
+	switch status {
+	case task.StatusNotStarted, task.StatusInProgress, task.StatusCompleted,
+		task.StatusBlocked, task.StatusAtRisk, task.StatusPaused:
+		return true
+	}
+	return false
+}
+
+func formatDeps(deps []string) string {
+	if len(deps) == 0 {
+		return "None"
+	}
+	return strings.Join(deps, ", ")
+}
@@ -0,0 +1,57 @@
+package cmd
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/spf13/cobra"
+	"hermes/internal/task"
+	"hermes/internal/ui"
+)
+
+type taskListOptions struct {
+	status  string
+	feature string
+}
+
+// newTaskListCmd creates the task list subcommand
+func newTaskListCmd() *cobra.Command {
+	opts := &taskListOptions{}
+
+	cmd := &cobra.Command{
+		Use:   "list",
+		Short: "List tasks",
+		Long: `List tasks, optionally filtered by status or feature. Blocked tasks are
+followed by the reason they are blocked, as reported by the AI.`,
+		Example: `  hermes task list
+  hermes task list --status BLOCKED
+  hermes task list --feature F002`,
+		Args: cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return taskListExecute(opts)
+		},
+	}
+
+	cmd.Flags().StringVar(&opts.status, "status", "", "Only list tasks with this status")
+	cmd.Flags().StringVar(&opts.feature, "feature", "", "Only list tasks of this feature")
+
+	return cmd
+}
+
+func taskListExecute(opts *taskListOptions) error {
+	tasks, err := task.NewReader(".").GetAllTasks()
+	if err != nil {
+		return fmt.Errorf("failed to read tasks: %w", err)
+	}
+
+	if opts.status != "" {
+		tasks = ui.FilterTasksByStatus(tasks, task.Status(strings.ToUpper(opts.status)))
+	}
+	if opts.feature != "" {
+		tasks = ui.FilterTasksByFeature(tasks, normalizeFeatureID(opts.feature))
+	}
+
+	ui.PrintTaskTable(tasks)
+	ui.PrintBlockedReasons(tasks)
+	return nil
+}
@@ -0,0 +1,72 @@
+package cmd
+
+import (
+	"fmt"
+
+	"github.com/spf13/cobra"
+	"hermes/internal/task"
+)
+
+// newTaskNewCmd creates the task new subcommand
+func newTaskNewCmd() *cobra.Command {
+	opts := &taskAddOptions{}
+	var list bool
+
+	cmd := &cobra.Command{
+		Use:   "new <feature-id> <task-name>",
+		Short: "Add a task to a feature from a template",
+		Long: `Append a new task to an existing feature, pre-filled from a template with
+the description, files to touch and success criteria of a common kind of work.
+
+Built-in templates: api-endpoint, bug-fix, refactor, migration and docs.
+Templates in .hermes/templates/<name>.md are task blocks without the header,
+where {{name}}, {{slug}} and {{feature}} are replaced by the task name, the
+task name as a slug and the feature name. They replace built-in templates of
+the same name.`,
+		Example: `  hermes task new F002 "List orders" --template api-endpoint
+  hermes task new 2 "Login fails with uppercase email" --template bug-fix --priority P1
+  hermes task new --list`,
+		Args: func(cmd *cobra.Command, args []string) error {
+			if list {
+				return cobra.NoArgs(cmd, args)
+			}
+			return cobra.ExactArgs(2)(cmd, args)
+		},
+		ValidArgsFunction: completeFeatureIDs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if list {
+				return taskNewListTemplates()
+			}
+			if opts.template == "" {
+				return fmt.Errorf("--template is required, see 'hermes task new --list'")
+			}
+			return taskAddExecute(normalizeFeatureID(args[0]), args[1], opts)
+		},
+	}
+
+	cmd.Flags().StringVar(&opts.template, "template", "", "Template to pre-fill the task from")
+	cmd.RegisterFlagCompletionFunc("template", completeTemplateNames)
+	cmd.Flags().BoolVar(&list, "list", false, "List the available templates")
+	cmd.Flags().StringVar(&opts.priority, "priority", "", "Task priority: P1, P2, P3, P4 (default: from template or P2)")
+	cmd.Flags().StringVar(&opts.effort, "effort", "", "Estimated effort (default: from template)")
+	cmd.Flags().StringSliceVar(&opts.deps, "dep", nil, "Dependency task ID (repeatable)")
+	cmd.RegisterFlagCompletionFunc("dep", completeTaskIDFlag)
+	cmd.Flags().BoolVar(&opts.dryRun, "dry-run", false, "Print the task without writing it")
+
+	return cmd
+}
+
+func taskNewListTemplates() error {
+	templates, err := task.ListTemplates(".")
+	if err != nil {
+		return fmt.Errorf("failed to read templates: %w", err)
+	}
+	for _, tmpl := range templates {
+		source := "built-in"
+		if tmpl.Path != "" {
+			source = tmpl.Path
+		}
+		fmt.Printf("%-16s %s\n", tmpl.Name, source)
+	}
+	return nil
+}
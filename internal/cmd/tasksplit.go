@@ -0,0 +1,329 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/spf13/cobra"
+	"hermes/internal/ai"
+	"hermes/internal/analyzer"
+	"hermes/internal/config"
+	"hermes/internal/task"
+)
+
+const (
+	minSplitParts = 2
+	maxSplitParts = 4
+)
+
+type taskSplitOptions struct {
+	parts  int
+	dryRun bool
+}
+
+// newTaskSplitCmd creates the task split subcommand
+func newTaskSplitCmd() *cobra.Command {
+	opts := &taskSplitOptions{}
+
+	cmd := &cobra.Command{
+		Use:   "split <id>",
+		Short: "Split an oversized task into smaller tasks",
+		Long: `Ask the planning AI provider to split a task into 2-4 smaller tasks.
+The original task is replaced in its feature file by the new tasks, which get
+the next free task IDs. The first tasks inherit the original dependencies and
+tasks that depended on the original now depend on the final new tasks.`,
+		Example: `  hermes task split T012
+  hermes task split 12 --parts 3
+  hermes task split T012 --dry-run`,
+		Args:              cobra.ExactArgs(1),
+		ValidArgsFunction: completeTaskIDs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return taskSplitExecute(normalizeTaskID(args[0]), opts)
+		},
+	}
+
+	cmd.Flags().IntVar(&opts.parts, "parts", 0, "Number of tasks to create, 2-4 (0 = let the AI decide)")
+	cmd.Flags().BoolVar(&opts.dryRun, "dry-run", false, "Print the new tasks without writing them")
+
+	return usesSecrets(cmd)
+}
+
+func taskSplitExecute(taskID string, opts *taskSplitOptions) error {
+	if opts.parts != 0 && (opts.parts < minSplitParts || opts.parts > maxSplitParts) {
+		return fmt.Errorf("--parts must be between %d and %d", minSplitParts, maxSplitParts)
+	}
+
+	reader := task.NewReader(".")
+	tasks, err := reader.GetAllTasks()
+	if err != nil {
+		return fmt.Errorf("failed to read tasks: %w", err)
+	}
+	t := findTask(tasks, taskID)
+	if t == nil {
+		return fmt.Errorf("task %s not found", taskID)
+	}
+	if t.Status == task.StatusCompleted || t.Status == task.StatusInProgress {
+		return fmt.Errorf("task %s is %s, only tasks that have not started can be split", t.ID, t.Status)
+	}
+
+	feature, err := reader.GetFeatureByID(t.FeatureID)
+	if err != nil {
+		return err
+	}
+	if feature == nil {
+		return fmt.Errorf("feature %s not found", t.FeatureID)
+	}
+
+	_, nextTaskID, err := analyzer.NewFeatureAnalyzer(".").GetNextIDs()
+	if err != nil {
+		return err
+	}
+
+	cfg, err := config.Load(".")
+	if err != nil {
+		return err
+	}
+
+	var provider ai.Provider
+	if cfg.AI.Planning != "" && cfg.AI.Planning != "auto" {
+		provider = ai.GetProvider(cfg.AI.Planning)
+	}
+	if provider == nil || !provider.IsAvailable() {
+		provider = ai.AutoDetectProvider()
+	}
+	if provider == nil {
+		return fmt.Errorf("no AI provider available")
+	}
+	fmt.Printf("Splitting %s with %s...\n", t.ID, provider.Name())
+
+	result, err := ai.ExecuteWithRetry(context.Background(), provider, &ai.ExecuteOptions{
+		Prompt:  buildTaskSplitPrompt(feature, t, nextTaskID, opts.parts),
+		Timeout: cfg.AI.Timeout,
+	}, &ai.RetryConfig{
+		MaxRetries: cfg.AI.MaxRetries,
+		Delay:      time.Duration(cfg.AI.RetryDelay) * time.Second,
+	})
+	if err != nil {
+		return fmt.Errorf("failed to split task: %w", err)
+	}
+
+	parts, err := buildSplitTasks(t, task.ParseTaskBlocks(result.Output, t.FeatureID), nextTaskID)
+	if err != nil {
+		return err
+	}
+	if opts.parts != 0 && len(parts) != opts.parts {
+		return fmt.Errorf("AI returned %d tasks, expected %d", len(parts), opts.parts)
+	}
+	dependents := rewireDependents(tasks, t.ID, splitSinks(parts))
+
+	if opts.dryRun {
+		for i, p := range parts {
+			if i > 0 {
+				fmt.Println("---")
+				fmt.Println()
+			}
+			fmt.Println(task.FormatTask(p))
+		}
+		for _, d := range dependents {
+			fmt.Printf("%s dependencies: %s\n", d.ID, formatDeps(d.Dependencies))
+		}
+		return nil
+	}
+
+	if err := task.ReplaceTask(feature.FilePath, t.ID, parts); err != nil {
+		return err
+	}
+	ids := make([]string, len(parts))
+	for i, p := range parts {
+		ids[i] = p.ID
+	}
+	fmt.Printf("Split %s into %s (%s)\n", t.ID, strings.Join(ids, ", "), feature.FilePath)
+
+	updater := task.NewStatusUpdater(".")
+	for _, d := range dependents {
+		if err := updater.UpdateTaskDependencies(d.ID, d.Dependencies); err != nil {
+			fmt.Printf("Warning: failed to update dependencies of %s: %v\n", d.ID, err)
+			continue
+		}
+		fmt.Printf("%s dependencies: %s\n", d.ID, formatDeps(d.Dependencies))
+	}
+	return nil
+}
+
+// buildSplitTasks assigns new IDs to the generated tasks and resolves their
+// dependencies. References to other new tasks may only point backwards, which
+// keeps the split acyclic; tasks without such a reference inherit the
+// original task's dependencies.
+func buildSplitTasks(original *task.Task, generated []task.Task, firstID int) ([]*task.Task, error) {
+	if len(generated) < minSplitParts || len(generated) > maxSplitParts {
+		return nil, fmt.Errorf("AI returned %d tasks, expected %d-%d", len(generated), minSplitParts, maxSplitParts)
+	}
+
+	ids := make(map[string]int, len(generated))
+	for i, g := range generated {
+		ids[g.ID] = i
+	}
+	inherited := make(map[string]bool, len(original.Dependencies))
+	for _, dep := range original.Dependencies {
+		inherited[dep] = true
+	}
+
+	parts := make([]*task.Task, len(generated))
+	for i, g := range generated {
+		p := g
+		p.ID = fmt.Sprintf("T%03d", firstID+i)
+		p.FeatureID = original.FeatureID
+		p.Status = task.StatusNotStarted
+		if p.Priority == "" {
+			p.Priority = original.Priority
+		}
+		if p.PRDSection == "" {
+			p.PRDSection = original.PRDSection
+		}
+
+		var deps []string
+		internal := false
+		seen := make(map[string]bool)
+		for _, dep := range g.Dependencies {
+			if idx, ok := ids[dep]; ok {
+				if idx >= i {
+					continue
+				}
+				dep = parts[idx].ID
+				internal = true
+			} else if !inherited[dep] {
+				continue
+			}
+			if !seen[dep] {
+				deps = append(deps, dep)
+				seen[dep] = true
+			}
+		}
+		if !internal {
+			for _, dep := range original.Dependencies {
+				if !seen[dep] {
+					deps = append(deps, dep)
+					seen[dep] = true
+				}
+			}
+		}
+		p.Dependencies = deps
+		parts[i] = &p
+	}
+
+	return parts, nil
+}
+
+// splitSinks returns the IDs of new tasks no other new task depends on
+func splitSinks(parts []*task.Task) []string {
+	dependedOn := make(map[string]bool)
+	for _, p := range parts {
+		for _, dep := range p.Dependencies {
+			dependedOn[dep] = true
+		}
+	}
+	var sinks []string
+	for _, p := range parts {
+		if !dependedOn[p.ID] {
+			sinks = append(sinks, p.ID)
+		}
+	}
+	return sinks
+}
+
+// rewireDependents returns the tasks that depended on the split task with
+// the split task replaced by replacements in their dependencies
+func rewireDependents(tasks []task.Task, splitID string, replacements []string) []task.Task {
+	var dependents []task.Task
+	for _, t := range tasks {
+		if t.ID == splitID {
+			continue
+		}
+		found := false
+		for _, dep := range t.Dependencies {
+			if dep == splitID {
+				found = true
+				break
+			}
+		}
+		if !found {
+			continue
+		}
+
+		var deps []string
+		seen := make(map[string]bool)
+		for _, dep := range t.Dependencies {
+			next := []string{dep}
+			if dep == splitID {
+				next = replacements
+			}
+			for _, d := range next {
+				if !seen[d] {
+					deps = append(deps, d)
+					seen[d] = true
+				}
+			}
+		}
+		t.Dependencies = deps
+		dependents = append(dependents, t)
+	}
+	return dependents
+}
+
+func buildTaskSplitPrompt(feature *task.Feature, t *task.Task, firstID, parts int) string {
+	count := fmt.Sprintf("%d-%d", minSplitParts, maxSplitParts)
+	if parts != 0 {
+		count = fmt.Sprintf("exactly %d", parts)
+	}
+
+	return fmt.Sprintf(`Split an oversized task into %s smaller tasks.
+
+Feature %s: %s
+
+%s
+
+Task to split:
+
+%s
+Number the new tasks consecutively starting at T%03d. Output ONLY the task
+blocks, separated by "---", each in this EXACT format:
+
+### T%03d: [Task Name]
+
+**Status:** NOT_STARTED
+**Priority:** %s
+**Estimated Effort:** X days
+
+#### Description
+
+[Clear description of what this task accomplishes]
+
+#### Technical Details
+
+[Implementation notes, patterns to follow, architectural decisions]
+
+#### Files to Touch
+
+- `+"`path/to/file.go`"+` (new)
+
+#### Dependencies
+
+- %s
+
+#### Success Criteria
+
+- [ ] [Specific deliverable 1]
+- [ ] [Specific deliverable 2]
+
+RULES:
+1. Together the new tasks must cover the original task's description and success criteria
+2. Each task must be atomic, testable and 0.5-2 days of work
+3. Dependencies may list the original dependencies or EARLIER new tasks only
+4. Do NOT create or modify any files
+
+Output only the markdown content, no additional explanation.`,
+		count, feature.ID, feature.Name, strings.TrimSpace(feature.Overview),
+		task.FormatTask(t), firstID, firstID, t.Priority, formatDeps(t.Dependencies))
+}
@@ -0,0 +1,41 @@
+package cmd
+
+import (
+	"context"
+	"time"
+
+	"hermes/internal/config"
+	"hermes/internal/tracing"
+)
+
+// tracingShutdownTimeout bounds how long the process waits to export remaining spans on exit
+const tracingShutdownTimeout = 5 * time.Second
+
+var shutdownTracing func(context.Context) error
+
+// ConfigureTracing exports OpenTelemetry spans of runs when tracing.enabled
+// is set. A misconfigured exporter never stops a command; it is logged.
+func ConfigureTracing() {
+	cfg, err := config.Load(".")
+	if err != nil || !cfg.Tracing.Enabled {
+		return
+	}
+	shutdown, err := tracing.Setup(context.Background(), cfg.Tracing, GetVersion())
+	if err != nil {
+		logBackgroundWarning("Failed to set up tracing: %v", err)
+		return
+	}
+	shutdownTracing = shutdown
+}
+
+// ShutdownTracing exports the remaining spans before the process exits
+func ShutdownTracing() {
+	if shutdownTracing == nil {
+		return
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), tracingShutdownTimeout)
+	defer cancel()
+	if err := shutdownTracing(ctx); err != nil {
+		logBackgroundWarning("Failed to export traces: %v", err)
+	}
+}
@@ -19,7 +19,7 @@ func NewTuiCmd() *cobra.Command {
 		},
 	}
 
-	return cmd
+	return usesSecrets(cmd)
 }
 
 func tuiExecute() error {
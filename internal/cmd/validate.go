@@ -0,0 +1,83 @@
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+
+	"github.com/fatih/color"
+	"github.com/spf13/cobra"
+	"hermes/internal/task"
+)
+
+type validateOptions struct {
+	strict  bool
+	jsonOut bool
+}
+
+// NewValidateCmd creates the validate subcommand
+func NewValidateCmd() *cobra.Command {
+	opts := &validateOptions{}
+
+	cmd := &cobra.Command{
+		Use:   "validate",
+		Short: "Check task files for parse problems",
+		Long: `Strictly parse every feature file in .hermes/tasks and report problems the
+task reader silently tolerates: malformed task headers, unknown statuses,
+dependencies that are not task IDs or name missing tasks, duplicate IDs and
+tasks without success criteria. Each problem is reported with its file and line.`,
+		Example: `  hermes validate
+  hermes validate --strict
+  hermes validate --json`,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return validateExecute(opts)
+		},
+	}
+
+	cmd.Flags().BoolVar(&opts.strict, "strict", false, "Fail on warnings as well as errors")
+	cmd.Flags().BoolVar(&opts.jsonOut, "json", false, "Print problems as JSON")
+
+	return cmd
+}
+
+func validateExecute(opts *validateOptions) error {
+	reader := task.NewReader(".")
+	if !reader.HasTasks() {
+		fmt.Println("No task files found, run 'hermes prd' or 'hermes add' first.")
+		return nil
+	}
+
+	diags, err := reader.Validate()
+	if err != nil {
+		return err
+	}
+	errors, warnings := task.CountDiagnostics(diags)
+
+	if opts.jsonOut {
+		if diags == nil {
+			diags = []task.Diagnostic{}
+		}
+		data, err := json.MarshalIndent(diags, "", "  ")
+		if err != nil {
+			return err
+		}
+		fmt.Println(string(data))
+	} else {
+		for _, d := range diags {
+			if d.Severity == task.SeverityError {
+				color.Red("%s", d)
+			} else {
+				color.Yellow("%s", d)
+			}
+		}
+		if len(diags) == 0 {
+			color.Green("All task files are valid")
+		} else {
+			fmt.Printf("\n%d errors, %d warnings\n", errors, warnings)
+		}
+	}
+
+	if errors > 0 || (opts.strict && warnings > 0) {
+		return fmt.Errorf("task files have problems")
+	}
+	return nil
+}
@@ -0,0 +1,300 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"os/signal"
+	"path/filepath"
+	"syscall"
+	"time"
+
+	"github.com/fsnotify/fsnotify"
+	"github.com/spf13/cobra"
+	"hermes/internal/ai"
+	"hermes/internal/circuit"
+	"hermes/internal/config"
+	"hermes/internal/git"
+	"hermes/internal/lock"
+	"hermes/internal/recovery"
+	"hermes/internal/task"
+	"hermes/internal/ui"
+)
+
+// NewWatchCmd creates the watch subcommand
+func NewWatchCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "watch",
+		Short: "Watch tasks and run them automatically",
+		Long: `Monitor .hermes/tasks/ for new or unblocked tasks and start the run loop
+whenever one becomes ready. Runs until interrupted; an open circuit breaker
+pauses execution until 'hermes reset' is run. After a run stops on
+loop.maxRunMinutes or loop.maxRunCost, the next starts when a task file
+changes.`,
+		Example: `  hermes watch
+  hermes watch --auto-commit
+  hermes watch --debounce 10s`,
+		RunE: watchExecute,
+	}
+
+	cmd.Flags().Bool("auto-branch", false, "Create feature branches (overrides config)")
+	cmd.Flags().Bool("auto-commit", false, "Commit on task completion (overrides config)")
+	cmd.Flags().Bool("debug", false, "Enable debug output")
+	cmd.Flags().String("ai", "", "AI provider: claude, droid, gemini, auto (default: from config or auto)")
+	cmd.Flags().Duration("debounce", 3*time.Second, "Wait for task file changes to settle before running")
+
+	return usesSecrets(cmd)
+}
+
+func watchExecute(cmd *cobra.Command, args []string) error {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	cfg, err := config.Load(".")
+	if err != nil {
+		return err
+	}
+
+	opts := sequentialOptions{
+		autoBranch: cfg.TaskMode.AutoBranch,
+		autoCommit: cfg.TaskMode.AutoCommit,
+		autonomous: true, // Nobody is at the terminal to press Enter
+	}
+	if cmd.Flags().Changed("auto-branch") {
+		opts.autoBranch, _ = cmd.Flags().GetBool("auto-branch")
+	}
+	if cmd.Flags().Changed("auto-commit") {
+		opts.autoCommit, _ = cmd.Flags().GetBool("auto-commit")
+	}
+	debug, _ := cmd.Flags().GetBool("debug")
+	debounce, _ := cmd.Flags().GetDuration("debounce")
+
+	logger, err := ui.NewLogger(".", debug)
+	if err != nil {
+		return err
+	}
+	defer logger.Close()
+
+	sigChan := make(chan os.Signal, 1)
+	signal.Notify(sigChan, os.Interrupt, syscall.SIGTERM)
+	go func() {
+		<-sigChan
+		fmt.Println("\nReceived interrupt, shutting down...")
+		logger.Info("Watch interrupted by user (SIGINT/SIGTERM)")
+		cancel()
+	}()
+
+	ui.PrintBanner(GetVersion())
+	ui.PrintHeader("Watch Mode")
+
+	reader := task.NewReader(".")
+	reader.SetImplicitDocDependencies(cfg.Parallel.ImplicitDocDependencies)
+	reader.SetPriorityAging(cfg.TaskMode.PriorityAgingLoops)
+	breaker := circuit.New(".")
+	gitOps := git.New(".")
+	gitOps.SetStageExcludes(cfg.GetStageExcludes()...)
+	gitOps.SetCommitPolicy(git.NewCommitPolicy(cfg.Git))
+	gitOps.SetSigning(git.NewSigning(cfg.Git))
+	if err := gitOps.SetCommitFormat(git.NewCommitFormat(cfg.Git)); err != nil {
+		return err
+	}
+	if cfg.Git.TrackTasks {
+		enableTaskTracking(gitOps, cfg, logger)
+	}
+
+	if err := breaker.Initialize(); err != nil {
+		return err
+	}
+	breaker.SetStateChangeCallback(func(fromState, toState circuit.State, reason string) {
+		logger.Info("Circuit breaker: %s -> %s (%s)", fromState, toState, reason)
+	})
+	breaker.SetCooldown(cfg.BreakerCooldown())
+
+	aiFlag, _ := cmd.Flags().GetString("ai")
+	provider, err := selectCodingProvider(aiFlag, cfg)
+	if err != nil {
+		return err
+	}
+	logger.Info("Using AI provider: %s", provider.Name())
+	if sb := ai.GetSandbox(); sb.Mode != ai.SandboxNone && sb.Mode != "" {
+		logger.Info("Provider sandbox: %s", sb)
+	}
+
+	// The tasks directory the status sidecar is kept in, see enableTaskTracking
+	hermesDir := cfg.Paths.HermesDir
+	tasksDir := cfg.Paths.TasksDir
+	if err := os.MkdirAll(tasksDir, 0755); err != nil {
+		return err
+	}
+
+	watcher, err := fsnotify.NewWatcher()
+	if err != nil {
+		return fmt.Errorf("failed to start file watcher: %w", err)
+	}
+	defer watcher.Close()
+
+	// Watch the tasks directory for task changes and the hermes directory
+	// so a 'hermes reset' of the circuit breaker resumes execution
+	for _, dir := range []string{tasksDir, hermesDir} {
+		if err := watcher.Add(dir); err != nil {
+			return fmt.Errorf("failed to watch %s: %w", dir, err)
+		}
+	}
+
+	logger.Info("Watching %s for new or unblocked tasks (debounce %s)", tasksDir, debounce)
+
+	w := &taskWatcher{
+		ctx:     ctx,
+		reader:  reader,
+		breaker: breaker,
+		logger:  logger,
+	}
+	opts.onLimit = func(reason string) { w.limited = true }
+	w.run = func() error {
+		l, err := lock.Acquire(".", "watch")
+		if err != nil {
+			return err
+		}
+		defer l.Release()
+		return runSequential(ctx, cfg, provider, reader, breaker, gitOps, logger, opts)
+	}
+
+	return w.watch(watcher.Events, watcher.Errors, tasksDir, debounce)
+}
+
+// watchQuiet is how long the watcher must be quiet after a run before the
+// events of the files the run wrote are all discarded
+var watchQuiet = 200 * time.Millisecond
+
+// taskWatcher starts the run loop when a task becomes runnable
+type taskWatcher struct {
+	ctx     context.Context
+	reader  *task.Reader
+	breaker *circuit.Breaker
+	logger  *ui.Logger
+	run     func() error
+	halted  bool
+	// limited is set when a run stopped on its duration or budget limit; only
+	// a change of the task files starts another run, with a new budget
+	limited bool
+}
+
+// watch checks for a runnable task at startup and whenever the watched files
+// have not changed for debounce, until ctx is done. The events of the files a
+// run writes itself are discarded after it.
+func (w *taskWatcher) watch(events <-chan fsnotify.Event, errs <-chan error, tasksDir string, debounce time.Duration) error {
+	// Check once at startup so already pending tasks are picked up
+	if w.runIfReady() {
+		discardEvents(events)
+	}
+
+	var settle <-chan time.Time
+	for {
+		select {
+		case <-w.ctx.Done():
+			return nil
+		case event, ok := <-events:
+			if !ok {
+				return nil
+			}
+			if !isWatchRelevant(event, tasksDir) || (w.limited && !isTaskEvent(event, tasksDir)) {
+				continue
+			}
+			w.limited = false
+			settle = time.After(debounce)
+		case err, ok := <-errs:
+			if !ok {
+				return nil
+			}
+			w.logger.Warn("Watcher error: %v", err)
+		case <-settle:
+			settle = nil
+			if w.runIfReady() {
+				discardEvents(events)
+			}
+		}
+	}
+}
+
+// discardEvents drops file events until none arrived for watchQuiet
+func discardEvents(events <-chan fsnotify.Event) {
+	for {
+		select {
+		case _, ok := <-events:
+			if !ok {
+				return
+			}
+		case <-time.After(watchQuiet):
+			return
+		}
+	}
+}
+
+// runIfReady runs the sequential loop if a task is ready and the breaker
+// allows it, and reports whether it ran
+func (w *taskWatcher) runIfReady() bool {
+	if w.ctx.Err() != nil {
+		return false
+	}
+
+	canExecute, err := w.breaker.CanExecute()
+	if err != nil {
+		w.logger.Warn("Failed to check circuit breaker: %v", err)
+		return false
+	}
+	if !canExecute {
+		if !w.halted {
+			state, _ := w.breaker.GetState()
+			if at, ok := w.breaker.RecoveryAt(); ok {
+				w.logger.Warn("Circuit breaker OPEN (%s), probing again at %s", state.Reason, at.Format("15:04:05"))
+			} else {
+				w.logger.Warn("Circuit breaker OPEN (%s), waiting for 'hermes reset'", state.Reason)
+			}
+			if d := recovery.ForBreaker(".", state); d != nil {
+				w.logger.Info("Suggested remediations: %s", d.Summary())
+			}
+			w.halted = true
+		}
+		return false
+	}
+	w.halted = false
+
+	nextTask, err := w.reader.GetNextTask()
+	if err != nil {
+		w.logger.Warn("Failed to read tasks: %v", err)
+		return false
+	}
+	if nextTask == nil {
+		w.logger.Debug("No runnable tasks")
+		return false
+	}
+
+	w.logger.Info("Task %s is ready, starting run", nextTask.ID)
+	if err := w.run(); err != nil && w.ctx.Err() == nil {
+		w.logger.Error("Run failed: %v", err)
+	}
+	if w.ctx.Err() == nil && w.limited {
+		w.logger.Info("Run stopped on its limits, waiting for a task change before starting another")
+	} else if w.ctx.Err() == nil {
+		w.logger.Info("Run finished, watching for changes")
+	}
+	return true
+}
+
+// isWatchRelevant reports whether a file event should trigger a run check
+func isWatchRelevant(event fsnotify.Event, tasksDir string) bool {
+	if event.Op == fsnotify.Chmod {
+		return false
+	}
+	name := filepath.Base(event.Name)
+	if filepath.Dir(event.Name) == filepath.Clean(tasksDir) {
+		return filepath.Ext(name) == ".md" || task.IsYAMLFile(name) || name == task.StatusSidecarFile
+	}
+	return name == "circuit-state.json"
+}
+
+// isTaskEvent reports whether a file event is a change of the task files
+// rather than of the circuit breaker state
+func isTaskEvent(event fsnotify.Event, tasksDir string) bool {
+	return isWatchRelevant(event, tasksDir) && filepath.Dir(event.Name) == filepath.Clean(tasksDir)
+}
@@ -0,0 +1,74 @@
+package cmd
+
+import (
+	"sync"
+	"time"
+
+	"hermes/internal/circuit"
+	"hermes/internal/config"
+	"hermes/internal/task"
+	"hermes/internal/ui"
+	"hermes/internal/webhook"
+)
+
+// webhookFlushTimeout bounds how long the process waits for pending deliveries on exit
+const webhookFlushTimeout = 5 * time.Second
+
+var (
+	backgroundLoggerOnce sync.Once
+	backgroundLogger     *ui.Logger
+)
+
+// logBackgroundWarning writes a warning of a background integration (Jira,
+// webhooks) to the log file only, so it never disturbs the console or TUI
+func logBackgroundWarning(format string, args ...interface{}) {
+	backgroundLoggerOnce.Do(func() {
+		l, err := ui.NewLogger(".", false)
+		if err != nil {
+			return
+		}
+		l.SetSilent(true)
+		backgroundLogger = l
+	})
+	if backgroundLogger != nil {
+		backgroundLogger.Warn(format, args...)
+	}
+}
+
+// ConfigureWebhooks posts lifecycle events to the webhooks in the config.
+// Run and task outcome events come from the run recorder; task starts and
+// breaker changes are forwarded from their hooks here.
+func ConfigureWebhooks() {
+	cfg, err := config.Load(".")
+	if err != nil || len(cfg.Webhooks) == 0 {
+		return
+	}
+
+	dispatcher := webhook.NewDispatcher(cfg.Webhooks)
+	dispatcher.SetErrorHandler(func(url string, err error) {
+		logBackgroundWarning("Webhook delivery to %s failed: %v", url, err)
+	})
+	webhook.Configure(dispatcher)
+
+	task.OnStatusChange(func(basePath, taskID string, status task.Status) {
+		if status == task.StatusInProgress {
+			webhook.Emit(webhook.TaskStarted, "", map[string]any{"taskId": taskID})
+		}
+	})
+	circuit.OnStateChange(func(fromState, toState circuit.State, reason string) {
+		data := map[string]any{
+			"from":   string(fromState),
+			"to":     string(toState),
+			"reason": reason,
+		}
+		webhook.Emit(webhook.BreakerChanged, "", data)
+		if toState == circuit.StateOpen {
+			webhook.Emit(webhook.BreakerOpened, "", data)
+		}
+	})
+}
+
+// FlushWebhooks waits for pending webhook deliveries before the process exits
+func FlushWebhooks() {
+	webhook.Flush(webhookFlushTimeout)
+}
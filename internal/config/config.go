@@ -2,40 +2,130 @@ package config
 
 import (
 	"encoding/json"
+	"fmt"
 	"os"
 	"path/filepath"
+	"time"
 
 	"github.com/spf13/viper"
 )
 
-// Load loads configuration with priority: Project config > Global config > Defaults
+// FileNames are the names a config file may have in a .hermes directory, in
+// the order they are looked for
+var FileNames = []string{"config.json", "config.yaml", "config.yml", "config.toml"}
+
+// Load loads configuration with priority: Environment > Profile > Project
+// config > Global config > Defaults. A config file that cannot be parsed or
+// holds values of the wrong type is an error. Other problems such as unknown
+// options, invalid values and invalid environment variables are reported on
+// stderr once per process; invalid environment variables are ignored.
 func Load(basePath string) (*Config, error) {
-	cfg := DefaultConfig()
+	cfg, files, err := loadFiles(basePath)
+	if err != nil {
+		return nil, err
+	}
+	applyProfile(cfg, files)
 
-	// Global config: ~/.hermes/config.json
-	if homeDir, err := os.UserHomeDir(); err == nil {
-		globalPath := filepath.Join(homeDir, ".hermes", "config.json")
-		loadFile(globalPath, cfg)
+	// Environment: HERMES_<SECTION>_<OPTION>, e.g. HERMES_AI_CODING
+	for _, err := range applyEnv(cfg, os.LookupEnv) {
+		warnOnce(fmt.Sprintf("warning: ignoring %v", err))
 	}
 
-	// Project config: .hermes/config.json
-	projectPath := filepath.Join(basePath, ".hermes", "config.json")
-	loadFile(projectPath, cfg)
+	warnProblems(basePath, cfg)
+	return cfg, nil
+}
+
+// applyProfile overlays the profile selected with --profile from the config
+// files read
+func applyProfile(cfg *Config, files []*viper.Viper) {
+	if profile == "" {
+		return
+	}
+	for _, v := range files {
+		if sub := v.Sub("profiles." + profile); sub != nil {
+			sub.Unmarshal(cfg)
+		}
+	}
+}
 
+// LoadFiles loads configuration like Load without the profile and the
+// environment overrides, for editing the config files
+func LoadFiles(basePath string) (*Config, error) {
+	cfg, _, err := loadFiles(basePath)
+	if err != nil {
+		return nil, err
+	}
 	return cfg, nil
 }
 
-// loadFile loads a config file and merges it into cfg
-func loadFile(path string, cfg *Config) error {
+// loadFiles loads the global and the project config file over the defaults
+// and returns the files read. A file that cannot be loaded is skipped and
+// the first such error returned.
+func loadFiles(basePath string) (*Config, []*viper.Viper, error) {
+	migrateFiles(basePath)
+	cfg := DefaultConfig()
+	var files []*viper.Viper
+	var firstErr error
+
+	// Global config: config.json, .yaml, .yml or .toml in ~/.hermes and
+	// ~/.config/hermes, the latter taking precedence, then the project
+	// config: .hermes/config.json, .yaml, .yml or .toml
+	for _, path := range configPaths(basePath) {
+		v, err := loadFile(path, cfg)
+		if err != nil {
+			if firstErr == nil {
+				firstErr = err
+			}
+			continue
+		}
+		files = append(files, v)
+	}
+
+	return cfg, files, firstErr
+}
+
+// GlobalDirs returns the directories of the user-wide config files in the
+// order they are loaded: ~/.hermes and $XDG_CONFIG_HOME/hermes, by default
+// ~/.config/hermes
+func GlobalDirs() []string {
+	var dirs []string
+	homeDir, err := os.UserHomeDir()
+	if err == nil {
+		dirs = append(dirs, filepath.Join(homeDir, ".hermes"))
+	}
+	if xdg := os.Getenv("XDG_CONFIG_HOME"); xdg != "" {
+		dirs = append(dirs, filepath.Join(xdg, "hermes"))
+	} else if err == nil {
+		dirs = append(dirs, filepath.Join(homeDir, ".config", "hermes"))
+	}
+	return dirs
+}
+
+// FindFile returns the config file in dir, the first of FileNames that
+// exists, or an empty string when there is none
+func FindFile(dir string) string {
+	for _, name := range FileNames {
+		path := filepath.Join(dir, name)
+		if _, err := os.Stat(path); err == nil {
+			return path
+		}
+	}
+	return ""
+}
+
+// loadFile loads a JSON, YAML or TOML config file, by its extension, and
+// merges it into cfg
+func loadFile(path string, cfg *Config) (*viper.Viper, error) {
 	v := viper.New()
 	v.SetConfigFile(path)
-	v.SetConfigType("json")
 
 	if err := v.ReadInConfig(); err != nil {
-		return err
+		return nil, fmt.Errorf("cannot read config %s: %w", path, err)
 	}
-
-	return v.Unmarshal(cfg)
+	if err := v.Unmarshal(cfg); err != nil {
+		return nil, fmt.Errorf("invalid config %s: %w", path, err)
+	}
+	return v, nil
 }
 
 // GetAIForTask returns the AI provider for a given task type
@@ -96,3 +186,35 @@ func (c *Config) GetLogsPath(basePath string) string {
 func (c *Config) GetHermesPath(basePath string) string {
 	return filepath.Join(basePath, c.Paths.HermesDir)
 }
+
+// RunLimitReached returns why a run must stop given its elapsed time and
+// spend so far, or an empty string if it is within the configured limits
+func (c *Config) RunLimitReached(elapsed time.Duration, cost float64) string {
+	if c.Loop.MaxRunMinutes > 0 && elapsed >= time.Duration(c.Loop.MaxRunMinutes)*time.Minute {
+		return fmt.Sprintf("maximum run duration of %d minutes reached", c.Loop.MaxRunMinutes)
+	}
+	if c.Loop.MaxRunCost > 0 && cost >= c.Loop.MaxRunCost {
+		return fmt.Sprintf("run budget of $%.2f reached ($%.2f spent)", c.Loop.MaxRunCost, cost)
+	}
+	return ""
+}
+
+// BreakerCooldown returns how long an open circuit breaker waits before
+// probing again, 0 if it waits for a manual reset
+func (c *Config) BreakerCooldown() time.Duration {
+	return time.Duration(c.Loop.BreakerCooldownMinutes) * time.Minute
+}
+
+// DrainTimeout returns how long an interrupted run waits for its running
+// tasks to finish before stopping them
+func (c *Config) DrainTimeout() time.Duration {
+	return time.Duration(c.Loop.DrainTimeout) * time.Second
+}
+
+// GetStageExcludes returns the paths auto-commit must never stage.
+// Hermes state is always excluded from code commits; when TrackTasks is
+// enabled task files are committed separately (see git.CommitPaths).
+func (c *Config) GetStageExcludes() []string {
+	excludes := []string{c.Paths.HermesDir}
+	return append(excludes, c.Git.ExcludePatterns...)
+}
@@ -3,7 +3,9 @@ package config
 import (
 	"os"
 	"path/filepath"
+	"strings"
 	"testing"
+	"time"
 )
 
 func TestDefaultConfig(t *testing.T) {
@@ -18,8 +20,8 @@ func TestDefaultConfig(t *testing.T) {
 	if cfg.AI.Timeout != 300 {
 		t.Errorf("expected AI.Timeout = 300, got %d", cfg.AI.Timeout)
 	}
-	if cfg.AI.PrdTimeout != 1200 {
-		t.Errorf("expected AI.PrdTimeout = 1200, got %d", cfg.AI.PrdTimeout)
+	if cfg.AI.PlanningTimeout != 1200 {
+		t.Errorf("expected AI.PlanningTimeout = 1200, got %d", cfg.AI.PlanningTimeout)
 	}
 	if cfg.AI.MaxRetries != 10 {
 		t.Errorf("expected AI.MaxRetries = 10, got %d", cfg.AI.MaxRetries)
@@ -100,6 +102,213 @@ func TestLoadConfig(t *testing.T) {
 	}
 }
 
+func TestLoadConfigFormats(t *testing.T) {
+	files := map[string]string{
+		"config.yaml": "# Comments are allowed\nai:\n  coding: gemini\nparallel:\n  maxWorkers: 4\ngit:\n  protectedBranches: [main]\n",
+		"config.toml": "# Comments are allowed\n[ai]\ncoding = \"gemini\"\n\n[parallel]\nmaxWorkers = 4\n\n[git]\nprotectedBranches = [\"main\"]\n",
+	}
+	for name, content := range files {
+		tmpDir := t.TempDir()
+		os.MkdirAll(filepath.Join(tmpDir, ".hermes"), 0755)
+		os.WriteFile(filepath.Join(tmpDir, ".hermes", name), []byte(content), 0644)
+
+		if got := FindFile(filepath.Join(tmpDir, ".hermes")); filepath.Base(got) != name {
+			t.Errorf("expected %s to be found, got %q", name, got)
+		}
+		cfg, err := Load(tmpDir)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if cfg.AI.Coding != "gemini" || cfg.Parallel.MaxWorkers != 4 || strings.Join(cfg.Git.ProtectedBranches, ",") != "main" {
+			t.Errorf("%s: options not loaded, got %+v %+v", name, cfg.AI, cfg.Parallel)
+		}
+		if cfg.AI.Planning != "claude" {
+			t.Errorf("%s: expected defaults to be kept, got planning %q", name, cfg.AI.Planning)
+		}
+	}
+
+	// config.json wins over the other formats
+	tmpDir := t.TempDir()
+	os.MkdirAll(filepath.Join(tmpDir, ".hermes"), 0755)
+	os.WriteFile(filepath.Join(tmpDir, ".hermes", "config.yaml"), []byte("ai:\n  coding: gemini\n"), 0644)
+	os.WriteFile(filepath.Join(tmpDir, ".hermes", "config.json"), []byte(`{"ai": {"coding": "droid"}}`), 0644)
+	if cfg, _ := Load(tmpDir); cfg.AI.Coding != "droid" {
+		t.Errorf("expected config.json to be used, got %q", cfg.AI.Coding)
+	}
+}
+
+func TestLoadConfigInvalidFile(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+	t.Setenv("XDG_CONFIG_HOME", t.TempDir())
+	for _, content := range []string{
+		`{"ai":{"sandbox":{"mode":"sudo","user":"nobody"},}}`, // Trailing comma
+		`{"ai": {"timeout": "ten minutes"}}`,
+	} {
+		tmpDir := t.TempDir()
+		path := filepath.Join(tmpDir, ".hermes", "config.json")
+		os.MkdirAll(filepath.Dir(path), 0755)
+		os.WriteFile(path, []byte(content), 0644)
+
+		// A broken file must not fall back to the defaults, e.g. no sandbox
+		if cfg, err := Load(tmpDir); err == nil || !strings.Contains(err.Error(), path) {
+			t.Errorf("expected an error naming %s for %s, got %v (%+v)", path, content, err, cfg)
+		}
+		if _, err := LoadFiles(tmpDir); err == nil {
+			t.Errorf("expected LoadFiles to fail for %s", content)
+		}
+	}
+}
+
+func TestLoadConfigGlobal(t *testing.T) {
+	home, xdg, project := t.TempDir(), t.TempDir(), t.TempDir()
+	t.Setenv("HOME", home)
+	t.Setenv("XDG_CONFIG_HOME", xdg)
+	os.MkdirAll(filepath.Join(home, ".hermes"), 0755)
+	os.WriteFile(filepath.Join(home, ".hermes", "config.json"), []byte(`{"ai": {"coding": "gemini", "timeout": 900}}`), 0644)
+	os.MkdirAll(filepath.Join(xdg, "hermes"), 0755)
+	os.WriteFile(filepath.Join(xdg, "hermes", "config.yaml"), []byte("ai:\n  coding: droid\nloop:\n  maxRunCost: 5\n"), 0644)
+	os.MkdirAll(filepath.Join(project, ".hermes"), 0755)
+	os.WriteFile(filepath.Join(project, ".hermes", "config.json"), []byte(`{"ai": {"timeout": 600}}`), 0644)
+
+	if dirs := GlobalDirs(); len(dirs) != 2 || dirs[1] != filepath.Join(xdg, "hermes") {
+		t.Fatalf("unexpected global config dirs %v", dirs)
+	}
+	cfg, _ := Load(project)
+	if cfg.AI.Coding != "droid" || cfg.Loop.MaxRunCost != 5 {
+		t.Errorf("expected the user config in ~/.config/hermes to be used, got %s and $%.2f", cfg.AI.Coding, cfg.Loop.MaxRunCost)
+	}
+	if cfg.AI.Timeout != 600 {
+		t.Errorf("expected the project config over the user config, got timeout %d", cfg.AI.Timeout)
+	}
+}
+
+func TestLoadConfigProfile(t *testing.T) {
+	tmpDir := t.TempDir()
+	os.MkdirAll(filepath.Join(tmpDir, ".hermes"), 0755)
+	os.WriteFile(filepath.Join(tmpDir, ".hermes", "config.json"), []byte(`{
+  "ai": {"coding": "claude", "timeout": 600},
+  "parallel": {"maxWorkers": 2},
+  "profiles": {
+    "ci": {"parallel": {"maxWorkers": 8}, "taskMode": {"autonomous": true}},
+    "cheap": {"ai": {"coding": "gemini"}}
+  }
+}`), 0644)
+	defer SetProfile("")
+
+	if got := strings.Join(Profiles(tmpDir), ","); got != "cheap,ci" {
+		t.Errorf("expected profiles cheap and ci, got %q", got)
+	}
+
+	SetProfile("CI")
+	cfg, _ := Load(tmpDir)
+	if cfg.Parallel.MaxWorkers != 8 || cfg.AI.Coding != "claude" || cfg.AI.Timeout != 600 {
+		t.Errorf("expected the ci profile over the base config, got %+v %+v", cfg.AI, cfg.Parallel)
+	}
+	if files, _ := LoadFiles(tmpDir); files.Parallel.MaxWorkers != 2 {
+		t.Errorf("expected LoadFiles to skip the profile, got %d workers", files.Parallel.MaxWorkers)
+	}
+
+	SetProfile("cheap")
+	t.Setenv("HERMES_AI_CODING", "droid")
+	if cfg, _ := Load(tmpDir); cfg.AI.Coding != "droid" || cfg.Parallel.MaxWorkers != 2 {
+		t.Errorf("expected the environment over the profile, got %s with %d workers", cfg.AI.Coding, cfg.Parallel.MaxWorkers)
+	}
+}
+
+func TestValidateConfig(t *testing.T) {
+	tmpDir := t.TempDir()
+	path := filepath.Join(tmpDir, "config.json")
+	if err := Save(path, DefaultConfig()); err != nil {
+		t.Fatal(err)
+	}
+	if problems := append(CheckFile(path), DefaultConfig().Validate()...); len(problems) != 0 {
+		t.Fatalf("expected the default config to be valid, got %v", problems)
+	}
+
+	os.WriteFile(path, []byte(`{
+  "parallel": {"maxWorker": 4, "maxWorkers": "four", "strategy": "branch"},
+  "profiles": {"ci": {"ai": {"codin": "droid"}}},
+  "ai": {"effortTimeouts": {"1 day": 600}}
+}`), 0644)
+	var got []string
+	for _, p := range CheckFile(path) {
+		got = append(got, strings.TrimPrefix(p.String(), path+": "))
+	}
+	want := []string{
+		"parallel.maxworker: unknown option, did you mean parallel.maxWorkers?",
+		"profiles.ci.ai.codin: unknown option, did you mean ai.coding?",
+		"parallel.maxWorkers: cannot parse value as 'int', got \"four\"",
+	}
+	if strings.Join(got, "\n") != strings.Join(want, "\n") {
+		t.Errorf("unexpected file problems:\n%s", strings.Join(got, "\n"))
+	}
+
+	cfg := DefaultConfig()
+	cfg.Parallel.Strategy = "branch"
+	cfg.Parallel.MaxWorkers = 0
+	cfg.Loop.MaxRunCost = -1
+	cfg.Analyzer.StatusAliases = map[string]string{"SHIPPED": "DONE"}
+	cfg.AI.PromptTemplates = map[string]string{"db": "../migration"}
+	cfg.Git.CommitTemplate = "{{.Type}}: {{.Name}}\n\nTask {{.ID}}"
+	got = nil
+	for _, p := range cfg.Validate() {
+		got = append(got, p.String())
+	}
+	want = []string{
+		"loop.maxRunCost: must not be negative, got -1",
+		"parallel.strategy: unknown value \"branch\", expected one of branch-per-task, worktree",
+		"parallel.maxWorkers: must be at least 1, got 0",
+		"parallel.minWorkers: must not exceed parallel.maxWorkers (0), got 1",
+		"ai.promptTemplates: template of tag \"db\" must be a name like \"migration\", got \"../migration\"",
+		"analyzer.statusAliases: alias \"SHIPPED\" maps to unknown status \"DONE\", expected one of COMPLETE, BLOCKED, AT_RISK, PAUSED, IN_PROGRESS",
+		"git.commitTemplate: the subject must contain ({{.ID}}), otherwise 'hermes rollback --task' cannot find task commits",
+	}
+	if strings.Join(got, "\n") != strings.Join(want, "\n") {
+		t.Errorf("unexpected problems:\n%s", strings.Join(got, "\n"))
+	}
+	if err := checkCommitTemplate("{{.Type}}: {{.Name}} ({{ .ID }})"); err != nil {
+		t.Errorf("expected a template with the task ID in the subject to be valid, got %v", err)
+	}
+}
+
+func TestLoadConfigEnvironment(t *testing.T) {
+	tmpDir := t.TempDir()
+	os.MkdirAll(filepath.Join(tmpDir, ".hermes"), 0755)
+	os.WriteFile(filepath.Join(tmpDir, ".hermes", "config.json"), []byte(`{"ai": {"coding": "gemini"}, "parallel": {"maxWorkers": 2}}`), 0644)
+
+	t.Setenv("HERMES_AI_CODING", "droid")
+	t.Setenv("HERMES_PARALLEL_MAX_WORKERS", "6")
+	t.Setenv("HERMES_PARALLEL_MAX_CPU_PERCENT", "80")
+	t.Setenv("HERMES_AI_SANDBOX_MODE", "container")
+	t.Setenv("HERMES_AI_EFFORT_TIMEOUTS", "1 day=600, 3 days=1800")
+	t.Setenv("HERMES_GIT_PROTECTED_BRANCHES", "main, release")
+	t.Setenv("HERMES_TASK_MODE_AUTO_COMMIT", "false")
+	t.Setenv("HERMES_LOOP_MAX_RUN_COST", "not a number")
+
+	cfg, err := Load(tmpDir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if cfg.AI.Coding != "droid" || cfg.Parallel.MaxWorkers != 6 || cfg.Parallel.MaxCPUPercent != 80 || cfg.AI.Sandbox.Mode != "container" {
+		t.Errorf("expected the environment to override the config file, got %+v %+v", cfg.AI, cfg.Parallel)
+	}
+	if cfg.AI.EffortTimeouts["3 days"] != 1800 || strings.Join(cfg.Git.ProtectedBranches, ",") != "main,release" {
+		t.Errorf("expected maps and lists to be parsed, got %v %v", cfg.AI.EffortTimeouts, cfg.Git.ProtectedBranches)
+	}
+	if cfg.TaskMode.AutoCommit {
+		t.Error("expected autoCommit to be disabled")
+	}
+	if cfg.Loop.MaxRunCost != 0 {
+		t.Errorf("expected the invalid value to be ignored, got %v", cfg.Loop.MaxRunCost)
+	}
+
+	for key, want := range map[string]string{"maxCPUPercent": "MAX_CPU_PERCENT", "maxMemoryMB": "MAX_MEMORY_MB", "coding": "CODING", "planningTimeout": "PLANNING_TIMEOUT"} {
+		if got := envName(key); got != want {
+			t.Errorf("envName(%q) = %q, want %q", key, got, want)
+		}
+	}
+}
+
 func TestSaveConfig(t *testing.T) {
 	tmpDir, err := os.MkdirTemp("", "hermes-config-test-*")
 	if err != nil {
@@ -156,3 +365,126 @@ func TestEnsureDirectories(t *testing.T) {
 		}
 	}
 }
+
+func TestGetStageExcludes(t *testing.T) {
+	cfg := DefaultConfig()
+	cfg.Git.ExcludePatterns = []string{"*.secret"}
+
+	excludes := cfg.GetStageExcludes()
+	if len(excludes) != 2 || excludes[0] != ".hermes" || excludes[1] != "*.secret" {
+		t.Errorf("unexpected excludes: %v", excludes)
+	}
+
+	// Task tracking commits task files separately, never with code
+	cfg.Git.TrackTasks = true
+	excludes = cfg.GetStageExcludes()
+	if excludes[0] != ".hermes" {
+		t.Errorf("expected .hermes to stay excluded when tracking tasks, got %v", excludes)
+	}
+}
+
+func TestRunLimitReached(t *testing.T) {
+	cfg := DefaultConfig()
+	if reason := cfg.RunLimitReached(10*time.Hour, 100); reason != "" {
+		t.Errorf("expected no limit by default, got %q", reason)
+	}
+
+	cfg.Loop.MaxRunMinutes = 30
+	cfg.Loop.MaxRunCost = 2.5
+	if reason := cfg.RunLimitReached(29*time.Minute, 2.49); reason != "" {
+		t.Errorf("expected run within limits, got %q", reason)
+	}
+	if reason := cfg.RunLimitReached(30*time.Minute, 0); !strings.Contains(reason, "30 minutes") {
+		t.Errorf("expected duration limit, got %q", reason)
+	}
+	if reason := cfg.RunLimitReached(time.Minute, 2.5); !strings.Contains(reason, "$2.50") {
+		t.Errorf("expected cost limit, got %q", reason)
+	}
+}
+
+func TestReloadConfig(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+	t.Setenv("XDG_CONFIG_HOME", t.TempDir())
+	tmpDir := t.TempDir()
+	path := filepath.Join(tmpDir, ".hermes", "config.json")
+	os.MkdirAll(filepath.Dir(path), 0755)
+
+	os.WriteFile(path, []byte(`{"ai": {"streamOutput": false}, "loop": {"maxRunCost": 10}}`), 0644)
+	cfg, err := Reload(tmpDir)
+	if err != nil {
+		t.Fatalf("Reload failed: %v", err)
+	}
+	if changes := Changes(DefaultConfig(), cfg); strings.Join(changes, ",") != "ai.streamOutput,loop.maxRunCost" {
+		t.Errorf("unexpected changes %v", changes)
+	}
+
+	os.WriteFile(path, []byte(`{"loop": {"maxRunCost": 5`), 0644)
+	if _, err := Reload(tmpDir); err == nil {
+		t.Error("expected an error for a partly written config file")
+	}
+	os.WriteFile(path, []byte(`{"parallel": {"maxWorkers": 0}}`), 0644)
+	if _, err := Reload(tmpDir); err == nil || !strings.Contains(err.Error(), "parallel.maxWorkers") {
+		t.Errorf("expected an error for an invalid config, got %v", err)
+	}
+}
+
+func TestConfigVersion(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+	t.Setenv("XDG_CONFIG_HOME", t.TempDir())
+	tmpDir := t.TempDir()
+	path := filepath.Join(tmpDir, ".hermes", "config.yaml")
+	os.MkdirAll(filepath.Dir(path), 0755)
+
+	os.WriteFile(path, []byte("version: 1\nai:\n  coding: droid\n"), 0644)
+	if problems := CheckFile(path); len(problems) != 0 {
+		t.Errorf("expected a file of the current version to be valid, got %v", problems)
+	}
+	if cfg, _ := Load(tmpDir); cfg.Version != 1 || cfg.AI.Coding != "droid" {
+		t.Errorf("expected version 1 and the file's options, got version %d, %s", cfg.Version, cfg.AI.Coding)
+	}
+
+	// A file without a version is version 0, from before ai.prdTimeout was
+	// renamed; one without renamed options is left as it is
+	unversioned := filepath.Join(t.TempDir(), ".hermes", "config.yaml")
+	os.MkdirAll(filepath.Dir(unversioned), 0755)
+	os.WriteFile(unversioned, []byte("ai:\n  coding: droid\n"), 0644)
+	Load(filepath.Dir(filepath.Dir(unversioned)))
+	if _, err := os.Stat(unversioned + ".v0.bak"); err == nil {
+		t.Error("expected a file without renamed options not to be migrated")
+	}
+
+	os.WriteFile(path, []byte("version: 2\n"), 0644)
+	problems := CheckFile(path)
+	if len(problems) != 1 || problems[0].Key != "version" || !strings.Contains(problems[0].Message, "version 2") {
+		t.Errorf("expected a file of a newer version to be reported, got %v", problems)
+	}
+}
+
+func TestMigrateConfig(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+	t.Setenv("XDG_CONFIG_HOME", t.TempDir())
+	tmpDir := t.TempDir()
+	path := filepath.Join(tmpDir, ".hermes", "config.json")
+	os.MkdirAll(filepath.Dir(path), 0755)
+	old := `{"ai": {"coding": "droid", "prdTimeout": 600}, "profiles": {"ci": {"ai": {"prdtimeout": 60}}}}`
+	os.WriteFile(path, []byte(old), 0644)
+
+	cfg, err := Load(tmpDir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if cfg.Version != CurrentVersion() || cfg.AI.PlanningTimeout != 600 || cfg.AI.Coding != "droid" {
+		t.Errorf("expected the renamed option to be kept, got version %d, %ds, %s", cfg.Version, cfg.AI.PlanningTimeout, cfg.AI.Coding)
+	}
+	if backup, _ := os.ReadFile(path + ".v0.bak"); string(backup) != old {
+		t.Errorf("expected a backup of the old file, got %q", backup)
+	}
+	SetProfile("ci")
+	defer SetProfile("")
+	if cfg, _ := Load(tmpDir); cfg.AI.PlanningTimeout != 60 {
+		t.Errorf("expected the profile to be migrated, got %ds", cfg.AI.PlanningTimeout)
+	}
+	if problems := CheckFile(path); len(problems) != 0 {
+		t.Errorf("expected the migrated file to be valid, got %v", problems)
+	}
+}
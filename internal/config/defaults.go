@@ -3,25 +3,50 @@ package config
 // DefaultConfig returns the default configuration
 func DefaultConfig() *Config {
 	return &Config{
+		Version: CurrentVersion(),
 		AI: AIConfig{
-			Planning:     "claude",
-			Coding:       "claude",
-			Timeout:      300,
-			PrdTimeout:   1200,
-			MaxRetries:   10,
-			RetryDelay:   5,
-			StreamOutput: true,
+			Planning:        "claude",
+			Coding:          "claude",
+			Timeout:         300,
+			PlanningTimeout: 1200,
+			MaxRetries:      10,
+			RetryDelay:      5,
+			StreamOutput:    true,
+			Sandbox: SandboxConfig{
+				Mode: "none",
+			},
+			ProjectContext: ProjectContextConfig{
+				Conventions: "CONVENTIONS.md",
+				MaxDepth:    3,
+			},
+			MaxPromptTokens: 150000,
 		},
 		TaskMode: TaskModeConfig{
 			AutoBranch:           true,
 			AutoCommit:           true,
 			Autonomous:           true,
 			MaxConsecutiveErrors: 5,
+			MaxRetries:           5,
+			PriorityAgingLoops:   0, // 0 means no aging
+			VerifyTimeout:        600,
+			TestCommand:          "",
+			TestFixAttempts:      2,
+			BuildCommand:         "",
+			LintCommand:          "",
+			FormatCommand:        "",
+			CoverageThreshold:    0, // 0 means no coverage check
+			CoverageCommand:      "go test -coverprofile=.hermes/coverage.out ./...",
+			CoverageProfile:      ".hermes/coverage.out",
 		},
 		Loop: LoopConfig{
 			MaxCallsPerHour: 100,
 			TimeoutMinutes:  15,
 			ErrorDelay:      10,
+			MaxRunMinutes:   0, // 0 means no limit
+			MaxRunCost:      0, // 0 means no limit
+
+			BreakerCooldownMinutes: 0, // 0 means manual reset only
+			DrainTimeout:           300,
 		},
 		Paths: PathsConfig{
 			HermesDir: ".hermes",
@@ -30,16 +55,52 @@ func DefaultConfig() *Config {
 			DocsDir:   ".hermes/docs",
 		},
 		Parallel: ParallelConfig{
-			Enabled:                 false,
-			MaxWorkers:              3,
-			Strategy:                "branch-per-task",
-			ConflictResolution:      "ai-assisted",
-			IsolatedWorkspaces:      true,
-			MergeStrategy:           "sequential",
-			MaxCostPerHour:          0, // 0 means no limit
-			FailureStrategy:         "continue",
-			MaxRetries:              2,
-			ImplicitDocDependencies: true,
+			Enabled:                  false,
+			MaxWorkers:               3,
+			MinWorkers:               1,
+			Strategy:                 "branch-per-task",
+			ConflictResolution:       "ai-assisted",
+			IsolatedWorkspaces:       true,
+			IsolationBackend:         "auto",
+			PoolWorktrees:            true,
+			MergeStrategy:            "sequential",
+			MaxCostPerHour:           0, // 0 means no limit
+			FailureStrategy:          "continue",
+			MaxRetries:               2,
+			ProviderFallback:         true,
+			ImplicitDocDependencies:  true,
+			ImplicitFileDependencies: false,
+			MaxMemoryMB:              0, // 0 means no limit
+			MaxCPUPercent:            0, // 0 means no limit
+		},
+		Git: GitConfig{
+			TrackTasks:      false,
+			ExcludePatterns: []string{},
+			PullRequests:    false,
+			Remote:          "origin",
+			AutoPush:        false,
+			PushRetries:     3,
+			DirtyTree:       "warn",
+
+			ProtectedBranches:   []string{},
+			RequireHermesBranch: false,
+			ScopedCommits:       false,
+			StageTaskFilesOnly:  false,
+			Sign:                false,
+
+			ReleaseNotes: false,
+		},
+		Logging: LoggingConfig{
+			MaxSizeMB:  10,
+			MaxFiles:   5,
+			MaxAgeDays: 30,
+			Compress:   true,
+		},
+		History: HistoryConfig{
+			Store: "json",
+		},
+		Hooks: HooksConfig{
+			Timeout: 600,
 		},
 	}
 }
@@ -0,0 +1,235 @@
+package config
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+
+	"github.com/pelletier/go-toml/v2"
+	"go.yaml.in/yaml/v3"
+)
+
+// migration upgrades the options of a config file, or of one of its
+// profiles, by one version and reports whether it changed any
+type migration func(doc map[string]interface{}) bool
+
+// migrations[i] upgrades a config file from version i to i+1. When an option
+// is renamed or moved, add a migration moving it so existing config files
+// keep their settings. Files without a version are version 0.
+var migrations = []migration{
+	// Version 1 renamed ai.prdTimeout, which also limits idea and convert
+	// runs, after the planning provider
+	func(doc map[string]interface{}) bool {
+		return moveOption(doc, "ai.prdTimeout", "ai.planningTimeout")
+	},
+}
+
+// CurrentVersion returns the version of the config format, the version the
+// last migration upgrades to
+func CurrentVersion() int {
+	return len(migrations)
+}
+
+// migratedPaths holds the config files already migrated by this process
+var migratedPaths sync.Map
+
+// migrateFile upgrades a config file written for an older version to the
+// current one, keeping the old file next to it as <file>.v<version>.bak. A
+// file none of the migrations change is left as it is. It returns the
+// version the file had and whether it was migrated.
+func migrateFile(path string) (from int, migrated bool, err error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return 0, false, nil // The loader reports missing and unreadable files
+	}
+	doc, err := decodeDocument(path, data)
+	if err != nil {
+		return 0, false, nil // The loader fails on the file
+	}
+
+	if v, ok := lookupOption(doc, "version"); ok {
+		if n, ok := toInt(v); ok {
+			from = n
+		}
+	}
+	if from >= CurrentVersion() {
+		return from, false, nil // CheckFile reports files of newer versions
+	}
+
+	changed := false
+	for v := from; v < CurrentVersion(); v++ {
+		changed = migrations[v](doc) || changed
+		if profiles, ok := lookupOption(doc, "profiles"); ok {
+			if profiles, ok := profiles.(map[string]interface{}); ok {
+				for _, p := range profiles {
+					if p, ok := p.(map[string]interface{}); ok {
+						changed = migrations[v](p) || changed
+					}
+				}
+			}
+		}
+	}
+	if !changed {
+		return from, false, nil
+	}
+	deleteOption(doc, "version")
+	doc["version"] = CurrentVersion()
+
+	updated, err := encodeDocument(path, doc)
+	if err != nil {
+		return from, false, err
+	}
+	backup := fmt.Sprintf("%s.v%d.bak", path, from)
+	if err := os.WriteFile(backup, data, 0644); err != nil {
+		return from, false, fmt.Errorf("cannot back up %s: %w", path, err)
+	}
+	tmp := path + ".tmp"
+	if err := os.WriteFile(tmp, updated, 0644); err != nil {
+		return from, false, err
+	}
+	return from, true, os.Rename(tmp, path)
+}
+
+// migrateFiles migrates the config files read for basePath, each once per
+// process, and reports what it did
+func migrateFiles(basePath string) {
+	for _, path := range configPaths(basePath) {
+		if _, done := migratedPaths.LoadOrStore(path, true); done {
+			continue
+		}
+		from, migrated, err := migrateFile(path)
+		if err != nil {
+			warnOnce(fmt.Sprintf("warning: config: cannot migrate %s: %v", path, err))
+		} else if migrated {
+			warnOnce(fmt.Sprintf("config: migrated %s from version %d to %d, the old file is %s.v%d.bak", path, from, CurrentVersion(), path, from))
+		}
+	}
+}
+
+// decodeDocument parses a JSON, YAML or TOML config file, by its extension
+func decodeDocument(path string, data []byte) (map[string]interface{}, error) {
+	doc := map[string]interface{}{}
+	var err error
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".yaml", ".yml":
+		err = yaml.Unmarshal(data, &doc)
+	case ".toml":
+		err = toml.Unmarshal(data, &doc)
+	default:
+		err = json.Unmarshal(data, &doc)
+	}
+	if doc == nil {
+		doc = map[string]interface{}{}
+	}
+	return doc, err
+}
+
+// encodeDocument formats a config file in the format of its extension
+func encodeDocument(path string, doc map[string]interface{}) ([]byte, error) {
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".yaml", ".yml":
+		return yaml.Marshal(doc)
+	case ".toml":
+		return toml.Marshal(doc)
+	default:
+		data, err := json.MarshalIndent(doc, "", "  ")
+		return append(data, '\n'), err
+	}
+}
+
+// moveOption moves an option to a new dotted path, for migrations of
+// renamed options, and reports whether the option was set. An option
+// already set at the new path is kept.
+func moveOption(doc map[string]interface{}, from, to string) bool {
+	value, ok := lookupOption(doc, from)
+	if !ok {
+		return false
+	}
+	deleteOption(doc, from)
+	if _, exists := lookupOption(doc, to); exists {
+		return true
+	}
+	parts := strings.Split(to, ".")
+	section := doc
+	for _, part := range parts[:len(parts)-1] {
+		key, ok := findKey(section, part)
+		if !ok {
+			key = part
+		}
+		child, isMap := section[key].(map[string]interface{})
+		if !isMap {
+			child = map[string]interface{}{}
+			section[key] = child
+		}
+		section = child
+	}
+	section[parts[len(parts)-1]] = value
+	return true
+}
+
+// lookupOption returns the value at a dotted path, matching keys like the
+// loader does regardless of case
+func lookupOption(doc map[string]interface{}, path string) (interface{}, bool) {
+	var value interface{} = doc
+	for _, part := range strings.Split(path, ".") {
+		section, ok := value.(map[string]interface{})
+		if !ok {
+			return nil, false
+		}
+		key, ok := findKey(section, part)
+		if !ok {
+			return nil, false
+		}
+		value = section[key]
+	}
+	return value, true
+}
+
+// deleteOption removes the option at a dotted path
+func deleteOption(doc map[string]interface{}, path string) {
+	parts := strings.Split(path, ".")
+	section := doc
+	for _, part := range parts[:len(parts)-1] {
+		key, ok := findKey(section, part)
+		if !ok {
+			return
+		}
+		if section, ok = section[key].(map[string]interface{}); !ok {
+			return
+		}
+	}
+	if key, ok := findKey(section, parts[len(parts)-1]); ok {
+		delete(section, key)
+	}
+}
+
+// findKey returns the key of a section equal to name regardless of case
+func findKey(section map[string]interface{}, name string) (string, bool) {
+	if _, ok := section[name]; ok {
+		return name, true
+	}
+	for key := range section {
+		if strings.EqualFold(key, name) {
+			return key, true
+		}
+	}
+	return "", false
+}
+
+// toInt converts a decoded number to an int
+func toInt(v interface{}) (int, bool) {
+	switch n := v.(type) {
+	case int:
+		return n, true
+	case int64:
+		return int(n), true
+	case uint64:
+		return int(n), true
+	case float64:
+		return int(n), n == float64(int(n))
+	}
+	return 0, false
+}
@@ -0,0 +1,39 @@
+package config
+
+import (
+	"sort"
+	"strings"
+)
+
+// profile is the named profile Load overlays on the config files, e.g. "ci"
+var profile string
+
+// SetProfile selects the profile Load overlays on the config files, empty
+// for none. Profiles are sections of the "profiles" object of a config file
+// holding the options that differ from the base configuration.
+func SetProfile(name string) {
+	profile = strings.ToLower(strings.TrimSpace(name))
+}
+
+// Profile returns the selected profile, empty when there is none
+func Profile() string {
+	return profile
+}
+
+// Profiles returns the names of the profiles defined in the global and the
+// project config file
+func Profiles(basePath string) []string {
+	_, files, _ := loadFiles(basePath)
+	seen := make(map[string]bool)
+	var names []string
+	for _, v := range files {
+		for name := range v.GetStringMap("profiles") {
+			if !seen[name] {
+				seen[name] = true
+				names = append(names, name)
+			}
+		}
+	}
+	sort.Strings(names)
+	return names
+}
@@ -0,0 +1,46 @@
+package config
+
+import (
+	"fmt"
+	"reflect"
+
+	"github.com/spf13/viper"
+)
+
+// Reload loads the configuration again while a run is in progress. Unlike
+// Load it fails when a config file cannot be read, e.g. while an editor is
+// still writing it, or the resulting configuration is invalid, so the run
+// keeps its configuration instead of falling back to the defaults.
+func Reload(basePath string) (*Config, error) {
+	for _, path := range configPaths(basePath) {
+		v := viper.New()
+		v.SetConfigFile(path)
+		if err := v.ReadInConfig(); err != nil {
+			return nil, fmt.Errorf("%s cannot be read: %w", path, err)
+		}
+	}
+	cfg, err := Load(basePath)
+	if err != nil {
+		return nil, err
+	}
+	if problems := cfg.Validate(); len(problems) > 0 {
+		return nil, fmt.Errorf("%s", problems[0])
+	}
+	return cfg, nil
+}
+
+// Changes returns the options whose values differ between two
+// configurations, e.g. ai.streamOutput
+func Changes(old, updated *Config) []string {
+	values := make(map[string]reflect.Value)
+	walkOptions(reflect.ValueOf(old).Elem(), "", func(key string, v reflect.Value) {
+		values[key] = v
+	})
+	var keys []string
+	walkOptions(reflect.ValueOf(updated).Elem(), "", func(key string, v reflect.Value) {
+		if !reflect.DeepEqual(values[key].Interface(), v.Interface()) {
+			keys = append(keys, key)
+		}
+	})
+	return keys
+}
@@ -2,22 +2,73 @@ package config
 
 // Config represents the complete Hermes configuration
 type Config struct {
-	AI       AIConfig       `json:"ai" mapstructure:"ai"`
-	TaskMode TaskModeConfig `json:"taskMode" mapstructure:"taskMode"`
-	Loop     LoopConfig     `json:"loop" mapstructure:"loop"`
-	Paths    PathsConfig    `json:"paths" mapstructure:"paths"`
-	Parallel ParallelConfig `json:"parallel" mapstructure:"parallel"`
+	Version  int             `json:"version" mapstructure:"version"` // Config format, see CurrentVersion
+	AI       AIConfig        `json:"ai" mapstructure:"ai"`
+	TaskMode TaskModeConfig  `json:"taskMode" mapstructure:"taskMode"`
+	Loop     LoopConfig      `json:"loop" mapstructure:"loop"`
+	Paths    PathsConfig     `json:"paths" mapstructure:"paths"`
+	Parallel ParallelConfig  `json:"parallel" mapstructure:"parallel"`
+	Git      GitConfig       `json:"git" mapstructure:"git"`
+	Analyzer AnalyzerConfig  `json:"analyzer" mapstructure:"analyzer"`
+	Jira     JiraConfig      `json:"jira" mapstructure:"jira"`
+	Tracing  TracingConfig   `json:"tracing" mapstructure:"tracing"`
+	Logging  LoggingConfig   `json:"logging" mapstructure:"logging"`
+	Audit    AuditConfig     `json:"audit" mapstructure:"audit"`
+	History  HistoryConfig   `json:"history" mapstructure:"history"`
+	Hooks    HooksConfig     `json:"hooks" mapstructure:"hooks"`
+	Webhooks []WebhookConfig `json:"webhooks,omitempty" mapstructure:"webhooks"`
+	// Environment variables set from secrets for providers and integrations,
+	// e.g. {"ANTHROPIC_API_KEY": "keychain:anthropic"}, see secrets.ParseRef
+	Secrets map[string]string `json:"secrets,omitempty" mapstructure:"secrets"`
 }
 
 // AIConfig contains AI provider settings
 type AIConfig struct {
-	Planning     string `json:"planning" mapstructure:"planning"`
-	Coding       string `json:"coding" mapstructure:"coding"`
-	Timeout      int    `json:"timeout" mapstructure:"timeout"`
-	PrdTimeout   int    `json:"prdTimeout" mapstructure:"prdTimeout"`
-	MaxRetries   int    `json:"maxRetries" mapstructure:"maxRetries"`
-	RetryDelay   int    `json:"retryDelay" mapstructure:"retryDelay"`
-	StreamOutput bool   `json:"streamOutput" mapstructure:"streamOutput"`
+	Planning        string        `json:"planning" mapstructure:"planning"`
+	Coding          string        `json:"coding" mapstructure:"coding"`
+	Timeout         int           `json:"timeout" mapstructure:"timeout"`
+	PlanningTimeout int           `json:"planningTimeout" mapstructure:"planningTimeout"`
+	MaxRetries      int           `json:"maxRetries" mapstructure:"maxRetries"`
+	RetryDelay      int           `json:"retryDelay" mapstructure:"retryDelay"`
+	StreamOutput    bool          `json:"streamOutput" mapstructure:"streamOutput"`
+	Sandbox         SandboxConfig `json:"sandbox" mapstructure:"sandbox"`
+	// Timeout in seconds of tasks by estimated effort, e.g. {"2 days": 1800}; a task
+	// uses the largest effort not above its own, its **Timeout:** field overrides it
+	EffortTimeouts map[string]int `json:"effortTimeouts,omitempty" mapstructure:"effortTimeouts"`
+	// Prompt templates by task tag, e.g. {"db": "migration"} for .hermes/prompts/task-migration.tmpl
+	// and execute-migration.tmpl; other tags use the templates named after them
+	PromptTemplates map[string]string    `json:"promptTemplates,omitempty" mapstructure:"promptTemplates"`
+	ProjectContext  ProjectContextConfig `json:"projectContext" mapstructure:"projectContext"`
+	// Estimated tokens a task prompt may take up before the project context and
+	// instructions are shortened to fit, 0 for no limit
+	MaxPromptTokens int `json:"maxPromptTokens" mapstructure:"maxPromptTokens"`
+	// Number of recent task commits, with their diffstats, added to task prompts
+	GitHistory int `json:"gitHistory" mapstructure:"gitHistory"`
+	// Let the AI record learnings in .hermes/MEMORY.md that are added to the
+	// prompts of later tasks
+	Memory bool `json:"memory" mapstructure:"memory"`
+}
+
+// ProjectContextConfig adds a generated overview of the project to task
+// prompts, so providers without memory of earlier tasks need not explore the
+// repository again
+type ProjectContextConfig struct {
+	Enabled     bool   `json:"enabled" mapstructure:"enabled"`
+	Conventions string `json:"conventions" mapstructure:"conventions"` // Conventions file included, relative to the project
+	MaxDepth    int    `json:"maxDepth" mapstructure:"maxDepth"`       // Depth of the file tree
+}
+
+// SandboxConfig confines provider subprocesses
+type SandboxConfig struct {
+	Mode       string   `json:"mode" mapstructure:"mode"`                       // none, sudo, systemd-run, container
+	User       string   `json:"user,omitempty" mapstructure:"user"`             // Low-privilege user to run providers as
+	Properties []string `json:"properties,omitempty" mapstructure:"properties"` // Extra systemd-run unit properties
+	PassEnv    []string `json:"passEnv,omitempty" mapstructure:"passEnv"`       // Environment variables forwarded into the sandbox
+	Image      string   `json:"image,omitempty" mapstructure:"image"`           // Container image with the provider CLIs installed
+	Runtime    string   `json:"runtime,omitempty" mapstructure:"runtime"`       // Container CLI, docker unless set
+	RunArgs    []string `json:"runArgs,omitempty" mapstructure:"runArgs"`       // Extra container run arguments
+	EnvAllow   []string `json:"envAllow,omitempty" mapstructure:"envAllow"`     // Only these variables (names or patterns like AWS_*) reach providers
+	EnvStrip   []string `json:"envStrip,omitempty" mapstructure:"envStrip"`     // Variables removed from the provider environment
 }
 
 // TaskModeConfig contains task execution settings
@@ -26,13 +77,35 @@ type TaskModeConfig struct {
 	AutoCommit           bool `json:"autoCommit" mapstructure:"autoCommit"`
 	Autonomous           bool `json:"autonomous" mapstructure:"autonomous"`
 	MaxConsecutiveErrors int  `json:"maxConsecutiveErrors" mapstructure:"maxConsecutiveErrors"`
+	MaxRetries           int  `json:"maxRetries" mapstructure:"maxRetries"`                 // Failed attempts in a row before a task is blocked, 0 means no limit
+	PriorityAgingLoops   int  `json:"priorityAgingLoops" mapstructure:"priorityAgingLoops"` // Loops a task waits before its priority is raised, 0 disables aging
+	VerifyTimeout        int  `json:"verifyTimeout" mapstructure:"verifyTimeout"`           // Timeout in seconds of the test command and each "cmd:" criterion
+	// Command run after each task unless the task sets **Test Command:**, e.g. "go test ./..."
+	TestCommand     string `json:"testCommand" mapstructure:"testCommand"`
+	TestFixAttempts int    `json:"testFixAttempts" mapstructure:"testFixAttempts"` // AI attempts to fix failing build, test or cmd: commands before the loop gives up
+	// Command that must succeed before a task is completed and committed, e.g. "go build ./..."
+	BuildCommand string `json:"buildCommand" mapstructure:"buildCommand"`
+	// Linter that must pass before a task is completed, e.g. "golangci-lint run"
+	LintCommand string `json:"lintCommand" mapstructure:"lintCommand"`
+	// Formatter run before the checks, whose changes are committed with the task, e.g. "gofmt -w ."
+	FormatCommand string `json:"formatCommand" mapstructure:"formatCommand"`
+	// Minimum statement coverage in percent of every package after a task, 0 disables the check
+	CoverageThreshold float64 `json:"coverageThreshold" mapstructure:"coverageThreshold"`
+	CoverageCommand   string  `json:"coverageCommand" mapstructure:"coverageCommand"` // Command writing a Go cover profile to CoverageProfile
+	CoverageProfile   string  `json:"coverageProfile" mapstructure:"coverageProfile"`
 }
 
 // LoopConfig contains loop execution settings
 type LoopConfig struct {
-	MaxCallsPerHour int `json:"maxCallsPerHour" mapstructure:"maxCallsPerHour"`
-	TimeoutMinutes  int `json:"timeoutMinutes" mapstructure:"timeoutMinutes"`
-	ErrorDelay      int `json:"errorDelay" mapstructure:"errorDelay"`
+	MaxCallsPerHour int     `json:"maxCallsPerHour" mapstructure:"maxCallsPerHour"`
+	TimeoutMinutes  int     `json:"timeoutMinutes" mapstructure:"timeoutMinutes"`
+	ErrorDelay      int     `json:"errorDelay" mapstructure:"errorDelay"`
+	MaxRunMinutes   int     `json:"maxRunMinutes" mapstructure:"maxRunMinutes"` // 0 means no limit
+	MaxRunCost      float64 `json:"maxRunCost" mapstructure:"maxRunCost"`       // 0 means no limit
+	// Minutes an open circuit breaker waits before probing one loop, 0 waits for a manual reset
+	BreakerCooldownMinutes int `json:"breakerCooldownMinutes" mapstructure:"breakerCooldownMinutes"`
+	// Seconds an interrupted run waits for running tasks to finish before stopping them, 0 stops them at once
+	DrainTimeout int `json:"drainTimeout" mapstructure:"drainTimeout"`
 }
 
 // PathsConfig contains directory paths
@@ -47,12 +120,119 @@ type PathsConfig struct {
 type ParallelConfig struct {
 	Enabled                  bool    `json:"enabled" mapstructure:"enabled"`
 	MaxWorkers               int     `json:"maxWorkers" mapstructure:"maxWorkers"`
+	MinWorkers               int     `json:"minWorkers" mapstructure:"minWorkers"` // Workers kept even without ready tasks; the pool grows up to MaxWorkers
 	Strategy                 string  `json:"strategy" mapstructure:"strategy"`
 	ConflictResolution       string  `json:"conflictResolution" mapstructure:"conflictResolution"`
 	IsolatedWorkspaces       bool    `json:"isolatedWorkspaces" mapstructure:"isolatedWorkspaces"`
+	IsolationBackend         string  `json:"isolationBackend" mapstructure:"isolationBackend"` // auto, worktree or copy; auto copies the project when it is not a git repository
+	PoolWorktrees            bool    `json:"poolWorktrees" mapstructure:"poolWorktrees"`       // Reuse the worktrees of finished tasks instead of creating one per task
 	MergeStrategy            string  `json:"mergeStrategy" mapstructure:"mergeStrategy"`
 	MaxCostPerHour           float64 `json:"maxCostPerHour" mapstructure:"maxCostPerHour"`
 	FailureStrategy          string  `json:"failureStrategy" mapstructure:"failureStrategy"`
 	MaxRetries               int     `json:"maxRetries" mapstructure:"maxRetries"`
+	ProviderFallback         bool    `json:"providerFallback" mapstructure:"providerFallback"` // Retry tasks whose provider timed out or was rate limited on the other available providers
 	ImplicitDocDependencies  bool    `json:"implicitDocDependencies" mapstructure:"implicitDocDependencies"`
+	ImplicitFileDependencies bool    `json:"implicitFileDependencies" mapstructure:"implicitFileDependencies"` // Order tasks touching the same files
+	MaxMemoryMB              int64   `json:"maxMemoryMB" mapstructure:"maxMemoryMB"`                           // Memory of Hermes and its AI processes above which no further task starts, 0 means no limit
+	MaxCPUPercent            int     `json:"maxCPUPercent" mapstructure:"maxCPUPercent"`                       // Share of all cores above which no further task starts, 0 means no limit
+}
+
+// GitConfig contains git integration settings
+type GitConfig struct {
+	TrackTasks       bool     `json:"trackTasks" mapstructure:"trackTasks"`           // Commit task files separately from code
+	ExcludePatterns  []string `json:"excludePatterns" mapstructure:"excludePatterns"` // Extra paths never staged by auto-commit
+	PullRequests     bool     `json:"pullRequests" mapstructure:"pullRequests"`       // Open a pull request for completed feature branches instead of merging
+	PullRequestDraft bool     `json:"pullRequestDraft" mapstructure:"pullRequestDraft"`
+	Remote           string   `json:"remote" mapstructure:"remote"`           // Remote feature branches are pushed to
+	AutoPush         bool     `json:"autoPush" mapstructure:"autoPush"`       // Push task commits, feature merges and tags to the remote
+	PushRetries      int      `json:"pushRetries" mapstructure:"pushRetries"` // Retries of a failed push, with backoff
+	DirtyTree        string   `json:"dirtyTree" mapstructure:"dirtyTree"`     // Uncommitted changes at run start: warn, prompt, stash or abort
+
+	// Commit guardrails
+	ProtectedBranches   []string `json:"protectedBranches" mapstructure:"protectedBranches"`     // Branches auto-commit never commits to directly
+	RequireHermesBranch bool     `json:"requireHermesBranch" mapstructure:"requireHermesBranch"` // Only auto-commit on feature/ and task/ branches
+	ScopedCommits       bool     `json:"scopedCommits" mapstructure:"scopedCommits"`             // Refuse task commits with files outside the task's Files to Touch
+	StageTaskFilesOnly  bool     `json:"stageTaskFilesOnly" mapstructure:"stageTaskFilesOnly"`   // Stage only the task's Files to Touch and warn about other changes
+
+	// Task commit messages
+	CommitTemplate string            `json:"commitTemplate,omitempty" mapstructure:"commitTemplate"` // Go template, default "{{.Type}}({{.ID}}): {{.Name}}"
+	CommitTypes    map[string]string `json:"commitTypes,omitempty" mapstructure:"commitTypes"`       // Words in task names -> conventional commit type
+	CoAuthor       string            `json:"coAuthor,omitempty" mapstructure:"coAuthor"`             // Co-authored-by trailer added to task commits
+
+	// Signing of commits, merges and tags
+	Sign          bool   `json:"sign" mapstructure:"sign"`
+	SigningKey    string `json:"signingKey,omitempty" mapstructure:"signingKey"`       // Key ID or SSH key path (default: user.signingKey)
+	SigningFormat string `json:"signingFormat,omitempty" mapstructure:"signingFormat"` // gpg, ssh or x509 (default: gpg.format)
+
+	// Release notes of feature tags
+	ReleaseNotes bool   `json:"releaseNotes" mapstructure:"releaseNotes"`     // Let the planning provider write the tag message of completed features
+	Changelog    string `json:"changelog,omitempty" mapstructure:"changelog"` // File the release notes are added to, e.g. CHANGELOG.md
+}
+
+// JiraConfig contains Jira synchronization settings. The API token is read from
+// the JIRA_API_TOKEN environment variable so it never ends up in a config file.
+type JiraConfig struct {
+	Enabled     bool              `json:"enabled" mapstructure:"enabled"` // Push task status changes to Jira
+	URL         string            `json:"url" mapstructure:"url"`         // e.g. https://example.atlassian.net
+	Email       string            `json:"email" mapstructure:"email"`
+	Project     string            `json:"project" mapstructure:"project"`
+	JQL         string            `json:"jql,omitempty" mapstructure:"jql"`                 // Issues to import (default: open issues of the project)
+	Transitions map[string]string `json:"transitions,omitempty" mapstructure:"transitions"` // Task status -> Jira status name
+}
+
+// TracingConfig contains OpenTelemetry trace export settings. The standard
+// OTEL_EXPORTER_OTLP_* environment variables apply when fields are empty.
+type TracingConfig struct {
+	Enabled  bool   `json:"enabled" mapstructure:"enabled"`
+	Endpoint string `json:"endpoint,omitempty" mapstructure:"endpoint"` // OTLP/HTTP collector, e.g. localhost:4318
+	Insecure bool   `json:"insecure,omitempty" mapstructure:"insecure"` // Use http instead of https
+}
+
+// LoggingConfig contains rotation and retention settings for the files
+// under .hermes/logs
+type LoggingConfig struct {
+	MaxSizeMB  int  `json:"maxSizeMB" mapstructure:"maxSizeMB"`   // Rotate a log at this size (0 disables)
+	Daily      bool `json:"daily" mapstructure:"daily"`           // Also rotate once a day
+	MaxFiles   int  `json:"maxFiles" mapstructure:"maxFiles"`     // Rotated files kept per log (0 keeps all)
+	MaxAgeDays int  `json:"maxAgeDays" mapstructure:"maxAgeDays"` // Delete rotated files older than this (0 keeps all)
+	Compress   bool `json:"compress" mapstructure:"compress"`     // Gzip rotated files
+}
+
+// AuditConfig controls the audit trail of AI executions in .hermes/audit
+type AuditConfig struct {
+	Enabled bool `json:"enabled" mapstructure:"enabled"` // Record every prompt and response
+}
+
+// HistoryConfig selects where run history is recorded for 'hermes stats',
+// 'hermes report' and the web dashboard
+type HistoryConfig struct {
+	Store string `json:"store" mapstructure:"store"` // "json" (files in .hermes/runs) or "sqlite" (.hermes/history.db)
+}
+
+// HooksConfig holds shell commands run around a run, e.g. to start services
+// with docker compose before the first task and stop them afterwards
+type HooksConfig struct {
+	PreRun  []string `json:"preRun,omitempty" mapstructure:"preRun"`   // Run before the first task, a failure aborts the run
+	PostRun []string `json:"postRun,omitempty" mapstructure:"postRun"` // Run when the run ends, also after a failure
+	// Run when the circuit breaker opens and execution halts, e.g. to page the on-call
+	BreakerOpen []string `json:"breakerOpen,omitempty" mapstructure:"breakerOpen"`
+	Timeout     int      `json:"timeout" mapstructure:"timeout"` // Timeout in seconds of each command
+}
+
+// WebhookConfig is an HTTP endpoint receiving lifecycle events as JSON
+type WebhookConfig struct {
+	URL     string            `json:"url" mapstructure:"url"`
+	Events  []string          `json:"events,omitempty" mapstructure:"events"`   // Event types to send, e.g. "task.completed" or "task.*"; empty sends all
+	Secret  string            `json:"secret,omitempty" mapstructure:"secret"`   // Signs payloads with HMAC-SHA256 in X-Hermes-Signature
+	Headers map[string]string `json:"headers,omitempty" mapstructure:"headers"` // Extra request headers, e.g. Authorization
+}
+
+// AnalyzerConfig overrides the response analyzer's keyword sets; empty sets keep the built-in defaults
+type AnalyzerConfig struct {
+	CompletionKeywords     []string          `json:"completionKeywords,omitempty" mapstructure:"completionKeywords"`
+	ImplementationPatterns []string          `json:"implementationPatterns,omitempty" mapstructure:"implementationPatterns"` // Signs of real progress
+	NoWorkPatterns         []string          `json:"noWorkPatterns,omitempty" mapstructure:"noWorkPatterns"`                 // Signs of no progress
+	TestOnlyPatterns       []string          `json:"testOnlyPatterns,omitempty" mapstructure:"testOnlyPatterns"`
+	ErrorKeywords          []string          `json:"errorKeywords,omitempty" mapstructure:"errorKeywords"`
+	StatusAliases          map[string]string `json:"statusAliases,omitempty" mapstructure:"statusAliases"` // Custom STATUS value -> COMPLETE, BLOCKED, AT_RISK, PAUSED, IN_PROGRESS
 }
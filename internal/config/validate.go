@@ -0,0 +1,380 @@
+package config
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"reflect"
+	"sort"
+	"strings"
+	"sync"
+	"text/template"
+
+	"github.com/spf13/viper"
+	"hermes/internal/secrets"
+)
+
+// Problem is an invalid option of a config file or of the configuration
+// they result in
+type Problem struct {
+	File    string // Config file, empty for the resulting configuration
+	Key     string // Option, e.g. parallel.maxWorkers
+	Message string
+}
+
+func (p Problem) String() string {
+	s := p.Message
+	if p.Key != "" {
+		s = p.Key + ": " + s
+	}
+	if p.File != "" {
+		s = p.File + ": " + s
+	}
+	return s
+}
+
+// enumOptions are the values options with a fixed set of choices accept,
+// besides the empty string for their default
+var enumOptions = map[string][]string{
+	"ai.planning":                 {"auto", "claude", "droid", "gemini", "opencode"},
+	"ai.coding":                   {"auto", "claude", "droid", "gemini", "opencode"},
+	"ai.sandbox.mode":             {"none", "sudo", "systemd-run", "container"},
+	"parallel.strategy":           {"branch-per-task", "worktree"},
+	"parallel.conflictResolution": {"ai-assisted", "manual", "auto-merge"},
+	"parallel.isolationBackend":   {"auto", "worktree", "copy"},
+	"parallel.mergeStrategy":      {"sequential", "parallel", "rebase"},
+	"parallel.failureStrategy":    {"continue", "fail-fast"},
+	"git.dirtyTree":               {"warn", "prompt", "stash", "abort"},
+	"git.signingFormat":           {"gpg", "ssh", "x509"},
+	"history.store":               {"json", "sqlite"},
+}
+
+// Validate checks the values of the configuration: choices, ranges and
+// numbers that must not be negative
+func (c *Config) Validate() []Problem {
+	var problems []Problem
+	add := func(key, format string, args ...interface{}) {
+		problems = append(problems, Problem{Key: key, Message: fmt.Sprintf(format, args...)})
+	}
+
+	values := make(map[string]reflect.Value)
+	walkOptions(reflect.ValueOf(c).Elem(), "", func(key string, v reflect.Value) {
+		values[key] = v
+		switch v.Kind() {
+		case reflect.Int, reflect.Int64:
+			if v.Int() < 0 {
+				add(key, "must not be negative, got %d", v.Int())
+			}
+		case reflect.Float64:
+			if v.Float() < 0 {
+				add(key, "must not be negative, got %g", v.Float())
+			}
+		}
+	})
+
+	keys := make([]string, 0, len(enumOptions))
+	for key := range enumOptions {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	for _, key := range keys {
+		value := values[key].String()
+		if value != "" && !contains(enumOptions[key], value) {
+			add(key, "unknown value %q, expected one of %s", value, strings.Join(enumOptions[key], ", "))
+		}
+	}
+
+	if c.Parallel.MaxWorkers < 1 {
+		add("parallel.maxWorkers", "must be at least 1, got %d", c.Parallel.MaxWorkers)
+	}
+	if c.Parallel.MinWorkers > c.Parallel.MaxWorkers {
+		add("parallel.minWorkers", "must not exceed parallel.maxWorkers (%d), got %d", c.Parallel.MaxWorkers, c.Parallel.MinWorkers)
+	}
+	if c.Parallel.MaxCPUPercent > 100 {
+		add("parallel.maxCPUPercent", "must be a percentage from 0 to 100, got %d", c.Parallel.MaxCPUPercent)
+	}
+	if c.TaskMode.CoverageThreshold > 100 {
+		add("taskMode.coverageThreshold", "must be a percentage from 0 to 100, got %g", c.TaskMode.CoverageThreshold)
+	}
+	if c.AI.Timeout == 0 {
+		add("ai.timeout", "must be at least 1 second")
+	}
+	if c.AI.PlanningTimeout == 0 {
+		add("ai.planningTimeout", "must be at least 1 second")
+	}
+	for effort, seconds := range c.AI.EffortTimeouts {
+		if seconds <= 0 {
+			add("ai.effortTimeouts", "timeout of %q must be at least 1 second, got %d", effort, seconds)
+		}
+	}
+	if c.AI.ProjectContext.Enabled && c.AI.ProjectContext.MaxDepth < 1 {
+		add("ai.projectContext.maxDepth", "must be at least 1, got %d", c.AI.ProjectContext.MaxDepth)
+	}
+	if c.AI.GitHistory < 0 {
+		add("ai.gitHistory", "must be 0 for none or a number of commits, got %d", c.AI.GitHistory)
+	}
+	if c.AI.MaxPromptTokens < 0 {
+		add("ai.maxPromptTokens", "must be 0 for no limit or a number of tokens, got %d", c.AI.MaxPromptTokens)
+	}
+	for tag, name := range c.AI.PromptTemplates {
+		if name == "" || strings.ContainsAny(name, `/\`) || strings.Contains(name, "..") {
+			add("ai.promptTemplates", "template of tag %q must be a name like \"migration\", got %q", tag, name)
+		}
+	}
+	statuses := []string{"COMPLETE", "BLOCKED", "AT_RISK", "PAUSED", "IN_PROGRESS"}
+	for alias, status := range c.Analyzer.StatusAliases {
+		if !contains(statuses, strings.ToUpper(status)) {
+			add("analyzer.statusAliases", "alias %q maps to unknown status %q, expected one of %s", alias, status, strings.Join(statuses, ", "))
+		}
+	}
+	names := make([]string, 0, len(c.Secrets))
+	for name := range c.Secrets {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		if _, _, err := secrets.ParseRef(c.Secrets[name]); err != nil {
+			add("secrets."+name, "%v", err)
+		}
+	}
+	for i, w := range c.Webhooks {
+		if w.URL == "" {
+			add(fmt.Sprintf("webhooks[%d].url", i), "must be set")
+		}
+	}
+	if c.Git.CommitTemplate != "" {
+		if err := checkCommitTemplate(c.Git.CommitTemplate); err != nil {
+			add("git.commitTemplate", "%v", err)
+		}
+	}
+	return problems
+}
+
+// checkCommitTemplate checks that a commit template is valid and keeps the
+// task ID in parentheses in the subject, which 'hermes rollback --task' finds
+// the commits of a task by
+func checkCommitTemplate(text string) error {
+	tmpl, err := template.New("commit").Parse(text)
+	if err != nil {
+		return fmt.Errorf("invalid template: %w", err)
+	}
+	var sb strings.Builder
+	err = tmpl.Execute(&sb, map[string]interface{}{
+		"Type": "feat", "ID": "T001", "Name": "Task", "FeatureID": "F001", "Description": "", "SuccessCriteria": []string{},
+	})
+	if err != nil {
+		return fmt.Errorf("invalid template: %w", err)
+	}
+	subject, _, _ := strings.Cut(strings.TrimSpace(sb.String()), "\n")
+	if !strings.Contains(subject, "(T001)") {
+		return fmt.Errorf("the subject must contain ({{.ID}}), otherwise 'hermes rollback --task' cannot find task commits")
+	}
+	return nil
+}
+
+// CheckFile checks a config file for syntax errors, unknown options,
+// including those of its profiles, values of the wrong type and a version
+// newer than CurrentVersion
+func CheckFile(path string) []Problem {
+	v := viper.New()
+	v.SetConfigFile(path)
+	if err := v.ReadInConfig(); err != nil {
+		return []Problem{{File: path, Message: fmt.Sprintf("cannot be read: %v", err)}}
+	}
+
+	var problems []Problem
+	if version := v.GetInt("version"); version > CurrentVersion() {
+		problems = append(problems, Problem{File: path, Key: "version", Message: fmt.Sprintf("file is for config version %d, this Hermes supports version %d; options added since may be ignored", version, CurrentVersion())})
+	}
+	known, maps := optionKeys()
+	keys := v.AllKeys()
+	sort.Strings(keys)
+	for _, key := range keys {
+		option := key
+		if strings.HasPrefix(key, "profiles.") {
+			parts := strings.SplitN(key, ".", 3)
+			if len(parts) < 3 {
+				problems = append(problems, Problem{File: path, Key: key, Message: "profile must be an object of options"})
+				continue
+			}
+			option = parts[2]
+		}
+		if _, ok := known[option]; ok || isMapKey(option, maps) {
+			continue
+		}
+		message := "unknown option"
+		if suggestion := closestKey(option, known); suggestion != "" {
+			message += fmt.Sprintf(", did you mean %s?", suggestion)
+		}
+		problems = append(problems, Problem{File: path, Key: key, Message: message})
+	}
+
+	// Decoding errors list one option per line: 'key' reason: detail
+	cfg := DefaultConfig()
+	if err := v.Unmarshal(cfg); err != nil {
+		for _, line := range strings.Split(err.Error(), "\n") {
+			line = strings.TrimPrefix(strings.TrimSpace(line), "* ")
+			if !strings.HasPrefix(line, "'") {
+				continue
+			}
+			key, reason, _ := strings.Cut(line[1:], "' ")
+			reason, _, _ = strings.Cut(reason, ": ")
+			problems = append(problems, Problem{File: path, Key: key, Message: fmt.Sprintf("%s, got %q", reason, fmt.Sprint(v.Get(key)))})
+		}
+	}
+	return problems
+}
+
+// Check returns the problems of the config files read for basePath and of
+// the configuration Load returns, including invalid environment variables
+func Check(basePath string) []Problem {
+	// Loading first migrates files of older versions. Files that cannot be
+	// loaded are reported by CheckFile.
+	cfg, files, _ := loadFiles(basePath)
+	applyProfile(cfg, files)
+	var problems []Problem
+	for _, path := range configPaths(basePath) {
+		problems = append(problems, CheckFile(path)...)
+	}
+	for _, err := range applyEnv(cfg, os.LookupEnv) {
+		problems = append(problems, Problem{File: "environment", Message: err.Error()})
+	}
+	return append(problems, cfg.Validate()...)
+}
+
+// configPaths returns the config files read for basePath, in load order
+func configPaths(basePath string) []string {
+	var paths []string
+	for _, dir := range append(GlobalDirs(), filepath.Join(basePath, ".hermes")) {
+		if path := FindFile(dir); path != "" {
+			paths = append(paths, path)
+		}
+	}
+	return paths
+}
+
+var (
+	warnedMu sync.Mutex
+	warned   = make(map[string]bool)
+)
+
+// warnProblems reports the problems of the config files and the resulting
+// configuration on stderr, each once per process since commands load the
+// configuration several times
+func warnProblems(basePath string, cfg *Config) {
+	var problems []Problem
+	for _, path := range configPaths(basePath) {
+		problems = append(problems, CheckFile(path)...)
+	}
+	problems = append(problems, cfg.Validate()...)
+	for _, p := range problems {
+		warnOnce("warning: config: " + p.String())
+	}
+}
+
+// warnOnce prints a message on stderr unless it was printed before
+func warnOnce(message string) {
+	warnedMu.Lock()
+	defer warnedMu.Unlock()
+	if !warned[message] {
+		warned[message] = true
+		fmt.Fprintln(os.Stderr, message)
+	}
+}
+
+// walkOptions calls fn with the path of every option of a config struct
+func walkOptions(v reflect.Value, prefix string, fn func(key string, v reflect.Value)) {
+	t := v.Type()
+	for i := 0; i < t.NumField(); i++ {
+		name := strings.Split(t.Field(i).Tag.Get("mapstructure"), ",")[0]
+		if name == "" || name == "-" {
+			continue
+		}
+		key := name
+		if prefix != "" {
+			key = prefix + "." + name
+		}
+		if field := v.Field(i); field.Kind() == reflect.Struct {
+			walkOptions(field, key, fn)
+		} else {
+			fn(key, field)
+		}
+	}
+}
+
+// optionKeys returns the options by their lower case path, as config files
+// report them, and the options holding maps with keys of their own
+func optionKeys() (map[string]string, []string) {
+	known := make(map[string]string)
+	var maps []string
+	walkOptions(reflect.ValueOf(DefaultConfig()).Elem(), "", func(key string, v reflect.Value) {
+		known[strings.ToLower(key)] = key
+		if v.Kind() == reflect.Map {
+			maps = append(maps, strings.ToLower(key))
+		}
+	})
+	return known, maps
+}
+
+func isMapKey(key string, maps []string) bool {
+	for _, m := range maps {
+		if strings.HasPrefix(key, m+".") {
+			return true
+		}
+	}
+	return false
+}
+
+// closestKey suggests the option meant by a misspelled or misplaced one: an
+// option in another section with the same name, or one a few edits away
+func closestKey(key string, known map[string]string) string {
+	name := key[strings.LastIndex(key, ".")+1:]
+	var sameName []string
+	best, bestDistance := "", 4
+	for lower, option := range known {
+		if lower[strings.LastIndex(lower, ".")+1:] == name {
+			sameName = append(sameName, option)
+		}
+		if d := editDistance(key, lower); d < bestDistance || (d == bestDistance && option < best) {
+			best, bestDistance = option, d
+		}
+	}
+	if len(sameName) > 1 {
+		sort.Strings(sameName)
+		return "one of " + strings.Join(sameName, ", ")
+	}
+	if len(sameName) == 1 {
+		return sameName[0]
+	}
+	return best
+}
+
+// editDistance returns the Levenshtein distance of two strings
+func editDistance(a, b string) int {
+	prev := make([]int, len(b)+1)
+	for j := range prev {
+		prev[j] = j
+	}
+	for i := 1; i <= len(a); i++ {
+		cur := make([]int, len(b)+1)
+		cur[0] = i
+		for j := 1; j <= len(b); j++ {
+			cost := 1
+			if a[i-1] == b[j-1] {
+				cost = 0
+			}
+			cur[j] = min(prev[j]+1, cur[j-1]+1, prev[j-1]+cost)
+		}
+		prev = cur
+	}
+	return prev[len(b)]
+}
+
+func contains(values []string, value string) bool {
+	for _, v := range values {
+		if v == value {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,55 @@
+package converter
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"hermes/internal/config"
+)
+
+// Lines of the file tree and conventions file in the project context, to keep
+// prompts of large projects small
+const (
+	contextTreeLines        = 300
+	contextConventionsLines = 200
+)
+
+// ProjectContext returns the project context section added to task prompts
+// when cfg is enabled: the project type, tech stack, conventions file and
+// file tree. It is empty when disabled or the project cannot be read.
+func ProjectContext(rootDir string, cfg config.ProjectContextConfig) string {
+	if !cfg.Enabled {
+		return ""
+	}
+	result, err := NewProjectAnalyzer(rootDir, cfg.MaxDepth, nil).Analyze()
+	if err != nil {
+		return ""
+	}
+
+	var sb strings.Builder
+	sb.WriteString("## Project Context\n\n")
+	sb.WriteString("Generated by Hermes from the current state of the repository, so you don't need to explore it first.\n\n")
+	fmt.Fprintf(&sb, "**Project:** %s (%s)\n", result.ProjectName, result.ProjectType)
+	if len(result.TechStack) > 0 {
+		// Detected from a map, sorted so prompts don't change between runs
+		stack := append([]string{}, result.TechStack...)
+		sort.Strings(stack)
+		fmt.Fprintf(&sb, "**Tech Stack:** %s\n", strings.Join(stack, ", "))
+	}
+
+	if cfg.Conventions != "" {
+		if content, err := os.ReadFile(filepath.Join(rootDir, cfg.Conventions)); err == nil && strings.TrimSpace(string(content)) != "" {
+			fmt.Fprintf(&sb, "\n### Conventions (%s)\n\n", filepath.ToSlash(cfg.Conventions))
+			sb.WriteString(truncateContent(strings.TrimSpace(string(content)), contextConventionsLines) + "\n")
+		}
+	}
+
+	sb.WriteString("\n### File Tree\n\n")
+	sb.WriteString("```\n")
+	sb.WriteString(truncateContent(strings.TrimRight(result.FileTree, "\n"), contextTreeLines) + "\n")
+	sb.WriteString("```\n")
+	return sb.String()
+}
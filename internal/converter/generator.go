@@ -78,7 +78,7 @@ func (g *Generator) Generate(ctx context.Context, opts GenerateOptions) (*Genera
 	result, err := ai.ExecuteWithRetry(ctx, g.provider, &ai.ExecuteOptions{
 		Prompt:       prompt,
 		WorkDir:      opts.RootDir,
-		Timeout:      g.config.AI.PrdTimeout,
+		Timeout:      g.config.AI.PlanningTimeout,
 		StreamOutput: g.config.AI.StreamOutput,
 	}, &ai.RetryConfig{
 		MaxRetries: g.config.AI.MaxRetries,
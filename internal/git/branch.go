@@ -107,7 +107,7 @@ func (g *Git) ForceDeleteBranch(name string) error {
 
 // MergeBranch merges a branch into the current branch
 func (g *Git) MergeBranch(branchName string) error {
-	_, err := g.run("merge", branchName, "--no-edit")
+	_, err := g.run(append([]string{"merge", branchName, "--no-edit"}, g.signArgs()...)...)
 	return err
 }
 
@@ -3,14 +3,30 @@ package git
 import (
 	"fmt"
 	"strings"
+
+	"hermes/internal/task"
 )
 
-// StageAll stages all changes
+// StageAll stages all changes except configured exclude patterns
 func (g *Git) StageAll() error {
-	_, err := g.run("add", "-A")
+	args := append([]string{"add", "-A", "--", "."}, ExcludePathspecs(g.stageExcludes)...)
+	_, err := g.run(args...)
 	return err
 }
 
+// ExcludePathspecs converts paths and patterns into git exclude pathspecs
+func ExcludePathspecs(patterns []string) []string {
+	var specs []string
+	for _, p := range patterns {
+		p = strings.TrimSpace(p)
+		if p == "" {
+			continue
+		}
+		specs = append(specs, ":(exclude)"+strings.TrimSuffix(p, "/"))
+	}
+	return specs
+}
+
 // StageFiles stages specific files
 func (g *Git) StageFiles(files ...string) error {
 	args := append([]string{"add"}, files...)
@@ -26,13 +42,40 @@ func (g *Git) Unstage() error {
 
 // Commit creates a commit with the given message
 func (g *Git) Commit(message string) error {
-	_, err := g.run("commit", "-m", message)
+	if err := g.CheckBranchPolicy(); err != nil {
+		return err
+	}
+	args := append(append([]string{"commit"}, g.signArgs()...), "-m", message)
+	_, err := g.run(args...)
+	return err
+}
+
+// CommitPaths stages and commits only the given paths, ignoring stage excludes.
+// It is a no-op when none of the paths have changes.
+func (g *Git) CommitPaths(message string, paths ...string) error {
+	args := append([]string{"add", "-f", "-A", "--"}, paths...)
+	if _, err := g.run(args...); err != nil {
+		return err
+	}
+	diffArgs := append([]string{"diff", "--cached", "--quiet", "--"}, paths...)
+	if _, err := g.run(diffArgs...); err == nil {
+		return nil
+	}
+	if err := g.CheckBranchPolicy(); err != nil {
+		return err
+	}
+	commitArgs := append(append([]string{"commit"}, g.signArgs()...), "-m", message, "--")
+	commitArgs = append(commitArgs, paths...)
+	_, err := g.run(commitArgs...)
 	return err
 }
 
-// CommitTask creates a commit for a task
+// CommitTask creates a commit for a task with the configured message format
 func (g *Git) CommitTask(taskID, taskName string) error {
-	message := fmt.Sprintf("feat(%s): %s", taskID, taskName)
+	message, err := g.TaskCommitMessage(&task.Task{ID: taskID, Name: taskName})
+	if err != nil {
+		return err
+	}
 	return g.Commit(message)
 }
 
@@ -57,9 +100,21 @@ func (g *Git) GetLastCommitShortHash() (string, error) {
 	return g.run("rev-parse", "--short", "HEAD")
 }
 
+// GetCommitsSince returns "hash subject" lines for commits reachable from HEAD but not from ref
+func (g *Git) GetCommitsSince(ref string) ([]string, error) {
+	output, err := g.run("log", "--format=%h %s", "--reverse", fmt.Sprintf("%s..HEAD", ref))
+	if err != nil {
+		return nil, err
+	}
+	if output == "" {
+		return nil, nil
+	}
+	return strings.Split(output, "\n"), nil
+}
+
 // AmendCommit amends the last commit with staged changes
 func (g *Git) AmendCommit() error {
-	_, err := g.run("commit", "--amend", "--no-edit")
+	_, err := g.run(append([]string{"commit", "--amend", "--no-edit"}, g.signArgs()...)...)
 	return err
 }
 
@@ -70,7 +125,8 @@ func (g *Git) GetLog(count int) (string, error) {
 
 // CreateTag creates a git tag
 func (g *Git) CreateTag(tag, message string) error {
-	_, err := g.run("tag", "-a", tag, "-m", message)
+	args := append(append([]string{"tag", "-a"}, g.tagSignArgs()...), tag, "-m", message)
+	_, err := g.run(args...)
 	return err
 }
 
@@ -111,9 +167,7 @@ func (g *Git) CreateFeatureTag(featureID, featureName, version string) error {
 	}
 
 	// Normalize version (add v prefix if missing)
-	if version[0] != 'v' {
-		version = "v" + version
-	}
+	version = FeatureTagName(version)
 
 	// Check if tag already exists
 	if g.TagExists(version) {
@@ -0,0 +1,102 @@
+package git
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// maxNotableFiles bounds how many new files a diffstat lists by name
+const maxNotableFiles = 5
+
+// DiffStat summarizes the size of a change
+type DiffStat struct {
+	Files      int      `json:"files"`
+	Insertions int      `json:"insertions"`
+	Deletions  int      `json:"deletions"`
+	NewFiles   []string `json:"newFiles,omitempty"`
+}
+
+// GetCommitDiffStat returns the diffstat of a single commit
+func (g *Git) GetCommitDiffStat(ref string) (*DiffStat, error) {
+	numstat, err := g.run("show", "--numstat", "--format=", ref)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get diffstat for %s: %w", ref, err)
+	}
+	added, err := g.run("show", "--diff-filter=A", "--name-only", "--format=", ref)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get new files for %s: %w", ref, err)
+	}
+	return parseDiffStat(numstat, added), nil
+}
+
+// GetRangeDiffStat returns the diffstat of the changes from one commit to another
+func (g *Git) GetRangeDiffStat(from, to string) (*DiffStat, error) {
+	numstat, err := g.run("diff", "--numstat", from, to)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get diffstat for %s..%s: %w", from, to, err)
+	}
+	added, err := g.run("diff", "--diff-filter=A", "--name-only", from, to)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get new files for %s..%s: %w", from, to, err)
+	}
+	return parseDiffStat(numstat, added), nil
+}
+
+// GetWorkingDiffStat returns the diffstat of uncommitted changes, counting
+// untracked files as new files
+func (g *Git) GetWorkingDiffStat() (*DiffStat, error) {
+	numstat, err := g.run("diff", "HEAD", "--numstat")
+	if err != nil {
+		return nil, fmt.Errorf("failed to get working tree diffstat: %w", err)
+	}
+	added, err := g.run("ls-files", "--others", "--exclude-standard")
+	if err != nil {
+		return nil, fmt.Errorf("failed to list untracked files: %w", err)
+	}
+	stat := parseDiffStat(numstat, added)
+	stat.Files += len(stat.NewFiles)
+	return stat, nil
+}
+
+// parseDiffStat builds a DiffStat from "git --numstat" and "--name-only" output
+func parseDiffStat(numstat, added string) *DiffStat {
+	stat := &DiffStat{}
+	for _, line := range strings.Split(numstat, "\n") {
+		fields := strings.Fields(line)
+		if len(fields) < 3 {
+			continue
+		}
+		stat.Files++
+		// Binary files report "-" for both counts
+		ins, _ := strconv.Atoi(fields[0])
+		del, _ := strconv.Atoi(fields[1])
+		stat.Insertions += ins
+		stat.Deletions += del
+	}
+	for _, file := range strings.Split(added, "\n") {
+		if file = strings.TrimSpace(file); file != "" {
+			stat.NewFiles = append(stat.NewFiles, file)
+		}
+	}
+	return stat
+}
+
+// String returns a compact summary such as "3 files, +120 -8, new: a.go, b.go"
+func (d *DiffStat) String() string {
+	files := "files"
+	if d.Files == 1 {
+		files = "file"
+	}
+	s := fmt.Sprintf("%d %s, +%d -%d", d.Files, files, d.Insertions, d.Deletions)
+	if len(d.NewFiles) > 0 {
+		names := d.NewFiles
+		more := ""
+		if len(names) > maxNotableFiles {
+			more = fmt.Sprintf(" (+%d more)", len(names)-maxNotableFiles)
+			names = names[:maxNotableFiles]
+		}
+		s += ", new: " + strings.Join(names, ", ") + more
+	}
+	return s
+}
@@ -1,14 +1,29 @@
 package git
 
 import (
+	"context"
 	"fmt"
+	"io"
+	"os"
 	"os/exec"
+	"path/filepath"
 	"strings"
+	"text/template"
+
+	"go.opentelemetry.io/otel/attribute"
+	"hermes/internal/tracing"
 )
 
 // Git provides git operations
 type Git struct {
-	workDir string
+	workDir       string
+	stageExcludes []string
+	traceCtx      context.Context
+	commandLog    io.Writer
+	policy        CommitPolicy
+	format        CommitFormat
+	message       *template.Template
+	signing       Signing
 }
 
 // New creates a new Git instance
@@ -16,11 +31,73 @@ func New(workDir string) *Git {
 	return &Git{workDir: workDir}
 }
 
+// WorkDir returns the directory git commands run in
+func (g *Git) WorkDir() string {
+	return g.workDir
+}
+
+// SetTraceContext sets the context whose span git commands are traced under
+func (g *Git) SetTraceContext(ctx context.Context) {
+	g.traceCtx = ctx
+}
+
+// SetCommandLog sets a writer receiving every git command run and its output,
+// or nil to stop logging them
+func (g *Git) SetCommandLog(w io.Writer) {
+	g.commandLog = w
+}
+
+// SetStageExcludes sets paths and patterns that StageAll never stages
+func (g *Git) SetStageExcludes(patterns ...string) {
+	g.stageExcludes = patterns
+}
+
+// EnsureAttribute adds "pattern attr" to .gitattributes if it is not already present
+func (g *Git) EnsureAttribute(pattern, attr string) error {
+	path := filepath.Join(g.workDir, ".gitattributes")
+	line := pattern + " " + attr
+
+	content, err := os.ReadFile(path)
+	if err != nil && !os.IsNotExist(err) {
+		return err
+	}
+	for _, existing := range strings.Split(string(content), "\n") {
+		if strings.TrimSpace(existing) == line {
+			return nil
+		}
+	}
+
+	text := string(content)
+	if text != "" && !strings.HasSuffix(text, "\n") {
+		text += "\n"
+	}
+	return os.WriteFile(path, []byte(text+line+"\n"), 0644)
+}
+
 // run executes a git command and returns the output
 func (g *Git) run(args ...string) (string, error) {
+	ctx := g.traceCtx
+	if ctx == nil {
+		ctx = context.Background()
+	}
+	_, span := tracing.Start(ctx, "git "+args[0], attribute.StringSlice("git.args", args))
+
 	cmd := exec.Command("git", args...)
 	cmd.Dir = g.workDir
+	if env := g.signEnv(); env != nil {
+		cmd.Env = append(os.Environ(), env...)
+	}
 	output, err := cmd.CombinedOutput()
+	tracing.End(span, err)
+	if g.commandLog != nil {
+		fmt.Fprintf(g.commandLog, "$ git %s\n", strings.Join(args, " "))
+		if out := strings.TrimSpace(string(output)); out != "" {
+			fmt.Fprintf(g.commandLog, "%s\n", out)
+		}
+		if err != nil {
+			fmt.Fprintf(g.commandLog, "(%v)\n", err)
+		}
+	}
 	return strings.TrimSpace(string(output)), err
 }
 
@@ -1,11 +1,15 @@
 package git
 
 import (
+	"context"
 	"os"
 	"os/exec"
 	"path/filepath"
 	"strings"
 	"testing"
+	"time"
+
+	"hermes/internal/task"
 )
 
 func setupTestRepo(t *testing.T) (string, func()) {
@@ -255,6 +259,78 @@ func TestCommitTask(t *testing.T) {
 	}
 }
 
+func TestTaskCommitMessage(t *testing.T) {
+	g := New(".")
+	fix := &task.Task{ID: "T002", Name: "Fix login redirect", SuccessCriteria: []string{"Redirects to /home"}}
+	if msg, _ := g.TaskCommitMessage(fix); msg != "fix(T002): Fix login redirect" {
+		t.Errorf("unexpected default message %q", msg)
+	}
+
+	err := g.SetCommitFormat(CommitFormat{
+		Template: "{{.Type}}: {{.Name}} ({{.ID}})\n\n{{range .SuccessCriteria}}- {{.}}\n{{end}}",
+		Types:    map[string]string{"login": "auth"},
+		CoAuthor: "Hermes <hermes@example.com>",
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	msg, err := g.TaskCommitMessage(fix)
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected := "fix: Fix login redirect (T002)\n\n- Redirects to /home\n\nCo-authored-by: Hermes <hermes@example.com>"
+	if msg != expected {
+		t.Errorf("expected %q, got %q", expected, msg)
+	}
+	if typ := CommitType(&task.Task{Name: "Add login form"}, map[string]string{"login": "auth"}); typ != "auth" {
+		t.Errorf("expected configured type auth, got %s", typ)
+	}
+	if typ := CommitType(&task.Task{Name: "Fix the install guide", Tags: []string{"Docs"}}, nil); typ != "docs" {
+		t.Errorf("expected the type of the task's tag, got %s", typ)
+	}
+	if err := g.SetCommitFormat(CommitFormat{Template: "{{.Type"}); err == nil {
+		t.Error("expected an invalid template to be rejected")
+	}
+}
+
+func TestSigningArgs(t *testing.T) {
+	g := New(".")
+	if g.signArgs() != nil || g.tagSignArgs() != nil || g.signEnv() != nil {
+		t.Error("expected no signing options by default")
+	}
+
+	g.SetSigning(Signing{Enabled: true})
+	if args := g.signArgs(); len(args) != 1 || args[0] != "--gpg-sign" {
+		t.Errorf("unexpected commit options %v", args)
+	}
+	if args := g.tagSignArgs(); len(args) != 1 || args[0] != "--sign" {
+		t.Errorf("unexpected tag options %v", args)
+	}
+
+	g.SetSigning(Signing{Enabled: true, Key: "~/.ssh/id_ed25519.pub", Format: "ssh"})
+	if args := g.signArgs(); args[0] != "--gpg-sign=~/.ssh/id_ed25519.pub" {
+		t.Errorf("unexpected commit options %v", args)
+	}
+	if args := g.tagSignArgs(); args[0] != "--local-user=~/.ssh/id_ed25519.pub" {
+		t.Errorf("unexpected tag options %v", args)
+	}
+	if env := g.signEnv(); len(env) != 3 || env[2] != "GIT_CONFIG_VALUE_0=ssh" {
+		t.Errorf("unexpected environment %v", env)
+	}
+
+	// Commands run outside of Git get the same options
+	cmd := exec.Command("git", "commit")
+	g.signing.Apply(cmd)
+	if len(cmd.Env) == 0 || cmd.Env[len(cmd.Env)-1] != "GIT_CONFIG_VALUE_0=ssh" {
+		t.Errorf("expected the signature format in the environment, got %v", cmd.Env)
+	}
+	cmd = exec.Command("git", "commit")
+	Signing{}.Apply(cmd)
+	if cmd.Env != nil {
+		t.Errorf("expected the inherited environment without signing, got %v", cmd.Env)
+	}
+}
+
 func TestBranchOperations(t *testing.T) {
 	repoDir, cleanup := setupTestRepo(t)
 	defer cleanup()
@@ -299,3 +375,561 @@ func TestListBranches(t *testing.T) {
 		t.Error("expected at least one branch")
 	}
 }
+
+func TestStageAllExcludes(t *testing.T) {
+	repoDir, cleanup := setupTestRepo(t)
+	defer cleanup()
+
+	g := New(repoDir)
+	g.SetStageExcludes(".hermes", "*.secret")
+
+	// Create files inside and outside the excluded paths
+	os.MkdirAll(filepath.Join(repoDir, ".hermes", "tasks"), 0755)
+	os.WriteFile(filepath.Join(repoDir, ".hermes", "tasks", "001-test.md"), []byte("# Feature"), 0644)
+	os.WriteFile(filepath.Join(repoDir, "keys.secret"), []byte("key"), 0644)
+	os.WriteFile(filepath.Join(repoDir, "main.go"), []byte("package main"), 0644)
+
+	if err := g.StageAll(); err != nil {
+		t.Fatal(err)
+	}
+
+	staged, _ := g.run("diff", "--cached", "--name-only")
+	if !strings.Contains(staged, "main.go") {
+		t.Errorf("expected main.go to be staged, got %q", staged)
+	}
+	if strings.Contains(staged, ".hermes") {
+		t.Errorf("expected .hermes to be excluded, got %q", staged)
+	}
+	if strings.Contains(staged, "keys.secret") {
+		t.Errorf("expected keys.secret to be excluded, got %q", staged)
+	}
+}
+
+func TestCommitPaths(t *testing.T) {
+	repoDir, cleanup := setupTestRepo(t)
+	defer cleanup()
+
+	g := New(repoDir)
+	g.SetStageExcludes(".hermes")
+
+	os.MkdirAll(filepath.Join(repoDir, ".hermes", "tasks"), 0755)
+	os.WriteFile(filepath.Join(repoDir, ".hermes", "tasks", "001-test.md"), []byte("# Feature"), 0644)
+	os.WriteFile(filepath.Join(repoDir, "main.go"), []byte("package main"), 0644)
+
+	if err := g.CommitPaths("chore(tasks): update task status", filepath.Join(".hermes", "tasks")); err != nil {
+		t.Fatal(err)
+	}
+
+	files, _ := g.run("show", "--name-only", "--format=", "HEAD")
+	if !strings.Contains(files, ".hermes/tasks/001-test.md") {
+		t.Errorf("expected task file in commit, got %q", files)
+	}
+	if strings.Contains(files, "main.go") {
+		t.Errorf("expected main.go to stay out of the task commit, got %q", files)
+	}
+
+	// Nothing changed: must not fail or create an empty commit
+	head, _ := g.GetLastCommitHash()
+	if err := g.CommitPaths("chore(tasks): update task status", filepath.Join(".hermes", "tasks")); err != nil {
+		t.Fatal(err)
+	}
+	if after, _ := g.GetLastCommitHash(); after != head {
+		t.Error("expected no commit when task files are unchanged")
+	}
+}
+
+func TestCommitPolicy(t *testing.T) {
+	repoDir, cleanup := setupTestRepo(t)
+	defer cleanup()
+
+	g := New(repoDir)
+	main, _ := g.GetCurrentBranch()
+	g.SetCommitPolicy(CommitPolicy{ProtectedBranches: []string{main}, ScopedCommits: true})
+
+	os.WriteFile(filepath.Join(repoDir, "main.go"), []byte("package main"), 0644)
+	g.StageAll()
+	if err := g.CommitTask("T001", "Add main"); err == nil || !strings.Contains(err.Error(), "protected") {
+		t.Fatalf("expected the protected branch to be refused, got %v", err)
+	}
+
+	g.CreateBranch("feature/F001-test")
+	os.MkdirAll(filepath.Join(repoDir, "internal"), 0755)
+	os.WriteFile(filepath.Join(repoDir, "internal", "a.go"), []byte("package internal"), 0644)
+	g.StageAll()
+	err := g.CommitTaskInScope(&task.Task{ID: "T001", Name: "Add main", FilesToTouch: []string{"internal/"}})
+	if err == nil || !strings.Contains(err.Error(), "main.go") {
+		t.Fatalf("expected main.go to be out of scope, got %v", err)
+	}
+	if g.HasStagedChanges() {
+		t.Error("expected the refused changes to be unstaged")
+	}
+
+	g.StageAll()
+	if err := g.CommitTaskInScope(&task.Task{ID: "T001", Name: "Add main", FilesToTouch: []string{"internal/", "*.go"}}); err != nil {
+		t.Fatalf("expected the commit to be in scope: %v", err)
+	}
+
+	// Staging only task files leaves the rest for the user
+	g.SetCommitPolicy(CommitPolicy{StageTaskFilesOnly: true})
+	os.WriteFile(filepath.Join(repoDir, "internal", "b.go"), []byte("package internal"), 0644)
+	os.WriteFile(filepath.Join(repoDir, "notes.txt"), []byte("scratch"), 0644)
+	outside, err := g.StageTask([]string{"internal"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(outside) != 1 || outside[0] != "notes.txt" {
+		t.Errorf("expected notes.txt to be left unstaged, got %v", outside)
+	}
+	if staged, _ := g.run("diff", "--cached", "--name-only"); staged != "internal/b.go" {
+		t.Errorf("expected only internal/b.go staged, got %q", staged)
+	}
+
+	g.SetCommitPolicy(CommitPolicy{RequireHermesBranch: true})
+	g.CreateBranch("experiment")
+	if err := g.CheckBranchPolicy(); err == nil {
+		t.Error("expected a non-hermes branch to be refused")
+	}
+}
+
+func TestStageTaskBacktickedFiles(t *testing.T) {
+	repoDir, cleanup := setupTestRepo(t)
+	defer cleanup()
+
+	g := New(repoDir)
+	g.SetCommitPolicy(CommitPolicy{StageTaskFilesOnly: true})
+	os.MkdirAll(filepath.Join(repoDir, "internal"), 0755)
+	os.WriteFile(filepath.Join(repoDir, "internal", "a.go"), []byte("package internal"), 0644)
+	os.WriteFile(filepath.Join(repoDir, "notes.txt"), []byte("scratch"), 0644)
+
+	outside, err := g.StageTask([]string{"`internal/a.go`", "``"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(outside) != 1 || outside[0] != "notes.txt" {
+		t.Errorf("expected notes.txt to be left unstaged, got %v", outside)
+	}
+	if staged, _ := g.run("diff", "--cached", "--name-only"); staged != "internal/a.go" {
+		t.Errorf("expected internal/a.go staged, got %q", staged)
+	}
+
+	// Entries that are empty without their backticks don't limit staging
+	if outside, err := g.StageTask([]string{"``"}); err != nil || len(outside) != 0 {
+		t.Errorf("expected everything staged, got %v (%v)", outside, err)
+	}
+}
+
+func TestInScope(t *testing.T) {
+	files := []string{"`internal/auth/`", " `cmd/main.go` ", "`*.md`"}
+	for file, want := range map[string]bool{
+		"internal/auth/login.go": true,
+		"cmd/main.go":            true,
+		"README.md":              true,
+		"internal/api/server.go": false,
+	} {
+		if got := InScope(file, files); got != want {
+			t.Errorf("InScope(%q) = %v, want %v", file, got, want)
+		}
+	}
+}
+
+func TestEnsureAttribute(t *testing.T) {
+	repoDir, cleanup := setupTestRepo(t)
+	defer cleanup()
+
+	g := New(repoDir)
+	for i := 0; i < 2; i++ {
+		if err := g.EnsureAttribute(".hermes/tasks/status.log", "merge=union"); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	content, _ := os.ReadFile(filepath.Join(repoDir, ".gitattributes"))
+	if strings.Count(string(content), "merge=union") != 1 {
+		t.Errorf("expected attribute to be written once, got %q", content)
+	}
+}
+
+func TestGetCommitDiffStat(t *testing.T) {
+	tmpDir, cleanup := setupTestRepo(t)
+	defer cleanup()
+
+	os.WriteFile(filepath.Join(tmpDir, "README.md"), []byte("# Test\n\nMore docs\n"), 0644)
+	os.WriteFile(filepath.Join(tmpDir, "main.go"), []byte("package main\n\nfunc main() {}\n"), 0644)
+
+	g := New(tmpDir)
+	if err := g.StageAll(); err != nil {
+		t.Fatal(err)
+	}
+	if err := g.Commit("feat: add main"); err != nil {
+		t.Fatal(err)
+	}
+
+	stat, err := g.GetCommitDiffStat("HEAD")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if stat.Files != 2 || stat.Insertions != 6 || stat.Deletions != 1 {
+		t.Errorf("unexpected diffstat: %+v", stat)
+	}
+	if len(stat.NewFiles) != 1 || stat.NewFiles[0] != "main.go" {
+		t.Errorf("expected main.go as new file, got %v", stat.NewFiles)
+	}
+	if got := stat.String(); got != "2 files, +6 -1, new: main.go" {
+		t.Errorf("unexpected summary %q", got)
+	}
+
+	// A range covers every commit in it
+	os.WriteFile(filepath.Join(tmpDir, "util.go"), []byte("package main\n"), 0644)
+	g.StageAll()
+	g.Commit("feat: add util")
+	stat, err = g.GetRangeDiffStat("HEAD~2", "HEAD")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if stat.Files != 3 || len(stat.NewFiles) != 2 {
+		t.Errorf("unexpected range diffstat: %+v", stat)
+	}
+}
+
+func TestDiffStatStringTruncatesNewFiles(t *testing.T) {
+	stat := parseDiffStat("1\t0\ta\n1\t0\tb\n-\t-\tlogo.png", "a\nb\nc\nd\ne\nf\ng")
+	if stat.Files != 3 || stat.Insertions != 2 {
+		t.Errorf("unexpected diffstat: %+v", stat)
+	}
+	if got := stat.String(); !strings.HasSuffix(got, "new: a, b, c, d, e (+2 more)") {
+		t.Errorf("unexpected summary %q", got)
+	}
+}
+
+func TestFindAndRevertTaskCommits(t *testing.T) {
+	tmpDir, cleanup := setupTestRepo(t)
+	defer cleanup()
+
+	g := New(tmpDir)
+	main, _ := g.GetCurrentBranch()
+	for _, c := range []struct{ file, id string }{{"a.go", "T001"}, {"b.go", "T002"}} {
+		os.WriteFile(filepath.Join(tmpDir, c.file), []byte("package main\n"), 0644)
+		g.StageAll()
+		if err := g.CommitTask(c.id, "Add "+c.file); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	// Parallel runs merge task branches
+	g.CreateBranch("task/T003")
+	os.WriteFile(filepath.Join(tmpDir, "c.go"), []byte("package main\n"), 0644)
+	g.StageAll()
+	g.CommitTask("T003", "Add c.go")
+	g.CheckoutBranch(main)
+	if _, err := g.run("merge", "--no-ff", "task/T003", "-m", "Merge branch 'task/T003' (task T003)"); err != nil {
+		t.Fatal(err)
+	}
+
+	commits, err := g.FindTaskCommits("T001")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(commits) != 1 || commits[0].IsMerge || commits[0].Subject != "feat(T001): Add a.go" {
+		t.Fatalf("unexpected T001 commits: %+v", commits)
+	}
+	if err := g.RevertCommit(commits[0]); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := os.Stat(filepath.Join(tmpDir, "a.go")); !os.IsNotExist(err) {
+		t.Error("expected a.go to be removed by the revert")
+	}
+
+	merges, _ := g.FindTaskCommits("T003")
+	if len(merges) != 1 || !merges[0].IsMerge {
+		t.Fatalf("expected only the T003 merge commit on the first-parent history, got %+v", merges)
+	}
+	if err := g.RevertCommit(merges[0]); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := os.Stat(filepath.Join(tmpDir, "c.go")); !os.IsNotExist(err) {
+		t.Error("expected c.go to be removed by reverting the merge")
+	}
+	if _, err := os.Stat(filepath.Join(tmpDir, "b.go")); err != nil {
+		t.Error("expected b.go to be kept")
+	}
+
+	// The rebase merge strategy fast-forwards the worktree commits
+	os.WriteFile(filepath.Join(tmpDir, "d.go"), []byte("package main\n"), 0644)
+	g.StageAll()
+	g.Commit("Complete task T004: Add d.go")
+	if rebased, _ := g.FindTaskCommits("T004"); len(rebased) != 1 || rebased[0].IsMerge {
+		t.Errorf("expected the rebased T004 commit, got %+v", rebased)
+	}
+	if others, _ := g.FindTaskCommits("T00"); len(others) != 0 {
+		t.Errorf("expected no commits for a task ID prefix, got %+v", others)
+	}
+}
+
+func TestTaskHistory(t *testing.T) {
+	tmpDir, cleanup := setupTestRepo(t)
+	defer cleanup()
+
+	g := New(tmpDir)
+	if history := g.TaskHistory(5); history != "" {
+		t.Errorf("expected no history without task commits, got %q", history)
+	}
+
+	main, _ := g.GetCurrentBranch()
+	os.WriteFile(filepath.Join(tmpDir, "a.go"), []byte("package main\n"), 0644)
+	g.StageAll()
+	g.CommitTask("T001", "Add a.go")
+	g.CreateBranch("task/T002")
+	os.WriteFile(filepath.Join(tmpDir, "b.go"), []byte("package main\n\nfunc b() {}\n"), 0644)
+	g.StageAll()
+	g.CommitTask("T002", "Add b.go")
+	g.CheckoutBranch(main)
+	if _, err := g.run("merge", "--no-ff", "task/T002", "-m", "Merge branch 'task/T002' (task T002)"); err != nil {
+		t.Fatal(err)
+	}
+	os.WriteFile(filepath.Join(tmpDir, "notes.txt"), []byte("notes\n"), 0644)
+	g.StageAll()
+	g.Commit("Update notes")
+
+	history := g.TaskHistory(5)
+	merge := strings.Index(history, "Merge branch 'task/T002' (task T002) (1 file, +3 -0, new: b.go)")
+	commit := strings.Index(history, "feat(T001): Add a.go (1 file, +1 -0, new: a.go)")
+	if merge < 0 || commit < merge {
+		t.Errorf("expected the task commits newest first with their diffstats:\n%s", history)
+	}
+	if strings.Contains(history, "Update notes") {
+		t.Errorf("expected only task commits:\n%s", history)
+	}
+	if commits, _ := g.RecentTaskCommits(1); len(commits) != 1 || !commits[0].IsMerge {
+		t.Errorf("expected only the newest task commit, got %+v", commits)
+	}
+}
+
+func TestFindCommitsTouching(t *testing.T) {
+	tmpDir, cleanup := setupTestRepo(t)
+	defer cleanup()
+
+	g := New(tmpDir)
+	main, _ := g.GetCurrentBranch()
+	os.WriteFile(filepath.Join(tmpDir, "a.go"), []byte("package main\n"), 0644)
+	g.StageAll()
+	g.CommitTask("T001", "Add a.go")
+	os.WriteFile(filepath.Join(tmpDir, "b.go"), []byte("package main\n"), 0644)
+	g.StageAll()
+	g.CommitTask("T002", "Add b.go")
+
+	// A later task merged from its branch changes a.go again
+	g.CreateBranch("task/T003")
+	os.WriteFile(filepath.Join(tmpDir, "a.go"), []byte("package main\n\nfunc A() {}\n"), 0644)
+	g.StageAll()
+	g.CommitTask("T003", "Extend a.go")
+	g.CheckoutBranch(main)
+	if _, err := g.run("merge", "--no-ff", "task/T003", "-m", "Merge branch 'task/T003' (task T003)"); err != nil {
+		t.Fatal(err)
+	}
+
+	commits, _ := g.FindTaskCommits("T001")
+	if len(commits) != 1 {
+		t.Fatalf("unexpected T001 commits: %+v", commits)
+	}
+	files, err := g.ChangedFiles(commits[0])
+	if err != nil || len(files) != 1 || files[0] != "a.go" {
+		t.Fatalf("expected a.go, got %v (%v)", files, err)
+	}
+	merges, _ := g.FindTaskCommits("T003")
+	if files, _ := g.ChangedFiles(merges[0]); len(files) != 1 || files[0] != "a.go" {
+		t.Errorf("expected the merge to change a.go, got %v", files)
+	}
+
+	later, err := g.FindCommitsTouching(commits[0].Hash, files)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(later) != 1 || !later[0].IsMerge || !strings.Contains(later[0].Subject, "T003") {
+		t.Errorf("expected only the T003 merge to touch a.go later, got %+v", later)
+	}
+}
+
+func TestPushHead(t *testing.T) {
+	repoDir, cleanup := setupTestRepo(t)
+	defer cleanup()
+	remoteDir := t.TempDir()
+	if err := exec.Command("git", "init", "--bare", remoteDir).Run(); err != nil {
+		t.Fatal(err)
+	}
+
+	g := New(repoDir)
+	g.run("remote", "add", "origin", remoteDir)
+	os.WriteFile(filepath.Join(repoDir, "a.go"), []byte("package main\n"), 0644)
+	g.StageAll()
+	g.CommitTask("T001", "Add a.go")
+	g.CreateFeatureTag("F001", "Test", "1.0.0")
+	if err := g.PushHead(context.Background(), "origin", 0); err != nil {
+		t.Fatal(err)
+	}
+
+	remote := New(remoteDir)
+	branch, _ := g.GetCurrentBranch()
+	head, _ := g.GetLastCommitHash()
+	if pushed, _ := remote.run("rev-parse", branch); pushed != head {
+		t.Errorf("expected %s on the remote, got %q", head, pushed)
+	}
+	if !remote.TagExists("v1.0.0") {
+		t.Error("expected the feature tag to be pushed")
+	}
+
+	defer func(delay time.Duration) { PushRetryDelay = delay }(PushRetryDelay)
+	PushRetryDelay = time.Millisecond
+	if err := g.PushHead(context.Background(), "missing", 2); err == nil {
+		t.Error("expected pushing to a missing remote to fail")
+	}
+
+	// Retries stop waiting once the run is cancelled
+	PushRetryDelay = time.Hour
+	ctx, cancel := context.WithCancel(context.Background())
+	time.AfterFunc(50*time.Millisecond, cancel)
+	start := time.Now()
+	if err := g.PushHead(ctx, "missing", 2); err == nil || time.Since(start) > 10*time.Second {
+		t.Errorf("expected the push to fail without waiting for the retry, got %v after %v", err, time.Since(start))
+	}
+}
+
+func TestStashDirtyFiles(t *testing.T) {
+	repoDir, cleanup := setupTestRepo(t)
+	defer cleanup()
+
+	g := New(repoDir)
+	g.SetStageExcludes(".hermes")
+	os.WriteFile(filepath.Join(repoDir, "README.md"), []byte("# Edited\n"), 0644)
+	os.WriteFile(filepath.Join(repoDir, "notes.txt"), []byte("scratch\n"), 0644)
+	os.MkdirAll(filepath.Join(repoDir, ".hermes"), 0755)
+	os.WriteFile(filepath.Join(repoDir, ".hermes", "state.json"), []byte("{}"), 0644)
+
+	files, err := g.DirtyFiles()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if strings.Join(files, ",") != "README.md,notes.txt" {
+		t.Fatalf("expected README.md and notes.txt, got %v", files)
+	}
+
+	hash, err := g.Stash("hermes: changes before run")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if files, _ := g.DirtyFiles(); len(files) != 0 {
+		t.Errorf("expected a clean tree after stashing, got %v", files)
+	}
+	if _, err := os.Stat(filepath.Join(repoDir, ".hermes", "state.json")); err != nil {
+		t.Error("expected Hermes state to stay in place")
+	}
+
+	if err := g.RestoreStash(hash); err != nil {
+		t.Fatal(err)
+	}
+	if data, _ := os.ReadFile(filepath.Join(repoDir, "notes.txt")); string(data) != "scratch\n" {
+		t.Errorf("expected notes.txt to be restored, got %q", data)
+	}
+	if list, _ := g.run("stash", "list"); list != "" {
+		t.Errorf("expected the stash to be dropped, got %q", list)
+	}
+}
+
+func TestCreateFeatureRelease(t *testing.T) {
+	repoDir, cleanup := setupTestRepo(t)
+	defer cleanup()
+
+	g := New(repoDir)
+	g.CreateTag("v0.1.0", "Release v0.1.0")
+	os.WriteFile(filepath.Join(repoDir, "orders.go"), []byte("package orders\n"), 0644)
+	g.StageAll()
+	g.Commit("feat(T001): List orders")
+
+	feature := &task.Feature{ID: "F001", Name: "Orders", TargetVersion: "0.2.0", Tasks: []task.Task{
+		{ID: "T001", Name: "List orders", Status: task.StatusCompleted},
+		{ID: "T002", Name: "Cancel orders", Status: task.StatusNotStarted},
+	}}
+	if committed, err := g.TasksCommittedSince("v0.1.0", []string{"T001", "T002"}); err != nil || !committed["T001"] || committed["T002"] {
+		t.Errorf("expected only T001 to be committed since v0.1.0, got %v, %v", committed, err)
+	}
+
+	var prompt string
+	release := &Release{
+		Changelog: "CHANGELOG.md",
+		Summarize: func(p string) (string, error) {
+			prompt = p
+			return "### Added\n\n- Orders can be listed\n", nil
+		},
+	}
+	if err := g.CreateFeatureRelease(feature, release); err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(prompt, "feat(T001): List orders") || strings.Contains(prompt, "Initial commit") || strings.Contains(prompt, "Cancel orders") {
+		t.Errorf("expected the prompt to list completed tasks and commits since v0.1.0, got:\n%s", prompt)
+	}
+
+	message, _ := g.run("tag", "-l", "--format=%(contents)", "v0.2.0")
+	if !strings.HasPrefix(message, "Release v0.2.0: F001 - Orders") || !strings.Contains(message, "- Orders can be listed") {
+		t.Errorf("unexpected tag message %q", message)
+	}
+	changelog, _ := os.ReadFile(filepath.Join(repoDir, "CHANGELOG.md"))
+	if !strings.HasPrefix(string(changelog), "# Changelog\n\n## v0.2.0 - ") {
+		t.Errorf("unexpected changelog %q", changelog)
+	}
+	if subject, _ := g.run("log", "-1", "--format=%s", "v0.2.0"); subject != "docs: update changelog for v0.2.0" {
+		t.Errorf("expected the tag to include the changelog commit, got %q", subject)
+	}
+
+	updated := PrependChangelog("# Changelog\n\nAll notable changes.\n\n## v0.2.0 - 2026-01-01\n\n- Old\n", "v0.3.0", "2026-02-01", "- New")
+	if updated != "# Changelog\n\nAll notable changes.\n\n## v0.3.0 - 2026-02-01\n\n- New\n\n## v0.2.0 - 2026-01-01\n\n- Old\n" {
+		t.Errorf("expected the entry above the previous ones, got %q", updated)
+	}
+	if notes := BuildReleaseNotes(feature); notes != "### F001: Orders\n\n- List orders" {
+		t.Errorf("unexpected fallback notes %q", notes)
+	}
+}
+
+func TestWorktreesAndMergedBranches(t *testing.T) {
+	tmpDir, cleanup := setupTestRepo(t)
+	defer cleanup()
+
+	g := New(tmpDir)
+	main, _ := g.GetCurrentBranch()
+	g.CreateBranch("task/T001")
+	g.CheckoutBranch(main)
+	g.CreateBranch("task/T002")
+	os.WriteFile(filepath.Join(tmpDir, "b.go"), []byte("package main\n"), 0644)
+	g.StageAll()
+	g.CommitTask("T002", "Add b.go")
+	g.CheckoutBranch(main)
+
+	wtPath := filepath.Join(tmpDir, ".hermes", "worktrees", "wt-T003")
+	if _, err := g.run("worktree", "add", "-b", "task/T003", wtPath); err != nil {
+		t.Fatal(err)
+	}
+	worktrees, err := g.ListWorktrees()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(worktrees) != 1 || worktrees[0].Branch != "task/T003" {
+		t.Fatalf("expected the T003 worktree, got %+v", worktrees)
+	}
+
+	// T002 has an unmerged commit; the current branch is never listed
+	branches, err := g.ListMergedBranches("task/*")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(branches) != 2 || branches[0].Name != "task/T001" || branches[1].Name != "task/T003" {
+		t.Errorf("expected task/T001 and task/T003, got %+v", branches)
+	}
+	if branches[0].CommitTime.IsZero() {
+		t.Error("expected the commit time to be set")
+	}
+
+	if err := g.RemoveWorktree(wtPath); err != nil {
+		t.Fatal(err)
+	}
+	if worktrees, _ := g.ListWorktrees(); len(worktrees) != 0 {
+		t.Errorf("expected no worktrees after removal, got %+v", worktrees)
+	}
+}
@@ -0,0 +1,63 @@
+package git
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+// taskSubjectRegex matches the subjects of task commits, like
+// subjectMentionsTask does for one task
+var taskSubjectRegex = regexp.MustCompile(`\((?:task )?T\d+\)|^Complete task T\d+:`)
+
+// RecentTaskCommits returns the last count task commits on the current
+// branch, newest first
+func (g *Git) RecentTaskCommits(count int) ([]TaskCommit, error) {
+	output, err := g.run("log", "--first-parent", "--format=%H%x00%P%x00%s")
+	if err != nil {
+		return nil, err
+	}
+
+	var commits []TaskCommit
+	for _, line := range strings.Split(output, "\n") {
+		if len(commits) >= count {
+			break
+		}
+		parts := strings.SplitN(line, "\x00", 3)
+		if len(parts) < 3 || !taskSubjectRegex.MatchString(parts[2]) {
+			continue
+		}
+		commits = append(commits, TaskCommit{
+			Hash:    parts[0],
+			Subject: parts[2],
+			IsMerge: len(strings.Fields(parts[1])) > 1,
+		})
+	}
+	return commits, nil
+}
+
+// TaskHistory returns a prompt section listing the last count task commits
+// with their diffstats, so the AI knows what earlier tasks already changed.
+// It is "" when there are none or the history cannot be read.
+func (g *Git) TaskHistory(count int) string {
+	if count <= 0 {
+		return ""
+	}
+	commits, err := g.RecentTaskCommits(count)
+	if err != nil || len(commits) == 0 {
+		return ""
+	}
+
+	var sb strings.Builder
+	sb.WriteString("## Recent Task Commits\n\n")
+	sb.WriteString("What earlier tasks already changed, newest first. Build on it rather than redoing or undoing it.\n\n")
+	for _, c := range commits {
+		sb.WriteString(fmt.Sprintf("- %s %s", c.Hash[:8], c.Subject))
+		// Merges are compared with their first parent, the branch before the task
+		if stat, err := g.GetRangeDiffStat(c.Hash+"^1", c.Hash); err == nil {
+			sb.WriteString(" (" + stat.String() + ")")
+		}
+		sb.WriteString("\n")
+	}
+	return sb.String()
+}
@@ -0,0 +1,127 @@
+package git
+
+import (
+	"fmt"
+	"strings"
+	"text/template"
+	"unicode"
+
+	"hermes/internal/config"
+	"hermes/internal/task"
+)
+
+// DefaultCommitTemplate is the message of task commits when no template is
+// configured. Keep the task ID in parentheses so 'hermes rollback --task'
+// can find the commits.
+const DefaultCommitTemplate = "{{.Type}}({{.ID}}): {{.Name}}"
+
+// DefaultCommitTypes maps task tags and words in task names to conventional
+// commit types. Tasks matching none of them are feat.
+var DefaultCommitTypes = map[string]string{
+	"fix":           "fix",
+	"bug":           "fix",
+	"bugfix":        "fix",
+	"test":          "test",
+	"tests":         "test",
+	"doc":           "docs",
+	"docs":          "docs",
+	"document":      "docs",
+	"documentation": "docs",
+	"readme":        "docs",
+	"refactor":      "refactor",
+	"perf":          "perf",
+	"performance":   "perf",
+	"optimize":      "perf",
+	"ci":            "ci",
+	"chore":         "chore",
+	"cleanup":       "chore",
+}
+
+// CommitFormat configures the messages of task commits
+type CommitFormat struct {
+	Template string            // text/template of the message, DefaultCommitTemplate when empty
+	Types    map[string]string // Task tags and words in task names -> commit type, added to DefaultCommitTypes
+	CoAuthor string            // Added as a Co-authored-by trailer, e.g. "Hermes <hermes@example.com>"
+}
+
+// CommitMessageData is what commit message templates are executed with
+type CommitMessageData struct {
+	Type            string
+	ID              string
+	Name            string
+	FeatureID       string
+	Description     string
+	SuccessCriteria []string
+}
+
+// NewCommitFormat returns the task commit message format of the git
+// configuration
+func NewCommitFormat(cfg config.GitConfig) CommitFormat {
+	return CommitFormat{
+		Template: cfg.CommitTemplate,
+		Types:    cfg.CommitTypes,
+		CoAuthor: cfg.CoAuthor,
+	}
+}
+
+// SetCommitFormat sets how task commit messages are written
+func (g *Git) SetCommitFormat(format CommitFormat) error {
+	text := format.Template
+	if text == "" {
+		text = DefaultCommitTemplate
+	}
+	tmpl, err := template.New("commit").Parse(text)
+	if err != nil {
+		return fmt.Errorf("invalid commit template: %w", err)
+	}
+	g.format = format
+	g.message = tmpl
+	return nil
+}
+
+// TaskCommitMessage returns the commit message of a task
+func (g *Git) TaskCommitMessage(t *task.Task) (string, error) {
+	tmpl := g.message
+	if tmpl == nil {
+		tmpl = template.Must(template.New("commit").Parse(DefaultCommitTemplate))
+	}
+	var sb strings.Builder
+	err := tmpl.Execute(&sb, CommitMessageData{
+		Type:            CommitType(t, g.format.Types),
+		ID:              t.ID,
+		Name:            t.Name,
+		FeatureID:       t.FeatureID,
+		Description:     t.Description,
+		SuccessCriteria: t.SuccessCriteria,
+	})
+	if err != nil {
+		return "", fmt.Errorf("invalid commit template: %w", err)
+	}
+	message := strings.TrimSpace(sb.String())
+	if g.format.CoAuthor != "" {
+		message += "\n\nCo-authored-by: " + g.format.CoAuthor
+	}
+	return message, nil
+}
+
+// CommitType returns the conventional commit type of a task from the first of
+// its tags, or else of the words of its name, found in types or
+// DefaultCommitTypes
+func CommitType(t *task.Task, types map[string]string) string {
+	var words []string
+	for _, tag := range t.Tags {
+		words = append(words, strings.ToLower(tag))
+	}
+	words = append(words, strings.FieldsFunc(strings.ToLower(t.Name), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	})...)
+	for _, word := range words {
+		if t, ok := types[word]; ok {
+			return t
+		}
+		if t, ok := DefaultCommitTypes[word]; ok {
+			return t
+		}
+	}
+	return "feat"
+}
@@ -5,6 +5,8 @@ import (
 	"os"
 	"path/filepath"
 	"strings"
+
+	"hermes/internal/task"
 )
 
 // ParallelBranchManager manages branches for parallel task execution
@@ -69,7 +71,7 @@ func (m *ParallelBranchManager) CreateWorktree(taskID string) (string, error) {
 	}
 
 	// Create worktree path
-	worktreePath := filepath.Join(os.TempDir(), fmt.Sprintf("wt-%s", taskID))
+	worktreePath := filepath.Join(os.TempDir(), fmt.Sprintf("wt-%s", task.FileSafeID(taskID)))
 
 	// Remove existing worktree if present
 	if _, err := os.Stat(worktreePath); err == nil {
@@ -127,7 +129,8 @@ func (m *ParallelBranchManager) MergeBranch(taskID string) error {
 	}
 
 	// Merge task branch
-	_, err = m.git.run("merge", branchName, "--no-ff", "-m", fmt.Sprintf("Merge task %s", taskID))
+	args := append([]string{"merge", branchName, "--no-ff"}, m.git.signArgs()...)
+	_, err = m.git.run(append(args, "-m", fmt.Sprintf("Merge task %s", taskID))...)
 	if err != nil {
 		return fmt.Errorf("failed to merge branch %s: %w", branchName, err)
 	}
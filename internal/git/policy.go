@@ -0,0 +1,149 @@
+package git
+
+import (
+	"fmt"
+	"path"
+	"strings"
+
+	"hermes/internal/config"
+	"hermes/internal/task"
+)
+
+// CommitPolicy restricts the branches Hermes commits to and what a task
+// commit may contain
+type CommitPolicy struct {
+	ProtectedBranches   []string // Branches never committed to directly, e.g. main
+	RequireHermesBranch bool     // Only commit on feature/ and task/ branches
+	ScopedCommits       bool     // Refuse task commits with files outside the task's files
+	StageTaskFilesOnly  bool     // Stage only the task's files, leaving other changes unstaged
+}
+
+// NewCommitPolicy returns the commit guardrails of the git configuration
+func NewCommitPolicy(cfg config.GitConfig) CommitPolicy {
+	return CommitPolicy{
+		ProtectedBranches:   cfg.ProtectedBranches,
+		RequireHermesBranch: cfg.RequireHermesBranch,
+		ScopedCommits:       cfg.ScopedCommits,
+		StageTaskFilesOnly:  cfg.StageTaskFilesOnly,
+	}
+}
+
+// SetCommitPolicy sets the policy commits are checked against
+func (g *Git) SetCommitPolicy(policy CommitPolicy) {
+	g.policy = policy
+}
+
+// IsHermesBranch reports whether branch was created by Hermes
+func IsHermesBranch(branch string) bool {
+	return strings.HasPrefix(branch, "feature/") || strings.HasPrefix(branch, "task/")
+}
+
+// CheckBranchPolicy returns an error when the policy forbids committing on
+// the current branch
+func (g *Git) CheckBranchPolicy() error {
+	if len(g.policy.ProtectedBranches) == 0 && !g.policy.RequireHermesBranch {
+		return nil
+	}
+	branch, err := g.GetCurrentBranch()
+	if err != nil {
+		return err
+	}
+	for _, protected := range g.policy.ProtectedBranches {
+		if branch == protected {
+			return fmt.Errorf("branch %s is protected, commit on a feature branch instead", branch)
+		}
+	}
+	if g.policy.RequireHermesBranch && !IsHermesBranch(branch) {
+		return fmt.Errorf("branch %s is not a hermes branch (feature/ or task/)", branch)
+	}
+	return nil
+}
+
+// StageTask stages the changes of a task. When the policy stages only task
+// files and the task lists its files, changes outside them, including new
+// files, are left unstaged and returned.
+func (g *Git) StageTask(files []string) ([]string, error) {
+	if err := g.StageAll(); err != nil {
+		return nil, err
+	}
+	files = scopePaths(files)
+	if !g.policy.StageTaskFilesOnly || len(files) == 0 {
+		return nil, nil
+	}
+	output, err := g.run("diff", "--cached", "--name-only")
+	if err != nil {
+		return nil, err
+	}
+	var outside []string
+	for _, file := range nonEmptyLines(output) {
+		if !InScope(file, files) {
+			outside = append(outside, file)
+		}
+	}
+	if len(outside) > 0 {
+		if _, err := g.run(append([]string{"reset", "-q", "--"}, outside...)...); err != nil {
+			return nil, err
+		}
+	}
+	return outside, nil
+}
+
+// CommitTaskInScope commits the staged changes of a task with the configured
+// message format. When the policy scopes commits and the task lists its
+// files or has a workspace, it refuses and unstages the changes if any staged file is outside
+// of them.
+func (g *Git) CommitTaskInScope(t *task.Task) error {
+	if !g.HasStagedChanges() {
+		return fmt.Errorf("no changes staged for task %s", t.ID)
+	}
+	if files := t.ScopeFiles(); g.policy.ScopedCommits && len(files) > 0 {
+		output, err := g.run("diff", "--cached", "--name-only")
+		if err != nil {
+			return err
+		}
+		var outside []string
+		for _, file := range nonEmptyLines(output) {
+			if !InScope(file, files) {
+				outside = append(outside, file)
+			}
+		}
+		if len(outside) > 0 {
+			g.Unstage()
+			return fmt.Errorf("changes outside the files of task %s: %s", t.ID, strings.Join(outside, ", "))
+		}
+	}
+	message, err := g.TaskCommitMessage(t)
+	if err != nil {
+		return err
+	}
+	return g.Commit(message)
+}
+
+// InScope reports whether file is one of files, below one of them or
+// matches one of them as a pattern. Files are taken as written in task
+// files, see scopePaths.
+func InScope(file string, files []string) bool {
+	for _, f := range scopePaths(files) {
+		if file == f || strings.HasPrefix(file, f+"/") {
+			return true
+		}
+		if matched, _ := path.Match(f, file); matched {
+			return true
+		}
+	}
+	return false
+}
+
+// scopePaths returns the files of a task as clean repository paths, without
+// the backticks and blank entries of the task file
+func scopePaths(files []string) []string {
+	var paths []string
+	for _, f := range files {
+		f = strings.TrimSpace(strings.Trim(strings.TrimSpace(f), "`"))
+		if f == "" {
+			continue
+		}
+		paths = append(paths, strings.TrimPrefix(path.Clean(strings.ReplaceAll(f, "\\", "/")), "./"))
+	}
+	return paths
+}
@@ -0,0 +1,201 @@
+package git
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"hermes/internal/task"
+)
+
+// maxReleaseCommits bounds the commits passed to the AI for release notes
+const maxReleaseCommits = 200
+
+// Release configures the notes of the tags created for completed features
+type Release struct {
+	// Summarize turns a prompt into the release notes. When nil or failing,
+	// the notes list the feature's completed tasks.
+	Summarize func(prompt string) (string, error)
+	// Changelog is the file the notes are added to, e.g. "CHANGELOG.md", and
+	// committed before tagging. Empty leaves the changelog alone.
+	Changelog string
+}
+
+// FeatureTagName returns the tag of a feature's target version, adding the
+// "v" prefix when missing
+func FeatureTagName(version string) string {
+	if version != "" && version[0] != 'v' {
+		return "v" + version
+	}
+	return version
+}
+
+// LatestTag returns the most recent tag reachable from HEAD, empty when
+// there is none
+func (g *Git) LatestTag() string {
+	tag, err := g.run("describe", "--tags", "--abbrev=0")
+	if err != nil {
+		return ""
+	}
+	return tag
+}
+
+// CreateFeatureRelease tags a completed feature like CreateFeatureTag, with
+// release notes on its completed tasks and the commits since the previous
+// tag as the tag message. With a nil release it is CreateFeatureTag.
+func (g *Git) CreateFeatureRelease(feature *task.Feature, release *Release) error {
+	if release == nil {
+		return g.CreateFeatureTag(feature.ID, feature.Name, feature.TargetVersion)
+	}
+	tag := FeatureTagName(feature.TargetVersion)
+	if tag == "" || g.TagExists(tag) {
+		return nil
+	}
+
+	logArgs := []string{"log", fmt.Sprintf("-%d", maxReleaseCommits), "--format=%s"}
+	if previous := g.LatestTag(); previous != "" {
+		logArgs = append(logArgs, previous+"..HEAD")
+	}
+	commits, _ := g.run(logArgs...)
+
+	notes := BuildReleaseNotes(feature)
+	if release.Summarize != nil {
+		if summary, err := release.Summarize(BuildReleaseNotesPrompt(feature, tag, commits)); err == nil && strings.TrimSpace(summary) != "" {
+			notes = strings.TrimSpace(summary)
+		}
+	}
+
+	if release.Changelog != "" {
+		if err := g.commitChangelog(release.Changelog, tag, notes); err != nil {
+			return err
+		}
+	}
+
+	message := fmt.Sprintf("Release %s: %s - %s\n\n%s", tag, feature.ID, feature.Name, notes)
+	return g.CreateTag(tag, message)
+}
+
+// commitChangelog adds the notes of a release to the top of the changelog
+// and commits it. A changelog that cannot be committed is restored.
+func (g *Git) commitChangelog(changelog, tag, notes string) error {
+	path := filepath.Join(g.workDir, changelog)
+	previous, readErr := os.ReadFile(path)
+	if readErr != nil && !os.IsNotExist(readErr) {
+		return fmt.Errorf("failed to read %s: %w", changelog, readErr)
+	}
+
+	content := PrependChangelog(string(previous), tag, time.Now().Format("2006-01-02"), notes)
+	if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+		return fmt.Errorf("failed to write %s: %w", changelog, err)
+	}
+	if err := g.CommitPaths("docs: update changelog for "+tag, changelog); err != nil {
+		g.run("reset", "-q", "--", changelog)
+		if readErr != nil {
+			os.Remove(path)
+		} else {
+			os.WriteFile(path, previous, 0644)
+		}
+		return fmt.Errorf("failed to commit %s: %w", changelog, err)
+	}
+	return nil
+}
+
+// TasksCommittedSince returns which of the given tasks have a commit after
+// ref, or anywhere in the history when ref is empty
+func (g *Git) TasksCommittedSince(ref string, taskIDs []string) (map[string]bool, error) {
+	args := []string{"log", "--format=%s"}
+	if ref != "" {
+		args = append(args, ref+"..HEAD")
+	}
+	output, err := g.run(args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read log: %s", output)
+	}
+	committed := make(map[string]bool)
+	for _, subject := range nonEmptyLines(output) {
+		for _, id := range taskIDs {
+			if subjectMentionsTask(subject, id) {
+				committed[id] = true
+			}
+		}
+	}
+	return committed, nil
+}
+
+// ChangelogEntry formats the changelog entry of a release
+func ChangelogEntry(tag, date, notes string) string {
+	return fmt.Sprintf("## %s - %s\n\n%s\n\n", tag, date, strings.TrimSpace(notes))
+}
+
+// PrependChangelog adds the entry of a release above the previous entries of
+// a changelog, below its title, creating the title for a new changelog
+func PrependChangelog(changelog, tag, date, notes string) string {
+	entry := ChangelogEntry(tag, date, notes)
+	newline := "\n"
+	if strings.Contains(changelog, "\r\n") {
+		newline = "\r\n"
+		entry = strings.ReplaceAll(entry, "\n", "\r\n")
+	}
+
+	if strings.TrimSpace(changelog) == "" {
+		return "# Changelog" + newline + newline + entry
+	}
+	if !strings.HasPrefix(changelog, "# ") {
+		return entry + changelog
+	}
+	// Keep the title and the paragraphs introducing the changelog on top
+	if i := strings.Index(changelog, newline+"## "); i >= 0 {
+		return changelog[:i+len(newline)] + entry + changelog[i+len(newline):]
+	}
+	return strings.TrimRight(changelog, "\r\n") + newline + newline + entry
+}
+
+// BuildReleaseNotes builds release notes from the feature's completed tasks,
+// without AI
+func BuildReleaseNotes(feature *task.Feature) string {
+	var sb strings.Builder
+	sb.WriteString(fmt.Sprintf("### %s: %s\n\n", feature.ID, feature.Name))
+	for _, t := range feature.Tasks {
+		if t.Status == task.StatusCompleted {
+			sb.WriteString(fmt.Sprintf("- %s\n", t.Name))
+		}
+	}
+	return strings.TrimSpace(sb.String())
+}
+
+// BuildReleaseNotesPrompt asks the AI for the release notes of a feature
+func BuildReleaseNotesPrompt(feature *task.Feature, tag, commits string) string {
+	var sb strings.Builder
+	sb.WriteString(fmt.Sprintf("Write the release notes of version %s, which completes a feature.\n\n", tag))
+	sb.WriteString(fmt.Sprintf("## Feature %s: %s\n\n", feature.ID, feature.Name))
+	if feature.Description != "" {
+		sb.WriteString(feature.Description + "\n\n")
+	}
+
+	sb.WriteString("## Completed Tasks\n\n")
+	for _, t := range feature.Tasks {
+		if t.Status != task.StatusCompleted {
+			continue
+		}
+		sb.WriteString(fmt.Sprintf("- %s: %s\n", t.ID, t.Name))
+		if t.Description != "" {
+			sb.WriteString("  " + strings.ReplaceAll(strings.TrimSpace(t.Description), "\n", " ") + "\n")
+		}
+	}
+
+	if commits != "" {
+		sb.WriteString("\n## Commits Since the Previous Release\n\n" + commits + "\n")
+	}
+
+	sb.WriteString(`
+## Instructions
+
+- Group the changes under "### Added", "### Changed" and "### Fixed", leaving out empty groups
+- Write one line per change, for users of the project rather than its developers
+- Output ONLY the Markdown notes, without a version heading and without code fences around them
+- Do NOT modify any files
+`)
+	return sb.String()
+}
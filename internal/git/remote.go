@@ -0,0 +1,66 @@
+package git
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+)
+
+// PushRetryDelay is the wait before the first push retry, doubled for each
+// further retry
+var PushRetryDelay = 2 * time.Second
+
+// GetRemoteURL returns the URL of a remote, e.g. "origin"
+func (g *Git) GetRemoteURL(remote string) (string, error) {
+	output, err := g.run("remote", "get-url", remote)
+	if err != nil {
+		return "", fmt.Errorf("remote %s not found", remote)
+	}
+	return output, nil
+}
+
+// PushBranch pushes a branch to a remote and sets it as upstream
+func (g *Git) PushBranch(remote, branch string) error {
+	if output, err := g.run("push", "-u", remote, branch); err != nil {
+		return fmt.Errorf("failed to push %s: %s", branch, output)
+	}
+	return nil
+}
+
+// PushHead pushes the current branch and the annotated tags on it to a
+// remote, retrying failed pushes with backoff. Rejected pushes are not
+// retried since they need the remote changes merged first. Retrying stops
+// when ctx is done.
+func (g *Git) PushHead(ctx context.Context, remote string, retries int) error {
+	delay := PushRetryDelay
+	for attempt := 0; ; attempt++ {
+		output, err := g.run("push", "--follow-tags", remote, "HEAD")
+		if err == nil {
+			return nil
+		}
+		if attempt >= retries || strings.Contains(output, "[rejected]") {
+			return fmt.Errorf("failed to push to %s: %s", remote, output)
+		}
+		timer := time.NewTimer(delay)
+		select {
+		case <-timer.C:
+		case <-ctx.Done():
+			timer.Stop()
+			return fmt.Errorf("failed to push to %s: %s", remote, output)
+		}
+		delay *= 2
+	}
+}
+
+// GetBranchDiffStat returns "git diff --stat" of a branch against the point it
+// forked from base
+func (g *Git) GetBranchDiffStat(base, branch string) (string, error) {
+	return g.run("diff", "--stat", base+"..."+branch)
+}
+
+// GetBranchLog returns the subjects of the commits on branch that are not on
+// base, oldest first
+func (g *Git) GetBranchLog(base, branch string) (string, error) {
+	return g.run("log", "--reverse", "--format=%s", base+".."+branch)
+}
@@ -0,0 +1,116 @@
+package git
+
+import (
+	"fmt"
+	"strings"
+)
+
+// TaskCommit is a commit on the current branch that belongs to a task
+type TaskCommit struct {
+	Hash    string
+	Subject string
+	IsMerge bool // Parallel runs merge task branches instead of committing directly
+}
+
+// FindTaskCommits returns the commits of a task on the current branch, newest
+// first. It matches the subjects written by CommitTask ("feat(T001): ...") and
+// by parallel merges ("Merge branch 'task/T001' (task T001)") or rebased
+// task branches ("Complete task T001: ...").
+func (g *Git) FindTaskCommits(taskID string) ([]TaskCommit, error) {
+	output, err := g.run("log", "--first-parent", "--format=%H%x00%P%x00%s")
+	if err != nil {
+		return nil, err
+	}
+
+	var commits []TaskCommit
+	for _, line := range strings.Split(output, "\n") {
+		parts := strings.SplitN(line, "\x00", 3)
+		if len(parts) < 3 || !subjectMentionsTask(parts[2], taskID) {
+			continue
+		}
+		commits = append(commits, TaskCommit{
+			Hash:    parts[0],
+			Subject: parts[2],
+			IsMerge: len(strings.Fields(parts[1])) > 1,
+		})
+	}
+	return commits, nil
+}
+
+// subjectMentionsTask reports whether a commit subject was written for
+// taskID, including worktree commits fast-forwarded by the rebase strategy
+func subjectMentionsTask(subject, taskID string) bool {
+	return strings.Contains(subject, "("+taskID+")") || strings.Contains(subject, "(task "+taskID+")") ||
+		strings.HasPrefix(subject, "Complete task "+taskID+":")
+}
+
+// RevertCommit creates a commit undoing the given commit. Merge commits are
+// reverted relative to their first parent.
+func (g *Git) RevertCommit(c TaskCommit) error {
+	args := append([]string{"revert", "--no-edit"}, g.signArgs()...)
+	if c.IsMerge {
+		args = append(args, "-m", "1")
+	}
+	if output, err := g.run(append(args, c.Hash)...); err != nil {
+		g.run("revert", "--abort")
+		return fmt.Errorf("failed to revert %s: %s", c.Hash[:8], output)
+	}
+	return nil
+}
+
+// ChangedFiles returns the files a commit changed, relative to its first
+// parent for merge commits
+func (g *Git) ChangedFiles(c TaskCommit) ([]string, error) {
+	output, err := g.run("diff", "--name-only", c.Hash+"^1", c.Hash)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list files of %s: %s", c.Hash[:8], output)
+	}
+	return nonEmptyLines(output), nil
+}
+
+// FindCommitsTouching returns the commits on the current branch after ref
+// that changed any of paths, newest first
+func (g *Git) FindCommitsTouching(ref string, paths []string) ([]TaskCommit, error) {
+	if len(paths) == 0 {
+		return nil, nil
+	}
+	args := []string{"log", "--first-parent", "--format=%H%x00%P%x00%s", ref + "..HEAD", "--"}
+	output, err := g.run(append(args, paths...)...)
+	if err != nil {
+		return nil, err
+	}
+
+	var commits []TaskCommit
+	for _, line := range nonEmptyLines(output) {
+		parts := strings.SplitN(line, "\x00", 3)
+		if len(parts) < 3 {
+			continue
+		}
+		commits = append(commits, TaskCommit{
+			Hash:    parts[0],
+			Subject: parts[2],
+			IsMerge: len(strings.Fields(parts[1])) > 1,
+		})
+	}
+	return commits, nil
+}
+
+// nonEmptyLines splits command output into its non-empty lines
+func nonEmptyLines(output string) []string {
+	var lines []string
+	for _, line := range strings.Split(output, "\n") {
+		if line = strings.TrimSpace(line); line != "" {
+			lines = append(lines, line)
+		}
+	}
+	return lines
+}
+
+// ResetHard moves the current branch to ref, discarding later commits and
+// uncommitted changes
+func (g *Git) ResetHard(ref string) error {
+	if output, err := g.run("reset", "--hard", ref); err != nil {
+		return fmt.Errorf("failed to reset to %s: %s", ref, output)
+	}
+	return nil
+}
@@ -0,0 +1,78 @@
+package git
+
+import (
+	"os"
+	"os/exec"
+
+	"hermes/internal/config"
+)
+
+// Signing configures the signing of the commits, merges and tags Hermes
+// creates, for repositories that require verified commits
+type Signing struct {
+	Enabled bool
+	Key     string // Key ID or SSH key path, git's user.signingKey when empty
+	Format  string // gpg, ssh or x509, git's gpg.format when empty
+}
+
+// NewSigning returns the commit signing of the git configuration
+func NewSigning(cfg config.GitConfig) Signing {
+	return Signing{Enabled: cfg.Sign, Key: cfg.SigningKey, Format: cfg.SigningFormat}
+}
+
+// SetSigning sets how commits, merges and tags are signed
+func (g *Git) SetSigning(signing Signing) {
+	g.signing = signing
+}
+
+// Args returns the options signing a commit, merge, rebase or revert, for
+// git commands run outside of Git
+func (s Signing) Args() []string {
+	if !s.Enabled {
+		return nil
+	}
+	if s.Key != "" {
+		return []string{"--gpg-sign=" + s.Key}
+	}
+	return []string{"--gpg-sign"}
+}
+
+// Apply adds the environment selecting the signature format to a git command
+// run outside of Git
+func (s Signing) Apply(cmd *exec.Cmd) {
+	if env := s.env(); env != nil {
+		if cmd.Env == nil {
+			cmd.Env = os.Environ()
+		}
+		cmd.Env = append(cmd.Env, env...)
+	}
+}
+
+// signArgs returns the options signing a commit, merge or revert
+func (g *Git) signArgs() []string {
+	return g.signing.Args()
+}
+
+// tagSignArgs returns the options signing an annotated tag
+func (g *Git) tagSignArgs() []string {
+	if !g.signing.Enabled {
+		return nil
+	}
+	if g.signing.Key != "" {
+		return []string{"--local-user=" + g.signing.Key}
+	}
+	return []string{"--sign"}
+}
+
+// signEnv returns the environment selecting the signature format, which git
+// only takes from its configuration
+func (g *Git) signEnv() []string {
+	return g.signing.env()
+}
+
+func (s Signing) env() []string {
+	if !s.Enabled || s.Format == "" {
+		return nil
+	}
+	return []string{"GIT_CONFIG_COUNT=1", "GIT_CONFIG_KEY_0=gpg.format", "GIT_CONFIG_VALUE_0=" + s.Format}
+}
@@ -0,0 +1,55 @@
+package git
+
+import "fmt"
+
+// DirtyFiles returns the modified and untracked files of the working tree,
+// leaving out the stage excludes such as Hermes state
+func (g *Git) DirtyFiles() ([]string, error) {
+	excludes := ExcludePathspecs(g.stageExcludes)
+	changed, err := g.run(append([]string{"diff", "--name-only", "HEAD", "--", "."}, excludes...)...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list changes: %s", changed)
+	}
+	untracked, err := g.run(append([]string{"ls-files", "--others", "--exclude-standard", "--", "."}, excludes...)...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list untracked files: %s", untracked)
+	}
+	return append(nonEmptyLines(changed), nonEmptyLines(untracked)...), nil
+}
+
+// Stash stashes the changes and untracked files of the working tree outside
+// the stage excludes and returns the stash commit
+func (g *Git) Stash(message string) (string, error) {
+	args := append([]string{"stash", "push", "--include-untracked", "-m", message, "--", "."}, ExcludePathspecs(g.stageExcludes)...)
+	if output, err := g.run(args...); err != nil {
+		return "", fmt.Errorf("failed to stash changes: %s", output)
+	}
+	return g.run("rev-parse", "stash@{0}")
+}
+
+// RestoreStash applies the stash commit returned by Stash and drops it. A
+// stash that does not apply cleanly is kept so nothing is lost.
+func (g *Git) RestoreStash(hash string) error {
+	if output, err := g.run("stash", "apply", hash); err != nil {
+		return fmt.Errorf("failed to restore stash %s, it is kept: %s", shortRef(hash), output)
+	}
+	stashes, err := g.run("stash", "list", "--format=%H")
+	if err != nil {
+		return err
+	}
+	for i, h := range nonEmptyLines(stashes) {
+		if h == hash {
+			_, err := g.run("stash", "drop", fmt.Sprintf("stash@{%d}", i))
+			return err
+		}
+	}
+	return nil
+}
+
+// shortRef abbreviates a commit hash for messages
+func shortRef(hash string) string {
+	if len(hash) > 8 {
+		return hash[:8]
+	}
+	return hash
+}
@@ -0,0 +1,88 @@
+package git
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Worktree is a linked working tree of the repository
+type Worktree struct {
+	Path   string
+	Branch string
+}
+
+// ListWorktrees returns the linked worktrees, without the main working tree
+func (g *Git) ListWorktrees() ([]Worktree, error) {
+	output, err := g.run("worktree", "list", "--porcelain")
+	if err != nil {
+		return nil, err
+	}
+
+	var worktrees []Worktree
+	// Blocks are separated by blank lines; the first block is the main working tree
+	for i, block := range strings.Split(output, "\n\n") {
+		if i == 0 {
+			continue
+		}
+		var wt Worktree
+		for _, line := range strings.Split(block, "\n") {
+			switch {
+			case strings.HasPrefix(line, "worktree "):
+				wt.Path = strings.TrimPrefix(line, "worktree ")
+			case strings.HasPrefix(line, "branch "):
+				wt.Branch = strings.TrimPrefix(strings.TrimPrefix(line, "branch "), "refs/heads/")
+			}
+		}
+		if wt.Path != "" {
+			worktrees = append(worktrees, wt)
+		}
+	}
+	return worktrees, nil
+}
+
+// RemoveWorktree removes a linked worktree including uncommitted changes
+func (g *Git) RemoveWorktree(path string) error {
+	if output, err := g.run("worktree", "remove", "--force", path); err != nil {
+		return fmt.Errorf("failed to remove worktree %s: %s", path, output)
+	}
+	return nil
+}
+
+// PruneWorktrees removes administrative data of worktrees whose directory is gone
+func (g *Git) PruneWorktrees() error {
+	_, err := g.run("worktree", "prune")
+	return err
+}
+
+// MergedBranch is a local branch fully merged into the current branch
+type MergedBranch struct {
+	Name       string
+	CommitTime time.Time // Time of the last commit on the branch
+}
+
+// ListMergedBranches returns local branches matching the patterns (e.g. "task/*")
+// that are fully merged into HEAD. The current branch is never included.
+func (g *Git) ListMergedBranches(patterns ...string) ([]MergedBranch, error) {
+	args := []string{"for-each-ref", "--merged", "HEAD", "--format=%(HEAD)%(refname:short) %(committerdate:unix)"}
+	for _, p := range patterns {
+		args = append(args, "refs/heads/"+p)
+	}
+	output, err := g.run(args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list merged branches: %s", output)
+	}
+
+	var branches []MergedBranch
+	for _, line := range strings.Split(output, "\n") {
+		// %(HEAD) is "*" for the current branch and a space otherwise
+		if line == "" || strings.HasPrefix(line, "*") {
+			continue
+		}
+		name, unix, _ := strings.Cut(strings.TrimSpace(line), " ")
+		secs, _ := strconv.ParseInt(unix, 10, 64)
+		branches = append(branches, MergedBranch{Name: name, CommitTime: time.Unix(secs, 0)})
+	}
+	return branches, nil
+}
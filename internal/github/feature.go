@@ -0,0 +1,133 @@
+package github
+
+import (
+	"fmt"
+	"strings"
+
+	"hermes/internal/git"
+	"hermes/internal/task"
+)
+
+// maxPromptDiffStat bounds the diffstat passed to the AI for the summary
+const maxPromptDiffStat = 4000
+
+// FeatureRequest describes the pull request of a completed feature
+type FeatureRequest struct {
+	Feature *task.Feature
+	Remote  string // Remote the feature branch is pushed to, e.g. "origin"
+	Draft   bool
+	// Summarize turns a prompt into the pull request description. When nil or
+	// failing, a description listing the tasks and the diffstat is used.
+	Summarize func(prompt string) (string, error)
+}
+
+// OpenFeaturePullRequest pushes the branch of a completed feature, opens a pull
+// request against the main branch and checks the main branch out again, so the
+// next feature starts from it. It returns the URL of the pull request.
+func OpenFeaturePullRequest(gitOps *git.Git, req *FeatureRequest) (string, error) {
+	feature := req.Feature
+	branch := gitOps.GetFeatureBranchName(feature.ID, feature.Name)
+	if !gitOps.BranchExists(branch) {
+		return "", fmt.Errorf("feature branch %s not found", branch)
+	}
+	base := gitOps.GetMainBranch()
+	remote := req.Remote
+	if remote == "" {
+		remote = "origin"
+	}
+
+	remoteURL, err := gitOps.GetRemoteURL(remote)
+	if err != nil {
+		return "", err
+	}
+
+	diffStat, _ := gitOps.GetBranchDiffStat(base, branch)
+	commits, _ := gitOps.GetBranchLog(base, branch)
+
+	body := BuildFeatureBody(feature, diffStat)
+	if req.Summarize != nil {
+		if summary, err := req.Summarize(BuildSummaryPrompt(feature, diffStat, commits)); err == nil && strings.TrimSpace(summary) != "" {
+			body = strings.TrimSpace(summary)
+		}
+	}
+
+	if err := gitOps.PushBranch(remote, branch); err != nil {
+		return "", err
+	}
+	url, err := Create(gitOps.WorkDir(), remoteURL, &PullRequest{
+		Title: FeatureTitle(feature),
+		Body:  body,
+		Head:  branch,
+		Base:  base,
+		Draft: req.Draft,
+	})
+	if err != nil {
+		return "", err
+	}
+
+	if err := gitOps.CheckoutBranch(base); err != nil {
+		return url, fmt.Errorf("failed to checkout %s: %w", base, err)
+	}
+	return url, nil
+}
+
+// FeatureTitle returns the pull request title of a feature
+func FeatureTitle(feature *task.Feature) string {
+	return fmt.Sprintf("feat(%s): %s", feature.ID, feature.Name)
+}
+
+// BuildFeatureBody builds a pull request description from the feature's tasks
+// and diffstat, without AI
+func BuildFeatureBody(feature *task.Feature, diffStat string) string {
+	var sb strings.Builder
+	if feature.Description != "" {
+		sb.WriteString(feature.Description + "\n\n")
+	}
+	sb.WriteString("## Tasks\n\n")
+	for _, t := range feature.Tasks {
+		sb.WriteString(fmt.Sprintf("- %s: %s\n", t.ID, t.Name))
+	}
+	if diffStat != "" {
+		sb.WriteString("\n## Changes\n\n```\n" + diffStat + "\n```\n")
+	}
+	return sb.String()
+}
+
+// BuildSummaryPrompt asks the AI for a pull request description of a feature
+func BuildSummaryPrompt(feature *task.Feature, diffStat, commits string) string {
+	var sb strings.Builder
+	sb.WriteString("Write the description of a GitHub pull request for a completed feature.\n\n")
+	sb.WriteString(fmt.Sprintf("## Feature %s: %s\n\n", feature.ID, feature.Name))
+	if feature.Description != "" {
+		sb.WriteString(feature.Description + "\n\n")
+	}
+
+	sb.WriteString("## Tasks\n\n")
+	for _, t := range feature.Tasks {
+		sb.WriteString(fmt.Sprintf("- %s: %s\n", t.ID, t.Name))
+		if t.Description != "" {
+			sb.WriteString("  " + strings.ReplaceAll(strings.TrimSpace(t.Description), "\n", " ") + "\n")
+		}
+	}
+
+	if commits != "" {
+		sb.WriteString("\n## Commits\n\n" + commits + "\n")
+	}
+	if diffStat != "" {
+		if len(diffStat) > maxPromptDiffStat {
+			diffStat = diffStat[:maxPromptDiffStat] + "\n... (truncated)"
+		}
+		sb.WriteString("\n## Diffstat\n\n" + diffStat + "\n")
+	}
+
+	sb.WriteString(`
+## Instructions
+
+- Summarize what the feature adds and how the changes are organized
+- List the tasks with one line each
+- Point out changes reviewers should look at closely
+- Output ONLY the Markdown description, without a title and without code fences around it
+- Do NOT modify any files
+`)
+	return sb.String()
+}
@@ -0,0 +1,95 @@
+package github
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"hermes/internal/task"
+)
+
+func TestParseRemote(t *testing.T) {
+	tests := []struct {
+		url, owner, repo string
+	}{
+		{"git@github.com:acme/widgets.git", "acme", "widgets"},
+		{"https://github.com/acme/widgets.git", "acme", "widgets"},
+		{"https://github.com/acme/widgets", "acme", "widgets"},
+		{"ssh://git@github.com/acme/my.repo.git", "acme", "my.repo"},
+	}
+	for _, tt := range tests {
+		owner, repo, err := ParseRemote(tt.url)
+		if err != nil || owner != tt.owner || repo != tt.repo {
+			t.Errorf("ParseRemote(%q) = %q, %q, %v", tt.url, owner, repo, err)
+		}
+	}
+
+	if _, _, err := ParseRemote("https://gitlab.com/acme/widgets.git"); err == nil {
+		t.Error("expected an error for a non-GitHub remote")
+	}
+}
+
+func TestCreatePullRequest(t *testing.T) {
+	var got PullRequest
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/repos/acme/widgets/pulls" || r.Header.Get("Authorization") != "Bearer secret" {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		json.NewDecoder(r.Body).Decode(&got)
+		w.WriteHeader(http.StatusCreated)
+		w.Write([]byte(`{"html_url": "https://github.com/acme/widgets/pull/7"}`))
+	}))
+	defer server.Close()
+
+	client := NewClient("secret")
+	client.baseURL = server.URL
+	url, err := client.CreatePullRequest("acme", "widgets", &PullRequest{
+		Title: "feat(F001): Auth",
+		Body:  "Adds login",
+		Head:  "feature/F001-auth",
+		Base:  "main",
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if url != "https://github.com/acme/widgets/pull/7" {
+		t.Errorf("unexpected URL %q", url)
+	}
+	if got.Head != "feature/F001-auth" || got.Base != "main" || got.Body != "Adds login" {
+		t.Errorf("unexpected request %+v", got)
+	}
+
+	if _, err := client.CreatePullRequest("acme", "other", &PullRequest{}); err == nil {
+		t.Error("expected an error for a failed request")
+	}
+}
+
+func TestBuildFeatureBody(t *testing.T) {
+	feature := &task.Feature{
+		ID:          "F001",
+		Name:        "Auth",
+		Description: "User authentication",
+		Tasks: []task.Task{
+			{ID: "T001", Name: "Login"},
+			{ID: "T002", Name: "Logout"},
+		},
+	}
+
+	body := BuildFeatureBody(feature, " auth.go | 10 ++++")
+	for _, want := range []string{"User authentication", "- T001: Login", "- T002: Logout", "auth.go | 10"} {
+		if !strings.Contains(body, want) {
+			t.Errorf("expected body to contain %q:\n%s", want, body)
+		}
+	}
+
+	prompt := BuildSummaryPrompt(feature, "", "feat(T001): Login")
+	if !strings.Contains(prompt, "Feature F001: Auth") || !strings.Contains(prompt, "feat(T001): Login") {
+		t.Errorf("unexpected prompt:\n%s", prompt)
+	}
+	if FeatureTitle(feature) != "feat(F001): Auth" {
+		t.Errorf("unexpected title %q", FeatureTitle(feature))
+	}
+}
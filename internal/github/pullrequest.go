@@ -0,0 +1,128 @@
+package github
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"os"
+	"os/exec"
+	"regexp"
+	"strings"
+)
+
+const apiURL = "https://api.github.com"
+
+// remoteRegex extracts owner and repository from SSH and HTTPS remote URLs
+var remoteRegex = regexp.MustCompile(`github\.com[:/]([^/]+)/([^/]+?)(?:\.git)?/?$`)
+
+// PullRequest describes a pull request to open
+type PullRequest struct {
+	Title string `json:"title"`
+	Body  string `json:"body"`
+	Head  string `json:"head"` // Branch with the changes
+	Base  string `json:"base"` // Branch to merge into
+	Draft bool   `json:"draft"`
+}
+
+// Client creates pull requests through the GitHub REST API
+type Client struct {
+	token      string
+	baseURL    string
+	httpClient *http.Client
+}
+
+// NewClient creates a new API client authenticated with token
+func NewClient(token string) *Client {
+	return &Client{
+		token:      token,
+		baseURL:    apiURL,
+		httpClient: &http.Client{},
+	}
+}
+
+// CreatePullRequest opens a pull request in owner/repo and returns its URL
+func (c *Client) CreatePullRequest(owner, repo string, pr *PullRequest) (string, error) {
+	data, err := json.Marshal(pr)
+	if err != nil {
+		return "", err
+	}
+
+	url := fmt.Sprintf("%s/repos/%s/%s/pulls", c.baseURL, owner, repo)
+	req, err := http.NewRequest("POST", url, bytes.NewReader(data))
+	if err != nil {
+		return "", err
+	}
+	req.Header.Set("Accept", "application/vnd.github.v3+json")
+	req.Header.Set("Authorization", "Bearer "+c.token)
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("User-Agent", "Hermes")
+
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return "", fmt.Errorf("failed to create pull request: %w", err)
+	}
+	defer resp.Body.Close()
+
+	var result struct {
+		HTMLURL string `json:"html_url"`
+		Message string `json:"message"`
+	}
+	json.NewDecoder(resp.Body).Decode(&result)
+	if resp.StatusCode != http.StatusCreated {
+		if result.Message != "" {
+			return "", fmt.Errorf("GitHub API error: %s: %s", resp.Status, result.Message)
+		}
+		return "", fmt.Errorf("GitHub API error: %s", resp.Status)
+	}
+	return result.HTMLURL, nil
+}
+
+// ParseRemote returns the owner and repository of a GitHub remote URL
+func ParseRemote(remoteURL string) (string, string, error) {
+	m := remoteRegex.FindStringSubmatch(strings.TrimSpace(remoteURL))
+	if m == nil {
+		return "", "", fmt.Errorf("not a GitHub remote: %s", remoteURL)
+	}
+	return m[1], m[2], nil
+}
+
+// Create opens a pull request for the repository at remoteURL. The gh CLI is
+// used when installed, so its login is reused; otherwise the REST API is called
+// with GITHUB_TOKEN or GH_TOKEN.
+func Create(workDir, remoteURL string, pr *PullRequest) (string, error) {
+	if _, err := exec.LookPath("gh"); err == nil {
+		return createWithCLI(workDir, pr)
+	}
+
+	token := os.Getenv("GITHUB_TOKEN")
+	if token == "" {
+		token = os.Getenv("GH_TOKEN")
+	}
+	if token == "" {
+		return "", fmt.Errorf("install the gh CLI or set GITHUB_TOKEN to open pull requests")
+	}
+	owner, repo, err := ParseRemote(remoteURL)
+	if err != nil {
+		return "", err
+	}
+	return NewClient(token).CreatePullRequest(owner, repo, pr)
+}
+
+// createWithCLI opens a pull request with "gh pr create"
+func createWithCLI(workDir string, pr *PullRequest) (string, error) {
+	args := []string{"pr", "create", "--title", pr.Title, "--body-file", "-", "--head", pr.Head, "--base", pr.Base}
+	if pr.Draft {
+		args = append(args, "--draft")
+	}
+	cmd := exec.Command("gh", args...)
+	cmd.Dir = workDir
+	cmd.Stdin = strings.NewReader(pr.Body)
+	output, err := cmd.CombinedOutput()
+	if err != nil {
+		return "", fmt.Errorf("gh pr create failed: %s", strings.TrimSpace(string(output)))
+	}
+	// gh prints the URL of the new pull request on the last line
+	lines := strings.Split(strings.TrimSpace(string(output)), "\n")
+	return lines[len(lines)-1], nil
+}
@@ -0,0 +1,61 @@
+package hooks
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"runtime"
+	"strings"
+	"time"
+)
+
+// Hook names, passed to commands in HERMES_HOOK
+const (
+	PreRun      = "pre_run"
+	PostRun     = "post_run"
+	BreakerOpen = "breaker_open"
+)
+
+// Run runs the commands of a hook one after another in dir through the shell,
+// each limited to timeout, and stops at the first one that fails. Commands
+// see the hook name in HERMES_HOOK and env in their environment.
+func Run(ctx context.Context, dir, hook string, commands []string, env []string, timeout time.Duration, out io.Writer) error {
+	for _, command := range commands {
+		if strings.TrimSpace(command) == "" {
+			continue
+		}
+		if err := run(ctx, dir, hook, command, env, timeout, out); err != nil {
+			return fmt.Errorf("%s hook %q failed: %w", hook, command, err)
+		}
+	}
+	return nil
+}
+
+func run(ctx context.Context, dir, hook, command string, env []string, timeout time.Duration, out io.Writer) error {
+	if timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, timeout)
+		defer cancel()
+	}
+
+	var cmd *exec.Cmd
+	if runtime.GOOS == "windows" {
+		cmd = exec.CommandContext(ctx, "cmd", "/C", command)
+	} else {
+		cmd = exec.CommandContext(ctx, "sh", "-c", command)
+	}
+	cmd.Dir = dir
+	cmd.Env = append(append(os.Environ(), "HERMES_HOOK="+hook), env...)
+	cmd.Stdout = out
+	cmd.Stderr = out
+	// Don't wait for children of the shell still holding the output open
+	cmd.WaitDelay = time.Second
+
+	err := cmd.Run()
+	if ctx.Err() == context.DeadlineExceeded {
+		return fmt.Errorf("timed out after %v", timeout)
+	}
+	return err
+}
@@ -0,0 +1,51 @@
+package hooks
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestRun(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("uses sh commands")
+	}
+	dir := t.TempDir()
+
+	var out strings.Builder
+	err := Run(context.Background(), dir, PostRun, []string{
+		`echo "$HERMES_HOOK $HERMES_RUN_STATUS" > hook.txt`,
+		"",
+		"echo done",
+	}, []string{"HERMES_RUN_STATUS=success"}, time.Second, &out)
+	if err != nil {
+		t.Fatal(err)
+	}
+	data, err := os.ReadFile(filepath.Join(dir, "hook.txt"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := strings.TrimSpace(string(data)); got != "post_run success" {
+		t.Errorf("expected hook environment, got %q", got)
+	}
+	if strings.TrimSpace(out.String()) != "done" {
+		t.Errorf("expected hook output, got %q", out.String())
+	}
+
+	err = Run(context.Background(), dir, PreRun, []string{"exit 4", "touch never"}, nil, time.Second, &out)
+	if err == nil || !strings.Contains(err.Error(), `pre_run hook "exit 4" failed`) {
+		t.Errorf("expected failing hook error, got %v", err)
+	}
+	if _, err := os.Stat(filepath.Join(dir, "never")); err == nil {
+		t.Error("expected commands after a failure to be skipped")
+	}
+
+	err = Run(context.Background(), dir, PreRun, []string{"sleep 5"}, nil, 100*time.Millisecond, &out)
+	if err == nil || !strings.Contains(err.Error(), "timed out") {
+		t.Errorf("expected hook to time out, got %v", err)
+	}
+}
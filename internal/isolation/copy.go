@@ -0,0 +1,347 @@
+package isolation
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"hermes/internal/git"
+)
+
+// Isolation backends of a workspace
+const (
+	BackendAuto     = "auto"     // worktree in git repositories, copy otherwise
+	BackendWorktree = "worktree" // git worktree on a task branch
+	BackendCopy     = "copy"     // plain copy of the project directory
+)
+
+// ResolveBackend returns the backend used for the project at basePath,
+// resolving auto and unknown names by whether the project is a git repository
+func ResolveBackend(backend, basePath string) string {
+	switch backend {
+	case BackendWorktree, BackendCopy:
+		return backend
+	}
+	if git.New(basePath).IsRepository() {
+		return BackendWorktree
+	}
+	return BackendCopy
+}
+
+// manifestPath returns the file holding the hashes of the files as copied,
+// next to the copy so the task cannot change it
+func (w *Workspace) manifestPath() string {
+	return w.WorkPath + ".files.json"
+}
+
+// setupCopy copies the project into the workspace and records the hash of
+// every copied file, which ApplyChanges compares against
+func (w *Workspace) setupCopy() error {
+	if _, err := os.Stat(w.WorkPath); err == nil {
+		if err := w.Cleanup(); err != nil {
+			return fmt.Errorf("failed to cleanup existing copy: %w", err)
+		}
+	}
+
+	manifest := make(map[string]string)
+	err := w.walkFiles(w.BasePath, func(rel string, info fs.FileInfo) error {
+		hash, err := copyFile(filepath.Join(w.BasePath, rel), filepath.Join(w.WorkPath, rel), info)
+		if err != nil {
+			return err
+		}
+		manifest[rel] = hash
+		return nil
+	})
+	if err != nil {
+		os.RemoveAll(w.WorkPath)
+		return fmt.Errorf("failed to copy project: %w", err)
+	}
+	if err := os.MkdirAll(w.WorkPath, 0755); err != nil {
+		return err
+	}
+	// The prompt is excluded with .hermes but the task section is added to it
+	promptPath := filepath.Join(".hermes", "PROMPT.md")
+	if info, err := os.Stat(filepath.Join(w.BasePath, promptPath)); err == nil {
+		if _, err := copyFile(filepath.Join(w.BasePath, promptPath), filepath.Join(w.WorkPath, promptPath), info); err != nil {
+			return fmt.Errorf("failed to copy prompt: %w", err)
+		}
+	}
+
+	data, err := json.Marshal(manifest)
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(w.manifestPath(), data, 0644)
+}
+
+// loadManifest reads the file hashes recorded when the copy was made
+func (w *Workspace) loadManifest() (map[string]string, error) {
+	data, err := os.ReadFile(w.manifestPath())
+	if err != nil {
+		return nil, fmt.Errorf("failed to read copy manifest: %w", err)
+	}
+	var manifest map[string]string
+	if err := json.Unmarshal(data, &manifest); err != nil {
+		return nil, fmt.Errorf("invalid copy manifest: %w", err)
+	}
+	return manifest, nil
+}
+
+// copyChanges returns the files the task added, changed or deleted in the
+// copy, and whether each of them still exists
+func (w *Workspace) copyChanges() (map[string]bool, error) {
+	manifest, err := w.loadManifest()
+	if err != nil {
+		return nil, err
+	}
+
+	changes := make(map[string]bool)
+	seen := make(map[string]bool)
+	err = w.walkFiles(w.WorkPath, func(rel string, info fs.FileInfo) error {
+		seen[rel] = true
+		hash, err := hashFile(filepath.Join(w.WorkPath, rel), info)
+		if err != nil {
+			return err
+		}
+		if manifest[rel] != hash {
+			changes[rel] = true
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	for rel := range manifest {
+		if !seen[rel] {
+			changes[rel] = false
+		}
+	}
+	return changes, nil
+}
+
+// ConflictError is returned by ApplyChanges when files the task changed were
+// also changed in the project since the copy was made
+type ConflictError struct {
+	TaskID string
+	Files  []string
+}
+
+func (e *ConflictError) Error() string {
+	return fmt.Sprintf("task %s changed files that changed in the project since the copy: %s", e.TaskID, strings.Join(e.Files, ", "))
+}
+
+// ApplyChanges copies the files the task added or changed in a copy
+// workspace into the project and deletes the ones it removed. It returns the
+// paths applied, or a ConflictError without applying anything when the
+// project changed one of them since the copy.
+func (w *Workspace) ApplyChanges() ([]string, error) {
+	changes, err := w.copyChanges()
+	if err != nil {
+		return nil, err
+	}
+	if err := w.checkConflicts(changes); err != nil {
+		return nil, err
+	}
+
+	var files []string
+	for rel, exists := range changes {
+		target := filepath.Join(w.BasePath, rel)
+		if !exists {
+			if err := os.Remove(target); err != nil && !os.IsNotExist(err) {
+				return files, fmt.Errorf("failed to delete %s: %w", rel, err)
+			}
+		} else {
+			source := filepath.Join(w.WorkPath, rel)
+			info, err := os.Lstat(source)
+			if err != nil {
+				return files, err
+			}
+			if _, err := copyFile(source, target, info); err != nil {
+				return files, fmt.Errorf("failed to apply %s: %w", rel, err)
+			}
+		}
+		files = append(files, rel)
+	}
+	sort.Strings(files)
+	return files, nil
+}
+
+// checkConflicts returns a ConflictError listing the changed files whose
+// project version differs from the copied one, unless it already matches
+// the task's
+func (w *Workspace) checkConflicts(changes map[string]bool) error {
+	manifest, err := w.loadManifest()
+	if err != nil {
+		return err
+	}
+
+	var conflicts []string
+	for rel, exists := range changes {
+		current, err := hashIfExists(filepath.Join(w.BasePath, rel))
+		if err != nil {
+			return err
+		}
+		if current == manifest[rel] {
+			continue
+		}
+		want := ""
+		if exists {
+			if want, err = hashIfExists(filepath.Join(w.WorkPath, rel)); err != nil {
+				return err
+			}
+		}
+		if current != want {
+			conflicts = append(conflicts, rel)
+		}
+	}
+	if len(conflicts) > 0 {
+		sort.Strings(conflicts)
+		return &ConflictError{TaskID: w.TaskID, Files: conflicts}
+	}
+	return nil
+}
+
+// CleanupCopies removes the copy workspaces left in the project, e.g. by an
+// interrupted run. Git worktrees are left to git.
+func CleanupCopies(basePath string) error {
+	manifests, err := filepath.Glob(filepath.Join(basePath, ".hermes", "worktrees", "wt-*.files.json"))
+	if err != nil {
+		return err
+	}
+	for _, manifest := range manifests {
+		if err := os.RemoveAll(strings.TrimSuffix(manifest, ".files.json")); err != nil {
+			return err
+		}
+		if err := os.Remove(manifest); err != nil && !os.IsNotExist(err) {
+			return err
+		}
+	}
+	return nil
+}
+
+// walkFiles calls fn with the path relative to root of every regular file
+// and symlink below root, skipping .git and the stage excludes
+func (w *Workspace) walkFiles(root string, fn func(rel string, info fs.FileInfo) error) error {
+	return filepath.WalkDir(root, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		rel, err := filepath.Rel(root, path)
+		if err != nil || rel == "." {
+			return err
+		}
+		rel = filepath.ToSlash(rel)
+		if d.IsDir() {
+			if rel == ".git" || path == w.WorkPath || w.excluded(rel) {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		if w.excluded(rel) {
+			return nil
+		}
+		info, err := d.Info()
+		if err != nil {
+			return err
+		}
+		if !info.Mode().IsRegular() && info.Mode()&fs.ModeSymlink == 0 {
+			return nil // Sockets, devices and the like are not copied
+		}
+		return fn(rel, info)
+	})
+}
+
+// excluded reports whether rel is one of the stage excludes or below one
+func (w *Workspace) excluded(rel string) bool {
+	for _, exclude := range w.StageExcludes {
+		exclude = strings.TrimSuffix(filepath.ToSlash(exclude), "/")
+		if rel == exclude || strings.HasPrefix(rel, exclude+"/") {
+			return true
+		}
+	}
+	return false
+}
+
+// copyFile copies a regular file or symlink, keeping its mode, and returns
+// the hash of its content
+func copyFile(source, target string, info fs.FileInfo) (string, error) {
+	if err := os.MkdirAll(filepath.Dir(target), 0755); err != nil {
+		return "", err
+	}
+	if info.Mode()&fs.ModeSymlink != 0 {
+		link, err := os.Readlink(source)
+		if err != nil {
+			return "", err
+		}
+		os.Remove(target)
+		if err := os.Symlink(link, target); err != nil {
+			return "", err
+		}
+		return hashString("symlink:" + link), nil
+	}
+
+	in, err := os.Open(source)
+	if err != nil {
+		return "", err
+	}
+	defer in.Close()
+	out, err := os.OpenFile(target, os.O_WRONLY|os.O_CREATE|os.O_TRUNC, info.Mode().Perm())
+	if err != nil {
+		return "", err
+	}
+	h := sha256.New()
+	if _, err := io.Copy(io.MultiWriter(out, h), in); err != nil {
+		out.Close()
+		return "", err
+	}
+	if err := out.Close(); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(h.Sum(nil)), nil
+}
+
+// hashFile returns the hash copyFile records for a file
+func hashFile(path string, info fs.FileInfo) (string, error) {
+	if info.Mode()&fs.ModeSymlink != 0 {
+		link, err := os.Readlink(path)
+		if err != nil {
+			return "", err
+		}
+		return hashString("symlink:" + link), nil
+	}
+	f, err := os.Open(path)
+	if err != nil {
+		return "", err
+	}
+	defer f.Close()
+	h := sha256.New()
+	if _, err := io.Copy(h, f); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(h.Sum(nil)), nil
+}
+
+// hashIfExists returns the hash of a file, or an empty string when it does
+// not exist
+func hashIfExists(path string) (string, error) {
+	info, err := os.Lstat(path)
+	if os.IsNotExist(err) {
+		return "", nil
+	}
+	if err != nil {
+		return "", err
+	}
+	return hashFile(path, info)
+}
+
+func hashString(s string) string {
+	sum := sha256.Sum256([]byte(s))
+	return hex.EncodeToString(sum[:])
+}
@@ -0,0 +1,177 @@
+package isolation
+
+import (
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"sync"
+)
+
+// WorktreePool reuses the worktrees of finished tasks for the next ones.
+// Checking out another branch in an existing worktree only touches the files
+// that differ, which is much faster than creating a worktree per task in
+// large repositories.
+type WorktreePool struct {
+	basePath string
+	mu       sync.Mutex
+	slots    map[string]bool // Worktree path -> in use by a task
+	next     int
+}
+
+// NewWorktreePool creates a pool for the repository at basePath, taking over
+// the pooled worktrees an interrupted run left behind
+func NewWorktreePool(basePath string) *WorktreePool {
+	p := &WorktreePool{basePath: basePath, slots: make(map[string]bool)}
+	paths, _ := filepath.Glob(p.slotPath("*"))
+	for _, path := range paths {
+		if info, err := os.Stat(path); err == nil && info.IsDir() {
+			p.slots[path] = false
+		}
+	}
+	return p
+}
+
+// slotPath returns the path of a pooled worktree, named so that run cleanup
+// removes it like the worktrees of single tasks
+func (p *WorktreePool) slotPath(name string) string {
+	return filepath.Join(p.basePath, ".hermes", "worktrees", "wt-pool-"+name)
+}
+
+// Attach reuses the idle pooled worktree an interrupted run left on the
+// task branch, keeping its changes. It returns false when there is none.
+func (p *WorktreePool) Attach(w *Workspace) bool {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	for path, inUse := range p.slots {
+		if !inUse && worktreeBranch(path) == w.Branch {
+			p.slots[path] = true
+			w.WorkPath = path
+			return true
+		}
+	}
+	return false
+}
+
+// Acquire sets up the workspace in an idle pooled worktree, checking out the
+// task branch and removing everything a previous task left, or in a new
+// pooled worktree when all are in use
+func (p *WorktreePool) Acquire(w *Workspace) error {
+	baseBranch, err := w.getCurrentBranch()
+	if err != nil {
+		return fmt.Errorf("failed to get current branch: %w", err)
+	}
+	if err := w.createBranch(baseBranch); err != nil && !strings.Contains(err.Error(), "already exists") {
+		return fmt.Errorf("failed to create branch: %w", err)
+	}
+
+	p.mu.Lock()
+	path := ""
+	for slot, inUse := range p.slots {
+		// Uncommitted changes may belong to a task waiting to be reattached
+		if !inUse && worktreeClean(slot) {
+			path = slot
+			break
+		}
+	}
+	for path == "" {
+		p.next++
+		candidate := p.slotPath(fmt.Sprint(p.next))
+		if _, err := os.Stat(candidate); os.IsNotExist(err) {
+			path = candidate
+		}
+	}
+	p.slots[path] = true
+	p.mu.Unlock()
+	w.WorkPath = path
+
+	if worktreeBranch(path) != "" {
+		err := runIn(path, "checkout", "-f", w.Branch)
+		if err == nil {
+			err = runIn(path, "clean", "-ffdx")
+		}
+		if err != nil {
+			p.mu.Lock()
+			p.slots[path] = false
+			p.mu.Unlock()
+			return fmt.Errorf("failed to reuse worktree: %w", err)
+		}
+		return nil
+	}
+	if err := runIn(w.BasePath, "worktree", "add", path, w.Branch); err != nil {
+		p.mu.Lock()
+		delete(p.slots, path)
+		p.mu.Unlock()
+		return fmt.Errorf("failed to create worktree: %w", err)
+	}
+	return nil
+}
+
+// Release returns the worktree of a workspace to the pool. It is detached
+// from the task branch so the branch can be deleted or checked out elsewhere.
+// Worktrees that cannot be reset are removed.
+func (p *WorktreePool) Release(w *Workspace) error {
+	p.mu.Lock()
+	_, pooled := p.slots[w.WorkPath]
+	p.mu.Unlock()
+	if !pooled {
+		return w.Cleanup()
+	}
+
+	err := runIn(w.WorkPath, "checkout", "-f", "--detach")
+	if err == nil {
+		err = runIn(w.WorkPath, "clean", "-ffdx")
+	}
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if err != nil {
+		delete(p.slots, w.WorkPath)
+		return w.Cleanup()
+	}
+	p.slots[w.WorkPath] = false
+	return nil
+}
+
+// isWorktree reports whether path is the root of a worktree rather than a
+// plain directory git would resolve to the enclosing repository
+func isWorktree(path string) bool {
+	_, err := os.Stat(filepath.Join(path, ".git"))
+	return err == nil
+}
+
+// worktreeBranch returns the branch checked out in a worktree, "HEAD" when
+// detached and empty when it is no worktree
+func worktreeBranch(path string) string {
+	if !isWorktree(path) {
+		return ""
+	}
+	cmd := exec.Command("git", "rev-parse", "--abbrev-ref", "HEAD")
+	cmd.Dir = path
+	output, err := cmd.Output()
+	if err != nil {
+		return ""
+	}
+	return strings.TrimSpace(string(output))
+}
+
+// worktreeClean reports whether a worktree has no uncommitted changes
+func worktreeClean(path string) bool {
+	if !isWorktree(path) {
+		return false
+	}
+	cmd := exec.Command("git", "status", "--porcelain")
+	cmd.Dir = path
+	output, err := cmd.Output()
+	return err == nil && strings.TrimSpace(string(output)) == ""
+}
+
+// runIn runs a git command in dir
+func runIn(dir string, args ...string) error {
+	cmd := exec.Command("git", args...)
+	cmd.Dir = dir
+	if output, err := cmd.CombinedOutput(); err != nil {
+		return fmt.Errorf("%w: %s", err, strings.TrimSpace(string(output)))
+	}
+	return nil
+}
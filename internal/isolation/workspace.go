@@ -5,9 +5,11 @@ import (
 	"os"
 	"os/exec"
 	"path/filepath"
+	"sort"
 	"strings"
 
 	"hermes/internal/git"
+	"hermes/internal/task"
 )
 
 // Workspace represents an isolated workspace for a task
@@ -17,19 +19,26 @@ type Workspace struct {
 	BasePath string // Original repository path
 	WorkPath string // Isolated workspace path (git worktree)
 	Branch   string
+	// Backend is how the workspace is isolated, BackendWorktree unless set
+	Backend string
+	// StageExcludes lists paths never staged when committing workspace changes
+	StageExcludes []string
+	// Signing signs the commits made in the workspace
+	Signing git.Signing
 }
 
 // NewWorkspace creates a new workspace configuration
 func NewWorkspace(taskID, basePath string) *Workspace {
 	branchName := fmt.Sprintf("task/%s", taskID)
 	// Create worktree in project directory instead of temp
-	workPath := filepath.Join(basePath, ".hermes", "worktrees", fmt.Sprintf("wt-%s", taskID))
+	workPath := filepath.Join(basePath, ".hermes", "worktrees", fmt.Sprintf("wt-%s", task.FileSafeID(taskID)))
 
 	return &Workspace{
-		TaskID:   taskID,
-		BasePath: basePath,
-		WorkPath: workPath,
-		Branch:   branchName,
+		TaskID:        taskID,
+		BasePath:      basePath,
+		WorkPath:      workPath,
+		Branch:        branchName,
+		StageExcludes: []string{".hermes"},
 	}
 }
 
@@ -42,19 +51,25 @@ func NewWorkspaceWithName(taskID, taskName, basePath string) *Workspace {
 		branchName = fmt.Sprintf("task/%s", taskID)
 	}
 	// Create worktree in project directory instead of temp
-	workPath := filepath.Join(basePath, ".hermes", "worktrees", fmt.Sprintf("wt-%s", taskID))
+	workPath := filepath.Join(basePath, ".hermes", "worktrees", fmt.Sprintf("wt-%s", task.FileSafeID(taskID)))
 
 	return &Workspace{
-		TaskID:   taskID,
-		TaskName: taskName,
-		BasePath: basePath,
-		WorkPath: workPath,
-		Branch:   branchName,
+		TaskID:        taskID,
+		TaskName:      taskName,
+		BasePath:      basePath,
+		WorkPath:      workPath,
+		Branch:        branchName,
+		StageExcludes: []string{".hermes"},
 	}
 }
 
-// Setup creates the isolated workspace using git worktree
+// Setup creates the isolated workspace using git worktree, or a copy of the
+// project with the copy backend
 func (w *Workspace) Setup() error {
+	if w.Backend == BackendCopy {
+		return w.setupCopy()
+	}
+
 	// Check if worktree already exists
 	if _, err := os.Stat(w.WorkPath); err == nil {
 		// Remove existing worktree
@@ -88,6 +103,26 @@ func (w *Workspace) Setup() error {
 	return nil
 }
 
+// Attach reuses the worktree an interrupted run left for the task, keeping
+// the changes made so far. It returns false when there is no worktree on the
+// task branch and Setup has to create one.
+func (w *Workspace) Attach() bool {
+	if _, err := os.Stat(w.WorkPath); err != nil {
+		return false
+	}
+	if w.Backend == BackendCopy {
+		_, err := os.Stat(w.manifestPath())
+		return err == nil
+	}
+	cmd := exec.Command("git", "rev-parse", "--abbrev-ref", "HEAD")
+	cmd.Dir = w.WorkPath
+	output, err := cmd.Output()
+	if err != nil {
+		return false
+	}
+	return strings.TrimSpace(string(output)) == w.Branch
+}
+
 // SetupShared creates a workspace using the shared repository (no isolation)
 // This is faster but doesn't provide isolation
 func (w *Workspace) SetupShared() error {
@@ -97,6 +132,16 @@ func (w *Workspace) SetupShared() error {
 
 // Cleanup removes the isolated workspace
 func (w *Workspace) Cleanup() error {
+	if w.Backend == BackendCopy {
+		if err := os.RemoveAll(w.WorkPath); err != nil {
+			return fmt.Errorf("failed to remove copy: %w", err)
+		}
+		if err := os.Remove(w.manifestPath()); err != nil && !os.IsNotExist(err) {
+			return err
+		}
+		return nil
+	}
+
 	// Remove worktree
 	cmd := exec.Command("git", "worktree", "remove", w.WorkPath, "--force")
 	cmd.Dir = w.BasePath
@@ -119,6 +164,9 @@ func (w *Workspace) Cleanup() error {
 
 // CleanupBranch removes the task branch
 func (w *Workspace) CleanupBranch() error {
+	if w.Backend == BackendCopy {
+		return nil // Copies have no branch
+	}
 	cmd := exec.Command("git", "branch", "-D", w.Branch)
 	cmd.Dir = w.BasePath
 	if output, err := cmd.CombinedOutput(); err != nil {
@@ -129,6 +177,19 @@ func (w *Workspace) CleanupBranch() error {
 
 // GetChanges returns the files changed in this workspace
 func (w *Workspace) GetChanges() ([]string, error) {
+	if w.Backend == BackendCopy {
+		changes, err := w.copyChanges()
+		if err != nil {
+			return nil, fmt.Errorf("failed to get changes: %w", err)
+		}
+		var files []string
+		for file := range changes {
+			files = append(files, file)
+		}
+		sort.Strings(files)
+		return files, nil
+	}
+
 	cmd := exec.Command("git", "diff", "--name-only", "HEAD")
 	cmd.Dir = w.WorkPath
 	output, err := cmd.CombinedOutput()
@@ -148,6 +209,9 @@ func (w *Workspace) GetChanges() ([]string, error) {
 
 // GetDiff returns the git diff for changes in this workspace
 func (w *Workspace) GetDiff() (string, error) {
+	if w.Backend == BackendCopy {
+		return "", fmt.Errorf("diff is not available for %s workspaces", w.Backend)
+	}
 	cmd := exec.Command("git", "diff", "HEAD")
 	cmd.Dir = w.WorkPath
 	output, err := cmd.CombinedOutput()
@@ -157,10 +221,16 @@ func (w *Workspace) GetDiff() (string, error) {
 	return string(output), nil
 }
 
-// CommitChanges commits all changes in the workspace
+// CommitChanges commits all changes in the workspace. Copies are not
+// committed; ApplyChanges brings their changes into the project.
 func (w *Workspace) CommitChanges(message string) error {
-	// Stage all changes
-	cmd := exec.Command("git", "add", "-A")
+	if w.Backend == BackendCopy {
+		return nil
+	}
+
+	// Stage all changes except Hermes state
+	args := append([]string{"add", "-A", "--", "."}, git.ExcludePathspecs(w.StageExcludes)...)
+	cmd := exec.Command("git", args...)
 	cmd.Dir = w.WorkPath
 	if output, err := cmd.CombinedOutput(); err != nil {
 		return fmt.Errorf("failed to stage changes: %w: %s", err, string(output))
@@ -175,8 +245,9 @@ func (w *Workspace) CommitChanges(message string) error {
 	}
 
 	// Commit
-	cmd = exec.Command("git", "commit", "-m", message)
+	cmd = exec.Command("git", append(append([]string{"commit"}, w.Signing.Args()...), "-m", message)...)
 	cmd.Dir = w.WorkPath
+	w.Signing.Apply(cmd)
 	if output, err := cmd.CombinedOutput(); err != nil {
 		return fmt.Errorf("failed to commit: %w: %s", err, string(output))
 	}
@@ -186,6 +257,9 @@ func (w *Workspace) CommitChanges(message string) error {
 
 // PushChanges pushes changes to remote
 func (w *Workspace) PushChanges() error {
+	if w.Backend == BackendCopy {
+		return fmt.Errorf("cannot push a %s workspace", w.Backend)
+	}
 	cmd := exec.Command("git", "push", "-u", "origin", w.Branch)
 	cmd.Dir = w.WorkPath
 	if output, err := cmd.CombinedOutput(); err != nil {
@@ -194,8 +268,12 @@ func (w *Workspace) PushChanges() error {
 	return nil
 }
 
-// HasUncommittedChanges returns true if there are uncommitted changes
+// HasUncommittedChanges returns true if there are uncommitted changes,
+// which copies never have
 func (w *Workspace) HasUncommittedChanges() bool {
+	if w.Backend == BackendCopy {
+		return false
+	}
 	cmd := exec.Command("git", "status", "--porcelain")
 	cmd.Dir = w.WorkPath
 	output, err := cmd.CombinedOutput()
@@ -226,8 +304,11 @@ func (w *Workspace) createBranch(baseBranch string) error {
 	return nil
 }
 
-// GetBranch returns the branch name for this workspace
+// GetBranch returns the branch name for this workspace, empty for copies
 func (w *Workspace) GetBranch() string {
+	if w.Backend == BackendCopy {
+		return ""
+	}
 	return w.Branch
 }
 
@@ -236,7 +317,7 @@ func (w *Workspace) GetWorkPath() string {
 	return w.WorkPath
 }
 
-// IsIsolated returns true if this workspace is isolated (using worktree)
+// IsIsolated returns true if this workspace is isolated (using worktree or copy)
 func (w *Workspace) IsIsolated() bool {
 	return w.WorkPath != w.BasePath
 }
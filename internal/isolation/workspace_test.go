@@ -0,0 +1,272 @@
+package isolation
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"hermes/internal/git"
+)
+
+func TestCopyWorkspace(t *testing.T) {
+	base := t.TempDir()
+	files := map[string]string{
+		"main.go":           "package main\n",
+		"pkg/util.go":       "package pkg\n",
+		"old.txt":           "remove me\n",
+		".hermes/PROMPT.md": "# Prompt\n",
+		".hermes/state.txt": "not copied\n",
+	}
+	for name, content := range files {
+		path := filepath.Join(base, name)
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	if ResolveBackend(BackendAuto, base) != BackendCopy {
+		t.Fatal("expected the copy backend outside a git repository")
+	}
+	w := NewWorkspaceWithName("T001", "Add util", base)
+	w.Backend = BackendCopy
+	if err := w.Setup(); err != nil {
+		t.Fatal(err)
+	}
+	if !w.IsIsolated() || w.GetBranch() != "" {
+		t.Errorf("expected an isolated workspace without branch, got %s on %q", w.GetWorkPath(), w.GetBranch())
+	}
+	if _, err := os.Stat(filepath.Join(w.WorkPath, ".hermes", "state.txt")); !os.IsNotExist(err) {
+		t.Error("expected .hermes state not to be copied")
+	}
+	if _, err := os.Stat(filepath.Join(w.WorkPath, ".hermes", "PROMPT.md")); err != nil {
+		t.Error("expected the prompt to be copied")
+	}
+
+	// Change the copy the way a task would
+	os.WriteFile(filepath.Join(w.WorkPath, "pkg", "util.go"), []byte("package pkg\n\nfunc Util() {}\n"), 0644)
+	os.WriteFile(filepath.Join(w.WorkPath, "new.go"), []byte("package main\n"), 0644)
+	os.Remove(filepath.Join(w.WorkPath, "old.txt"))
+	os.WriteFile(filepath.Join(w.WorkPath, ".hermes", "PROMPT.md"), []byte("# Prompt\n\n## Task\n"), 0644)
+
+	changes, err := w.GetChanges()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if strings.Join(changes, ",") != "new.go,old.txt,pkg/util.go" {
+		t.Errorf("unexpected changes: %v", changes)
+	}
+
+	// A resumed run picks the copy up again
+	resumed := NewWorkspaceWithName("T001", "Add util", base)
+	resumed.Backend = BackendCopy
+	if !resumed.Attach() {
+		t.Error("expected to reattach to the copy")
+	}
+
+	applied, err := w.ApplyChanges()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(applied) != 3 {
+		t.Errorf("expected 3 applied files, got %v", applied)
+	}
+	if data, _ := os.ReadFile(filepath.Join(base, "pkg", "util.go")); !strings.Contains(string(data), "func Util") {
+		t.Error("expected the changed file in the project")
+	}
+	if _, err := os.Stat(filepath.Join(base, "old.txt")); !os.IsNotExist(err) {
+		t.Error("expected the deleted file to be removed from the project")
+	}
+	if data, _ := os.ReadFile(filepath.Join(base, ".hermes", "PROMPT.md")); string(data) != "# Prompt\n" {
+		t.Error("expected the project prompt to be left alone")
+	}
+
+	if err := CleanupCopies(base); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := os.Stat(w.WorkPath); !os.IsNotExist(err) {
+		t.Error("expected the copy to be removed")
+	}
+}
+
+func TestWorktreePool(t *testing.T) {
+	base := t.TempDir()
+	for _, args := range [][]string{
+		{"init"},
+		{"config", "user.email", "test@test.com"},
+		{"config", "user.name", "Test User"},
+		{"commit", "--allow-empty", "-m", "Initial commit"},
+	} {
+		if err := runIn(base, args...); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	pool := NewWorktreePool(base)
+	first := NewWorkspaceWithName("T001", "First", base)
+	if err := pool.Acquire(first); err != nil {
+		t.Fatal(err)
+	}
+	path := first.GetWorkPath()
+	if worktreeBranch(path) != first.Branch {
+		t.Fatalf("expected %s checked out in %s", first.Branch, path)
+	}
+	os.WriteFile(filepath.Join(path, "left-over.txt"), []byte("untracked\n"), 0644)
+	if err := first.CommitChanges("Complete task T001"); err != nil {
+		t.Fatal(err)
+	}
+	os.WriteFile(filepath.Join(path, "scratch.txt"), []byte("untracked\n"), 0644)
+	if err := pool.Release(first); err != nil {
+		t.Fatal(err)
+	}
+
+	// The next task reuses the worktree without anything the first one left
+	second := NewWorkspaceWithName("T002", "Second", base)
+	if err := pool.Acquire(second); err != nil {
+		t.Fatal(err)
+	}
+	if second.GetWorkPath() != path || worktreeBranch(path) != second.Branch {
+		t.Fatalf("expected %s to be reused for %s, got %s", path, second.Branch, second.GetWorkPath())
+	}
+	for _, name := range []string{"left-over.txt", "scratch.txt"} {
+		if _, err := os.Stat(filepath.Join(path, name)); !os.IsNotExist(err) {
+			t.Errorf("expected %s of the previous task to be gone", name)
+		}
+	}
+
+	// A task acquiring while the worktree is in use gets a new one
+	third := NewWorkspaceWithName("T003", "Third", base)
+	if err := pool.Acquire(third); err != nil {
+		t.Fatal(err)
+	}
+	if third.GetWorkPath() == path {
+		t.Error("expected a second worktree while the first is in use")
+	}
+
+	// An interrupted run's worktree is found again by its branch
+	os.WriteFile(filepath.Join(third.GetWorkPath(), "wip.txt"), []byte("work in progress\n"), 0644)
+	resumed := NewWorktreePool(base)
+	again := NewWorkspaceWithName("T003", "Third", base)
+	if !resumed.Attach(again) || again.GetWorkPath() != third.GetWorkPath() {
+		t.Errorf("expected to reattach %s, got %s", third.GetWorkPath(), again.GetWorkPath())
+	}
+}
+
+func TestCopyWorkspaceConflict(t *testing.T) {
+	base := t.TempDir()
+	for name, content := range map[string]string{"main.go": "package main\n", "util.go": "package main\n"} {
+		os.WriteFile(filepath.Join(base, name), []byte(content), 0644)
+	}
+
+	w := NewWorkspace("T001", base)
+	w.Backend = BackendCopy
+	if err := w.Setup(); err != nil {
+		t.Fatal(err)
+	}
+	defer CleanupCopies(base)
+
+	os.WriteFile(filepath.Join(w.WorkPath, "main.go"), []byte("package main\n\nfunc main() {}\n"), 0644)
+	os.WriteFile(filepath.Join(w.WorkPath, "util.go"), []byte("package main\n\nfunc util() {}\n"), 0644)
+	// The project changed main.go meanwhile and made the same change to util.go
+	os.WriteFile(filepath.Join(base, "main.go"), []byte("package main\n\nfunc other() {}\n"), 0644)
+	os.WriteFile(filepath.Join(base, "util.go"), []byte("package main\n\nfunc util() {}\n"), 0644)
+
+	_, err := w.ApplyChanges()
+	conflict, ok := err.(*ConflictError)
+	if !ok {
+		t.Fatalf("expected a conflict, got %v", err)
+	}
+	if strings.Join(conflict.Files, ",") != "main.go" {
+		t.Errorf("expected only main.go to conflict, got %v", conflict.Files)
+	}
+	if data, _ := os.ReadFile(filepath.Join(base, "main.go")); !strings.Contains(string(data), "func other") {
+		t.Error("expected the project change to be kept")
+	}
+}
+
+func TestWorkspaceCommitSigned(t *testing.T) {
+	base := t.TempDir()
+	for _, args := range [][]string{
+		{"init"},
+		{"config", "user.email", "test@test.com"},
+		{"config", "user.name", "Test User"},
+		{"commit", "--allow-empty", "-m", "Initial commit"},
+	} {
+		if err := runIn(base, args...); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	w := NewWorkspace("T001", base)
+	if err := w.Setup(); err != nil {
+		t.Fatal(err)
+	}
+	defer w.Cleanup()
+	os.WriteFile(filepath.Join(w.GetWorkPath(), "main.go"), []byte("package main\n"), 0644)
+
+	// No key exists, so the commit only fails when it is signed
+	w.Signing = git.Signing{Enabled: true, Key: "hermes-test-missing-key"}
+	if err := w.CommitChanges("Complete task T001"); err == nil || !strings.Contains(err.Error(), "gpg") {
+		t.Fatalf("expected the commit to be signed, got %v", err)
+	}
+	w.Signing = git.Signing{}
+	if err := w.CommitChanges("Complete task T001"); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestWorkspaceAttach(t *testing.T) {
+	base := t.TempDir()
+	for _, args := range [][]string{
+		{"init"},
+		{"config", "user.email", "test@test.com"},
+		{"config", "user.name", "Test User"},
+		{"commit", "--allow-empty", "-m", "Initial commit"},
+	} {
+		if err := runIn(base, args...); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	w := NewWorkspace("T001", base)
+	if w.Attach() {
+		t.Fatal("expected nothing to attach to before the worktree exists")
+	}
+	if err := w.Setup(); err != nil {
+		t.Fatal(err)
+	}
+	defer w.Cleanup()
+	os.WriteFile(filepath.Join(w.GetWorkPath(), "wip.txt"), []byte("work in progress\n"), 0644)
+
+	// A new workspace of the same task finds the worktree with its changes
+	again := NewWorkspace("T001", base)
+	if !again.Attach() {
+		t.Fatalf("expected to reattach %s", w.GetWorkPath())
+	}
+	if _, err := os.Stat(filepath.Join(again.GetWorkPath(), "wip.txt")); err != nil {
+		t.Error("expected the changes of the interrupted run to be kept")
+	}
+
+	// A worktree on another branch is not the task's
+	if err := runIn(w.GetWorkPath(), "checkout", "-q", "-b", "other"); err != nil {
+		t.Fatal(err)
+	}
+	if NewWorkspace("T001", base).Attach() {
+		t.Error("expected a worktree on another branch not to be reattached")
+	}
+
+	c := NewWorkspace("T002", base)
+	c.Backend = BackendCopy
+	if err := c.Setup(); err != nil {
+		t.Fatal(err)
+	}
+	defer CleanupCopies(base)
+	copyAgain := NewWorkspace("T002", base)
+	copyAgain.Backend = BackendCopy
+	if !copyAgain.Attach() {
+		t.Error("expected to reattach the copy of the interrupted run")
+	}
+}
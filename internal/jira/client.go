@@ -0,0 +1,179 @@
+package jira
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+)
+
+// issueFields are the fields requested for imported issues
+const issueFields = "summary,description,priority,status"
+
+// Issue is a Jira issue with the fields Hermes uses
+type Issue struct {
+	Key    string `json:"key"`
+	Fields struct {
+		Summary     string `json:"summary"`
+		Description string `json:"description"`
+		Priority    *struct {
+			Name string `json:"name"`
+		} `json:"priority"`
+		Status struct {
+			Name           string `json:"name"`
+			StatusCategory struct {
+				Key string `json:"key"` // "new", "indeterminate" or "done"
+			} `json:"statusCategory"`
+		} `json:"status"`
+	} `json:"fields"`
+}
+
+// transition is a workflow transition available for an issue
+type transition struct {
+	ID   string `json:"id"`
+	Name string `json:"name"`
+	To   struct {
+		Name string `json:"name"`
+	} `json:"to"`
+}
+
+// Client talks to the Jira REST API (v2)
+type Client struct {
+	baseURL    string
+	email      string
+	token      string
+	httpClient *http.Client
+}
+
+// NewClient creates a new Jira client. With an email, the token is used for
+// basic auth as on Jira Cloud; without one it is sent as a personal access
+// token as on Jira Server and Data Center.
+func NewClient(baseURL, email, token string) *Client {
+	return &Client{
+		baseURL:    strings.TrimRight(baseURL, "/"),
+		email:      email,
+		token:      token,
+		httpClient: &http.Client{Timeout: 15 * time.Second},
+	}
+}
+
+// Search returns up to max issues matching a JQL query
+func (c *Client) Search(jql string, max int) ([]Issue, error) {
+	query := url.Values{}
+	query.Set("jql", jql)
+	query.Set("fields", issueFields)
+	query.Set("maxResults", fmt.Sprintf("%d", max))
+
+	var result struct {
+		Issues []Issue `json:"issues"`
+	}
+	// Jira Cloud replaced /search with /search/jql; Server and Data Center only have /search
+	err := c.do("GET", "/rest/api/2/search/jql?"+query.Encode(), nil, &result)
+	if apiErr, ok := err.(*apiError); ok && (apiErr.status == http.StatusNotFound || apiErr.status == http.StatusGone) {
+		err = c.do("GET", "/rest/api/2/search?"+query.Encode(), nil, &result)
+	}
+	if err != nil {
+		return nil, err
+	}
+	return result.Issues, nil
+}
+
+// GetIssue returns a single issue
+func (c *Client) GetIssue(key string) (*Issue, error) {
+	var issue Issue
+	if err := c.do("GET", "/rest/api/2/issue/"+url.PathEscape(key)+"?fields="+issueFields, nil, &issue); err != nil {
+		return nil, err
+	}
+	return &issue, nil
+}
+
+// TransitionTo moves an issue to the status with the given name, using the
+// transition leading there. Issues already in that status are left alone.
+func (c *Client) TransitionTo(key, status string) error {
+	path := "/rest/api/2/issue/" + url.PathEscape(key) + "/transitions"
+	var result struct {
+		Transitions []transition `json:"transitions"`
+	}
+	if err := c.do("GET", path, nil, &result); err != nil {
+		return err
+	}
+
+	for _, t := range result.Transitions {
+		if strings.EqualFold(t.To.Name, status) || strings.EqualFold(t.Name, status) {
+			body := map[string]any{"transition": map[string]string{"id": t.ID}}
+			return c.do("POST", path, body, nil)
+		}
+	}
+
+	issue, err := c.GetIssue(key)
+	if err != nil {
+		return err
+	}
+	if strings.EqualFold(issue.Fields.Status.Name, status) {
+		return nil
+	}
+	return fmt.Errorf("%s has no transition from %q to %q", key, issue.Fields.Status.Name, status)
+}
+
+// apiError is a failed Jira API request
+type apiError struct {
+	status int
+	msg    string
+}
+
+func (e *apiError) Error() string {
+	return e.msg
+}
+
+// do sends a request and decodes the JSON response into out, if given
+func (c *Client) do(method, path string, body, out any) error {
+	var reader io.Reader
+	if body != nil {
+		data, err := json.Marshal(body)
+		if err != nil {
+			return err
+		}
+		reader = bytes.NewReader(data)
+	}
+
+	req, err := http.NewRequest(method, c.baseURL+path, reader)
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Accept", "application/json")
+	if body != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+	if c.email != "" {
+		req.SetBasicAuth(c.email, c.token)
+	} else {
+		req.Header.Set("Authorization", "Bearer "+c.token)
+	}
+
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return fmt.Errorf("Jira request failed: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		var result struct {
+			ErrorMessages []string `json:"errorMessages"`
+		}
+		json.NewDecoder(resp.Body).Decode(&result)
+		msg := fmt.Sprintf("Jira API error: %s", resp.Status)
+		if len(result.ErrorMessages) > 0 {
+			msg += ": " + strings.Join(result.ErrorMessages, "; ")
+		}
+		return &apiError{status: resp.StatusCode, msg: msg}
+	}
+
+	if out == nil || resp.StatusCode == http.StatusNoContent {
+		return nil
+	}
+	return json.NewDecoder(resp.Body).Decode(out)
+}
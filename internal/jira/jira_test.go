@@ -0,0 +1,102 @@
+package jira
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"hermes/internal/task"
+)
+
+// newTestServer serves a single issue APP-1 in "To Do" with a transition to "In Progress"
+func newTestServer(t *testing.T, transitioned *string) *httptest.Server {
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if user, pass, ok := r.BasicAuth(); !ok || user != "dev@example.com" || pass != "secret" {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		switch {
+		case r.URL.Path == "/rest/api/2/search/jql":
+			// Behave like Jira Server, which only knows /search
+			w.WriteHeader(http.StatusNotFound)
+		case r.URL.Path == "/rest/api/2/search":
+			if r.URL.Query().Get("jql") != "project = APP" {
+				w.WriteHeader(http.StatusBadRequest)
+				return
+			}
+			w.Write([]byte(`{"issues": [{"key": "APP-1", "fields": {
+				"summary": "Add login", "description": "Users sign in with email",
+				"priority": {"name": "High"},
+				"status": {"name": "To Do", "statusCategory": {"key": "new"}}}}]}`))
+		case r.URL.Path == "/rest/api/2/issue/APP-1/transitions" && r.Method == "GET":
+			w.Write([]byte(`{"transitions": [{"id": "21", "name": "Start", "to": {"name": "In Progress"}}]}`))
+		case r.URL.Path == "/rest/api/2/issue/APP-1/transitions" && r.Method == "POST":
+			var body struct {
+				Transition struct {
+					ID string `json:"id"`
+				} `json:"transition"`
+			}
+			json.NewDecoder(r.Body).Decode(&body)
+			*transitioned = body.Transition.ID
+			w.WriteHeader(http.StatusNoContent)
+		case r.URL.Path == "/rest/api/2/issue/APP-1":
+			w.Write([]byte(`{"key": "APP-1", "fields": {"status": {"name": "To Do"}}}`))
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+}
+
+func TestSearchAndToTask(t *testing.T) {
+	var transitioned string
+	server := newTestServer(t, &transitioned)
+	defer server.Close()
+
+	issues, err := NewClient(server.URL, "dev@example.com", "secret").Search("project = APP", 10)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(issues) != 1 || issues[0].Key != "APP-1" {
+		t.Fatalf("unexpected issues %+v", issues)
+	}
+
+	got := ToTask(&issues[0], "T004", "F002")
+	if got.ID != "T004" || got.FeatureID != "F002" || got.JiraKey != "APP-1" || got.Name != "Add login" {
+		t.Errorf("unexpected task %+v", got)
+	}
+	if got.Priority != task.PriorityP2 || got.Status != task.StatusNotStarted || got.Description != "Users sign in with email" {
+		t.Errorf("unexpected mapping %+v", got)
+	}
+}
+
+func TestSyncerPush(t *testing.T) {
+	var transitioned string
+	server := newTestServer(t, &transitioned)
+	defer server.Close()
+
+	syncer := NewSyncer(NewClient(server.URL, "dev@example.com", "secret"), map[string]string{"blocked": "On Hold"})
+
+	if err := syncer.Push(&task.Task{ID: "T001", JiraKey: "APP-1", Status: task.StatusInProgress}); err != nil {
+		t.Fatal(err)
+	}
+	if transitioned != "21" {
+		t.Errorf("expected transition 21, got %q", transitioned)
+	}
+
+	// Tasks without an issue and unmapped statuses are ignored
+	if err := syncer.Push(&task.Task{ID: "T002", Status: task.StatusInProgress}); err != nil {
+		t.Error(err)
+	}
+	if err := syncer.Push(&task.Task{ID: "T001", JiraKey: "APP-1", Status: task.StatusNotStarted}); err != nil {
+		t.Error(err)
+	}
+
+	// The override replaces the default "Blocked" status, which has no transition
+	if err := syncer.Push(&task.Task{ID: "T001", JiraKey: "APP-1", Status: task.StatusBlocked}); err == nil {
+		t.Error("expected an error for a status without a transition")
+	}
+	if syncer.transitions[task.StatusBlocked] != "On Hold" {
+		t.Errorf("expected the override to apply, got %q", syncer.transitions[task.StatusBlocked])
+	}
+}
@@ -0,0 +1,116 @@
+package jira
+
+import (
+	"fmt"
+	"strings"
+
+	"hermes/internal/task"
+)
+
+// DefaultTransitions maps task statuses to the Jira statuses of the default workflow
+var DefaultTransitions = map[task.Status]string{
+	task.StatusInProgress: "In Progress",
+	task.StatusCompleted:  "Done",
+	task.StatusBlocked:    "Blocked",
+}
+
+// Syncer pushes task status changes to the Jira issues tasks were imported from
+type Syncer struct {
+	client      *Client
+	transitions map[task.Status]string
+	onError     func(taskID string, err error)
+}
+
+// NewSyncer creates a syncer. overrides maps task statuses (e.g. "COMPLETED")
+// to Jira status names and replaces the defaults for those statuses.
+func NewSyncer(client *Client, overrides map[string]string) *Syncer {
+	transitions := make(map[task.Status]string)
+	for status, name := range DefaultTransitions {
+		transitions[status] = name
+	}
+	for status, name := range overrides {
+		transitions[task.Status(strings.ToUpper(status))] = name
+	}
+	return &Syncer{client: client, transitions: transitions}
+}
+
+// SetErrorHandler sets the function called when pushing a status change fails.
+// Status updates never fail because of Jira, so errors are only reported.
+func (s *Syncer) SetErrorHandler(fn func(taskID string, err error)) {
+	s.onError = fn
+}
+
+// Push moves the Jira issue of a task to the status matching the task status.
+// Tasks not imported from Jira and statuses without a mapping are ignored.
+func (s *Syncer) Push(t *task.Task) error {
+	if t.JiraKey == "" {
+		return nil
+	}
+	target, ok := s.transitions[t.Status]
+	if !ok || target == "" {
+		return nil
+	}
+	if err := s.client.TransitionTo(t.JiraKey, target); err != nil {
+		return fmt.Errorf("failed to move %s to %q: %w", t.JiraKey, target, err)
+	}
+	return nil
+}
+
+// HandleStatusChange is a task.StatusHook pushing the new status to Jira
+func (s *Syncer) HandleStatusChange(basePath, taskID string, status task.Status) {
+	t, err := task.NewReader(basePath).GetTaskByID(taskID)
+	if err != nil || t == nil {
+		return
+	}
+	t.Status = status
+	if err := s.Push(t); err != nil && s.onError != nil {
+		s.onError(taskID, err)
+	}
+}
+
+// ToTask converts an issue to a task of a feature
+func ToTask(issue *Issue, taskID, featureID string) *task.Task {
+	description := strings.TrimSpace(issue.Fields.Description)
+	if description == "" {
+		description = issue.Fields.Summary
+	}
+	priority := task.PriorityP3
+	if issue.Fields.Priority != nil {
+		priority = mapPriority(issue.Fields.Priority.Name)
+	}
+	return &task.Task{
+		ID:          taskID,
+		Name:        strings.TrimSpace(issue.Fields.Summary),
+		Status:      mapStatusCategory(issue.Fields.Status.StatusCategory.Key),
+		Priority:    priority,
+		Description: description,
+		FeatureID:   featureID,
+		JiraKey:     issue.Key,
+	}
+}
+
+// mapPriority maps the default Jira priorities to task priorities
+func mapPriority(name string) task.Priority {
+	switch strings.ToLower(name) {
+	case "highest", "blocker":
+		return task.PriorityP1
+	case "high", "critical":
+		return task.PriorityP2
+	case "low", "lowest", "minor", "trivial":
+		return task.PriorityP4
+	default:
+		return task.PriorityP3
+	}
+}
+
+// mapStatusCategory maps a Jira status category to a task status
+func mapStatusCategory(key string) task.Status {
+	switch key {
+	case "done":
+		return task.StatusCompleted
+	case "indeterminate":
+		return task.StatusInProgress
+	default:
+		return task.StatusNotStarted
+	}
+}
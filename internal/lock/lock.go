@@ -0,0 +1,208 @@
+package lock
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+)
+
+// StaleAfter is how long a lock may go without a heartbeat before it is
+// considered abandoned by a crashed process and taken over
+const StaleAfter = 30 * time.Second
+
+// heartbeatInterval is how often the holder refreshes the lock
+var heartbeatInterval = 10 * time.Second
+
+// Info describes the process holding the project lock
+type Info struct {
+	PID       int       `json:"pid"`
+	Host      string    `json:"host"`
+	Command   string    `json:"command"` // e.g. "run", "tui" or "serve"
+	StartTime time.Time `json:"startTime"`
+	Heartbeat time.Time `json:"heartbeat"`
+}
+
+// Stale returns true if the holder stopped refreshing the lock
+func (i *Info) Stale() bool {
+	return time.Since(i.Heartbeat) > StaleAfter
+}
+
+// HeldError is returned by Acquire when another process holds the lock
+type HeldError struct {
+	Holder *Info
+}
+
+func (e *HeldError) Error() string {
+	return fmt.Sprintf("another hermes %s (PID %d on %s) has been running in this project for %s; "+
+		"wait for it to finish or stop it first (a crashed process releases the lock %s after its last heartbeat)",
+		e.Holder.Command, e.Holder.PID, e.Holder.Host, time.Since(e.Holder.StartTime).Round(time.Second), StaleAfter)
+}
+
+// GetLockPath returns the path of the project lock
+func GetLockPath(basePath string) string {
+	return filepath.Join(basePath, ".hermes", "lock")
+}
+
+// Lock is the project lock held by this process. It is refreshed in the
+// background until released.
+type Lock struct {
+	path string
+	info Info
+	mu   sync.Mutex
+	stop chan struct{}
+	done chan struct{}
+	once sync.Once
+}
+
+// Acquire takes the project lock for command, taking over a stale lock left
+// by a crashed process. It returns a *HeldError if another process is running.
+func Acquire(basePath, command string) (*Lock, error) {
+	path := GetLockPath(basePath)
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return nil, err
+	}
+	host, _ := os.Hostname()
+	now := time.Now()
+	l := &Lock{
+		path: path,
+		info: Info{PID: os.Getpid(), Host: host, Command: command, StartTime: now, Heartbeat: now},
+		stop: make(chan struct{}),
+		done: make(chan struct{}),
+	}
+	data, err := json.MarshalIndent(&l.info, "", "  ")
+	if err != nil {
+		return nil, err
+	}
+
+	for attempt := 0; ; attempt++ {
+		file, err := os.OpenFile(path, os.O_CREATE|os.O_EXCL|os.O_WRONLY, 0644)
+		if err == nil {
+			_, err = file.Write(data)
+			if closeErr := file.Close(); err == nil {
+				err = closeErr
+			}
+			if err != nil {
+				os.Remove(path)
+				return nil, err
+			}
+			break
+		}
+		if !os.IsExist(err) {
+			return nil, err
+		}
+
+		holder, readErr := Read(basePath)
+		if readErr != nil {
+			if os.IsNotExist(readErr) {
+				continue // Released in the meantime
+			}
+			return nil, readErr
+		}
+		if !holder.Stale() || attempt > 0 {
+			return nil, &HeldError{Holder: holder}
+		}
+		if err := os.Remove(path); err != nil && !os.IsNotExist(err) {
+			return nil, err
+		}
+	}
+
+	go l.heartbeat()
+	return l, nil
+}
+
+// Read returns the current holder of the project lock. A lock that is being
+// written and cannot be parsed yet is reported with its modification time as
+// heartbeat.
+func Read(basePath string) (*Info, error) {
+	path := GetLockPath(basePath)
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	var info Info
+	if err := json.Unmarshal(data, &info); err != nil || info.Heartbeat.IsZero() {
+		stat, statErr := os.Stat(path)
+		if statErr != nil {
+			return nil, statErr
+		}
+		info = Info{Command: "unknown", StartTime: stat.ModTime(), Heartbeat: stat.ModTime()}
+	}
+	return &info, nil
+}
+
+// Info returns the lock as written by this process
+func (l *Lock) Info() Info {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	return l.info
+}
+
+// heartbeat refreshes the lock until it is released
+func (l *Lock) heartbeat() {
+	defer close(l.done)
+	ticker := time.NewTicker(heartbeatInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-l.stop:
+			return
+		case now := <-ticker.C:
+			l.mu.Lock()
+			l.info.Heartbeat = now
+			l.mu.Unlock()
+			l.write()
+		}
+	}
+}
+
+// write replaces the lock file atomically, unless another process took it over
+func (l *Lock) write() error {
+	if !l.owned() {
+		return fmt.Errorf("lock was taken over by another process")
+	}
+	info := l.Info()
+	data, err := json.MarshalIndent(&info, "", "  ")
+	if err != nil {
+		return err
+	}
+	tmp := fmt.Sprintf("%s.%d.tmp", l.path, l.info.PID)
+	if err := os.WriteFile(tmp, data, 0644); err != nil {
+		return err
+	}
+	return os.Rename(tmp, l.path)
+}
+
+// owned returns true if the lock file still belongs to this process
+func (l *Lock) owned() bool {
+	data, err := os.ReadFile(l.path)
+	if err != nil {
+		return false
+	}
+	var info Info
+	if err := json.Unmarshal(data, &info); err != nil {
+		return false
+	}
+	return info.PID == l.info.PID && info.Host == l.info.Host && info.StartTime.Equal(l.info.StartTime)
+}
+
+// Release stops the heartbeat and removes the lock. It is safe to call more
+// than once and on a nil lock.
+func (l *Lock) Release() error {
+	if l == nil {
+		return nil
+	}
+	var err error
+	l.once.Do(func() {
+		close(l.stop)
+		<-l.done
+		if l.owned() {
+			if removeErr := os.Remove(l.path); removeErr != nil && !os.IsNotExist(removeErr) {
+				err = removeErr
+			}
+		}
+	})
+	return err
+}
@@ -0,0 +1,97 @@
+package lock
+
+import (
+	"encoding/json"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestAcquireAndRelease(t *testing.T) {
+	dir := t.TempDir()
+
+	l, err := Acquire(dir, "run")
+	if err != nil {
+		t.Fatal(err)
+	}
+	info, err := Read(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if info.PID != os.Getpid() || info.Command != "run" || info.Stale() {
+		t.Errorf("unexpected lock holder %+v", info)
+	}
+
+	_, err = Acquire(dir, "tui")
+	var held *HeldError
+	if !errors.As(err, &held) || held.Holder.Command != "run" {
+		t.Fatalf("expected the second runner to be refused, got %v", err)
+	}
+
+	if err := l.Release(); err != nil {
+		t.Fatal(err)
+	}
+	if err := l.Release(); err != nil {
+		t.Errorf("expected a second release to be a no-op, got %v", err)
+	}
+	if _, err := os.Stat(GetLockPath(dir)); !os.IsNotExist(err) {
+		t.Error("expected the lock to be removed")
+	}
+
+	l2, err := Acquire(dir, "tui")
+	if err != nil {
+		t.Fatalf("expected the lock to be free after release, got %v", err)
+	}
+	l2.Release()
+}
+
+func TestAcquireTakesOverStaleLock(t *testing.T) {
+	dir := t.TempDir()
+	old := time.Now().Add(-time.Hour)
+	data, _ := json.Marshal(&Info{PID: 999999, Host: "elsewhere", Command: "run", StartTime: old, Heartbeat: old})
+	os.MkdirAll(filepath.Dir(GetLockPath(dir)), 0755)
+	os.WriteFile(GetLockPath(dir), data, 0644)
+
+	l, err := Acquire(dir, "serve")
+	if err != nil {
+		t.Fatalf("expected the stale lock to be taken over, got %v", err)
+	}
+	defer l.Release()
+	if info, _ := Read(dir); info.PID != os.Getpid() || info.Command != "serve" {
+		t.Errorf("expected this process to hold the lock, got %+v", info)
+	}
+}
+
+func TestHeartbeat(t *testing.T) {
+	defer func(d time.Duration) { heartbeatInterval = d }(heartbeatInterval)
+	heartbeatInterval = 10 * time.Millisecond
+
+	dir := t.TempDir()
+	l, err := Acquire(dir, "run")
+	if err != nil {
+		t.Fatal(err)
+	}
+	start := l.Info().Heartbeat
+
+	deadline := time.Now().Add(2 * time.Second)
+	for {
+		info, err := Read(dir)
+		if err == nil && info.Heartbeat.After(start) {
+			break
+		}
+		if time.Now().After(deadline) {
+			t.Fatal("expected the heartbeat to be refreshed")
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+
+	// A lock taken over by another process is left alone on release
+	other, _ := json.Marshal(&Info{PID: 999999, Command: "tui", StartTime: time.Now(), Heartbeat: time.Now()})
+	os.WriteFile(GetLockPath(dir), other, 0644)
+	l.Release()
+	if info, _ := Read(dir); info.PID != 999999 {
+		t.Errorf("expected the other lock to be kept, got %+v", info)
+	}
+}
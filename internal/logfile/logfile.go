@@ -0,0 +1,267 @@
+package logfile
+
+import (
+	"compress/gzip"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// rotatedTimeFormat is the suffix of rotated files, e.g. hermes.log.20260102-150405
+const rotatedTimeFormat = "20060102-150405"
+
+// Policy controls when log files are rotated and how long rotated files are kept
+type Policy struct {
+	MaxSize    int64         // Rotate when a file would grow beyond this many bytes (0 disables)
+	Daily      bool          // Rotate when the first write of a new day arrives
+	MaxBackups int           // Rotated files kept per log (0 keeps all)
+	MaxAge     time.Duration // Rotated files older than this are deleted (0 keeps all)
+	Compress   bool          // Gzip rotated files
+}
+
+var (
+	defaultMu     sync.Mutex
+	defaultPolicy Policy
+)
+
+// SetDefaultPolicy sets the policy of files opened with Open
+func SetDefaultPolicy(p Policy) {
+	defaultMu.Lock()
+	defer defaultMu.Unlock()
+	defaultPolicy = p
+}
+
+// DefaultPolicy returns the policy of files opened with Open
+func DefaultPolicy() Policy {
+	defaultMu.Lock()
+	defer defaultMu.Unlock()
+	return defaultPolicy
+}
+
+// File is an append-only log file rotated according to a policy
+type File struct {
+	path   string
+	policy Policy
+	file   *os.File
+	size   int64
+	day    string
+	mu     sync.Mutex
+}
+
+// Open opens a log file for appending with the default policy
+func Open(path string) (*File, error) {
+	return OpenWithPolicy(path, DefaultPolicy())
+}
+
+// OpenWithPolicy opens a log file for appending, rotating it first when it
+// already exceeds the policy
+func OpenWithPolicy(path string, policy Policy) (*File, error) {
+	f := &File{path: path, policy: policy}
+	if err := f.open(); err != nil {
+		return nil, err
+	}
+	if f.exceeds(0) {
+		if err := f.rotate(); err != nil {
+			f.file.Close()
+			return nil, err
+		}
+	}
+	return f, nil
+}
+
+// Write appends p, rotating the file first when p would exceed the policy
+func (f *File) Write(p []byte) (int, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	if f.file == nil {
+		return 0, os.ErrClosed
+	}
+	if f.size > 0 && f.exceeds(int64(len(p))) {
+		if err := f.rotate(); err != nil {
+			return 0, err
+		}
+	}
+	n, err := f.file.Write(p)
+	f.size += int64(n)
+	return n, err
+}
+
+// Close closes the file
+func (f *File) Close() error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	if f.file == nil {
+		return nil
+	}
+	err := f.file.Close()
+	f.file = nil
+	return err
+}
+
+// Path returns the path of the active file
+func (f *File) Path() string {
+	return f.path
+}
+
+func (f *File) open() error {
+	file, err := os.OpenFile(f.path, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0644)
+	if err != nil {
+		return err
+	}
+	info, err := file.Stat()
+	if err != nil {
+		file.Close()
+		return err
+	}
+	f.file = file
+	f.size = info.Size()
+	f.day = info.ModTime().Format("2006-01-02")
+	if f.size == 0 {
+		f.day = time.Now().Format("2006-01-02")
+	}
+	return nil
+}
+
+// exceeds reports whether writing n more bytes breaks the policy
+func (f *File) exceeds(n int64) bool {
+	if f.size == 0 {
+		return false
+	}
+	if f.policy.MaxSize > 0 && f.size+n > f.policy.MaxSize {
+		return true
+	}
+	return f.policy.Daily && f.day != time.Now().Format("2006-01-02")
+}
+
+// rotate moves the active file aside, starts a new one and applies retention
+func (f *File) rotate() error {
+	if err := f.file.Close(); err != nil {
+		return err
+	}
+	f.file = nil
+
+	rotated := f.path + "." + time.Now().Format(rotatedTimeFormat)
+	for i := 1; exists(rotated) || exists(rotated+".gz"); i++ {
+		rotated = fmt.Sprintf("%s.%s-%d", f.path, time.Now().Format(rotatedTimeFormat), i)
+	}
+	if err := os.Rename(f.path, rotated); err != nil {
+		return err
+	}
+	if err := f.open(); err != nil {
+		return err
+	}
+
+	if f.policy.Compress {
+		if err := compress(rotated); err != nil {
+			return err
+		}
+	}
+	return Prune(f.path, f.policy)
+}
+
+// Backups returns the rotated files of a log, oldest first
+func Backups(path string) ([]string, error) {
+	matches, err := filepath.Glob(path + ".*")
+	if err != nil {
+		return nil, err
+	}
+	type backup struct {
+		path  string
+		stamp string
+		seq   int
+	}
+	var found []backup
+	for _, m := range matches {
+		// The suffix is a timestamp, with "-N" when several rotations share a second
+		suffix := strings.TrimSuffix(strings.TrimPrefix(m, path+"."), ".gz")
+		stamp, seq := suffix, ""
+		if len(suffix) > len(rotatedTimeFormat) {
+			stamp, seq = suffix[:len(rotatedTimeFormat)], strings.TrimPrefix(suffix[len(rotatedTimeFormat):], "-")
+		}
+		if _, err := time.Parse(rotatedTimeFormat, stamp); err != nil {
+			continue
+		}
+		n, err := strconv.Atoi(seq)
+		if seq != "" && err != nil {
+			continue
+		}
+		found = append(found, backup{path: m, stamp: stamp, seq: n})
+	}
+	sort.Slice(found, func(i, j int) bool {
+		if found[i].stamp != found[j].stamp {
+			return found[i].stamp < found[j].stamp
+		}
+		return found[i].seq < found[j].seq
+	})
+
+	backups := make([]string, len(found))
+	for i, b := range found {
+		backups[i] = b.path
+	}
+	return backups, nil
+}
+
+// Prune deletes the rotated files of a log beyond the retention of a policy
+func Prune(path string, policy Policy) error {
+	backups, err := Backups(path)
+	if err != nil {
+		return err
+	}
+	for i, b := range backups {
+		expired := policy.MaxBackups > 0 && i < len(backups)-policy.MaxBackups
+		if !expired && policy.MaxAge > 0 {
+			if info, err := os.Stat(b); err == nil && time.Since(info.ModTime()) > policy.MaxAge {
+				expired = true
+			}
+		}
+		if expired {
+			if err := os.Remove(b); err != nil && !os.IsNotExist(err) {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// compress gzips a file and removes the original
+func compress(path string) error {
+	src, err := os.Open(path)
+	if err != nil {
+		return err
+	}
+	defer src.Close()
+
+	dst, err := os.Create(path + ".gz")
+	if err != nil {
+		return err
+	}
+	zw := gzip.NewWriter(dst)
+	if _, err := io.Copy(zw, src); err != nil {
+		zw.Close()
+		dst.Close()
+		os.Remove(path + ".gz")
+		return err
+	}
+	if err := zw.Close(); err != nil {
+		dst.Close()
+		return err
+	}
+	if err := dst.Close(); err != nil {
+		return err
+	}
+	src.Close()
+	return os.Remove(path)
+}
+
+func exists(path string) bool {
+	_, err := os.Stat(path)
+	return err == nil
+}
@@ -0,0 +1,96 @@
+package logfile
+
+import (
+	"compress/gzip"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestRotateBySize(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "hermes.log")
+	f, err := OpenWithPolicy(path, Policy{MaxSize: 20, MaxBackups: 2, Compress: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+
+	for _, line := range []string{"first line\n", "second line\n", "third line\n", "fourth line\n"} {
+		if _, err := f.Write([]byte(line)); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	content, _ := os.ReadFile(path)
+	if string(content) != "fourth line\n" {
+		t.Errorf("expected only the last line in the active file, got %q", content)
+	}
+
+	backups, err := Backups(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(backups) != 2 {
+		t.Fatalf("expected 2 backups to be kept, got %v", backups)
+	}
+	last := backups[len(backups)-1]
+	if !strings.HasSuffix(last, ".gz") {
+		t.Fatalf("expected a compressed backup, got %s", last)
+	}
+	gz, _ := os.Open(last)
+	defer gz.Close()
+	zr, err := gzip.NewReader(gz)
+	if err != nil {
+		t.Fatal(err)
+	}
+	data, _ := io.ReadAll(zr)
+	if string(data) != "third line\n" {
+		t.Errorf("unexpected backup content %q", data)
+	}
+}
+
+func TestOpenRotatesOversizedFile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "worker-1.log")
+	os.WriteFile(path, []byte(strings.Repeat("x", 100)), 0644)
+
+	f, err := OpenWithPolicy(path, Policy{MaxSize: 50})
+	if err != nil {
+		t.Fatal(err)
+	}
+	f.Close()
+
+	if info, _ := os.Stat(path); info.Size() != 0 {
+		t.Errorf("expected a fresh file, got %d bytes", info.Size())
+	}
+	if backups, _ := Backups(path); len(backups) != 1 {
+		t.Errorf("expected 1 backup, got %v", backups)
+	}
+}
+
+func TestPruneByAge(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "hermes.log")
+	old := path + ".20250101-120000.gz"
+	recent := path + "." + time.Now().Format(rotatedTimeFormat)
+	unrelated := path + ".bak"
+	for _, p := range []string{old, recent, unrelated} {
+		os.WriteFile(p, []byte("log"), 0644)
+	}
+	past := time.Now().Add(-48 * time.Hour)
+	os.Chtimes(old, past, past)
+
+	if err := Prune(path, Policy{MaxAge: 24 * time.Hour}); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := os.Stat(old); !os.IsNotExist(err) {
+		t.Error("expected the expired backup to be deleted")
+	}
+	for _, p := range []string{recent, unrelated} {
+		if _, err := os.Stat(p); err != nil {
+			t.Errorf("expected %s to be kept", filepath.Base(p))
+		}
+	}
+}
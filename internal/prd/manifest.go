@@ -0,0 +1,154 @@
+package prd
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+// Change describes how a section differs from the previous split
+type Change string
+
+const (
+	ChangeNew       Change = "new"
+	ChangeModified  Change = "changed"
+	ChangeUnchanged Change = "unchanged"
+	ChangeRemoved   Change = "removed"
+)
+
+// Manifest records the sections of the last split and the task files generated from them
+type Manifest struct {
+	Source    string          `json:"source"`
+	UpdatedAt time.Time       `json:"updatedAt"`
+	Sections  []ManifestEntry `json:"sections"`
+}
+
+// ManifestEntry links a PRD section to its sub-PRD and generated feature file
+type ManifestEntry struct {
+	Heading   string `json:"heading"`
+	File      string `json:"file"`
+	Hash      string `json:"hash"`
+	FeatureID string `json:"featureId,omitempty"`
+	TaskFile  string `json:"taskFile,omitempty"`
+}
+
+// SectionChange pairs a section with its change status and previous manifest entry
+type SectionChange struct {
+	Section  Section
+	Change   Change
+	Previous *ManifestEntry
+}
+
+// GetFeaturesDir returns the directory where sub-PRDs are written
+func GetFeaturesDir(basePath string) string {
+	return filepath.Join(basePath, ".hermes", "docs", "features")
+}
+
+func manifestPath(basePath string) string {
+	return filepath.Join(GetFeaturesDir(basePath), "index.json")
+}
+
+// LoadManifest loads the split manifest, returning an empty manifest if none exists
+func LoadManifest(basePath string) (*Manifest, error) {
+	data, err := os.ReadFile(manifestPath(basePath))
+	if os.IsNotExist(err) {
+		return &Manifest{}, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	var m Manifest
+	if err := json.Unmarshal(data, &m); err != nil {
+		return nil, fmt.Errorf("invalid split manifest: %w", err)
+	}
+	return &m, nil
+}
+
+// Save writes the manifest to the features directory
+func (m *Manifest) Save(basePath string) error {
+	if err := os.MkdirAll(GetFeaturesDir(basePath), 0755); err != nil {
+		return err
+	}
+	m.UpdatedAt = time.Now()
+	data, err := json.MarshalIndent(m, "", "  ")
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(manifestPath(basePath), data, 0644)
+}
+
+// Find returns the entry for a heading, or nil
+func (m *Manifest) Find(heading string) *ManifestEntry {
+	for i := range m.Sections {
+		if strings.EqualFold(m.Sections[i].Heading, heading) {
+			return &m.Sections[i]
+		}
+	}
+	return nil
+}
+
+// Diff compares sections against the manifest. Removed sections are
+// returned last with a zero Section holding only the heading.
+func (m *Manifest) Diff(sections []Section) []SectionChange {
+	var changes []SectionChange
+	seen := make(map[string]bool)
+
+	for _, s := range sections {
+		prev := m.Find(s.Heading)
+		change := SectionChange{Section: s, Change: ChangeNew}
+		if prev != nil {
+			entry := *prev
+			change.Previous = &entry
+			change.Change = ChangeModified
+			if prev.Hash == s.Hash {
+				change.Change = ChangeUnchanged
+			}
+			seen[strings.ToLower(prev.Heading)] = true
+		}
+		changes = append(changes, change)
+	}
+
+	for i := range m.Sections {
+		if seen[strings.ToLower(m.Sections[i].Heading)] {
+			continue
+		}
+		entry := m.Sections[i]
+		changes = append(changes, SectionChange{
+			Section:  Section{Heading: entry.Heading},
+			Change:   ChangeRemoved,
+			Previous: &entry,
+		})
+	}
+
+	return changes
+}
+
+// WriteSection writes a section as a standalone sub-PRD and returns its path.
+// The preamble is included so each document carries the shared product context.
+func WriteSection(basePath, source, preamble string, s Section) (string, error) {
+	dir := GetFeaturesDir(basePath)
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return "", err
+	}
+
+	var sb strings.Builder
+	fmt.Fprintf(&sb, "<!-- Generated by 'hermes prd split' from %s, section %q. Edit the source PRD instead. -->\n\n", filepath.ToSlash(source), s.Heading)
+	fmt.Fprintf(&sb, "**Source PRD:** %s\n", filepath.ToSlash(source))
+	fmt.Fprintf(&sb, "**Source Section:** %s\n\n", s.Heading)
+	if preamble != "" {
+		sb.WriteString("## Product Context\n\n")
+		sb.WriteString(preamble)
+		sb.WriteString("\n\n")
+	}
+	sb.WriteString(s.Content)
+	sb.WriteString("\n")
+
+	path := filepath.Join(dir, fmt.Sprintf("%02d-%s.md", s.Index, Slug(s.Heading)))
+	if err := os.WriteFile(path, []byte(sb.String()), 0644); err != nil {
+		return "", err
+	}
+	return path, nil
+}
@@ -0,0 +1,168 @@
+package prd
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+const testPRD = `# Product Spec
+
+Shared context for every feature.
+
+## User Authentication
+
+Users log in with email and password.
+
+` + "```" + `
+## not a heading inside code
+` + "```" + `
+
+## Billing
+
+Monthly invoices.
+`
+
+func TestSplit(t *testing.T) {
+	preamble, sections := Split(testPRD, 0)
+
+	if !strings.Contains(preamble, "Shared context") {
+		t.Errorf("expected preamble to contain shared context, got %q", preamble)
+	}
+	if len(sections) != 2 {
+		t.Fatalf("expected 2 sections, got %d", len(sections))
+	}
+	if sections[0].Heading != "User Authentication" || sections[1].Heading != "Billing" {
+		t.Errorf("unexpected headings: %q, %q", sections[0].Heading, sections[1].Heading)
+	}
+	if !strings.Contains(sections[0].Content, "not a heading inside code") {
+		t.Error("expected fenced code to stay inside its section")
+	}
+	if sections[0].Hash == "" || sections[0].Hash == sections[1].Hash {
+		t.Error("expected distinct section hashes")
+	}
+}
+
+func TestSplitExplicitLevel(t *testing.T) {
+	_, sections := Split(testPRD, 1)
+	if len(sections) != 1 || sections[0].Heading != "Product Spec" {
+		t.Errorf("expected single level-1 section, got %+v", sections)
+	}
+}
+
+func TestSlug(t *testing.T) {
+	tests := map[string]string{
+		"User Authentication":  "user-authentication",
+		"  API / Rate-Limits ": "api-rate-limits",
+		"!!!":                  "section",
+	}
+	for input, expected := range tests {
+		if got := Slug(input); got != expected {
+			t.Errorf("Slug(%q) = %q, want %q", input, got, expected)
+		}
+	}
+}
+
+func TestManifestDiff(t *testing.T) {
+	_, sections := Split(testPRD, 0)
+	m := &Manifest{Sections: []ManifestEntry{
+		{Heading: "User Authentication", Hash: sections[0].Hash, FeatureID: "F001"},
+		{Heading: "Billing", Hash: "stale"},
+		{Heading: "Reporting", Hash: "x", FeatureID: "F003"},
+	}}
+
+	changes := m.Diff(sections)
+	if len(changes) != 3 {
+		t.Fatalf("expected 3 changes, got %d", len(changes))
+	}
+	if changes[0].Change != ChangeUnchanged {
+		t.Errorf("expected unchanged, got %s", changes[0].Change)
+	}
+	if changes[1].Change != ChangeModified {
+		t.Errorf("expected changed, got %s", changes[1].Change)
+	}
+	if changes[2].Change != ChangeRemoved || changes[2].Previous.FeatureID != "F003" {
+		t.Errorf("expected Reporting removed, got %+v", changes[2])
+	}
+}
+
+func TestWriteSectionAndManifest(t *testing.T) {
+	tmpDir := t.TempDir()
+	preamble, sections := Split(testPRD, 0)
+
+	path, err := WriteSection(tmpDir, "docs/PRD.md", preamble, sections[1])
+	if err != nil {
+		t.Fatal(err)
+	}
+	if filepath.Base(path) != "02-billing.md" {
+		t.Errorf("unexpected file name %s", filepath.Base(path))
+	}
+	content, _ := os.ReadFile(path)
+	for _, want := range []string{"**Source PRD:** docs/PRD.md", "**Source Section:** Billing", "Shared context", "Monthly invoices"} {
+		if !strings.Contains(string(content), want) {
+			t.Errorf("expected sub-PRD to contain %q", want)
+		}
+	}
+
+	m := &Manifest{Source: "docs/PRD.md", Sections: []ManifestEntry{{Heading: "Billing", File: path, Hash: sections[1].Hash}}}
+	if err := m.Save(tmpDir); err != nil {
+		t.Fatal(err)
+	}
+	loaded, err := LoadManifest(tmpDir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if loaded.Find("billing") == nil {
+		t.Error("expected to find Billing entry case-insensitively")
+	}
+}
+
+func TestMatchesSection(t *testing.T) {
+	_, sections := Split(testPRD+"\n### Invoice Emails\n\nSend PDFs.\n", 0)
+	billing := sections[1]
+
+	tests := []struct {
+		prdSection string
+		expected   bool
+	}{
+		{"Billing", true},
+		{"2. billing", true},
+		{"Invoice Emails", true},
+		{"User Authentication", false},
+		{"", false},
+	}
+	for _, tt := range tests {
+		if got := MatchesSection(tt.prdSection, billing); got != tt.expected {
+			t.Errorf("MatchesSection(%q) = %v, want %v", tt.prdSection, got, tt.expected)
+		}
+	}
+}
+
+func TestSnapshotRoundTrip(t *testing.T) {
+	tmpDir := t.TempDir()
+
+	if s, err := LoadSnapshot(tmpDir); err != nil || s != nil {
+		t.Fatalf("expected no snapshot, got %v, %v", s, err)
+	}
+
+	_, sections := Split(testPRD, 0)
+	if err := NewSnapshot("docs/PRD.md", sections).Save(tmpDir); err != nil {
+		t.Fatal(err)
+	}
+
+	loaded, err := LoadSnapshot(tmpDir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if loaded.Level != 2 || len(loaded.Sections) != 2 {
+		t.Errorf("unexpected snapshot: %+v", loaded)
+	}
+
+	changed := strings.Replace(testPRD, "Monthly invoices.", "Weekly invoices.", 1)
+	_, sections = Split(changed, loaded.Level)
+	changes := loaded.Diff(sections)
+	if changes[0].Change != ChangeUnchanged || changes[1].Change != ChangeModified {
+		t.Errorf("expected only Billing to change, got %s, %s", changes[0].Change, changes[1].Change)
+	}
+}
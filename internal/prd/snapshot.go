@@ -0,0 +1,108 @@
+package prd
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"time"
+)
+
+var sectionNumberRegex = regexp.MustCompile(`^[\d.]+\s+`)
+
+// Snapshot records the PRD sections seen by the last parse so later
+// parses can be scoped to what changed
+type Snapshot struct {
+	Source    string          `json:"source"`
+	Level     int             `json:"level"`
+	UpdatedAt time.Time       `json:"updatedAt"`
+	Sections  []ManifestEntry `json:"sections"`
+}
+
+// NewSnapshot creates a snapshot of the given PRD sections
+func NewSnapshot(source string, sections []Section) *Snapshot {
+	s := &Snapshot{Source: filepath.ToSlash(source)}
+	for _, sec := range sections {
+		if s.Level == 0 {
+			s.Level = sec.Level
+		}
+		s.Sections = append(s.Sections, ManifestEntry{Heading: sec.Heading, Hash: sec.Hash})
+	}
+	return s
+}
+
+func snapshotPath(basePath string) string {
+	return filepath.Join(basePath, ".hermes", "docs", "prd-snapshot.json")
+}
+
+// LoadSnapshot loads the last PRD snapshot, returning nil if none exists
+func LoadSnapshot(basePath string) (*Snapshot, error) {
+	data, err := os.ReadFile(snapshotPath(basePath))
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	var s Snapshot
+	if err := json.Unmarshal(data, &s); err != nil {
+		return nil, err
+	}
+	return &s, nil
+}
+
+// Save writes the snapshot to .hermes/docs
+func (s *Snapshot) Save(basePath string) error {
+	path := snapshotPath(basePath)
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return err
+	}
+	s.UpdatedAt = time.Now()
+	data, err := json.MarshalIndent(s, "", "  ")
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(path, data, 0644)
+}
+
+// Diff compares sections against the snapshot
+func (s *Snapshot) Diff(sections []Section) []SectionChange {
+	m := &Manifest{Sections: s.Sections}
+	return m.Diff(sections)
+}
+
+// Headings returns the heading of a section and of every subsection inside it
+func (s Section) Headings() []string {
+	headings := []string{s.Heading}
+	lines := strings.Split(s.Content, "\n")
+	for i, lvl := range headingLevels(lines) {
+		if lvl > s.Level {
+			if m := headingRegex.FindStringSubmatch(lines[i]); m != nil {
+				headings = append(headings, strings.TrimSpace(m[2]))
+			}
+		}
+	}
+	return headings
+}
+
+// MatchesSection reports whether a task's PRD Section refers to the section
+// or one of its subsections. Leading section numbers ("3.2 ") are ignored.
+func MatchesSection(prdSection string, s Section) bool {
+	want := normalizeHeading(prdSection)
+	if want == "" {
+		return false
+	}
+	for _, h := range s.Headings() {
+		if normalizeHeading(h) == want {
+			return true
+		}
+	}
+	return false
+}
+
+func normalizeHeading(h string) string {
+	h = strings.TrimSpace(strings.Trim(strings.TrimSpace(h), "#"))
+	h = sectionNumberRegex.ReplaceAllString(h, "")
+	return strings.ToLower(strings.TrimSpace(h))
+}
@@ -0,0 +1,144 @@
+package prd
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"regexp"
+	"strings"
+)
+
+var headingRegex = regexp.MustCompile(`^(#{1,6})\s+(.+?)\s*#*\s*$`)
+
+// Section is a single feature section of a PRD
+type Section struct {
+	Index   int
+	Heading string
+	Level   int
+	Content string
+	Hash    string
+}
+
+// Split breaks a PRD into its preamble and one section per heading at the
+// given level. A level of 0 picks the shallowest level with at least two headings.
+func Split(content string, level int) (string, []Section) {
+	content = strings.ReplaceAll(content, "\r\n", "\n")
+	lines := strings.Split(content, "\n")
+	levels := headingLevels(lines)
+
+	if level <= 0 {
+		level = detectLevel(levels)
+	}
+	if level <= 0 {
+		return strings.TrimSpace(content), nil
+	}
+
+	var preamble []string
+	var sections []Section
+	var current *Section
+	var body []string
+
+	flush := func() {
+		if current == nil {
+			return
+		}
+		current.Content = strings.TrimSpace(strings.Join(body, "\n"))
+		current.Hash = hashContent(current.Content)
+		sections = append(sections, *current)
+	}
+
+	for i, line := range lines {
+		lvl := levels[i]
+		if lvl > 0 && lvl <= level {
+			m := headingRegex.FindStringSubmatch(line)
+			flush()
+			if lvl < level {
+				// A shallower heading (e.g. the document title) ends the current section
+				current = nil
+				if len(sections) == 0 {
+					preamble = append(preamble, line)
+				}
+				continue
+			}
+			current = &Section{Index: len(sections) + 1, Heading: strings.TrimSpace(m[2]), Level: lvl}
+			body = []string{line}
+			continue
+		}
+		if current != nil {
+			body = append(body, line)
+		} else if len(sections) == 0 {
+			preamble = append(preamble, line)
+		}
+	}
+	flush()
+
+	return strings.TrimSpace(strings.Join(preamble, "\n")), sections
+}
+
+// headingLevels returns the heading level of each line, ignoring fenced code blocks
+func headingLevels(lines []string) []int {
+	levels := make([]int, len(lines))
+	inFence := false
+	for i, line := range lines {
+		trimmed := strings.TrimSpace(line)
+		if strings.HasPrefix(trimmed, "```") || strings.HasPrefix(trimmed, "~~~") {
+			inFence = !inFence
+			continue
+		}
+		if inFence {
+			continue
+		}
+		if m := headingRegex.FindStringSubmatch(line); m != nil {
+			levels[i] = len(m[1])
+		}
+	}
+	return levels
+}
+
+// detectLevel returns the shallowest heading level that occurs at least twice
+func detectLevel(levels []int) int {
+	counts := make(map[int]int)
+	for _, lvl := range levels {
+		if lvl > 0 {
+			counts[lvl]++
+		}
+	}
+	for lvl := 1; lvl <= 6; lvl++ {
+		if counts[lvl] >= 2 {
+			return lvl
+		}
+	}
+	for lvl := 1; lvl <= 6; lvl++ {
+		if counts[lvl] > 0 {
+			return lvl
+		}
+	}
+	return 0
+}
+
+// Slug returns a filename-safe slug for a heading
+func Slug(heading string) string {
+	var sb strings.Builder
+	lastDash := true
+	for _, r := range strings.ToLower(heading) {
+		if (r >= 'a' && r <= 'z') || (r >= '0' && r <= '9') {
+			sb.WriteRune(r)
+			lastDash = false
+		} else if !lastDash {
+			sb.WriteRune('-')
+			lastDash = true
+		}
+	}
+	slug := strings.Trim(sb.String(), "-")
+	if len(slug) > 40 {
+		slug = strings.TrimRight(slug[:40], "-")
+	}
+	if slug == "" {
+		slug = "section"
+	}
+	return slug
+}
+
+func hashContent(content string) string {
+	sum := sha256.Sum256([]byte(content))
+	return hex.EncodeToString(sum[:])[:16]
+}
@@ -0,0 +1,149 @@
+package prompt
+
+import (
+	"fmt"
+	"strings"
+	"unicode/utf8"
+)
+
+// truncationMarker replaces the part of a section cut to fit the prompt budget
+const truncationMarker = "\n\n[... truncated by Hermes to fit ai.maxPromptTokens ...]\n"
+
+// EstimateTokens estimates the tokens text takes up in a prompt. Providers
+// tokenize differently; about four characters per token holds for English
+// text and code and errs on the side of too many for both.
+func EstimateTokens(text string) int {
+	return (utf8.RuneCountInString(text) + 3) / 4
+}
+
+// budgetSection is a part of the prompt data that can be shortened
+type budgetSection struct {
+	name string
+	get  func(data *TemplateData) string
+	set  func(data *TemplateData, text string)
+}
+
+// budgetSections are shortened in order until a prompt fits its budget, the
+// parts that matter least for the task first. The task section of PROMPT.md,
+// the files to touch and the success criteria are never cut.
+var budgetSections = []budgetSection{
+	{
+		name: "project context",
+		get:  func(data *TemplateData) string { return data.ProjectContext },
+		set:  func(data *TemplateData, text string) { data.ProjectContext = text },
+	},
+	{
+		name: "git history",
+		get:  func(data *TemplateData) string { return data.GitHistory },
+		set:  func(data *TemplateData, text string) { data.GitHistory = text },
+	},
+	{
+		name: "memory",
+		get:  func(data *TemplateData) string { return data.Memory },
+		set:  func(data *TemplateData, text string) { data.Memory = text },
+	},
+	{
+		name: "project instructions",
+		get: func(data *TemplateData) string {
+			before, _ := splitInstructions(data.Instructions)
+			return before
+		},
+		set: func(data *TemplateData, text string) {
+			_, rest := splitInstructions(data.Instructions)
+			data.Instructions = text + rest
+		},
+	},
+	{
+		name: "technical details",
+		get: func(data *TemplateData) string {
+			if data.Task == nil {
+				return ""
+			}
+			return data.Task.TechnicalDetails
+		},
+		set: func(data *TemplateData, text string) { data.Task.TechnicalDetails = text },
+	},
+	{
+		name: "task description",
+		get: func(data *TemplateData) string {
+			if data.Task == nil {
+				return ""
+			}
+			return data.Task.Description
+		},
+		set: func(data *TemplateData, text string) { data.Task.Description = text },
+	},
+}
+
+// splitInstructions splits PROMPT.md into the project's instructions before
+// the task section and the task section with what follows. Instructions
+// without a task section, like fix prompts, are all project instructions.
+func splitInstructions(instructions string) (before, rest string) {
+	if i := strings.Index(instructions, TaskSectionStart); i >= 0 {
+		return instructions[:i], instructions[i:]
+	}
+	return instructions, ""
+}
+
+// RenderWithin renders a prompt like Render and, when it is estimated to take
+// more than maxTokens, shortens the sections of budgetSections until it fits.
+// It returns what was shortened; a prompt still too long once they are cut is
+// returned as it is, with a note saying so. A maxTokens of 0 means no limit.
+func RenderWithin(basePath, name, text string, data TemplateData, maxTokens int) (string, []string, error) {
+	out, err := Render(basePath, name, text, data)
+	if err != nil || maxTokens <= 0 || EstimateTokens(out) <= maxTokens {
+		return out, nil, err
+	}
+
+	// Shorten a copy, the task is shared with the caller
+	if data.Task != nil {
+		t := *data.Task
+		data.Task = &t
+	}
+	var notes []string
+	for _, section := range budgetSections {
+		excess := EstimateTokens(out) - maxTokens
+		if excess <= 0 {
+			break
+		}
+		content := section.get(&data)
+		if content == "" {
+			continue
+		}
+		tokens := EstimateTokens(content)
+		section.set(&data, truncateTokens(content, tokens-excess))
+		shortened, err := Render(basePath, name, text, data)
+		if err != nil {
+			return "", nil, err
+		}
+		if len(shortened) >= len(out) {
+			// The template doesn't use the section
+			section.set(&data, content)
+			continue
+		}
+		out = shortened
+		notes = append(notes, fmt.Sprintf("%s shortened from ~%d to ~%d tokens", section.name, tokens, EstimateTokens(section.get(&data))))
+	}
+	if tokens := EstimateTokens(out); tokens > maxTokens {
+		notes = append(notes, fmt.Sprintf("prompt of ~%d tokens still exceeds ai.maxPromptTokens (%d) with only the task left", tokens, maxTokens))
+	}
+	return out, notes, nil
+}
+
+// truncateTokens keeps the start of text within about tokens tokens, cut at
+// a line break where there is one, and marks the cut
+func truncateTokens(text string, tokens int) string {
+	keep := tokens*4 - utf8.RuneCountInString(truncationMarker)
+	if keep <= 0 {
+		return strings.TrimPrefix(truncationMarker, "\n\n")
+	}
+	runes := []rune(text)
+	if keep >= len(runes) {
+		return text
+	}
+	kept := string(runes[:keep])
+	if i := strings.LastIndex(kept, "\n"); i > 0 {
+		kept = kept[:i]
+	}
+	return kept + truncationMarker
+}
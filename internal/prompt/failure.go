@@ -0,0 +1,53 @@
+package prompt
+
+import (
+	"fmt"
+	"strings"
+
+	"hermes/internal/analyzer"
+)
+
+// maxFailureOutput bounds how many characters of the failed attempt's output
+// are fed back
+const maxFailureOutput = 2000
+
+// Reasons attempts at a task fail for, told to the next attempt
+const (
+	ReasonError         = "The previous attempt stopped with an error before it finished."
+	ReasonNoStatusBlock = "The previous response had no HERMES_STATUS block, so Hermes could not tell whether the task was done. End your response with the status block."
+	ReasonVerification  = "Your previous attempt reported COMPLETE, but these checks failed. Fix them first:"
+	ReasonIncomplete    = "Your previous attempt did not report the task COMPLETE."
+)
+
+// Failure is why an attempt at a task failed, fed back to the next attempt so
+// it doesn't start over with the same prompt
+type Failure struct {
+	Reason   string                   // One of the Reason constants
+	Details  string                   // Error or failed checks, Markdown
+	Analysis *analyzer.AnalysisResult // What was read from the response, nil when it wasn't analyzed
+	Output   string                   // AI output of the attempt, the end of which is shown
+}
+
+// Feedback formats the failure for the Feedback of the task template
+func (f Failure) Feedback() string {
+	var sb strings.Builder
+	sb.WriteString(f.Reason + "\n\n")
+	if details := strings.TrimSpace(f.Details); details != "" {
+		sb.WriteString(details + "\n\n")
+	}
+	if a := f.Analysis; a != nil {
+		fmt.Fprintf(&sb, "**Analysis:** status %s, %d of %d success criteria met, confidence %.2f",
+			a.Status, a.CriteriaMet, a.CriteriaTotal, a.Confidence)
+		if a.Recommendation != "" {
+			sb.WriteString(", recommendation: " + a.Recommendation)
+		}
+		sb.WriteString("\n\n")
+	}
+	if output := strings.TrimSpace(f.Output); output != "" {
+		if runes := []rune(output); len(runes) > maxFailureOutput {
+			output = "..." + string(runes[len(runes)-maxFailureOutput:])
+		}
+		sb.WriteString("**End of the previous response:**\n\n```\n" + output + "\n```\n")
+	}
+	return strings.TrimSpace(sb.String())
+}
@@ -1,7 +1,6 @@
 package prompt
 
 import (
-	"fmt"
 	"os"
 	"path/filepath"
 	"regexp"
@@ -17,8 +16,9 @@ const (
 
 // Injector manages PROMPT.md task injection
 type Injector struct {
-	basePath   string
-	promptPath string
+	basePath     string
+	promptPath   string
+	stepSubtasks bool
 }
 
 // NewInjector creates a new prompt injector
@@ -29,6 +29,13 @@ func NewInjector(basePath string) *Injector {
 	}
 }
 
+// SetStepSubtasks makes the prompt ask for the task's next open subtask only,
+// for run loops that complete one subtask per iteration. Otherwise all
+// subtasks are worked through in one go.
+func (i *Injector) SetStepSubtasks(enabled bool) {
+	i.stepSubtasks = enabled
+}
+
 // GetPromptPath returns the path to PROMPT.md
 func (i *Injector) GetPromptPath() string {
 	return i.promptPath
@@ -60,6 +67,12 @@ func (i *Injector) Write(content string) error {
 
 // AddTask adds a task section to the prompt
 func (i *Injector) AddTask(t *task.Task) error {
+	return i.AddTaskWithFeedback(t, "")
+}
+
+// AddTaskWithFeedback adds a task section to the prompt, telling the AI why
+// its previous attempt was not accepted, see Failure
+func (i *Injector) AddTaskWithFeedback(t *task.Task, feedback string) error {
 	content, err := i.Read()
 	if err != nil {
 		content = ""
@@ -69,7 +82,10 @@ func (i *Injector) AddTask(t *task.Task) error {
 	content = i.removeTaskSection(content)
 
 	// Add new task section
-	section := i.generateTaskSection(t)
+	section, err := i.generateTaskSection(t, feedback)
+	if err != nil {
+		return err
+	}
 	if content != "" {
 		content = content + "\n\n" + section
 	} else {
@@ -96,110 +112,22 @@ func (i *Injector) removeTaskSection(content string) string {
 	return strings.TrimSpace(content)
 }
 
-func (i *Injector) generateTaskSection(t *task.Task) string {
-	var sb strings.Builder
-
-	sb.WriteString(TaskSectionStart + "\n")
-	sb.WriteString(fmt.Sprintf("## Current Task: %s\n\n", t.ID))
-	sb.WriteString(fmt.Sprintf("**Task:** %s: %s\n\n", t.ID, t.Name))
-
-	if t.Priority != "" {
-		sb.WriteString(fmt.Sprintf("**Priority:** %s\n\n", t.Priority))
-	}
-
-	if t.EstimatedEffort != "" {
-		sb.WriteString(fmt.Sprintf("**Estimated Effort:** %s\n\n", t.EstimatedEffort))
-	}
-
-	if t.Description != "" {
-		sb.WriteString("### Description\n\n")
-		sb.WriteString(t.Description + "\n\n")
-	}
-
-	if t.TechnicalDetails != "" {
-		sb.WriteString("### Technical Details\n\n")
-		sb.WriteString(t.TechnicalDetails + "\n\n")
-	}
-
-	if len(t.FilesToTouch) > 0 {
-		sb.WriteString("**Files to Touch:**\n")
-		for _, f := range t.FilesToTouch {
-			sb.WriteString(fmt.Sprintf("- %s\n", f))
-		}
-		sb.WriteString("\n")
+// generateTaskSection renders the task section from the project's task
+// template or DefaultTaskTemplate
+func (i *Injector) generateTaskSection(t *task.Task, feedback string) (string, error) {
+	data, err := NewTemplateData(i.basePath, t)
+	if err != nil {
+		return "", err
 	}
-
-	if len(t.Dependencies) > 0 {
-		sb.WriteString("**Dependencies:**\n")
-		for _, d := range t.Dependencies {
-			sb.WriteString(fmt.Sprintf("- %s\n", d))
-		}
-		sb.WriteString("\n")
+	data.Feedback = feedback
+	if i.stepSubtasks {
+		data.NextSubtask = t.NextSubtask()
 	}
-
-	if len(t.SuccessCriteria) > 0 {
-		sb.WriteString("**Success Criteria:**\n")
-		for _, c := range t.SuccessCriteria {
-			sb.WriteString(fmt.Sprintf("- [ ] %s\n", c))
-		}
-		sb.WriteString("\n")
+	section, err := Render(i.basePath, TaskTemplate, DefaultTaskTemplate, data)
+	if err != nil {
+		return "", err
 	}
-
-	sb.WriteString("### Instructions\n\n")
-	sb.WriteString("1. Review the task description and technical details\n")
-	sb.WriteString("2. Implement all requirements following project conventions\n")
-	sb.WriteString("3. Create or update files as specified\n")
-	sb.WriteString("4. Write tests for new functionality\n")
-	sb.WriteString("5. ***Writing mock code is strictly forbidden, except for test files!***\n")
-	sb.WriteString("6. Verify all success criteria are met\n")
-	sb.WriteString("7. Ensure code compiles without errors\n\n")
-
-	sb.WriteString("---\n")
-	sb.WriteString("## CRITICAL: HERMES_STATUS BLOCK IS MANDATORY\n\n")
-	sb.WriteString("**YOUR RESPONSE WILL BE REJECTED IF YOU DO NOT INCLUDE THE HERMES_STATUS BLOCK!**\n\n")
-	sb.WriteString("You MUST end your response with one of the status blocks below.\n")
-	sb.WriteString("Without this block, your work will NOT be saved and the task will be retried.\n\n")
-	sb.WriteString("---\n\n")
-
-	sb.WriteString("### Status Reporting (REQUIRED)\n\n")
-	sb.WriteString("**ALWAYS output one of these status blocks at the VERY END of your response:**\n\n")
-	sb.WriteString("**COMPLETE** - All success criteria met, code compiles, tests pass:\n")
-	sb.WriteString("```\n")
-	sb.WriteString("---HERMES_STATUS---\n")
-	sb.WriteString("STATUS: COMPLETE\n")
-	sb.WriteString("EXIT_SIGNAL: true\n")
-	sb.WriteString("RECOMMENDATION: Move to next task\n")
-	sb.WriteString("---END_HERMES_STATUS---\n")
-	sb.WriteString("```\n\n")
-	sb.WriteString("**BLOCKED** - Cannot proceed due to missing dependency or external blocker:\n")
-	sb.WriteString("```\n")
-	sb.WriteString("---HERMES_STATUS---\n")
-	sb.WriteString("STATUS: BLOCKED\n")
-	sb.WriteString("EXIT_SIGNAL: false\n")
-	sb.WriteString("RECOMMENDATION: <describe what is blocking>\n")
-	sb.WriteString("---END_HERMES_STATUS---\n")
-	sb.WriteString("```\n\n")
-	sb.WriteString("**AT_RISK** - Progressing but facing challenges that may cause delays:\n")
-	sb.WriteString("```\n")
-	sb.WriteString("---HERMES_STATUS---\n")
-	sb.WriteString("STATUS: AT_RISK\n")
-	sb.WriteString("EXIT_SIGNAL: false\n")
-	sb.WriteString("RECOMMENDATION: <describe the risk and mitigation>\n")
-	sb.WriteString("---END_HERMES_STATUS---\n")
-	sb.WriteString("```\n\n")
-	sb.WriteString("**IN_PROGRESS** - Still working, not yet complete:\n")
-	sb.WriteString("```\n")
-	sb.WriteString("---HERMES_STATUS---\n")
-	sb.WriteString("STATUS: IN_PROGRESS\n")
-	sb.WriteString("EXIT_SIGNAL: false\n")
-	sb.WriteString("RECOMMENDATION: <describe next steps>\n")
-	sb.WriteString("---END_HERMES_STATUS---\n")
-	sb.WriteString("```\n\n")
-	sb.WriteString("**REMEMBER: No status block = task will be retried!**\n\n")
-
-	sb.WriteString(TaskSectionEnd)
-
-	return sb.String()
+	return TaskSectionStart + "\n" + section + TaskSectionEnd, nil
 }
 
 // GetCurrentTaskID returns the task ID from the prompt
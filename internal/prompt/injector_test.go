@@ -1,11 +1,15 @@
 package prompt
 
 import (
+	"fmt"
 	"os"
 	"path/filepath"
 	"strings"
+	"sync"
 	"testing"
+	"unicode/utf8"
 
+	"hermes/internal/analyzer"
 	"hermes/internal/task"
 )
 
@@ -327,3 +331,225 @@ func TestCleanupBackups(t *testing.T) {
 		t.Errorf("expected 2 backups after cleanup, got %d", len(backups))
 	}
 }
+
+func TestAddTaskWithSubtasks(t *testing.T) {
+	tmpDir, cleanup := setupTestDir(t)
+	defer cleanup()
+
+	testTask := &task.Task{
+		ID:   "T001",
+		Name: "Implement login",
+		Subtasks: []task.Subtask{
+			{ID: "T001.1", Name: "Add the route", Status: task.StatusCompleted},
+			{ID: "T001.2", Name: "Check the password", Status: task.StatusNotStarted},
+		},
+	}
+
+	i := NewInjector(tmpDir)
+	i.AddTask(testTask)
+	content, _ := i.Read()
+	if !strings.Contains(content, "- [x] T001.1: Add the route") || strings.Contains(content, "Current Subtask") {
+		t.Errorf("expected all subtasks to be listed without a current one:\n%s", content)
+	}
+
+	i.SetStepSubtasks(true)
+	i.AddTask(testTask)
+	content, _ = i.Read()
+	if !strings.Contains(content, "**Current Subtask:** T001.2: Check the password") {
+		t.Errorf("expected the next open subtask to be current:\n%s", content)
+	}
+}
+
+func TestAddTaskWithFeedback(t *testing.T) {
+	tmpDir, cleanup := setupTestDir(t)
+	defer cleanup()
+
+	i := NewInjector(tmpDir)
+	testTask := &task.Task{
+		ID:              "T001",
+		Name:            "Implement login",
+		SuccessCriteria: []string{"Login works", "cmd: go test ./auth/..."},
+	}
+
+	failure := Failure{Reason: ReasonVerification, Details: "`go test ./auth/...` failed: exit status 1"}
+	if err := i.AddTaskWithFeedback(testTask, failure.Feedback()); err != nil {
+		t.Fatal(err)
+	}
+	content, _ := i.Read()
+	for _, want := range []string{
+		"- [ ] Login works",
+		"- [ ] `go test ./auth/...` succeeds",
+		"only accepts the task if all of them succeed",
+		"### Previous Attempt Failed\n\n" + ReasonVerification + "\n\n`go test ./auth/...` failed: exit status 1\n",
+	} {
+		if !strings.Contains(content, want) {
+			t.Errorf("expected prompt to contain %q", want)
+		}
+	}
+
+	// Responses that were analyzed or cut short show what was found and how they ended
+	feedback := Failure{
+		Reason:   ReasonIncomplete,
+		Analysis: &analyzer.AnalysisResult{Status: "IN_PROGRESS", CriteriaMet: 1, CriteriaTotal: 2, Confidence: 0.4, Recommendation: "Add the handler"},
+		Output:   strings.Repeat("x", maxFailureOutput) + "last line",
+	}.Feedback()
+	for _, want := range []string{
+		"**Analysis:** status IN_PROGRESS, 1 of 2 success criteria met, confidence 0.40, recommendation: Add the handler",
+		"**End of the previous response:**\n\n```\n...",
+		"last line\n```",
+	} {
+		if !strings.Contains(feedback, want) {
+			t.Errorf("expected feedback to contain %q:\n%s", want, feedback)
+		}
+	}
+	// Output is cut between characters, not inside one
+	if feedback := (Failure{Reason: ReasonIncomplete, Output: "START" + strings.Repeat("ş", maxFailureOutput)}).Feedback(); !utf8.ValidString(feedback) || strings.Contains(feedback, "START") {
+		t.Errorf("expected the last %d characters of the output, got %q", maxFailureOutput, feedback[len(feedback)-20:])
+	}
+
+	// Without feedback there is no failure section
+	i.AddTask(testTask)
+	if content, _ := i.Read(); strings.Contains(content, "Previous Attempt") {
+		t.Error("expected no feedback section")
+	}
+}
+
+func TestAddTaskWithTemplate(t *testing.T) {
+	tmpDir, cleanup := setupTestDir(t)
+	defer cleanup()
+
+	tasksDir := filepath.Join(tmpDir, ".hermes", "tasks")
+	os.MkdirAll(tasksDir, 0755)
+	os.WriteFile(filepath.Join(tasksDir, "001-auth.md"), []byte("# Feature 1: Authentication\n**Feature ID:** F001\n**Status:** NOT_STARTED\n"), 0644)
+	os.MkdirAll(filepath.Join(tmpDir, ".hermes", TemplatesDir), 0755)
+	templatePath := TemplatePath(tmpDir, TaskTemplate)
+	os.WriteFile(templatePath, []byte("## Current Task: {{.Task.ID}}\n{{.Feature.Name}} / {{.Config.AI.Coding}}\n{{list .Task.FilesToTouch}}"), 0644)
+
+	i := NewInjector(tmpDir)
+	testTask := &task.Task{ID: "T001", Name: "Implement login", FeatureID: "F001", FilesToTouch: []string{"auth.go"}}
+	if err := i.AddTask(testTask); err != nil {
+		t.Fatal(err)
+	}
+	content, _ := i.Read()
+	for _, want := range []string{"Authentication / claude", "- auth.go", TaskSectionEnd} {
+		if !strings.Contains(content, want) {
+			t.Errorf("expected prompt to contain %q:\n%s", want, content)
+		}
+	}
+	if strings.Contains(content, "### Instructions") {
+		t.Error("expected the template to replace the built-in task section")
+	}
+	if id, _ := i.GetCurrentTaskID(); id != "T001" {
+		t.Errorf("expected current task T001, got %q", id)
+	}
+
+	// Tagged tasks use the template of their first tag there is one for,
+	// through the config's mapping or by name
+	os.WriteFile(TemplatePath(tmpDir, TaskTemplate+"-migration"), []byte("## Current Task: {{.Task.ID}}\nmigration rules"), 0644)
+	os.WriteFile(filepath.Join(tmpDir, ".hermes", "config.json"), []byte(`{"ai": {"promptTemplates": {"DB": "migration"}}}`), 0644)
+	for _, tags := range [][]string{{"db"}, {"ui", "Migration"}} {
+		testTask.Tags = tags
+		if err := i.AddTask(testTask); err != nil {
+			t.Fatal(err)
+		}
+		if content, _ := i.Read(); !strings.Contains(content, "migration rules") {
+			t.Errorf("expected tags %v to use task-migration.tmpl:\n%s", tags, content)
+		}
+	}
+	testTask.Tags = nil
+
+	// A broken template fails rather than sending a partial prompt
+	os.WriteFile(templatePath, []byte("{{.Task.Missing}}"), 0644)
+	if err := i.AddTask(testTask); err == nil || !strings.Contains(err.Error(), templatePath) {
+		t.Errorf("expected an error naming %s, got %v", templatePath, err)
+	}
+}
+
+func TestRenderWithin(t *testing.T) {
+	tmpDir, cleanup := setupTestDir(t)
+	defer cleanup()
+
+	const text = "{{.ProjectContext}}\n{{.Instructions}}\n{{.Task.Description}}\n{{list .Task.SuccessCriteria}}"
+	testTask := &task.Task{ID: "T001", Description: strings.Repeat("describe the task\n", 50), SuccessCriteria: []string{"cmd: go test ./..."}}
+	data := TemplateData{
+		Task:           testTask,
+		ProjectContext: strings.Repeat("project tree line\n", 500),
+		Instructions:   strings.Repeat("project rule\n", 500) + TaskSectionStart + "\ntask section\n" + TaskSectionEnd,
+	}
+
+	// Prompts within the budget are left alone
+	out, notes, err := RenderWithin(tmpDir, ExecuteTemplate, text, data, 100000)
+	if err != nil || len(notes) != 0 || !strings.Contains(out, data.ProjectContext) {
+		t.Fatalf("expected the whole prompt, got notes %v, err %v", notes, err)
+	}
+
+	out, notes, err = RenderWithin(tmpDir, ExecuteTemplate, text, data, 1000)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if tokens := EstimateTokens(out); tokens > 1000 {
+		t.Errorf("expected at most 1000 tokens, got %d", tokens)
+	}
+	for _, want := range []string{TaskSectionStart + "\ntask section\n" + TaskSectionEnd, "- cmd: go test ./...", "truncated by Hermes"} {
+		if !strings.Contains(out, want) {
+			t.Errorf("expected prompt to contain %q:\n%s", want, out)
+		}
+	}
+	if len(notes) != 2 || !strings.HasPrefix(notes[0], "project context") || !strings.HasPrefix(notes[1], "project instructions") {
+		t.Errorf("expected the project context and instructions to be shortened, got %v", notes)
+	}
+	if testTask.Description != strings.Repeat("describe the task\n", 50) {
+		t.Error("expected the caller's task to be left alone")
+	}
+
+	// What cannot be cut is sent anyway, with a note
+	_, notes, _ = RenderWithin(tmpDir, ExecuteTemplate, text, data, 10)
+	if len(notes) == 0 || !strings.Contains(notes[len(notes)-1], "still exceeds") {
+		t.Errorf("expected a note that the prompt still exceeds the budget, got %v", notes)
+	}
+}
+
+func TestMemory(t *testing.T) {
+	tmpDir, cleanup := setupTestDir(t)
+	defer cleanup()
+
+	if ReadMemory(tmpDir) != "" {
+		t.Error("expected no memory before anything is recorded")
+	}
+
+	output := "Done.\n" + MemoryStart + "\n- Run `make generate` before the tests\n- <learning>\n\n" + MemoryEnd + "\n" +
+		MemoryStart + "\n* API handlers live in internal/api\n" + MemoryEnd + "\n---HERMES_STATUS---\nSTATUS: COMPLETE\n"
+	notes := ParseMemory(output)
+	if len(notes) != 2 || notes[0] != "Run `make generate` before the tests" || notes[1] != "API handlers live in internal/api" {
+		t.Fatalf("unexpected learnings %q", notes)
+	}
+
+	if added, err := AppendMemory(tmpDir, "T001", notes); err != nil || added != 2 {
+		t.Fatalf("expected 2 learnings added, got %d, %v", added, err)
+	}
+	// Learnings already recorded are left out
+	if added, err := AppendMemory(tmpDir, "T002", []string{notes[0], "Migrations need a down step"}); err != nil || added != 1 {
+		t.Fatalf("expected 1 learning added, got %d, %v", added, err)
+	}
+
+	want := "- Run `make generate` before the tests (T001)\n- API handlers live in internal/api (T001)\n- Migrations need a down step (T002)"
+	if memory := ReadMemory(tmpDir); memory != want {
+		t.Errorf("expected memory %q, got %q", want, memory)
+	}
+
+	// Tasks running in parallel write the header once
+	parallelDir := t.TempDir()
+	var wg sync.WaitGroup
+	for n := 0; n < 8; n++ {
+		wg.Add(1)
+		go func(n int) {
+			defer wg.Done()
+			AppendMemory(parallelDir, fmt.Sprintf("T%03d", n), []string{fmt.Sprintf("Learning %d", n)})
+		}(n)
+	}
+	wg.Wait()
+	data, _ := os.ReadFile(MemoryPath(parallelDir))
+	if strings.Count(string(data), memoryHeader) != 1 || strings.Count(string(data), "- Learning ") != 8 {
+		t.Errorf("expected one header and 8 learnings, got:\n%s", data)
+	}
+}
@@ -0,0 +1,109 @@
+package prompt
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// MemoryFile is the file in .hermes with what the AI learned in earlier
+// tasks, added to the prompts of the next ones with ai.memory
+const MemoryFile = "MEMORY.md"
+
+// Markers of the block the AI records learnings in, one per line
+const (
+	MemoryStart = "---HERMES_MEMORY---"
+	MemoryEnd   = "---END_HERMES_MEMORY---"
+)
+
+const memoryHeader = `# Hermes Memory
+
+Learnings the AI recorded while working on tasks, added to the prompts of the
+next ones. Edit or remove entries that are no longer true.
+
+`
+
+// MemoryPath returns the path of the memory file of the project at basePath
+func MemoryPath(basePath string) string {
+	return filepath.Join(basePath, ".hermes", MemoryFile)
+}
+
+// ReadMemory returns the learnings recorded for the project at basePath,
+// without the file's header, "" when there are none
+func ReadMemory(basePath string) string {
+	data, err := os.ReadFile(MemoryPath(basePath))
+	if err != nil {
+		return ""
+	}
+	content := strings.ReplaceAll(string(data), "\r\n", "\n")
+	return strings.TrimSpace(strings.TrimPrefix(content, memoryHeader))
+}
+
+// ParseMemory returns the learnings of the HERMES_MEMORY blocks in AI output
+func ParseMemory(output string) []string {
+	var notes []string
+	for {
+		start := strings.Index(output, MemoryStart)
+		if start < 0 {
+			return notes
+		}
+		output = output[start+len(MemoryStart):]
+		end := strings.Index(output, MemoryEnd)
+		if end < 0 {
+			return notes
+		}
+		for _, line := range strings.Split(output[:end], "\n") {
+			line = strings.TrimSpace(strings.TrimLeft(strings.TrimSpace(line), "-*"))
+			if line != "" && !strings.HasPrefix(line, "<") {
+				notes = append(notes, line)
+			}
+		}
+		output = output[end+len(MemoryEnd):]
+	}
+}
+
+// AppendMemory adds the learnings of a task to the memory file of the
+// project at basePath, leaving out ones already recorded. It returns the
+// number added.
+func AppendMemory(basePath, taskID string, notes []string) (int, error) {
+	path := MemoryPath(basePath)
+	existing, err := os.ReadFile(path)
+	if err != nil && !os.IsNotExist(err) {
+		return 0, err
+	}
+
+	var sb strings.Builder
+	added := 0
+	for _, note := range notes {
+		if strings.Contains(string(existing), "- "+note+" (") || strings.Contains(sb.String(), "- "+note+" (") {
+			continue
+		}
+		fmt.Fprintf(&sb, "- %s (%s)\n", note, taskID)
+		added++
+	}
+	if added == 0 {
+		return 0, nil
+	}
+
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return 0, err
+	}
+	// Only the task creating the file writes the header, and appending keeps
+	// learnings of tasks running in parallel
+	content := sb.String()
+	f, err := os.OpenFile(path, os.O_CREATE|os.O_EXCL|os.O_WRONLY, 0644)
+	if err == nil {
+		content = memoryHeader + content
+	} else if os.IsExist(err) {
+		f, err = os.OpenFile(path, os.O_APPEND|os.O_WRONLY, 0644)
+	}
+	if err != nil {
+		return 0, err
+	}
+	defer f.Close()
+	if _, err := f.WriteString(content); err != nil {
+		return 0, err
+	}
+	return added, nil
+}
@@ -0,0 +1,141 @@
+package prompt
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"text/template"
+
+	"hermes/internal/config"
+	"hermes/internal/task"
+)
+
+// TemplatesDir is the directory in .hermes with the project's prompt
+// templates, <name>.tmpl, which replace the built-in prompts of that name
+const TemplatesDir = "prompts"
+
+// Names of the prompts that can be replaced by a template
+const (
+	TaskTemplate    = "task"    // Task section added to PROMPT.md for run loops
+	ExecuteTemplate = "execute" // Prompt a task is executed with, around PROMPT.md
+	PrdTemplate     = "prd"     // Parsing a PRD into task files
+)
+
+// TemplateData is what prompt templates are executed with. Fields a prompt
+// has no value for are empty, e.g. PRD in the task template.
+type TemplateData struct {
+	Task           *task.Task
+	Feature        *task.Feature // Feature of the task, nil when it cannot be read
+	Config         *config.Config
+	Instructions   string        // PROMPT.md with its task section, or a fix prompt (execute)
+	ProjectContext string        // Generated overview of the project with ai.projectContext (execute)
+	GitHistory     string        // Recent task commits with ai.gitHistory (execute)
+	Memory         string        // Learnings recorded in .hermes/MEMORY.md with ai.memory (execute)
+	RecordMemory   bool          // Whether the AI can record learnings in a HERMES_MEMORY block (execute)
+	Feedback       string        // Why the previous attempt was rejected (task)
+	NextSubtask    *task.Subtask // Subtask to work on when runs complete one subtask per iteration (task)
+	PRD            string        // PRD content (prd)
+}
+
+// templateFuncs are the functions prompt templates can use besides the
+// text/template built-ins
+var templateFuncs = template.FuncMap{
+	// list formats items as a Markdown list, "- (none specified)" when empty
+	"list": func(items []string) string {
+		if len(items) == 0 {
+			return "- (none specified)"
+		}
+		var sb strings.Builder
+		for _, item := range items {
+			sb.WriteString("- " + item + "\n")
+		}
+		return sb.String()
+	},
+	// command returns the command of a "cmd:" success criterion, "" for others
+	"command": func(criterion string) string {
+		if cmd, ok := task.CriterionCommand(criterion); ok {
+			return cmd
+		}
+		return ""
+	},
+	"join": strings.Join,
+}
+
+// NewTemplateData returns the data of prompt templates for a task of the
+// project at basePath, nil for prompts without a task
+func NewTemplateData(basePath string, t *task.Task) (TemplateData, error) {
+	cfg, err := config.Load(basePath)
+	if err != nil {
+		return TemplateData{}, err
+	}
+	data := TemplateData{Task: t, Config: cfg}
+	if t != nil && t.FeatureID != "" {
+		data.Feature, _ = task.NewReader(basePath).GetFeatureByID(t.FeatureID)
+	}
+	return data, nil
+}
+
+// TemplatePath returns the path of a prompt template of the project at basePath
+func TemplatePath(basePath, name string) string {
+	return filepath.Join(basePath, ".hermes", TemplatesDir, name+".tmpl")
+}
+
+// templatePaths returns the project templates that can replace a prompt, in
+// the order they are tried
+func templatePaths(basePath, name string, data TemplateData) []string {
+	var tags []string
+	if data.Task != nil {
+		tags = append(tags, data.Task.Tags...)
+	}
+	if data.Feature != nil {
+		tags = append(tags, data.Feature.Tags...)
+	}
+
+	var paths []string
+	for _, tag := range tags {
+		variant := strings.ToLower(strings.Join(strings.Fields(tag), "-"))
+		if data.Config != nil {
+			// Config keys are lower case once loaded, compare regardless of case
+			for t, v := range data.Config.AI.PromptTemplates {
+				if strings.EqualFold(t, tag) {
+					variant = v
+				}
+			}
+		}
+		if variant == "" || strings.ContainsAny(variant, `/\`) || strings.Contains(variant, "..") {
+			continue
+		}
+		paths = append(paths, TemplatePath(basePath, name+"-"+variant))
+	}
+	return append(paths, TemplatePath(basePath, name))
+}
+
+// Render executes the project's template of a prompt, or text, the built-in
+// template, when the project has none. A task uses the template for the first
+// of its tags, then of its feature's tags, the project has one for, e.g.
+// task-migration.tmpl for the tag migration, before <name>.tmpl.
+func Render(basePath, name, text string, data TemplateData) (string, error) {
+	source := "built-in " + name + " prompt"
+	for _, path := range templatePaths(basePath, name, data) {
+		custom, err := os.ReadFile(path)
+		if err == nil {
+			text, source = string(custom), path
+			break
+		}
+		if !errors.Is(err, os.ErrNotExist) {
+			return "", err
+		}
+	}
+
+	tmpl, err := template.New(name).Funcs(templateFuncs).Parse(text)
+	if err != nil {
+		return "", fmt.Errorf("invalid prompt template %s: %w", source, err)
+	}
+	var sb strings.Builder
+	if err := tmpl.Execute(&sb, data); err != nil {
+		return "", fmt.Errorf("invalid prompt template %s: %w", source, err)
+	}
+	return sb.String(), nil
+}
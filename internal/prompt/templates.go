@@ -94,3 +94,111 @@ func (i *Injector) CreateDefault() error {
 func (i *Injector) EnsureExists() error {
 	return i.CreateDefault()
 }
+
+// DefaultTaskTemplate is the task section added to PROMPT.md, between the
+// task section markers, when the project has no .hermes/prompts/task.tmpl.
+// Keep the "## Current Task:" heading, GetCurrentTaskID reads the task ID
+// from it.
+const DefaultTaskTemplate = `## Current Task: {{.Task.ID}}
+
+**Task:** {{.Task.ID}}: {{.Task.Name}}
+
+{{if .Task.Priority}}**Priority:** {{.Task.Priority}}
+
+{{end}}{{if .Task.EstimatedEffort}}**Estimated Effort:** {{.Task.EstimatedEffort}}
+
+{{end}}{{if .Task.Description}}### Description
+
+{{.Task.Description}}
+
+{{end}}{{if .Task.TechnicalDetails}}### Technical Details
+
+{{.Task.TechnicalDetails}}
+
+{{end}}{{if .Task.FilesToTouch}}**Files to Touch:**
+{{range .Task.FilesToTouch}}- {{.}}
+{{end}}
+{{end}}{{if .Task.Dependencies}}**Dependencies:**
+{{range .Task.Dependencies}}- {{.}}
+{{end}}
+{{end}}{{if .Task.SuccessCriteria}}**Success Criteria:**
+{{range .Task.SuccessCriteria}}{{with command .}}- [ ] ` + "`{{.}}`" + ` succeeds{{else}}- [ ] {{.}}{{end}}
+{{end}}
+{{if .Task.VerifyCommands}}Hermes runs the commands above after you report COMPLETE and only accepts the task if all of them succeed.
+
+{{end}}{{end}}{{if .Feedback}}### Previous Attempt Failed
+
+{{.Feedback}}
+
+{{end}}{{if .Task.Subtasks}}**Subtasks:**
+{{range .Task.Subtasks}}- [{{if eq .Status "COMPLETED"}}x{{else}} {{end}}] {{.ID}}: {{.Name}}
+{{end}}
+{{with .NextSubtask}}**Current Subtask:** {{.ID}}: {{.Name}}
+
+Work on the current subtask only and report COMPLETE when it is done. The remaining subtasks follow in later iterations.
+
+{{else}}Work through the open subtasks in order.
+
+{{end}}{{end}}### Instructions
+
+1. Review the task description and technical details
+2. Implement all requirements following project conventions
+3. Create or update files as specified
+4. Write tests for new functionality
+5. ***Writing mock code is strictly forbidden, except for test files!***
+6. Verify all success criteria are met
+7. Ensure code compiles without errors
+
+---
+## CRITICAL: HERMES_STATUS BLOCK IS MANDATORY
+
+**YOUR RESPONSE WILL BE REJECTED IF YOU DO NOT INCLUDE THE HERMES_STATUS BLOCK!**
+
+You MUST end your response with one of the status blocks below.
+Without this block, your work will NOT be saved and the task will be retried.
+
+---
+
+### Status Reporting (REQUIRED)
+
+**ALWAYS output one of these status blocks at the VERY END of your response:**
+
+**COMPLETE** - All success criteria met, code compiles, tests pass:
+` + "```" + `
+---HERMES_STATUS---
+STATUS: COMPLETE
+EXIT_SIGNAL: true
+RECOMMENDATION: Move to next task
+---END_HERMES_STATUS---
+` + "```" + `
+
+**BLOCKED** - Cannot proceed due to missing dependency or external blocker:
+` + "```" + `
+---HERMES_STATUS---
+STATUS: BLOCKED
+EXIT_SIGNAL: false
+RECOMMENDATION: <describe what is blocking>
+---END_HERMES_STATUS---
+` + "```" + `
+
+**AT_RISK** - Progressing but facing challenges that may cause delays:
+` + "```" + `
+---HERMES_STATUS---
+STATUS: AT_RISK
+EXIT_SIGNAL: false
+RECOMMENDATION: <describe the risk and mitigation>
+---END_HERMES_STATUS---
+` + "```" + `
+
+**IN_PROGRESS** - Still working, not yet complete:
+` + "```" + `
+---HERMES_STATUS---
+STATUS: IN_PROGRESS
+EXIT_SIGNAL: false
+RECOMMENDATION: <describe next steps>
+---END_HERMES_STATUS---
+` + "```" + `
+
+**REMEMBER: No status block = task will be retried!**
+
+`
@@ -0,0 +1,313 @@
+package recovery
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"time"
+
+	"hermes/internal/ai"
+	"hermes/internal/circuit"
+	"hermes/internal/git"
+	"hermes/internal/report"
+	"hermes/internal/task"
+)
+
+// RecentAttempts is how many of the latest task attempts are examined
+const RecentAttempts = 5
+
+// Thresholds above which a task is considered too large to finish in one loop
+const (
+	maxTaskDays     = 2
+	maxTaskFiles    = 5
+	maxTaskCriteria = 6
+)
+
+var (
+	providerErrorRegex = regexp.MustCompile(`(?i)rate.?limit|quota|\b429\b|overloaded|timed? ?out|deadline exceeded|unauthori[sz]ed|authentication|api key|executable file not found|command not found`)
+	missingDepRegex    = regexp.MustCompile(`(?i)undefined: \w+|cannot find (package|module)|no such file or directory|does not exist|not (yet )?implemented|missing dependency|depends on|requires? T\d+`)
+	taskRefRegex       = regexp.MustCompile(`\bT\d{3,}\b`)
+)
+
+// Suggestion is a concrete remediation for a halted run
+type Suggestion struct {
+	Title   string `json:"title"`
+	Detail  string `json:"detail"`
+	Command string `json:"command,omitempty"`
+}
+
+// Diagnosis explains why the circuit breaker opened and how to recover
+type Diagnosis struct {
+	TaskID      string       `json:"taskId,omitempty"`
+	TaskName    string       `json:"taskName,omitempty"`
+	Reason      string       `json:"reason"`
+	Opens       int          `json:"opens"` // Breaker TotalOpens the diagnosis was made for
+	Findings    []string     `json:"findings"`
+	Suggestions []Suggestion `json:"suggestions"`
+	Time        time.Time    `json:"time"`
+}
+
+// GetDiagnosisPath returns the file the latest diagnosis is stored in
+func GetDiagnosisPath(basePath string) string {
+	return filepath.Join(basePath, ".hermes", "diagnosis.json")
+}
+
+// ForBreaker returns the diagnosis for the breaker's current opening, generating
+// and saving it on first use. It returns nil while the breaker is not OPEN.
+func ForBreaker(basePath string, state *circuit.BreakerState) *Diagnosis {
+	if state == nil || state.State != circuit.StateOpen {
+		return nil
+	}
+	if d, err := Load(basePath); err == nil && d.Opens == state.TotalOpens {
+		return d
+	}
+	d := Diagnose(basePath, state)
+	Save(basePath, d)
+	return d
+}
+
+// Diagnose examines the recent attempts, the failing task and the working tree
+func Diagnose(basePath string, state *circuit.BreakerState) *Diagnosis {
+	d := &Diagnosis{
+		Reason: state.Reason,
+		Opens:  state.TotalOpens,
+		Time:   time.Now(),
+	}
+
+	var records []report.TaskRecord
+	provider := ""
+	if run, err := report.LoadRun(basePath, ""); err == nil {
+		provider = run.Provider
+		records = run.Tasks
+		if len(records) > RecentAttempts {
+			records = records[len(records)-RecentAttempts:]
+		}
+	}
+
+	taskID, failures := mostFailedTask(records)
+	reader := task.NewReader(basePath)
+	var t *task.Task
+	if taskID != "" {
+		t, _ = reader.GetTaskByID(taskID)
+	}
+	if t == nil {
+		// Fall back to the task the loop would pick up next
+		t, _ = reader.GetNextTask()
+	}
+	if t != nil {
+		d.TaskID = t.ID
+		d.TaskName = t.Name
+	}
+
+	var evidence strings.Builder
+	for _, rec := range records {
+		if rec.TaskID == d.TaskID {
+			evidence.WriteString(rec.Error + "\n")
+		}
+	}
+	if d.TaskID != "" {
+		if attempt, err := report.LoadAttempt(basePath, d.TaskID); err == nil {
+			evidence.WriteString(attempt.Error + "\n" + attempt.Output)
+		}
+	}
+	text := evidence.String()
+
+	if failures > 1 {
+		d.Findings = append(d.Findings, fmt.Sprintf("%s did not complete in %d of the last %d attempts", d.TaskID, failures, len(records)))
+	}
+
+	var stat *git.DiffStat
+	if g := git.New(basePath); g.IsRepository() {
+		stat, _ = g.GetWorkingDiffStat()
+	}
+	if stat != nil {
+		if stat.Files == 0 {
+			d.Findings = append(d.Findings, "No uncommitted changes in the working tree")
+		} else {
+			d.Findings = append(d.Findings, "Uncommitted changes: "+stat.String())
+		}
+	}
+
+	if t != nil {
+		if size := taskSize(t); size != "" {
+			d.Findings = append(d.Findings, fmt.Sprintf("%s is large (%s)", t.ID, size))
+			d.Suggestions = append(d.Suggestions, Suggestion{
+				Title:   "Split the task",
+				Detail:  fmt.Sprintf("%s is likely too large to finish in one loop", t.ID),
+				Command: "hermes task split " + t.ID,
+			})
+		} else if failures >= 3 {
+			d.Suggestions = append(d.Suggestions, Suggestion{
+				Title:   "Split the task",
+				Detail:  fmt.Sprintf("%s keeps failing; smaller steps are easier to verify", t.ID),
+				Command: "hermes task split " + t.ID,
+			})
+		}
+	}
+
+	if m := providerErrorRegex.FindString(text); m != "" || strings.Contains(text, "missing HERMES_STATUS block") {
+		detail := "The provider did not return the HERMES_STATUS block"
+		if m != "" {
+			detail = fmt.Sprintf("The provider reported %q", m)
+		}
+		d.Findings = append(d.Findings, detail)
+		d.Suggestions = append(d.Suggestions, Suggestion{
+			Title:   "Change provider",
+			Detail:  detail + "; try another one",
+			Command: "hermes run --ai " + alternativeProvider(provider),
+		})
+	}
+
+	if t != nil {
+		if m := missingDepRegex.FindString(text); m != "" {
+			d.Findings = append(d.Findings, fmt.Sprintf("Output mentions %q", m))
+			s := Suggestion{
+				Title:  "Add missing dependency info",
+				Detail: fmt.Sprintf("%s may need work from another task first", t.ID),
+			}
+			if dep := dependencyCandidate(reader, t, text); dep != "" {
+				s.Detail = fmt.Sprintf("%s may need %s to be finished first", t.ID, dep)
+				s.Command = fmt.Sprintf("hermes task edit %s --add-dep %s", t.ID, dep)
+			}
+			d.Suggestions = append(d.Suggestions, s)
+		}
+	}
+
+	if stat != nil && stat.Files == 0 && d.TaskID != "" {
+		d.Suggestions = append(d.Suggestions, Suggestion{
+			Title:   "Clarify the task",
+			Detail:  "No files were changed; the description or success criteria may be ambiguous",
+			Command: fmt.Sprintf("hermes explain \"why is %s not making progress?\"", d.TaskID),
+		})
+	}
+
+	resume := Suggestion{Title: "Reset and resume", Detail: "Reset the circuit breaker once the cause is addressed", Command: "hermes reset"}
+	if d.TaskID != "" {
+		if _, err := report.LoadAttempt(basePath, d.TaskID); err == nil {
+			resume.Detail = "Reset the circuit breaker and retry with the captured context"
+			resume.Command = "hermes reset && hermes replay " + d.TaskID
+		}
+	}
+	d.Suggestions = append(d.Suggestions, resume)
+
+	return d
+}
+
+// mostFailedTask returns the task with the most unfinished attempts in records
+func mostFailedTask(records []report.TaskRecord) (string, int) {
+	counts := make(map[string]int)
+	bestID, best := "", 0
+	for i := len(records) - 1; i >= 0; i-- {
+		rec := records[i]
+		if rec.Outcome == report.OutcomeCompleted {
+			continue
+		}
+		counts[rec.TaskID]++
+		if counts[rec.TaskID] > best {
+			bestID, best = rec.TaskID, counts[rec.TaskID]
+		}
+	}
+	return bestID, best
+}
+
+// taskSize describes why a task is considered too large, or returns ""
+func taskSize(t *task.Task) string {
+	var reasons []string
+	if days, ok := task.EffortDays(t.EstimatedEffort); ok && days > maxTaskDays {
+		reasons = append(reasons, "effort "+t.EstimatedEffort)
+	}
+	if len(t.FilesToTouch) > maxTaskFiles {
+		reasons = append(reasons, fmt.Sprintf("%d files", len(t.FilesToTouch)))
+	}
+	if len(t.SuccessCriteria) > maxTaskCriteria {
+		reasons = append(reasons, fmt.Sprintf("%d success criteria", len(t.SuccessCriteria)))
+	}
+	return strings.Join(reasons, ", ")
+}
+
+// dependencyCandidate picks an unfinished task the failing task may depend on:
+// a task named in the output, or one whose files the output mentions
+func dependencyCandidate(reader *task.Reader, t *task.Task, text string) string {
+	tasks, err := reader.GetAllTasks()
+	if err != nil {
+		return ""
+	}
+	isDep := make(map[string]bool)
+	for _, dep := range t.Dependencies {
+		isDep[dep] = true
+	}
+	usable := func(c *task.Task) bool {
+		return c.ID != t.ID && c.Status != task.StatusCompleted && !isDep[c.ID]
+	}
+
+	for _, id := range taskRefRegex.FindAllString(text, -1) {
+		for i := range tasks {
+			if tasks[i].ID == id && usable(&tasks[i]) {
+				return id
+			}
+		}
+	}
+	for i := range tasks {
+		if !usable(&tasks[i]) {
+			continue
+		}
+		for _, f := range tasks[i].FilesToTouch {
+			fields := strings.Fields(f)
+			if len(fields) == 0 {
+				continue
+			}
+			if path := strings.Trim(fields[0], "`"); path != "" && strings.Contains(text, path) {
+				return tasks[i].ID
+			}
+		}
+	}
+	return ""
+}
+
+// alternativeProvider returns an available provider other than current
+func alternativeProvider(current string) string {
+	for _, name := range ai.GetAvailableProviders() {
+		if name != current {
+			return name
+		}
+	}
+	return "<provider>"
+}
+
+// Save writes the diagnosis to .hermes/diagnosis.json
+func Save(basePath string, d *Diagnosis) error {
+	data, err := json.MarshalIndent(d, "", "  ")
+	if err != nil {
+		return err
+	}
+	path := GetDiagnosisPath(basePath)
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return err
+	}
+	return os.WriteFile(path, data, 0644)
+}
+
+// Load reads the latest saved diagnosis
+func Load(basePath string) (*Diagnosis, error) {
+	data, err := os.ReadFile(GetDiagnosisPath(basePath))
+	if err != nil {
+		return nil, err
+	}
+	var d Diagnosis
+	if err := json.Unmarshal(data, &d); err != nil {
+		return nil, err
+	}
+	return &d, nil
+}
+
+// Clear removes the saved diagnosis, e.g. after the breaker is reset
+func Clear(basePath string) error {
+	err := os.Remove(GetDiagnosisPath(basePath))
+	if os.IsNotExist(err) {
+		return nil
+	}
+	return err
+}
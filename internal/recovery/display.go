@@ -0,0 +1,50 @@
+package recovery
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/fatih/color"
+)
+
+// Print prints the diagnosis and its suggested remediations
+func (d *Diagnosis) Print() {
+	bold := color.New(color.Bold)
+	cyan := color.New(color.FgCyan)
+
+	fmt.Println()
+	bold.Println("Diagnosis")
+	fmt.Println(strings.Repeat("-", 60))
+	if d.TaskID != "" {
+		fmt.Printf("Task:    %s - %s\n", d.TaskID, d.TaskName)
+	}
+	if d.Reason != "" {
+		fmt.Printf("Reason:  %s\n", d.Reason)
+	}
+	for _, f := range d.Findings {
+		fmt.Printf("  - %s\n", f)
+	}
+
+	fmt.Println()
+	bold.Println("Suggested remediations")
+	fmt.Println(strings.Repeat("-", 60))
+	for i, s := range d.Suggestions {
+		fmt.Printf("  %d. %s: %s\n", i+1, s.Title, s.Detail)
+		if s.Command != "" {
+			cyan.Printf("     %s\n", s.Command)
+		}
+	}
+}
+
+// Summary returns the suggestions as a single line for logs
+func (d *Diagnosis) Summary() string {
+	var parts []string
+	for _, s := range d.Suggestions {
+		if s.Command != "" {
+			parts = append(parts, fmt.Sprintf("%s (%s)", s.Title, s.Command))
+		} else {
+			parts = append(parts, s.Title)
+		}
+	}
+	return strings.Join(parts, "; ")
+}
@@ -0,0 +1,114 @@
+package recovery
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"hermes/internal/circuit"
+	"hermes/internal/report"
+)
+
+const testFeature = `# Feature 1: Billing
+
+**Feature ID:** F001
+**Status:** IN_PROGRESS
+
+### T001: Invoice generation
+
+**Status:** IN_PROGRESS
+**Priority:** P1
+**Estimated Effort:** 4 days
+
+#### Description
+
+Generate invoices.
+
+### T002: Tax tables
+
+**Status:** NOT_STARTED
+**Priority:** P2
+**Files to Touch:** billing/tax.go
+`
+
+func setupTestDir(t *testing.T) string {
+	tmpDir := t.TempDir()
+	tasksDir := filepath.Join(tmpDir, ".hermes", "tasks")
+	if err := os.MkdirAll(tasksDir, 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(tasksDir, "001-billing.md"), []byte(testFeature), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	recorder := report.NewRecorder(tmpDir, "sequential", "claude")
+	for i := 0; i < 3; i++ {
+		recorder.RecordTask(report.TaskRecord{
+			TaskID:  "T001",
+			Outcome: report.OutcomeFailed,
+			Error:   "rate limit exceeded",
+			Prompt:  "prompt",
+			Output:  "open billing/tax.go: no such file or directory",
+		})
+	}
+	return tmpDir
+}
+
+func TestDiagnose(t *testing.T) {
+	tmpDir := setupTestDir(t)
+	state := &circuit.BreakerState{State: circuit.StateOpen, Reason: "No progress in 3 loops", TotalOpens: 1}
+
+	d := Diagnose(tmpDir, state)
+	if d.TaskID != "T001" {
+		t.Fatalf("expected diagnosis for T001, got %q", d.TaskID)
+	}
+
+	commands := make(map[string]string)
+	for _, s := range d.Suggestions {
+		commands[s.Title] = s.Command
+	}
+	if commands["Split the task"] != "hermes task split T001" {
+		t.Errorf("expected split suggestion, got %v", commands)
+	}
+	if !strings.HasPrefix(commands["Change provider"], "hermes run --ai ") {
+		t.Errorf("expected provider suggestion, got %v", commands)
+	}
+	if commands["Add missing dependency info"] != "hermes task edit T001 --add-dep T002" {
+		t.Errorf("expected dependency suggestion on T002, got %v", commands)
+	}
+	if commands["Reset and resume"] != "hermes reset && hermes replay T001" {
+		t.Errorf("expected replay suggestion, got %v", commands)
+	}
+	if len(d.Findings) == 0 || !strings.Contains(d.Findings[0], "3 of the last 3") {
+		t.Errorf("expected repeated failure finding, got %v", d.Findings)
+	}
+}
+
+func TestForBreaker(t *testing.T) {
+	tmpDir := setupTestDir(t)
+
+	if d := ForBreaker(tmpDir, &circuit.BreakerState{State: circuit.StateClosed}); d != nil {
+		t.Error("expected no diagnosis while the breaker is closed")
+	}
+
+	state := &circuit.BreakerState{State: circuit.StateOpen, TotalOpens: 2}
+	first := ForBreaker(tmpDir, state)
+	if first == nil {
+		t.Fatal("expected a diagnosis")
+	}
+	saved, err := Load(tmpDir)
+	if err != nil || saved.Opens != 2 {
+		t.Fatalf("expected saved diagnosis for opening 2, got %+v (%v)", saved, err)
+	}
+	if again := ForBreaker(tmpDir, state); !again.Time.Equal(first.Time) {
+		t.Error("expected the saved diagnosis to be reused for the same opening")
+	}
+
+	if err := Clear(tmpDir); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := Load(tmpDir); err == nil {
+		t.Error("expected diagnosis to be removed")
+	}
+}
@@ -0,0 +1,77 @@
+package report
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+
+	"hermes/internal/task"
+)
+
+// maxCapturedOutput bounds the AI output kept for a replay
+const maxCapturedOutput = 20000
+
+// Attempt captures the prompt and AI output of a task's latest unsuccessful
+// attempt so 'hermes replay' can retry it with full context
+type Attempt struct {
+	TaskID   string    `json:"taskId"`
+	TaskName string    `json:"taskName"`
+	RunID    string    `json:"runId"`
+	Outcome  string    `json:"outcome"`
+	Error    string    `json:"error,omitempty"`
+	Prompt   string    `json:"prompt"`
+	Output   string    `json:"output,omitempty"`
+	Time     time.Time `json:"time"`
+}
+
+// GetAttemptsDir returns the directory where task attempts are captured
+func GetAttemptsDir(basePath string) string {
+	return filepath.Join(basePath, ".hermes", "attempts")
+}
+
+func attemptPath(basePath, taskID string) string {
+	return filepath.Join(GetAttemptsDir(basePath), task.FileSafeID(taskID)+".json")
+}
+
+// SaveAttempt stores the attempt, replacing any earlier capture for the task
+func SaveAttempt(basePath string, attempt *Attempt) error {
+	if err := os.MkdirAll(GetAttemptsDir(basePath), 0755); err != nil {
+		return err
+	}
+	if len(attempt.Output) > maxCapturedOutput {
+		// Keep the tail: the end of the output explains why the attempt stopped
+		attempt.Output = "..." + attempt.Output[len(attempt.Output)-maxCapturedOutput:]
+	}
+	data, err := json.MarshalIndent(attempt, "", "  ")
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(attemptPath(basePath, attempt.TaskID), data, 0644)
+}
+
+// LoadAttempt loads the captured attempt for a task
+func LoadAttempt(basePath, taskID string) (*Attempt, error) {
+	data, err := os.ReadFile(attemptPath(basePath, taskID))
+	if os.IsNotExist(err) {
+		return nil, fmt.Errorf("no captured attempt for task %s", taskID)
+	}
+	if err != nil {
+		return nil, err
+	}
+	var attempt Attempt
+	if err := json.Unmarshal(data, &attempt); err != nil {
+		return nil, fmt.Errorf("failed to parse attempt for task %s: %w", taskID, err)
+	}
+	return &attempt, nil
+}
+
+// ClearAttempt removes the captured attempt for a task
+func ClearAttempt(basePath, taskID string) error {
+	err := os.Remove(attemptPath(basePath, taskID))
+	if os.IsNotExist(err) {
+		return nil
+	}
+	return err
+}
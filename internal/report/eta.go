@@ -0,0 +1,96 @@
+package report
+
+import (
+	"fmt"
+	"time"
+
+	"hermes/internal/task"
+)
+
+// Estimate forecasts the time left in a run from how long completed tasks of
+// each effort took in recorded runs and from the throughput of the run itself
+type Estimate struct {
+	perTask map[string]time.Duration // Expected time of each pending task, 0 without history
+	workers int
+}
+
+// NewEstimate returns an estimate for the pending tasks, worked on by workers
+// tasks at a time. Tasks whose effort has no history use the average of all
+// completed tasks.
+func NewEstimate(stats *Stats, pending []task.Task, workers int) *Estimate {
+	if workers < 1 {
+		workers = 1
+	}
+	byEffort := make(map[string]time.Duration)
+	var total time.Duration
+	var tasks int
+	if stats != nil {
+		for _, e := range stats.Efforts {
+			byEffort[e.Effort] = e.AvgDuration
+			total += e.AvgDuration * time.Duration(e.Tasks)
+			tasks += e.Tasks
+		}
+	}
+	var average time.Duration
+	if tasks > 0 {
+		average = total / time.Duration(tasks)
+	}
+
+	e := &Estimate{perTask: make(map[string]time.Duration, len(pending)), workers: workers}
+	for _, t := range pending {
+		_, label := normalizeEffort(t.EstimatedEffort)
+		d, ok := byEffort[label]
+		if !ok {
+			d = average
+		}
+		e.perTask[t.ID] = d
+	}
+	return e
+}
+
+// Complete removes a finished task from the estimate
+func (e *Estimate) Complete(taskID string) {
+	delete(e.perTask, taskID)
+}
+
+// Remaining returns the estimated time left, given that completed tasks were
+// finished in elapsed since the run started. Both the history and the current
+// throughput are averaged when available; false means neither is.
+func (e *Estimate) Remaining(completed int, elapsed time.Duration) (time.Duration, bool) {
+	if len(e.perTask) == 0 {
+		return 0, true
+	}
+
+	var history time.Duration
+	for _, d := range e.perTask {
+		history += d
+	}
+	history /= time.Duration(e.workers)
+
+	var throughput time.Duration
+	if completed > 0 && elapsed > 0 {
+		throughput = elapsed / time.Duration(completed) * time.Duration(len(e.perTask))
+	}
+
+	switch {
+	case history > 0 && throughput > 0:
+		return (history + throughput) / 2, true
+	case history > 0:
+		return history, true
+	case throughput > 0:
+		return throughput, true
+	}
+	return 0, false
+}
+
+// FormatETA formats an estimated remaining time, e.g. "~1h 25m"
+func FormatETA(d time.Duration) string {
+	d = d.Round(time.Minute)
+	switch {
+	case d < time.Minute:
+		return "<1m"
+	case d < time.Hour:
+		return fmt.Sprintf("~%dm", int(d.Minutes()))
+	}
+	return fmt.Sprintf("~%dh %dm", int(d.Hours()), int(d.Minutes())%60)
+}
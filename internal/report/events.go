@@ -0,0 +1,92 @@
+package report
+
+import (
+	"strings"
+
+	"hermes/internal/task"
+	"hermes/internal/webhook"
+)
+
+// taskEvents maps attempt outcomes to the webhook events they emit
+var taskEvents = map[string]string{
+	OutcomeCompleted: webhook.TaskCompleted,
+	OutcomeFailed:    webhook.TaskFailed,
+	OutcomeBlocked:   webhook.TaskBlocked,
+}
+
+// emitRunStarted announces the run to webhooks
+func (r *Recorder) emitRunStarted(resumed bool) {
+	webhook.Emit(webhook.RunStarted, r.run.ID, map[string]any{
+		"mode":     r.run.Mode,
+		"provider": r.run.Provider,
+		"resumed":  resumed,
+	})
+}
+
+// emitRunFinished sends the run summary to webhooks
+func (r *Recorder) emitRunFinished() {
+	s := r.run.Summarize()
+	webhook.Emit(webhook.RunFinished, r.run.ID, map[string]any{
+		"mode":           r.run.Mode,
+		"elapsedSeconds": r.run.Elapsed().Seconds(),
+		"attempts":       s.Attempts,
+		"completed":      s.Completed,
+		"failed":         s.Failed,
+		"blocked":        s.Blocked,
+		"cost":           s.TotalCost,
+		"commits":        len(r.run.Commits),
+		"tags":           r.run.Tags,
+	})
+}
+
+// emitTask sends the event of a task attempt. Completed tasks carry the
+// diffstat of their commit and may complete their feature.
+func (r *Recorder) emitTask(rec TaskRecord) {
+	eventType, ok := taskEvents[rec.Outcome]
+	if !ok {
+		return
+	}
+	data := map[string]any{
+		"taskId":          rec.TaskID,
+		"taskName":        rec.TaskName,
+		"featureId":       rec.FeatureID,
+		"durationSeconds": rec.Duration.Seconds(),
+		"cost":            rec.Cost,
+	}
+	if rec.Error != "" {
+		data["error"] = rec.Error
+	}
+	if rec.Outcome == OutcomeCompleted {
+		if c := r.findTaskCommit(rec.TaskID); c != nil {
+			data["commit"] = c.Hash
+			data["diffstat"] = c.Stat
+		}
+	}
+	webhook.Emit(eventType, r.run.ID, data)
+
+	if rec.Outcome != OutcomeCompleted || rec.FeatureID == "" || r.featuresDone[rec.FeatureID] {
+		return
+	}
+	reader := task.NewReader(r.basePath)
+	if complete, _ := reader.IsFeatureComplete(rec.FeatureID); complete {
+		r.featuresDone[rec.FeatureID] = true
+		feature, _ := reader.GetFeatureByID(rec.FeatureID)
+		webhook.Emit(webhook.FeatureCompleted, r.run.ID, map[string]any{
+			"featureId":     feature.ID,
+			"featureName":   feature.Name,
+			"targetVersion": feature.TargetVersion,
+			"tasks":         len(feature.Tasks),
+		})
+	}
+}
+
+// findTaskCommit returns the latest recorded commit written for a task
+func (r *Recorder) findTaskCommit(taskID string) *Commit {
+	for i := len(r.run.Commits) - 1; i >= 0; i-- {
+		msg := r.run.Commits[i].Message
+		if strings.Contains(msg, "("+taskID+")") || strings.Contains(msg, "(task "+taskID+")") {
+			return &r.run.Commits[i]
+		}
+	}
+	return nil
+}
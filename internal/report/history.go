@@ -0,0 +1,120 @@
+package report
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+
+	"hermes/internal/task"
+)
+
+// maxHistoryAttempts bounds the attempts kept in a task's history
+const maxHistoryAttempts = 100
+
+// AttemptEntry is a single attempt in a task's history
+type AttemptEntry struct {
+	RunID    string        `json:"runId"`
+	Outcome  string        `json:"outcome"`
+	Subtask  string        `json:"subtask,omitempty"` // Subtask the attempt completed
+	Error    string        `json:"error,omitempty"`
+	Duration time.Duration `json:"duration"`
+	Time     time.Time     `json:"time"`
+}
+
+// TaskHistory is the attempt history of a task, kept across runs so retry
+// limits hold even when a run is restarted
+type TaskHistory struct {
+	TaskID   string         `json:"taskId"`
+	Attempts []AttemptEntry `json:"attempts"`
+	ResetAt  time.Time      `json:"resetAt,omitzero"` // Failures before this time are forgiven
+}
+
+func historyPath(basePath, taskID string) string {
+	return filepath.Join(GetAttemptsDir(basePath), task.FileSafeID(taskID)+".history.json")
+}
+
+// LoadTaskHistory loads a task's attempt history, which is empty for a task
+// that was never attempted
+func LoadTaskHistory(basePath, taskID string) (*TaskHistory, error) {
+	h := &TaskHistory{TaskID: taskID}
+	data, err := os.ReadFile(historyPath(basePath, taskID))
+	if os.IsNotExist(err) {
+		return h, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	if err := json.Unmarshal(data, h); err != nil {
+		return nil, fmt.Errorf("failed to parse attempt history of task %s: %w", taskID, err)
+	}
+	return h, nil
+}
+
+func (h *TaskHistory) save(basePath string) error {
+	if err := os.MkdirAll(GetAttemptsDir(basePath), 0755); err != nil {
+		return err
+	}
+	data, err := json.MarshalIndent(h, "", "  ")
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(historyPath(basePath, h.TaskID), data, 0644)
+}
+
+// appendHistory adds an attempt to its task's history
+func appendHistory(basePath, runID string, rec TaskRecord) error {
+	h, err := LoadTaskHistory(basePath, rec.TaskID)
+	if err != nil {
+		return err
+	}
+	h.Attempts = append(h.Attempts, AttemptEntry{
+		RunID:    runID,
+		Outcome:  rec.Outcome,
+		Subtask:  rec.Subtask,
+		Error:    rec.Error,
+		Duration: rec.Duration,
+		Time:     rec.Time,
+	})
+	if len(h.Attempts) > maxHistoryAttempts {
+		h.Attempts = h.Attempts[len(h.Attempts)-maxHistoryAttempts:]
+	}
+	return h.save(basePath)
+}
+
+// ResetTaskHistory forgives a task's failures so far, e.g. when it is
+// unblocked by hand, keeping the attempts for reference
+func ResetTaskHistory(basePath, taskID string) error {
+	h, err := LoadTaskHistory(basePath, taskID)
+	if err != nil {
+		return err
+	}
+	if len(h.Attempts) == 0 {
+		return nil
+	}
+	h.ResetAt = time.Now()
+	return h.save(basePath)
+}
+
+// Failures returns the number of attempts in a row, since the last reset,
+// that neither completed the task nor one of its subtasks
+func (h *TaskHistory) Failures() int {
+	failures := 0
+	for _, a := range h.Attempts {
+		switch {
+		case !a.Time.After(h.ResetAt):
+		case a.Outcome == OutcomeCompleted || a.Subtask != "":
+			failures = 0
+		default:
+			failures++
+		}
+	}
+	return failures
+}
+
+// RetriesExhausted returns true when the task failed more than maxRetries
+// times in a row. A maxRetries of 0 means no limit.
+func (h *TaskHistory) RetriesExhausted(maxRetries int) bool {
+	return maxRetries > 0 && h.Failures() > maxRetries
+}
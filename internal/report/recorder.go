@@ -0,0 +1,186 @@
+package report
+
+import (
+	"fmt"
+	"path/filepath"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Recorder collects run data and persists it to the project's run store
+type Recorder struct {
+	run          *Run
+	store        Store
+	basePath     string
+	featuresDone map[string]bool // Features whose completion was announced
+	mu           sync.Mutex
+}
+
+// NewRecorder creates a recorder for a new run
+func NewRecorder(basePath, mode, provider string) *Recorder {
+	now := time.Now()
+	id := now.Format("20060102-150405.000000")
+	r := &Recorder{
+		run: &Run{
+			ID:        id,
+			Mode:      mode,
+			Provider:  provider,
+			StartTime: now,
+		},
+		store:        OpenStore(basePath),
+		basePath:     basePath,
+		featuresDone: make(map[string]bool),
+	}
+	r.emitRunStarted(false)
+	return r
+}
+
+// ResumeRecorder continues recording an interrupted run, so a resumed run is
+// reported as a single run
+func ResumeRecorder(basePath, id string) (*Recorder, error) {
+	if id == "" {
+		return nil, fmt.Errorf("no run to resume")
+	}
+	run, err := LoadRun(basePath, id)
+	if err != nil {
+		return nil, err
+	}
+	run.EndTime = time.Time{}
+	r := &Recorder{run: run, store: OpenStore(basePath), basePath: basePath, featuresDone: make(map[string]bool)}
+	r.emitRunStarted(true)
+	return r, nil
+}
+
+// GetRunsDir returns the directory where run records are stored
+func GetRunsDir(basePath string) string {
+	return filepath.Join(basePath, ".hermes", "runs")
+}
+
+// RecordTask records a task attempt, persists the run and adds the attempt to
+// the task's history. Attempts that carry a prompt are captured for 'hermes
+// replay' until the task completes.
+func (r *Recorder) RecordTask(rec TaskRecord) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if rec.Time.IsZero() {
+		rec.Time = time.Now()
+	}
+	r.captureAttempt(rec)
+	rec.Error = excerpt(rec.Error, 500)
+	r.run.Tasks = append(r.run.Tasks, rec)
+	r.save()
+	appendHistory(r.basePath, r.run.ID, rec)
+	r.emitTask(rec)
+}
+
+// RecordCommit records a git commit with its diffstat and persists the run
+func (r *Recorder) RecordCommit(hash, message, stat string) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.run.Commits = append(r.run.Commits, Commit{Hash: hash, Message: message, Stat: stat})
+	r.save()
+}
+
+// RecordTag records a git tag and persists the run
+func (r *Recorder) RecordTag(tag string) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.run.Tags = append(r.run.Tags, tag)
+	r.save()
+}
+
+// Finish marks the run as ended and persists it
+func (r *Recorder) Finish() error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.run.EndTime = time.Now()
+	r.emitRunFinished()
+	return r.save()
+}
+
+// GetRun returns the run being recorded
+func (r *Recorder) GetRun() *Run {
+	return r.run
+}
+
+// PendingDuration returns the time spent on attempts of a task since it last
+// completed, across the recorded runs and the run being recorded
+func (r *Recorder) PendingDuration(taskID string) time.Duration {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	runs, _ := r.store.LoadRuns()
+	runs = slices.DeleteFunc(runs, func(run *Run) bool { return run.ID == r.run.ID })
+	return PendingDuration(append(runs, r.run), taskID)
+}
+
+func (r *Recorder) captureAttempt(rec TaskRecord) {
+	if rec.Outcome == OutcomeCompleted {
+		ClearAttempt(r.basePath, rec.TaskID)
+		return
+	}
+	if rec.Prompt == "" {
+		return
+	}
+	SaveAttempt(r.basePath, &Attempt{
+		TaskID:   rec.TaskID,
+		TaskName: rec.TaskName,
+		RunID:    r.run.ID,
+		Outcome:  rec.Outcome,
+		Error:    rec.Error,
+		Prompt:   rec.Prompt,
+		Output:   rec.Output,
+		Time:     rec.Time,
+	})
+}
+
+func (r *Recorder) save() error {
+	return r.store.SaveRun(r.run)
+}
+
+// LoadRuns loads all recorded runs ordered from oldest to newest
+func LoadRuns(basePath string) ([]*Run, error) {
+	return OpenStore(basePath).LoadRuns()
+}
+
+// LoadRun loads a run by ID or a prefix matching only one run, such as the
+// date and time without the microseconds, or the latest run when id is empty
+func LoadRun(basePath, id string) (*Run, error) {
+	runs, err := LoadRuns(basePath)
+	if err != nil {
+		return nil, err
+	}
+	if len(runs) == 0 {
+		return nil, fmt.Errorf("no recorded runs found, run 'hermes run' first")
+	}
+	if id == "" {
+		return runs[len(runs)-1], nil
+	}
+	var matches []*Run
+	for _, run := range runs {
+		if run.ID == id {
+			return run, nil
+		}
+		if strings.HasPrefix(run.ID, id) {
+			matches = append(matches, run)
+		}
+	}
+	switch len(matches) {
+	case 0:
+		return nil, fmt.Errorf("run %s not found", id)
+	case 1:
+		return matches[0], nil
+	}
+	return nil, fmt.Errorf("run %s matches %d runs, give more of its ID", id, len(matches))
+}
+
+// excerpt shortens s to at most max characters
+func excerpt(s string, max int) string {
+	s = strings.TrimSpace(s)
+	runes := []rune(s)
+	if len(runes) <= max {
+		return s
+	}
+	return string(runes[:max]) + "..."
+}
@@ -0,0 +1,188 @@
+package report
+
+import (
+	"bytes"
+	"fmt"
+	"html/template"
+	"strings"
+	"time"
+
+	"hermes/internal/circuit"
+)
+
+// Report combines a run with the circuit breaker incidents that happened during it
+type Report struct {
+	Run       *Run
+	Summary   Summary
+	Incidents []circuit.HistoryEntry
+}
+
+// New builds a report for a run using the breaker history of basePath
+func New(basePath string, run *Run) *Report {
+	rep := &Report{
+		Run:     run,
+		Summary: run.Summarize(),
+	}
+
+	history, _ := circuit.New(basePath).GetHistory()
+	end := run.EndTime
+	if end.IsZero() {
+		end = time.Now()
+	}
+	for _, entry := range history {
+		if entry.Timestamp.Before(run.StartTime) || entry.Timestamp.After(end) {
+			continue
+		}
+		rep.Incidents = append(rep.Incidents, entry)
+	}
+
+	return rep
+}
+
+// Failures returns the failed and blocked task attempts
+func (r *Report) Failures() []TaskRecord {
+	var failures []TaskRecord
+	for _, t := range r.Run.Tasks {
+		if t.Outcome == OutcomeFailed || t.Outcome == OutcomeBlocked {
+			failures = append(failures, t)
+		}
+	}
+	return failures
+}
+
+// Markdown renders the report as Markdown
+func (r *Report) Markdown() string {
+	var sb strings.Builder
+	run := r.Run
+
+	fmt.Fprintf(&sb, "# Hermes Run Report: %s\n\n", run.ID)
+	fmt.Fprintf(&sb, "| | |\n|---|---|\n")
+	fmt.Fprintf(&sb, "| Started | %s |\n", run.StartTime.Format("2006-01-02 15:04:05"))
+	if !run.EndTime.IsZero() {
+		fmt.Fprintf(&sb, "| Finished | %s |\n", run.EndTime.Format("2006-01-02 15:04:05"))
+	}
+	fmt.Fprintf(&sb, "| Duration | %s |\n", run.Elapsed().Round(time.Second))
+	fmt.Fprintf(&sb, "| Mode | %s |\n", run.Mode)
+	fmt.Fprintf(&sb, "| Provider | %s |\n", run.Provider)
+	fmt.Fprintf(&sb, "| Tasks completed | %d |\n", r.Summary.Completed)
+	fmt.Fprintf(&sb, "| Failed attempts | %d |\n", r.Summary.Failed)
+	fmt.Fprintf(&sb, "| Blocked | %d |\n", r.Summary.Blocked)
+	fmt.Fprintf(&sb, "| Total cost | $%.4f |\n\n", r.Summary.TotalCost)
+
+	sb.WriteString("## Tasks\n\n")
+	if len(run.Tasks) == 0 {
+		sb.WriteString("No task attempts recorded.\n\n")
+	} else {
+		sb.WriteString("| Task | Name | Outcome | Duration | Cost |\n|---|---|---|---|---|\n")
+		for _, t := range run.Tasks {
+			fmt.Fprintf(&sb, "| %s | %s | %s | %s | $%.4f |\n",
+				t.TaskID, escapeTable(t.TaskName), t.Outcome, t.Duration.Round(time.Second), t.Cost)
+		}
+		sb.WriteString("\n")
+	}
+
+	if failures := r.Failures(); len(failures) > 0 {
+		sb.WriteString("## Failures\n\n")
+		for _, t := range failures {
+			fmt.Fprintf(&sb, "### %s: %s (%s)\n\n", t.TaskID, t.TaskName, t.Outcome)
+			if t.Error != "" {
+				fmt.Fprintf(&sb, "```\n%s\n```\n\n", t.Error)
+			}
+		}
+	}
+
+	sb.WriteString("## Git\n\n")
+	if len(run.Commits) == 0 && len(run.Tags) == 0 {
+		sb.WriteString("No commits or tags created.\n\n")
+	} else {
+		for _, c := range run.Commits {
+			fmt.Fprintf(&sb, "- `%s` %s", c.Hash, c.Message)
+			if c.Stat != "" {
+				fmt.Fprintf(&sb, " (%s)", c.Stat)
+			}
+			sb.WriteString("\n")
+		}
+		for _, tag := range run.Tags {
+			fmt.Fprintf(&sb, "- Tag `%s`\n", tag)
+		}
+		sb.WriteString("\n")
+	}
+
+	sb.WriteString("## Circuit Breaker Incidents\n\n")
+	if len(r.Incidents) == 0 {
+		sb.WriteString("None.\n")
+	} else {
+		for _, e := range r.Incidents {
+			fmt.Fprintf(&sb, "- %s: %s -> %s (loop %d) %s\n",
+				e.Timestamp.Format("15:04:05"), e.FromState, e.ToState, e.LoopNumber, e.Reason)
+		}
+	}
+
+	return sb.String()
+}
+
+// HTML renders the report as a standalone HTML page
+func (r *Report) HTML() (string, error) {
+	var buf bytes.Buffer
+	if err := htmlTemplate.Execute(&buf, r); err != nil {
+		return "", err
+	}
+	return buf.String(), nil
+}
+
+func escapeTable(s string) string {
+	return strings.ReplaceAll(s, "|", "\\|")
+}
+
+var htmlTemplate = template.Must(template.New("report").Funcs(template.FuncMap{
+	"fmtTime":     func(t time.Time) string { return t.Format("2006-01-02 15:04:05") },
+	"fmtDuration": func(d time.Duration) string { return d.Round(time.Second).String() },
+	"fmtCost":     func(c float64) string { return fmt.Sprintf("$%.4f", c) },
+}).Parse(`<!DOCTYPE html>
+<html>
+<head>
+<meta charset="utf-8">
+<title>Hermes Run Report {{.Run.ID}}</title>
+<style>
+body { font-family: -apple-system, Segoe UI, sans-serif; margin: 2em; color: #222; }
+table { border-collapse: collapse; margin-bottom: 1.5em; }
+th, td { border: 1px solid #ccc; padding: 4px 10px; text-align: left; }
+th { background: #f4f4f4; }
+pre { background: #f8f8f8; padding: 8px; overflow-x: auto; }
+.COMPLETED { color: #2e7d32; } .FAILED, .BLOCKED { color: #c62828; } .AT_RISK, .PAUSED { color: #ef6c00; }
+</style>
+</head>
+<body>
+<h1>Hermes Run Report: {{.Run.ID}}</h1>
+<table>
+<tr><th>Started</th><td>{{fmtTime .Run.StartTime}}</td></tr>
+{{if not .Run.EndTime.IsZero}}<tr><th>Finished</th><td>{{fmtTime .Run.EndTime}}</td></tr>{{end}}
+<tr><th>Duration</th><td>{{fmtDuration .Run.Elapsed}}</td></tr>
+<tr><th>Mode</th><td>{{.Run.Mode}}</td></tr>
+<tr><th>Provider</th><td>{{.Run.Provider}}</td></tr>
+<tr><th>Tasks completed</th><td>{{.Summary.Completed}}</td></tr>
+<tr><th>Failed attempts</th><td>{{.Summary.Failed}}</td></tr>
+<tr><th>Blocked</th><td>{{.Summary.Blocked}}</td></tr>
+<tr><th>Total cost</th><td>{{fmtCost .Summary.TotalCost}}</td></tr>
+</table>
+<h2>Tasks</h2>
+{{if .Run.Tasks}}<table>
+<tr><th>Task</th><th>Name</th><th>Outcome</th><th>Duration</th><th>Cost</th></tr>
+{{range .Run.Tasks}}<tr><td>{{.TaskID}}</td><td>{{.TaskName}}</td><td class="{{.Outcome}}">{{.Outcome}}</td><td>{{fmtDuration .Duration}}</td><td>{{fmtCost .Cost}}</td></tr>
+{{end}}</table>{{else}}<p>No task attempts recorded.</p>{{end}}
+{{with .Failures}}<h2>Failures</h2>
+{{range .}}<h3>{{.TaskID}}: {{.TaskName}} ({{.Outcome}})</h3>
+{{if .Error}}<pre>{{.Error}}</pre>{{end}}
+{{end}}{{end}}
+<h2>Git</h2>
+{{if or .Run.Commits .Run.Tags}}<ul>
+{{range .Run.Commits}}<li><code>{{.Hash}}</code> {{.Message}}{{if .Stat}} <small>({{.Stat}})</small>{{end}}</li>
+{{end}}{{range .Run.Tags}}<li>Tag <code>{{.}}</code></li>
+{{end}}</ul>{{else}}<p>No commits or tags created.</p>{{end}}
+<h2>Circuit Breaker Incidents</h2>
+{{if .Incidents}}<ul>
+{{range .Incidents}}<li>{{fmtTime .Timestamp}}: {{.FromState}} &rarr; {{.ToState}} (loop {{.LoopNumber}}) {{.Reason}}</li>
+{{end}}</ul>{{else}}<p>None.</p>{{end}}
+</body>
+</html>
+`))
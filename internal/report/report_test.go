@@ -0,0 +1,404 @@
+package report
+
+import (
+	"os"
+	"strings"
+	"testing"
+	"time"
+
+	"hermes/internal/task"
+)
+
+func setupTestDir(t *testing.T) (string, func()) {
+	tmpDir, err := os.MkdirTemp("", "hermes-report-test-*")
+	if err != nil {
+		t.Fatal(err)
+	}
+	return tmpDir, func() { os.RemoveAll(tmpDir) }
+}
+
+func TestRecorderPersistsRun(t *testing.T) {
+	tmpDir, cleanup := setupTestDir(t)
+	defer cleanup()
+
+	rec := NewRecorder(tmpDir, "sequential", "claude")
+	rec.RecordTask(TaskRecord{TaskID: "T001", TaskName: "Setup", Outcome: OutcomeCompleted, Duration: time.Minute, Cost: 0.5})
+	rec.RecordTask(TaskRecord{TaskID: "T002", TaskName: "API", Outcome: OutcomeFailed, Error: "timeout"})
+	rec.RecordCommit("abc123", "feat(T001): Setup", "2 files, +40 -3")
+	rec.RecordTag("v1.0.0")
+	if err := rec.Finish(); err != nil {
+		t.Fatal(err)
+	}
+
+	run, err := LoadRun(tmpDir, "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if run.ID != rec.GetRun().ID {
+		t.Errorf("expected run %s, got %s", rec.GetRun().ID, run.ID)
+	}
+	if len(run.Tasks) != 2 || len(run.Commits) != 1 || len(run.Tags) != 1 {
+		t.Errorf("unexpected run contents: %+v", run)
+	}
+
+	summary := run.Summarize()
+	if summary.Completed != 1 || summary.Failed != 1 {
+		t.Errorf("expected 1 completed and 1 failed, got %+v", summary)
+	}
+	if summary.TotalCost != 0.5 {
+		t.Errorf("expected total cost 0.5, got %f", summary.TotalCost)
+	}
+}
+
+func TestLoadRunMissing(t *testing.T) {
+	tmpDir, cleanup := setupTestDir(t)
+	defer cleanup()
+
+	if _, err := LoadRun(tmpDir, ""); err == nil {
+		t.Error("expected error when no runs are recorded")
+	}
+}
+
+func TestRecorderRunIDs(t *testing.T) {
+	tmpDir, cleanup := setupTestDir(t)
+	defer cleanup()
+
+	// Runs started within the same second, e.g. by two processes, are kept apart
+	first := NewRecorder(tmpDir, "sequential", "claude")
+	second := NewRecorder(tmpDir, "parallel", "claude")
+	if first.GetRun().ID == second.GetRun().ID {
+		t.Errorf("expected distinct run IDs, both are %s", first.GetRun().ID)
+	}
+}
+
+func TestLoadRunByPrefix(t *testing.T) {
+	tmpDir, cleanup := setupTestDir(t)
+	defer cleanup()
+	store := OpenStore(tmpDir)
+	for _, id := range []string{"20250101-120000.000001", "20250101-120000.500000", "20250101-130000.000001"} {
+		if err := store.SaveRun(&Run{ID: id, StartTime: time.Now()}); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	for id, want := range map[string]string{
+		"20250101-120000.500000": "20250101-120000.500000",
+		"20250101-13":            "20250101-130000.000001",
+	} {
+		if run, err := LoadRun(tmpDir, id); err != nil || run.ID != want {
+			t.Errorf("LoadRun(%q) = %v, %v, want %s", id, run, err, want)
+		}
+	}
+	if _, err := LoadRun(tmpDir, "20250101-120000"); err == nil || !strings.Contains(err.Error(), "matches 2 runs") {
+		t.Errorf("expected an ambiguous prefix to be refused, got %v", err)
+	}
+	if _, err := LoadRun(tmpDir, "20250102"); err == nil {
+		t.Error("expected an unknown run to be refused")
+	}
+}
+
+func TestExcerpt(t *testing.T) {
+	if got := excerpt("  short  ", 10); got != "short" {
+		t.Errorf("expected the trimmed text, got %q", got)
+	}
+	if got := excerpt("çalışma hatası", 5); got != "çalış..." {
+		t.Errorf("expected the text cut after 5 characters, got %q", got)
+	}
+}
+
+func TestReportRendering(t *testing.T) {
+	tmpDir, cleanup := setupTestDir(t)
+	defer cleanup()
+
+	run := &Run{
+		ID:        "20250101-120000",
+		Mode:      "parallel",
+		Provider:  "claude",
+		StartTime: time.Now().Add(-time.Hour),
+		EndTime:   time.Now(),
+		Tasks: []TaskRecord{
+			{TaskID: "T001", TaskName: "Login <form>", Outcome: OutcomeCompleted},
+			{TaskID: "T002", TaskName: "Payments", Outcome: OutcomeFailed, Error: "build failed"},
+		},
+		Tags: []string{"v1.0.0"},
+	}
+
+	rep := New(tmpDir, run)
+
+	md := rep.Markdown()
+	for _, want := range []string{"# Hermes Run Report: 20250101-120000", "T001", "## Failures", "build failed", "Tag `v1.0.0`"} {
+		if !strings.Contains(md, want) {
+			t.Errorf("expected markdown to contain %q", want)
+		}
+	}
+
+	html, err := rep.HTML()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(html, "Login &lt;form&gt;") {
+		t.Error("expected HTML output to escape task names")
+	}
+}
+
+func TestAttemptCapture(t *testing.T) {
+	tmpDir, cleanup := setupTestDir(t)
+	defer cleanup()
+
+	rec := NewRecorder(tmpDir, "sequential", "claude")
+	rec.RecordTask(TaskRecord{TaskID: "T001", Outcome: OutcomeFailed, Error: "tests failed", Prompt: "do it", Output: "ran tests"})
+
+	attempt, err := LoadAttempt(tmpDir, "T001")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if attempt.Prompt != "do it" || attempt.Output != "ran tests" || attempt.Error != "tests failed" {
+		t.Errorf("unexpected attempt: %+v", attempt)
+	}
+	if attempt.RunID != rec.GetRun().ID {
+		t.Errorf("expected run %s, got %s", rec.GetRun().ID, attempt.RunID)
+	}
+
+	// Prompt and output stay out of the run record
+	run, _ := LoadRun(tmpDir, "")
+	if run.Tasks[0].Prompt != "" || run.Tasks[0].Output != "" {
+		t.Error("expected prompt and output to be omitted from the run record")
+	}
+
+	rec.RecordTask(TaskRecord{TaskID: "T001", Outcome: OutcomeCompleted})
+	if _, err := LoadAttempt(tmpDir, "T001"); err == nil {
+		t.Error("expected attempt to be cleared after completion")
+	}
+}
+
+func TestComputeStats(t *testing.T) {
+	start := time.Date(2025, 1, 1, 10, 0, 0, 0, time.UTC)
+	runs := []*Run{
+		{
+			ID: "run-1", StartTime: start, EndTime: start.Add(time.Hour),
+			Tasks: []TaskRecord{
+				{TaskID: "T001", Effort: "1 day", Outcome: OutcomeCompleted, Duration: 10 * time.Minute, Cost: 1},
+				{TaskID: "T002", Effort: "2 days", Outcome: OutcomeFailed, Duration: 20 * time.Minute},
+			},
+		},
+		{
+			ID: "run-2", StartTime: start.Add(2 * time.Hour), EndTime: start.Add(3 * time.Hour),
+			Tasks: []TaskRecord{
+				{TaskID: "T002", Effort: "2 days", Outcome: OutcomeCompleted, Duration: 40 * time.Minute},
+				{TaskID: "T003", Effort: "4 hours", Outcome: OutcomeCompleted, Duration: 5 * time.Minute},
+				{TaskID: "T004", Outcome: OutcomeCompleted, Duration: time.Minute},
+			},
+		},
+	}
+
+	stats := ComputeStats(runs)
+	if stats.Attempts != 5 || stats.Completed != 4 || stats.Retries != 1 {
+		t.Errorf("unexpected totals: %+v", stats)
+	}
+	if stats.AvgAttempts != 1.25 {
+		t.Errorf("expected 1.25 attempts per task, got %v", stats.AvgAttempts)
+	}
+	if rs := stats.Runs[1]; rs.Retries != 1 || rs.Throughput != 3 || rs.SuccessRate != 100 {
+		t.Errorf("unexpected run stats: %+v", rs)
+	}
+
+	var labels []string
+	for _, e := range stats.Efforts {
+		labels = append(labels, e.Effort)
+	}
+	if got := strings.Join(labels, ","); got != "0.5 days,1 day,2 days,unestimated" {
+		t.Errorf("unexpected effort order: %s", got)
+	}
+	if e := stats.Efforts[2]; e.AvgDuration != time.Hour || e.AvgAttempts != 2 {
+		t.Errorf("expected both attempts of T002 to count, got %+v", e)
+	}
+	if e := stats.Efforts[2]; e.Accuracy != 0.0625 {
+		t.Errorf("expected an hour for a 2 day estimate to be 0.0625x, got %v", e.Accuracy)
+	}
+	if e := stats.Efforts[3]; e.Accuracy != 0 {
+		t.Errorf("expected no accuracy for unestimated tasks, got %v", e.Accuracy)
+	}
+}
+
+func TestPendingDuration(t *testing.T) {
+	tmpDir, cleanup := setupTestDir(t)
+	defer cleanup()
+
+	OpenStore(tmpDir).SaveRun(&Run{ID: "20250101-100000", Tasks: []TaskRecord{
+		{TaskID: "T001", Outcome: OutcomeCompleted, Duration: time.Hour},
+		{TaskID: "T001", Outcome: OutcomeFailed, Duration: 10 * time.Minute},
+	}})
+
+	// Attempts since the last completion count, across runs
+	second := NewRecorder(tmpDir, "sequential", "claude")
+	second.RecordTask(TaskRecord{TaskID: "T001", Outcome: OutcomeIncomplete, Duration: 5 * time.Minute})
+	second.RecordTask(TaskRecord{TaskID: "T002", Outcome: OutcomeIncomplete, Duration: time.Minute})
+	if got := second.PendingDuration("T001"); got != 15*time.Minute {
+		t.Errorf("expected 15m pending, got %v", got)
+	}
+	if got := second.PendingDuration("T003"); got != 0 {
+		t.Errorf("expected nothing pending for an unattempted task, got %v", got)
+	}
+}
+
+func TestResumeRecorder(t *testing.T) {
+	tmpDir, cleanup := setupTestDir(t)
+	defer cleanup()
+
+	rec := NewRecorder(tmpDir, "sequential", "claude")
+	rec.RecordTask(TaskRecord{TaskID: "T001", Outcome: OutcomeIncomplete})
+
+	// The process died before Finish; the resumed run continues the same record
+	resumed, err := ResumeRecorder(tmpDir, rec.GetRun().ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	resumed.RecordTask(TaskRecord{TaskID: "T001", Outcome: OutcomeCompleted})
+	resumed.Finish()
+
+	runs, _ := LoadRuns(tmpDir)
+	if len(runs) != 1 || len(runs[0].Tasks) != 2 || runs[0].EndTime.IsZero() {
+		t.Errorf("expected one finished run with both attempts, got %+v", runs)
+	}
+	if _, err := ResumeRecorder(tmpDir, "missing"); err == nil {
+		t.Error("expected an error for an unknown run")
+	}
+}
+
+func TestSQLiteStore(t *testing.T) {
+	tmpDir, cleanup := setupTestDir(t)
+	defer cleanup()
+
+	// A run recorded as JSON before the database existed is imported
+	start := time.Date(2026, 1, 2, 9, 0, 0, 0, time.UTC)
+	NewJSONStore(tmpDir).SaveRun(&Run{ID: "20260102-090000", Mode: "sequential", Provider: "claude", StartTime: start, EndTime: start.Add(time.Hour)})
+
+	if err := SetStore("postgres"); err == nil {
+		t.Error("expected an unknown store to be rejected")
+	}
+	if err := SetStore(StoreSQLite); err != nil {
+		t.Fatal(err)
+	}
+	defer SetStore(StoreJSON)
+
+	rec := NewRecorder(tmpDir, "parallel", "gemini")
+	rec.RecordTask(TaskRecord{TaskID: "T001", TaskName: "Setup", FeatureID: "F001", Effort: "1d", Outcome: OutcomeFailed, Duration: 90 * time.Second, Cost: 0.25, Error: "tests failed"})
+	rec.RecordTask(TaskRecord{TaskID: "T001", TaskName: "Setup", FeatureID: "F001", Outcome: OutcomeCompleted, Duration: time.Minute, Cost: 0.5})
+	rec.RecordCommit("abc123", "feat(T001): Setup", "2 files, +40 -3")
+	rec.RecordTag("v1.0.0")
+	if err := rec.Finish(); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := os.Stat(GetHistoryDBPath(tmpDir)); err != nil {
+		t.Fatalf("expected history database: %v", err)
+	}
+
+	runs, err := LoadRuns(tmpDir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(runs) != 2 || runs[0].ID != "20260102-090000" || !runs[0].EndTime.Equal(start.Add(time.Hour)) {
+		t.Fatalf("expected the imported run first, got %+v", runs)
+	}
+	run := runs[1]
+	if run.Mode != "parallel" || len(run.Tasks) != 2 || len(run.Commits) != 1 || len(run.Tags) != 1 {
+		t.Fatalf("unexpected run contents: %+v", run)
+	}
+	first := run.Tasks[0]
+	if first.Outcome != OutcomeFailed || first.Duration != 90*time.Second || first.Effort != "1d" || first.Error != "tests failed" {
+		t.Errorf("unexpected task record %+v", first)
+	}
+	if run.Commits[0].Stat != "2 files, +40 -3" || run.Tags[0] != "v1.0.0" || run.EndTime.IsZero() {
+		t.Errorf("unexpected run %+v", run)
+	}
+
+	// Resuming a run rewrites it rather than adding a second one
+	resumed, err := ResumeRecorder(tmpDir, run.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	resumed.RecordTask(TaskRecord{TaskID: "T002", Outcome: OutcomeCompleted})
+	resumed.Finish()
+	runs, _ = LoadRuns(tmpDir)
+	if len(runs) != 2 || len(runs[1].Tasks) != 3 {
+		t.Errorf("expected the resumed run to have 3 tasks, got %+v", runs)
+	}
+}
+
+func TestTaskHistory(t *testing.T) {
+	tmpDir, cleanup := setupTestDir(t)
+	defer cleanup()
+
+	rec := NewRecorder(tmpDir, "sequential", "claude")
+	rec.RecordTask(TaskRecord{TaskID: "T001", Outcome: OutcomeFailed, Error: "timeout"})
+	rec.RecordTask(TaskRecord{TaskID: "T001", Outcome: OutcomeIncomplete, Subtask: "T001.1"})
+	rec.RecordTask(TaskRecord{TaskID: "T001", Outcome: OutcomeFailed})
+	rec.RecordTask(TaskRecord{TaskID: "T001", Outcome: OutcomeIncomplete})
+	rec.RecordTask(TaskRecord{TaskID: "T001", Outcome: OutcomeBlocked})
+
+	h, err := LoadTaskHistory(tmpDir, "T001")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(h.Attempts) != 5 || h.Attempts[0].Error != "timeout" || h.Attempts[0].RunID != rec.GetRun().ID {
+		t.Fatalf("unexpected history: %+v", h)
+	}
+	// Completing a subtask counts as progress
+	if h.Failures() != 3 {
+		t.Errorf("expected 3 failures in a row, got %d", h.Failures())
+	}
+	if !h.RetriesExhausted(2) || h.RetriesExhausted(3) || h.RetriesExhausted(0) {
+		t.Error("expected retries to be exhausted after more than maxRetries failures only")
+	}
+
+	if err := ResetTaskHistory(tmpDir, "T001"); err != nil {
+		t.Fatal(err)
+	}
+	h, _ = LoadTaskHistory(tmpDir, "T001")
+	if len(h.Attempts) != 5 || h.Failures() != 0 {
+		t.Errorf("expected a reset to keep attempts and forgive failures, got %d attempts, %d failures", len(h.Attempts), h.Failures())
+	}
+
+	if h, _ := LoadTaskHistory(tmpDir, "T002"); h == nil || len(h.Attempts) != 0 {
+		t.Error("expected an empty history for an unattempted task")
+	}
+}
+
+func TestEstimate(t *testing.T) {
+	stats := &Stats{Efforts: []EffortStats{
+		{Effort: "1 day", Days: 1, Tasks: 3, AvgDuration: 30 * time.Minute},
+		{Effort: "2 days", Days: 2, Tasks: 1, AvgDuration: 70 * time.Minute},
+	}}
+	pending := []task.Task{
+		{ID: "T010", EstimatedEffort: "1 day"},
+		{ID: "T011", EstimatedEffort: "2 days"},
+		{ID: "T012", EstimatedEffort: "5 days"}, // No history, uses the 40m average
+	}
+
+	e := NewEstimate(stats, pending, 2)
+	if got, ok := e.Remaining(0, 0); !ok || got != 70*time.Minute {
+		t.Errorf("expected 70m from history, got %v (%v)", got, ok)
+	}
+	e.Complete("T011")
+	// History says 35m, throughput one task per 10m says 20m
+	if got, _ := e.Remaining(1, 10*time.Minute); got != 27*time.Minute+30*time.Second {
+		t.Errorf("expected history and throughput to be averaged, got %v", got)
+	}
+
+	if _, ok := NewEstimate(nil, pending, 1).Remaining(0, 0); ok {
+		t.Error("expected no estimate without history or progress")
+	}
+	if got, ok := NewEstimate(nil, pending, 1).Remaining(1, 5*time.Minute); !ok || got != 15*time.Minute {
+		t.Errorf("expected estimate from throughput, got %v (%v)", got, ok)
+	}
+
+	for d, want := range map[time.Duration]string{
+		20 * time.Second:                "<1m",
+		25 * time.Minute:                "~25m",
+		85*time.Minute + 10*time.Second: "~1h 25m",
+	} {
+		if got := FormatETA(d); got != want {
+			t.Errorf("FormatETA(%v) = %q, want %q", d, got, want)
+		}
+	}
+}
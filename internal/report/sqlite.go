@@ -0,0 +1,253 @@
+package report
+
+import (
+	"database/sql"
+	"os"
+	"path/filepath"
+	"time"
+
+	_ "modernc.org/sqlite"
+)
+
+// sqliteSchema creates the history tables. Times are stored as RFC 3339
+// text and durations in nanoseconds so the database is easy to query with
+// the sqlite3 shell.
+const sqliteSchema = `
+CREATE TABLE IF NOT EXISTS runs (
+	id         TEXT PRIMARY KEY,
+	mode       TEXT NOT NULL,
+	provider   TEXT NOT NULL,
+	start_time TEXT NOT NULL,
+	end_time   TEXT NOT NULL DEFAULT ''
+);
+CREATE TABLE IF NOT EXISTS task_attempts (
+	run_id      TEXT NOT NULL REFERENCES runs(id) ON DELETE CASCADE,
+	seq         INTEGER NOT NULL,
+	task_id     TEXT NOT NULL,
+	task_name   TEXT NOT NULL,
+	feature_id  TEXT NOT NULL,
+	effort      TEXT NOT NULL,
+	outcome     TEXT NOT NULL,
+	duration_ns INTEGER NOT NULL,
+	cost        REAL NOT NULL,
+	error       TEXT NOT NULL,
+	time        TEXT NOT NULL,
+	PRIMARY KEY (run_id, seq)
+);
+CREATE INDEX IF NOT EXISTS task_attempts_task ON task_attempts(task_id);
+CREATE TABLE IF NOT EXISTS commits (
+	run_id  TEXT NOT NULL REFERENCES runs(id) ON DELETE CASCADE,
+	seq     INTEGER NOT NULL,
+	hash    TEXT NOT NULL,
+	message TEXT NOT NULL,
+	stat    TEXT NOT NULL,
+	PRIMARY KEY (run_id, seq)
+);
+CREATE TABLE IF NOT EXISTS tags (
+	run_id TEXT NOT NULL REFERENCES runs(id) ON DELETE CASCADE,
+	seq    INTEGER NOT NULL,
+	tag    TEXT NOT NULL,
+	PRIMARY KEY (run_id, seq)
+);
+`
+
+// GetHistoryDBPath returns the path of the SQLite run history
+func GetHistoryDBPath(basePath string) string {
+	return filepath.Join(basePath, ".hermes", "history.db")
+}
+
+// SQLiteStore keeps all runs in .hermes/history.db. Runs recorded as JSON
+// files before the database existed are imported when it is created.
+type SQLiteStore struct {
+	path     string
+	basePath string
+}
+
+// NewSQLiteStore creates a store for the run history database of a project
+func NewSQLiteStore(basePath string) *SQLiteStore {
+	return &SQLiteStore{path: GetHistoryDBPath(basePath), basePath: basePath}
+}
+
+// open opens the database, creating the schema and importing existing
+// run files on first use
+func (s *SQLiteStore) open() (*sql.DB, error) {
+	if err := os.MkdirAll(filepath.Dir(s.path), 0755); err != nil {
+		return nil, err
+	}
+	_, statErr := os.Stat(s.path)
+	created := os.IsNotExist(statErr)
+
+	db, err := sql.Open("sqlite", "file:"+s.path+"?_pragma=busy_timeout(5000)&_pragma=journal_mode(WAL)&_pragma=foreign_keys(1)")
+	if err != nil {
+		return nil, err
+	}
+	if _, err := db.Exec(sqliteSchema); err != nil {
+		db.Close()
+		return nil, err
+	}
+	if created {
+		runs, _ := NewJSONStore(s.basePath).LoadRuns()
+		for _, run := range runs {
+			if err := saveRun(db, run); err != nil {
+				db.Close()
+				return nil, err
+			}
+		}
+	}
+	return db, nil
+}
+
+// SaveRun creates or replaces the run and its attempts, commits and tags
+func (s *SQLiteStore) SaveRun(run *Run) error {
+	db, err := s.open()
+	if err != nil {
+		return err
+	}
+	defer db.Close()
+	return saveRun(db, run)
+}
+
+func saveRun(db *sql.DB, run *Run) error {
+	tx, err := db.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	if _, err := tx.Exec(`INSERT INTO runs (id, mode, provider, start_time, end_time) VALUES (?, ?, ?, ?, ?)
+		ON CONFLICT(id) DO UPDATE SET mode = excluded.mode, provider = excluded.provider,
+		start_time = excluded.start_time, end_time = excluded.end_time`,
+		run.ID, run.Mode, run.Provider, formatTime(run.StartTime), formatTime(run.EndTime)); err != nil {
+		return err
+	}
+	for _, table := range []string{"task_attempts", "commits", "tags"} {
+		if _, err := tx.Exec("DELETE FROM "+table+" WHERE run_id = ?", run.ID); err != nil {
+			return err
+		}
+	}
+	for i, t := range run.Tasks {
+		if _, err := tx.Exec(`INSERT INTO task_attempts
+			(run_id, seq, task_id, task_name, feature_id, effort, outcome, duration_ns, cost, error, time)
+			VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)`,
+			run.ID, i, t.TaskID, t.TaskName, t.FeatureID, t.Effort, t.Outcome,
+			int64(t.Duration), t.Cost, t.Error, formatTime(t.Time)); err != nil {
+			return err
+		}
+	}
+	for i, c := range run.Commits {
+		if _, err := tx.Exec("INSERT INTO commits (run_id, seq, hash, message, stat) VALUES (?, ?, ?, ?, ?)",
+			run.ID, i, c.Hash, c.Message, c.Stat); err != nil {
+			return err
+		}
+	}
+	for i, tag := range run.Tags {
+		if _, err := tx.Exec("INSERT INTO tags (run_id, seq, tag) VALUES (?, ?, ?)", run.ID, i, tag); err != nil {
+			return err
+		}
+	}
+	return tx.Commit()
+}
+
+// LoadRuns reads all runs ordered by start time
+func (s *SQLiteStore) LoadRuns() ([]*Run, error) {
+	db, err := s.open()
+	if err != nil {
+		return nil, err
+	}
+	defer db.Close()
+
+	rows, err := db.Query("SELECT id, mode, provider, start_time, end_time FROM runs ORDER BY start_time, id")
+	if err != nil {
+		return nil, err
+	}
+	var runs []*Run
+	byID := make(map[string]*Run)
+	for rows.Next() {
+		var run Run
+		var start, end string
+		if err := rows.Scan(&run.ID, &run.Mode, &run.Provider, &start, &end); err != nil {
+			rows.Close()
+			return nil, err
+		}
+		run.StartTime = parseTime(start)
+		run.EndTime = parseTime(end)
+		runs = append(runs, &run)
+		byID[run.ID] = &run
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	rows, err = db.Query(`SELECT run_id, task_id, task_name, feature_id, effort, outcome, duration_ns, cost, error, time
+		FROM task_attempts ORDER BY run_id, seq`)
+	if err != nil {
+		return nil, err
+	}
+	for rows.Next() {
+		var runID, at string
+		var t TaskRecord
+		var duration int64
+		if err := rows.Scan(&runID, &t.TaskID, &t.TaskName, &t.FeatureID, &t.Effort, &t.Outcome, &duration, &t.Cost, &t.Error, &at); err != nil {
+			rows.Close()
+			return nil, err
+		}
+		t.Duration = time.Duration(duration)
+		t.Time = parseTime(at)
+		if run := byID[runID]; run != nil {
+			run.Tasks = append(run.Tasks, t)
+		}
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	rows, err = db.Query("SELECT run_id, hash, message, stat FROM commits ORDER BY run_id, seq")
+	if err != nil {
+		return nil, err
+	}
+	for rows.Next() {
+		var runID string
+		var c Commit
+		if err := rows.Scan(&runID, &c.Hash, &c.Message, &c.Stat); err != nil {
+			rows.Close()
+			return nil, err
+		}
+		if run := byID[runID]; run != nil {
+			run.Commits = append(run.Commits, c)
+		}
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	rows, err = db.Query("SELECT run_id, tag FROM tags ORDER BY run_id, seq")
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	for rows.Next() {
+		var runID, tag string
+		if err := rows.Scan(&runID, &tag); err != nil {
+			return nil, err
+		}
+		if run := byID[runID]; run != nil {
+			run.Tags = append(run.Tags, tag)
+		}
+	}
+	return runs, rows.Err()
+}
+
+func formatTime(t time.Time) string {
+	if t.IsZero() {
+		return ""
+	}
+	return t.Format(time.RFC3339Nano)
+}
+
+func parseTime(s string) time.Time {
+	t, _ := time.Parse(time.RFC3339Nano, s)
+	return t
+}
@@ -0,0 +1,175 @@
+package report
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"time"
+
+	"hermes/internal/task"
+)
+
+// RunStats contains the statistics of a single run
+type RunStats struct {
+	ID          string        `json:"id"`
+	Provider    string        `json:"provider"`
+	StartTime   time.Time     `json:"startTime"`
+	Elapsed     time.Duration `json:"elapsed"`
+	Attempts    int           `json:"attempts"`
+	Completed   int           `json:"completed"`
+	Retries     int           `json:"retries"`     // Attempts of tasks that were attempted before
+	SuccessRate float64       `json:"successRate"` // Percentage of attempts that completed their task
+	Throughput  float64       `json:"throughput"`  // Completed tasks per hour
+	AvgDuration time.Duration `json:"avgDuration"` // Average duration of an attempt
+	Cost        float64       `json:"cost"`
+}
+
+// EffortStats relates an effort estimate to the work completed tasks actually took
+type EffortStats struct {
+	Effort      string        `json:"effort"` // Normalized estimate, e.g. "2 days"
+	Days        float64       `json:"days"`   // Estimate in days, 0 for unestimated tasks
+	Tasks       int           `json:"tasks"`
+	AvgDuration time.Duration `json:"avgDuration"` // Average time of all attempts per task
+	AvgAttempts float64       `json:"avgAttempts"`
+	Accuracy    float64       `json:"accuracy,omitempty"` // Average time as a multiple of the estimate, 0 for unestimated tasks
+}
+
+// Stats contains metrics aggregated over recorded runs
+type Stats struct {
+	Runs          []RunStats    `json:"runs"`
+	Efforts       []EffortStats `json:"efforts"` // Ordered by estimate
+	Attempts      int           `json:"attempts"`
+	Completed     int           `json:"completed"`
+	Retries       int           `json:"retries"`
+	SuccessRate   float64       `json:"successRate"`
+	AvgAttempts   float64       `json:"avgAttempts"` // Attempts per completed task
+	TotalDuration time.Duration `json:"totalDuration"`
+	TotalCost     float64       `json:"totalCost"`
+}
+
+// ComputeStats aggregates runs ordered from oldest to newest. Attempts of a
+// task are counted across runs until the task completes, so a task that failed
+// in one run and completed in the next counts as one task with one retry.
+func ComputeStats(runs []*Run) *Stats {
+	type pendingTask struct {
+		attempts int
+		duration time.Duration
+	}
+	pending := make(map[string]*pendingTask)
+	efforts := make(map[string]*EffortStats)
+	stats := &Stats{}
+	var totalAttempts int
+
+	for _, run := range runs {
+		rs := RunStats{
+			ID:        run.ID,
+			Provider:  run.Provider,
+			StartTime: run.StartTime,
+			Elapsed:   run.Elapsed(),
+		}
+		var runDuration time.Duration
+		for _, rec := range run.Tasks {
+			p := pending[rec.TaskID]
+			if p == nil {
+				p = &pendingTask{}
+				pending[rec.TaskID] = p
+			} else {
+				rs.Retries++
+			}
+			p.attempts++
+			p.duration += rec.Duration
+			rs.Attempts++
+			rs.Cost += rec.Cost
+			runDuration += rec.Duration
+
+			if rec.Outcome != OutcomeCompleted {
+				continue
+			}
+			rs.Completed++
+			delete(pending, rec.TaskID)
+			totalAttempts += p.attempts
+
+			days, label := normalizeEffort(rec.Effort)
+			e := efforts[label]
+			if e == nil {
+				e = &EffortStats{Effort: label, Days: days}
+				efforts[label] = e
+			}
+			// Accumulate totals; they are turned into averages below
+			e.Tasks++
+			e.AvgDuration += p.duration
+			e.AvgAttempts += float64(p.attempts)
+		}
+
+		if rs.Attempts > 0 {
+			rs.SuccessRate = float64(rs.Completed) / float64(rs.Attempts) * 100
+			rs.AvgDuration = runDuration / time.Duration(rs.Attempts)
+		}
+		if hours := rs.Elapsed.Hours(); hours > 0 {
+			rs.Throughput = float64(rs.Completed) / hours
+		}
+
+		stats.Runs = append(stats.Runs, rs)
+		stats.Attempts += rs.Attempts
+		stats.Completed += rs.Completed
+		stats.Retries += rs.Retries
+		stats.TotalDuration += runDuration
+		stats.TotalCost += rs.Cost
+	}
+
+	if stats.Attempts > 0 {
+		stats.SuccessRate = float64(stats.Completed) / float64(stats.Attempts) * 100
+	}
+	if stats.Completed > 0 {
+		stats.AvgAttempts = float64(totalAttempts) / float64(stats.Completed)
+	}
+
+	for _, e := range efforts {
+		e.AvgDuration /= time.Duration(e.Tasks)
+		e.AvgAttempts /= float64(e.Tasks)
+		if e.Days > 0 {
+			e.Accuracy = e.AvgDuration.Hours() / (e.Days * task.HoursPerDay)
+		}
+		stats.Efforts = append(stats.Efforts, *e)
+	}
+	sort.Slice(stats.Efforts, func(i, j int) bool {
+		a, b := stats.Efforts[i], stats.Efforts[j]
+		// Unestimated tasks go last
+		if (a.Days == 0) != (b.Days == 0) {
+			return b.Days == 0
+		}
+		return a.Days < b.Days
+	})
+
+	return stats
+}
+
+// PendingDuration returns the time spent on attempts of a task since it last
+// completed, in runs ordered from oldest to newest
+func PendingDuration(runs []*Run, taskID string) time.Duration {
+	var pending time.Duration
+	for _, run := range runs {
+		for _, rec := range run.Tasks {
+			if rec.TaskID != taskID {
+				continue
+			}
+			pending += rec.Duration
+			if rec.Outcome == OutcomeCompleted {
+				pending = 0
+			}
+		}
+	}
+	return pending
+}
+
+// normalizeEffort returns an estimate in days and a label grouping equal estimates
+func normalizeEffort(effort string) (float64, string) {
+	days, ok := task.EffortDays(effort)
+	if !ok || days <= 0 {
+		return 0, "unestimated"
+	}
+	if days == 1 {
+		return days, "1 day"
+	}
+	return days, fmt.Sprintf("%s days", strconv.FormatFloat(days, 'f', -1, 64))
+}
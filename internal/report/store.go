@@ -0,0 +1,101 @@
+package report
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"sync"
+)
+
+// Store kinds accepted by SetStore
+const (
+	StoreJSON   = "json"   // One file per run in .hermes/runs
+	StoreSQLite = "sqlite" // A single database at .hermes/history.db
+)
+
+// Store persists recorded runs
+type Store interface {
+	// SaveRun creates or replaces a run
+	SaveRun(run *Run) error
+	// LoadRuns returns all runs ordered from oldest to newest
+	LoadRuns() ([]*Run, error)
+}
+
+var (
+	storeMu   sync.Mutex
+	storeKind = StoreJSON
+)
+
+// SetStore selects where runs are recorded and read from. An empty kind
+// selects the JSON files.
+func SetStore(kind string) error {
+	switch kind {
+	case "":
+		kind = StoreJSON
+	case StoreJSON, StoreSQLite:
+	default:
+		return fmt.Errorf("unknown history store %q (use %q or %q)", kind, StoreJSON, StoreSQLite)
+	}
+	storeMu.Lock()
+	defer storeMu.Unlock()
+	storeKind = kind
+	return nil
+}
+
+// OpenStore returns the run store of a project selected with SetStore
+func OpenStore(basePath string) Store {
+	storeMu.Lock()
+	kind := storeKind
+	storeMu.Unlock()
+	if kind == StoreSQLite {
+		return NewSQLiteStore(basePath)
+	}
+	return NewJSONStore(basePath)
+}
+
+// JSONStore keeps each run in its own file under .hermes/runs
+type JSONStore struct {
+	dir string
+}
+
+// NewJSONStore creates a store for the run files of a project
+func NewJSONStore(basePath string) *JSONStore {
+	return &JSONStore{dir: GetRunsDir(basePath)}
+}
+
+// SaveRun writes the run to run-<id>.json
+func (s *JSONStore) SaveRun(run *Run) error {
+	if err := os.MkdirAll(s.dir, 0755); err != nil {
+		return err
+	}
+	data, err := json.MarshalIndent(run, "", "  ")
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(filepath.Join(s.dir, fmt.Sprintf("run-%s.json", run.ID)), data, 0644)
+}
+
+// LoadRuns reads every run file, skipping files that cannot be parsed
+func (s *JSONStore) LoadRuns() ([]*Run, error) {
+	files, err := filepath.Glob(filepath.Join(s.dir, "run-*.json"))
+	if err != nil {
+		return nil, err
+	}
+	sort.Strings(files)
+
+	var runs []*Run
+	for _, file := range files {
+		data, err := os.ReadFile(file)
+		if err != nil {
+			continue
+		}
+		var run Run
+		if err := json.Unmarshal(data, &run); err != nil {
+			continue
+		}
+		runs = append(runs, &run)
+	}
+	return runs, nil
+}
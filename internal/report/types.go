@@ -0,0 +1,87 @@
+package report
+
+import "time"
+
+// Outcome values for a recorded task attempt
+const (
+	OutcomeCompleted  = "COMPLETED"
+	OutcomeFailed     = "FAILED"
+	OutcomeBlocked    = "BLOCKED"
+	OutcomePaused     = "PAUSED"
+	OutcomeAtRisk     = "AT_RISK"
+	OutcomeIncomplete = "IN_PROGRESS"
+)
+
+// Run contains everything recorded during a single execution run
+type Run struct {
+	ID        string       `json:"id"`
+	Mode      string       `json:"mode"` // "sequential" or "parallel"
+	Provider  string       `json:"provider"`
+	StartTime time.Time    `json:"startTime"`
+	EndTime   time.Time    `json:"endTime,omitempty"`
+	Tasks     []TaskRecord `json:"tasks"`
+	Commits   []Commit     `json:"commits"`
+	Tags      []string     `json:"tags"`
+}
+
+// TaskRecord records a single task attempt within a run
+type TaskRecord struct {
+	TaskID    string        `json:"taskId"`
+	TaskName  string        `json:"taskName"`
+	FeatureID string        `json:"featureId"`
+	Effort    string        `json:"effort,omitempty"` // Estimated effort of the task, for 'hermes stats'
+	Outcome   string        `json:"outcome"`
+	Subtask   string        `json:"subtask,omitempty"` // Subtask completed by the attempt
+	Duration  time.Duration `json:"duration"`
+	Cost      float64       `json:"cost"`
+	Error     string        `json:"error,omitempty"`
+	Time      time.Time     `json:"time"`
+
+	// Prompt and Output are captured for 'hermes replay', not stored in the run
+	Prompt string `json:"-"`
+	Output string `json:"-"`
+}
+
+// Commit records a git commit created during a run
+type Commit struct {
+	Hash    string `json:"hash"`
+	Message string `json:"message"`
+	Stat    string `json:"stat,omitempty"` // Compact diffstat, e.g. "3 files, +120 -8"
+}
+
+// Summary contains aggregated figures for a run
+type Summary struct {
+	Completed     int
+	Failed        int
+	Blocked       int
+	Attempts      int
+	TotalCost     float64
+	TotalDuration time.Duration
+}
+
+// Summarize aggregates the task records of a run
+func (r *Run) Summarize() Summary {
+	var s Summary
+	for _, t := range r.Tasks {
+		s.Attempts++
+		s.TotalCost += t.Cost
+		s.TotalDuration += t.Duration
+		switch t.Outcome {
+		case OutcomeCompleted:
+			s.Completed++
+		case OutcomeFailed:
+			s.Failed++
+		case OutcomeBlocked:
+			s.Blocked++
+		}
+	}
+	return s
+}
+
+// Elapsed returns the wall-clock duration of the run
+func (r *Run) Elapsed() time.Duration {
+	if r.EndTime.IsZero() {
+		return time.Since(r.StartTime)
+	}
+	return r.EndTime.Sub(r.StartTime)
+}
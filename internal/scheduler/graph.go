@@ -2,6 +2,7 @@ package scheduler
 
 import (
 	"fmt"
+	"path"
 	"strings"
 
 	"hermes/internal/task"
@@ -320,6 +321,9 @@ func (g *TaskGraph) GetBatches() ([][]*task.Task, error) {
 			return nil, fmt.Errorf("cycle detected or all tasks blocked")
 		}
 
+		// Put higher priorities into the earlier batches
+		readyTasks = SortByPriority(readyTasks)
+
 		// Split ready tasks into batches of maxTasksPerBatch
 		for len(readyTasks) > 0 {
 			batchSize := len(readyTasks)
@@ -416,3 +420,83 @@ func isDocTask(name string, keywords []string) bool {
 	}
 	return false
 }
+
+// addImplicitFileDependencies orders unfinished tasks that touch the same file,
+// so they run one after another instead of in parallel branches that conflict
+// when merged. A later task depends on the earlier one, unless the two are
+// already ordered by their dependencies.
+func addImplicitFileDependencies(tasks []*task.Task) {
+	deps := make(map[string][]string)
+	for _, t := range tasks {
+		deps[t.ID] = graphDependencies(t)
+	}
+
+	touchedBy := make(map[string][]*task.Task)
+	for _, t := range tasks {
+		if t.Status == task.StatusCompleted {
+			continue
+		}
+		seen := make(map[string]bool)
+		for _, f := range append(append([]string{}, t.FilesToTouch...), t.ExclusiveFiles...) {
+			f = normalizeTaskFile(f)
+			if f == "" || seen[f] {
+				continue
+			}
+			seen[f] = true
+			for _, earlier := range touchedBy[f] {
+				if dependsOn(deps, t.ID, earlier.ID) || dependsOn(deps, earlier.ID, t.ID) {
+					continue
+				}
+				// Copy, the slices may be shared with the task reader's cache
+				if len(t.DependsOn) > 0 {
+					t.DependsOn = append(append([]string{}, t.DependsOn...), earlier.ID)
+				} else {
+					t.Dependencies = append(append([]string{}, t.Dependencies...), earlier.ID)
+				}
+				deps[t.ID] = graphDependencies(t)
+			}
+			touchedBy[f] = append(touchedBy[f], t)
+		}
+	}
+}
+
+// graphDependencies returns the dependencies the task graph uses for a task
+func graphDependencies(t *task.Task) []string {
+	if len(t.DependsOn) > 0 {
+		return t.DependsOn
+	}
+	return t.Dependencies
+}
+
+// dependsOn returns true if task from depends on task to, directly or transitively
+func dependsOn(deps map[string][]string, from, to string) bool {
+	visited := make(map[string]bool)
+	stack := []string{from}
+	for len(stack) > 0 {
+		id := stack[len(stack)-1]
+		stack = stack[:len(stack)-1]
+		for _, dep := range deps[id] {
+			if dep == to {
+				return true
+			}
+			if !visited[dep] {
+				visited[dep] = true
+				stack = append(stack, dep)
+			}
+		}
+	}
+	return false
+}
+
+// normalizeTaskFile turns a Files to Touch entry such as "`db/users.sql` (new)"
+// into a path comparable across tasks
+func normalizeTaskFile(f string) string {
+	if i := strings.Index(f, " ("); i > 0 {
+		f = f[:i]
+	}
+	f = strings.Trim(strings.TrimSpace(f), "`")
+	if f == "" {
+		return ""
+	}
+	return path.Clean(strings.ReplaceAll(f, "\\", "/"))
+}
@@ -2,9 +2,21 @@ package scheduler
 
 import (
 	"context"
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"slices"
+	"strings"
 	"testing"
 	"time"
 
+	"hermes/internal/ai"
+	"hermes/internal/checkpoint"
+	"hermes/internal/config"
+	"hermes/internal/git"
+	"hermes/internal/isolation"
 	"hermes/internal/task"
 )
 
@@ -193,8 +205,19 @@ func TestGroupByConflicts(t *testing.T) {
 }
 
 func TestRollback(t *testing.T) {
-	// Skip if not in a git repo
-	rollback := NewRollback(".")
+	tmpDir := t.TempDir()
+	for _, args := range [][]string{
+		{"init"},
+		{"-c", "user.email=test@test.com", "-c", "user.name=Test", "commit", "--allow-empty", "-m", "initial"},
+	} {
+		cmd := exec.Command("git", args...)
+		cmd.Dir = tmpDir
+		if err := cmd.Run(); err != nil {
+			t.Skipf("git not available: %v", err)
+		}
+	}
+
+	rollback := NewRollback(tmpDir)
 	if rollback.GetBaseBranch() == "" {
 		t.Skip("Not in a git repository")
 	}
@@ -213,4 +236,465 @@ func TestRollback(t *testing.T) {
 	if !ok || commit == "" {
 		t.Error("Should be able to retrieve snapshot")
 	}
+
+	// Snapshots are saved so 'hermes rollback' can use them after the run
+	rollback.SaveSnapshot("TEST-002")
+	snapshots, err := LoadSnapshots(tmpDir)
+	if err != nil || len(snapshots) != 2 || snapshots[1].ID != 2 {
+		t.Fatalf("expected 2 saved snapshots, got %+v (%v)", snapshots, err)
+	}
+	for _, ref := range []string{"2", "test-002", commit[:7]} {
+		if s, err := FindSnapshot(tmpDir, ref); err != nil || s.Commit != commit {
+			t.Errorf("expected %s to find a snapshot of %s, got %+v (%v)", ref, commit, s, err)
+		}
+	}
+	if _, err := FindSnapshot(tmpDir, "T999"); err == nil {
+		t.Error("expected an error for an unknown snapshot")
+	}
+	if _, err := os.Stat(GetSnapshotsPath(tmpDir)); err != nil {
+		t.Error(err)
+	}
+
+	// Deleting keeps the IDs of the other snapshots
+	if removed, err := DeleteSnapshots(tmpDir, 1, 7); err != nil || removed != 1 {
+		t.Fatalf("expected 1 deleted snapshot, got %d (%v)", removed, err)
+	}
+	if s, err := FindSnapshot(tmpDir, "2"); err != nil || s.Name != "TEST-002" {
+		t.Errorf("expected snapshot 2 to be kept, got %+v (%v)", s, err)
+	}
+	if _, err := FindSnapshot(tmpDir, "TEST-001"); err == nil {
+		t.Error("expected snapshot 1 to be deleted")
+	}
+}
+
+// delayProvider completes every task after the delay set for its ID
+type delayProvider struct {
+	delays map[string]time.Duration
+}
+
+func (p *delayProvider) Name() string      { return "delay" }
+func (p *delayProvider) IsAvailable() bool { return true }
+
+func (p *delayProvider) Execute(ctx context.Context, opts *ai.ExecuteOptions) (*ai.ExecuteResult, error) {
+	select {
+	case <-time.After(p.delays[opts.TaskID]):
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	}
+	output := "Done\n---HERMES_STATUS---\nSTATUS: COMPLETE\nEXIT_SIGNAL: true\n---END_HERMES_STATUS---"
+	return &ai.ExecuteResult{Output: output, Success: true}, nil
+}
+
+func (p *delayProvider) ExecuteStream(ctx context.Context, opts *ai.ExecuteOptions) (<-chan ai.StreamEvent, error) {
+	return nil, nil
+}
+
+func TestExecuteReadyQueue(t *testing.T) {
+	tasks := []*task.Task{
+		{ID: "T010", Name: "Slow Task", Status: task.StatusNotStarted},
+		{ID: "T011", Name: "Fast Task", Status: task.StatusNotStarted},
+		{ID: "T012", Name: "Dependent Task", Status: task.StatusNotStarted, DependsOn: []string{"T011"}},
+	}
+	provider := &delayProvider{delays: map[string]time.Duration{"T010": 500 * time.Millisecond}}
+	sched := New(&config.ParallelConfig{MaxWorkers: 2, FailureStrategy: "continue"}, provider, t.TempDir(), nil)
+
+	var checkpoints []int
+	sched.SetBatchCallback(func(batchNum int, remaining [][]*task.Task) {
+		checkpoints = append(checkpoints, batchNum)
+	})
+
+	result, err := sched.Execute(context.Background(), tasks)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if result.Successful != 3 {
+		t.Fatalf("expected 3 successful tasks, got %d", result.Successful)
+	}
+
+	// The dependent task starts as soon as its dependency is done, without
+	// waiting for the slow task of the first batch
+	var order []string
+	for _, r := range result.Results {
+		order = append(order, r.TaskID)
+	}
+	if want := []string{"T011", "T012", "T010"}; !slices.Equal(order, want) {
+		t.Errorf("expected tasks to finish in order %v, got %v", want, order)
+	}
+	if len(checkpoints) == 0 || checkpoints[0] != 1 {
+		t.Errorf("expected a checkpoint of the first batch, got %v", checkpoints)
+	}
+}
+
+func TestWorkerPoolResize(t *testing.T) {
+	provider := &delayProvider{delays: map[string]time.Duration{
+		"T010": 300 * time.Millisecond,
+		"T011": 300 * time.Millisecond,
+		"T012": 300 * time.Millisecond,
+	}}
+	pool := NewWorkerPoolWithConfig(context.Background(), provider, t.TempDir(), WorkerPoolConfig{Workers: 1, MaxWorkers: 3})
+	pool.Start()
+	defer pool.Stop()
+
+	pool.Resize(3)
+	if pool.WorkerCount() != 3 {
+		t.Fatalf("expected 3 workers, got %d", pool.WorkerCount())
+	}
+	start := time.Now()
+	for _, id := range []string{"T010", "T011", "T012"} {
+		if err := pool.Submit(&task.Task{ID: id, Name: "Task " + id}); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if results := pool.WaitForBatch(3); len(results) != 3 {
+		t.Fatalf("expected 3 results, got %d", len(results))
+	}
+	if elapsed := time.Since(start); elapsed > 800*time.Millisecond {
+		t.Errorf("expected tasks to run on 3 workers at once, took %v", elapsed)
+	}
+
+	pool.Resize(10)
+	if pool.WorkerCount() != 3 {
+		t.Errorf("expected the pool to stay at its maximum of 3 workers, got %d", pool.WorkerCount())
+	}
+	pool.Resize(0)
+	if pool.WorkerCount() != 1 {
+		t.Errorf("expected the pool to keep 1 worker, got %d", pool.WorkerCount())
+	}
+}
+
+// rateLimitedProvider fails every execution with a rate limit error
+type rateLimitedProvider struct{}
+
+func (p *rateLimitedProvider) Name() string      { return "limited" }
+func (p *rateLimitedProvider) IsAvailable() bool { return true }
+
+func (p *rateLimitedProvider) Execute(ctx context.Context, opts *ai.ExecuteOptions) (*ai.ExecuteResult, error) {
+	return &ai.ExecuteResult{Error: "exit status 1: 429 Too Many Requests"}, nil
+}
+
+func (p *rateLimitedProvider) ExecuteStream(ctx context.Context, opts *ai.ExecuteOptions) (<-chan ai.StreamEvent, error) {
+	return nil, nil
+}
+
+func TestProviderFallback(t *testing.T) {
+	pool := NewWorkerPoolWithConfig(context.Background(), &rateLimitedProvider{}, t.TempDir(), WorkerPoolConfig{
+		Workers:           1,
+		MaxRetries:        1,
+		FallbackProviders: []ai.Provider{&delayProvider{}},
+	})
+	pool.Start()
+	defer pool.Stop()
+
+	if err := pool.Submit(&task.Task{ID: "T010", Name: "Rate limited task"}); err != nil {
+		t.Fatal(err)
+	}
+	results := pool.WaitForBatch(1)
+	if len(results) != 1 {
+		t.Fatalf("expected 1 result, got %d", len(results))
+	}
+	// The rate limited attempt doesn't use up the only retry
+	if r := results[0]; !r.Success || r.Provider != "delay" {
+		t.Errorf("expected task to succeed on the fallback provider, got success=%v provider=%s error=%v", r.Success, r.Provider, r.Error)
+	}
+}
+
+func TestExecuteDrain(t *testing.T) {
+	tasks := []*task.Task{
+		{ID: "T010", Name: "Running Task", Status: task.StatusNotStarted},
+		{ID: "T011", Name: "Dependent Task", Status: task.StatusNotStarted, DependsOn: []string{"T010"}},
+	}
+	provider := &delayProvider{delays: map[string]time.Duration{"T010": 300 * time.Millisecond}}
+	sched := New(&config.ParallelConfig{MaxWorkers: 2, FailureStrategy: "continue"}, provider, t.TempDir(), nil)
+	drain := make(chan struct{})
+	sched.SetDrain(drain)
+	time.AfterFunc(100*time.Millisecond, func() { close(drain) })
+
+	result, err := sched.Execute(context.Background(), tasks)
+	if !errors.Is(err, context.Canceled) {
+		t.Fatalf("expected a drained execution to be cancelled, got %v", err)
+	}
+	// The running task finishes, the dependent task never starts
+	if result.Successful != 1 || len(result.Results) != 1 || result.Results[0].TaskID != "T010" {
+		t.Errorf("expected only T010 to run to completion, got %+v", result.Results)
+	}
+}
+
+// setupMergeRepo creates a repository with one commit and returns it with
+// the name of its branch
+func setupMergeRepo(t *testing.T) (string, string) {
+	t.Helper()
+	for _, kv := range [][2]string{{"GIT_AUTHOR_NAME", "Test"}, {"GIT_AUTHOR_EMAIL", "test@test.com"}, {"GIT_COMMITTER_NAME", "Test"}, {"GIT_COMMITTER_EMAIL", "test@test.com"}} {
+		t.Setenv(kv[0], kv[1])
+	}
+	tmpDir := t.TempDir()
+	for _, args := range [][]string{{"init"}, {"commit", "--allow-empty", "-m", "initial"}} {
+		cmd := exec.Command("git", args...)
+		cmd.Dir = tmpDir
+		if err := cmd.Run(); err != nil {
+			t.Skipf("git not available: %v", err)
+		}
+	}
+	branch, err := git.New(tmpDir).GetCurrentBranch()
+	if err != nil {
+		t.Fatal(err)
+	}
+	return tmpDir, branch
+}
+
+// commitInWorkspace sets up the worktree of a task and commits a file in it
+func commitInWorkspace(t *testing.T, repoDir, taskID, file, content string) *isolation.Workspace {
+	t.Helper()
+	workspace := isolation.NewWorkspace(taskID, repoDir)
+	if err := workspace.Setup(); err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { workspace.Cleanup() })
+	os.WriteFile(filepath.Join(workspace.GetWorkPath(), file), []byte(content), 0644)
+	if err := workspace.CommitChanges("Task " + taskID); err != nil {
+		t.Fatal(err)
+	}
+	return workspace
+}
+
+func TestMergeBranchPolicy(t *testing.T) {
+	repoDir, branch := setupMergeRepo(t)
+	workspace := commitInWorkspace(t, repoDir, "T001", "a.go", "package main\n")
+
+	s := New(&config.ParallelConfig{}, &delayProvider{}, repoDir, nil)
+	s.SetCommitPolicy(git.CommitPolicy{ProtectedBranches: []string{branch}})
+	if err := s.mergeBranch(workspace); err == nil || !strings.Contains(err.Error(), "protected") {
+		t.Fatalf("expected the merge into %s to be refused, got %v", branch, err)
+	}
+	if _, err := os.Stat(filepath.Join(repoDir, "a.go")); err == nil {
+		t.Error("expected the task branch not to be merged")
+	}
+
+	s.SetCommitPolicy(git.CommitPolicy{})
+	if err := s.mergeBranch(workspace); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := os.Stat(filepath.Join(repoDir, "a.go")); err != nil {
+		t.Error("expected the task branch to be merged")
+	}
+}
+
+// conflictingProvider changes shared.txt in the workspace of a task while
+// the file is deleted on the base, so the task branch cannot be merged
+type conflictingProvider struct {
+	repoDir string
+	ran     []string
+}
+
+func (p *conflictingProvider) Name() string      { return "conflicting" }
+func (p *conflictingProvider) IsAvailable() bool { return true }
+
+func (p *conflictingProvider) Execute(ctx context.Context, opts *ai.ExecuteOptions) (*ai.ExecuteResult, error) {
+	p.ran = append(p.ran, opts.TaskID)
+	if opts.TaskID == "T001" {
+		os.WriteFile(filepath.Join(opts.WorkDir, "shared.txt"), []byte("changed by the task\n"), 0644)
+		for _, args := range [][]string{{"rm", "-q", "shared.txt"}, {"commit", "-m", "Remove shared.txt"}} {
+			cmd := exec.Command("git", args...)
+			cmd.Dir = p.repoDir
+			if output, err := cmd.CombinedOutput(); err != nil {
+				return nil, fmt.Errorf("git %v: %s", args, output)
+			}
+		}
+	}
+	output := "Done\n---HERMES_STATUS---\nSTATUS: COMPLETE\nEXIT_SIGNAL: true\n---END_HERMES_STATUS---"
+	return &ai.ExecuteResult{Output: output, Success: true}, nil
+}
+
+func (p *conflictingProvider) ExecuteStream(ctx context.Context, opts *ai.ExecuteOptions) (<-chan ai.StreamEvent, error) {
+	return nil, nil
+}
+
+func TestExecuteFailedMerge(t *testing.T) {
+	repoDir, _ := setupMergeRepo(t)
+	os.WriteFile(filepath.Join(repoDir, "shared.txt"), []byte("shared\n"), 0644)
+	for _, args := range [][]string{{"add", "shared.txt"}, {"commit", "-m", "Add shared.txt"}} {
+		cmd := exec.Command("git", args...)
+		cmd.Dir = repoDir
+		if err := cmd.Run(); err != nil {
+			t.Fatal(err)
+		}
+	}
+	tasks := []*task.Task{
+		{ID: "T001", Name: "Conflicting Task", Status: task.StatusNotStarted},
+		{ID: "T002", Name: "Dependent Task", Status: task.StatusNotStarted, DependsOn: []string{"T001"}},
+	}
+	provider := &conflictingProvider{repoDir: repoDir}
+	cfg := &config.ParallelConfig{MaxWorkers: 1, FailureStrategy: "continue", IsolatedWorkspaces: true}
+
+	result, err := New(cfg, provider, repoDir, nil).Execute(context.Background(), tasks)
+	if err != nil {
+		t.Fatal(err)
+	}
+	// The task whose branch could not be merged fails and its dependent,
+	// which would start without its changes, does not run
+	if result.Successful != 0 || result.Failed != 1 || result.Results[0].Error == nil || !strings.Contains(result.Results[0].Error.Error(), "merge failed") {
+		t.Fatalf("expected the unmerged task to fail, got %+v", result.Results)
+	}
+	if !slices.Equal(provider.ran, []string{"T001"}) {
+		t.Errorf("expected the dependent task not to run, ran %v", provider.ran)
+	}
+	if _, err := os.Stat(filepath.Join(repoDir, ".git", "MERGE_HEAD")); err == nil {
+		t.Error("expected the failed merge to be aborted")
+	}
+}
+
+func TestExecuteBranchPolicy(t *testing.T) {
+	repoDir, branch := setupMergeRepo(t)
+	tasks := []*task.Task{{ID: "T001", Name: "Task", Status: task.StatusNotStarted}}
+	provider := &interruptedProvider{}
+	s := New(&config.ParallelConfig{MaxWorkers: 1, IsolatedWorkspaces: true}, provider, repoDir, nil)
+	s.SetCommitPolicy(git.CommitPolicy{ProtectedBranches: []string{branch}})
+
+	// No task runs when none of them could be merged into the base
+	if _, err := s.Execute(context.Background(), tasks); err == nil || !strings.Contains(err.Error(), "protected") {
+		t.Fatalf("expected the run on %s to be refused, got %v", branch, err)
+	}
+	if len(provider.workDirs) != 0 {
+		t.Errorf("expected no task to run, ran in %v", provider.workDirs)
+	}
+}
+
+func TestRebaseBranch(t *testing.T) {
+	repoDir, branch := setupMergeRepo(t)
+	commit := func(file, content string) {
+		t.Helper()
+		os.WriteFile(filepath.Join(repoDir, file), []byte(content), 0644)
+		for _, args := range [][]string{{"add", file}, {"commit", "-q", "-m", "Update " + file}} {
+			cmd := exec.Command("git", args...)
+			cmd.Dir = repoDir
+			if output, err := cmd.CombinedOutput(); err != nil {
+				t.Fatalf("git %v: %v: %s", args, err, output)
+			}
+		}
+	}
+	gitOutput := func(args ...string) string {
+		t.Helper()
+		cmd := exec.Command("git", args...)
+		cmd.Dir = repoDir
+		output, err := cmd.Output()
+		if err != nil {
+			t.Fatalf("git %v: %v", args, err)
+		}
+		return strings.TrimSpace(string(output))
+	}
+	commit("shared.txt", "base\n")
+
+	s := New(&config.ParallelConfig{MergeStrategy: "rebase"}, &delayProvider{}, repoDir, nil)
+
+	// The base moved on since the task started
+	clean := commitInWorkspace(t, repoDir, "T001", "a.go", "package main\n")
+	commit("b.go", "package main\n")
+	if err := s.rebaseBranch(clean, branch); err != nil {
+		t.Fatal(err)
+	}
+	for _, file := range []string{"a.go", "b.go"} {
+		if _, err := os.Stat(filepath.Join(repoDir, file)); err != nil {
+			t.Errorf("expected %s on %s", file, branch)
+		}
+	}
+	if merges := gitOutput("rev-list", "--merges", branch); merges != "" {
+		t.Errorf("expected a linear history, got merge commits %s", merges)
+	}
+
+	// The task and the base changed the same line; the task's change wins
+	conflicting := commitInWorkspace(t, repoDir, "T002", "shared.txt", "task\n")
+	commit("shared.txt", "base moved\n")
+	if err := s.rebaseBranch(conflicting, branch); err != nil {
+		t.Fatal(err)
+	}
+	if data, _ := os.ReadFile(filepath.Join(repoDir, "shared.txt")); string(data) != "task\n" {
+		t.Errorf("expected the conflict to be resolved with the task's change, got %q", data)
+	}
+	if gitOutput("log", "-1", "--format=%s", branch) != "Task T002" {
+		t.Error("expected the task commit to be replayed onto the moved base")
+	}
+}
+
+func TestWorkspaceCommitMessage(t *testing.T) {
+	pool := NewWorkerPoolWithConfig(context.Background(), &delayProvider{}, t.TempDir(), WorkerPoolConfig{
+		Workers:      1,
+		CommitFormat: git.CommitFormat{Template: "{{.Type}}: {{.Name}} [{{.ID}}]"},
+	})
+	defer pool.Stop()
+
+	if got := pool.commitMessage(&task.Task{ID: "T001", Name: "Fix login"}); got != "fix: Fix login [T001]" {
+		t.Errorf("expected the configured template, got %q", got)
+	}
+
+	pool.commitFormat = git.CommitFormat{Template: "{{.Missing"}
+	if got := pool.commitMessage(&task.Task{ID: "T001", Name: "Add login"}); got != "feat(T001): Add login" {
+		t.Errorf("expected the default message for an invalid template, got %q", got)
+	}
+}
+
+// interruptedProvider leaves a file in the task's work directory and
+// interrupts the run, or, once resumed, reports whether it found the file
+type interruptedProvider struct {
+	interrupt context.CancelFunc
+	workDirs  []string
+	found     bool
+}
+
+func (p *interruptedProvider) Name() string      { return "interrupted" }
+func (p *interruptedProvider) IsAvailable() bool { return true }
+
+func (p *interruptedProvider) Execute(ctx context.Context, opts *ai.ExecuteOptions) (*ai.ExecuteResult, error) {
+	p.workDirs = append(p.workDirs, opts.WorkDir)
+	if p.interrupt != nil {
+		os.WriteFile(filepath.Join(opts.WorkDir, "wip.txt"), []byte("work in progress\n"), 0644)
+		p.interrupt()
+		<-ctx.Done()
+		return nil, ctx.Err()
+	}
+	_, err := os.Stat(filepath.Join(opts.WorkDir, "wip.txt"))
+	p.found = err == nil
+	output := "Done\n---HERMES_STATUS---\nSTATUS: COMPLETE\nEXIT_SIGNAL: true\n---END_HERMES_STATUS---"
+	return &ai.ExecuteResult{Output: output, Success: true}, nil
+}
+
+func (p *interruptedProvider) ExecuteStream(ctx context.Context, opts *ai.ExecuteOptions) (<-chan ai.StreamEvent, error) {
+	return nil, nil
+}
+
+func TestResumeReattachesWorktrees(t *testing.T) {
+	repoDir, _ := setupMergeRepo(t)
+	tasks := []*task.Task{{ID: "T001", Name: "Interrupted Task", Status: task.StatusNotStarted}}
+	cfg := &config.ParallelConfig{MaxWorkers: 1, FailureStrategy: "continue", IsolatedWorkspaces: true}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	first := &interruptedProvider{interrupt: cancel}
+	if _, err := New(cfg, first, repoDir, nil).Execute(ctx, tasks); err == nil {
+		t.Fatal("expected the execution to be interrupted")
+	}
+	if len(first.workDirs) == 0 || first.workDirs[0] == repoDir {
+		t.Fatalf("expected the task to run in a worktree, got %v", first.workDirs)
+	}
+	worktree := first.workDirs[0]
+	if _, err := os.Stat(filepath.Join(worktree, "wip.txt")); err != nil {
+		t.Fatal("expected the worktree of the interrupted task to be kept")
+	}
+
+	// The remaining queue is persisted and resumed by a new process
+	if err := checkpoint.Save(repoDir, &checkpoint.Checkpoint{Mode: checkpoint.ModeParallel, Batches: [][]string{{"T001"}}}); err != nil {
+		t.Fatal(err)
+	}
+	cp, err := checkpoint.Load(repoDir)
+	if err != nil || cp == nil {
+		t.Fatalf("expected the checkpoint to be loaded, got %v", err)
+	}
+	second := &interruptedProvider{}
+	result, err := New(cfg, second, repoDir, nil).Resume(context.Background(), tasks, cp.Batches)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if result.Successful != 1 {
+		t.Fatalf("expected the resumed task to succeed, got %+v", result.Results)
+	}
+	if len(second.workDirs) != 1 || second.workDirs[0] != worktree || !second.found {
+		t.Errorf("expected the task to continue in %s with its changes, got %v (changes found: %v)", worktree, second.workDirs, second.found)
+	}
 }
@@ -7,6 +7,9 @@ import (
 	"path/filepath"
 	"sync"
 	"time"
+
+	"hermes/internal/logfile"
+	"hermes/internal/task"
 )
 
 // ParallelLogger provides thread-safe logging for parallel task execution
@@ -21,7 +24,7 @@ type ParallelLogger struct {
 
 // LogWriter wraps a file with thread-safe writing
 type LogWriter struct {
-	file   *os.File
+	file   *logfile.File
 	mu     sync.Mutex
 	prefix string
 }
@@ -34,7 +37,7 @@ func NewLogWriter(path, prefix string) (*LogWriter, error) {
 		return nil, fmt.Errorf("failed to create log directory: %w", err)
 	}
 
-	file, err := os.OpenFile(path, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0644)
+	file, err := logfile.Open(path)
 	if err != nil {
 		return nil, fmt.Errorf("failed to open log file: %w", err)
 	}
@@ -219,7 +222,7 @@ func (l *ParallelLogger) GetLogDirectory() string {
 
 // WriteOutput writes task output to a separate file
 func (l *ParallelLogger) WriteOutput(taskID, output string) error {
-	outputPath := filepath.Join(l.GetLogDirectory(), fmt.Sprintf("output-%s.log", taskID))
+	outputPath := filepath.Join(l.GetLogDirectory(), fmt.Sprintf("output-%s.log", task.FileSafeID(taskID)))
 	return os.WriteFile(outputPath, []byte(output), 0644)
 }
 
@@ -6,11 +6,18 @@ import (
 	"sync"
 	"time"
 
+	"go.opentelemetry.io/otel/attribute"
 	"hermes/internal/ai"
 	"hermes/internal/analyzer"
+	"hermes/internal/config"
+	"hermes/internal/converter"
+	"hermes/internal/git"
 	"hermes/internal/isolation"
 	"hermes/internal/prompt"
 	"hermes/internal/task"
+	"hermes/internal/tasklog"
+	"hermes/internal/tracing"
+	"hermes/internal/verify"
 )
 
 // ProgressEvent represents a progress update from worker pool
@@ -38,42 +45,80 @@ type TaskResult struct {
 	StartTime time.Time
 	EndTime   time.Time
 	WorkerID  int
+	Feedback  string // Why the attempt failed, fed back to the next one, see prompt.Failure
+	Provider  string // Provider of the last attempt
+	Prompt    string // Prompt of the last attempt, recorded for 'hermes replay'
+	// The provider timed out or was rate limited rather than the task failing
+	ProviderFailed bool
 }
 
 // WorkerPool manages multiple AI agent instances for parallel execution
 type WorkerPool struct {
 	workers          int
+	maxWorkers       int
+	nextWorkerID     int
+	retire           chan struct{} // Each value stops one idle worker
 	taskQueue        chan *task.Task
 	results          chan *TaskResult
 	ctx              context.Context
 	cancel           context.CancelFunc
 	wg               sync.WaitGroup
 	provider         ai.Provider
+	fallbacks        []ai.Provider
 	workDir          string
 	mu               sync.Mutex
 	running          int
 	useIsolation     bool
+	backend          string
+	reattach         bool
+	worktrees        *isolation.WorktreePool // Reused worktrees, nil when each task gets its own
 	workspaces       map[string]*isolation.Workspace
 	logger           *ParallelLogger
 	streamOutput     bool
 	maxRetries       int
 	taskTimeout      time.Duration
+	effortTimeouts   map[string]int
 	progressCallback ProgressCallback
 	currentBatch     int
 	totalBatches     int
+	analyzerConfig   *config.AnalyzerConfig
+	taskModeConfig   *config.TaskModeConfig
+	projectContext   *config.ProjectContextConfig
+	memory           bool
+	gitHistory       int
+	signing          git.Signing
+	commitFormat     git.CommitFormat
 }
 
 // WorkerPoolConfig contains configuration for the worker pool
 type WorkerPoolConfig struct {
 	Workers          int
+	MaxWorkers       int // Upper bound for Resize, defaults to Workers
 	UseIsolation     bool
 	Logger           *ParallelLogger
 	StreamOutput     bool
 	MaxRetries       int
 	TaskTimeout      time.Duration
+	EffortTimeouts   map[string]int // Task timeouts in seconds by estimated effort
 	ProgressCallback ProgressCallback
 	CurrentBatch     int
 	TotalBatches     int
+	AnalyzerConfig   *config.AnalyzerConfig
+	TaskModeConfig   *config.TaskModeConfig       // Test command and verification settings
+	ProjectContext   *config.ProjectContextConfig // Overview of the project prepended to task prompts
+	Memory           bool                         // Share learnings between tasks in .hermes/MEMORY.md
+	GitHistory       int                          // Recent task commits added to task prompts
+	// Providers a task is retried on, in order, when the provider fails
+	FallbackProviders []ai.Provider
+	// Reuse the worktrees an interrupted run left behind instead of recreating them
+	ReattachWorkspaces bool
+	// Isolation backend of the workspaces, see isolation.ResolveBackend
+	IsolationBackend string
+	// Reuse the worktrees of finished tasks instead of creating one per task
+	PoolWorktrees bool
+	// Signing and message format of the task commits in isolated workspaces
+	Signing      git.Signing
+	CommitFormat git.CommitFormat
 }
 
 // NewWorkerPool creates a new worker pool
@@ -96,31 +141,77 @@ func NewWorkerPoolWithConfig(ctx context.Context, provider ai.Provider, workDir
 	if taskTimeout <= 0 {
 		taskTimeout = 5 * time.Minute // Default 5 minute timeout per task
 	}
+	maxWorkers := cfg.MaxWorkers
+	if maxWorkers < cfg.Workers {
+		maxWorkers = cfg.Workers
+	}
+	backend := isolation.ResolveBackend(cfg.IsolationBackend, workDir)
+	var worktrees *isolation.WorktreePool
+	if cfg.UseIsolation && cfg.PoolWorktrees && backend == isolation.BackendWorktree {
+		worktrees = isolation.NewWorktreePool(workDir)
+	}
 	return &WorkerPool{
 		workers:          cfg.Workers,
-		taskQueue:        make(chan *task.Task, cfg.Workers*2),
-		results:          make(chan *TaskResult, cfg.Workers*2),
+		maxWorkers:       maxWorkers,
+		retire:           make(chan struct{}, maxWorkers),
+		taskQueue:        make(chan *task.Task, maxWorkers*2),
+		results:          make(chan *TaskResult, maxWorkers*2),
 		ctx:              ctx,
 		cancel:           cancel,
 		provider:         provider,
+		fallbacks:        cfg.FallbackProviders,
 		workDir:          workDir,
 		useIsolation:     cfg.UseIsolation,
+		backend:          backend,
+		reattach:         cfg.ReattachWorkspaces,
+		worktrees:        worktrees,
 		workspaces:       make(map[string]*isolation.Workspace),
 		logger:           cfg.Logger,
 		streamOutput:     cfg.StreamOutput,
 		maxRetries:       maxRetries,
 		taskTimeout:      taskTimeout,
+		effortTimeouts:   cfg.EffortTimeouts,
 		progressCallback: cfg.ProgressCallback,
 		currentBatch:     cfg.CurrentBatch,
 		totalBatches:     cfg.TotalBatches,
+		analyzerConfig:   cfg.AnalyzerConfig,
+		taskModeConfig:   cfg.TaskModeConfig,
+		projectContext:   cfg.ProjectContext,
+		memory:           cfg.Memory,
+		gitHistory:       cfg.GitHistory,
+		signing:          cfg.Signing,
+		commitFormat:     cfg.CommitFormat,
 	}
 }
 
 // Start starts the worker pool
 func (p *WorkerPool) Start() {
-	for i := 0; i < p.workers; i++ {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	for ; p.nextWorkerID < p.workers; p.nextWorkerID++ {
 		p.wg.Add(1)
-		go p.worker(i)
+		go p.worker(p.nextWorkerID)
+	}
+}
+
+// Resize grows or shrinks the pool to n workers, at least one and at most
+// the configured maximum. Surplus workers stop once they are idle.
+func (p *WorkerPool) Resize(n int) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	n = max(1, min(n, p.maxWorkers))
+	for ; p.workers < n; p.workers++ {
+		select {
+		case <-p.retire:
+			// Cancel a retirement no worker has picked up yet
+		default:
+			p.wg.Add(1)
+			go p.worker(p.nextWorkerID)
+			p.nextWorkerID++
+		}
+	}
+	for ; p.workers > n; p.workers-- {
+		p.retire <- struct{}{}
 	}
 }
 
@@ -132,6 +223,8 @@ func (p *WorkerPool) worker(workerID int) {
 		select {
 		case <-p.ctx.Done():
 			return
+		case <-p.retire:
+			return
 		case t, ok := <-p.taskQueue:
 			if !ok {
 				return
@@ -140,12 +233,27 @@ func (p *WorkerPool) worker(workerID int) {
 			
 			// Retry loop for task execution
 			var result *TaskResult
+			feedback := ""
+			providers := append([]ai.Provider{p.provider}, p.fallbacks...)
+			current := 0
 			for attempt := 1; attempt <= p.maxRetries; attempt++ {
-				result = p.executeTask(workerID, t, attempt)
+				result = p.executeTask(workerID, t, providers[current], attempt, feedback)
 				
 				if result.Success {
 					break // Task completed successfully
 				}
+
+				// Retry a provider failure on the next provider without using up an attempt
+				if result.ProviderFailed && current+1 < len(providers) && p.ctx.Err() == nil {
+					current++
+					if p.logger != nil {
+						p.logger.Worker(workerID+1, "Task %s failed on %s (%v), retrying on %s",
+							t.ID, result.Provider, result.Error, providers[current].Name())
+					}
+					attempt--
+					continue
+				}
+				feedback = result.Feedback
 				
 				// Check if we should retry
 				if attempt < p.maxRetries {
@@ -207,7 +315,7 @@ func (p *WorkerPool) notifyProgress(workerID int, taskID, taskName, status strin
 }
 
 // executeTask executes a single task and returns the result
-func (p *WorkerPool) executeTask(workerID int, t *task.Task, attempt int) *TaskResult {
+func (p *WorkerPool) executeTask(workerID int, t *task.Task, provider ai.Provider, attempt int, feedback string) *TaskResult {
 	startTime := time.Now()
 
 	result := &TaskResult{
@@ -215,7 +323,21 @@ func (p *WorkerPool) executeTask(workerID int, t *task.Task, attempt int) *TaskR
 		TaskName:  t.Name,
 		StartTime: startTime,
 		WorkerID:  workerID + 1, // 1-indexed for display
+		Provider:  provider.Name(),
+	}
+
+	ctx, span := tracing.Start(p.ctx, "task",
+		attribute.String("task.id", t.ID),
+		attribute.String("task.name", t.Name),
+		attribute.Int("worker", workerID+1),
+		attribute.Int("attempt", attempt))
+	defer func() { tracing.End(span, result.Error) }()
+
+	taskLog, err := tasklog.Create(p.workDir, t.ID)
+	if err != nil && p.logger != nil {
+		p.logger.Worker(workerID+1, "Failed to create task log: %v", err)
 	}
+	defer taskLog.Close()
 
 	// Notify progress: started
 	p.notifyProgress(workerID+1, t.ID, t.Name, "started")
@@ -243,7 +365,26 @@ func (p *WorkerPool) executeTask(workerID int, t *task.Task, attempt int) *TaskR
 	var workspace *isolation.Workspace
 	if p.useIsolation {
 		workspace = isolation.NewWorkspaceWithName(t.ID, t.Name, p.workDir)
-		if err := workspace.Setup(); err != nil {
+		workspace.Backend = p.backend
+		workspace.Signing = p.signing
+		// The first attempt of a resumed task continues in its old worktree
+		p.mu.Lock()
+		previous, retry := p.workspaces[t.ID]
+		p.mu.Unlock()
+		if retry && p.worktrees != nil {
+			p.worktrees.Release(previous)
+		}
+		var err error
+		if p.reattach && !retry && p.attachWorkspace(workspace) {
+			if p.logger != nil {
+				p.logger.Worker(workerID+1, "Reattached to workspace of the interrupted run: %s", workspace.GetWorkPath())
+			}
+		} else if p.worktrees != nil {
+			err = p.worktrees.Acquire(workspace)
+		} else {
+			err = workspace.Setup()
+		}
+		if err != nil {
 			// Fall back to shared workspace
 			if p.logger != nil {
 				p.logger.Worker(workerID+1, "Failed to create isolated workspace, using shared: %v", err)
@@ -259,29 +400,59 @@ func (p *WorkerPool) executeTask(workerID int, t *task.Task, attempt int) *TaskR
 
 	// Inject task into PROMPT.md
 	injector := prompt.NewInjector(workDir)
-	if err := injector.AddTask(t); err != nil {
+	if err := injector.AddTaskWithFeedback(t, feedback); err != nil {
 		if p.logger != nil {
 			p.logger.Worker(workerID+1, "Failed to inject task into prompt: %v", err)
 		}
 	}
 
 	// Create task executor with appropriate work directory
-	executor := ai.NewTaskExecutor(p.provider, workDir)
+	executor := ai.NewTaskExecutor(provider, workDir)
+	if p.projectContext != nil {
+		executor.SetProjectContext(converter.ProjectContext(workDir, *p.projectContext))
+	}
+	executor.SetGitHistory(git.New(workDir).TaskHistory(p.gitHistory))
+	if p.memory {
+		// Learnings go to the repository, not the task's worktree
+		executor.SetMemory(p.workDir)
+	}
+	if p.logger != nil {
+		executor.SetWarn(func(format string, args ...interface{}) {
+			p.logger.Worker(workerID+1, format, args...)
+		})
+	}
+	if taskLog != nil {
+		executor.SetTranscript(taskLog)
+	}
 
 	// Read prompt content (includes injected task)
 	promptContent, _ := injector.Read()
+	result.Prompt = promptContent
 
-	// Execute the task with timeout
-	taskCtx, taskCancel := context.WithTimeout(p.ctx, p.taskTimeout)
+	// Execute the task with the timeout set for it, its effort or the pool
+	taskTimeout := t.ResolveTimeout(p.taskTimeout, p.effortTimeouts)
+	taskCtx, taskCancel := context.WithTimeout(ctx, taskTimeout)
 	defer taskCancel()
 	execResult, err := executor.ExecuteTask(taskCtx, t, promptContent, p.streamOutput)
 
 	result.EndTime = time.Now()
 	result.Duration = result.EndTime.Sub(startTime)
 
+	// A timed out or rate limited provider may be replaced by another one
+	if taskCtx.Err() == context.DeadlineExceeded {
+		err = fmt.Errorf("%s timed out after %v", provider.Name(), taskTimeout)
+		result.ProviderFailed = true
+	} else if ctx.Err() == nil && ai.IsProviderError(execResult, err) {
+		if err == nil {
+			err = fmt.Errorf("%s failed: %s", provider.Name(), execResult.Error)
+		}
+		result.ProviderFailed = true
+	}
+
 	if err != nil {
 		result.Success = false
 		result.Error = err
+		result.Feedback = prompt.Failure{Reason: prompt.ReasonError, Details: err.Error()}.Feedback()
 		if p.logger != nil {
 			p.logger.TaskFailed(workerID+1, t.ID, err)
 		}
@@ -291,12 +462,15 @@ func (p *WorkerPool) executeTask(workerID int, t *task.Task, attempt int) *TaskR
 	result.Output = execResult.Output
 
 	// Analyze AI response to determine if task is truly complete
-	respAnalyzer := analyzer.NewResponseAnalyzer()
+	respAnalyzer := analyzer.NewResponseAnalyzerWithConfig(p.analyzerConfig)
 
 	// Check if HERMES_STATUS block is present - if not, treat as incomplete
 	if !respAnalyzer.HasStatusBlock(execResult.Output) {
 		result.Success = false
 		result.Error = fmt.Errorf("missing HERMES_STATUS block in AI response")
+		result.Feedback = prompt.Failure{Reason: prompt.ReasonNoStatusBlock, Output: execResult.Output}.Feedback()
+		taskLog.Section("Analysis")
+		taskLog.Printf("Missing HERMES_STATUS block, the task will be retried\n")
 		if p.logger != nil {
 			p.logger.Worker(workerID+1, "Task %s missing HERMES_STATUS block - will retry", t.ID)
 		}
@@ -304,6 +478,7 @@ func (p *WorkerPool) executeTask(workerID int, t *task.Task, attempt int) *TaskR
 	}
 
 	analysis := respAnalyzer.AnalyzeWithCriteria(execResult.Output, t.SuccessCriteria)
+	taskLog.Analysis(analysis)
 
 	if p.logger != nil {
 		p.logger.Worker(workerID+1, "Analysis: complete=%v blocked=%v atRisk=%v paused=%v progress=%v confidence=%.2f criteria=%d/%d",
@@ -314,7 +489,7 @@ func (p *WorkerPool) executeTask(workerID int, t *task.Task, attempt int) *TaskR
 	if analysis.IsBlocked {
 		result.Success = false
 		result.Error = fmt.Errorf("task blocked: %s", analysis.Recommendation)
-		statusUpdater.UpdateTaskStatus(t.ID, task.StatusBlocked)
+		statusUpdater.BlockTask(t.ID, analysis.Recommendation)
 		if p.logger != nil {
 			p.logger.Worker(workerID+1, "Task %s is BLOCKED: %s", t.ID, analysis.Recommendation)
 		}
@@ -341,6 +516,63 @@ func (p *WorkerPool) executeTask(workerID int, t *task.Task, attempt int) *TaskR
 		// Continue processing - at-risk doesn't stop execution
 	}
 
+	// Format the changes, then run the build, lint and test commands and the
+	// task's command criteria in its workspace before accepting COMPLETE,
+	// letting the AI fix failures a bounded number of times, and check the coverage
+	taskMode := p.taskModeConfig
+	if taskMode == nil {
+		taskMode = &config.TaskModeConfig{}
+	}
+	verifyTimeout := time.Duration(taskMode.VerifyTimeout) * time.Second
+	format := func() {
+		if err := verify.Format(ctx, workDir, taskMode.FormatCommand, verifyTimeout, taskLog); err != nil && p.logger != nil {
+			p.logger.Worker(workerID+1, "Task %s format command failed: %v", t.ID, err)
+		}
+	}
+	if analysis.IsComplete && taskMode.FormatCommand != "" {
+		taskLog.Section("Format")
+		format()
+	}
+	if commands := t.GateCommands(taskMode.TestCommand, taskMode.BuildCommand, taskMode.LintCommand); analysis.IsComplete && (len(commands) > 0 || taskMode.CoverageThreshold > 0) {
+		taskLog.Section("Verification")
+		fix := func(feedback string) error {
+			if p.logger != nil {
+				p.logger.Worker(workerID+1, "Task %s failed verification, asking the AI to fix it", t.ID)
+			}
+			failure := prompt.Failure{Reason: prompt.ReasonVerification, Details: feedback}
+			if err := injector.AddTaskWithFeedback(t, failure.Feedback()); err != nil {
+				return err
+			}
+			fixPrompt, _ := injector.Read()
+			fixCtx, fixCancel := context.WithTimeout(ctx, taskTimeout)
+			defer fixCancel()
+			if _, err := executor.ExecuteTask(fixCtx, t, fixPrompt, p.streamOutput); err != nil {
+				return err
+			}
+			format()
+			return nil
+		}
+		failed, _ := verify.Gate(ctx, workDir, commands, verifyTimeout, taskLog, taskMode.TestFixAttempts, fix)
+		if len(failed) == 0 && taskMode.CoverageThreshold > 0 {
+			taskLog.Section("Coverage")
+			if r := verify.Coverage(ctx, workDir, taskMode.CoverageCommand, taskMode.CoverageProfile, taskMode.CoverageThreshold, verifyTimeout, taskLog); r != nil {
+				failed = []verify.Result{*r}
+			}
+		}
+		result.EndTime = time.Now()
+		result.Duration = result.EndTime.Sub(startTime)
+		if len(failed) > 0 {
+			result.Success = false
+			result.Error = fmt.Errorf("%s", verify.Summary(failed))
+			result.Feedback = prompt.Failure{Reason: prompt.ReasonVerification, Details: verify.Feedback(failed), Analysis: analysis}.Feedback()
+			if p.logger != nil {
+				p.logger.Worker(workerID+1, "Task %s %s", t.ID, verify.Summary(failed))
+			}
+			p.notifyProgress(workerID+1, t.ID, t.Name, "failed")
+			return result
+		}
+	}
+
 	// Task is successful only if AI indicates completion
 	if analysis.IsComplete {
 		result.Success = true
@@ -364,6 +596,7 @@ func (p *WorkerPool) executeTask(workerID int, t *task.Task, attempt int) *TaskR
 		result.Success = false
 		result.Error = fmt.Errorf("task not completed by AI (progress=%v, confidence=%.2f)", 
 			analysis.HasProgress, analysis.Confidence)
+		result.Feedback = prompt.Failure{Reason: prompt.ReasonIncomplete, Analysis: analysis, Output: execResult.Output}.Feedback()
 		if p.logger != nil {
 			p.logger.Worker(workerID+1, "Task %s not marked as complete by AI", t.ID)
 		}
@@ -373,8 +606,11 @@ func (p *WorkerPool) executeTask(workerID int, t *task.Task, attempt int) *TaskR
 
 	// Commit changes in isolated workspace
 	if workspace != nil && workspace.HasUncommittedChanges() {
-		commitMsg := fmt.Sprintf("Complete task %s: %s", t.ID, t.Name)
+		commitMsg := p.commitMessage(t)
+		taskLog.Section("Git")
+		taskLog.Printf("Commit on %s: %s\n", workspace.GetBranch(), commitMsg)
 		if err := workspace.CommitChanges(commitMsg); err != nil {
+			taskLog.Printf("Commit failed: %v\n", err)
 			if p.logger != nil {
 				p.logger.Worker(workerID+1, "Failed to commit changes: %v", err)
 			}
@@ -441,6 +677,8 @@ func (p *WorkerPool) WaitForBatch(count int) []*TaskResult {
 
 // WorkerCount returns the number of workers
 func (p *WorkerPool) WorkerCount() int {
+	p.mu.Lock()
+	defer p.mu.Unlock()
 	return p.workers
 }
 
@@ -449,6 +687,37 @@ func (p *WorkerPool) IsRunning() bool {
 	return p.GetRunningCount() > 0
 }
 
+// commitMessage returns the message of a task commit in an isolated
+// workspace, in the configured format like the commits of sequential runs
+func (p *WorkerPool) commitMessage(t *task.Task) string {
+	g := git.New(p.workDir)
+	if err := g.SetCommitFormat(p.commitFormat); err == nil {
+		if message, err := g.TaskCommitMessage(t); err == nil {
+			return message
+		}
+	}
+	g.SetCommitFormat(git.CommitFormat{})
+	message, _ := g.TaskCommitMessage(t)
+	return message
+}
+
+// attachWorkspace reuses the workspace an interrupted run left for a task
+func (p *WorkerPool) attachWorkspace(workspace *isolation.Workspace) bool {
+	if p.worktrees != nil && p.worktrees.Attach(workspace) {
+		return true
+	}
+	return workspace.Attach()
+}
+
+// ReleaseWorkspace removes the workspace of a finished task, or returns its
+// worktree to the pool for the next task
+func (p *WorkerPool) ReleaseWorkspace(workspace *isolation.Workspace) error {
+	if p.worktrees != nil && workspace.Backend != isolation.BackendCopy {
+		return p.worktrees.Release(workspace)
+	}
+	return workspace.Cleanup()
+}
+
 // GetWorkspaces returns all workspaces created by the pool
 func (p *WorkerPool) GetWorkspaces() map[string]*isolation.Workspace {
 	p.mu.Lock()
@@ -0,0 +1,102 @@
+package scheduler
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// errSamplingUnsupported is returned where process trees can't be sampled
+var errSamplingUnsupported = errors.New("process sampling is only supported on Linux")
+
+// clockTicks is the USER_HZ unit of CPU times in /proc, 100 on all common Linux builds
+const clockTicks = 100
+
+// processUsage is the resource usage of a process and its descendants
+type processUsage struct {
+	MemoryMB int64         // Resident memory
+	CPUTime  time.Duration // User and system time consumed so far
+}
+
+// procStat holds the fields of /proc/<pid>/stat the sampler needs
+type procStat struct {
+	ppid     int
+	cpuTicks int64
+	rssPages int64
+}
+
+// sampleProcessTree returns the resource usage of pid and all its
+// descendants, such as the AI provider processes started by workers
+func sampleProcessTree(pid int) (processUsage, error) {
+	if runtime.GOOS != "linux" {
+		return processUsage{}, errSamplingUnsupported
+	}
+	dirs, err := filepath.Glob("/proc/[0-9]*")
+	if err != nil {
+		return processUsage{}, err
+	}
+
+	stats := make(map[int]procStat, len(dirs))
+	children := make(map[int][]int)
+	for _, dir := range dirs {
+		id, err := strconv.Atoi(filepath.Base(dir))
+		if err != nil {
+			continue
+		}
+		data, err := os.ReadFile(filepath.Join(dir, "stat"))
+		if err != nil {
+			continue // The process exited meanwhile
+		}
+		st, ok := parseProcStat(string(data))
+		if !ok {
+			continue
+		}
+		stats[id] = st
+		children[st.ppid] = append(children[st.ppid], id)
+	}
+	if _, ok := stats[pid]; !ok {
+		return processUsage{}, errors.New("process not found in /proc")
+	}
+
+	var ticks, pages int64
+	queue := []int{pid}
+	for len(queue) > 0 {
+		id := queue[0]
+		queue = queue[1:]
+		st := stats[id]
+		ticks += st.cpuTicks
+		pages += st.rssPages
+		queue = append(queue, children[id]...)
+	}
+	return processUsage{
+		MemoryMB: pages * int64(os.Getpagesize()) / 1024 / 1024,
+		CPUTime:  time.Duration(ticks) * time.Second / clockTicks,
+	}, nil
+}
+
+// parseProcStat parses the parent PID, the user and system CPU ticks and the
+// resident set size of a /proc/<pid>/stat line
+func parseProcStat(line string) (procStat, bool) {
+	// The command name is in parentheses and may contain spaces
+	end := strings.LastIndexByte(line, ')')
+	if end < 0 {
+		return procStat{}, false
+	}
+	// Fields after the name start with the state (field 3)
+	fields := strings.Fields(line[end+1:])
+	if len(fields) < 22 {
+		return procStat{}, false
+	}
+	ppid, err1 := strconv.Atoi(fields[1])
+	utime, err2 := strconv.ParseInt(fields[11], 10, 64)
+	stime, err3 := strconv.ParseInt(fields[12], 10, 64)
+	rss, err4 := strconv.ParseInt(fields[21], 10, 64)
+	if err1 != nil || err2 != nil || err3 != nil || err4 != nil {
+		return procStat{}, false
+	}
+	return procStat{ppid: ppid, cpuTicks: utime + stime, rssPages: rss}, true
+}
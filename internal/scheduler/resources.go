@@ -3,6 +3,7 @@ package scheduler
 import (
 	"context"
 	"fmt"
+	"os"
 	"runtime"
 	"sync"
 	"sync/atomic"
@@ -20,10 +21,22 @@ type ResourceMonitor struct {
 	apiCallsWindow []time.Time
 	totalCost      float64
 	maxCostPerHour float64
+
+	// Latest sample of the Hermes process tree, including the AI providers
+	// started by workers
+	pid        int
+	sampledAt  time.Time
+	cpuTime    time.Duration
+	memoryMB   int64
+	cpuPercent float64
+	sampleErr  error
 	
 	mu sync.RWMutex
 }
 
+// sampleInterval is the minimum time between two samples of the process tree
+const sampleInterval = time.Second
+
 // NewResourceMonitor creates a new resource monitor
 func NewResourceMonitor(maxMemoryMB int64, maxCPUPercent int, maxCallsPerMin int) *ResourceMonitor {
 	return &ResourceMonitor{
@@ -31,7 +44,37 @@ func NewResourceMonitor(maxMemoryMB int64, maxCPUPercent int, maxCallsPerMin int
 		maxCPUPercent:  maxCPUPercent,
 		maxCallsPerMin: maxCallsPerMin,
 		apiCallsWindow: make([]time.Time, 0),
+		pid:            os.Getpid(),
+	}
+}
+
+// Sample measures the memory and CPU use of the Hermes process and its
+// descendants. CPU use is the share of all cores used since the previous
+// sample. Samples taken less than a second apart are reused.
+func (m *ResourceMonitor) Sample() (memoryMB int64, cpuPercent float64, err error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	now := time.Now()
+	if !m.sampledAt.IsZero() && now.Sub(m.sampledAt) < sampleInterval {
+		return m.memoryMB, m.cpuPercent, m.sampleErr
+	}
+
+	usage, err := sampleProcessTree(m.pid)
+	if err != nil {
+		m.sampledAt = now
+		m.sampleErr = err
+		return 0, 0, err
+	}
+	if !m.sampledAt.IsZero() && m.sampleErr == nil {
+		wall := now.Sub(m.sampledAt) * time.Duration(runtime.NumCPU())
+		m.cpuPercent = float64(usage.CPUTime-m.cpuTime) / float64(wall) * 100
 	}
+	m.sampledAt = now
+	m.sampleErr = nil
+	m.cpuTime = usage.CPUTime
+	m.memoryMB = usage.MemoryMB
+	return m.memoryMB, m.cpuPercent, nil
 }
 
 // SetCostLimit sets the maximum cost per hour
@@ -108,16 +151,25 @@ func (m *ResourceMonitor) CheckMemory() bool {
 	if m.maxMemoryMB <= 0 {
 		return true
 	}
-	
-	var memStats runtime.MemStats
-	runtime.ReadMemStats(&memStats)
-	
-	usedMB := int64(memStats.Alloc / 1024 / 1024)
-	return usedMB < m.maxMemoryMB
+	return m.GetMemoryUsageMB() < m.maxMemoryMB
+}
+
+// CheckCPU checks if CPU usage is acceptable. It always passes where the
+// process tree can't be sampled.
+func (m *ResourceMonitor) CheckCPU() bool {
+	if m.maxCPUPercent <= 0 {
+		return true
+	}
+	_, cpuPercent, err := m.Sample()
+	return err != nil || cpuPercent < float64(m.maxCPUPercent)
 }
 
-// GetMemoryUsageMB returns current memory usage in MB
+// GetMemoryUsageMB returns current memory usage in MB of the Hermes process
+// tree, or of the Hermes process alone where the tree can't be sampled
 func (m *ResourceMonitor) GetMemoryUsageMB() int64 {
+	if memoryMB, _, err := m.Sample(); err == nil {
+		return memoryMB
+	}
 	var memStats runtime.MemStats
 	runtime.ReadMemStats(&memStats)
 	return int64(memStats.Alloc / 1024 / 1024)
@@ -125,7 +177,7 @@ func (m *ResourceMonitor) GetMemoryUsageMB() int64 {
 
 // CanStartWorker checks if we have resources to start a new worker
 func (m *ResourceMonitor) CanStartWorker() bool {
-	return m.CheckMemory() && m.CanMakeAPICall()
+	return m.CheckMemory() && m.CheckCPU() && m.CanMakeAPICall()
 }
 
 // WaitForResources waits until resources are available
@@ -143,6 +195,9 @@ func (m *ResourceMonitor) WaitForResources(ctx context.Context) error {
 
 // GetStats returns current resource statistics
 func (m *ResourceMonitor) GetStats() ResourceStats {
+	memoryMB := m.GetMemoryUsageMB()
+	_, cpuPercent, _ := m.Sample()
+
 	m.mu.RLock()
 	defer m.mu.RUnlock()
 	
@@ -159,8 +214,10 @@ func (m *ResourceMonitor) GetStats() ResourceStats {
 		TotalAPICalls:    atomic.LoadInt64(&m.apiCalls),
 		CallsPerMinute:   recentCalls,
 		TotalCost:        m.totalCost,
-		MemoryUsageMB:    m.GetMemoryUsageMB(),
+		MemoryUsageMB:    memoryMB,
 		MaxMemoryMB:      m.maxMemoryMB,
+		CPUPercent:       cpuPercent,
+		MaxCPUPercent:    m.maxCPUPercent,
 		MaxCallsPerMin:   m.maxCallsPerMin,
 		MaxCostPerHour:   m.maxCostPerHour,
 	}
@@ -173,6 +230,8 @@ type ResourceStats struct {
 	TotalCost       float64
 	MemoryUsageMB   int64
 	MaxMemoryMB     int64
+	CPUPercent      float64
+	MaxCPUPercent   int
 	MaxCallsPerMin  int
 	MaxCostPerHour  float64
 }
@@ -191,6 +250,11 @@ func (s ResourceStats) Print() {
 		fmt.Printf(" / %d MB (%.1f%%)", s.MaxMemoryMB, float64(s.MemoryUsageMB)/float64(s.MaxMemoryMB)*100)
 	}
 	fmt.Println()
+	fmt.Printf("CPU: %.1f%%", s.CPUPercent)
+	if s.MaxCPUPercent > 0 {
+		fmt.Printf(" / %d%%", s.MaxCPUPercent)
+	}
+	fmt.Println()
 	if s.TotalCost > 0 {
 		fmt.Printf("Cost: $%.4f", s.TotalCost)
 		if s.MaxCostPerHour > 0 {
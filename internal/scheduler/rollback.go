@@ -1,15 +1,35 @@
 package scheduler
 
 import (
+	"encoding/json"
 	"fmt"
+	"os"
 	"os/exec"
+	"path/filepath"
+	"strconv"
 	"strings"
+	"time"
+
+	"hermes/internal/isolation"
 )
 
+// MaxSnapshots is how many snapshots are kept in .hermes/snapshots.json
+const MaxSnapshots = 100
+
+// Snapshot is a saved repository state that 'hermes rollback --to' can return to
+type Snapshot struct {
+	ID     int       `json:"id"`
+	Name   string    `json:"name"` // Task ID the snapshot was taken before, or INITIAL
+	Commit string    `json:"commit"`
+	Branch string    `json:"branch"`
+	Time   time.Time `json:"time"`
+}
+
 // Rollback provides rollback functionality for parallel execution
 type Rollback struct {
 	workDir    string
 	snapshots  map[string]string // taskID -> commit hash before task
+	first      string            // Commit of the earliest snapshot
 	baseBranch string
 }
 
@@ -23,14 +43,121 @@ func NewRollback(workDir string) *Rollback {
 	}
 }
 
-// SaveSnapshot saves the current state before a task
+// SaveSnapshot saves the current state before a task. Snapshots are also
+// appended to .hermes/snapshots.json so they outlive the run.
 func (r *Rollback) SaveSnapshot(taskID string) error {
 	commitHash, err := getCurrentCommit(r.workDir)
 	if err != nil {
 		return fmt.Errorf("failed to get current commit: %w", err)
 	}
 	r.snapshots[taskID] = commitHash
-	return nil
+	if r.first == "" {
+		r.first = commitHash
+	}
+	return appendSnapshot(r.workDir, Snapshot{
+		Name:   taskID,
+		Commit: commitHash,
+		Branch: r.baseBranch,
+		Time:   time.Now(),
+	})
+}
+
+// GetSnapshotsPath returns the file saved snapshots are stored in
+func GetSnapshotsPath(workDir string) string {
+	return filepath.Join(workDir, ".hermes", "snapshots.json")
+}
+
+// LoadSnapshots returns the saved snapshots, oldest first
+func LoadSnapshots(workDir string) ([]Snapshot, error) {
+	data, err := os.ReadFile(GetSnapshotsPath(workDir))
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	var snapshots []Snapshot
+	if err := json.Unmarshal(data, &snapshots); err != nil {
+		return nil, err
+	}
+	return snapshots, nil
+}
+
+// FindSnapshot looks up a saved snapshot by ID, by name (the latest snapshot
+// with that name) or by commit hash prefix
+func FindSnapshot(workDir, ref string) (*Snapshot, error) {
+	snapshots, err := LoadSnapshots(workDir)
+	if err != nil {
+		return nil, err
+	}
+	id, idErr := strconv.Atoi(ref)
+	for i := len(snapshots) - 1; i >= 0; i-- {
+		s := snapshots[i]
+		if (idErr == nil && s.ID == id) || strings.EqualFold(s.Name, ref) {
+			return &s, nil
+		}
+	}
+	if len(ref) >= 4 {
+		for i := len(snapshots) - 1; i >= 0; i-- {
+			if strings.HasPrefix(snapshots[i].Commit, ref) {
+				return &snapshots[i], nil
+			}
+		}
+	}
+	return nil, fmt.Errorf("snapshot %s not found, use 'hermes snapshot list'", ref)
+}
+
+// appendSnapshot adds a snapshot to the saved history, dropping the oldest
+// entries beyond MaxSnapshots
+func appendSnapshot(workDir string, s Snapshot) error {
+	snapshots, _ := LoadSnapshots(workDir)
+	s.ID = 1
+	if len(snapshots) > 0 {
+		s.ID = snapshots[len(snapshots)-1].ID + 1
+	}
+	snapshots = append(snapshots, s)
+	if len(snapshots) > MaxSnapshots {
+		snapshots = snapshots[len(snapshots)-MaxSnapshots:]
+	}
+	return saveSnapshots(workDir, snapshots)
+}
+
+// DeleteSnapshots removes the snapshots with the given IDs from the saved
+// history and returns how many were removed. IDs of the remaining snapshots
+// are kept, so references to them stay valid.
+func DeleteSnapshots(workDir string, ids ...int) (int, error) {
+	snapshots, err := LoadSnapshots(workDir)
+	if err != nil {
+		return 0, err
+	}
+	remove := make(map[int]bool)
+	for _, id := range ids {
+		remove[id] = true
+	}
+	kept := snapshots[:0]
+	for _, s := range snapshots {
+		if !remove[s.ID] {
+			kept = append(kept, s)
+		}
+	}
+	removed := len(snapshots) - len(kept)
+	if removed == 0 {
+		return 0, nil
+	}
+	return removed, saveSnapshots(workDir, kept)
+}
+
+// saveSnapshots writes the saved snapshot history
+func saveSnapshots(workDir string, snapshots []Snapshot) error {
+	data, err := json.MarshalIndent(snapshots, "", "  ")
+	if err != nil {
+		return err
+	}
+	path := GetSnapshotsPath(workDir)
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return err
+	}
+	return os.WriteFile(path, data, 0644)
 }
 
 // RollbackTask reverts changes made by a specific task
@@ -69,19 +196,11 @@ func (r *Rollback) RollbackBatch(taskIDs []string) error {
 
 // RollbackAll reverts all changes to the initial state
 func (r *Rollback) RollbackAll() error {
-	// Get the earliest snapshot
-	var earliestCommit string
-	for _, commit := range r.snapshots {
-		if earliestCommit == "" {
-			earliestCommit = commit
-		}
-	}
-
-	if earliestCommit == "" {
+	if r.first == "" {
 		return fmt.Errorf("no snapshots available")
 	}
 
-	return runGitCommand(r.workDir, "reset", "--hard", earliestCommit)
+	return runGitCommand(r.workDir, "reset", "--hard", r.first)
 }
 
 // CleanupTaskBranches removes all task branches
@@ -104,8 +223,12 @@ func (r *Rollback) CleanupTaskBranches() error {
 	return nil
 }
 
-// CleanupWorktrees removes all hermes worktrees
+// CleanupWorktrees removes all hermes worktrees and workspace copies
 func (r *Rollback) CleanupWorktrees() error {
+	if err := isolation.CleanupCopies(r.workDir); err != nil {
+		return err
+	}
+
 	// List worktrees
 	output, err := runGitCommandOutput(r.workDir, "worktree", "list", "--porcelain")
 	if err != nil {
@@ -2,18 +2,23 @@ package scheduler
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"os/exec"
+	"sort"
 	"strings"
 	"sync"
 	"time"
 
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
 	"hermes/internal/ai"
 	"hermes/internal/circuit"
 	"hermes/internal/config"
 	"hermes/internal/git"
 	"hermes/internal/isolation"
 	"hermes/internal/task"
+	"hermes/internal/tracing"
 	"hermes/internal/ui"
 )
 
@@ -21,15 +26,31 @@ import (
 type Scheduler struct {
 	config           *config.ParallelConfig
 	provider         ai.Provider
+	fallbacks        []ai.Provider
 	workDir          string
 	logger           *ui.Logger
 	parallelLogger   *ParallelLogger
 	breaker          *circuit.Breaker
 	mu               sync.Mutex
 	progressCallback ProgressCallback
+	batchCallback    BatchCallback
 	currentBatch     int
 	totalBatches     int
 	taskTimeout      time.Duration
+	effortTimeouts   map[string]int
+	analyzerConfig   *config.AnalyzerConfig
+	taskModeConfig   *config.TaskModeConfig
+	projectContext   *config.ProjectContextConfig
+	memory           bool
+	gitHistory       int
+	release          *git.Release
+	policy           git.CommitPolicy
+	signing          git.Signing
+	commitFormat     git.CommitFormat
+	resources        *ResourceMonitor
+	rollback         *Rollback
+	drain            <-chan struct{}
+	maxWorkers       int // Replaces config.MaxWorkers when set, see SetMaxWorkers
 }
 
 // ExecutionPlan represents the planned execution order
@@ -76,14 +97,143 @@ func (s *Scheduler) SetParallelLogger(logger *ParallelLogger) {
 	s.parallelLogger = logger
 }
 
+// SetFallbackProviders sets the providers a task is retried on, in order, when
+// its provider times out or is rate limited. These retries don't count against
+// the configured maximum.
+func (s *Scheduler) SetFallbackProviders(providers []ai.Provider) {
+	s.fallbacks = providers
+}
+
+// SetDrain sets a channel whose closing drains the execution: no further task
+// starts and Execute returns context.Canceled once the running tasks finished
+func (s *Scheduler) SetDrain(drain <-chan struct{}) {
+	s.drain = drain
+}
+
+// SetRollback sets the rollback manager that snapshots the project before
+// each task branch is merged, for 'hermes rollback --task'
+func (s *Scheduler) SetRollback(rollback *Rollback) {
+	s.rollback = rollback
+}
+
+// SetEffortTimeouts sets the task timeouts in seconds by estimated effort,
+// used for tasks without a timeout of their own
+func (s *Scheduler) SetEffortTimeouts(timeouts map[string]int) {
+	s.effortTimeouts = timeouts
+}
+
+// SetAnalyzerConfig sets the keyword sets used to analyze worker responses
+func (s *Scheduler) SetAnalyzerConfig(cfg *config.AnalyzerConfig) {
+	s.analyzerConfig = cfg
+}
+
+// SetBreakerCooldown sets how long an open circuit breaker waits before
+// probing the next batch
+func (s *Scheduler) SetBreakerCooldown(cooldown time.Duration) {
+	s.breaker.SetCooldown(cooldown)
+}
+
+// SetResourceMonitor sets the monitor whose memory, CPU and API limits hold
+// back the start of further tasks
+func (s *Scheduler) SetResourceMonitor(m *ResourceMonitor) {
+	s.resources = m
+}
+
+// SetTaskModeConfig sets the test command and verification settings applied
+// when a worker reports a task complete
+func (s *Scheduler) SetTaskModeConfig(cfg *config.TaskModeConfig) {
+	s.taskModeConfig = cfg
+}
+
+// SetProjectContext sets how the overview of the project prepended to task
+// prompts is generated
+func (s *Scheduler) SetProjectContext(cfg *config.ProjectContextConfig) {
+	s.projectContext = cfg
+}
+
+// SetMemory sets whether tasks share learnings in .hermes/MEMORY.md
+func (s *Scheduler) SetMemory(enabled bool) {
+	s.memory = enabled
+}
+
+// SetGitHistory sets the number of recent task commits added to task prompts
+func (s *Scheduler) SetGitHistory(count int) {
+	s.gitHistory = count
+}
+
+// SetRelease sets the release notes of the tags created for completed
+// features, nil for their plain message
+func (s *Scheduler) SetRelease(release *git.Release) {
+	s.release = release
+}
+
+// SetCommitPolicy sets the policy task branches are merged under, so a
+// protected base branch is refused like the commits of a sequential run
+func (s *Scheduler) SetCommitPolicy(policy git.CommitPolicy) {
+	s.policy = policy
+}
+
+// SetSigning sets how the task commits and merges of isolated workspaces
+// are signed
+func (s *Scheduler) SetSigning(signing git.Signing) {
+	s.signing = signing
+}
+
+// SetCommitFormat sets the message format of the task commits made in
+// isolated workspaces
+func (s *Scheduler) SetCommitFormat(format git.CommitFormat) {
+	s.commitFormat = format
+}
+
+// SetMaxWorkers changes the maximum number of workers, also of an execution
+// in progress: no further task starts beyond the new maximum, and surplus
+// workers stop as their tasks finish
+func (s *Scheduler) SetMaxWorkers(n int) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.maxWorkers = n
+}
+
+// workerLimit returns the maximum number of workers for totalTasks tasks
+func (s *Scheduler) workerLimit(totalTasks int) int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	limit := s.config.MaxWorkers
+	if s.maxWorkers > 0 {
+		limit = s.maxWorkers
+	}
+	return max(1, min(limit, totalTasks))
+}
+
 // SetProgressCallback sets the callback for progress updates
 func (s *Scheduler) SetProgressCallback(callback ProgressCallback) {
 	s.progressCallback = callback
 }
 
+// BatchCallback is called when execution starts and after each finished task
+// with the number of the first unfinished batch and the unfinished tasks of
+// each remaining batch
+type BatchCallback func(batchNum int, remaining [][]*task.Task)
+
+// SetBatchCallback sets the callback used to checkpoint the batch queue
+func (s *Scheduler) SetBatchCallback(callback BatchCallback) {
+	s.batchCallback = callback
+}
+
+// buildGraph builds the task graph, adding the implicit dependencies enabled in the config
+func (s *Scheduler) buildGraph(tasks []*task.Task) (*TaskGraph, error) {
+	if s.config.ImplicitDocDependencies {
+		addImplicitDocDependencies(tasks)
+	}
+	if s.config.ImplicitFileDependencies {
+		addImplicitFileDependencies(tasks)
+	}
+	return NewTaskGraph(tasks)
+}
+
 // GetExecutionPlan returns the planned execution order without executing
 func (s *Scheduler) GetExecutionPlan(tasks []*task.Task) (*ExecutionPlan, error) {
-	graph, err := NewTaskGraphWithOptions(tasks, s.config.ImplicitDocDependencies)
+	graph, err := s.buildGraph(tasks)
 	if err != nil {
 		return nil, fmt.Errorf("failed to build task graph: %w", err)
 	}
@@ -101,18 +251,8 @@ func (s *Scheduler) GetExecutionPlan(tasks []*task.Task) (*ExecutionPlan, error)
 
 // Execute runs all tasks respecting dependencies
 func (s *Scheduler) Execute(ctx context.Context, tasks []*task.Task) (*ExecutionResult, error) {
-	startTime := time.Now()
-	
-	result := &ExecutionResult{
-		Results:   make([]*TaskResult, 0),
-		StartTime: startTime,
-	}
-
-	// Cleanup worktrees on exit (cancelled or completed)
-	defer s.cleanupWorktrees()
-
 	// Build task graph with implicit doc dependencies if enabled
-	graph, err := NewTaskGraphWithOptions(tasks, s.config.ImplicitDocDependencies)
+	graph, err := s.buildGraph(tasks)
 	if err != nil {
 		return nil, fmt.Errorf("failed to build task graph: %w", err)
 	}
@@ -123,7 +263,81 @@ func (s *Scheduler) Execute(ctx context.Context, tasks []*task.Task) (*Execution
 		return nil, fmt.Errorf("failed to compute execution batches: %w", err)
 	}
 
-	s.logInfo("Execution plan: %d batches, %d total tasks", len(batches), len(tasks))
+	return s.executeBatches(ctx, graph, batches, false)
+}
+
+// Resume runs the remaining batch queue of an interrupted execution, given as
+// task IDs. Tasks completed since are skipped and tasks added since are not run.
+// Tasks that were running continue in the worktrees they were interrupted in.
+func (s *Scheduler) Resume(ctx context.Context, tasks []*task.Task, queue [][]string) (*ExecutionResult, error) {
+	graph, err := s.buildGraph(tasks)
+	if err != nil {
+		return nil, fmt.Errorf("failed to build task graph: %w", err)
+	}
+
+	var batches [][]*task.Task
+	for _, ids := range queue {
+		var batch []*task.Task
+		for _, id := range ids {
+			if node, ok := graph.GetNode(id); ok && node.Status != NodeCompleted {
+				batch = append(batch, node.Task)
+			}
+		}
+		if len(batch) > 0 {
+			batches = append(batches, batch)
+		}
+	}
+
+	return s.executeBatches(ctx, graph, batches, true)
+}
+
+// executeBatches runs the tasks of batches as soon as their dependencies are
+// complete and a worker is free, rather than waiting for whole batches. It
+// stops starting tasks on cancellation, an open circuit breaker or a failed
+// task with the fail-fast strategy. Batches remain the unit of progress
+// reporting, checkpoints and the circuit breaker, which records a batch once
+// all its tasks have finished. When resumed is set, tasks reattach to the
+// worktrees of the interrupted execution, which keeps its worktrees in turn.
+func (s *Scheduler) executeBatches(ctx context.Context, graph *TaskGraph, batches [][]*task.Task, resumed bool) (*ExecutionResult, error) {
+	startTime := time.Now()
+
+	result := &ExecutionResult{
+		Results:   make([]*TaskResult, 0),
+		StartTime: startTime,
+	}
+	finish := func(err error) (*ExecutionResult, error) {
+		result.EndTime = time.Now()
+		result.TotalTime = result.EndTime.Sub(startTime)
+		s.countResults(result)
+		return result, err
+	}
+
+	// Refuse the run when the policy forbids merging task branches into the
+	// base, instead of leaving the work of every task on its branch
+	if s.config.IsolatedWorkspaces && isolation.ResolveBackend(s.config.IsolationBackend, s.workDir) != isolation.BackendCopy {
+		gitOps := git.New(s.workDir)
+		gitOps.SetCommitPolicy(s.policy)
+		if err := gitOps.CheckBranchPolicy(); err != nil {
+			s.logError("Cannot merge task branches: %v", err)
+			return finish(fmt.Errorf("cannot merge task branches: %w", err))
+		}
+	}
+
+	// Cleanup worktrees on exit, unless the execution was interrupted and the
+	// checkpoint still refers to them
+	defer func() {
+		if ctx.Err() == nil && !IsClosed(s.drain) {
+			s.cleanupWorktrees()
+		} else {
+			s.logInfo("Keeping worktrees of interrupted tasks for 'hermes resume'")
+		}
+	}()
+
+	totalTasks := 0
+	for _, batch := range batches {
+		totalTasks += len(batch)
+	}
+	s.logInfo("Execution plan: %d batches, %d total tasks", len(batches), totalTasks)
 	for i, batch := range batches {
 		taskIDs := make([]string, len(batch))
 		for j, t := range batch {
@@ -131,204 +345,462 @@ func (s *Scheduler) Execute(ctx context.Context, tasks []*task.Task) (*Execution
 		}
 		s.logInfo("  Batch %d: %v", i+1, taskIDs)
 	}
+	if totalTasks == 0 {
+		return finish(nil)
+	}
 
 	// Set total batches for progress tracking
 	s.totalBatches = len(batches)
 
-	// Execute each batch
-	for batchNum, batch := range batches {
-		// Update current batch for progress tracking
-		s.currentBatch = batchNum + 1
+	// The pool starts with the minimum number of workers and grows with the
+	// tasks ready to run, up to the maximum. The pool can hold a worker per
+	// task so a maximum raised by SetMaxWorkers takes effect.
+	run := newDispatchState(batches)
+	maxWorkers := s.workerLimit(totalTasks)
+	minWorkers := max(1, min(s.config.MinWorkers, maxWorkers))
 
-		select {
-		case <-ctx.Done():
-			s.logInfo("Execution cancelled, cleaning up...")
-			result.EndTime = time.Now()
-			result.TotalTime = result.EndTime.Sub(startTime)
-			return result, ctx.Err()
-		default:
+	var progress ProgressCallback
+	if s.progressCallback != nil {
+		progress = func(event ProgressEvent) {
+			event.Batch = run.batchOf[event.TaskID] + 1
+			event.TotalBatch = len(batches)
+			s.progressCallback(event)
 		}
+	}
+	pool := NewWorkerPoolWithConfig(ctx, s.provider, s.workDir, WorkerPoolConfig{
+		Workers:            minWorkers,
+		MaxWorkers:         totalTasks,
+		UseIsolation:       s.config.IsolatedWorkspaces,
+		Logger:             s.parallelLogger,
+		StreamOutput:       false, // Parallel mode should not stream to avoid mixed output
+		MaxRetries:         s.config.MaxRetries,
+		TaskTimeout:        s.taskTimeout,
+		EffortTimeouts:     s.effortTimeouts,
+		ProgressCallback:   progress,
+		TotalBatches:       len(batches),
+		AnalyzerConfig:     s.analyzerConfig,
+		TaskModeConfig:     s.taskModeConfig,
+		ProjectContext:     s.projectContext,
+		Memory:             s.memory,
+		GitHistory:         s.gitHistory,
+		FallbackProviders:  s.fallbacks,
+		ReattachWorkspaces: resumed,
+		IsolationBackend:   s.config.IsolationBackend,
+		PoolWorktrees:      s.config.PoolWorktrees,
+		Signing:            s.signing,
+		CommitFormat:       s.commitFormat,
+	})
+	pool.Start()
+	defer pool.Stop()
 
-		// Check circuit breaker before batch execution
-		canExecute, err := s.breaker.CanExecute()
-		if err != nil {
-			s.logError("Circuit breaker error: %v", err)
+	completedFeatures := make(map[string]bool)
+	var haltErr error
+	draining := false
+	s.checkpoint(run)
+
+	for {
+		if !draining && IsClosed(s.drain) {
+			s.logInfo("Draining: waiting for %d running tasks, starting no new ones", run.running)
+			draining = true
 		}
-		if !canExecute {
-			s.logError("Circuit breaker OPEN - stopping execution")
-			result.EndTime = time.Now()
-			result.TotalTime = result.EndTime.Sub(startTime)
-			s.countResults(result)
-			return result, fmt.Errorf("circuit breaker open: execution halted due to no progress")
+		var throttle <-chan time.Time
+		var ready []*task.Task
+		if haltErr == nil && !draining {
+			ready = run.ready(graph)
+			if len(ready) > 0 {
+				canExecute, err := s.breakerAllows(ctx, run.running == 0)
+				if err != nil {
+					s.logInfo("Execution cancelled, cleaning up...")
+					return finish(err)
+				}
+				if !canExecute && run.running == 0 {
+					s.logError("Circuit breaker OPEN - stopping execution")
+					return finish(fmt.Errorf("circuit breaker open: execution halted due to no progress"))
+				}
+				if !canExecute {
+					ready = nil // Let the running tasks finish first
+				}
+			}
+			// Hold back further tasks while the resource limits are exceeded
+			if len(ready) > 0 && run.running > 0 && s.resources != nil && !s.resources.CanStartWorker() {
+				if !run.throttled {
+					stats := s.resources.GetStats()
+					s.logInfo("Waiting for resources before starting %s (memory %d MB, CPU %.0f%%)", ready[0].ID, stats.MemoryUsageMB, stats.CPUPercent)
+					run.throttled = true
+				}
+				throttle = time.After(time.Second)
+				ready = nil
+			} else {
+				run.throttled = false
+			}
 		}
 
-		s.logInfo("Starting batch %d/%d with %d tasks", batchNum+1, len(batches), len(batch))
-		if s.parallelLogger != nil {
-			s.parallelLogger.BatchStart(batchNum+1, len(batches), len(batch))
+		// Scale the pool to the running and ready tasks, retiring idle workers
+		if limit := s.workerLimit(totalTasks); limit != maxWorkers {
+			s.logInfo("Maximum workers changed from %d to %d", maxWorkers, limit)
+			maxWorkers = limit
 		}
-		batchStartTime := time.Now()
-
-		batchResults, err := s.executeBatch(ctx, graph, batch)
-		
-		// Calculate batch progress for circuit breaker
-		batchHasProgress := false
-		for _, r := range batchResults {
-			if r.Success {
-				batchHasProgress = true
+		workers := max(min(minWorkers, maxWorkers), min(run.running+len(ready), maxWorkers))
+		if current := pool.WorkerCount(); workers != current {
+			s.logInfo("Scaling workers from %d to %d (%d running, %d ready)", current, workers, run.running, len(ready))
+			pool.Resize(workers)
+		}
+		for _, t := range ready {
+			if run.running >= workers {
 				break
 			}
-		}
-		
-		// Report to circuit breaker
-		s.breaker.AddLoopResult(batchHasProgress, err != nil, batchNum+1)
-		
-		if err != nil {
-			s.logError("Batch %d failed: %v", batchNum+1, err)
-			
-			// Handle based on failure strategy
-			switch s.config.FailureStrategy {
-			case "fail-fast":
-				result.EndTime = time.Now()
-				result.TotalTime = result.EndTime.Sub(startTime)
-				result.Results = append(result.Results, batchResults...)
-				s.countResults(result)
-				return result, fmt.Errorf("batch %d failed: %w", batchNum+1, err)
-			case "continue":
-				// Continue with next batch
-				result.Results = append(result.Results, batchResults...)
-				continue
+			if err := s.startTask(ctx, pool, graph, run, t); err != nil {
+				return finish(err)
 			}
 		}
+		if run.running == 0 {
+			break
+		}
 
-		result.Results = append(result.Results, batchResults...)
-		s.logInfo("Batch %d completed", batchNum+1)
-		if s.parallelLogger != nil {
-			s.parallelLogger.BatchComplete(batchNum+1, time.Since(batchStartTime))
+		select {
+		case <-ctx.Done():
+			s.logInfo("Execution cancelled, cleaning up...")
+			return finish(ctx.Err())
+		case <-throttle:
+		case r := <-pool.Results():
+			run.running--
+			result.Results = append(result.Results, r)
+			if err := s.finishTask(pool, graph, run, r, completedFeatures); err != nil && haltErr == nil && s.config.FailureStrategy == "fail-fast" {
+				s.logError("Stopping execution after failed task %s", r.TaskID)
+				haltErr = err
+			}
+			s.checkpoint(run)
 		}
 	}
 
-	result.EndTime = time.Now()
-	result.TotalTime = result.EndTime.Sub(startTime)
-	s.countResults(result)
+	if haltErr != nil {
+		return finish(haltErr)
+	}
+	if draining {
+		s.logInfo("Drained: %d tasks left for the next run", len(run.queued))
+		return finish(context.Canceled)
+	}
 
-	return result, nil
+	// Tasks left over depend on a failed task or one outside this execution
+	for _, t := range run.waiting() {
+		s.logError("[SKIPPED] %s %s: dependencies not completed", t.ID, t.Name)
+	}
+
+	return finish(nil)
 }
 
-// executeBatch executes a single batch of tasks in parallel
-func (s *Scheduler) executeBatch(ctx context.Context, graph *TaskGraph, batch []*task.Task) ([]*TaskResult, error) {
-	workers := s.config.MaxWorkers
-	if workers > len(batch) {
-		workers = len(batch)
+// IsClosed reports whether ch is closed; a nil channel never is
+func IsClosed(ch <-chan struct{}) bool {
+	select {
+	case <-ch:
+		return true
+	default:
+		return false
 	}
+}
 
-	pool := NewWorkerPoolWithConfig(ctx, s.provider, s.workDir, WorkerPoolConfig{
-		Workers:          workers,
-		UseIsolation:     s.config.IsolatedWorkspaces,
-		Logger:           s.parallelLogger,
-		StreamOutput:     false, // Parallel mode should not stream to avoid mixed output
-		MaxRetries:       s.config.MaxRetries,
-		TaskTimeout:      s.taskTimeout,
-		ProgressCallback: s.progressCallback,
-		CurrentBatch:     s.currentBatch,
-		TotalBatches:     s.totalBatches,
-	})
-	pool.Start()
+// dispatchState tracks the tasks of an execution between their batches,
+// the task graph and the worker pool
+type dispatchState struct {
+	batches   [][]*task.Task
+	batchOf   map[string]int  // Task ID -> index of its batch
+	queued    map[string]bool // Tasks not started yet
+	done      map[string]bool // Tasks finished, successfully or not
+	left      []int           // Unfinished tasks per batch
+	started   []time.Time     // Start of the first task per batch
+	progress  []bool          // Whether a task of the batch succeeded
+	failed    []bool          // Whether a task of the batch failed
+	spans     []trace.Span
+	running   int
+	throttled bool
+}
 
-	// Mark tasks as running and submit to pool
-	for _, t := range batch {
-		if err := graph.MarkRunning(t.ID); err != nil {
-			s.logError("Failed to mark task %s as running: %v", t.ID, err)
+func newDispatchState(batches [][]*task.Task) *dispatchState {
+	run := &dispatchState{
+		batches:  batches,
+		batchOf:  make(map[string]int),
+		queued:   make(map[string]bool),
+		done:     make(map[string]bool),
+		left:     make([]int, len(batches)),
+		started:  make([]time.Time, len(batches)),
+		progress: make([]bool, len(batches)),
+		failed:   make([]bool, len(batches)),
+		spans:    make([]trace.Span, len(batches)),
+	}
+	for i, batch := range batches {
+		for _, t := range batch {
+			run.batchOf[t.ID] = i
+			run.queued[t.ID] = true
+			run.left[i]++
 		}
-		if err := pool.Submit(t); err != nil {
-			return nil, fmt.Errorf("failed to submit task %s: %w", t.ID, err)
+	}
+	return run
+}
+
+// ready returns the queued tasks whose dependencies are complete, higher
+// priorities first so that critical work gets the free workers, then earlier
+// batches
+func (run *dispatchState) ready(graph *TaskGraph) []*task.Task {
+	var ready []*task.Task
+	for _, t := range graph.GetReadyTasks() {
+		if run.queued[t.ID] {
+			ready = append(ready, t)
 		}
 	}
+	sort.Slice(ready, func(i, j int) bool {
+		pi, pj := PriorityOrder[ready[i].Priority], PriorityOrder[ready[j].Priority]
+		if pi != pj {
+			return pi < pj
+		}
+		bi, bj := run.batchOf[ready[i].ID], run.batchOf[ready[j].ID]
+		if bi != bj {
+			return bi < bj
+		}
+		return ready[i].ID < ready[j].ID
+	})
+	return ready
+}
 
-	// Collect results
-	results := pool.WaitForBatch(len(batch))
+// waiting returns the tasks that were never started
+func (run *dispatchState) waiting() []*task.Task {
+	var tasks []*task.Task
+	for _, batch := range run.batches {
+		for _, t := range batch {
+			if run.queued[t.ID] {
+				tasks = append(tasks, t)
+			}
+		}
+	}
+	return tasks
+}
 
-	// Update graph based on results
-	var batchErr error
-	var successfulTasks []string
-	for _, result := range results {
-		if result.Success {
-			if err := graph.MarkComplete(result.TaskID); err != nil {
-				s.logError("Failed to mark task %s as complete: %v", result.TaskID, err)
+// remaining returns the number of the first unfinished batch and the
+// unfinished tasks of each batch, running ones included so that a resumed
+// execution runs them again
+func (run *dispatchState) remaining() (int, [][]*task.Task) {
+	first := 0
+	var remaining [][]*task.Task
+	for i, batch := range run.batches {
+		var tasks []*task.Task
+		for _, t := range batch {
+			if !run.done[t.ID] {
+				tasks = append(tasks, t)
 			}
-			s.logInfo("[COMPLETED] %s %s (%.0fs)", result.TaskID, result.TaskName, result.Duration.Seconds())
-			successfulTasks = append(successfulTasks, result.TaskID)
+		}
+		if len(tasks) == 0 {
+			continue
+		}
+		if first == 0 {
+			first = i + 1
+		}
+		remaining = append(remaining, tasks)
+	}
+	return first, remaining
+}
+
+// checkpoint updates the current batch and passes the unfinished batches to
+// the batch callback
+func (s *Scheduler) checkpoint(run *dispatchState) {
+	first, remaining := run.remaining()
+	if first == 0 {
+		return
+	}
+	s.currentBatch = first
+	if s.batchCallback != nil {
+		s.batchCallback(first, remaining)
+	}
+}
+
+// breakerAllows reports whether the circuit breaker lets another task start.
+// With wait, an open breaker that recovers after a cooldown is waited for.
+func (s *Scheduler) breakerAllows(ctx context.Context, wait bool) (bool, error) {
+	canExecute, err := s.breaker.CanExecute()
+	if err != nil {
+		s.logError("Circuit breaker error: %v", err)
+	}
+	if at, ok := s.breaker.RecoveryAt(); !canExecute && ok && wait {
+		s.logInfo("Circuit breaker OPEN - probing again at %s", at.Format("15:04:05"))
+		select {
+		case <-ctx.Done():
+			return false, ctx.Err()
+		case <-time.After(time.Until(at)):
+		}
+		canExecute, _ = s.breaker.CanExecute()
+	}
+	return canExecute, nil
+}
+
+// startTask marks a ready task as running and submits it to the pool
+func (s *Scheduler) startTask(ctx context.Context, pool *WorkerPool, graph *TaskGraph, run *dispatchState, t *task.Task) error {
+	batch := run.batchOf[t.ID]
+	if run.started[batch].IsZero() {
+		run.started[batch] = time.Now()
+		_, run.spans[batch] = tracing.Start(ctx, "batch",
+			attribute.Int("batch", batch+1),
+			attribute.Int("batch.tasks", len(run.batches[batch])))
+		s.logInfo("Starting batch %d/%d with %d tasks", batch+1, len(run.batches), len(run.batches[batch]))
+		if s.parallelLogger != nil {
+			s.parallelLogger.BatchStart(batch+1, len(run.batches), len(run.batches[batch]))
+		}
+	}
+
+	if err := graph.MarkRunning(t.ID); err != nil {
+		s.logError("Failed to mark task %s as running: %v", t.ID, err)
+	}
+	if err := pool.Submit(t); err != nil {
+		return fmt.Errorf("failed to submit task %s: %w", t.ID, err)
+	}
+	delete(run.queued, t.ID)
+	run.running++
+	return nil
+}
+
+// finishTask records the result of a task in the graph, merges its branch
+// and, once the last task of its batch has finished, reports the batch to the
+// circuit breaker. It returns an error for a failed task, including one whose
+// branch could not be merged.
+func (s *Scheduler) finishTask(pool *WorkerPool, graph *TaskGraph, run *dispatchState, r *TaskResult, completedFeatures map[string]bool) error {
+	batch := run.batchOf[r.TaskID]
+	run.done[r.TaskID] = true
+	run.left[batch]--
+
+	// Merge before marking the task complete so dependents start from its
+	// changes; a task whose changes did not reach the base fails
+	if node, ok := graph.GetNode(r.TaskID); ok && r.Success && s.config.IsolatedWorkspaces {
+		if err := s.mergeTask(pool, node.Task, completedFeatures); err != nil {
+			r.Success = false
+			r.Error = fmt.Errorf("merge failed: %w", err)
+		}
+	}
+
+	var taskErr error
+	if r.Success {
+		if err := graph.MarkComplete(r.TaskID); err != nil {
+			s.logError("Failed to mark task %s as complete: %v", r.TaskID, err)
+		}
+		s.logInfo("[COMPLETED] %s %s (%.0fs)", r.TaskID, r.TaskName, r.Duration.Seconds())
+		run.progress[batch] = true
+	} else {
+		if err := graph.MarkFailed(r.TaskID); err != nil {
+			s.logError("Failed to mark task %s as failed: %v", r.TaskID, err)
+		}
+		s.logError("[FAILED] %s %s: %v", r.TaskID, r.TaskName, r.Error)
+		run.failed[batch] = true
+		taskErr = fmt.Errorf("task %s failed: %w", r.TaskID, r.Error)
+	}
+
+	if run.left[batch] == 0 {
+		var batchErr error
+		if run.failed[batch] {
+			batchErr = fmt.Errorf("batch %d had failed tasks", batch+1)
+		}
+		tracing.End(run.spans[batch], batchErr)
+		s.breaker.AddLoopResult(run.progress[batch], run.failed[batch], batch+1)
+		if batchErr != nil {
+			s.logError("Batch %d failed: %v", batch+1, batchErr)
 		} else {
-			if err := graph.MarkFailed(result.TaskID); err != nil {
-				s.logError("Failed to mark task %s as failed: %v", result.TaskID, err)
+			s.logInfo("Batch %d completed", batch+1)
+			if s.parallelLogger != nil {
+				s.parallelLogger.BatchComplete(batch+1, time.Since(run.started[batch]))
 			}
-			s.logError("[FAILED] %s %s: %v", result.TaskID, result.TaskName, result.Error)
-			batchErr = fmt.Errorf("task %s failed: %w", result.TaskID, result.Error)
 		}
 	}
+	return taskErr
+}
+
+// mergeTask merges the branch of a successful task in an isolated workspace,
+// marks the task COMPLETED, tags features completed by it and removes the
+// workspace. It returns the error of a merge that failed, whose changes are
+// left on the task branch.
+func (s *Scheduler) mergeTask(pool *WorkerPool, t *task.Task, completedFeatures map[string]bool) error {
+	taskID := t.ID
+	workspace := pool.GetWorkspace(taskID)
+	if workspace == nil || !workspace.IsIsolated() {
+		return nil
+	}
 
-	// Merge and cleanup workspaces for isolated execution
-	if s.config.IsolatedWorkspaces && len(successfulTasks) > 0 {
-		s.logInfo("Merging %d successful task branches...", len(successfulTasks))
+	if s.rollback != nil && workspace.Backend != isolation.BackendCopy {
+		if err := s.rollback.SaveSnapshot(taskID); err != nil {
+			s.logError("Failed to save snapshot before task %s: %v", taskID, err)
+		}
+	}
+	mergeErr := s.mergeWorkspace(workspace)
+	if mergeErr != nil {
+		s.logError("Failed to merge workspace for task %s: %v", taskID, mergeErr)
+	} else {
+		// Update task status to COMPLETED in main project after successful merge
 		statusUpdater := task.NewStatusUpdater(s.workDir)
+		if err := statusUpdater.UpdateTaskStatus(taskID, task.StatusCompleted); err != nil {
+			s.logError("Failed to update task %s status to COMPLETED: %v", taskID, err)
+		} else {
+			s.logInfo("Task %s marked as COMPLETED", taskID)
+		}
+
+		// Check if the feature is complete and create its tag right after the merge
 		reader := task.NewReader(s.workDir)
 		gitOps := git.New(s.workDir)
-		completedFeatures := make(map[string]bool)
-		
-		for _, taskID := range successfulTasks {
-			workspace := pool.GetWorkspace(taskID)
-			if workspace != nil && workspace.IsIsolated() {
-				// Merge branch to main
-				if err := s.mergeBranch(workspace); err != nil {
-					s.logError("Failed to merge branch for task %s: %v", taskID, err)
-				} else {
-					s.logInfo("Merged branch %s for task %s", workspace.GetBranch(), taskID)
-					// Update task status to COMPLETED in main project after successful merge
-					if err := statusUpdater.UpdateTaskStatus(taskID, task.StatusCompleted); err != nil {
-						s.logError("Failed to update task %s status to COMPLETED: %v", taskID, err)
+		completedFeatures[t.FeatureID] = true
+		for featureID := range completedFeatures {
+			if featureComplete, _ := reader.IsFeatureComplete(featureID); featureComplete {
+				feature, _ := reader.GetFeatureByID(featureID)
+				if feature != nil && feature.TargetVersion != "" && gitOps.IsRepository() {
+					if err := gitOps.CreateFeatureRelease(feature, s.release); err != nil {
+						s.logError("Failed to create tag %s: %v", feature.TargetVersion, err)
 					} else {
-						s.logInfo("Task %s marked as COMPLETED", taskID)
-					}
-					
-					// Track feature for tag creation
-					for _, t := range batch {
-						if t.ID == taskID {
-							completedFeatures[t.FeatureID] = true
-							break
-						}
+						s.logInfo("Created tag: %s for feature %s", feature.TargetVersion, feature.ID)
 					}
-					
-					// Check if feature is complete and create tag immediately after merge
-					for featureID := range completedFeatures {
-						if featureComplete, _ := reader.IsFeatureComplete(featureID); featureComplete {
-							feature, _ := reader.GetFeatureByID(featureID)
-							if feature != nil && feature.TargetVersion != "" && gitOps.IsRepository() {
-								if err := gitOps.CreateFeatureTag(feature.ID, feature.Name, feature.TargetVersion); err != nil {
-									s.logError("Failed to create tag %s: %v", feature.TargetVersion, err)
-								} else {
-									s.logInfo("Created tag: %s for feature %s", feature.TargetVersion, feature.ID)
-								}
-								// Remove from map to avoid duplicate tag attempts
-								delete(completedFeatures, featureID)
-							}
-						}
-					}
-				}
-				// Cleanup worktree
-				if err := workspace.Cleanup(); err != nil {
-					s.logError("Failed to cleanup workspace for task %s: %v", taskID, err)
+					// Remove from map to avoid duplicate tag attempts
+					delete(completedFeatures, featureID)
 				}
 			}
 		}
 	}
 
-	// Stop the pool
-	pool.Stop()
+	// Cleanup worktree
+	if err := pool.ReleaseWorkspace(workspace); err != nil {
+		s.logError("Failed to cleanup workspace for task %s: %v", taskID, err)
+	}
+	return mergeErr
+}
+
+// mergeWorkspace brings the changes of an isolated workspace into the
+// project, merging the branch of a worktree or applying the files of a copy
+func (s *Scheduler) mergeWorkspace(workspace *isolation.Workspace) error {
+	if workspace.Backend != isolation.BackendCopy {
+		return s.mergeBranch(workspace)
+	}
 
-	return results, batchErr
+	files, err := workspace.ApplyChanges()
+	if err != nil {
+		var conflict *isolation.ConflictError
+		if errors.As(err, &conflict) && s.parallelLogger != nil {
+			for _, file := range conflict.Files {
+				s.parallelLogger.ConflictDetected(file, []string{workspace.TaskID}, "copy")
+			}
+		}
+		if s.parallelLogger != nil {
+			s.parallelLogger.Merge("Applying changes of task %s failed: %v", workspace.TaskID, err)
+		}
+		return fmt.Errorf("failed to apply changes: %w", err)
+	}
+	s.logInfo("Applied %d changed files of task %s", len(files), workspace.TaskID)
+	if s.parallelLogger != nil {
+		s.parallelLogger.Merge("Applied %d changed files of task %s: %v", len(files), workspace.TaskID, files)
+	}
+	return nil
 }
 
 // mergeBranch merges a workspace branch back to the base branch
 func (s *Scheduler) mergeBranch(workspace *isolation.Workspace) error {
+	gitOps := git.New(s.workDir)
+	gitOps.SetCommitPolicy(s.policy)
+	if err := gitOps.CheckBranchPolicy(); err != nil {
+		if s.parallelLogger != nil {
+			s.parallelLogger.Merge("Not merging task %s: %v", workspace.TaskID, err)
+		}
+		return err
+	}
+
 	// Get current branch (should be base branch)
 	cmd := exec.Command("git", "rev-parse", "--abbrev-ref", "HEAD")
 	cmd.Dir = s.workDir
@@ -343,10 +815,25 @@ func (s *Scheduler) mergeBranch(workspace *isolation.Workspace) error {
 		s.parallelLogger.Merge("Starting merge of branch %s (task %s) into %s", workspace.GetBranch(), workspace.TaskID, baseBranch)
 	}
 
+	if s.config.MergeStrategy == "rebase" {
+		if err := s.rebaseBranch(workspace, baseBranch); err != nil {
+			if s.parallelLogger != nil {
+				s.parallelLogger.Merge("Rebase of %s failed: %v", workspace.TaskID, err)
+			}
+			return err
+		}
+		s.logInfo("Rebased %s and fast-forwarded %s", workspace.GetBranch(), baseBranch)
+		if s.parallelLogger != nil {
+			s.parallelLogger.Merge("Rebased %s and fast-forwarded %s", workspace.GetBranch(), baseBranch)
+		}
+		return nil
+	}
+
 	// Merge the task branch
-	cmd = exec.Command("git", "merge", workspace.GetBranch(), "--no-edit", "-m", 
-		fmt.Sprintf("Merge branch '%s' (task %s)", workspace.GetBranch(), workspace.TaskID))
+	cmd = exec.Command("git", append([]string{"merge", workspace.GetBranch(), "--no-edit", "-m",
+		fmt.Sprintf("Merge branch '%s' (task %s)", workspace.GetBranch(), workspace.TaskID)}, s.signing.Args()...)...)
 	cmd.Dir = s.workDir
+	s.signing.Apply(cmd)
 	if output, err := cmd.CombinedOutput(); err != nil {
 		// Check if it's a merge conflict
 		if strings.Contains(string(output), "CONFLICT") {
@@ -355,11 +842,18 @@ func (s *Scheduler) mergeBranch(workspace *isolation.Workspace) error {
 				s.parallelLogger.ConflictDetected(workspace.GetBranch(), []string{workspace.TaskID}, "git-merge")
 			}
 			// Try to abort and use theirs strategy
-			exec.Command("git", "merge", "--abort").Run()
-			cmd = exec.Command("git", "merge", workspace.GetBranch(), "--no-edit", "-X", "theirs", "-m",
-				fmt.Sprintf("Merge branch '%s' (task %s) with auto-resolution", workspace.GetBranch(), workspace.TaskID))
+			abort := exec.Command("git", "merge", "--abort")
+			abort.Dir = s.workDir
+			abort.Run()
+			cmd = exec.Command("git", append([]string{"merge", workspace.GetBranch(), "--no-edit", "-X", "theirs", "-m",
+				fmt.Sprintf("Merge branch '%s' (task %s) with auto-resolution", workspace.GetBranch(), workspace.TaskID)}, s.signing.Args()...)...)
 			cmd.Dir = s.workDir
+			s.signing.Apply(cmd)
 			if output, err := cmd.CombinedOutput(); err != nil {
+				// Leave the base clean for the merges of other tasks
+				abort := exec.Command("git", "merge", "--abort")
+				abort.Dir = s.workDir
+				abort.Run()
 				if s.parallelLogger != nil {
 					s.parallelLogger.Merge("Merge failed for %s even with auto-resolution: %v", workspace.TaskID, err)
 				}
@@ -386,6 +880,49 @@ func (s *Scheduler) mergeBranch(workspace *isolation.Workspace) error {
 	return nil
 }
 
+// rebaseBranch rebases a workspace branch onto the base branch in its
+// worktree and fast-forwards the base branch to it, keeping the history
+// linear. Conflicts are resolved in favor of the task like merges are.
+func (s *Scheduler) rebaseBranch(workspace *isolation.Workspace, baseBranch string) error {
+	rebase := func(args ...string) (string, error) {
+		// Replayed commits are signed again
+		cmd := exec.Command("git", append(append([]string{"rebase"}, s.signing.Args()...), args...)...)
+		cmd.Dir = workspace.GetWorkPath()
+		s.signing.Apply(cmd)
+		output, err := cmd.CombinedOutput()
+		if err != nil {
+			abort := exec.Command("git", "rebase", "--abort")
+			abort.Dir = workspace.GetWorkPath()
+			abort.Run()
+		}
+		return string(output), err
+	}
+
+	if output, err := rebase(baseBranch); err != nil {
+		if !strings.Contains(output, "CONFLICT") {
+			return fmt.Errorf("rebase failed: %w: %s", err, output)
+		}
+		s.logError("Rebase conflict detected for %s, attempting auto-resolution...", workspace.TaskID)
+		if s.parallelLogger != nil {
+			s.parallelLogger.ConflictDetected(workspace.GetBranch(), []string{workspace.TaskID}, "git-rebase")
+		}
+		// While rebasing, "theirs" are the task commits being replayed
+		if output, err := rebase("-X", "theirs", baseBranch); err != nil {
+			return fmt.Errorf("rebase failed even with auto-resolution: %w: %s", err, output)
+		}
+		if s.parallelLogger != nil {
+			s.parallelLogger.ConflictResolved(workspace.GetBranch(), "theirs-strategy")
+		}
+	}
+
+	cmd := exec.Command("git", "merge", "--ff-only", workspace.GetBranch())
+	cmd.Dir = s.workDir
+	if output, err := cmd.CombinedOutput(); err != nil {
+		return fmt.Errorf("fast-forward failed: %w: %s", err, string(output))
+	}
+	return nil
+}
+
 // countResults updates the result counts
 func (s *Scheduler) countResults(result *ExecutionResult) {
 	for _, r := range result.Results {
@@ -397,9 +934,15 @@ func (s *Scheduler) countResults(result *ExecutionResult) {
 	}
 }
 
-// cleanupWorktrees removes all hermes worktrees
+// cleanupWorktrees removes all hermes worktrees and workspace copies
 func (s *Scheduler) cleanupWorktrees() {
 	s.logInfo("Cleaning up worktrees...")
+	if err := isolation.CleanupCopies(s.workDir); err != nil {
+		s.logError("Failed to remove workspace copies: %v", err)
+	}
+	if isolation.ResolveBackend(s.config.IsolationBackend, s.workDir) == isolation.BackendCopy {
+		return
+	}
 	
 	// List worktrees
 	cmd := exec.Command("git", "worktree", "list", "--porcelain")
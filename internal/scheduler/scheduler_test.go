@@ -1,6 +1,8 @@
 package scheduler
 
 import (
+	"runtime"
+	"slices"
 	"testing"
 
 	"hermes/internal/task"
@@ -232,3 +234,132 @@ func TestImplicitDocDependencies(t *testing.T) {
 		t.Error("With implicit deps: non-doc tasks should be in first batch")
 	}
 }
+
+func TestImplicitFileDependencies(t *testing.T) {
+	tasks := []*task.Task{
+		{ID: "T001", Name: "Add users table", Status: task.StatusNotStarted, FilesToTouch: []string{"`db/schema.sql`"}},
+		{ID: "T002", Name: "Add orders table", Status: task.StatusNotStarted, FilesToTouch: []string{"db/./schema.sql (modify)"}},
+		{ID: "T003", Name: "Add logging", Status: task.StatusNotStarted, FilesToTouch: []string{"log.go"}},
+		{ID: "T004", Name: "Extend schema", Status: task.StatusNotStarted, FilesToTouch: []string{"db/schema.sql"}, Dependencies: []string{"T005"}},
+		{ID: "T005", Name: "Add migrations", Status: task.StatusNotStarted, FilesToTouch: []string{"db/schema.sql"}},
+	}
+
+	addImplicitFileDependencies(tasks)
+
+	if len(tasks[1].Dependencies) != 1 || tasks[1].Dependencies[0] != "T001" {
+		t.Errorf("T002 should depend on T001, got %v", tasks[1].Dependencies)
+	}
+	if len(tasks[2].Dependencies) != 0 {
+		t.Errorf("T003 touches no shared file, got dependencies %v", tasks[2].Dependencies)
+	}
+	// T004 already depends on T005, adding the reverse edge would be a cycle
+	if slices.Contains(tasks[4].Dependencies, "T004") {
+		t.Errorf("T005 should not depend on T004, got %v", tasks[4].Dependencies)
+	}
+
+	graph, err := NewTaskGraph(tasks)
+	if err != nil {
+		t.Fatalf("NewTaskGraph failed: %v", err)
+	}
+	batches, err := graph.GetBatches()
+	if err != nil {
+		t.Fatalf("GetBatches failed: %v", err)
+	}
+	for _, batch := range batches {
+		shared := 0
+		for _, bt := range batch {
+			if bt.ID != "T003" {
+				shared++
+			}
+		}
+		if shared > 1 {
+			t.Errorf("tasks touching db/schema.sql should not share a batch, got %d", shared)
+		}
+	}
+
+	// Completed tasks do not order the remaining ones
+	done := []*task.Task{
+		{ID: "T001", Status: task.StatusCompleted, FilesToTouch: []string{"a.go"}},
+		{ID: "T002", Status: task.StatusNotStarted, FilesToTouch: []string{"a.go"}},
+	}
+	addImplicitFileDependencies(done)
+	if len(done[1].Dependencies) != 0 {
+		t.Errorf("completed task should not become a dependency, got %v", done[1].Dependencies)
+	}
+}
+
+func TestParseProcStat(t *testing.T) {
+	line := "4242 (claude (worker)) S 4200 4242 4200 0 -1 4194304 1200 0 0 0 150 50 0 0 20 0 12 0 98765 734003200 2560 18446744073709551615 1 1 0 0 0 0 0 4096 0 0 0 0 17 3 0 0 0 0 0"
+	st, ok := parseProcStat(line)
+	if !ok {
+		t.Fatal("expected stat line to parse")
+	}
+	if st.ppid != 4200 || st.cpuTicks != 200 || st.rssPages != 2560 {
+		t.Errorf("unexpected stat %+v", st)
+	}
+	if _, ok := parseProcStat("4242 (claude) S 4200"); ok {
+		t.Error("expected truncated stat line to fail")
+	}
+}
+
+func TestResourceMonitorSample(t *testing.T) {
+	m := NewResourceMonitor(0, 0, 0)
+	memoryMB, _, err := m.Sample()
+	if runtime.GOOS != "linux" {
+		if err == nil {
+			t.Error("expected sampling to be unsupported")
+		}
+		return
+	}
+	if err != nil {
+		t.Fatal(err)
+	}
+	if memoryMB <= 0 {
+		t.Errorf("expected the test process to use memory, got %d MB", memoryMB)
+	}
+
+	// A CPU limit no sample can reach and a memory limit every sample exceeds
+	if !NewResourceMonitor(0, 1000, 0).CanStartWorker() {
+		t.Error("expected worker to start below the CPU limit")
+	}
+	if NewResourceMonitor(1, 0, 0).CanStartWorker() {
+		t.Error("expected worker to wait above the memory limit")
+	}
+}
+
+func TestPriorityOrdering(t *testing.T) {
+	tasks := []*task.Task{
+		{ID: "T010", Status: task.StatusNotStarted, Priority: task.PriorityP3},
+		{ID: "T011", Status: task.StatusNotStarted, Priority: task.PriorityP1},
+		{ID: "T012", Status: task.StatusNotStarted, Priority: task.PriorityP2},
+		{ID: "T013", Status: task.StatusNotStarted, Priority: task.PriorityP4},
+		{ID: "T014", Status: task.StatusNotStarted, Priority: task.PriorityP1},
+		{ID: "T015", Status: task.StatusNotStarted, Priority: task.PriorityP3},
+		{ID: "T016", Status: task.StatusNotStarted, Priority: task.PriorityP2},
+	}
+	graph, err := NewTaskGraph(tasks)
+	if err != nil {
+		t.Fatal(err)
+	}
+	batches, err := graph.GetBatches()
+	if err != nil {
+		t.Fatal(err)
+	}
+	var first []string
+	for _, tk := range batches[0] {
+		first = append(first, tk.ID)
+	}
+	if want := []string{"T011", "T014", "T012", "T016", "T010"}; !slices.Equal(first, want) {
+		t.Errorf("expected first batch %v, got %v", want, first)
+	}
+
+	// A P1 task of a later batch goes before the lower priorities of earlier ones
+	run := newDispatchState([][]*task.Task{{tasks[0], tasks[2]}, {tasks[1]}})
+	var ready []string
+	for _, tk := range run.ready(graph) {
+		ready = append(ready, tk.ID)
+	}
+	if want := []string{"T011", "T012", "T010"}; !slices.Equal(ready, want) {
+		t.Errorf("expected ready order %v, got %v", want, ready)
+	}
+}
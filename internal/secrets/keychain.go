@@ -0,0 +1,91 @@
+package secrets
+
+import (
+	"bytes"
+	"fmt"
+	"os/exec"
+	"runtime"
+	"strings"
+)
+
+// keychainService is the service Hermes secrets are stored under in the OS
+// keychain, with the secret's name as the account
+const keychainService = "hermes"
+
+// KeychainGet returns a secret from the OS keychain: the login keychain on
+// macOS, the Secret Service (GNOME Keyring, KWallet) through secret-tool on
+// Linux
+func KeychainGet(name string) (string, error) {
+	var cmd *exec.Cmd
+	switch runtime.GOOS {
+	case "darwin":
+		cmd = exec.Command("security", "find-generic-password", "-s", keychainService, "-a", name, "-w")
+	case "linux":
+		cmd = exec.Command("secret-tool", "lookup", "service", keychainService, "account", name)
+	default:
+		return "", fmt.Errorf("the OS keychain is not supported on %s, use store:%s", runtime.GOOS, name)
+	}
+	var stderr bytes.Buffer
+	cmd.Stderr = &stderr
+	out, err := cmd.Output()
+	value := strings.TrimRight(string(out), "\r\n")
+	if err != nil || value == "" {
+		return "", fmt.Errorf("secret %q is not in the keychain%s", name, keychainDetail(err, stderr.String()))
+	}
+	return value, nil
+}
+
+// KeychainSet stores a secret in the OS keychain, replacing an existing one.
+// The secret is passed on stdin so it doesn't show up in the process list.
+func KeychainSet(name, value string) error {
+	var cmd *exec.Cmd
+	switch runtime.GOOS {
+	case "darwin":
+		// security -i reads its commands from stdin
+		cmd = exec.Command("security", "-i")
+		cmd.Stdin = strings.NewReader(fmt.Sprintf("add-generic-password -U -s %s -a %s -w %s\n",
+			keychainService, quoteSecurityArg(name), quoteSecurityArg(value)))
+	case "linux":
+		cmd = exec.Command("secret-tool", "store", "--label=Hermes "+name, "service", keychainService, "account", name)
+		cmd.Stdin = strings.NewReader(value)
+	default:
+		return fmt.Errorf("the OS keychain is not supported on %s", runtime.GOOS)
+	}
+	if out, err := cmd.CombinedOutput(); err != nil {
+		return fmt.Errorf("cannot store %q in the keychain%s", name, keychainDetail(err, string(out)))
+	}
+	return nil
+}
+
+// KeychainDelete removes a secret from the OS keychain
+func KeychainDelete(name string) error {
+	var cmd *exec.Cmd
+	switch runtime.GOOS {
+	case "darwin":
+		cmd = exec.Command("security", "delete-generic-password", "-s", keychainService, "-a", name)
+	case "linux":
+		cmd = exec.Command("secret-tool", "clear", "service", keychainService, "account", name)
+	default:
+		return fmt.Errorf("the OS keychain is not supported on %s", runtime.GOOS)
+	}
+	if out, err := cmd.CombinedOutput(); err != nil {
+		return fmt.Errorf("cannot remove %q from the keychain%s", name, keychainDetail(err, string(out)))
+	}
+	return nil
+}
+
+// quoteSecurityArg quotes an argument of a security -i command
+func quoteSecurityArg(s string) string {
+	return `"` + strings.NewReplacer(`\`, `\\`, `"`, `\"`).Replace(s) + `"`
+}
+
+// keychainDetail describes why a keychain command failed
+func keychainDetail(err error, output string) string {
+	if err == nil {
+		return ""
+	}
+	if detail := strings.TrimSpace(output); detail != "" {
+		return ": " + detail
+	}
+	return ": " + err.Error()
+}
@@ -0,0 +1,81 @@
+package secrets
+
+import (
+	"fmt"
+	"os"
+	"strings"
+)
+
+// Sources a secret reference can name
+const (
+	SourceEnv      = "env"      // Another environment variable, e.g. env:CI_ANTHROPIC_KEY
+	SourceKeychain = "keychain" // The OS keychain, under the service "hermes"
+	SourceStore    = "store"    // The encrypted .hermes/secrets.enc
+)
+
+// ParseRef splits a secret reference like "keychain:anthropic" into its
+// source and name. The reference never holds the secret itself, so the
+// error does not repeat it in case a key was pasted by mistake.
+func ParseRef(ref string) (source, name string, err error) {
+	source, name, ok := strings.Cut(strings.TrimSpace(ref), ":")
+	if !ok || name == "" || (source != SourceEnv && source != SourceKeychain && source != SourceStore) {
+		return "", "", fmt.Errorf("must be env:NAME, keychain:NAME or store:NAME, never the secret itself")
+	}
+	return source, name, nil
+}
+
+// Resolver looks up the secrets of references, reading the encrypted store
+// once on first use
+type Resolver struct {
+	storePath  string
+	passphrase func() (string, error)
+	store      map[string]string
+	storeErr   error // Why the store cannot be read, to fail once rather than per secret
+	lookupEnv  func(string) (string, bool)
+	keychain   func(name string) (string, error)
+}
+
+// NewResolver creates a resolver for the store at storePath, which is
+// decrypted with the passphrase returned by passphrase
+func NewResolver(storePath string, passphrase func() (string, error)) *Resolver {
+	return &Resolver{
+		storePath:  storePath,
+		passphrase: passphrase,
+		lookupEnv:  os.LookupEnv,
+		keychain:   KeychainGet,
+	}
+}
+
+// Resolve returns the secret a reference names
+func (r *Resolver) Resolve(ref string) (string, error) {
+	source, name, err := ParseRef(ref)
+	if err != nil {
+		return "", err
+	}
+	switch source {
+	case SourceEnv:
+		value, ok := r.lookupEnv(name)
+		if !ok || value == "" {
+			return "", fmt.Errorf("environment variable %s is not set", name)
+		}
+		return value, nil
+	case SourceKeychain:
+		return r.keychain(name)
+	default:
+		if r.store == nil && r.storeErr == nil {
+			passphrase, err := r.passphrase()
+			if err == nil {
+				r.store, err = LoadStore(r.storePath, passphrase)
+			}
+			r.storeErr = err
+		}
+		if r.storeErr != nil {
+			return "", r.storeErr
+		}
+		value, ok := r.store[name]
+		if !ok {
+			return "", fmt.Errorf("secret %q is not in %s", name, r.storePath)
+		}
+		return value, nil
+	}
+}
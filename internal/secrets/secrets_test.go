@@ -0,0 +1,82 @@
+package secrets
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestStore(t *testing.T) {
+	path := filepath.Join(t.TempDir(), ".hermes", StoreFile)
+	store, err := LoadStore(path, "passphrase")
+	if err != nil || len(store) != 0 {
+		t.Fatalf("expected an empty store before it is saved, got %v, %v", store, err)
+	}
+
+	if err := SaveStore(path, "passphrase", map[string]string{"anthropic": "sk-ant-123"}); err != nil {
+		t.Fatalf("SaveStore failed: %v", err)
+	}
+	data, _ := os.ReadFile(path)
+	if strings.Contains(string(data), "sk-ant-123") {
+		t.Error("expected the secret to be encrypted")
+	}
+	if info, _ := os.Stat(path); info.Mode().Perm() != 0600 {
+		t.Errorf("expected the store to be readable by the owner only, got %v", info.Mode().Perm())
+	}
+
+	store, err = LoadStore(path, "passphrase")
+	if err != nil || store["anthropic"] != "sk-ant-123" {
+		t.Errorf("expected the secret back, got %v, %v", store, err)
+	}
+	if _, err := LoadStore(path, "wrong"); err == nil || !strings.Contains(err.Error(), "wrong passphrase") {
+		t.Errorf("expected a wrong passphrase error, got %v", err)
+	}
+	if err := SaveStore(path, "", nil); err == nil {
+		t.Error("expected an empty passphrase to be refused")
+	}
+}
+
+func TestResolve(t *testing.T) {
+	path := filepath.Join(t.TempDir(), StoreFile)
+	if err := SaveStore(path, "passphrase", map[string]string{"gemini": "AIza-456"}); err != nil {
+		t.Fatal(err)
+	}
+	prompts := 0
+	r := NewResolver(path, func() (string, error) {
+		prompts++
+		return "passphrase", nil
+	})
+	r.lookupEnv = func(name string) (string, bool) {
+		return "ci-key", name == "CI_KEY"
+	}
+	r.keychain = func(name string) (string, error) {
+		return "", fmt.Errorf("secret %q is not in the keychain", name)
+	}
+
+	tests := []struct {
+		ref   string
+		value string
+		err   string
+	}{
+		{"env:CI_KEY", "ci-key", ""},
+		{"env:MISSING", "", "MISSING is not set"},
+		{"store:gemini", "AIza-456", ""},
+		{"store:openai", "", `"openai" is not in`},
+		{"keychain:droid", "", "not in the keychain"},
+		{"sk-ant-789", "", "never the secret itself"},
+	}
+	for _, tt := range tests {
+		value, err := r.Resolve(tt.ref)
+		if value != tt.value || (tt.err == "") != (err == nil) || (err != nil && !strings.Contains(err.Error(), tt.err)) {
+			t.Errorf("Resolve(%q) = %q, %v, expected %q with error %q", tt.ref, value, err, tt.value, tt.err)
+		}
+		if err != nil && strings.Contains(err.Error(), "sk-ant-789") {
+			t.Errorf("expected the error not to repeat the reference, got %v", err)
+		}
+	}
+	if prompts != 1 {
+		t.Errorf("expected the passphrase to be asked for once, got %d", prompts)
+	}
+}
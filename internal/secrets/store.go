@@ -0,0 +1,115 @@
+package secrets
+
+import (
+	"bytes"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/pbkdf2"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+)
+
+// StoreFile is the encrypted store in the .hermes directory
+const StoreFile = "secrets.enc"
+
+// The store is the magic, a random salt and nonce and the AES-256-GCM
+// encrypted JSON object of the secrets by name. The key is derived from the
+// passphrase with PBKDF2-SHA256.
+var storeMagic = []byte("HERMES-SECRETS-1\n")
+
+const (
+	saltSize   = 16
+	iterations = 600000
+)
+
+// StorePath returns the path of the encrypted store of a project
+func StorePath(basePath string) string {
+	return filepath.Join(basePath, ".hermes", StoreFile)
+}
+
+// LoadStore decrypts the secrets of the store at path, none if it doesn't exist
+func LoadStore(path, passphrase string) (map[string]string, error) {
+	data, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return map[string]string{}, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	data, ok := bytes.CutPrefix(data, storeMagic)
+	if !ok || len(data) < saltSize {
+		return nil, fmt.Errorf("%s is not a secrets store", path)
+	}
+	gcm, err := storeCipher(passphrase, data[:saltSize])
+	if err != nil {
+		return nil, err
+	}
+	data = data[saltSize:]
+	if len(data) < gcm.NonceSize() {
+		return nil, fmt.Errorf("%s is not a secrets store", path)
+	}
+	plain, err := gcm.Open(nil, data[:gcm.NonceSize()], data[gcm.NonceSize():], storeMagic)
+	if err != nil {
+		return nil, fmt.Errorf("cannot decrypt %s: wrong passphrase or damaged file", path)
+	}
+
+	secrets := map[string]string{}
+	if err := json.Unmarshal(plain, &secrets); err != nil {
+		return nil, fmt.Errorf("cannot decrypt %s: %w", path, err)
+	}
+	return secrets, nil
+}
+
+// SaveStore encrypts secrets to the store at path with a new salt, readable
+// by the owner only
+func SaveStore(path, passphrase string, secrets map[string]string) error {
+	if passphrase == "" {
+		return fmt.Errorf("the passphrase must not be empty")
+	}
+	plain, err := json.Marshal(secrets)
+	if err != nil {
+		return err
+	}
+
+	salt := make([]byte, saltSize)
+	if _, err := rand.Read(salt); err != nil {
+		return err
+	}
+	gcm, err := storeCipher(passphrase, salt)
+	if err != nil {
+		return err
+	}
+	nonce := make([]byte, gcm.NonceSize())
+	if _, err := rand.Read(nonce); err != nil {
+		return err
+	}
+
+	data := append(append(append(append([]byte{}, storeMagic...), salt...), nonce...), gcm.Seal(nil, nonce, plain, storeMagic)...)
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return err
+	}
+	tmp := path + ".tmp"
+	if err := os.WriteFile(tmp, data, 0600); err != nil {
+		return err
+	}
+	return os.Rename(tmp, path)
+}
+
+// storeCipher derives the store key from the passphrase and salt
+func storeCipher(passphrase string, salt []byte) (cipher.AEAD, error) {
+	key, err := pbkdf2.Key(sha256.New, passphrase, salt, iterations, 32)
+	if err != nil {
+		return nil, err
+	}
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, err
+	}
+	return cipher.NewGCM(block)
+}
@@ -0,0 +1,168 @@
+package server
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+)
+
+// RunState represents the state of the run loop controlled by the server
+type RunState string
+
+const (
+	RunIdle    RunState = "idle"
+	RunRunning RunState = "running"
+	RunPaused  RunState = "paused"
+)
+
+// RunFunc executes the run loop until it finishes or ctx is cancelled.
+// It must call gate.Wait between tasks so the run can be paused.
+type RunFunc func(ctx context.Context, gate *Gate) error
+
+// Gate blocks the run loop while paused
+type Gate struct {
+	mu     sync.Mutex
+	paused bool
+	resume chan struct{}
+}
+
+// NewGate creates an open gate
+func NewGate() *Gate {
+	return &Gate{resume: make(chan struct{})}
+}
+
+// Wait blocks while the gate is paused or until ctx is cancelled
+func (g *Gate) Wait(ctx context.Context) error {
+	g.mu.Lock()
+	if !g.paused {
+		g.mu.Unlock()
+		return nil
+	}
+	resume := g.resume
+	g.mu.Unlock()
+
+	select {
+	case <-resume:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+func (g *Gate) setPaused(paused bool) {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	if g.paused == paused {
+		return
+	}
+	g.paused = paused
+	if !paused {
+		close(g.resume)
+		g.resume = make(chan struct{})
+	}
+}
+
+// RunStatus describes the controlled run
+type RunStatus struct {
+	State     RunState  `json:"state"`
+	StartedAt time.Time `json:"startedAt,omitempty"`
+	LastError string    `json:"lastError,omitempty"`
+}
+
+// Controller starts, stops and pauses a single run loop
+type Controller struct {
+	run    RunFunc
+	gate   *Gate
+	mu     sync.Mutex
+	cancel context.CancelFunc
+	done   chan struct{}
+	status RunStatus
+}
+
+// NewController creates a controller for the given run function
+func NewController(run RunFunc) *Controller {
+	return &Controller{
+		run:    run,
+		gate:   NewGate(),
+		status: RunStatus{State: RunIdle},
+	}
+}
+
+// Start launches the run loop in the background
+func (c *Controller) Start() error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if c.status.State != RunIdle {
+		return fmt.Errorf("run already %s", c.status.State)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan struct{})
+	c.cancel = cancel
+	c.done = done
+	c.gate.setPaused(false)
+	c.status = RunStatus{State: RunRunning, StartedAt: time.Now()}
+
+	go func() {
+		defer close(done)
+		err := c.run(ctx, c.gate)
+
+		c.mu.Lock()
+		defer c.mu.Unlock()
+		c.status.State = RunIdle
+		if err != nil && ctx.Err() == nil {
+			c.status.LastError = err.Error()
+		}
+		cancel()
+	}()
+
+	return nil
+}
+
+// Stop cancels the run loop and waits for it to exit
+func (c *Controller) Stop() error {
+	c.mu.Lock()
+	if c.status.State == RunIdle {
+		c.mu.Unlock()
+		return fmt.Errorf("no run in progress")
+	}
+	cancel, done := c.cancel, c.done
+	c.mu.Unlock()
+
+	cancel()
+	<-done
+	return nil
+}
+
+// Pause stops the run loop before its next task
+func (c *Controller) Pause() error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.status.State != RunRunning {
+		return fmt.Errorf("no running run to pause")
+	}
+	c.gate.setPaused(true)
+	c.status.State = RunPaused
+	return nil
+}
+
+// Resume continues a paused run loop
+func (c *Controller) Resume() error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.status.State != RunPaused {
+		return fmt.Errorf("run is not paused")
+	}
+	c.gate.setPaused(false)
+	c.status.State = RunRunning
+	return nil
+}
+
+// Status returns the current run status
+func (c *Controller) Status() RunStatus {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.status
+}
@@ -0,0 +1,107 @@
+package server
+
+// dashboardHTML is the single-page dashboard served at /
+const dashboardHTML = `<!DOCTYPE html>
+<html>
+<head>
+<meta charset="utf-8">
+<title>Hermes Dashboard</title>
+<style>
+body { font-family: -apple-system, Segoe UI, sans-serif; margin: 2em; color: #222; background: #fafafa; }
+.card { background: #fff; border: 1px solid #ddd; border-radius: 6px; padding: 1em; margin-bottom: 1em; }
+.bar { background: #eee; border-radius: 4px; height: 14px; overflow: hidden; }
+.bar div { background: #2e7d32; height: 100%; }
+button { margin-right: 0.5em; padding: 4px 12px; }
+table { border-collapse: collapse; width: 100%; font-size: 13px; }
+th, td { text-align: left; padding: 2px 8px; border-bottom: 1px solid #eee; }
+#logs { background: #111; color: #ddd; font-family: monospace; font-size: 12px; height: 320px; overflow-y: auto; padding: 8px; white-space: pre-wrap; }
+.CLOSED { color: #2e7d32; } .HALF_OPEN { color: #ef6c00; } .OPEN { color: #c62828; }
+</style>
+</head>
+<body>
+<h1>Hermes</h1>
+<div class="card">
+  <strong>Run:</strong> <span id="run">-</span>
+  <span id="runError" style="color:#c62828"></span>
+  <div style="margin-top:0.5em">
+    <button onclick="action('start')">Start</button>
+    <button onclick="action('pause')">Pause</button>
+    <button onclick="action('resume')">Resume</button>
+    <button onclick="action('stop')">Stop</button>
+  </div>
+</div>
+<div class="card">
+  <strong>Progress:</strong> <span id="progressText">-</span>
+  <div class="bar"><div id="progressBar" style="width:0%"></div></div>
+</div>
+<div class="card"><strong>Current task:</strong> <span id="task">none</span></div>
+<div class="card"><strong>Circuit breaker:</strong> <span id="circuit">-</span></div>
+<div class="card"><strong>Recent runs</strong>
+  <table><thead><tr><th>Run</th><th>Mode</th><th>Provider</th><th>Completed</th><th>Failed</th><th>Attempts</th><th>Duration</th><th>Cost</th></tr></thead>
+  <tbody id="runs"></tbody></table>
+</div>
+<div class="card"><strong>Logs</strong><div id="logs"></div></div>
+<script>
+const token = new URLSearchParams(location.search).get('token') || '';
+const headers = token ? { 'Authorization': 'Bearer ' + token } : {};
+const q = token ? '?token=' + encodeURIComponent(token) : '';
+
+async function refresh() {
+  const res = await fetch('/api/status', { headers });
+  if (!res.ok) return;
+  const s = await res.json();
+  document.getElementById('run').textContent = s.run.state;
+  document.getElementById('runError').textContent = s.run.lastError ? ' (' + s.run.lastError + ')' : '';
+  if (s.progress) {
+    const p = s.progress;
+    document.getElementById('progressText').textContent = p.completed + '/' + p.total + ' (' + p.percentage.toFixed(1) + '%)';
+    document.getElementById('progressBar').style.width = p.percentage + '%';
+  }
+  document.getElementById('task').textContent = s.currentTask ? s.currentTask.id + ' - ' + s.currentTask.name : 'none';
+  const c = document.getElementById('circuit');
+  if (s.circuit) {
+    c.textContent = s.circuit.state + (s.circuit.reason ? ' - ' + s.circuit.reason : '');
+    c.className = s.circuit.state;
+  }
+}
+
+async function refreshRuns() {
+  const res = await fetch('/api/runs?limit=10', { headers });
+  if (!res.ok) return;
+  const runs = await res.json();
+  const body = document.getElementById('runs');
+  body.textContent = '';
+  runs.forEach(r => {
+    const row = body.insertRow();
+    [r.id, r.mode, r.provider, r.completed, r.failed, r.attempts,
+     Math.round(r.seconds / 60) + 'm', '$' + r.cost.toFixed(2)].forEach(v => row.insertCell().textContent = v);
+  });
+}
+
+async function action(name) {
+  const res = await fetch('/api/run/' + name, { method: 'POST', headers });
+  if (!res.ok) {
+    const body = await res.json();
+    alert(body.error);
+  }
+  refresh();
+}
+
+function appendLog(line) {
+  const logs = document.getElementById('logs');
+  logs.textContent += line + '\n';
+  logs.scrollTop = logs.scrollHeight;
+}
+
+fetch('/api/logs?lines=200' + (token ? '&token=' + encodeURIComponent(token) : ''), { headers })
+  .then(r => r.json()).then(d => (d.lines || []).forEach(appendLog));
+new EventSource('/api/logs/stream' + q).onmessage = e => appendLog(e.data);
+
+refresh();
+refreshRuns();
+setInterval(refresh, 2000);
+setInterval(refreshRuns, 30000);
+</script>
+</body>
+</html>
+`
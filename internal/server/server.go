@@ -0,0 +1,401 @@
+package server
+
+import (
+	"bufio"
+	"context"
+	"crypto/rand"
+	"crypto/subtle"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+
+	"hermes/internal/circuit"
+	"hermes/internal/report"
+	"hermes/internal/task"
+)
+
+// Server exposes Hermes progress and run controls over HTTP
+type Server struct {
+	basePath     string
+	controller   *Controller
+	token        string
+	controlToken string // Required by the run controls when no token is set
+	addr         string
+	logPoll      time.Duration
+}
+
+// StatusResponse is returned by GET /api/status
+type StatusResponse struct {
+	Run         RunStatus             `json:"run"`
+	Progress    *task.Progress        `json:"progress,omitempty"`
+	CurrentTask *task.Task            `json:"currentTask,omitempty"`
+	Circuit     *circuit.BreakerState `json:"circuit,omitempty"`
+}
+
+// RunSummary is one recorded run returned by GET /api/runs
+type RunSummary struct {
+	ID        string    `json:"id"`
+	Mode      string    `json:"mode"`
+	Provider  string    `json:"provider"`
+	StartTime time.Time `json:"startTime"`
+	EndTime   time.Time `json:"endTime"`
+	Completed int       `json:"completed"`
+	Failed    int       `json:"failed"`
+	Blocked   int       `json:"blocked"`
+	Attempts  int       `json:"attempts"`
+	Cost      float64   `json:"cost"`
+	Seconds   float64   `json:"seconds"` // Wall-clock duration of the run
+}
+
+// New creates a server for the project at basePath. The run controls require
+// a generated token until SetToken sets one for the whole API.
+func New(basePath string, controller *Controller) *Server {
+	return &Server{
+		basePath:     basePath,
+		controller:   controller,
+		controlToken: generateToken(),
+		logPoll:      500 * time.Millisecond,
+	}
+}
+
+// SetToken requires API requests to carry the given bearer token
+func (s *Server) SetToken(token string) {
+	s.token = token
+}
+
+// ControlToken returns the token the run controls require
+func (s *Server) ControlToken() string {
+	if s.token != "" {
+		return s.token
+	}
+	return s.controlToken
+}
+
+// generateToken returns a random token, or an empty one when the system has
+// no randomness, which leaves the run controls unusable rather than open
+func generateToken() string {
+	b := make([]byte, 16)
+	if _, err := rand.Read(b); err != nil {
+		return ""
+	}
+	return hex.EncodeToString(b)
+}
+
+// Handler returns the HTTP handler for the dashboard and REST API
+func (s *Server) Handler() http.Handler {
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /{$}", s.handleDashboard)
+	mux.HandleFunc("GET /api/status", s.handleStatus)
+	mux.HandleFunc("GET /api/tasks", s.handleTasks)
+	mux.HandleFunc("GET /api/circuit", s.handleCircuit)
+	mux.HandleFunc("GET /api/runs", s.handleRuns)
+	mux.HandleFunc("GET /api/logs", s.handleLogs)
+	mux.HandleFunc("GET /api/logs/stream", s.handleLogStream)
+	mux.HandleFunc("POST /api/run/{action}", s.handleRunAction)
+	return s.authenticate(mux)
+}
+
+// ListenAndServe serves until ctx is cancelled
+func (s *Server) ListenAndServe(ctx context.Context, addr string) error {
+	s.addr = addr
+	srv := &http.Server{Addr: addr, Handler: s.Handler()}
+
+	errCh := make(chan error, 1)
+	go func() {
+		errCh <- srv.ListenAndServe()
+	}()
+
+	select {
+	case err := <-errCh:
+		return err
+	case <-ctx.Done():
+		shutdownCtx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer cancel()
+		return srv.Shutdown(shutdownCtx)
+	}
+}
+
+// authenticate rejects requests from other origins and checks the bearer
+// token (or ?token= for EventSource). Reads only need a token when one is
+// set, the run controls always do.
+func (s *Server) authenticate(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if !s.sameOrigin(r) {
+			writeError(w, http.StatusForbidden, "cross-origin requests are not allowed")
+			return
+		}
+		token := s.token
+		if r.Method != http.MethodGet && r.Method != http.MethodHead {
+			token = s.ControlToken()
+			if token == "" {
+				writeError(w, http.StatusUnauthorized, "run controls are disabled without a token")
+				return
+			}
+		}
+		if token == "" || r.URL.Path == "/" {
+			next.ServeHTTP(w, r)
+			return
+		}
+		provided := strings.TrimPrefix(r.Header.Get("Authorization"), "Bearer ")
+		if provided == "" {
+			provided = r.URL.Query().Get("token")
+		}
+		if subtle.ConstantTimeCompare([]byte(provided), []byte(token)) != 1 {
+			writeError(w, http.StatusUnauthorized, "invalid or missing token")
+			return
+		}
+		next.ServeHTTP(w, r)
+	})
+}
+
+// sameOrigin reports whether a request comes from the dashboard itself. The
+// Origin, when sent, must match the Host, and a server listening on loopback
+// only answers loopback host names so other sites cannot rebind to it.
+func (s *Server) sameOrigin(r *http.Request) bool {
+	if origin := r.Header.Get("Origin"); origin != "" {
+		u, err := url.Parse(origin)
+		if err != nil || u.Host != r.Host {
+			return false
+		}
+	}
+	if !isLoopbackHost(hostOnly(s.addr)) {
+		return true
+	}
+	return isLoopbackHost(hostOnly(r.Host))
+}
+
+// hostOnly strips the port from a host:port address
+func hostOnly(addr string) string {
+	if host, _, err := net.SplitHostPort(addr); err == nil {
+		return host
+	}
+	return addr
+}
+
+// isLoopbackHost reports whether host names the local machine
+func isLoopbackHost(host string) bool {
+	if host == "localhost" {
+		return true
+	}
+	ip := net.ParseIP(host)
+	return ip != nil && ip.IsLoopback()
+}
+
+func (s *Server) handleDashboard(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "text/html; charset=utf-8")
+	io.WriteString(w, dashboardHTML)
+}
+
+func (s *Server) handleStatus(w http.ResponseWriter, r *http.Request) {
+	resp := StatusResponse{Run: s.controller.Status()}
+
+	reader := task.NewReader(s.basePath)
+	if reader.HasTasks() {
+		resp.Progress, _ = reader.GetProgress()
+		if inProgress, err := reader.GetTasksByStatus(task.StatusInProgress); err == nil && len(inProgress) > 0 {
+			resp.CurrentTask = &inProgress[0]
+		}
+	}
+	resp.Circuit, _ = circuit.New(s.basePath).GetState()
+
+	writeJSON(w, http.StatusOK, resp)
+}
+
+func (s *Server) handleTasks(w http.ResponseWriter, r *http.Request) {
+	features, err := task.NewReader(s.basePath).GetAllFeatures()
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+	writeJSON(w, http.StatusOK, features)
+}
+
+func (s *Server) handleCircuit(w http.ResponseWriter, r *http.Request) {
+	breaker := circuit.New(s.basePath)
+	state, err := breaker.GetState()
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+	history, _ := breaker.GetHistory()
+	writeJSON(w, http.StatusOK, map[string]interface{}{
+		"state":   state,
+		"history": history,
+	})
+}
+
+// handleRuns lists recorded runs from newest to oldest, ?limit= runs at most
+func (s *Server) handleRuns(w http.ResponseWriter, r *http.Request) {
+	runs, err := report.LoadRuns(s.basePath)
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+	limit := 20
+	if n, err := strconv.Atoi(r.URL.Query().Get("limit")); err == nil && n > 0 {
+		limit = n
+	}
+
+	summaries := []RunSummary{}
+	for i := len(runs) - 1; i >= 0 && len(summaries) < limit; i-- {
+		run := runs[i]
+		sum := run.Summarize()
+		summaries = append(summaries, RunSummary{
+			ID:        run.ID,
+			Mode:      run.Mode,
+			Provider:  run.Provider,
+			StartTime: run.StartTime,
+			EndTime:   run.EndTime,
+			Completed: sum.Completed,
+			Failed:    sum.Failed,
+			Blocked:   sum.Blocked,
+			Attempts:  sum.Attempts,
+			Cost:      sum.TotalCost,
+			Seconds:   run.Elapsed().Seconds(),
+		})
+	}
+	writeJSON(w, http.StatusOK, summaries)
+}
+
+func (s *Server) handleLogs(w http.ResponseWriter, r *http.Request) {
+	lines := 100
+	if n, err := strconv.Atoi(r.URL.Query().Get("lines")); err == nil && n > 0 {
+		lines = n
+	}
+	tail, err := tailLines(s.logPath(), lines)
+	if err != nil && !os.IsNotExist(err) {
+		writeError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+	writeJSON(w, http.StatusOK, map[string][]string{"lines": tail})
+}
+
+// handleLogStream streams new log lines as server-sent events
+func (s *Server) handleLogStream(w http.ResponseWriter, r *http.Request) {
+	flusher, ok := w.(http.Flusher)
+	if !ok {
+		writeError(w, http.StatusInternalServerError, "streaming not supported")
+		return
+	}
+
+	w.Header().Set("Content-Type", "text/event-stream")
+	w.Header().Set("Cache-Control", "no-cache")
+	w.Header().Set("Connection", "keep-alive")
+	flusher.Flush()
+
+	var offset int64
+	if info, err := os.Stat(s.logPath()); err == nil {
+		offset = info.Size()
+	}
+
+	ticker := time.NewTicker(s.logPoll)
+	defer ticker.Stop()
+
+	var partial string
+	for {
+		select {
+		case <-r.Context().Done():
+			return
+		case <-ticker.C:
+			data, newOffset, err := readFrom(s.logPath(), offset)
+			if err != nil || len(data) == 0 {
+				continue
+			}
+			offset = newOffset
+
+			text := partial + string(data)
+			parts := strings.Split(text, "\n")
+			partial = parts[len(parts)-1]
+			for _, line := range parts[:len(parts)-1] {
+				fmt.Fprintf(w, "data: %s\n\n", strings.TrimRight(line, "\r"))
+			}
+			flusher.Flush()
+		}
+	}
+}
+
+func (s *Server) handleRunAction(w http.ResponseWriter, r *http.Request) {
+	var err error
+	switch r.PathValue("action") {
+	case "start":
+		err = s.controller.Start()
+	case "stop":
+		err = s.controller.Stop()
+	case "pause":
+		err = s.controller.Pause()
+	case "resume":
+		err = s.controller.Resume()
+	default:
+		writeError(w, http.StatusNotFound, "unknown action")
+		return
+	}
+	if err != nil {
+		writeError(w, http.StatusConflict, err.Error())
+		return
+	}
+	writeJSON(w, http.StatusOK, s.controller.Status())
+}
+
+func (s *Server) logPath() string {
+	return filepath.Join(s.basePath, ".hermes", "logs", "hermes.log")
+}
+
+// tailLines returns the last n lines of a file
+func tailLines(path string, n int) ([]string, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return []string{}, err
+	}
+	defer file.Close()
+
+	lines := []string{}
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		lines = append(lines, scanner.Text())
+		if len(lines) > n {
+			lines = lines[1:]
+		}
+	}
+	return lines, scanner.Err()
+}
+
+// readFrom reads a file from offset, restarting from zero if it was truncated
+func readFrom(path string, offset int64) ([]byte, int64, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, offset, err
+	}
+	defer file.Close()
+
+	info, err := file.Stat()
+	if err != nil {
+		return nil, offset, err
+	}
+	if info.Size() < offset {
+		offset = 0
+	}
+	if _, err := file.Seek(offset, io.SeekStart); err != nil {
+		return nil, offset, err
+	}
+	data, err := io.ReadAll(file)
+	return data, offset + int64(len(data)), err
+}
+
+func writeJSON(w http.ResponseWriter, status int, v interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(v)
+}
+
+func writeError(w http.ResponseWriter, status int, message string) {
+	writeJSON(w, status, map[string]string{"error": message})
+}
@@ -0,0 +1,252 @@
+package server
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"hermes/internal/report"
+)
+
+const testFeature = `# Feature 1: Test
+
+**Feature ID:** F001
+**Status:** IN_PROGRESS
+
+## Tasks
+
+### T001: First Task
+
+**Status:** COMPLETED
+**Priority:** P1
+
+### T002: Second Task
+
+**Status:** IN_PROGRESS
+**Priority:** P2
+`
+
+func setupTestServer(t *testing.T, run RunFunc) (*Server, string) {
+	t.Helper()
+	tmpDir := t.TempDir()
+	tasksDir := filepath.Join(tmpDir, ".hermes", "tasks")
+	os.MkdirAll(tasksDir, 0755)
+	os.WriteFile(filepath.Join(tasksDir, "001-test.md"), []byte(testFeature), 0644)
+
+	if run == nil {
+		run = func(ctx context.Context, gate *Gate) error {
+			<-ctx.Done()
+			return nil
+		}
+	}
+	return New(tmpDir, NewController(run)), tmpDir
+}
+
+func TestStatusEndpoint(t *testing.T) {
+	srv, _ := setupTestServer(t, nil)
+
+	rec := httptest.NewRecorder()
+	srv.Handler().ServeHTTP(rec, httptest.NewRequest("GET", "/api/status", nil))
+	if rec.Code != http.StatusOK {
+		t.Fatalf("expected 200, got %d", rec.Code)
+	}
+
+	var resp StatusResponse
+	if err := json.Unmarshal(rec.Body.Bytes(), &resp); err != nil {
+		t.Fatal(err)
+	}
+	if resp.Run.State != RunIdle {
+		t.Errorf("expected idle run, got %s", resp.Run.State)
+	}
+	if resp.Progress == nil || resp.Progress.Total != 2 || resp.Progress.Completed != 1 {
+		t.Errorf("unexpected progress: %+v", resp.Progress)
+	}
+	if resp.CurrentTask == nil || resp.CurrentTask.ID != "T002" {
+		t.Errorf("expected current task T002, got %+v", resp.CurrentTask)
+	}
+}
+
+func TestTokenRequired(t *testing.T) {
+	srv, _ := setupTestServer(t, nil)
+	srv.SetToken("secret")
+	handler := srv.Handler()
+
+	rec := httptest.NewRecorder()
+	handler.ServeHTTP(rec, httptest.NewRequest("GET", "/api/status", nil))
+	if rec.Code != http.StatusUnauthorized {
+		t.Errorf("expected 401 without token, got %d", rec.Code)
+	}
+
+	req := httptest.NewRequest("GET", "/api/status", nil)
+	req.Header.Set("Authorization", "Bearer secret")
+	rec = httptest.NewRecorder()
+	handler.ServeHTTP(rec, req)
+	if rec.Code != http.StatusOK {
+		t.Errorf("expected 200 with token, got %d", rec.Code)
+	}
+
+	rec = httptest.NewRecorder()
+	handler.ServeHTTP(rec, httptest.NewRequest("GET", "/", nil))
+	if rec.Code != http.StatusOK {
+		t.Errorf("expected dashboard to load without token, got %d", rec.Code)
+	}
+}
+
+func TestRunControlsRequireToken(t *testing.T) {
+	srv, _ := setupTestServer(t, nil)
+	handler := srv.Handler()
+	if srv.ControlToken() == "" {
+		t.Fatal("expected a generated control token")
+	}
+
+	rec := httptest.NewRecorder()
+	handler.ServeHTTP(rec, httptest.NewRequest("POST", "/api/run/start", nil))
+	if rec.Code != http.StatusUnauthorized {
+		t.Errorf("expected 401 for run control without token, got %d", rec.Code)
+	}
+	if srv.controller.Status().State != RunIdle {
+		t.Errorf("expected run not to start, got %s", srv.controller.Status().State)
+	}
+
+	rec = httptest.NewRecorder()
+	handler.ServeHTTP(rec, httptest.NewRequest("GET", "/api/status", nil))
+	if rec.Code != http.StatusOK {
+		t.Errorf("expected reads to stay open without a configured token, got %d", rec.Code)
+	}
+}
+
+func TestForeignOriginRejected(t *testing.T) {
+	srv, _ := setupTestServer(t, nil)
+	srv.addr = "127.0.0.1:8080"
+	handler := srv.Handler()
+
+	req := httptest.NewRequest("POST", "http://127.0.0.1:8080/api/run/start", nil)
+	req.Header.Set("Authorization", "Bearer "+srv.ControlToken())
+	req.Header.Set("Origin", "http://evil.example")
+	rec := httptest.NewRecorder()
+	handler.ServeHTTP(rec, req)
+	if rec.Code != http.StatusForbidden {
+		t.Errorf("expected 403 for foreign origin, got %d", rec.Code)
+	}
+
+	req = httptest.NewRequest("GET", "http://evil.example/api/status", nil)
+	rec = httptest.NewRecorder()
+	handler.ServeHTTP(rec, req)
+	if rec.Code != http.StatusForbidden {
+		t.Errorf("expected 403 for rebound host, got %d", rec.Code)
+	}
+
+	req = httptest.NewRequest("GET", "http://localhost:8080/api/status", nil)
+	req.Header.Set("Origin", "http://localhost:8080")
+	rec = httptest.NewRecorder()
+	handler.ServeHTTP(rec, req)
+	if rec.Code != http.StatusOK {
+		t.Errorf("expected 200 for same origin, got %d", rec.Code)
+	}
+	if srv.controller.Status().State != RunIdle {
+		t.Errorf("expected run not to start, got %s", srv.controller.Status().State)
+	}
+}
+
+func TestRunControls(t *testing.T) {
+	passed := make(chan struct{}, 10)
+	srv, _ := setupTestServer(t, func(ctx context.Context, gate *Gate) error {
+		for {
+			if err := gate.Wait(ctx); err != nil {
+				return nil
+			}
+			select {
+			case passed <- struct{}{}:
+			default:
+			}
+			select {
+			case <-ctx.Done():
+				return nil
+			case <-time.After(5 * time.Millisecond):
+			}
+		}
+	})
+	handler := srv.Handler()
+
+	post := func(action string) int {
+		req := httptest.NewRequest("POST", "/api/run/"+action, nil)
+		req.Header.Set("Authorization", "Bearer "+srv.ControlToken())
+		rec := httptest.NewRecorder()
+		handler.ServeHTTP(rec, req)
+		return rec.Code
+	}
+
+	if code := post("pause"); code != http.StatusConflict {
+		t.Errorf("expected pause on idle run to conflict, got %d", code)
+	}
+	if code := post("start"); code != http.StatusOK {
+		t.Fatalf("expected start to succeed, got %d", code)
+	}
+	if code := post("start"); code != http.StatusConflict {
+		t.Errorf("expected second start to conflict, got %d", code)
+	}
+	<-passed
+
+	if code := post("pause"); code != http.StatusOK {
+		t.Fatalf("expected pause to succeed, got %d", code)
+	}
+	if srv.controller.Status().State != RunPaused {
+		t.Errorf("expected paused state, got %s", srv.controller.Status().State)
+	}
+	if code := post("resume"); code != http.StatusOK {
+		t.Fatalf("expected resume to succeed, got %d", code)
+	}
+	if code := post("stop"); code != http.StatusOK {
+		t.Fatalf("expected stop to succeed, got %d", code)
+	}
+	if srv.controller.Status().State != RunIdle {
+		t.Errorf("expected idle after stop, got %s", srv.controller.Status().State)
+	}
+}
+
+func TestLogsEndpoint(t *testing.T) {
+	srv, tmpDir := setupTestServer(t, nil)
+	logsDir := filepath.Join(tmpDir, ".hermes", "logs")
+	os.MkdirAll(logsDir, 0755)
+	os.WriteFile(filepath.Join(logsDir, "hermes.log"), []byte("one\ntwo\nthree\n"), 0644)
+
+	rec := httptest.NewRecorder()
+	srv.Handler().ServeHTTP(rec, httptest.NewRequest("GET", "/api/logs?lines=2", nil))
+
+	var resp map[string][]string
+	json.Unmarshal(rec.Body.Bytes(), &resp)
+	if len(resp["lines"]) != 2 || resp["lines"][1] != "three" {
+		t.Errorf("expected last 2 lines, got %v", resp["lines"])
+	}
+}
+
+func TestRunsEndpoint(t *testing.T) {
+	srv, tmpDir := setupTestServer(t, nil)
+	store := report.NewJSONStore(tmpDir)
+	start := time.Now().Add(-2 * time.Hour)
+	store.SaveRun(&report.Run{ID: "20260101-100000", Mode: "sequential", StartTime: start, EndTime: start.Add(time.Hour)})
+	store.SaveRun(&report.Run{ID: "20260101-120000", Mode: "parallel", StartTime: start.Add(time.Hour), EndTime: start.Add(90 * time.Minute),
+		Tasks: []report.TaskRecord{{TaskID: "T001", Outcome: report.OutcomeCompleted, Cost: 0.4}, {TaskID: "T002", Outcome: report.OutcomeFailed}}})
+
+	rec := httptest.NewRecorder()
+	srv.Handler().ServeHTTP(rec, httptest.NewRequest("GET", "/api/runs?limit=1", nil))
+	if rec.Code != http.StatusOK {
+		t.Fatalf("expected 200, got %d", rec.Code)
+	}
+	var runs []RunSummary
+	if err := json.Unmarshal(rec.Body.Bytes(), &runs); err != nil {
+		t.Fatal(err)
+	}
+	if len(runs) != 1 {
+		t.Fatalf("expected 1 run, got %+v", runs)
+	}
+	r := runs[0]
+	if r.ID != "20260101-120000" || r.Completed != 1 || r.Failed != 1 || r.Cost != 0.4 || r.Seconds != 1800 {
+		t.Errorf("unexpected run summary %+v", r)
+	}
+}
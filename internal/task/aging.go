@@ -0,0 +1,59 @@
+package task
+
+// SetPriorityAging makes GetNextTask raise the priority of a task by one level
+// for every loops calls it was eligible but another task was picked, so low
+// priority tasks are not starved by a stream of new P1 tasks. 0 disables aging.
+func (r *Reader) SetPriorityAging(loops int) {
+	r.agingLoops = loops
+	r.waiting = make(map[string]int)
+}
+
+// EffectivePriority returns the priority GetNextTask ranks a task by, which is
+// its own priority raised by aging
+func (r *Reader) EffectivePriority(t *Task) Priority {
+	if r.agingLoops <= 0 {
+		return t.Priority
+	}
+	return t.Priority.raise(r.waiting[t.ID] / r.agingLoops)
+}
+
+// raise returns the priority the given number of levels higher, P1 at most.
+// Unknown priorities are returned unchanged.
+func (p Priority) raise(levels int) Priority {
+	if len(p) != 2 || p[0] != 'P' || p[1] < '1' || p[1] > '9' || levels <= 0 {
+		return p
+	}
+	level := max(int(p[1]-'0')-levels, 1)
+	return Priority([]byte{'P', byte('0' + level)})
+}
+
+// age counts another loop of waiting for the candidates that were not picked.
+// Tasks that are no longer eligible start over.
+func (r *Reader) age(candidates []Task, picked *Task) {
+	if r.agingLoops <= 0 {
+		return
+	}
+	eligible := make(map[string]bool)
+	for _, c := range candidates {
+		if picked != nil && c.ID == picked.ID {
+			continue
+		}
+		eligible[c.ID] = true
+		r.waiting[c.ID]++
+	}
+	for id := range r.waiting {
+		if !eligible[id] {
+			delete(r.waiting, id)
+		}
+	}
+}
+
+// ranksBefore returns true if GetNextTask prefers task a over task b: by
+// effective priority, then the task that waited longer
+func (r *Reader) ranksBefore(a, b *Task) bool {
+	pa, pb := r.EffectivePriority(a), r.EffectivePriority(b)
+	if pa != pb {
+		return pa < pb
+	}
+	return r.waiting[a.ID] > r.waiting[b.ID]
+}
@@ -0,0 +1,93 @@
+package task
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+)
+
+// ArchiveDirName is the subdirectory of .hermes/tasks/ holding archived feature files.
+// GetFeatureFiles only globs the tasks directory itself, so archived features are
+// never parsed during a run.
+const ArchiveDirName = "archive"
+
+// GetArchiveDir returns the directory archived feature files are moved to
+func GetArchiveDir(basePath string) string {
+	return filepath.Join(basePath, ".hermes", "tasks", ArchiveDirName)
+}
+
+// GetArchivedFeatures returns the features in the archive directory
+func (r *Reader) GetArchivedFeatures() ([]Feature, error) {
+	var features []Feature
+	for _, file := range featureFilesIn(filepath.Join(r.tasksDir, ArchiveDirName)) {
+		feature, err := r.ReadFeature(file)
+		if err != nil {
+			continue
+		}
+		features = append(features, *feature)
+	}
+	return features, nil
+}
+
+// IsArchivable returns true if the feature has tasks and all of them are completed
+func (f *Feature) IsArchivable() bool {
+	if len(f.Tasks) == 0 {
+		return false
+	}
+	for _, t := range f.Tasks {
+		if t.Status != StatusCompleted {
+			return false
+		}
+	}
+	return true
+}
+
+// ArchiveFeature moves a fully completed feature file into the archive directory.
+// Dependencies of the remaining tasks on the archived tasks are removed, since they
+// are satisfied for good and would otherwise point at tasks the reader no longer sees.
+// It returns the IDs of the tasks whose dependencies were updated.
+func ArchiveFeature(basePath string, feature *Feature) ([]string, error) {
+	if !feature.IsArchivable() {
+		return nil, fmt.Errorf("feature %s has unfinished tasks", feature.ID)
+	}
+
+	archiveDir := GetArchiveDir(basePath)
+	if err := os.MkdirAll(archiveDir, 0755); err != nil {
+		return nil, err
+	}
+	target := filepath.Join(archiveDir, filepath.Base(feature.FilePath))
+	if _, err := os.Stat(target); err == nil {
+		return nil, fmt.Errorf("%s already exists", target)
+	}
+	if err := os.Rename(feature.FilePath, target); err != nil {
+		return nil, fmt.Errorf("failed to archive %s: %w", feature.ID, err)
+	}
+
+	archived := make(map[string]bool, len(feature.Tasks))
+	for _, t := range feature.Tasks {
+		archived[t.ID] = true
+	}
+
+	tasks, err := NewReader(basePath).GetAllTasks()
+	if err != nil {
+		return nil, err
+	}
+	updater := NewStatusUpdater(basePath)
+	var updated []string
+	for _, t := range tasks {
+		var deps []string
+		for _, dep := range t.Dependencies {
+			if !archived[dep] {
+				deps = append(deps, dep)
+			}
+		}
+		if len(deps) == len(t.Dependencies) {
+			continue
+		}
+		if err := updater.UpdateTaskDependencies(t.ID, deps); err != nil {
+			return updated, fmt.Errorf("failed to update dependencies of %s: %w", t.ID, err)
+		}
+		updated = append(updated, t.ID)
+	}
+	return updated, nil
+}
@@ -0,0 +1,68 @@
+package task
+
+import (
+	"os"
+	"path/filepath"
+)
+
+// BackupSuffix is appended to a task file's name for the copy of its
+// previous version kept by every rewrite
+const BackupSuffix = ".bak"
+
+// writeFileAtomic rewrites a task file so a crash never leaves it half
+// written: the previous version is kept as <file>.bak, then the new content
+// is synced to a temporary file in the same directory and renamed over it.
+func writeFileAtomic(path string, data []byte) error {
+	perm := os.FileMode(0644)
+	if info, err := os.Stat(path); err == nil {
+		perm = info.Mode().Perm()
+		old, err := os.ReadFile(path)
+		if err != nil {
+			return err
+		}
+		if err := replaceFile(path+BackupSuffix, old, perm); err != nil {
+			return err
+		}
+	}
+	return replaceFile(path, data, perm)
+}
+
+// replaceFile writes data to a synced temporary file and renames it to path
+func replaceFile(path string, data []byte, perm os.FileMode) error {
+	dir := filepath.Dir(path)
+	tmp, err := os.CreateTemp(dir, "."+filepath.Base(path)+".tmp-*")
+	if err != nil {
+		return err
+	}
+	tmpPath := tmp.Name()
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		os.Remove(tmpPath)
+		return err
+	}
+	if err := tmp.Sync(); err != nil {
+		tmp.Close()
+		os.Remove(tmpPath)
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		os.Remove(tmpPath)
+		return err
+	}
+	if err := os.Chmod(tmpPath, perm); err != nil {
+		os.Remove(tmpPath)
+		return err
+	}
+	if err := os.Rename(tmpPath, path); err != nil {
+		os.Remove(tmpPath)
+		return err
+	}
+
+	// Persist the rename itself; directories cannot be synced on every
+	// platform, so this is best effort
+	if d, err := os.Open(dir); err == nil {
+		d.Sync()
+		d.Close()
+	}
+	return nil
+}
@@ -0,0 +1,74 @@
+package task
+
+import (
+	"os"
+	"path/filepath"
+	"sync"
+)
+
+// cachedFeature is a parsed feature file and the file version it was parsed from
+type cachedFeature struct {
+	info    os.FileInfo
+	feature *Feature
+}
+
+// The feature cache is shared by all readers, since most callers (the TUI,
+// the dashboard, the run loop) create a new Reader for every query. A file is
+// parsed again only when its modification time, size or inode changes.
+var (
+	featureCacheMu sync.Mutex
+	featureCache   = make(map[string]cachedFeature)
+)
+
+// parseFeatureFile returns the parsed feature file, from the cache when the
+// file has not changed since it was last parsed. Status sidecar entries are
+// not applied.
+func parseFeatureFile(filePath string) (*Feature, error) {
+	info, err := os.Stat(filePath)
+	if err != nil {
+		return nil, err
+	}
+	key, err := filepath.Abs(filePath)
+	if err != nil {
+		key = filePath
+	}
+
+	featureCacheMu.Lock()
+	cached, ok := featureCache[key]
+	featureCacheMu.Unlock()
+	if ok && sameFileVersion(cached.info, info) {
+		return cached.feature.clone(filePath), nil
+	}
+
+	content, err := os.ReadFile(filePath)
+	if err != nil {
+		return nil, err
+	}
+	var feature *Feature
+	if IsYAMLFile(filePath) {
+		feature, err = ParseFeatureYAML(string(content), filePath)
+	} else {
+		feature, err = ParseFeature(string(content), filePath)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	featureCacheMu.Lock()
+	featureCache[key] = cachedFeature{info: info, feature: feature.clone(filePath)}
+	featureCacheMu.Unlock()
+	return feature, nil
+}
+
+// sameFileVersion returns true if two stats describe the same file content
+func sameFileVersion(a, b os.FileInfo) bool {
+	return os.SameFile(a, b) && a.ModTime().Equal(b.ModTime()) && a.Size() == b.Size()
+}
+
+// clone copies a feature so callers can change its tasks without affecting the cache
+func (f *Feature) clone(filePath string) *Feature {
+	c := *f
+	c.FilePath = filePath
+	c.Tasks = append([]Task(nil), f.Tasks...)
+	return &c
+}
@@ -0,0 +1,52 @@
+package task
+
+import "strings"
+
+// CommandCriterionPrefix marks a success criterion Hermes verifies by running
+// a shell command, such as "cmd: go test ./...", instead of trusting the AI
+const CommandCriterionPrefix = "cmd:"
+
+// CriterionCommand returns the command of a command criterion
+func CriterionCommand(criterion string) (string, bool) {
+	c := strings.TrimSpace(criterion)
+	c = strings.TrimPrefix(c, "[ ] ")
+	c = strings.TrimPrefix(c, "[x] ")
+	c, ok := strings.CutPrefix(c, CommandCriterionPrefix)
+	c = strings.TrimSpace(c)
+	return strings.Trim(c, "`"), ok && c != ""
+}
+
+// VerifyCommands returns the commands of the task's command criteria
+func (t *Task) VerifyCommands() []string {
+	var commands []string
+	for _, c := range t.SuccessCriteria {
+		if cmd, ok := CriterionCommand(c); ok {
+			commands = append(commands, cmd)
+		}
+	}
+	return commands
+}
+
+// NoTestCommand as a task's test command skips the configured default
+const NoTestCommand = "none"
+
+// GateCommands returns the commands that must pass before the task is
+// completed: the non-empty checks such as the build and lint commands, its
+// test command, or defaultTest if it has none, and the commands of its command
+// criteria
+func (t *Task) GateCommands(defaultTest string, checks ...string) []string {
+	var commands []string
+	for _, check := range checks {
+		if check = strings.TrimSpace(check); check != "" {
+			commands = append(commands, check)
+		}
+	}
+	test := t.TestCommand
+	if test == "" {
+		test = strings.TrimSpace(defaultTest)
+	}
+	if test != "" && !strings.EqualFold(test, NoTestCommand) {
+		commands = append(commands, test)
+	}
+	return append(commands, t.VerifyCommands()...)
+}
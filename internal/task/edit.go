@@ -0,0 +1,436 @@
+package task
+
+import (
+	"fmt"
+	"os"
+	"regexp"
+	"strings"
+	"time"
+
+	"go.yaml.in/yaml/v3"
+)
+
+// UpdateTaskPriority sets the priority of a task in its feature file
+func (u *StatusUpdater) UpdateTaskPriority(taskID string, priority Priority) error {
+	return u.editTask(taskID, func(lines []string) []string {
+		return setTaskField(lines, "Priority", string(priority))
+	}, func(t *yaml.Node) {
+		setYAMLValue(t, "priority", yamlScalar(string(priority)))
+	})
+}
+
+// SetActualDuration records the wall-clock time a task took in its feature file
+func (u *StatusUpdater) SetActualDuration(taskID string, d time.Duration) error {
+	value := d.Round(time.Second).String()
+	return u.editTask(taskID, func(lines []string) []string {
+		return setTaskField(lines, "Actual Duration", value)
+	}, func(t *yaml.Node) {
+		setYAMLValue(t, "actualDuration", yamlScalar(value))
+	})
+}
+
+// SetBlockedReason records why a task is blocked in its feature file. An
+// empty reason removes it.
+func (u *StatusUpdater) SetBlockedReason(taskID, reason string) error {
+	reason = strings.Join(strings.Fields(reason), " ")
+	return u.editTask(taskID, func(lines []string) []string {
+		if reason == "" {
+			return removeTaskField(lines, "Blocked Reason")
+		}
+		return setTaskField(lines, "Blocked Reason", reason)
+	}, func(t *yaml.Node) {
+		if reason == "" {
+			deleteYAMLValue(t, "blockedReason")
+		} else {
+			setYAMLValue(t, "blockedReason", yamlScalar(reason))
+		}
+	})
+}
+
+// BlockTask marks a task as blocked and records why
+func (u *StatusUpdater) BlockTask(taskID, reason string) error {
+	if err := u.SetBlockedReason(taskID, reason); err != nil {
+		return err
+	}
+	return u.UpdateTaskStatus(taskID, StatusBlocked)
+}
+
+// UpdateTaskDependencies replaces the dependencies of a task in its feature file,
+// keeping whichever format (inline or #### section) the file already uses
+func (u *StatusUpdater) UpdateTaskDependencies(taskID string, deps []string) error {
+	return u.editTask(taskID, func(lines []string) []string {
+		return setTaskDependencies(lines, deps)
+	}, func(t *yaml.Node) {
+		setYAMLValue(t, "dependencies", yamlList(deps))
+	})
+}
+
+// editTask applies edit to the lines of a task's section, or editYAML to the
+// task's mapping in a YAML feature file, and writes the file back
+func (u *StatusUpdater) editTask(taskID string, edit func(lines []string) []string, editYAML func(t *yaml.Node)) error {
+	reader := NewReader(u.basePath)
+	t, err := reader.GetTaskByID(taskID)
+	if err != nil {
+		return err
+	}
+	if t == nil {
+		return fmt.Errorf("task %s not found", taskID)
+	}
+	feature, err := reader.GetFeatureByID(t.FeatureID)
+	if err != nil {
+		return err
+	}
+	if feature == nil {
+		return fmt.Errorf("feature %s not found", t.FeatureID)
+	}
+
+	content, err := os.ReadFile(feature.FilePath)
+	if err != nil {
+		return err
+	}
+
+	// The feature file uses the plain task ID
+	_, taskID = SplitTaskID(t.ID)
+	var updated string
+	ok := false
+	if IsYAMLFile(feature.FilePath) {
+		updated, ok, err = editYAMLTask(string(content), taskID, func(t *yaml.Node) error {
+			editYAML(t)
+			return nil
+		})
+		if err != nil {
+			return fmt.Errorf("failed to edit %s: %w", feature.FilePath, err)
+		}
+	} else {
+		updated, ok = editTaskSection(string(content), taskID, edit)
+	}
+	if !ok {
+		return fmt.Errorf("task %s not found in %s", taskID, feature.FilePath)
+	}
+	return writeFileAtomic(feature.FilePath, []byte(updated))
+}
+
+// editTaskSection applies edit to the lines between a task's header and the next heading
+func editTaskSection(content, taskID string, edit func(lines []string) []string) (string, bool) {
+	lines := strings.Split(content, "\n")
+	taskPattern := regexp.MustCompile(`^###\s*` + regexp.QuoteMeta(taskID) + `:`)
+
+	start := -1
+	for i, line := range lines {
+		if taskPattern.MatchString(line) {
+			start = i
+			break
+		}
+	}
+	if start < 0 {
+		return content, false
+	}
+
+	end := len(lines)
+	for i := start + 1; i < len(lines); i++ {
+		if strings.HasPrefix(lines[i], "### ") || strings.HasPrefix(lines[i], "## ") {
+			end = i
+			break
+		}
+	}
+
+	section := edit(append([]string{}, lines[start:end]...))
+	result := append(append(append([]string{}, lines[:start]...), section...), lines[end:]...)
+	return strings.Join(result, "\n"), true
+}
+
+// setTaskField replaces a **Field:** line, or inserts it after the status line
+func setTaskField(lines []string, field, value string) []string {
+	marker := "**" + field + ":**"
+	line := marker + " " + value
+	for i, l := range lines {
+		if strings.HasPrefix(strings.TrimSpace(l), marker) {
+			lines[i] = line
+			return lines
+		}
+	}
+	return insertAfterMetadata(lines, line)
+}
+
+// removeTaskField removes a **Field:** line
+func removeTaskField(lines []string, field string) []string {
+	marker := "**" + field + ":**"
+	for i, l := range lines {
+		if strings.HasPrefix(strings.TrimSpace(l), marker) {
+			return append(lines[:i], lines[i+1:]...)
+		}
+	}
+	return lines
+}
+
+// setTaskDependencies rewrites the inline **Dependencies:** line or the
+// #### Dependencies list, adding an inline line when neither exists
+func setTaskDependencies(lines []string, deps []string) []string {
+	value := "None"
+	if len(deps) > 0 {
+		value = strings.Join(deps, ", ")
+	}
+
+	for i, l := range lines {
+		if strings.HasPrefix(strings.TrimSpace(l), "**Dependencies:**") {
+			lines[i] = "**Dependencies:** " + value
+			return lines
+		}
+	}
+
+	for i, l := range lines {
+		if !strings.HasPrefix(strings.TrimSpace(l), "#### Dependencies") {
+			continue
+		}
+		// Replace list items up to the next heading or separator
+		end := i + 1
+		for end < len(lines) {
+			trimmed := strings.TrimSpace(lines[end])
+			if strings.HasPrefix(trimmed, "#") || strings.HasPrefix(trimmed, "---") {
+				break
+			}
+			end++
+		}
+		items := []string{""}
+		if len(deps) == 0 {
+			items = append(items, "- None")
+		}
+		for _, d := range deps {
+			items = append(items, "- "+d)
+		}
+		items = append(items, "")
+		result := append(append([]string{}, lines[:i+1]...), items...)
+		return append(result, lines[end:]...)
+	}
+
+	return insertAfterMetadata(lines, "**Dependencies:** "+value)
+}
+
+// insertAfterMetadata inserts line after the last **Key:** line following the task header
+func insertAfterMetadata(lines []string, line string) []string {
+	pos := 1
+	for i := 1; i < len(lines); i++ {
+		trimmed := strings.TrimSpace(lines[i])
+		if strings.HasPrefix(trimmed, "**") {
+			pos = i + 1
+		} else if trimmed != "" {
+			break
+		}
+	}
+	result := append(append([]string{}, lines[:pos]...), line)
+	return append(result, lines[pos:]...)
+}
+
+// FormatTask renders a task as a markdown block in the feature file format
+func FormatTask(t *Task) string {
+	var sb strings.Builder
+	status := t.Status
+	if status == "" {
+		status = StatusNotStarted
+	}
+	priority := t.Priority
+	if priority == "" {
+		priority = PriorityP2
+	}
+
+	fmt.Fprintf(&sb, "### %s: %s\n\n", t.ID, t.Name)
+	fmt.Fprintf(&sb, "**Status:** %s\n", status)
+	fmt.Fprintf(&sb, "**Priority:** %s\n", priority)
+	if t.EstimatedEffort != "" {
+		fmt.Fprintf(&sb, "**Estimated Effort:** %s\n", t.EstimatedEffort)
+	}
+	if t.ActualDuration > 0 {
+		fmt.Fprintf(&sb, "**Actual Duration:** %s\n", t.ActualDuration.Round(time.Second))
+	}
+	if t.PRDSection != "" {
+		fmt.Fprintf(&sb, "**PRD Section:** %s\n", t.PRDSection)
+	}
+	if t.JiraKey != "" {
+		fmt.Fprintf(&sb, "**Jira:** %s\n", t.JiraKey)
+	}
+	if t.BlockedReason != "" {
+		fmt.Fprintf(&sb, "**Blocked Reason:** %s\n", t.BlockedReason)
+	}
+	if t.TestCommand != "" {
+		fmt.Fprintf(&sb, "**Test Command:** `%s`\n", t.TestCommand)
+	}
+	if t.Timeout > 0 {
+		fmt.Fprintf(&sb, "**Timeout:** %s\n", t.Timeout)
+	}
+	if t.Workspace != "" {
+		fmt.Fprintf(&sb, "**Workspace:** %s\n", t.Workspace)
+	}
+	if len(t.Tags) > 0 {
+		fmt.Fprintf(&sb, "**Tags:** %s\n", strings.Join(t.Tags, ", "))
+	}
+
+	description := t.Description
+	if description == "" {
+		description = t.Name
+	}
+	fmt.Fprintf(&sb, "\n#### Description\n\n%s\n", strings.TrimSpace(description))
+	if t.TechnicalDetails != "" {
+		fmt.Fprintf(&sb, "\n#### Technical Details\n\n%s\n", strings.TrimSpace(t.TechnicalDetails))
+	}
+
+	if len(t.FilesToTouch) > 0 {
+		sb.WriteString("\n#### Files to Touch\n\n")
+		for _, f := range t.FilesToTouch {
+			fmt.Fprintf(&sb, "- `%s`\n", strings.Trim(f, "`"))
+		}
+	}
+
+	sb.WriteString("\n#### Dependencies\n\n")
+	if len(t.Dependencies) == 0 {
+		sb.WriteString("- None\n")
+	}
+	for _, d := range t.Dependencies {
+		fmt.Fprintf(&sb, "- %s\n", d)
+	}
+
+	if len(t.SuccessCriteria) > 0 {
+		sb.WriteString("\n#### Success Criteria\n\n")
+		for _, c := range t.SuccessCriteria {
+			c = strings.TrimPrefix(strings.TrimPrefix(c, "[ ] "), "[x] ")
+			fmt.Fprintf(&sb, "- [ ] %s\n", c)
+		}
+	}
+
+	if len(t.Subtasks) > 0 {
+		sb.WriteString("\n#### Subtasks\n\n")
+		for _, s := range t.Subtasks {
+			box := " "
+			if s.Status == StatusCompleted {
+				box = "x"
+			}
+			fmt.Fprintf(&sb, "- [%s] %s: %s\n", box, s.ID, s.Name)
+		}
+	}
+
+	return sb.String()
+}
+
+// ReplaceTask replaces a task's block in its feature file with the given tasks,
+// keeping the separator that followed the original block. A qualified task ID
+// is reduced to the plain ID the file uses.
+func ReplaceTask(filePath, taskID string, tasks []*Task) error {
+	_, taskID = SplitTaskID(taskID)
+	content, err := os.ReadFile(filePath)
+	if err != nil {
+		return err
+	}
+	if IsYAMLFile(filePath) {
+		return replaceYAMLTask(filePath, string(content), taskID, tasks)
+	}
+
+	blocks := make([]string, len(tasks))
+	for i, t := range tasks {
+		blocks[i] = strings.TrimRight(FormatTask(t), "\n")
+	}
+	replacement := strings.Split(strings.Join(blocks, "\n\n---\n\n"), "\n")
+
+	updated, ok := editTaskSection(string(content), taskID, func(lines []string) []string {
+		end := len(lines)
+		for end > 1 {
+			trimmed := strings.TrimSpace(lines[end-1])
+			if trimmed != "" && trimmed != "---" {
+				break
+			}
+			end--
+		}
+		return append(replacement, lines[end:]...)
+	})
+	if !ok {
+		return fmt.Errorf("task %s not found in %s", taskID, filePath)
+	}
+	return writeFileAtomic(filePath, []byte(updated))
+}
+
+// AppendTask adds a task block after the last task of a feature file
+func AppendTask(filePath string, t *Task) error {
+	content, err := os.ReadFile(filePath)
+	if err != nil {
+		return err
+	}
+	if IsYAMLFile(filePath) {
+		return appendYAMLTask(filePath, string(content), t)
+	}
+	updated := appendTaskToContent(string(content), FormatTask(t))
+	return writeFileAtomic(filePath, []byte(updated))
+}
+
+// replaceYAMLTask replaces a task's mapping in a YAML feature file with the given tasks
+func replaceYAMLTask(filePath, content, taskID string, tasks []*Task) error {
+	nodes, err := yamlTaskNodes(tasks)
+	if err != nil {
+		return err
+	}
+	updated, ok, err := editYAML(content, func(root *yaml.Node) (bool, error) {
+		seq, i := findYAMLTask(root, taskID)
+		if seq == nil {
+			return false, nil
+		}
+		seq.Content = append(seq.Content[:i], append(nodes, seq.Content[i+1:]...)...)
+		return true, nil
+	})
+	if err != nil {
+		return fmt.Errorf("failed to edit %s: %w", filePath, err)
+	}
+	if !ok {
+		return fmt.Errorf("task %s not found in %s", taskID, filePath)
+	}
+	return writeFileAtomic(filePath, []byte(updated))
+}
+
+// appendYAMLTask adds a task to the end of a YAML feature file's task list
+func appendYAMLTask(filePath, content string, t *Task) error {
+	nodes, err := yamlTaskNodes([]*Task{t})
+	if err != nil {
+		return err
+	}
+	updated, _, err := editYAML(content, func(root *yaml.Node) (bool, error) {
+		seq := yamlValue(root, "tasks")
+		if seq == nil || seq.Kind != yaml.SequenceNode {
+			seq = &yaml.Node{Kind: yaml.SequenceNode, Tag: "!!seq"}
+			setYAMLValue(root, "tasks", seq)
+		}
+		seq.Style = 0
+		seq.Content = append(seq.Content, nodes...)
+		return true, nil
+	})
+	if err != nil {
+		return fmt.Errorf("failed to edit %s: %w", filePath, err)
+	}
+	return writeFileAtomic(filePath, []byte(updated))
+}
+
+func appendTaskToContent(content, block string) string {
+	// Insert before the first level-2 heading after the last task,
+	// or before the heading following "## Tasks" when there are none yet
+	insertAt := len(content)
+	searchFrom := -1
+	if matches := taskHeaderRegex.FindAllStringIndex(content, -1); len(matches) > 0 {
+		searchFrom = matches[len(matches)-1][1]
+	} else if loc := regexp.MustCompile(`(?m)^## Tasks\s*$`).FindStringIndex(content); loc != nil {
+		searchFrom = loc[1]
+	}
+
+	if searchFrom < 0 {
+		return strings.TrimRight(content, "\n") + "\n\n## Tasks\n\n" + block
+	}
+	if loc := regexp.MustCompile(`(?m)^## `).FindStringIndex(content[searchFrom:]); loc != nil {
+		insertAt = searchFrom + loc[0]
+	}
+
+	before := strings.TrimRight(content[:insertAt], "\n")
+	after := content[insertAt:]
+
+	if taskHeaderRegex.MatchString(before) && !strings.HasSuffix(before, "---") {
+		before += "\n\n---"
+	}
+	result := before + "\n\n" + block
+	if after != "" {
+		result += "\n" + after
+	}
+	return result
+}
@@ -0,0 +1,164 @@
+package task
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"go.yaml.in/yaml/v3"
+)
+
+// frontmatterDelimiter opens and closes the YAML frontmatter of a markdown feature file
+const frontmatterDelimiter = "---"
+
+// Frontmatter is the machine-managed metadata at the top of a markdown
+// feature file. Its values take precedence over the **Field:** lines of the
+// markdown body, which stays free for humans to edit.
+//
+//	---
+//	id: F001
+//	status: IN_PROGRESS
+//	updated: 2025-01-02T15:04:05Z
+//	tasks:
+//	  T001: {status: COMPLETED, updated: 2025-01-02T15:04:05Z}
+//	  T002: {status: IN_PROGRESS, retries: 1}
+//	---
+type Frontmatter struct {
+	ID       string               `yaml:"id,omitempty"`
+	Status   Status               `yaml:"status,omitempty"`
+	Priority Priority             `yaml:"priority,omitempty"`
+	Source   string               `yaml:"source,omitempty"`
+	Updated  time.Time            `yaml:"updated,omitempty"`
+	Tasks    map[string]*TaskMeta `yaml:"tasks,omitempty"`
+}
+
+// TaskMeta is the frontmatter metadata of a single task
+type TaskMeta struct {
+	Status   Status    `yaml:"status,omitempty"`
+	Priority Priority  `yaml:"priority,omitempty"`
+	Updated  time.Time `yaml:"updated,omitempty"`
+	Retries  int       `yaml:"retries,omitempty"`
+}
+
+// splitFrontmatter splits a markdown feature file into its frontmatter and
+// body. ok is false when the file has no frontmatter.
+func splitFrontmatter(content string) (meta, body string, ok bool) {
+	first, rest, found := strings.Cut(content, "\n")
+	if !found || strings.TrimSpace(first) != frontmatterDelimiter {
+		return "", content, false
+	}
+	offset := 0
+	for offset <= len(rest) {
+		line, next, more := strings.Cut(rest[offset:], "\n")
+		if strings.TrimSpace(line) == frontmatterDelimiter {
+			return rest[:offset], next, true
+		}
+		if !more {
+			break
+		}
+		offset += len(line) + 1
+	}
+	return "", content, false
+}
+
+// parseFrontmatter parses the frontmatter of a markdown feature file
+func parseFrontmatter(meta string) (*Frontmatter, error) {
+	var fm Frontmatter
+	if err := yaml.Unmarshal([]byte(meta), &fm); err != nil {
+		return nil, fmt.Errorf("invalid frontmatter: %w", err)
+	}
+	if fm.ID != "" && !strings.HasPrefix(fm.ID, "F") {
+		fm.ID = "F" + fm.ID
+	}
+	return &fm, nil
+}
+
+// apply overlays frontmatter values onto a feature parsed from the markdown body
+func (fm *Frontmatter) apply(feature *Feature) {
+	if fm.ID != "" {
+		feature.ID = fm.ID
+	}
+	if fm.Status != "" {
+		feature.Status = fm.Status
+	}
+	if fm.Priority != "" {
+		feature.Priority = fm.Priority
+	}
+	if fm.Source != "" {
+		feature.Source = fm.Source
+	}
+	for i := range feature.Tasks {
+		t := &feature.Tasks[i]
+		t.FeatureID = feature.ID
+		m := fm.Tasks[t.ID]
+		if m == nil {
+			continue
+		}
+		if m.Status != "" {
+			t.Status = m.Status
+		}
+		if m.Priority != "" {
+			t.Priority = m.Priority
+		}
+		t.UpdatedAt = m.Updated
+		t.Retries = m.Retries
+	}
+}
+
+// editFrontmatter applies edit to the frontmatter mapping of a markdown
+// feature file, leaving the body untouched. ok is false when the file has no
+// frontmatter.
+func editFrontmatter(content string, edit func(root *yaml.Node) error) (string, bool, error) {
+	meta, body, ok := splitFrontmatter(content)
+	if !ok {
+		return content, false, nil
+	}
+	if strings.TrimSpace(meta) == "" {
+		meta = "{}\n"
+	}
+	updated, _, err := editYAML(meta, func(root *yaml.Node) (bool, error) {
+		root.Style = 0
+		return true, edit(root)
+	})
+	if err != nil {
+		return "", true, err
+	}
+	head := frontmatterDelimiter + "\n" + updated + frontmatterDelimiter + "\n"
+	if strings.Contains(meta, "\r\n") {
+		head = strings.ReplaceAll(head, "\n", "\r\n")
+	}
+	return head + body, true, nil
+}
+
+// setFrontmatterStatus records a feature or task status and its time in the
+// frontmatter. A task moved back to IN_PROGRESS after it was started before
+// counts as a retry.
+func setFrontmatterStatus(content, id string, status Status, isTask bool) (string, bool, error) {
+	now := &yaml.Node{Kind: yaml.ScalarNode, Tag: "!!timestamp", Value: time.Now().UTC().Format(time.RFC3339)}
+	return editFrontmatter(content, func(root *yaml.Node) error {
+		target := root
+		if isTask {
+			tasks := yamlValue(root, "tasks")
+			if tasks == nil || tasks.Kind != yaml.MappingNode {
+				tasks = &yaml.Node{Kind: yaml.MappingNode, Tag: "!!map"}
+				setYAMLValue(root, "tasks", tasks)
+			}
+			target = yamlValue(tasks, id)
+			if target == nil || target.Kind != yaml.MappingNode {
+				target = &yaml.Node{Kind: yaml.MappingNode, Tag: "!!map", Style: yaml.FlowStyle}
+				setYAMLValue(tasks, id, target)
+			}
+			if prev := yamlValue(target, "status"); status == StatusInProgress && prev != nil && Status(prev.Value) != StatusNotStarted {
+				retries := 0
+				if r := yamlValue(target, "retries"); r != nil {
+					retries, _ = strconv.Atoi(r.Value)
+				}
+				setYAMLValue(target, "retries", &yaml.Node{Kind: yaml.ScalarNode, Tag: "!!int", Value: strconv.Itoa(retries + 1)})
+			}
+		}
+		setYAMLValue(target, "status", yamlScalar(string(status)))
+		setYAMLValue(target, "updated", now)
+		return nil
+	})
+}
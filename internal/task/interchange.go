@@ -0,0 +1,239 @@
+package task
+
+import (
+	"encoding/json"
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+var (
+	featureIDFormat = regexp.MustCompile(`^F\d{3,}$`)
+	taskIDFormat    = regexp.MustCompile(`^T\d{3,}$`)
+	jsonFenceRegex  = regexp.MustCompile("(?s)```(?:json)?\\s*\\n(.*?)```")
+)
+
+// Plan is the JSON interchange document: the features of a PRD or of a
+// single 'hermes add', each with its tasks
+type Plan struct {
+	Features []Feature `json:"features"`
+}
+
+// ValidationError lists everything wrong with a plan
+type ValidationError struct {
+	Problems []string
+}
+
+func (e *ValidationError) Error() string {
+	return fmt.Sprintf("invalid task plan:\n  - %s", strings.Join(e.Problems, "\n  - "))
+}
+
+// FormatPlanJSON renders features as an indented JSON plan
+func FormatPlanJSON(features []Feature) ([]byte, error) {
+	return json.MarshalIndent(Plan{Features: features}, "", "  ")
+}
+
+// ParsePlanJSON extracts a JSON plan from AI output, which may wrap it in a
+// code fence or surround it with prose, and validates it. Dependencies must
+// name a task of the plan or one of the known task IDs. Missing statuses and
+// priorities get their defaults.
+func ParsePlanJSON(output string, known map[string]bool) ([]Feature, error) {
+	data := extractJSON(output)
+	if data == "" {
+		return nil, fmt.Errorf("output does not contain a JSON plan")
+	}
+
+	var plan Plan
+	dec := json.NewDecoder(strings.NewReader(data))
+	dec.DisallowUnknownFields()
+	if strings.HasPrefix(data, "[") {
+		err := dec.Decode(&plan.Features)
+		if err != nil {
+			return nil, fmt.Errorf("invalid JSON plan: %w", err)
+		}
+	} else if err := dec.Decode(&plan); err != nil {
+		// A single feature object is accepted as well
+		var feature Feature
+		single := json.NewDecoder(strings.NewReader(data))
+		single.DisallowUnknownFields()
+		if single.Decode(&feature) != nil || feature.ID == "" {
+			return nil, fmt.Errorf("invalid JSON plan: %w", err)
+		}
+		plan.Features = []Feature{feature}
+	}
+
+	if err := ValidatePlan(plan.Features, known); err != nil {
+		return nil, err
+	}
+	return plan.Features, nil
+}
+
+// extractJSON returns the JSON document in s: the first fenced block, or the
+// text from the first opening to the last closing bracket
+func extractJSON(s string) string {
+	if m := jsonFenceRegex.FindStringSubmatch(s); m != nil {
+		s = m[1]
+	}
+	start := strings.IndexAny(s, "{[")
+	if start < 0 {
+		return ""
+	}
+	closing := "}"
+	if s[start] == '[' {
+		closing = "]"
+	}
+	end := strings.LastIndex(s, closing)
+	if end < start {
+		return ""
+	}
+	return strings.TrimSpace(s[start : end+1])
+}
+
+// ValidatePlan checks IDs, statuses, priorities and dependencies of features
+// and fills in default statuses and priorities
+func ValidatePlan(features []Feature, known map[string]bool) error {
+	var problems []string
+	if len(features) == 0 {
+		problems = append(problems, "plan has no features")
+	}
+
+	taskIDs := make(map[string]bool)
+	featureIDs := make(map[string]bool)
+	for fi := range features {
+		for _, t := range features[fi].Tasks {
+			taskIDs[t.ID] = true
+		}
+	}
+
+	for fi := range features {
+		f := &features[fi]
+		where := fmt.Sprintf("feature %d", fi+1)
+		if f.ID != "" {
+			where = "feature " + f.ID
+		}
+		switch {
+		case !featureIDFormat.MatchString(f.ID):
+			problems = append(problems, fmt.Sprintf("%s: id %q must look like F001", where, f.ID))
+		case featureIDs[f.ID]:
+			problems = append(problems, fmt.Sprintf("%s: duplicate feature id", where))
+		}
+		featureIDs[f.ID] = true
+		if strings.TrimSpace(f.Name) == "" {
+			problems = append(problems, where+": name is empty")
+		}
+		if len(f.Tasks) == 0 {
+			problems = append(problems, where+": has no tasks")
+		}
+		problems = append(problems, checkStatusAndPriority(where, &f.Status, &f.Priority)...)
+
+		seen := make(map[string]bool)
+		for ti := range f.Tasks {
+			t := &f.Tasks[ti]
+			t.FeatureID = f.ID
+			tw := fmt.Sprintf("%s task %d", where, ti+1)
+			if t.ID != "" {
+				tw = "task " + t.ID
+			}
+			switch {
+			case !taskIDFormat.MatchString(t.ID):
+				problems = append(problems, fmt.Sprintf("%s: id %q must look like T001", tw, t.ID))
+			case seen[t.ID] || known[t.ID]:
+				problems = append(problems, fmt.Sprintf("%s: duplicate task id", tw))
+			}
+			seen[t.ID] = true
+			if strings.TrimSpace(t.Name) == "" {
+				problems = append(problems, tw+": name is empty")
+			}
+			problems = append(problems, checkStatusAndPriority(tw, &t.Status, &t.Priority)...)
+			for _, dep := range append(append([]string{}, t.Dependencies...), t.DependsOn...) {
+				_, dep := SplitTaskID(dep)
+				if dep == t.ID {
+					problems = append(problems, fmt.Sprintf("%s: depends on itself", tw))
+				} else if !taskIDs[dep] && !known[dep] {
+					problems = append(problems, fmt.Sprintf("%s: unknown dependency %q", tw, dep))
+				}
+			}
+		}
+	}
+
+	if len(problems) > 0 {
+		return &ValidationError{Problems: problems}
+	}
+	return nil
+}
+
+// checkStatusAndPriority defaults an empty status and priority and reports invalid ones
+func checkStatusAndPriority(where string, status *Status, priority *Priority) []string {
+	var problems []string
+	if *status == "" {
+		*status = StatusNotStarted
+	} else if !validStatus(*status) {
+		problems = append(problems, fmt.Sprintf("%s: unknown status %q", where, *status))
+	}
+	switch *priority {
+	case "":
+		*priority = PriorityP2
+	case PriorityP1, PriorityP2, PriorityP3, PriorityP4:
+	default:
+		problems = append(problems, fmt.Sprintf("%s: priority %q must be P1-P4", where, *priority))
+	}
+	return problems
+}
+
+// FeatureNumber returns the number of a feature ID, e.g. 3 for "F003"
+func FeatureNumber(featureID string) int {
+	n, _ := strconv.Atoi(strings.TrimPrefix(featureID, "F"))
+	return n
+}
+
+// FormatFeature renders a feature and its tasks in the markdown feature file format
+func FormatFeature(f *Feature) string {
+	var sb strings.Builder
+	fmt.Fprintf(&sb, "# Feature %d: %s\n\n", FeatureNumber(f.ID), f.Name)
+	fmt.Fprintf(&sb, "**Feature ID:** %s\n", f.ID)
+	fmt.Fprintf(&sb, "**Priority:** %s\n", f.Priority)
+	if f.TargetVersion != "" {
+		fmt.Fprintf(&sb, "**Target Version:** %s\n", f.TargetVersion)
+	}
+	if f.EstimatedDuration != "" {
+		fmt.Fprintf(&sb, "**Estimated Duration:** %s\n", f.EstimatedDuration)
+	}
+	fmt.Fprintf(&sb, "**Status:** %s\n", f.Status)
+	if f.Source != "" {
+		fmt.Fprintf(&sb, "**Source:** %s\n", f.Source)
+	}
+	if len(f.Tags) > 0 {
+		fmt.Fprintf(&sb, "**Tags:** %s\n", strings.Join(f.Tags, ", "))
+	}
+
+	overview := f.Overview
+	if overview == "" {
+		overview = f.Description
+	}
+	if overview != "" {
+		fmt.Fprintf(&sb, "\n## Overview\n\n%s\n", strings.TrimSpace(overview))
+	}
+	if len(f.Goals) > 0 {
+		sb.WriteString("\n## Goals\n\n")
+		for _, g := range f.Goals {
+			fmt.Fprintf(&sb, "- %s\n", g)
+		}
+	}
+
+	sb.WriteString("\n## Tasks\n\n")
+	blocks := make([]string, len(f.Tasks))
+	for i := range f.Tasks {
+		blocks[i] = strings.TrimRight(FormatTask(&f.Tasks[i]), "\n")
+	}
+	sb.WriteString(strings.Join(blocks, "\n\n---\n\n"))
+	sb.WriteString("\n")
+
+	if f.PerformanceTarget != "" {
+		fmt.Fprintf(&sb, "\n## Performance Targets\n\n%s\n", strings.TrimSpace(f.PerformanceTarget))
+	}
+	if f.RiskAssessment != "" {
+		fmt.Fprintf(&sb, "\n## Risk Assessment\n\n%s\n", strings.TrimSpace(f.RiskAssessment))
+	}
+	return sb.String()
+}
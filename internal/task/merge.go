@@ -0,0 +1,290 @@
+package task
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+)
+
+var (
+	taskIDTokenRegex   = regexp.MustCompile(`\bT\d+\b`)
+	featureNumberRegex = regexp.MustCompile(`(?m)^(#\s*Feature\s*)\d+(:)`)
+)
+
+// MergePlan describes how re-parsed features are merged into the existing
+// task files. Existing features and tasks are matched by name and are never
+// rewritten, so their statuses are preserved.
+type MergePlan struct {
+	NewFeatures []PlannedFeature
+	NewTasks    []PlannedTask
+	Kept        int      // Tasks that already exist
+	Warnings    []string // Items that could not be merged
+}
+
+// PlannedFeature is a feature that does not exist yet
+type PlannedFeature struct {
+	ID       string
+	Name     string
+	FileName string
+	Content  string // Feature file content with IDs renumbered
+	Tasks    int
+}
+
+// PlannedTask is a task added to an existing feature
+type PlannedTask struct {
+	Feature *Feature
+	Task    *Task
+}
+
+// IsEmpty returns true if the plan adds nothing
+func (p *MergePlan) IsEmpty() bool {
+	return len(p.NewFeatures) == 0 && len(p.NewTasks) == 0
+}
+
+// PlanMerge matches parsed feature files against the existing features and
+// assigns new feature and task IDs starting at nextFeatureID and nextTaskID
+func PlanMerge(basePath string, contents []string, nextFeatureID, nextTaskID int) (*MergePlan, error) {
+	reader := NewReader(basePath)
+	existing, err := reader.GetAllFeatures()
+	if err != nil {
+		return nil, err
+	}
+	archived, _ := reader.GetArchivedFeatures()
+
+	byName := make(map[string]*Feature)
+	for i := range existing {
+		byName[normalizeName(existing[i].Name)] = &existing[i]
+	}
+	archivedByName := make(map[string]*Feature)
+	for i := range archived {
+		archivedByName[normalizeName(archived[i].Name)] = &archived[i]
+	}
+	known := make(map[string]bool)
+	for _, f := range append(append([]Feature{}, existing...), archived...) {
+		for _, t := range f.Tasks {
+			known[t.ID] = true
+		}
+	}
+
+	type generated struct {
+		feature *Feature
+		content string
+		isNew   []bool // Per task, whether it does not exist yet
+	}
+
+	plan := &MergePlan{}
+	var parsed []*generated
+	for _, content := range contents {
+		f, err := ParseFeature(content, "")
+		if err != nil || f.Name == "" {
+			continue
+		}
+		parsed = append(parsed, &generated{feature: f, content: content})
+	}
+
+	// First pass: map every generated task ID to its final ID
+	ids := make(map[string]string)
+	for _, g := range parsed {
+		f := g.feature
+		match := byName[normalizeName(f.Name)]
+		if match == nil {
+			match = archivedByName[normalizeName(f.Name)]
+		}
+		g.isNew = make([]bool, len(f.Tasks))
+		for i, t := range f.Tasks {
+			if match != nil {
+				if old := findTaskByName(match.Tasks, t.Name); old != nil {
+					ids[t.ID] = old.ID
+					plan.Kept++
+					continue
+				}
+			}
+			ids[t.ID] = fmt.Sprintf("T%03d", nextTaskID)
+			nextTaskID++
+			g.isNew[i] = true
+		}
+	}
+
+	remap := func(deps []string) []string {
+		var result []string
+		for _, dep := range deps {
+			if id, ok := ids[dep]; ok {
+				result = append(result, id)
+			} else if known[dep] {
+				result = append(result, dep)
+			}
+		}
+		return result
+	}
+
+	// Second pass: plan new tasks and features
+	for _, g := range parsed {
+		f := g.feature
+		name := normalizeName(f.Name)
+		if match := byName[name]; match != nil {
+			for i := range f.Tasks {
+				if !g.isNew[i] {
+					continue
+				}
+				t := f.Tasks[i]
+				t.ID = ids[t.ID]
+				t.FeatureID = match.ID
+				t.Status = StatusNotStarted
+				t.Dependencies = remap(t.Dependencies)
+				plan.NewTasks = append(plan.NewTasks, PlannedTask{Feature: match, Task: &t})
+			}
+			continue
+		}
+		if match := archivedByName[name]; match != nil {
+			for i := range f.Tasks {
+				if g.isNew[i] {
+					plan.Warnings = append(plan.Warnings, fmt.Sprintf("%s is archived, task %q was not added", match.ID, f.Tasks[i].Name))
+				}
+			}
+			continue
+		}
+
+		featureID := fmt.Sprintf("F%03d", nextFeatureID)
+		content := taskIDTokenRegex.ReplaceAllStringFunc(g.content, func(id string) string {
+			if mapped, ok := ids[id]; ok {
+				return mapped
+			}
+			return id
+		})
+		content = featureIDRegex.ReplaceAllLiteralString(content, "**Feature ID:** "+featureID)
+		content = featureNumberRegex.ReplaceAllString(content, fmt.Sprintf("${1}%d${2}", nextFeatureID))
+		plan.NewFeatures = append(plan.NewFeatures, PlannedFeature{
+			ID:       featureID,
+			Name:     f.Name,
+			FileName: FeatureFileName(nextFeatureID, f.Name),
+			Content:  content,
+			Tasks:    len(f.Tasks),
+		})
+		nextFeatureID++
+	}
+
+	return plan, nil
+}
+
+// ApplyMerge writes the planned features and appends the planned tasks.
+// It returns the paths of the files that were created or changed.
+func ApplyMerge(basePath string, plan *MergePlan) ([]string, error) {
+	tasksDir := filepath.Join(basePath, ".hermes", "tasks")
+	if err := os.MkdirAll(tasksDir, 0755); err != nil {
+		return nil, err
+	}
+
+	var files []string
+	for _, f := range plan.NewFeatures {
+		path := filepath.Join(tasksDir, f.FileName)
+		if _, err := os.Stat(path); err == nil {
+			return files, fmt.Errorf("%s already exists", path)
+		}
+		if err := os.WriteFile(path, []byte(strings.TrimSpace(f.Content)+"\n"), 0644); err != nil {
+			return files, err
+		}
+		files = append(files, path)
+	}
+
+	updater := NewStatusUpdater(basePath)
+	reopened := make(map[string]bool)
+	for _, p := range plan.NewTasks {
+		if err := AppendTask(p.Feature.FilePath, p.Task); err != nil {
+			return files, err
+		}
+		if !containsString(files, p.Feature.FilePath) {
+			files = append(files, p.Feature.FilePath)
+		}
+		// New tasks reopen a completed feature
+		if p.Feature.Status == StatusCompleted && !reopened[p.Feature.ID] {
+			if err := updater.UpdateFeatureStatus(p.Feature.ID, StatusInProgress); err != nil {
+				return files, err
+			}
+			reopened[p.Feature.ID] = true
+		}
+	}
+	return files, nil
+}
+
+// FeatureFileName returns the file name for a feature, e.g. "003-user-auth.md"
+func FeatureFileName(featureID int, name string) string {
+	var sb strings.Builder
+	for _, r := range strings.ReplaceAll(strings.ToLower(name), " ", "-") {
+		if (r >= 'a' && r <= 'z') || (r >= '0' && r <= '9') || r == '-' {
+			sb.WriteRune(r)
+		}
+	}
+	safeName := sb.String()
+	if len(safeName) > 30 {
+		safeName = safeName[:30]
+	}
+	return fmt.Sprintf("%03d-%s.md", featureID, safeName)
+}
+
+// KeepTaskIDs renumbers the tasks of a regenerated feature file that match a
+// task of its previous version, by name or else as the only task of the same
+// PRD section, back to the previous ID so dependencies on them keep working
+func KeepTaskIDs(content string, previous []Task) string {
+	f, err := ParseFeature(content, "")
+	if err != nil {
+		return content
+	}
+
+	ids := make(map[string]string)
+	claimed := make(map[string]bool)
+	keep := func(t Task, old *Task) {
+		if old != nil && !claimed[old.ID] {
+			ids[t.ID] = old.ID
+			claimed[old.ID] = true
+		}
+	}
+	for _, t := range f.Tasks {
+		keep(t, findTaskByName(previous, t.Name))
+	}
+	for _, t := range f.Tasks {
+		if _, ok := ids[t.ID]; ok || t.PRDSection == "" {
+			continue
+		}
+		var candidates []*Task
+		for i := range previous {
+			if !claimed[previous[i].ID] && normalizeName(previous[i].PRDSection) == normalizeName(t.PRDSection) {
+				candidates = append(candidates, &previous[i])
+			}
+		}
+		if len(candidates) == 1 {
+			keep(t, candidates[0])
+		}
+	}
+
+	return taskIDTokenRegex.ReplaceAllStringFunc(content, func(id string) string {
+		if mapped, ok := ids[id]; ok {
+			return mapped
+		}
+		return id
+	})
+}
+
+func findTaskByName(tasks []Task, name string) *Task {
+	name = normalizeName(name)
+	for i := range tasks {
+		if normalizeName(tasks[i].Name) == name {
+			return &tasks[i]
+		}
+	}
+	return nil
+}
+
+func normalizeName(name string) string {
+	return strings.ToLower(strings.Join(strings.Fields(name), " "))
+}
+
+func containsString(list []string, s string) bool {
+	for _, item := range list {
+		if item == s {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,80 @@
+package task
+
+import (
+	"strconv"
+	"strings"
+)
+
+// CompareVersions compares two versions such as "v1.2.0" and "1.10", returning
+// -1, 0 or 1. Missing components count as 0 and a pre-release such as
+// "1.0.0-rc1" comes before its release.
+func CompareVersions(a, b string) int {
+	a, preA, _ := strings.Cut(strings.TrimPrefix(strings.TrimSpace(a), "v"), "-")
+	b, preB, _ := strings.Cut(strings.TrimPrefix(strings.TrimSpace(b), "v"), "-")
+	partsA, partsB := strings.Split(a, "."), strings.Split(b, ".")
+	for i := 0; i < max(len(partsA), len(partsB)); i++ {
+		var numA, numB int
+		if i < len(partsA) {
+			numA, _ = strconv.Atoi(partsA[i])
+		}
+		if i < len(partsB) {
+			numB, _ = strconv.Atoi(partsB[i])
+		}
+		if numA != numB {
+			if numA < numB {
+				return -1
+			}
+			return 1
+		}
+	}
+	switch {
+	case preA == preB:
+		return 0
+	case preA == "":
+		return 1
+	case preB == "":
+		return -1
+	}
+	return strings.Compare(preA, preB)
+}
+
+// InMilestone returns true if the feature targets the version or an earlier one
+func (f *Feature) InMilestone(version string) bool {
+	return f.TargetVersion != "" && CompareVersions(f.TargetVersion, version) <= 0
+}
+
+// SetMilestone limits GetNextTask to tasks of features whose target version
+// is the given version or an earlier one. An empty version removes the limit.
+func (r *Reader) SetMilestone(version string) {
+	r.milestone = version
+}
+
+// Milestone returns the version set with SetMilestone
+func (r *Reader) Milestone() string {
+	return r.milestone
+}
+
+// FilterMilestone returns the tasks of features in the milestone, along with
+// the completed tasks of other features that tasks may depend on. Without a
+// milestone the tasks are returned unchanged.
+func (r *Reader) FilterMilestone(tasks []Task) ([]Task, error) {
+	if r.milestone == "" {
+		return tasks, nil
+	}
+	features, err := r.GetAllFeatures()
+	if err != nil {
+		return nil, err
+	}
+	inMilestone := make(map[string]bool)
+	for i := range features {
+		inMilestone[features[i].ID] = features[i].InMilestone(r.milestone)
+	}
+
+	var filtered []Task
+	for _, t := range tasks {
+		if inMilestone[t.FeatureID] || t.Status == StatusCompleted {
+			filtered = append(filtered, t)
+		}
+	}
+	return filtered, nil
+}
@@ -4,6 +4,7 @@ import (
 	"fmt"
 	"regexp"
 	"strings"
+	"time"
 )
 
 var (
@@ -17,10 +18,29 @@ var (
 	targetVersionRegex    = regexp.MustCompile(`\*\*Target Version:\*\*\s*(.+)`)
 	estimatedDurationRegex = regexp.MustCompile(`\*\*Estimated Duration:\*\*\s*(.+)`)
 	estimatedEffortRegex  = regexp.MustCompile(`\*\*Estimated Effort:\*\*\s*(.+)`)
+	actualDurationRegex   = regexp.MustCompile(`(?m)^\*\*Actual Duration:\*\*\s*(\S+)`)
+	prdSectionRegex       = regexp.MustCompile(`(?m)^\*\*PRD Section:\*\*\s*(.+)$`)
+	featureSourceRegex    = regexp.MustCompile(`(?m)^\*\*Source:\*\*\s*(.+)$`)
+	jiraKeyRegex          = regexp.MustCompile(`(?m)^\*\*Jira:\*\*\s*([A-Z][A-Z0-9]*-\d+)`)
+	blockedReasonRegex    = regexp.MustCompile(`(?m)^\*\*Blocked Reason:\*\*\s*(.+)$`)
+	testCommandRegex      = regexp.MustCompile(`(?m)^\*\*Test Command:\*\*\s*(.+)$`)
+	timeoutRegex          = regexp.MustCompile(`(?m)^\*\*Timeout:\*\*\s*(.+)$`)
+	workspaceRegex        = regexp.MustCompile(`(?m)^\*\*Workspace:\*\*\s*(.+)$`)
+	tagsRegex             = regexp.MustCompile(`(?m)^\*\*Tags:\*\*\s*(.+)$`)
 )
 
-// ParseFeature parses a feature file content
+// ParseFeature parses a feature file content. Values from a YAML frontmatter
+// block take precedence over the markdown body.
 func ParseFeature(content, filePath string) (*Feature, error) {
+	var fm *Frontmatter
+	if meta, body, ok := splitFrontmatter(content); ok {
+		var err error
+		if fm, err = parseFrontmatter(meta); err != nil {
+			return nil, fmt.Errorf("%s: %w", filePath, err)
+		}
+		content = body
+	}
+
 	feature := &Feature{
 		FilePath: filePath,
 		Status:   StatusNotStarted,
@@ -61,6 +81,20 @@ func ParseFeature(content, filePath string) (*Feature, error) {
 		feature.EstimatedDuration = strings.TrimSpace(m[1])
 	}
 
+	// Parse source document (**Source:** path), written by 'hermes prd split'
+	if m := featureSourceRegex.FindStringSubmatch(content); len(m) > 1 {
+		feature.Source = strings.TrimSpace(m[1])
+	}
+
+	// Parse feature tags, from the header only as tasks have their own
+	header := content
+	if loc := taskHeaderRegex.FindStringIndex(content); loc != nil {
+		header = content[:loc[0]]
+	}
+	if m := tagsRegex.FindStringSubmatch(header); len(m) > 1 {
+		feature.Tags = parseTags(m[1])
+	}
+
 	// Parse overview section
 	feature.Overview = parseSection(content, "## Overview")
 
@@ -76,6 +110,10 @@ func ParseFeature(content, filePath string) (*Feature, error) {
 	// Parse tasks
 	feature.Tasks = parseTasks(content, feature.ID)
 
+	if fm != nil {
+		fm.apply(feature)
+	}
+
 	return feature, nil
 }
 
@@ -140,6 +178,20 @@ func parseListSection(content, header string) []string {
 	return items
 }
 
+// ParseTaskBlock parses a single task block such as one generated by AI
+func ParseTaskBlock(content, featureID string) (*Task, error) {
+	tasks := parseTasks(content, featureID)
+	if len(tasks) == 0 {
+		return nil, fmt.Errorf("no task header found")
+	}
+	return &tasks[0], nil
+}
+
+// ParseTaskBlocks parses every task block in content, such as a set of tasks generated by AI
+func ParseTaskBlocks(content, featureID string) []Task {
+	return parseTasks(content, featureID)
+}
+
 func parseTasks(content, featureID string) []Task {
 	var tasks []Task
 
@@ -181,6 +233,30 @@ func parseTasks(content, featureID string) []Task {
 		if m := estimatedEffortRegex.FindStringSubmatch(taskContent); len(m) > 1 {
 			task.EstimatedEffort = strings.TrimSpace(m[1])
 		}
+		if m := blockedReasonRegex.FindStringSubmatch(taskContent); len(m) > 1 {
+			task.BlockedReason = strings.TrimSpace(m[1])
+		}
+		if m := testCommandRegex.FindStringSubmatch(taskContent); len(m) > 1 {
+			task.TestCommand = strings.Trim(strings.TrimSpace(m[1]), "`")
+		}
+		if m := timeoutRegex.FindStringSubmatch(taskContent); len(m) > 1 {
+			task.Timeout, _ = ParseTimeout(m[1])
+		}
+		if m := workspaceRegex.FindStringSubmatch(taskContent); len(m) > 1 {
+			task.Workspace = CleanWorkspace(m[1])
+		}
+		if m := tagsRegex.FindStringSubmatch(taskContent); len(m) > 1 {
+			task.Tags = parseTags(m[1])
+		}
+		if m := actualDurationRegex.FindStringSubmatch(taskContent); len(m) > 1 {
+			task.ActualDuration, _ = time.ParseDuration(m[1])
+		}
+		if m := prdSectionRegex.FindStringSubmatch(taskContent); len(m) > 1 {
+			task.PRDSection = strings.TrimSpace(m[1])
+		}
+		if m := jiraKeyRegex.FindStringSubmatch(taskContent); len(m) > 1 {
+			task.JiraKey = m[1]
+		}
 		// Parse files to touch (both inline and section formats)
 		if m := filesToTouchRegex.FindStringSubmatch(taskContent); len(m) > 1 {
 			task.FilesToTouch = parseCommaSeparated(m[1])
@@ -209,6 +285,8 @@ func parseTasks(content, featureID string) []Task {
 			task.SuccessCriteria = parseTaskListSection(taskContent, "#### Success Criteria")
 		}
 
+		task.Subtasks = parseSubtasks(taskContent)
+
 		tasks = append(tasks, task)
 	}
 
@@ -265,6 +343,11 @@ func parseTaskListSection(content, header string) []string {
 			item = strings.TrimPrefix(item, "* ")
 			item = strings.TrimPrefix(item, "[ ] ")
 			item = strings.TrimPrefix(item, "[x] ")
+			// Verification commands are kept verbatim
+			if _, ok := CriterionCommand(item); ok {
+				items = append(items, item)
+				continue
+			}
 			// Remove parenthetical comments like "(project structure must exist)"
 			if idx := strings.Index(item, "("); idx > 0 {
 				item = strings.TrimSpace(item[:idx])
@@ -329,6 +412,11 @@ func parseCommaSeparated(s string) []string {
 	return items
 }
 
+// parseTags parses a **Tags:** line like "migration, `db`"
+func parseTags(s string) []string {
+	return parseCommaSeparated(strings.ReplaceAll(s, "`", ""))
+}
+
 func parseSuccessCriteria(content string) []string {
 	var criteria []string
 	lines := strings.Split(content, "\n")
@@ -0,0 +1,128 @@
+package task
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// QualifiedIDSeparator separates the feature and task ID of a qualified task
+// ID such as "F002/T010"
+const QualifiedIDSeparator = "/"
+
+// QualifiedID returns the task ID qualified with its feature ID
+func QualifiedID(featureID, taskID string) string {
+	return featureID + QualifiedIDSeparator + taskID
+}
+
+// SplitTaskID splits a qualified task ID into its feature and task ID. The
+// feature ID is empty for a plain task ID.
+func SplitTaskID(id string) (featureID, taskID string) {
+	if f, t, ok := strings.Cut(id, QualifiedIDSeparator); ok {
+		return f, t
+	}
+	return "", id
+}
+
+// FileSafeID returns a task ID usable as part of a file name
+func FileSafeID(id string) string {
+	return strings.ReplaceAll(id, QualifiedIDSeparator, "-")
+}
+
+// AmbiguousIDError is returned when a plain task ID is used by several features
+type AmbiguousIDError struct {
+	ID         string
+	FeatureIDs []string
+}
+
+func (e *AmbiguousIDError) Error() string {
+	qualified := make([]string, len(e.FeatureIDs))
+	for i, f := range e.FeatureIDs {
+		qualified[i] = QualifiedID(f, e.ID)
+	}
+	return fmt.Sprintf("task ID %s is used by several features, use %s", e.ID, strings.Join(qualified, " or "))
+}
+
+// taskIndex maps plain task IDs to the features using them
+type taskIndex map[string][]string
+
+func newTaskIndex(tasks []Task) taskIndex {
+	index := make(taskIndex)
+	for _, t := range tasks {
+		_, id := SplitTaskID(t.ID)
+		index[id] = append(index[id], t.FeatureID)
+	}
+	for id, features := range index {
+		sort.Strings(features)
+		index[id] = features
+	}
+	return index
+}
+
+// duplicated returns true if several features use the plain task ID
+func (x taskIndex) duplicated(id string) bool {
+	return len(x[id]) > 1
+}
+
+// has returns true if the feature has a task with the plain task ID
+func (x taskIndex) has(featureID, id string) bool {
+	for _, f := range x[id] {
+		if f == featureID {
+			return true
+		}
+	}
+	return false
+}
+
+// canonical returns the ID the reader gives a task: its plain ID, or its
+// qualified ID when the plain ID is used by several features
+func (x taskIndex) canonical(featureID, id string) string {
+	if x.duplicated(id) {
+		return QualifiedID(featureID, id)
+	}
+	return id
+}
+
+// resolve returns the canonical ID of the task a dependency of a task in
+// fromFeature refers to. Plain IDs prefer the task of the same feature. ok is
+// false when the dependency names no task or a plain ID is ambiguous.
+func (x taskIndex) resolve(dep, fromFeature string) (string, bool) {
+	featureID, id := SplitTaskID(dep)
+	switch {
+	case featureID != "":
+		if !x.has(featureID, id) {
+			return dep, false
+		}
+		return x.canonical(featureID, id), true
+	case len(x[id]) == 1:
+		return id, true
+	case x.has(fromFeature, id):
+		return QualifiedID(fromFeature, id), true
+	}
+	return dep, false
+}
+
+// namespaceTasks qualifies the IDs of tasks whose ID is used by several
+// features and rewrites dependencies to the canonical IDs, so that a plain
+// dependency never silently resolves to another feature's task
+func namespaceTasks(tasks []Task) {
+	index := newTaskIndex(tasks)
+	for i := range tasks {
+		t := &tasks[i]
+		t.ID = index.canonical(t.FeatureID, t.ID)
+		t.Dependencies = resolveAll(index, t.Dependencies, t.FeatureID)
+		t.DependsOn = resolveAll(index, t.DependsOn, t.FeatureID)
+	}
+}
+
+// resolveAll resolves dependencies into a new slice, leaving the cached one untouched
+func resolveAll(index taskIndex, deps []string, fromFeature string) []string {
+	if len(deps) == 0 {
+		return deps
+	}
+	resolved := make([]string, len(deps))
+	for i, dep := range deps {
+		resolved[i], _ = index.resolve(dep, fromFeature)
+	}
+	return resolved
+}
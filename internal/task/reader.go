@@ -1,7 +1,6 @@
 package task
 
 import (
-	"os"
 	"path/filepath"
 	"sort"
 	"strings"
@@ -12,6 +11,9 @@ type Reader struct {
 	basePath            string
 	tasksDir            string
 	implicitDocDeps     bool
+	agingLoops          int            // Loops a task waits before its priority is raised, 0 disables aging
+	waiting             map[string]int // Loops each eligible task has waited
+	milestone           string         // Latest target version GetNextTask selects tasks of
 }
 
 // NewReader creates a new task reader
@@ -42,22 +44,23 @@ func (r *Reader) HasTasks() bool {
 	return err == nil && len(files) > 0
 }
 
-// GetFeatureFiles returns all feature files sorted by name
+// GetFeatureFiles returns all feature files sorted by name. Archived
+// features in the archive subdirectory are not included.
 func (r *Reader) GetFeatureFiles() ([]string, error) {
-	// Try both patterns: XXX-*.md and FXXX-*.md
-	pattern1 := filepath.Join(r.tasksDir, "[0-9][0-9][0-9]-*.md")
-	pattern2 := filepath.Join(r.tasksDir, "F[0-9][0-9][0-9]-*.md")
-
-	files1, _ := filepath.Glob(pattern1)
-	files2, _ := filepath.Glob(pattern2)
+	return featureFilesIn(r.tasksDir), nil
+}
 
-	// Merge and deduplicate
+// featureFilesIn returns the feature files directly inside dir sorted by name
+func featureFilesIn(dir string) []string {
+	// Try both patterns: XXX-*.md and FXXX-*.md, in markdown or YAML
 	fileSet := make(map[string]bool)
-	for _, f := range files1 {
-		fileSet[f] = true
-	}
-	for _, f := range files2 {
-		fileSet[f] = true
+	for _, prefix := range []string{"[0-9][0-9][0-9]-*", "F[0-9][0-9][0-9]-*"} {
+		for _, ext := range []string{".md", ".yaml", ".yml"} {
+			matches, _ := filepath.Glob(filepath.Join(dir, prefix+ext))
+			for _, f := range matches {
+				fileSet[f] = true
+			}
+		}
 	}
 
 	var files []string
@@ -66,16 +69,23 @@ func (r *Reader) GetFeatureFiles() ([]string, error) {
 	}
 
 	sort.Strings(files)
-	return files, nil
+	return files
 }
 
 // ReadFeature reads and parses a single feature file
 func (r *Reader) ReadFeature(filePath string) (*Feature, error) {
-	content, err := os.ReadFile(filePath)
+	return r.readFeature(filePath, readStatusSidecar(sidecarDir(r.basePath)))
+}
+
+// readFeature parses a feature file, which is only re-read when it changed,
+// and applies the sidecar statuses
+func (r *Reader) readFeature(filePath string, statuses map[string]Status) (*Feature, error) {
+	feature, err := parseFeatureFile(filePath)
 	if err != nil {
 		return nil, err
 	}
-	return ParseFeature(string(content), filePath)
+	applyStatusSidecar(feature, statuses)
+	return feature, nil
 }
 
 // GetAllFeatures returns all features
@@ -85,9 +95,10 @@ func (r *Reader) GetAllFeatures() ([]Feature, error) {
 		return nil, err
 	}
 
+	statuses := readStatusSidecar(sidecarDir(r.basePath))
 	var features []Feature
 	for _, file := range files {
-		feature, err := r.ReadFeature(file)
+		feature, err := r.readFeature(file, statuses)
 		if err != nil {
 			continue
 		}
@@ -96,7 +107,9 @@ func (r *Reader) GetAllFeatures() ([]Feature, error) {
 	return features, nil
 }
 
-// GetAllTasks returns all tasks from all features
+// GetAllTasks returns all tasks from all features. Tasks whose ID is used by
+// several features get qualified IDs such as "F002/T010", and dependencies
+// are rewritten to the IDs of the tasks they refer to.
 func (r *Reader) GetAllTasks() ([]Task, error) {
 	features, err := r.GetAllFeatures()
 	if err != nil {
@@ -107,10 +120,12 @@ func (r *Reader) GetAllTasks() ([]Task, error) {
 	for _, feature := range features {
 		tasks = append(tasks, feature.Tasks...)
 	}
+	namespaceTasks(tasks)
 	return tasks, nil
 }
 
-// GetTaskByID finds a task by its ID
+// GetTaskByID finds a task by its ID. A qualified ID such as "F002/T010"
+// finds any task; a plain ID used by several features is an *AmbiguousIDError.
 func (r *Reader) GetTaskByID(id string) (*Task, error) {
 	tasks, err := r.GetAllTasks()
 	if err != nil {
@@ -121,6 +136,21 @@ func (r *Reader) GetTaskByID(id string) (*Task, error) {
 			return &t, nil
 		}
 	}
+
+	featureID, plain := SplitTaskID(id)
+	var features []string
+	for _, t := range tasks {
+		if _, tid := SplitTaskID(t.ID); tid != plain {
+			continue
+		}
+		if t.FeatureID == featureID {
+			return &t, nil
+		}
+		features = append(features, t.FeatureID)
+	}
+	if featureID == "" && len(features) > 1 {
+		return nil, &AmbiguousIDError{ID: plain, FeatureIDs: features}
+	}
 	return nil, nil
 }
 
@@ -182,6 +212,10 @@ func (r *Reader) GetNextTask() (*Task, error) {
 	if err != nil {
 		return nil, err
 	}
+	tasks, err = r.FilterMilestone(tasks)
+	if err != nil {
+		return nil, err
+	}
 
 	// Build completed tasks map
 	completed := make(map[string]bool)
@@ -192,9 +226,11 @@ func (r *Reader) GetNextTask() (*Task, error) {
 	}
 
 	// First, check for IN_PROGRESS tasks - they should be continued first
+	var inProgress *Task
 	for _, t := range tasks {
 		if t.Status == StatusInProgress {
-			return &t, nil
+			inProgress = &t
+			break
 		}
 	}
 
@@ -213,24 +249,26 @@ func (r *Reader) GetNextTask() (*Task, error) {
 		}
 	}
 
-	// If we have non-doc candidates, use them first
-	if len(candidates) > 0 {
-		// Sort by priority
-		sort.Slice(candidates, func(i, j int) bool {
-			return candidates[i].Priority < candidates[j].Priority
-		})
-		return &candidates[0], nil
-	}
-
-	// If only doc candidates remain, use them
-	if len(docCandidates) > 0 {
-		sort.Slice(docCandidates, func(i, j int) bool {
-			return docCandidates[i].Priority < docCandidates[j].Priority
-		})
-		return &docCandidates[0], nil
+	// Sort by priority, raised by aging when enabled
+	sort.Slice(candidates, func(i, j int) bool {
+		return r.ranksBefore(&candidates[i], &candidates[j])
+	})
+	sort.Slice(docCandidates, func(i, j int) bool {
+		return r.ranksBefore(&docCandidates[i], &docCandidates[j])
+	})
+
+	// Continue the IN_PROGRESS task, then use non-doc candidates first
+	next := inProgress
+	switch {
+	case next != nil:
+	case len(candidates) > 0:
+		next = &candidates[0]
+	case len(docCandidates) > 0:
+		next = &docCandidates[0]
 	}
+	r.age(append(candidates, docCandidates...), next)
 
-	return nil, nil
+	return next, nil
 }
 
 // isDocTask checks if a task name indicates it's a documentation task
@@ -266,8 +304,13 @@ func (r *Reader) GetProgress() (*Progress, error) {
 		}
 	}
 
+	// Completed subtasks count toward the percentage of unfinished tasks
 	if p.Total > 0 {
-		p.Percentage = float64(p.Completed) / float64(p.Total) * 100
+		done := 0.0
+		for i := range tasks {
+			done += tasks[i].completedFraction()
+		}
+		p.Percentage = done / float64(p.Total) * 100
 	}
 
 	return p, nil
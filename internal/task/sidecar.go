@@ -0,0 +1,177 @@
+package task
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+	"os/user"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+)
+
+// StatusSidecarFile is the append-only status log used when tasks are versioned in git.
+// Each line is "<RFC3339 timestamp> <ID> <STATUS> <agent>"; lines never change once
+// written, so the file merges cleanly with git's union merge driver.
+const StatusSidecarFile = "status.log"
+
+// sidecarEntry is a single status record from the sidecar
+type sidecarEntry struct {
+	Time   time.Time
+	Status Status
+	Agent  string
+}
+
+var (
+	sidecarMu       sync.RWMutex
+	sidecarTasksDir string // Relative to the project, empty while the sidecar is off
+)
+
+// UseStatusSidecar keeps statuses in the sidecar of tasksDir, relative to the
+// project, instead of the task files, as git.trackTasks does. An empty
+// tasksDir switches back to the task files.
+func UseStatusSidecar(tasksDir string) {
+	sidecarMu.Lock()
+	sidecarTasksDir = tasksDir
+	sidecarMu.Unlock()
+}
+
+// EnableStatusSidecar creates the status sidecar in tasksDir of the project
+// at basePath and uses it, so status updates stop rewriting task files.
+// Backups of rewritten task files are kept out of git.
+func EnableStatusSidecar(basePath, tasksDir string) error {
+	dir := filepath.Join(basePath, tasksDir)
+	if err := createStatusSidecar(dir); err != nil {
+		return err
+	}
+	UseStatusSidecar(tasksDir)
+	return nil
+}
+
+// createStatusSidecar writes the sidecar and the .gitignore of task file
+// backups to tasksDir unless they exist
+func createStatusSidecar(tasksDir string) error {
+	path := sidecarPath(tasksDir)
+	if err := os.MkdirAll(tasksDir, 0755); err != nil {
+		return err
+	}
+	ignore := filepath.Join(tasksDir, ".gitignore")
+	if _, err := os.Stat(ignore); os.IsNotExist(err) {
+		if err := os.WriteFile(ignore, []byte("*"+BackupSuffix+"\n"), 0644); err != nil {
+			return err
+		}
+	}
+	if _, err := os.Stat(path); err == nil {
+		return nil
+	}
+	header := "# Hermes task status log (append-only, merge=union)\n"
+	return os.WriteFile(path, []byte(header), 0644)
+}
+
+// sidecarDir returns the directory of the sidecar of the project at
+// basePath, empty while the sidecar is off
+func sidecarDir(basePath string) string {
+	sidecarMu.RLock()
+	defer sidecarMu.RUnlock()
+	if sidecarTasksDir == "" {
+		return ""
+	}
+	return filepath.Join(basePath, sidecarTasksDir)
+}
+
+func sidecarPath(tasksDir string) string {
+	return filepath.Join(tasksDir, StatusSidecarFile)
+}
+
+// readStatusSidecar returns the latest status recorded for each task or feature ID
+func readStatusSidecar(tasksDir string) map[string]Status {
+	if tasksDir == "" {
+		return nil
+	}
+	file, err := os.Open(sidecarPath(tasksDir))
+	if err != nil {
+		return nil
+	}
+	defer file.Close()
+
+	latest := make(map[string]sidecarEntry)
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		fields := strings.Fields(line)
+		if len(fields) < 3 {
+			continue
+		}
+		ts, err := time.Parse(time.RFC3339Nano, fields[0])
+		if err != nil {
+			continue
+		}
+		entry := sidecarEntry{Time: ts, Status: Status(fields[2])}
+		if len(fields) > 3 {
+			entry.Agent = fields[3]
+		}
+
+		// Union merges interleave lines from both sides, so order by timestamp
+		// (agent name breaks ties deterministically) rather than by line position
+		prev, ok := latest[fields[1]]
+		if !ok || entry.Time.After(prev.Time) || (entry.Time.Equal(prev.Time) && entry.Agent > prev.Agent) {
+			latest[fields[1]] = entry
+		}
+	}
+
+	statuses := make(map[string]Status, len(latest))
+	for id, entry := range latest {
+		statuses[id] = entry.Status
+	}
+	return statuses
+}
+
+// appendStatusSidecar appends a status record for a task or feature ID,
+// creating the sidecar when a run has not yet
+func appendStatusSidecar(tasksDir, id string, status Status) error {
+	if err := createStatusSidecar(tasksDir); err != nil {
+		return err
+	}
+	file, err := os.OpenFile(sidecarPath(tasksDir), os.O_APPEND|os.O_WRONLY, 0644)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+
+	_, err = fmt.Fprintf(file, "%s %s %s %s\n", time.Now().UTC().Format(time.RFC3339Nano), id, status, sidecarAgent())
+	return err
+}
+
+// sidecarAgent identifies who wrote a status record
+func sidecarAgent() string {
+	name := "hermes"
+	if u, err := user.Current(); err == nil && u.Username != "" {
+		name = u.Username
+	}
+	if host, err := os.Hostname(); err == nil && host != "" {
+		name += "@" + host
+	}
+	return strings.ReplaceAll(name, " ", "_")
+}
+
+// applyStatusSidecar overlays sidecar statuses onto a parsed feature
+func applyStatusSidecar(feature *Feature, statuses map[string]Status) {
+	if len(statuses) == 0 {
+		return
+	}
+	if s, ok := statuses[feature.ID]; ok {
+		feature.Status = s
+	}
+	for i := range feature.Tasks {
+		t := &feature.Tasks[i]
+		if s, ok := statuses[QualifiedID(feature.ID, t.ID)]; ok {
+			t.Status = s
+		} else if s, ok := statuses[t.ID]; ok {
+			t.Status = s
+		}
+	}
+}
@@ -5,8 +5,36 @@ import (
 	"os"
 	"regexp"
 	"strings"
+	"sync"
+
+	"go.yaml.in/yaml/v3"
+)
+
+// StatusHook is called after a task status was written
+type StatusHook func(basePath, taskID string, status Status)
+
+var (
+	statusHooksMu sync.Mutex
+	statusHooks   []StatusHook
 )
 
+// OnStatusChange registers a hook called after every successful task status
+// update, e.g. to mirror statuses to an issue tracker
+func OnStatusChange(hook StatusHook) {
+	statusHooksMu.Lock()
+	defer statusHooksMu.Unlock()
+	statusHooks = append(statusHooks, hook)
+}
+
+func notifyStatusChange(basePath, taskID string, status Status) {
+	statusHooksMu.Lock()
+	hooks := append([]StatusHook{}, statusHooks...)
+	statusHooksMu.Unlock()
+	for _, hook := range hooks {
+		hook(basePath, taskID, status)
+	}
+}
+
 // StatusUpdater updates task status in files
 type StatusUpdater struct {
 	basePath string
@@ -17,30 +45,108 @@ func NewStatusUpdater(basePath string) *StatusUpdater {
 	return &StatusUpdater{basePath: basePath}
 }
 
-// UpdateTaskStatus updates the status of a task in its feature file
+// UpdateTaskStatus updates the status of a task in its feature file,
+// or in the status sidecar when tasks are versioned in git
 func (u *StatusUpdater) UpdateTaskStatus(taskID string, newStatus Status) error {
+	if err := u.writeTaskStatus(taskID, newStatus); err != nil {
+		return err
+	}
+	notifyStatusChange(u.basePath, taskID, newStatus)
+	return nil
+}
+
+func (u *StatusUpdater) writeTaskStatus(taskID string, newStatus Status) error {
 	reader := NewReader(u.basePath)
-	files, err := reader.GetFeatureFiles()
+	t, err := reader.GetTaskByID(taskID)
+	if err != nil {
+		return err
+	}
+	if t == nil {
+		return fmt.Errorf("task %s not found", taskID)
+	}
+
+	if dir := sidecarDir(u.basePath); dir != "" {
+		return appendStatusSidecar(dir, t.ID, newStatus)
+	}
+
+	feature, err := reader.GetFeatureByID(t.FeatureID)
+	if err != nil {
+		return err
+	}
+	if feature == nil {
+		return fmt.Errorf("feature %s not found", t.FeatureID)
+	}
+	content, err := os.ReadFile(feature.FilePath)
 	if err != nil {
 		return err
 	}
 
-	for _, file := range files {
-		content, err := os.ReadFile(file)
+	// The feature file uses the plain task ID
+	_, id := SplitTaskID(t.ID)
+	var updated string
+	if IsYAMLFile(feature.FilePath) {
+		var ok bool
+		updated, ok, err = editYAMLTask(string(content), id, func(t *yaml.Node) error {
+			setYAMLValue(t, "status", yamlScalar(string(newStatus)))
+			return nil
+		})
+		if err == nil && !ok {
+			err = fmt.Errorf("task %s not found", id)
+		}
+	} else {
+		updated = updateTaskStatusInContent(string(content), id, newStatus)
+		updated, _, err = setFrontmatterStatus(updated, id, newStatus, true)
+	}
+	if err != nil {
+		return fmt.Errorf("failed to edit %s: %w", feature.FilePath, err)
+	}
+	return writeFileAtomic(feature.FilePath, []byte(updated))
+}
+
+// SetFeatureSource records the document a feature file was generated from,
+// replacing any existing **Source:** line
+func SetFeatureSource(filePath, source string) error {
+	content, err := os.ReadFile(filePath)
+	if err != nil {
+		return err
+	}
+
+	if IsYAMLFile(filePath) {
+		updated, err := setYAMLFeatureField(string(content), "source", source)
 		if err != nil {
-			continue
+			return fmt.Errorf("failed to edit %s: %w", filePath, err)
 		}
+		return writeFileAtomic(filePath, []byte(updated))
+	}
 
-		contentStr := string(content)
-		if !strings.Contains(contentStr, taskID+":") {
-			continue
+	contentStr := string(content)
+	if _, _, ok := splitFrontmatter(contentStr); ok {
+		updated, _, err := editFrontmatter(contentStr, func(root *yaml.Node) error {
+			setYAMLValue(root, "source", yamlScalar(source))
+			return nil
+		})
+		if err != nil {
+			return fmt.Errorf("failed to edit %s: %w", filePath, err)
 		}
+		return writeFileAtomic(filePath, []byte(updated))
+	}
 
-		updated := updateTaskStatusInContent(contentStr, taskID, newStatus)
-		return os.WriteFile(file, []byte(updated), 0644)
+	line := "**Source:** " + source
+	if featureSourceRegex.MatchString(contentStr) {
+		contentStr = featureSourceRegex.ReplaceAllLiteralString(contentStr, line)
+	} else if loc := featureIDRegex.FindStringIndex(contentStr); loc != nil {
+		end := loc[1]
+		if nl := strings.Index(contentStr[end:], "\n"); nl >= 0 {
+			end += nl
+		} else {
+			end = len(contentStr)
+		}
+		contentStr = contentStr[:end] + "\n" + line + contentStr[end:]
+	} else {
+		return fmt.Errorf("no Feature ID found in %s", filePath)
 	}
 
-	return fmt.Errorf("task %s not found", taskID)
+	return writeFileAtomic(filePath, []byte(contentStr))
 }
 
 // UpdateFeatureStatus updates the status of a feature
@@ -56,18 +162,43 @@ func (u *StatusUpdater) UpdateFeatureStatus(featureID string, newStatus Status)
 			continue
 		}
 
+		if dir := sidecarDir(u.basePath); dir != "" {
+			return appendStatusSidecar(dir, featureID, newStatus)
+		}
+
 		content, err := os.ReadFile(f.FilePath)
 		if err != nil {
 			return err
 		}
 
+		if IsYAMLFile(f.FilePath) {
+			updated, err := setYAMLFeatureField(string(content), "status", string(newStatus))
+			if err != nil {
+				return fmt.Errorf("failed to edit %s: %w", f.FilePath, err)
+			}
+			return writeFileAtomic(f.FilePath, []byte(updated))
+		}
+
 		updated := updateFeatureStatusInContent(string(content), newStatus)
-		return os.WriteFile(f.FilePath, []byte(updated), 0644)
+		updated, _, err = setFrontmatterStatus(updated, featureID, newStatus, false)
+		if err != nil {
+			return fmt.Errorf("failed to edit %s: %w", f.FilePath, err)
+		}
+		return writeFileAtomic(f.FilePath, []byte(updated))
 	}
 
 	return fmt.Errorf("feature %s not found", featureID)
 }
 
+// setYAMLFeatureField sets a top-level field of a YAML feature file
+func setYAMLFeatureField(content, key, value string) (string, error) {
+	updated, _, err := editYAML(content, func(root *yaml.Node) (bool, error) {
+		setYAMLValue(root, key, yamlScalar(value))
+		return true, nil
+	})
+	return updated, err
+}
+
 func updateTaskStatusInContent(content, taskID string, newStatus Status) string {
 	lines := strings.Split(content, "\n")
 	var result []string
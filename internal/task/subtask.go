@@ -0,0 +1,134 @@
+package task
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+
+	"go.yaml.in/yaml/v3"
+)
+
+// subtaskLineRegex matches a subtask list item such as "- [x] T001.2: Add tests"
+var subtaskLineRegex = regexp.MustCompile(`^([-*]\s*)(?:\[([ xX])\]\s*)?(T\d+\.\d+):\s*(.+)$`)
+
+// parseSubtasks parses the #### Subtasks list of a task. Checked items are completed.
+func parseSubtasks(content string) []Subtask {
+	var subtasks []Subtask
+	inSection := false
+	for _, line := range strings.Split(content, "\n") {
+		trimmed := strings.TrimSpace(line)
+		if strings.HasPrefix(trimmed, "#### Subtasks") {
+			inSection = true
+			continue
+		}
+		if !inSection {
+			continue
+		}
+		if strings.HasPrefix(trimmed, "###") || strings.HasPrefix(trimmed, "---") {
+			break
+		}
+		m := subtaskLineRegex.FindStringSubmatch(trimmed)
+		if m == nil {
+			continue
+		}
+		status := StatusNotStarted
+		if strings.EqualFold(m[2], "x") {
+			status = StatusCompleted
+		}
+		subtasks = append(subtasks, Subtask{ID: m[3], Name: strings.TrimSpace(m[4]), Status: status})
+	}
+	return subtasks
+}
+
+// NextSubtask returns the first subtask that is not completed, or nil
+func (t *Task) NextSubtask() *Subtask {
+	for i := range t.Subtasks {
+		if t.Subtasks[i].Status != StatusCompleted {
+			return &t.Subtasks[i]
+		}
+	}
+	return nil
+}
+
+// SubtaskProgress returns the number of completed subtasks and the number of
+// subtasks. All subtasks of a completed task count as completed.
+func (t *Task) SubtaskProgress() (done, total int) {
+	total = len(t.Subtasks)
+	if t.Status == StatusCompleted {
+		return total, total
+	}
+	for _, s := range t.Subtasks {
+		if s.Status == StatusCompleted {
+			done++
+		}
+	}
+	return done, total
+}
+
+// completedFraction returns how much of a task is done, counting completed
+// subtasks of a task that is not completed yet
+func (t *Task) completedFraction() float64 {
+	if t.Status == StatusCompleted {
+		return 1
+	}
+	done, total := t.SubtaskProgress()
+	if total == 0 {
+		return 0
+	}
+	return float64(done) / float64(total)
+}
+
+// CompleteSubtask checks off a subtask in its task's feature file
+func (u *StatusUpdater) CompleteSubtask(taskID, subtaskID string) error {
+	found := false
+	err := u.editTask(taskID, func(lines []string) []string {
+		found = checkSubtask(lines, subtaskID)
+		return lines
+	}, func(t *yaml.Node) {
+		if subtasks := yamlValue(t, "subtasks"); subtasks != nil {
+			for _, s := range subtasks.Content {
+				if id := yamlValue(s, "id"); id != nil && id.Value == subtaskID {
+					setYAMLValue(s, "status", yamlScalar(string(StatusCompleted)))
+					found = true
+				}
+			}
+		}
+	})
+	if err != nil {
+		return err
+	}
+	if !found {
+		return fmt.Errorf("subtask %s not found in task %s", subtaskID, taskID)
+	}
+	return nil
+}
+
+// checkSubtask checks the box of a subtask list item, adding one if missing
+func checkSubtask(lines []string, subtaskID string) bool {
+	for i, line := range lines {
+		body := strings.TrimRight(line, "\r")
+		trimmed := strings.TrimSpace(body)
+		m := subtaskLineRegex.FindStringSubmatch(trimmed)
+		if m == nil || m[3] != subtaskID {
+			continue
+		}
+		indent := body[:len(body)-len(strings.TrimLeft(body, " \t"))]
+		lines[i] = fmt.Sprintf("%s- [x] %s: %s%s", indent, m[3], strings.TrimSpace(m[4]), line[len(body):])
+		return true
+	}
+	return false
+}
+
+// CompleteNextSubtask checks off the next open subtask of a task. It returns
+// the subtask, nil if the task has none open, and how many remain after it.
+func (u *StatusUpdater) CompleteNextSubtask(t *Task) (*Subtask, int, error) {
+	next := t.NextSubtask()
+	if next == nil {
+		return nil, 0, nil
+	}
+	if err := u.CompleteSubtask(t.ID, next.ID); err != nil {
+		return nil, 0, err
+	}
+	done, total := t.SubtaskProgress()
+	return next, total - done - 1, nil
+}
@@ -3,7 +3,10 @@ package task
 import (
 	"os"
 	"path/filepath"
+	"slices"
+	"strings"
 	"testing"
+	"time"
 )
 
 const testFeatureContent = `# Feature 1: User Authentication
@@ -341,6 +344,113 @@ func TestStatusUpdater(t *testing.T) {
 	}
 }
 
+func TestStatusSidecar(t *testing.T) {
+	tmpDir := setupTestDir(t)
+	defer os.RemoveAll(tmpDir)
+
+	if err := EnableStatusSidecar(tmpDir, filepath.Join(".hermes", "tasks")); err != nil {
+		t.Fatal(err)
+	}
+	defer UseStatusSidecar("")
+	if ignore, _ := os.ReadFile(filepath.Join(tmpDir, ".hermes", "tasks", ".gitignore")); !strings.Contains(string(ignore), "*"+BackupSuffix) {
+		t.Error("expected task file backups to be ignored by git")
+	}
+
+	featurePath := filepath.Join(tmpDir, ".hermes", "tasks", "001-user-auth.md")
+	before, _ := os.ReadFile(featurePath)
+
+	updater := NewStatusUpdater(tmpDir)
+	if err := updater.UpdateTaskStatus("T002", StatusCompleted); err != nil {
+		t.Fatal(err)
+	}
+
+	// Task file must stay untouched so it merges cleanly
+	after, _ := os.ReadFile(featurePath)
+	if string(before) != string(after) {
+		t.Error("expected task file to be unchanged when sidecar is enabled")
+	}
+
+	reader := NewReader(tmpDir)
+	task, err := reader.GetTaskByID("T002")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if task.Status != StatusCompleted {
+		t.Errorf("expected Status = COMPLETED, got %s", task.Status)
+	}
+}
+
+func TestStatusSidecarFollowsConfig(t *testing.T) {
+	tmpDir := setupTestDir(t)
+	defer os.RemoveAll(tmpDir)
+
+	// A sidecar left in the tasks directory is ignored while tracking is off
+	sidecar := filepath.Join(tmpDir, ".hermes", "tasks", StatusSidecarFile)
+	os.WriteFile(sidecar, []byte("2025-01-01T10:00:00Z T002 BLOCKED alice\n"), 0644)
+	reader := NewReader(tmpDir)
+	if task, _ := reader.GetTaskByID("T002"); task == nil || task.Status == StatusBlocked {
+		t.Fatalf("expected the task file status without tracking, got %+v", task)
+	}
+	if err := NewStatusUpdater(tmpDir).UpdateTaskStatus("T002", StatusCompleted); err != nil {
+		t.Fatal(err)
+	}
+	if data, _ := os.ReadFile(sidecar); strings.Contains(string(data), "COMPLETED") {
+		t.Error("expected the status in the task file, not the sidecar")
+	}
+
+	// With tracking on, the sidecar of the configured directory is created on the first update
+	UseStatusSidecar("tasks")
+	defer UseStatusSidecar("")
+	if err := NewStatusUpdater(tmpDir).UpdateTaskStatus("T002", StatusInProgress); err != nil {
+		t.Fatal(err)
+	}
+	if data, _ := os.ReadFile(filepath.Join(tmpDir, "tasks", StatusSidecarFile)); !strings.Contains(string(data), "T002 IN_PROGRESS") {
+		t.Errorf("expected the status in the configured sidecar, got %q", data)
+	}
+	if task, _ := reader.GetTaskByID("T002"); task == nil || task.Status != StatusInProgress {
+		t.Errorf("expected the sidecar status, got %+v", task)
+	}
+}
+
+func TestStatusSidecarLatestWins(t *testing.T) {
+	tmpDir := t.TempDir()
+	content := "# header\n" +
+		"2025-01-01T10:00:00Z T001 COMPLETED alice\n" +
+		"2025-01-01T09:00:00Z T001 BLOCKED bob\n" +
+		"2025-01-01T11:00:00Z T002 BLOCKED bob\n" +
+		"2025-01-01T11:00:00Z T002 IN_PROGRESS carol\n"
+	os.WriteFile(filepath.Join(tmpDir, StatusSidecarFile), []byte(content), 0644)
+
+	statuses := readStatusSidecar(tmpDir)
+	if statuses["T001"] != StatusCompleted {
+		t.Errorf("expected T001 = COMPLETED, got %s", statuses["T001"])
+	}
+	if statuses["T002"] != StatusInProgress {
+		t.Errorf("expected T002 = IN_PROGRESS, got %s", statuses["T002"])
+	}
+}
+
+func TestSetFeatureSource(t *testing.T) {
+	tmpDir := setupTestDir(t)
+	defer os.RemoveAll(tmpDir)
+
+	featurePath := filepath.Join(tmpDir, ".hermes", "tasks", "001-user-auth.md")
+	for _, source := range []string{".hermes/docs/features/01-auth.md", ".hermes/docs/features/02-auth.md"} {
+		if err := SetFeatureSource(featurePath, source); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	reader := NewReader(tmpDir)
+	feature, err := reader.GetFeatureByID("F001")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if feature.Source != ".hermes/docs/features/02-auth.md" {
+		t.Errorf("expected Source to be replaced, got %q", feature.Source)
+	}
+}
+
 func TestCanStart(t *testing.T) {
 	completed := map[string]bool{"T001": true}
 
@@ -364,3 +474,1239 @@ func TestCanStart(t *testing.T) {
 		t.Error("T005 should NOT be able to start (already completed)")
 	}
 }
+
+func TestUpdateTaskMetadata(t *testing.T) {
+	tmpDir := setupTestDir(t)
+	defer os.RemoveAll(tmpDir)
+
+	updater := NewStatusUpdater(tmpDir)
+	if err := updater.UpdateTaskPriority("T003", PriorityP1); err != nil {
+		t.Fatal(err)
+	}
+	if err := updater.UpdateTaskDependencies("T003", []string{"T002"}); err != nil {
+		t.Fatal(err)
+	}
+	if err := updater.UpdateTaskDependencies("T002", nil); err != nil {
+		t.Fatal(err)
+	}
+
+	reader := NewReader(tmpDir)
+	t3, _ := reader.GetTaskByID("T003")
+	if t3.Priority != PriorityP1 {
+		t.Errorf("expected T003 priority P1, got %s", t3.Priority)
+	}
+	if len(t3.Dependencies) != 1 || t3.Dependencies[0] != "T002" {
+		t.Errorf("expected T003 to depend on T002, got %v", t3.Dependencies)
+	}
+	if len(t3.SuccessCriteria) == 0 || t3.SuccessCriteria[0] != "Generate valid JWT" {
+		t.Errorf("expected success criteria to survive the edit, got %v", t3.SuccessCriteria)
+	}
+
+	t2, _ := reader.GetTaskByID("T002")
+	if len(t2.Dependencies) != 0 {
+		t.Errorf("expected T002 to have no dependencies, got %v", t2.Dependencies)
+	}
+	t1, _ := reader.GetTaskByID("T001")
+	if t1.Priority != PriorityP1 || t1.Status != StatusCompleted {
+		t.Errorf("expected T001 to be untouched, got %s %s", t1.Priority, t1.Status)
+	}
+
+	if err := updater.UpdateTaskPriority("T999", PriorityP1); err == nil {
+		t.Error("expected error for unknown task")
+	}
+}
+
+func TestAppendTask(t *testing.T) {
+	tmpDir := setupTestDir(t)
+	defer os.RemoveAll(tmpDir)
+
+	path := filepath.Join(tmpDir, ".hermes", "tasks", "001-user-auth.md")
+	newTask := &Task{
+		ID:              "T004",
+		Name:            "Add rate limiter",
+		Priority:        PriorityP1,
+		EstimatedEffort: "1 day",
+		FilesToTouch:    []string{"api/limit.go"},
+		Dependencies:    []string{"T002"},
+		SuccessCriteria: []string{"Limits requests per client"},
+	}
+	if err := AppendTask(path, newTask); err != nil {
+		t.Fatal(err)
+	}
+
+	feature, err := NewReader(tmpDir).GetFeatureByID("F001")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(feature.Tasks) != 4 {
+		t.Fatalf("expected 4 tasks, got %d", len(feature.Tasks))
+	}
+	added := feature.Tasks[3]
+	if added.ID != "T004" || added.Status != StatusNotStarted || added.Priority != PriorityP1 {
+		t.Errorf("unexpected task: %+v", added)
+	}
+	if len(added.Dependencies) != 1 || added.Dependencies[0] != "T002" {
+		t.Errorf("expected dependency T002, got %v", added.Dependencies)
+	}
+	if len(added.SuccessCriteria) == 0 || added.SuccessCriteria[0] != "Limits requests per client" {
+		t.Errorf("unexpected success criteria: %v", added.SuccessCriteria)
+	}
+
+	// Trailing sections stay after the tasks
+	content, _ := os.ReadFile(path)
+	if strings.Index(string(content), "### T004") > strings.Index(string(content), "## Performance Targets") {
+		t.Error("expected new task before the Performance Targets section")
+	}
+
+	parsed, err := ParseTaskBlock(FormatTask(newTask), "F001")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if parsed.ID != "T004" || parsed.EstimatedEffort != "1 day" {
+		t.Errorf("expected FormatTask output to round-trip, got %+v", parsed)
+	}
+}
+
+func TestReplaceTask(t *testing.T) {
+	tmpDir := setupTestDir(t)
+	defer os.RemoveAll(tmpDir)
+
+	path := filepath.Join(tmpDir, ".hermes", "tasks", "001-user-auth.md")
+	parts := []*Task{
+		{ID: "T004", Name: "Token model", Dependencies: []string{"T001"}},
+		{ID: "T005", Name: "Token endpoint", Dependencies: []string{"T004"}},
+	}
+	if err := ReplaceTask(path, "T002", parts); err != nil {
+		t.Fatal(err)
+	}
+
+	feature, err := NewReader(tmpDir).GetFeatureByID("F001")
+	if err != nil {
+		t.Fatal(err)
+	}
+	var ids []string
+	for _, ft := range feature.Tasks {
+		ids = append(ids, ft.ID)
+	}
+	if strings.Join(ids, ",") != "T001,T004,T005,T003" {
+		t.Fatalf("expected T002 replaced in place, got %v", ids)
+	}
+	if deps := feature.Tasks[2].Dependencies; len(deps) != 1 || deps[0] != "T004" {
+		t.Errorf("expected T005 to depend on T004, got %v", deps)
+	}
+
+	if err := ReplaceTask(path, "T002", parts); err == nil {
+		t.Error("expected error for a task that no longer exists")
+	}
+}
+
+func TestArchiveFeature(t *testing.T) {
+	tmpDir := setupTestDir(t)
+	defer os.RemoveAll(tmpDir)
+
+	tasksDir := filepath.Join(tmpDir, ".hermes", "tasks")
+	os.WriteFile(filepath.Join(tasksDir, "002-done.md"), []byte(`# Feature 2: Done
+
+**Feature ID:** F002
+**Status:** COMPLETED
+
+### T010: Finished work
+
+**Status:** COMPLETED
+`), 0644)
+	os.WriteFile(filepath.Join(tasksDir, "003-next.md"), []byte(`# Feature 3: Next
+
+**Feature ID:** F003
+**Status:** NOT_STARTED
+
+### T020: Follow-up
+
+**Status:** NOT_STARTED
+**Dependencies:** T001, T010
+`), 0644)
+
+	reader := NewReader(tmpDir)
+	done, _ := reader.GetFeatureByID("F002")
+	updated, err := ArchiveFeature(tmpDir, done)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(updated) != 1 || updated[0] != "T020" {
+		t.Errorf("expected T020 dependencies to be updated, got %v", updated)
+	}
+
+	if f, _ := reader.GetFeatureByID("F002"); f != nil {
+		t.Error("expected archived feature to be excluded from the reader")
+	}
+	archived, _ := reader.GetArchivedFeatures()
+	if len(archived) != 1 || archived[0].ID != "F002" {
+		t.Errorf("expected F002 in the archive, got %v", archived)
+	}
+	t20, _ := reader.GetTaskByID("T020")
+	if len(t20.Dependencies) != 1 || t20.Dependencies[0] != "T001" {
+		t.Errorf("expected T020 to depend on T001 only, got %v", t20.Dependencies)
+	}
+
+	unfinished, _ := reader.GetFeatureByID("F001")
+	if _, err := ArchiveFeature(tmpDir, unfinished); err == nil {
+		t.Error("expected error archiving a feature with unfinished tasks")
+	}
+}
+
+func TestPlanMerge(t *testing.T) {
+	tmpDir := setupTestDir(t)
+	defer os.RemoveAll(tmpDir)
+
+	contents := []string{`# Feature 1: User Authentication
+
+**Feature ID:** F001
+**Status:** NOT_STARTED
+
+### T001: Create login endpoint
+
+**Status:** NOT_STARTED
+
+### T002: Add Password   Hashing
+
+**Status:** NOT_STARTED
+
+### T003: Add logout endpoint
+
+**Status:** NOT_STARTED
+**Dependencies:** T001
+`, `# Feature 2: Billing
+
+**Feature ID:** F002
+**Status:** NOT_STARTED
+
+### T004: Add invoices
+
+**Status:** NOT_STARTED
+**Dependencies:** T003
+`}
+
+	plan, err := PlanMerge(tmpDir, contents, 2, 4)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if plan.Kept != 2 {
+		t.Errorf("expected 2 kept tasks, got %d", plan.Kept)
+	}
+	if len(plan.NewTasks) != 1 || plan.NewTasks[0].Task.ID != "T004" || plan.NewTasks[0].Feature.ID != "F001" {
+		t.Fatalf("expected T004 to be added to F001, got %+v", plan.NewTasks)
+	}
+	if deps := plan.NewTasks[0].Task.Dependencies; len(deps) != 1 || deps[0] != "T001" {
+		t.Errorf("expected T004 to depend on T001, got %v", deps)
+	}
+	if len(plan.NewFeatures) != 1 || plan.NewFeatures[0].ID != "F002" || plan.NewFeatures[0].FileName != "002-billing.md" {
+		t.Fatalf("expected new feature F002, got %+v", plan.NewFeatures)
+	}
+	if !strings.Contains(plan.NewFeatures[0].Content, "### T005: Add invoices") || !strings.Contains(plan.NewFeatures[0].Content, "**Dependencies:** T004") {
+		t.Errorf("expected new feature tasks to be renumbered, got:\n%s", plan.NewFeatures[0].Content)
+	}
+
+	if _, err := ApplyMerge(tmpDir, plan); err != nil {
+		t.Fatal(err)
+	}
+	reader := NewReader(tmpDir)
+	if done, _ := reader.GetTaskByID("T001"); done == nil || done.Status != StatusCompleted {
+		t.Errorf("expected T001 to stay COMPLETED, got %+v", done)
+	}
+	if added, _ := reader.GetTaskByID("T005"); added == nil || added.FeatureID != "F002" {
+		t.Errorf("expected T005 in F002, got %+v", added)
+	}
+	if tasks, _ := reader.GetAllTasks(); len(tasks) != 5 {
+		t.Errorf("expected 5 tasks after merge, got %d", len(tasks))
+	}
+}
+
+func TestKeepTaskIDs(t *testing.T) {
+	previous := []Task{
+		{ID: "T010", Name: "Create login endpoint", PRDSection: "Login"},
+		{ID: "T011", Name: "Hash passwords", PRDSection: "Passwords"},
+		{ID: "T012", Name: "Add remember me", PRDSection: "Login"},
+	}
+	content := `# Feature 1: Auth
+
+**Feature ID:** F001
+
+### T020: Create Login   Endpoint
+
+**Status:** NOT_STARTED
+**PRD Section:** Login
+
+### T021: Store password hashes
+
+**Status:** NOT_STARTED
+**PRD Section:** Passwords
+**Dependencies:** T020
+
+### T022: Add sessions
+
+**Status:** NOT_STARTED
+**PRD Section:** Sessions
+**Dependencies:** T021
+`
+
+	f, err := ParseFeature(KeepTaskIDs(content, previous), "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	var got []string
+	for _, task := range f.Tasks {
+		got = append(got, task.ID+"<"+strings.Join(task.Dependencies, ","))
+	}
+	// Matched by name, by the only task of its section, and a new task
+	want := []string{"T010<", "T011<T010", "T022<T011"}
+	if strings.Join(got, " ") != strings.Join(want, " ") {
+		t.Errorf("expected tasks %v, got %v", want, got)
+	}
+}
+
+func TestStatusChangeHook(t *testing.T) {
+	tmpDir := setupTestDir(t)
+	defer os.RemoveAll(tmpDir)
+
+	path := filepath.Join(tmpDir, ".hermes", "tasks", "001-user-auth.md")
+	if err := AppendTask(path, &Task{ID: "T004", Name: "Imported issue", JiraKey: "APP-12"}); err != nil {
+		t.Fatal(err)
+	}
+	imported, err := NewReader(tmpDir).GetTaskByID("T004")
+	if err != nil || imported == nil || imported.JiraKey != "APP-12" {
+		t.Fatalf("expected the Jira key to round-trip, got %+v (%v)", imported, err)
+	}
+
+	var changes []string
+	OnStatusChange(func(basePath, taskID string, status Status) {
+		if basePath == tmpDir {
+			changes = append(changes, taskID+" "+string(status))
+		}
+	})
+
+	updater := NewStatusUpdater(tmpDir)
+	if err := updater.MarkTaskInProgress("T004"); err != nil {
+		t.Fatal(err)
+	}
+	if err := updater.UpdateTaskStatus("T999", StatusCompleted); err == nil {
+		t.Error("expected an error for an unknown task")
+	}
+	if len(changes) != 1 || changes[0] != "T004 IN_PROGRESS" {
+		t.Errorf("expected one successful change to be reported, got %v", changes)
+	}
+}
+
+func TestStatusUpdateIsAtomic(t *testing.T) {
+	tmpDir := setupTestDir(t)
+	defer os.RemoveAll(tmpDir)
+	tasksDir := filepath.Join(tmpDir, ".hermes", "tasks")
+	featurePath := filepath.Join(tasksDir, "001-user-auth.md")
+	os.Chmod(featurePath, 0600)
+
+	if err := NewStatusUpdater(tmpDir).UpdateTaskStatus("T002", StatusCompleted); err != nil {
+		t.Fatal(err)
+	}
+
+	// The previous version is kept as a backup
+	backup, err := os.ReadFile(featurePath + BackupSuffix)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(backup) != testFeatureContent {
+		t.Error("expected the backup to hold the previous version")
+	}
+
+	// No temporary files are left behind and the file mode is kept
+	entries, _ := os.ReadDir(tasksDir)
+	for _, e := range entries {
+		if strings.Contains(e.Name(), ".tmp-") {
+			t.Errorf("unexpected temporary file %s", e.Name())
+		}
+	}
+	if info, _ := os.Stat(featurePath); info.Mode().Perm() != 0600 {
+		t.Errorf("expected mode 0600, got %v", info.Mode().Perm())
+	}
+
+	// The backup is not picked up as a feature file
+	features, _ := NewReader(tmpDir).GetAllFeatures()
+	if len(features) != 1 {
+		t.Errorf("expected 1 feature, got %d", len(features))
+	}
+}
+
+func TestReaderCachesUnchangedFiles(t *testing.T) {
+	tmpDir := setupTestDir(t)
+	defer os.RemoveAll(tmpDir)
+	featurePath := filepath.Join(tmpDir, ".hermes", "tasks", "001-user-auth.md")
+
+	first, err := NewReader(tmpDir).GetFeatureByID("F001")
+	if err != nil || first == nil {
+		t.Fatalf("expected F001, got %v (%v)", first, err)
+	}
+	first.Tasks[0].Status = StatusBlocked // Must not leak into the cache
+
+	// Content changed in place with the same size and modification time is
+	// served from the cache, which shows the file was not parsed again
+	info, _ := os.Stat(featurePath)
+	changed := strings.Replace(testFeatureContent, "User Authentication", "User Authenticatoin", 1)
+	os.WriteFile(featurePath, []byte(changed), 0644)
+	os.Chtimes(featurePath, info.ModTime(), info.ModTime())
+
+	cached, _ := NewReader(tmpDir).GetFeatureByID("F001")
+	if cached.Name != first.Name || cached.Tasks[0].Status == StatusBlocked {
+		t.Errorf("expected an unchanged copy from the cache, got %q %s", cached.Name, cached.Tasks[0].Status)
+	}
+
+	// A status update is seen right away
+	if err := NewStatusUpdater(tmpDir).UpdateTaskStatus("T001", StatusPaused); err != nil {
+		t.Fatal(err)
+	}
+	updated, _ := NewReader(tmpDir).GetTaskByID("T001")
+	if updated.Status != StatusPaused {
+		t.Errorf("expected the update to be read, got %s", updated.Status)
+	}
+}
+
+const testYAMLFeature = `# Generated by the release tooling
+id: F002
+name: Background Jobs
+priority: P1
+targetVersion: v1.2.0
+tasks:
+  - id: T010
+    name: Job queue
+    status: COMPLETED
+    estimatedEffort: 1 day
+    filesToTouch: [internal/jobs/queue.go]
+  - id: T011
+    name: Retry failed jobs # keep this comment
+    dependencies: [T010]
+    successCriteria:
+      - Failed jobs are retried three times
+`
+
+func TestYAMLFeatureFiles(t *testing.T) {
+	tmpDir := setupTestDir(t)
+	defer os.RemoveAll(tmpDir)
+	yamlPath := filepath.Join(tmpDir, ".hermes", "tasks", "002-jobs.yaml")
+	os.WriteFile(yamlPath, []byte(testYAMLFeature), 0644)
+
+	reader := NewReader(tmpDir)
+	features, err := reader.GetAllFeatures()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(features) != 2 {
+		t.Fatalf("expected markdown and YAML features, got %d", len(features))
+	}
+	f := features[1]
+	if f.ID != "F002" || f.Name != "Background Jobs" || f.Status != StatusNotStarted || f.TargetVersion != "v1.2.0" || len(f.Tasks) != 2 {
+		t.Fatalf("unexpected feature %+v", f)
+	}
+	retry := f.Tasks[1]
+	if retry.FeatureID != "F002" || retry.Status != StatusNotStarted || retry.Priority != PriorityP2 ||
+		len(retry.Dependencies) != 1 || retry.Dependencies[0] != "T010" || len(retry.SuccessCriteria) != 1 {
+		t.Errorf("unexpected task %+v", retry)
+	}
+
+	updater := NewStatusUpdater(tmpDir)
+	if err := updater.UpdateTaskStatus("T011", StatusInProgress); err != nil {
+		t.Fatal(err)
+	}
+	if err := updater.UpdateTaskPriority("T011", PriorityP1); err != nil {
+		t.Fatal(err)
+	}
+	if err := updater.UpdateFeatureStatus("F002", StatusInProgress); err != nil {
+		t.Fatal(err)
+	}
+	if err := AppendTask(yamlPath, &Task{ID: "T012", Name: "Dead letter queue", Dependencies: []string{"T011"}}); err != nil {
+		t.Fatal(err)
+	}
+
+	content, _ := os.ReadFile(yamlPath)
+	for _, want := range []string{"# Generated by the release tooling", "# keep this comment"} {
+		if !strings.Contains(string(content), want) {
+			t.Errorf("expected comments to be kept, got:\n%s", content)
+		}
+	}
+	f2, _ := reader.GetFeatureByID("F002")
+	if f2.Status != StatusInProgress || len(f2.Tasks) != 3 {
+		t.Fatalf("unexpected feature after edits %+v", f2)
+	}
+	if f2.Tasks[1].Status != StatusInProgress || f2.Tasks[1].Priority != PriorityP1 {
+		t.Errorf("expected T011 in progress with P1, got %+v", f2.Tasks[1])
+	}
+	if f2.Tasks[2].ID != "T012" || f2.Tasks[2].FeatureID != "F002" {
+		t.Errorf("expected the appended task, got %+v", f2.Tasks[2])
+	}
+
+	// Tasks of both formats take part in scheduling
+	next, _ := reader.GetNextTask()
+	if next == nil {
+		t.Fatal("expected a next task")
+	}
+}
+
+func TestFormatFeatureYAMLRoundTrip(t *testing.T) {
+	f := &Feature{ID: "F003", Name: "Reports", Status: StatusNotStarted, Priority: PriorityP3,
+		Tasks: []Task{{ID: "T020", Name: "Export CSV", Status: StatusNotStarted, Priority: PriorityP2, FilesToTouch: []string{"export.go"}}}}
+	data, err := FormatFeatureYAML(f)
+	if err != nil {
+		t.Fatal(err)
+	}
+	parsed, err := ParseFeatureYAML(string(data), "003-reports.yaml")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if parsed.Name != "Reports" || len(parsed.Tasks) != 1 || parsed.Tasks[0].FilesToTouch[0] != "export.go" || parsed.Tasks[0].FeatureID != "F003" {
+		t.Errorf("unexpected round trip %+v", parsed)
+	}
+
+	if _, err := ParseFeatureYAML("name: No ID\n", "bad.yaml"); err == nil {
+		t.Error("expected a feature without id to be rejected")
+	}
+}
+
+func TestParsePlanJSON(t *testing.T) {
+	output := "Here is the plan:\n```json\n" + `{"features": [{
+		"id": "F002", "name": "Export", "priority": "P1",
+		"overview": "Export data as CSV.",
+		"goals": ["Fast exports"],
+		"tasks": [
+			{"id": "T010", "name": "CSV writer", "filesToTouch": ["export/csv.go"], "successCriteria": ["Writes headers"]},
+			{"id": "T011", "name": "Export command", "dependencies": ["T010", "T001"]}
+		]
+	}]}` + "\n```\nDone."
+
+	features, err := ParsePlanJSON(output, map[string]bool{"T001": true})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(features) != 1 || len(features[0].Tasks) != 2 {
+		t.Fatalf("unexpected plan %+v", features)
+	}
+	f := features[0]
+	if f.Status != StatusNotStarted || f.Tasks[0].Priority != PriorityP2 || f.Tasks[1].FeatureID != "F002" {
+		t.Errorf("expected defaults to be filled in, got %+v", f)
+	}
+
+	// The rendered markdown parses back to the same feature
+	parsed, err := ParseFeature(FormatFeature(&f), "002-export.md")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if parsed.ID != "F002" || parsed.Name != "Export" || parsed.Priority != PriorityP1 || len(parsed.Tasks) != 2 {
+		t.Errorf("unexpected parsed feature %+v", parsed)
+	}
+	if got := parsed.Tasks[1].Dependencies; len(got) != 2 || got[0] != "T010" {
+		t.Errorf("expected dependencies to round trip, got %v", got)
+	}
+	if got := parsed.Tasks[0].FilesToTouch; len(got) != 1 || !strings.Contains(got[0], "export/csv.go") {
+		t.Errorf("expected files to touch to round trip, got %v", got)
+	}
+}
+
+func TestParsePlanJSONRejectsInvalidPlans(t *testing.T) {
+	tests := []struct {
+		name   string
+		output string
+		want   string
+	}{
+		{"no json", "I could not parse the PRD", "does not contain"},
+		{"unknown field", `{"features": [{"id": "F001", "name": "A", "colour": "red", "tasks": [{"id": "T001", "name": "a"}]}]}`, "unknown field"},
+		{"bad ids", `[{"id": "1", "name": "A", "tasks": [{"id": "task-1", "name": "a"}]}]`, "must look like"},
+		{"duplicate task", `[{"id": "F001", "name": "A", "tasks": [{"id": "T001", "name": "a"}, {"id": "T001", "name": "b"}]}]`, "duplicate task id"},
+		{"unknown dependency", `[{"id": "F001", "name": "A", "tasks": [{"id": "T001", "name": "a", "dependencies": ["T009"]}]}]`, "unknown dependency"},
+		{"bad status", `[{"id": "F001", "name": "A", "tasks": [{"id": "T001", "name": "a", "status": "DONE"}]}]`, "unknown status"},
+		{"no tasks", `{"id": "F001", "name": "A"}`, "has no tasks"},
+	}
+
+	for _, tt := range tests {
+		_, err := ParsePlanJSON(tt.output, nil)
+		if err == nil || !strings.Contains(err.Error(), tt.want) {
+			t.Errorf("%s: expected error containing %q, got %v", tt.name, tt.want, err)
+		}
+	}
+}
+
+const testFrontmatterFeature = `---
+id: F002
+# Managed by Hermes
+status: IN_PROGRESS
+tasks:
+  T010: {status: COMPLETED, retries: 2}
+---
+# Feature 2: Reports
+
+**Priority:** P1
+**Status:** NOT_STARTED
+
+## Tasks
+
+### T010: Report model
+
+**Status:** NOT_STARTED
+**Priority:** P2
+
+---
+
+### T011: Report export
+
+**Status:** NOT_STARTED
+**Priority:** P3
+`
+
+func TestFrontmatter(t *testing.T) {
+	tmpDir := setupTestDir(t)
+	defer os.RemoveAll(tmpDir)
+	path := filepath.Join(tmpDir, ".hermes", "tasks", "002-reports.md")
+	os.WriteFile(path, []byte(testFrontmatterFeature), 0644)
+
+	feature, err := NewReader(tmpDir).GetFeatureByID("F002")
+	if err != nil || feature == nil {
+		t.Fatalf("expected the feature ID to come from the frontmatter, got %v", err)
+	}
+	if feature.Name != "Reports" || feature.Status != StatusInProgress || feature.Priority != PriorityP1 || len(feature.Tasks) != 2 {
+		t.Fatalf("unexpected feature %+v", feature)
+	}
+	if got := feature.Tasks[0]; got.Status != StatusCompleted || got.Retries != 2 || got.FeatureID != "F002" {
+		t.Errorf("expected frontmatter task metadata, got %+v", got)
+	}
+	if got := feature.Tasks[1]; got.Status != StatusNotStarted || got.Priority != PriorityP3 {
+		t.Errorf("expected body values without frontmatter entry, got %+v", got)
+	}
+
+	updater := NewStatusUpdater(tmpDir)
+	if err := updater.UpdateTaskStatus("T011", StatusInProgress); err != nil {
+		t.Fatal(err)
+	}
+	if err := updater.UpdateTaskStatus("T010", StatusInProgress); err != nil {
+		t.Fatal(err)
+	}
+	if err := updater.UpdateFeatureStatus("F002", StatusAtRisk); err != nil {
+		t.Fatal(err)
+	}
+
+	content, _ := os.ReadFile(path)
+	if !strings.Contains(string(content), "# Managed by Hermes") || !strings.Contains(string(content), "# Feature 2: Reports") {
+		t.Errorf("expected comments and body to be kept, got:\n%s", content)
+	}
+	feature, _ = NewReader(tmpDir).GetFeatureByID("F002")
+	if feature.Status != StatusAtRisk {
+		t.Errorf("expected feature status AT_RISK, got %s", feature.Status)
+	}
+	if got := feature.Tasks[0]; got.Status != StatusInProgress || got.Retries != 3 || got.UpdatedAt.IsZero() {
+		t.Errorf("expected a restarted task to count a retry, got %+v", got)
+	}
+	if got := feature.Tasks[1]; got.Status != StatusInProgress || got.Retries != 0 || got.UpdatedAt.IsZero() {
+		t.Errorf("expected the first start not to count as a retry, got %+v", got)
+	}
+}
+
+func TestParseFeatureStrict(t *testing.T) {
+	content := `# Feature 3: Search
+
+**Feature ID:** F003
+**Status:** NOT_STARTED
+
+## Tasks
+
+### T020: Index documents
+
+**Status:** DONE
+**Priority:** high
+
+**Dependencies:** All backend features
+
+**Success Criteria:**
+- Documents are indexed
+
+### T021 Query API
+
+### T022: Ranking
+
+**Dependencies:** T022
+`
+	feature, diags := ParseFeatureStrict(content, "003-search.md")
+	if feature == nil || len(feature.Tasks) != 2 {
+		t.Fatalf("expected the feature to be parsed, got %+v", feature)
+	}
+
+	want := []string{
+		"003-search.md:10: error: unknown status \"DONE\"",
+		"003-search.md:11: warning: priority \"high\" is not P1-P4, assuming P2",
+		"003-search.md:18: error: malformed task header \"### T021 Query API\"",
+		"003-search.md:20: warning: task T022 has no **Status:** line",
+		"003-search.md:8: error: task T020 has dependency \"All backend features\" that is not a task ID",
+		"003-search.md:20: warning: task T022 has no success criteria",
+		"003-search.md:20: error: task T022 depends on itself",
+	}
+	var got []string
+	for _, d := range diags {
+		got = append(got, d.String())
+	}
+	if len(got) != len(want) {
+		t.Fatalf("expected %d diagnostics, got:\n%s", len(want), strings.Join(got, "\n"))
+	}
+	for i := range want {
+		if !strings.HasPrefix(got[i], want[i]) {
+			t.Errorf("diagnostic %d: expected %q, got %q", i, want[i], got[i])
+		}
+	}
+}
+
+func TestReaderValidate(t *testing.T) {
+	tmpDir := setupTestDir(t)
+	defer os.RemoveAll(tmpDir)
+	tasksDir := filepath.Join(tmpDir, ".hermes", "tasks")
+
+	diags, err := NewReader(tmpDir).Validate()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if errs, _ := CountDiagnostics(diags); errs != 0 {
+		t.Fatalf("expected the test feature to be valid, got %v", diags)
+	}
+
+	os.WriteFile(filepath.Join(tasksDir, "002-more.md"), []byte(`# Feature 2: More
+
+**Feature ID:** F002
+
+### T001: Clash
+
+**Status:** NOT_STARTED
+**Dependencies:** T099
+
+**Success Criteria:**
+- Done
+`), 0644)
+	os.WriteFile(filepath.Join(tasksDir, "003-broken.yaml"), []byte("id: F003\ntasks:\n  - id: T050\n   name: bad indent\n"), 0644)
+
+	diags, err = NewReader(tmpDir).Validate()
+	if err != nil {
+		t.Fatal(err)
+	}
+	var messages []string
+	for _, d := range diags {
+		messages = append(messages, d.String())
+	}
+	joined := strings.Join(messages, "\n")
+	for _, want := range []string{"002-more.md:5: warning: task ID T001 is also used by another feature, other features must refer to it as F002/T001", "002-more.md:5: error: task T001 depends on unknown task T099", "003-broken.yaml:2: error:"} {
+		if !strings.Contains(joined, want) {
+			t.Errorf("expected %q in:\n%s", want, joined)
+		}
+	}
+}
+
+func TestQualifiedDependencies(t *testing.T) {
+	tmpDir := setupTestDir(t)
+	defer os.RemoveAll(tmpDir)
+	tasksDir := filepath.Join(tmpDir, ".hermes", "tasks")
+
+	// F002 was generated independently and reuses T001 and T002
+	os.WriteFile(filepath.Join(tasksDir, "002-billing.md"), []byte(`# Feature 2: Billing
+
+**Feature ID:** F002
+
+### T001: Invoice model
+
+**Status:** NOT_STARTED
+
+### T002: Invoice API
+
+**Status:** NOT_STARTED
+**Dependencies:** T001, F001/T002
+`), 0644)
+
+	reader := NewReader(tmpDir)
+	tasks, err := reader.GetAllTasks()
+	if err != nil {
+		t.Fatal(err)
+	}
+	ids := make(map[string]Task)
+	for _, tk := range tasks {
+		ids[tk.ID] = tk
+	}
+	for _, id := range []string{"F001/T001", "F001/T002", "F002/T001", "F002/T002"} {
+		if _, ok := ids[id]; !ok {
+			t.Fatalf("expected duplicate IDs to be qualified, got %v", ids)
+		}
+	}
+	if deps := ids["F002/T002"].Dependencies; len(deps) != 2 || deps[0] != "F002/T001" || deps[1] != "F001/T002" {
+		t.Errorf("expected dependencies to resolve within the feature and by qualified ID, got %v", deps)
+	}
+
+	if _, err := reader.GetTaskByID("T001"); err == nil {
+		t.Error("expected a plain duplicate ID to be ambiguous")
+	}
+	if got, _ := reader.GetTaskByID("F002/T001"); got == nil || got.Name != "Invoice model" {
+		t.Errorf("expected the qualified ID to find F002's task, got %+v", got)
+	}
+
+	if err := NewStatusUpdater(tmpDir).UpdateTaskStatus("F002/T002", StatusInProgress); err != nil {
+		t.Fatal(err)
+	}
+	if got, _ := NewReader(tmpDir).GetTaskByID("F001/T002"); got.Status == StatusInProgress {
+		t.Error("expected F001's task to be left alone")
+	}
+	if got, _ := NewReader(tmpDir).GetTaskByID("F002/T002"); got.Status != StatusInProgress {
+		t.Errorf("expected F002's task to be in progress, got %s", got.Status)
+	}
+}
+
+func TestSubtasks(t *testing.T) {
+	tmpDir := setupTestDir(t)
+	defer os.RemoveAll(tmpDir)
+	path := filepath.Join(tmpDir, ".hermes", "tasks", "002-profile.md")
+	os.WriteFile(path, []byte(`# Feature 2: Profile
+
+**Feature ID:** F002
+
+### T010: Profile page
+
+**Status:** IN_PROGRESS
+
+#### Subtasks
+
+- [x] T010.1: Add the route
+- [ ] T010.2: Render the form
+- T010.3: Save changes
+- [ ] T011.1: Misnumbered step
+`), 0644)
+
+	reader := NewReader(tmpDir)
+	tk, err := reader.GetTaskByID("T010")
+	if err != nil || tk == nil {
+		t.Fatalf("GetTaskByID failed: %v", err)
+	}
+	if len(tk.Subtasks) != 4 || tk.Subtasks[0].Status != StatusCompleted || tk.Subtasks[2].Name != "Save changes" {
+		t.Fatalf("unexpected subtasks: %+v", tk.Subtasks)
+	}
+	if next := tk.NextSubtask(); next == nil || next.ID != "T010.2" {
+		t.Errorf("expected next subtask T010.2, got %+v", next)
+	}
+
+	// T001 is completed, T002 and T003 not and T010 a quarter done
+	progress, _ := reader.GetProgress()
+	if progress.Percentage != 31.25 {
+		t.Errorf("expected subtasks to count toward progress, got %.2f%%", progress.Percentage)
+	}
+
+	sub, remaining, err := NewStatusUpdater(tmpDir).CompleteNextSubtask(tk)
+	if err != nil || sub == nil || sub.ID != "T010.2" || remaining != 2 {
+		t.Fatalf("CompleteNextSubtask = %+v, %d, %v", sub, remaining, err)
+	}
+	content, _ := os.ReadFile(path)
+	if !strings.Contains(string(content), "- [x] T010.2: Render the form") {
+		t.Errorf("expected subtask to be checked off:\n%s", content)
+	}
+	if progress, _ := reader.GetProgress(); progress.Percentage != 37.5 {
+		t.Errorf("expected 37.5%% progress, got %.2f%%", progress.Percentage)
+	}
+
+	parsed := ParseTaskBlocks(FormatTask(tk), "F002")
+	if len(parsed) != 1 || len(parsed[0].Subtasks) != 4 || parsed[0].Subtasks[0].Status != StatusCompleted {
+		t.Errorf("expected subtasks to survive FormatTask, got %+v", parsed)
+	}
+
+	_, diags := ParseFeatureStrict(string(content), path)
+	found := false
+	for _, d := range diags {
+		found = found || strings.Contains(d.Message, "subtask T011.1")
+	}
+	if !found {
+		t.Errorf("expected a warning for the misnumbered subtask, got %v", diags)
+	}
+}
+
+func TestSetActualDuration(t *testing.T) {
+	tmpDir := setupTestDir(t)
+	defer os.RemoveAll(tmpDir)
+
+	updater := NewStatusUpdater(tmpDir)
+	if err := updater.SetActualDuration("T002", 95*time.Minute+300*time.Millisecond); err != nil {
+		t.Fatal(err)
+	}
+	// Setting it again replaces the line
+	if err := updater.SetActualDuration("T002", 2*time.Hour); err != nil {
+		t.Fatal(err)
+	}
+	content, _ := os.ReadFile(filepath.Join(tmpDir, ".hermes", "tasks", "001-user-auth.md"))
+	if n := strings.Count(string(content), "**Actual Duration:**"); n != 1 {
+		t.Errorf("expected one Actual Duration line, got %d", n)
+	}
+
+	tk, _ := NewReader(tmpDir).GetTaskByID("T002")
+	if tk == nil || tk.ActualDuration != 2*time.Hour {
+		t.Fatalf("expected an actual duration of 2h, got %+v", tk)
+	}
+	if !strings.Contains(FormatTask(tk), "**Actual Duration:** 2h0m0s") {
+		t.Error("expected FormatTask to keep the actual duration")
+	}
+}
+
+func TestBlockTask(t *testing.T) {
+	tmpDir := setupTestDir(t)
+	defer os.RemoveAll(tmpDir)
+	yamlPath := filepath.Join(tmpDir, ".hermes", "tasks", "002-jobs.yaml")
+	os.WriteFile(yamlPath, []byte(testYAMLFeature), 0644)
+
+	updater := NewStatusUpdater(tmpDir)
+	reader := NewReader(tmpDir)
+	for _, id := range []string{"T002", "T011"} {
+		if err := updater.BlockTask(id, "Needs the\nDATABASE_URL secret"); err != nil {
+			t.Fatal(err)
+		}
+		tk, _ := reader.GetTaskByID(id)
+		if tk.Status != StatusBlocked || tk.BlockedReason != "Needs the DATABASE_URL secret" {
+			t.Errorf("expected %s to be blocked with its reason, got %s %q", id, tk.Status, tk.BlockedReason)
+		}
+
+		if err := updater.SetBlockedReason(id, ""); err != nil {
+			t.Fatal(err)
+		}
+		if tk, _ := reader.GetTaskByID(id); tk.BlockedReason != "" {
+			t.Errorf("expected the reason of %s to be cleared, got %q", id, tk.BlockedReason)
+		}
+	}
+
+	content, _ := os.ReadFile(filepath.Join(tmpDir, ".hermes", "tasks", "001-user-auth.md"))
+	if strings.Contains(string(content), "Blocked Reason") {
+		t.Error("expected the Blocked Reason line to be removed")
+	}
+}
+
+func TestPriorityAging(t *testing.T) {
+	tmpDir := setupTestDir(t)
+	defer os.RemoveAll(tmpDir)
+	path := filepath.Join(tmpDir, ".hermes", "tasks", "002-profile.md")
+	os.WriteFile(path, []byte(`# Feature 2: Profile
+
+**Feature ID:** F002
+
+### T010: Profile page
+
+**Status:** NOT_STARTED
+**Priority:** P3
+`), 0644)
+
+	reader := NewReader(tmpDir)
+	for i := 0; i < 5; i++ {
+		if next, _ := reader.GetNextTask(); next == nil || next.ID != "T002" {
+			t.Fatalf("without aging expected T002, got %+v", next)
+		}
+	}
+
+	// T010 waits 2 loops per level: P3 -> P2 -> P1, then wins the tie with T002
+	reader.SetPriorityAging(2)
+	var picked []string
+	for i := 0; i < 6; i++ {
+		next, err := reader.GetNextTask()
+		if err != nil || next == nil {
+			t.Fatalf("GetNextTask failed: %v", err)
+		}
+		picked = append(picked, next.ID)
+	}
+	want := []string{"T002", "T002", "T002", "T002", "T010", "T002"}
+	if !slices.Equal(picked, want) {
+		t.Errorf("expected %v, got %v", want, picked)
+	}
+
+	if got := Priority("P4").raise(2); got != PriorityP2 {
+		t.Errorf("expected P4 raised twice = P2, got %s", got)
+	}
+	if got := PriorityP2.raise(5); got != PriorityP1 {
+		t.Errorf("expected P2 raised past P1 = P1, got %s", got)
+	}
+}
+
+func TestMilestone(t *testing.T) {
+	for _, tt := range []struct {
+		a, b string
+		want int
+	}{
+		{"v1.0.0", "1.0.0", 0},
+		{"1.0", "v1.0.0", 0},
+		{"v1.2.0", "v1.10.0", -1},
+		{"v2.0.0", "v1.9.9", 1},
+		{"v1.0.0-rc1", "v1.0.0", -1},
+		{"v1.0.0", "v1.0.0-rc1", 1},
+	} {
+		if got := CompareVersions(tt.a, tt.b); got != tt.want {
+			t.Errorf("CompareVersions(%q, %q) = %d, want %d", tt.a, tt.b, got, tt.want)
+		}
+	}
+
+	tmpDir := setupTestDir(t)
+	defer os.RemoveAll(tmpDir)
+	path := filepath.Join(tmpDir, ".hermes", "tasks", "002-billing.md")
+	os.WriteFile(path, []byte(`# Feature 2: Billing
+
+**Feature ID:** F002
+**Target Version:** v1.1.0
+
+### T010: Add invoices
+
+**Status:** IN_PROGRESS
+**Priority:** P1
+`), 0644)
+
+	// F002 targets v1.1.0, so its in-progress task is left alone until v1.1.0
+	reader := NewReader(tmpDir)
+	reader.SetMilestone("v1.0.0")
+	if next, _ := reader.GetNextTask(); next == nil || next.ID != "T002" {
+		t.Errorf("expected T002 of the v1.0.0 milestone, got %+v", next)
+	}
+	reader.SetMilestone("1.1")
+	if next, _ := reader.GetNextTask(); next == nil || next.ID != "T010" {
+		t.Errorf("expected T010 of the v1.1.0 milestone, got %+v", next)
+	}
+
+	reader.SetMilestone("v0.9.0")
+	all, _ := reader.GetAllTasks()
+	filtered, err := reader.FilterMilestone(all)
+	if err != nil {
+		t.Fatal(err)
+	}
+	// Only the completed T001 is kept, for dependency resolution
+	if len(filtered) != 1 || filtered[0].ID != "T001" {
+		t.Errorf("expected only completed T001 below v1.0.0, got %d tasks", len(filtered))
+	}
+}
+
+func TestTaskTemplates(t *testing.T) {
+	tmpDir := setupTestDir(t)
+	defer os.RemoveAll(tmpDir)
+
+	tmpl, err := LoadTemplate(tmpDir, "api-endpoint")
+	if err != nil {
+		t.Fatal(err)
+	}
+	tk := &Task{ID: "T010", Name: "List orders", FeatureID: "F001", SuccessCriteria: []string{"Paginated"}}
+	vars := map[string]string{"name": "List orders", "slug": "list-orders", "feature": "Orders"}
+	if err := tmpl.Apply(tk, vars); err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(tk.Description, "List orders endpoint for Orders") {
+		t.Errorf("expected placeholders replaced in description, got %q", tk.Description)
+	}
+	if len(tk.FilesToTouch) != 3 || !strings.Contains(tk.FilesToTouch[0], "api/list-orders.go") {
+		t.Errorf("expected files from template, got %v", tk.FilesToTouch)
+	}
+	if len(tk.SuccessCriteria) != 1 || tk.SuccessCriteria[0] != "Paginated" {
+		t.Errorf("expected given success criteria kept, got %v", tk.SuccessCriteria)
+	}
+	if tk.EstimatedEffort != "1 day" || tk.Priority != PriorityP2 {
+		t.Errorf("expected effort and default priority from template, got %q %q", tk.EstimatedEffort, tk.Priority)
+	}
+
+	// User templates replace built-in ones of the same name
+	os.MkdirAll(GetTemplatesDir(tmpDir), 0755)
+	os.WriteFile(filepath.Join(GetTemplatesDir(tmpDir), "bug-fix.md"), []byte("**Priority:** P1\n\n#### Description\n\nHotfix {{name}}\n"), 0644)
+	tmpl, err = LoadTemplate(tmpDir, "bug-fix")
+	if err != nil || tmpl.Path == "" {
+		t.Fatalf("expected user bug-fix template, got %+v, %v", tmpl, err)
+	}
+	tk = &Task{ID: "T011", Name: "Crash on login", FeatureID: "F001"}
+	if err := tmpl.Apply(tk, map[string]string{"name": "Crash on login"}); err != nil {
+		t.Fatal(err)
+	}
+	if tk.Priority != PriorityP1 || tk.Description != "Hotfix Crash on login" {
+		t.Errorf("unexpected task from user template: %+v", tk)
+	}
+
+	if _, err := LoadTemplate(tmpDir, "missing"); err == nil || !strings.Contains(err.Error(), "api-endpoint") {
+		t.Errorf("expected not found error listing templates, got %v", err)
+	}
+}
+
+func TestVerifyCommands(t *testing.T) {
+	tk, err := ParseTaskBlock(`### T010: Add parser
+
+#### Success Criteria
+
+- [ ] Parser handles empty input
+- [ ] cmd: go test ./parser/... -run 'TestParse(Empty|Large)', -count=1
+- [x] cmd: `+"`go vet ./...`"+`
+- [ ] cmd:
+`, "F001")
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []string{"go test ./parser/... -run 'TestParse(Empty|Large)', -count=1", "go vet ./..."}
+	if got := tk.VerifyCommands(); !slices.Equal(got, want) {
+		t.Errorf("expected commands %q, got %q", want, got)
+	}
+}
+
+func TestGateCommands(t *testing.T) {
+	tk, err := ParseTaskBlock(`### T010: Add parser
+
+**Test Command:** `+"`go test ./parser/...`"+`
+
+#### Success Criteria
+
+- [ ] cmd: go vet ./...
+`, "F001")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if tk.TestCommand != "go test ./parser/..." {
+		t.Fatalf("expected test command to be parsed, got %q", tk.TestCommand)
+	}
+	if !strings.Contains(FormatTask(tk), "**Test Command:** `go test ./parser/...`") {
+		t.Error("expected test command to be written back")
+	}
+
+	want := []string{"go test ./parser/...", "go vet ./..."}
+	if got := tk.GateCommands("make test"); !slices.Equal(got, want) {
+		t.Errorf("expected task test command to override default, got %q", got)
+	}
+
+	tk.TestCommand = ""
+	want = []string{"make test", "go vet ./..."}
+	if got := tk.GateCommands("make test"); !slices.Equal(got, want) {
+		t.Errorf("expected default test command, got %q", got)
+	}
+
+	tk.TestCommand = NoTestCommand
+	want = []string{"go vet ./..."}
+	if got := tk.GateCommands("make test"); !slices.Equal(got, want) {
+		t.Errorf("expected default test command to be skipped, got %q", got)
+	}
+
+	want = []string{"go build ./...", "golangci-lint run", "go vet ./..."}
+	if got := tk.GateCommands("make test", " go build ./... ", "", "golangci-lint run"); !slices.Equal(got, want) {
+		t.Errorf("expected build and lint commands to run first, got %q", got)
+	}
+}
+
+func TestResolveTimeout(t *testing.T) {
+	tk, err := ParseTaskBlock(`### T010: Migrate orders table
+
+**Estimated Effort:** 3 days
+**Timeout:** 1800s
+
+#### Description
+
+Migrate the orders table.
+`, "F001")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if tk.Timeout != 30*time.Minute {
+		t.Fatalf("expected timeout to be parsed, got %v", tk.Timeout)
+	}
+	if !strings.Contains(FormatTask(tk), "**Timeout:** 30m0s") {
+		t.Error("expected timeout to be written back")
+	}
+
+	efforts := map[string]int{"1 day": 600, "2 days": 1200, "1 week": 3600, "soon": 60}
+	if got := tk.ResolveTimeout(5*time.Minute, efforts); got != 30*time.Minute {
+		t.Errorf("expected task timeout to win, got %v", got)
+	}
+
+	tk.Timeout = 0
+	if got := tk.ResolveTimeout(5*time.Minute, efforts); got != 20*time.Minute {
+		t.Errorf("expected timeout of the largest effort not above 3 days, got %v", got)
+	}
+	tk.EstimatedEffort = "4 hours"
+	if got := tk.ResolveTimeout(5*time.Minute, efforts); got != 5*time.Minute {
+		t.Errorf("expected default timeout below all efforts, got %v", got)
+	}
+
+	for value, want := range map[string]time.Duration{"1800": 30 * time.Minute, "45m": 45 * time.Minute, " 2h ": 2 * time.Hour} {
+		if got, err := ParseTimeout(value); err != nil || got != want {
+			t.Errorf("ParseTimeout(%q) = %v, %v, want %v", value, got, err, want)
+		}
+	}
+	if _, err := ParseTimeout("forever"); err == nil {
+		t.Error("expected invalid timeout to fail")
+	}
+}
+
+func TestWorkspace(t *testing.T) {
+	tk, err := ParseTaskBlock("### T030: Add orders endpoint\n\n**Workspace:** `./services/api/`\n\n**Files to Touch:** services/api/orders.go, services/web/orders.ts\n\n**Success Criteria:**\n- Orders are listed\n", "F001")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if tk.Workspace != "services/api" {
+		t.Fatalf("expected workspace to be cleaned, got %q", tk.Workspace)
+	}
+	if !strings.Contains(FormatTask(tk), "**Workspace:** services/api") {
+		t.Error("expected workspace to be written back")
+	}
+	if got := tk.WorkspaceDir("/repo"); got != filepath.Join("/repo", "services", "api") {
+		t.Errorf("unexpected workspace dir %q", got)
+	}
+	if !tk.InWorkspace("services/api/orders.go") || tk.InWorkspace("services/web/orders.ts") || tk.InWorkspace("services/apiv2/main.go") {
+		t.Error("expected only files below the workspace to be inside it")
+	}
+
+	tk.FilesToTouch = nil
+	if got := tk.ScopeFiles(); len(got) != 1 || got[0] != "services/api" {
+		t.Errorf("expected task without files to be scoped to its workspace, got %v", got)
+	}
+
+	for value, want := range map[string]string{".": "", "a\\b": "a/b", "../other": "../other", "/abs": "/abs"} {
+		if got := CleanWorkspace(value); got != want {
+			t.Errorf("CleanWorkspace(%q) = %q, want %q", value, got, want)
+		}
+	}
+	if ValidWorkspace("../other") || ValidWorkspace("/abs") || !ValidWorkspace("services/api") {
+		t.Error("expected workspaces outside the repository to be invalid")
+	}
+
+	content := "# Feature 1: Orders\n\n**Feature ID:** F001\n\n### T030: Add orders endpoint\n\n**Status:** NOT_STARTED\n**Workspace:** services/api\n\n**Files to Touch:** services/web/orders.ts\n\n**Success Criteria:**\n- Orders are listed\n\n### T031: Share types\n\n**Status:** NOT_STARTED\n**Workspace:** ../shared\n\n**Success Criteria:**\n- Types are shared\n"
+	_, diags := ParseFeatureStrict(content, "001-orders.md")
+	var got []string
+	for _, d := range diags {
+		got = append(got, d.String())
+	}
+	want := []string{
+		"001-orders.md:5: warning: task T030 touches services/web/orders.ts outside its workspace services/api",
+		"001-orders.md:15: error: task T031 has workspace \"../shared\" outside the repository",
+	}
+	if strings.Join(got, "\n") != strings.Join(want, "\n") {
+		t.Errorf("unexpected diagnostics:\n%s", strings.Join(got, "\n"))
+	}
+}
+
+func TestTags(t *testing.T) {
+	content := "# Feature 1: Storage\n\n**Feature ID:** F001\n**Tags:** backend\n\n## Tasks\n\n### T001: Add orders table\n\n**Status:** NOT_STARTED\n**Tags:** migration, `db`\n\n### T002: Document the schema\n\n**Status:** NOT_STARTED\n"
+	feature, err := ParseFeature(content, "001-storage.md")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if strings.Join(feature.Tags, ",") != "backend" {
+		t.Errorf("expected feature tags [backend], got %v", feature.Tags)
+	}
+	if got := feature.Tasks[0].Tags; strings.Join(got, ",") != "migration,db" {
+		t.Errorf("expected task tags [migration db], got %v", got)
+	}
+	if got := feature.Tasks[1].Tags; len(got) != 0 {
+		t.Errorf("expected the feature's tags not to be read as the task's, got %v", got)
+	}
+	if !strings.Contains(FormatTask(&feature.Tasks[0]), "**Tags:** migration, db\n") {
+		t.Error("expected task tags to be written back")
+	}
+	if !strings.Contains(FormatFeature(feature), "**Tags:** backend\n") {
+		t.Error("expected feature tags to be written back")
+	}
+}
+
+func TestPRDSection(t *testing.T) {
+	content := "# Feature 1: Auth\n\n**Feature ID:** F001\n\n## Tasks\n\n### T001: Create login endpoint\n\n**Status:** NOT_STARTED\n**PRD Section:** 3.1 Login\n\n### T002: Add logout\n\n**Status:** NOT_STARTED\n"
+	feature, err := ParseFeature(content, "001-auth.md")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := feature.Tasks[0].PRDSection; got != "3.1 Login" {
+		t.Errorf("expected PRDSection = '3.1 Login', got %q", got)
+	}
+	if got := feature.Tasks[1].PRDSection; got != "" {
+		t.Errorf("expected empty PRDSection, got %q", got)
+	}
+	if !strings.Contains(FormatTask(&feature.Tasks[0]), "**PRD Section:** 3.1 Login\n") {
+		t.Error("expected the PRD section to be written back")
+	}
+}
@@ -0,0 +1,199 @@
+package task
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+// TaskTemplate pre-fills a new task for a common kind of work. Its content is
+// a task block without the header, in which {{name}}, {{slug}} and {{feature}}
+// are replaced by the task name, the task name as a slug and the feature name.
+type TaskTemplate struct {
+	Name    string
+	Content string
+	Path    string // File the template was read from, empty for built-in templates
+}
+
+// GetTemplatesDir returns the directory of user-defined task templates
+func GetTemplatesDir(basePath string) string {
+	return filepath.Join(basePath, ".hermes", "templates")
+}
+
+// builtinTemplates are available in every project. A user-defined template
+// with the same name replaces the built-in one.
+var builtinTemplates = map[string]string{
+	"api-endpoint": `**Estimated Effort:** 1 day
+
+#### Description
+
+Add the {{name}} endpoint for {{feature}}, with request validation, error
+responses and tests.
+
+#### Technical Details
+
+Follow the routing, handler and error response conventions of the existing
+endpoints. Validate input before touching storage.
+
+#### Files to Touch
+
+- ` + "`api/{{slug}}.go`" + ` (new)
+- ` + "`api/{{slug}}_test.go`" + ` (new)
+- ` + "`api/routes.go`" + ` (modify)
+
+#### Success Criteria
+
+- [ ] Endpoint is registered and documented
+- [ ] Invalid requests are rejected with a 4xx response
+- [ ] Handler is covered by tests for success and error cases
+`,
+	"bug-fix": `**Estimated Effort:** 0.5 days
+
+#### Description
+
+Fix: {{name}}. Reproduce the bug with a failing test first, then fix the root
+cause.
+
+#### Technical Details
+
+Keep the fix minimal and avoid unrelated refactoring.
+
+#### Success Criteria
+
+- [ ] A regression test reproduces the bug and passes after the fix
+- [ ] Existing tests still pass
+`,
+	"refactor": `**Estimated Effort:** 1 day
+
+#### Description
+
+Refactor: {{name}}, without changing behavior.
+
+#### Technical Details
+
+Work in small steps and keep the tests green after each one.
+
+#### Success Criteria
+
+- [ ] Behavior is unchanged and all existing tests pass
+- [ ] No new lint or vet warnings
+`,
+	"migration": `**Estimated Effort:** 1 day
+
+#### Description
+
+Add a database migration for {{name}}, with a matching rollback.
+
+#### Technical Details
+
+Migrations must be idempotent and safe to run on existing data.
+
+#### Files to Touch
+
+- ` + "`migrations/{{slug}}.sql`" + ` (new)
+
+#### Success Criteria
+
+- [ ] Migration applies cleanly on an existing database
+- [ ] Rollback restores the previous schema
+- [ ] Affected queries and models are updated
+`,
+	"docs": `**Estimated Effort:** 0.5 days
+
+#### Description
+
+Document {{name}} for {{feature}}.
+
+#### Files to Touch
+
+- ` + "`README.md`" + ` (modify)
+
+#### Success Criteria
+
+- [ ] Usage is explained with an example
+- [ ] Configuration options and defaults are listed
+`,
+}
+
+// ListTemplates returns the built-in and user-defined task templates sorted by name
+func ListTemplates(basePath string) ([]TaskTemplate, error) {
+	byName := make(map[string]TaskTemplate)
+	for name, content := range builtinTemplates {
+		byName[name] = TaskTemplate{Name: name, Content: content}
+	}
+
+	files, err := filepath.Glob(filepath.Join(GetTemplatesDir(basePath), "*.md"))
+	if err != nil {
+		return nil, err
+	}
+	for _, file := range files {
+		data, err := os.ReadFile(file)
+		if err != nil {
+			return nil, err
+		}
+		name := strings.TrimSuffix(filepath.Base(file), ".md")
+		byName[name] = TaskTemplate{Name: name, Content: string(data), Path: file}
+	}
+
+	templates := make([]TaskTemplate, 0, len(byName))
+	for _, tmpl := range byName {
+		templates = append(templates, tmpl)
+	}
+	sort.Slice(templates, func(i, j int) bool {
+		return templates[i].Name < templates[j].Name
+	})
+	return templates, nil
+}
+
+// LoadTemplate returns the task template with the given name
+func LoadTemplate(basePath, name string) (*TaskTemplate, error) {
+	templates, err := ListTemplates(basePath)
+	if err != nil {
+		return nil, err
+	}
+	var names []string
+	for i := range templates {
+		if templates[i].Name == name {
+			return &templates[i], nil
+		}
+		names = append(names, templates[i].Name)
+	}
+	return nil, fmt.Errorf("template %q not found, available: %s", name, strings.Join(names, ", "))
+}
+
+// Apply fills the parts of t that are still empty from the template.
+// vars holds the values of the {{name}}, {{slug}} and {{feature}} placeholders.
+func (tmpl *TaskTemplate) Apply(t *Task, vars map[string]string) error {
+	pairs := make([]string, 0, 2*len(vars))
+	for k, v := range vars {
+		pairs = append(pairs, "{{"+k+"}}", v)
+	}
+	content := strings.NewReplacer(pairs...).Replace(tmpl.Content)
+
+	filled, err := ParseTaskBlock(fmt.Sprintf("### %s: %s\n\n%s", t.ID, t.Name, content), t.FeatureID)
+	if err != nil {
+		return fmt.Errorf("invalid template %s: %w", tmpl.Name, err)
+	}
+
+	if t.Priority == "" {
+		t.Priority = filled.Priority
+	}
+	if t.EstimatedEffort == "" {
+		t.EstimatedEffort = filled.EstimatedEffort
+	}
+	if t.Description == "" {
+		t.Description = filled.Description
+	}
+	if t.TechnicalDetails == "" {
+		t.TechnicalDetails = filled.TechnicalDetails
+	}
+	if len(t.FilesToTouch) == 0 {
+		t.FilesToTouch = filled.FilesToTouch
+	}
+	if len(t.SuccessCriteria) == 0 {
+		t.SuccessCriteria = filled.SuccessCriteria
+	}
+	return nil
+}
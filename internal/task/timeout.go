@@ -0,0 +1,73 @@
+package task
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+)
+
+var effortRegex = regexp.MustCompile(`(?i)([\d.]+)\s*(day|week|hour)`)
+
+// HoursPerDay is the length of a working day in effort estimates
+const HoursPerDay = 8
+
+// EffortDays converts an effort estimate such as "2 days", "1 week" or "4 hours"
+// to days. It returns false when the estimate cannot be parsed.
+func EffortDays(effort string) (float64, bool) {
+	m := effortRegex.FindStringSubmatch(effort)
+	if len(m) < 3 {
+		return 0, false
+	}
+	n, err := strconv.ParseFloat(m[1], 64)
+	if err != nil {
+		return 0, false
+	}
+	switch strings.ToLower(m[2]) {
+	case "week":
+		n *= 5
+	case "hour":
+		n /= HoursPerDay
+	}
+	return n, true
+}
+
+// ParseTimeout parses the value of a **Timeout:** field, a duration such as
+// "30m" or "1800s" or a number of seconds
+func ParseTimeout(s string) (time.Duration, error) {
+	s = strings.TrimSpace(s)
+	if seconds, err := strconv.Atoi(s); err == nil {
+		s = strconv.Itoa(seconds) + "s"
+	}
+	d, err := time.ParseDuration(s)
+	if err != nil || d <= 0 {
+		return 0, fmt.Errorf("invalid timeout %q", s)
+	}
+	return d, nil
+}
+
+// ResolveTimeout returns how long the AI may work on the task: its own
+// timeout if set, else the timeout in seconds of the largest effort in
+// effortTimeouts not above its estimated effort, else defaultTimeout.
+// effortTimeouts maps effort estimates such as "2 days" to seconds.
+func (t *Task) ResolveTimeout(defaultTimeout time.Duration, effortTimeouts map[string]int) time.Duration {
+	if t.Timeout > 0 {
+		return t.Timeout
+	}
+	days, ok := EffortDays(t.EstimatedEffort)
+	if !ok {
+		return defaultTimeout
+	}
+	timeout := defaultTimeout
+	best := -1.0
+	for effort, seconds := range effortTimeouts {
+		d, ok := EffortDays(effort)
+		if !ok || d > days || d <= best || seconds <= 0 {
+			continue
+		}
+		best = d
+		timeout = time.Duration(seconds) * time.Second
+	}
+	return timeout
+}
@@ -1,5 +1,7 @@
 package task
 
+import "time"
+
 // Status represents the status of a task or feature
 type Status string
 
@@ -24,38 +26,66 @@ const (
 
 // Feature represents a feature with its tasks
 type Feature struct {
-	ID                string   `json:"id"`
-	Name              string   `json:"name"`
-	Status            Status   `json:"status"`
-	Priority          Priority `json:"priority"`
-	Description       string   `json:"description"`
-	Overview          string   `json:"overview"`
-	Goals             []string `json:"goals"`
-	TargetVersion     string   `json:"targetVersion"`
-	EstimatedDuration string   `json:"estimatedDuration"`
-	PerformanceTarget string   `json:"performanceTarget"`
-	RiskAssessment    string   `json:"riskAssessment"`
-	Tasks             []Task   `json:"tasks"`
-	FilePath          string   `json:"filePath"`
+	ID                string   `json:"id" yaml:"id,omitempty"`
+	Name              string   `json:"name" yaml:"name,omitempty"`
+	Status            Status   `json:"status" yaml:"status,omitempty"`
+	Priority          Priority `json:"priority" yaml:"priority,omitempty"`
+	Description       string   `json:"description" yaml:"description,omitempty"`
+	Overview          string   `json:"overview" yaml:"overview,omitempty"`
+	Goals             []string `json:"goals" yaml:"goals,omitempty"`
+	TargetVersion     string   `json:"targetVersion" yaml:"targetVersion,omitempty"`
+	EstimatedDuration string   `json:"estimatedDuration" yaml:"estimatedDuration,omitempty"`
+	PerformanceTarget string   `json:"performanceTarget" yaml:"performanceTarget,omitempty"`
+	RiskAssessment    string   `json:"riskAssessment" yaml:"riskAssessment,omitempty"`
+	Tasks             []Task   `json:"tasks" yaml:"tasks,omitempty"`
+	FilePath          string   `json:"filePath" yaml:"-"`
+	Source            string   `json:"source,omitempty" yaml:"source,omitempty"` // Sub-PRD the feature was generated from
+	Tags              []string `json:"tags,omitempty" yaml:"tags,omitempty"`     // Tags of all its tasks, see Task.Tags
 }
 
 // Task represents a single task within a feature
 type Task struct {
-	ID               string   `json:"id"`
-	Name             string   `json:"name"`
-	Status           Status   `json:"status"`
-	Priority         Priority `json:"priority"`
-	EstimatedEffort  string   `json:"estimatedEffort"`
-	Description      string   `json:"description"`
-	TechnicalDetails string   `json:"technicalDetails"`
-	FilesToTouch     []string `json:"filesToTouch"`
-	Dependencies     []string `json:"dependencies"`
-	SuccessCriteria  []string `json:"successCriteria"`
-	FeatureID        string   `json:"featureId"`
+	ID               string   `json:"id" yaml:"id,omitempty"`
+	Name             string   `json:"name" yaml:"name,omitempty"`
+	Status           Status   `json:"status" yaml:"status,omitempty"`
+	Priority         Priority `json:"priority" yaml:"priority,omitempty"`
+	EstimatedEffort  string   `json:"estimatedEffort" yaml:"estimatedEffort,omitempty"`
+	Description      string   `json:"description" yaml:"description,omitempty"`
+	TechnicalDetails string   `json:"technicalDetails" yaml:"technicalDetails,omitempty"`
+	FilesToTouch     []string `json:"filesToTouch" yaml:"filesToTouch,omitempty"`
+	Dependencies     []string `json:"dependencies" yaml:"dependencies,omitempty"`
+	SuccessCriteria  []string `json:"successCriteria" yaml:"successCriteria,omitempty"`
+	FeatureID        string   `json:"featureId" yaml:"-"`
+	PRDSection       string   `json:"prdSection,omitempty" yaml:"prdSection,omitempty"` // PRD heading the task was derived from
+	JiraKey          string   `json:"jiraKey,omitempty" yaml:"jiraKey,omitempty"`       // Jira issue the task was imported from
+	// Why the task is blocked and what is needed to unblock it
+	BlockedReason string `json:"blockedReason,omitempty" yaml:"blockedReason,omitempty"`
+	// Command that must pass before the task is completed, "none" to skip the configured default
+	TestCommand string `json:"testCommand,omitempty" yaml:"testCommand,omitempty"`
+	// Monorepo subdirectory the provider works in and the task's changes stay in
+	Workspace string `json:"workspace,omitempty" yaml:"workspace,omitempty"`
+	// Kinds of work, e.g. migration or docs, selecting the prompt templates of the task
+	Tags []string `json:"tags,omitempty" yaml:"tags,omitempty"`
+	// How long the AI may work on the task, overriding the configured timeout
+	Timeout time.Duration `json:"timeout,omitempty" yaml:"timeout,omitempty"`
 	// Parallel execution fields
-	DependsOn      []string `json:"dependsOn"`      // Explicit task dependencies (task IDs)
-	Parallelizable bool     `json:"parallelizable"` // Can run in parallel (default: true)
-	ExclusiveFiles []string `json:"exclusiveFiles"` // Files only this task should modify
+	DependsOn      []string `json:"dependsOn" yaml:"dependsOn,omitempty"`           // Explicit task dependencies (task IDs)
+	Parallelizable bool     `json:"parallelizable" yaml:"parallelizable,omitempty"` // Can run in parallel (default: true)
+	ExclusiveFiles []string `json:"exclusiveFiles" yaml:"exclusiveFiles,omitempty"` // Files only this task should modify
+	// Metadata maintained by Hermes in the feature file's frontmatter
+	UpdatedAt time.Time `json:"updatedAt,omitzero" yaml:"updatedAt,omitempty"` // Last status change
+	Retries   int       `json:"retries,omitempty" yaml:"retries,omitempty"`    // Times the task was started again
+	// Wall-clock time of all attempts, written as **Actual Duration:** when the task completes
+	ActualDuration time.Duration `json:"actualDuration,omitempty" yaml:"actualDuration,omitempty"`
+	// Steps worked through one at a time, listed under #### Subtasks
+	Subtasks []Subtask `json:"subtasks,omitempty" yaml:"subtasks,omitempty"`
+}
+
+// Subtask is a step of a task, identified as TXXX.N
+type Subtask struct {
+	ID     string `json:"id" yaml:"id"`
+	Name   string `json:"name" yaml:"name"`
+	Status Status `json:"status" yaml:"status,omitempty"`
 }
 
 // Progress represents overall task progress
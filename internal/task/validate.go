@@ -0,0 +1,359 @@
+package task
+
+import (
+	"fmt"
+	"os"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+
+	"go.yaml.in/yaml/v3"
+)
+
+// Severity of a problem found by strict parsing
+type Severity string
+
+const (
+	SeverityError   Severity = "error"
+	SeverityWarning Severity = "warning"
+)
+
+// Diagnostic is a problem the lenient parser skips over, such as a status it
+// does not know or a dependency that is not a task ID
+type Diagnostic struct {
+	File     string   `json:"file"`
+	Line     int      `json:"line,omitempty"` // 1-based, 0 when the problem concerns the whole file
+	Severity Severity `json:"severity"`
+	Message  string   `json:"message"`
+}
+
+// String formats a diagnostic as "file:line: severity: message"
+func (d Diagnostic) String() string {
+	if d.Line > 0 {
+		return fmt.Sprintf("%s:%d: %s: %s", d.File, d.Line, d.Severity, d.Message)
+	}
+	return fmt.Sprintf("%s: %s: %s", d.File, d.Severity, d.Message)
+}
+
+var (
+	taskIDRefRegex    = regexp.MustCompile(`^(F\d+/)?T\d+$`)
+	taskHeaderLike    = regexp.MustCompile(`^###\s*T\d`)
+	statusLineRegex   = regexp.MustCompile(`^\*\*Status:\*\*\s*(.*)$`)
+	priorityLineRegex = regexp.MustCompile(`^\*\*Priority:\*\*\s*(.*)$`)
+	yamlErrorLine     = regexp.MustCompile(`line (\d+)`)
+)
+
+// strictFeature is a feature parsed in strict mode with the lines its tasks
+// start on, in file order
+type strictFeature struct {
+	feature   *Feature
+	taskLines map[string][]int
+}
+
+// taskLine returns the line of the n-th task with the given ID
+func (sf *strictFeature) taskLine(id string, n int) int {
+	if lines := sf.taskLines[id]; n < len(lines) {
+		return lines[n]
+	}
+	return 0
+}
+
+// ParseFeatureStrict parses a feature file like ParseFeature or
+// ParseFeatureYAML and reports what the lenient parser would silently
+// tolerate. The feature is nil when the file cannot be parsed at all.
+func ParseFeatureStrict(content, filePath string) (*Feature, []Diagnostic) {
+	sf, diags := parseStrict(content, filePath)
+	if sf == nil {
+		return nil, diags
+	}
+	return sf.feature, diags
+}
+
+func parseStrict(content, filePath string) (*strictFeature, []Diagnostic) {
+	var sf *strictFeature
+	var diags []Diagnostic
+	if IsYAMLFile(filePath) {
+		sf, diags = lintYAML(content, filePath)
+	} else {
+		sf, diags = lintMarkdown(content, filePath)
+	}
+	if sf == nil {
+		return nil, diags
+	}
+
+	report := func(line int, severity Severity, format string, args ...interface{}) {
+		diags = append(diags, Diagnostic{File: filePath, Line: line, Severity: severity, Message: fmt.Sprintf(format, args...)})
+	}
+	f := sf.feature
+	if f.ID == "" {
+		report(0, SeverityError, "missing feature ID")
+	}
+	if f.Name == "" {
+		report(0, SeverityError, "missing feature name")
+	}
+	if len(f.Tasks) == 0 {
+		report(0, SeverityWarning, "feature has no tasks")
+	}
+
+	seen := make(map[string]int)
+	for _, t := range f.Tasks {
+		line := sf.taskLine(t.ID, seen[t.ID])
+		if seen[t.ID] > 0 {
+			report(line, SeverityError, "duplicate task ID %s", t.ID)
+		}
+		seen[t.ID]++
+		if len(t.SuccessCriteria) == 0 {
+			report(line, SeverityWarning, "task %s has no success criteria", t.ID)
+		}
+		for _, s := range t.Subtasks {
+			if !strings.HasPrefix(s.ID, t.ID+".") {
+				report(line, SeverityWarning, "subtask %s of task %s should be numbered %s.N", s.ID, t.ID, t.ID)
+			}
+		}
+		if t.Workspace != "" {
+			if !ValidWorkspace(t.Workspace) {
+				report(line, SeverityError, "task %s has workspace %q outside the repository", t.ID, t.Workspace)
+			}
+			for _, file := range t.FilesToTouch {
+				if !t.InWorkspace(file) {
+					report(line, SeverityWarning, "task %s touches %s outside its workspace %s", t.ID, file, t.Workspace)
+				}
+			}
+		}
+		for _, dep := range append(append([]string{}, t.Dependencies...), t.DependsOn...) {
+			switch {
+			case dep == t.ID:
+				report(line, SeverityError, "task %s depends on itself", t.ID)
+			case !taskIDRefRegex.MatchString(dep):
+				report(line, SeverityError, "task %s has dependency %q that is not a task ID and is ignored when scheduling", t.ID, dep)
+			}
+		}
+	}
+	return sf, diags
+}
+
+// lintMarkdown parses a markdown feature file, reporting malformed lines
+func lintMarkdown(content, filePath string) (*strictFeature, []Diagnostic) {
+	var diags []Diagnostic
+	report := func(line int, severity Severity, format string, args ...interface{}) {
+		diags = append(diags, Diagnostic{File: filePath, Line: line, Severity: severity, Message: fmt.Sprintf(format, args...)})
+	}
+
+	// Line numbers of the body start after the frontmatter
+	offset := 0
+	var fm *Frontmatter
+	if meta, body, ok := splitFrontmatter(content); ok {
+		offset = strings.Count(content[:len(content)-len(body)], "\n")
+		var err error
+		if fm, err = parseFrontmatter(meta); err != nil {
+			report(1+yamlLine(err), SeverityError, "%v", err)
+			return nil, diags
+		}
+	}
+
+	feature, err := ParseFeature(content, filePath)
+	if err != nil {
+		report(0, SeverityError, "%v", err)
+		return nil, diags
+	}
+
+	sf := &strictFeature{feature: feature, taskLines: make(map[string][]int)}
+	lines := strings.Split(content, "\n")
+	header := false
+	currentTask, taskLine, statusSeen := "", 0, true
+	endTask := func() {
+		if currentTask != "" && !statusSeen {
+			report(taskLine, SeverityWarning, "task %s has no **Status:** line, assuming %s", currentTask, StatusNotStarted)
+		}
+	}
+	for i := offset; i < len(lines); i++ {
+		n := i + 1
+		line := strings.TrimSpace(lines[i])
+		switch {
+		case featureHeaderRegex.MatchString(line):
+			header = true
+		case taskHeaderLike.MatchString(line):
+			endTask()
+			m := taskHeaderRegex.FindStringSubmatch(line)
+			if m == nil {
+				report(n, SeverityError, "malformed task header %q, expected \"### T001: Task name\"", line)
+				currentTask = ""
+				continue
+			}
+			currentTask, taskLine, statusSeen = m[1], n, false
+			sf.taskLines[currentTask] = append(sf.taskLines[currentTask], n)
+		default:
+			if m := statusLineRegex.FindStringSubmatch(line); m != nil {
+				statusSeen = true
+				if value := strings.TrimSpace(m[1]); !validStatus(Status(value)) {
+					report(n, SeverityError, "unknown status %q", value)
+				}
+			} else if m := priorityLineRegex.FindStringSubmatch(line); m != nil {
+				if !priorityRegex.MatchString(line) {
+					report(n, SeverityWarning, "priority %q is not P1-P4, assuming %s", strings.TrimSpace(m[1]), PriorityP2)
+				}
+			}
+		}
+	}
+	endTask()
+
+	if !header && feature.Name == "" {
+		report(offset+1, SeverityError, "missing \"# Feature N: Name\" header")
+	}
+
+	if fm != nil {
+		if fm.Status != "" && !validStatus(fm.Status) {
+			report(1, SeverityError, "frontmatter has unknown status %q", fm.Status)
+		}
+		ids := make([]string, 0, len(fm.Tasks))
+		for id := range fm.Tasks {
+			ids = append(ids, id)
+		}
+		sort.Strings(ids)
+		for _, id := range ids {
+			if _, ok := sf.taskLines[id]; !ok {
+				report(1, SeverityWarning, "frontmatter has metadata for task %s, which is not in the file", id)
+			} else if s := fm.Tasks[id].Status; s != "" && !validStatus(s) {
+				report(1, SeverityError, "frontmatter has unknown status %q for task %s", s, id)
+			}
+		}
+	}
+	return sf, diags
+}
+
+// lintYAML parses a YAML feature file, reporting the line of syntax errors
+func lintYAML(content, filePath string) (*strictFeature, []Diagnostic) {
+	feature, err := ParseFeatureYAML(content, filePath)
+	if err != nil {
+		return nil, []Diagnostic{{File: filePath, Line: yamlLine(err), Severity: SeverityError, Message: err.Error()}}
+	}
+
+	sf := &strictFeature{feature: feature, taskLines: make(map[string][]int)}
+	var doc yaml.Node
+	if yaml.Unmarshal([]byte(content), &doc) == nil && len(doc.Content) > 0 {
+		if tasks := yamlValue(doc.Content[0], "tasks"); tasks != nil {
+			for _, t := range tasks.Content {
+				if id := yamlValue(t, "id"); id != nil {
+					sf.taskLines[id.Value] = append(sf.taskLines[id.Value], t.Line)
+				}
+			}
+		}
+	}
+
+	var diags []Diagnostic
+	if !validStatus(feature.Status) {
+		diags = append(diags, Diagnostic{File: filePath, Severity: SeverityError, Message: fmt.Sprintf("unknown status %q", feature.Status)})
+	}
+	seen := make(map[string]int)
+	for _, t := range feature.Tasks {
+		if !validStatus(t.Status) {
+			diags = append(diags, Diagnostic{File: filePath, Line: sf.taskLine(t.ID, seen[t.ID]), Severity: SeverityError, Message: fmt.Sprintf("task %s has unknown status %q", t.ID, t.Status)})
+		}
+		seen[t.ID]++
+	}
+	return sf, diags
+}
+
+// yamlLine returns the line number in a YAML error message, or 0
+func yamlLine(err error) int {
+	if m := yamlErrorLine.FindStringSubmatch(err.Error()); m != nil {
+		n, _ := strconv.Atoi(m[1])
+		return n
+	}
+	return 0
+}
+
+func validStatus(s Status) bool {
+	switch s {
+	case StatusNotStarted, StatusInProgress, StatusCompleted, StatusBlocked, StatusAtRisk, StatusPaused:
+		return true
+	}
+	return false
+}
+
+// Validate strictly parses every feature file and checks that feature IDs
+// are unique across files and that dependencies name exactly one existing
+// task. Diagnostics are ordered by file and line.
+func (r *Reader) Validate() ([]Diagnostic, error) {
+	files, err := r.GetFeatureFiles()
+	if err != nil {
+		return nil, err
+	}
+
+	var diags []Diagnostic
+	var parsed []*strictFeature
+	var tasks []Task
+	featureFiles := make(map[string]string)
+	for _, file := range files {
+		content, err := os.ReadFile(file)
+		if err != nil {
+			return nil, err
+		}
+		sf, fileDiags := parseStrict(string(content), file)
+		diags = append(diags, fileDiags...)
+		if sf == nil {
+			continue
+		}
+		parsed = append(parsed, sf)
+
+		f := sf.feature
+		if other, ok := featureFiles[f.ID]; ok && f.ID != "" {
+			diags = append(diags, Diagnostic{File: file, Severity: SeverityError, Message: fmt.Sprintf("feature ID %s is also used by %s", f.ID, other)})
+		} else {
+			featureFiles[f.ID] = file
+		}
+		tasks = append(tasks, f.Tasks...)
+	}
+
+	// Archived tasks are valid dependency targets
+	if archived, err := r.GetArchivedFeatures(); err == nil {
+		for _, f := range archived {
+			tasks = append(tasks, f.Tasks...)
+		}
+	}
+
+	index := newTaskIndex(tasks)
+	for _, sf := range parsed {
+		f := sf.feature
+		report := func(t Task, format string, args ...interface{}) {
+			diags = append(diags, Diagnostic{File: f.FilePath, Line: sf.taskLine(t.ID, 0), Severity: SeverityError, Message: fmt.Sprintf(format, args...)})
+		}
+		for _, t := range f.Tasks {
+			if index.duplicated(t.ID) {
+				diags = append(diags, Diagnostic{File: f.FilePath, Line: sf.taskLine(t.ID, 0), Severity: SeverityWarning,
+					Message: fmt.Sprintf("task ID %s is also used by another feature, other features must refer to it as %s", t.ID, QualifiedID(f.ID, t.ID))})
+			}
+			for _, dep := range append(append([]string{}, t.Dependencies...), t.DependsOn...) {
+				if _, ok := index.resolve(dep, f.ID); ok || !taskIDRefRegex.MatchString(dep) {
+					continue
+				}
+				if _, id := SplitTaskID(dep); dep == id && index.duplicated(id) {
+					report(t, "task %s has ambiguous dependency: %v", t.ID, &AmbiguousIDError{ID: id, FeatureIDs: index[id]})
+				} else {
+					report(t, "task %s depends on unknown task %s", t.ID, dep)
+				}
+			}
+		}
+	}
+
+	sort.SliceStable(diags, func(i, j int) bool {
+		if diags[i].File != diags[j].File {
+			return diags[i].File < diags[j].File
+		}
+		return diags[i].Line < diags[j].Line
+	})
+	return diags, nil
+}
+
+// CountDiagnostics returns the number of errors and warnings
+func CountDiagnostics(diags []Diagnostic) (errors, warnings int) {
+	for _, d := range diags {
+		if d.Severity == SeverityError {
+			errors++
+		} else {
+			warnings++
+		}
+	}
+	return errors, warnings
+}
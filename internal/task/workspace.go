@@ -0,0 +1,52 @@
+package task
+
+import (
+	"path"
+	"path/filepath"
+	"strings"
+)
+
+// CleanWorkspace normalizes the **Workspace:** of a task to a slash
+// separated path relative to the repository root, "" for the root itself
+func CleanWorkspace(workspace string) string {
+	workspace = strings.Trim(strings.TrimSpace(workspace), "`")
+	workspace = path.Clean(strings.ReplaceAll(workspace, "\\", "/"))
+	if workspace == "." || workspace == "/" {
+		return ""
+	}
+	return strings.TrimSuffix(strings.TrimPrefix(workspace, "./"), "/")
+}
+
+// ValidWorkspace reports whether a cleaned workspace stays inside the
+// repository
+func ValidWorkspace(workspace string) bool {
+	return !path.IsAbs(workspace) && workspace != ".." && !strings.HasPrefix(workspace, "../")
+}
+
+// WorkspaceDir returns the directory below root the task works in, root
+// itself for tasks without a workspace
+func (t *Task) WorkspaceDir(root string) string {
+	if t.Workspace == "" {
+		return root
+	}
+	return filepath.Join(root, filepath.FromSlash(t.Workspace))
+}
+
+// InWorkspace reports whether a repository relative path is inside the
+// task's workspace, which every path is for tasks without one
+func (t *Task) InWorkspace(file string) bool {
+	if t.Workspace == "" {
+		return true
+	}
+	file = strings.TrimPrefix(path.Clean(strings.ReplaceAll(strings.Trim(file, "`"), "\\", "/")), "./")
+	return file == t.Workspace || strings.HasPrefix(file, t.Workspace+"/")
+}
+
+// ScopeFiles returns the paths the task's changes are limited to: its files
+// to touch, or its whole workspace when it lists none
+func (t *Task) ScopeFiles() []string {
+	if len(t.FilesToTouch) == 0 && t.Workspace != "" {
+		return []string{t.Workspace}
+	}
+	return t.FilesToTouch
+}
@@ -0,0 +1,186 @@
+package task
+
+import (
+	"bytes"
+	"fmt"
+	"path/filepath"
+	"strings"
+
+	"go.yaml.in/yaml/v3"
+)
+
+// IsYAMLFile returns true if a feature file uses the YAML format
+func IsYAMLFile(path string) bool {
+	ext := strings.ToLower(filepath.Ext(path))
+	return ext == ".yaml" || ext == ".yml"
+}
+
+// ParseFeatureYAML parses a feature file in the YAML format. The document
+// mirrors Feature, with the feature's tasks as a list under "tasks".
+func ParseFeatureYAML(content, filePath string) (*Feature, error) {
+	var feature Feature
+	if err := yaml.Unmarshal([]byte(content), &feature); err != nil {
+		return nil, fmt.Errorf("invalid YAML in %s: %w", filePath, err)
+	}
+	if feature.ID == "" {
+		return nil, fmt.Errorf("no feature id found in %s", filePath)
+	}
+	if !strings.HasPrefix(feature.ID, "F") {
+		feature.ID = "F" + feature.ID
+	}
+	feature.FilePath = filePath
+	if feature.Status == "" {
+		feature.Status = StatusNotStarted
+	}
+	if feature.Priority == "" {
+		feature.Priority = PriorityP2
+	}
+	for i := range feature.Tasks {
+		t := &feature.Tasks[i]
+		if t.ID == "" {
+			return nil, fmt.Errorf("task %d of %s has no id", i+1, filePath)
+		}
+		t.FeatureID = feature.ID
+		if t.Status == "" {
+			t.Status = StatusNotStarted
+		}
+		if t.Priority == "" {
+			t.Priority = PriorityP2
+		}
+		for j := range t.Subtasks {
+			if t.Subtasks[j].Status == "" {
+				t.Subtasks[j].Status = StatusNotStarted
+			}
+		}
+	}
+	return &feature, nil
+}
+
+// FormatFeatureYAML renders a feature in the YAML task file format
+func FormatFeatureYAML(f *Feature) ([]byte, error) {
+	var buf bytes.Buffer
+	enc := yaml.NewEncoder(&buf)
+	enc.SetIndent(2)
+	if err := enc.Encode(f); err != nil {
+		return nil, err
+	}
+	if err := enc.Close(); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// editYAML applies edit to the root mapping of a YAML feature file and
+// renders it again, keeping comments and key order. It returns false when
+// edit did not find what it was looking for.
+func editYAML(content string, edit func(root *yaml.Node) (bool, error)) (string, bool, error) {
+	var doc yaml.Node
+	if err := yaml.Unmarshal([]byte(content), &doc); err != nil {
+		return "", false, err
+	}
+	if len(doc.Content) == 0 || doc.Content[0].Kind != yaml.MappingNode {
+		return "", false, fmt.Errorf("feature file is not a YAML mapping")
+	}
+	ok, err := edit(doc.Content[0])
+	if err != nil || !ok {
+		return "", ok, err
+	}
+
+	var buf bytes.Buffer
+	enc := yaml.NewEncoder(&buf)
+	enc.SetIndent(2)
+	if err := enc.Encode(&doc); err != nil {
+		return "", false, err
+	}
+	if err := enc.Close(); err != nil {
+		return "", false, err
+	}
+	return buf.String(), true, nil
+}
+
+// editYAMLTask applies edit to the mapping of the task with the given ID
+func editYAMLTask(content, taskID string, edit func(task *yaml.Node) error) (string, bool, error) {
+	return editYAML(content, func(root *yaml.Node) (bool, error) {
+		tasks, i := findYAMLTask(root, taskID)
+		if tasks == nil {
+			return false, nil
+		}
+		return true, edit(tasks.Content[i])
+	})
+}
+
+// findYAMLTask returns the tasks sequence and the index of the task with the given ID
+func findYAMLTask(root *yaml.Node, taskID string) (*yaml.Node, int) {
+	tasks := yamlValue(root, "tasks")
+	if tasks == nil || tasks.Kind != yaml.SequenceNode {
+		return nil, -1
+	}
+	for i, t := range tasks.Content {
+		if id := yamlValue(t, "id"); id != nil && id.Value == taskID {
+			return tasks, i
+		}
+	}
+	return nil, -1
+}
+
+// yamlValue returns the value of key in a mapping node
+func yamlValue(m *yaml.Node, key string) *yaml.Node {
+	if m.Kind != yaml.MappingNode {
+		return nil
+	}
+	for i := 0; i+1 < len(m.Content); i += 2 {
+		if m.Content[i].Value == key {
+			return m.Content[i+1]
+		}
+	}
+	return nil
+}
+
+// setYAMLValue replaces the value of key in a mapping node, adding the key if needed
+func setYAMLValue(m *yaml.Node, key string, value *yaml.Node) {
+	for i := 0; i+1 < len(m.Content); i += 2 {
+		if m.Content[i].Value == key {
+			value.HeadComment, value.LineComment = m.Content[i+1].HeadComment, m.Content[i+1].LineComment
+			m.Content[i+1] = value
+			return
+		}
+	}
+	m.Content = append(m.Content, &yaml.Node{Kind: yaml.ScalarNode, Tag: "!!str", Value: key}, value)
+}
+
+// deleteYAMLValue removes key from a mapping node
+func deleteYAMLValue(m *yaml.Node, key string) {
+	for i := 0; i+1 < len(m.Content); i += 2 {
+		if m.Content[i].Value == key {
+			m.Content = append(m.Content[:i], m.Content[i+2:]...)
+			return
+		}
+	}
+}
+
+// yamlScalar returns a string scalar node
+func yamlScalar(value string) *yaml.Node {
+	return &yaml.Node{Kind: yaml.ScalarNode, Tag: "!!str", Value: value}
+}
+
+// yamlList returns a sequence of string scalars
+func yamlList(values []string) *yaml.Node {
+	n := &yaml.Node{Kind: yaml.SequenceNode, Tag: "!!seq", Style: yaml.FlowStyle}
+	for _, v := range values {
+		n.Content = append(n.Content, yamlScalar(v))
+	}
+	return n
+}
+
+// yamlTaskNodes encodes tasks as mapping nodes
+func yamlTaskNodes(tasks []*Task) ([]*yaml.Node, error) {
+	var nodes []*yaml.Node
+	for _, t := range tasks {
+		var n yaml.Node
+		if err := n.Encode(t); err != nil {
+			return nil, err
+		}
+		nodes = append(nodes, &n)
+	}
+	return nodes, nil
+}
@@ -0,0 +1,125 @@
+package tasklog
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"hermes/internal/analyzer"
+	"hermes/internal/task"
+)
+
+// Dir returns the directory holding the per-task logs
+func Dir(basePath string) string {
+	return filepath.Join(basePath, ".hermes", "logs", "tasks")
+}
+
+// Log is the log of a single task attempt with the prompt, the AI output,
+// the analysis and the git commands run for it. A nil Log discards writes.
+type Log struct {
+	file *os.File
+	path string
+	mu   sync.Mutex
+}
+
+// Create starts the log of the next attempt of a task, e.g. T012-attempt2.log
+func Create(basePath, taskID string) (*Log, error) {
+	dir := Dir(basePath)
+	name := task.FileSafeID(taskID)
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return nil, err
+	}
+	attempt := 1
+	if logs, err := List(basePath, taskID); err == nil && len(logs) > 0 {
+		attempt = attemptOf(logs[len(logs)-1], taskID) + 1
+	}
+
+	path := filepath.Join(dir, fmt.Sprintf("%s-attempt%d.log", name, attempt))
+	file, err := os.OpenFile(path, os.O_CREATE|os.O_WRONLY|os.O_EXCL, 0644)
+	if err != nil {
+		return nil, err
+	}
+	l := &Log{file: file, path: path}
+	l.Printf("Task %s, attempt %d, started %s\n", taskID, attempt, time.Now().Format("2006-01-02 15:04:05"))
+	return l, nil
+}
+
+// List returns the logs of a task, oldest attempt first
+func List(basePath, taskID string) ([]string, error) {
+	taskID = task.FileSafeID(taskID)
+	matches, err := filepath.Glob(filepath.Join(Dir(basePath), taskID+"-attempt*.log"))
+	if err != nil {
+		return nil, err
+	}
+	var logs []string
+	for _, m := range matches {
+		if attemptOf(m, taskID) > 0 {
+			logs = append(logs, m)
+		}
+	}
+	sort.Slice(logs, func(i, j int) bool {
+		return attemptOf(logs[i], taskID) < attemptOf(logs[j], taskID)
+	})
+	return logs, nil
+}
+
+// attemptOf returns the attempt number of a log path, or 0 if it is not a log of the task
+func attemptOf(path, taskID string) int {
+	name := strings.TrimSuffix(filepath.Base(path), ".log")
+	n, err := strconv.Atoi(strings.TrimPrefix(name, taskID+"-attempt"))
+	if err != nil || !strings.HasPrefix(name, taskID+"-attempt") {
+		return 0
+	}
+	return n
+}
+
+// Write appends raw output such as streamed AI text
+func (l *Log) Write(p []byte) (int, error) {
+	if l == nil {
+		return len(p), nil
+	}
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	return l.file.Write(p)
+}
+
+// Printf appends a formatted message
+func (l *Log) Printf(format string, args ...interface{}) {
+	fmt.Fprintf(l, format, args...)
+}
+
+// Section starts a titled section
+func (l *Log) Section(title string) {
+	l.Printf("\n=== %s [%s] ===\n", title, time.Now().Format("15:04:05"))
+}
+
+// Analysis writes the analysis of the AI output
+func (l *Log) Analysis(a *analyzer.AnalysisResult) {
+	l.Section("Analysis")
+	l.Printf("status=%s progress=%v complete=%v blocked=%v atRisk=%v paused=%v confidence=%.2f criteria=%d/%d\n",
+		a.Status, a.HasProgress, a.IsComplete, a.IsBlocked, a.IsAtRisk, a.IsPaused, a.Confidence, a.CriteriaMet, a.CriteriaTotal)
+	if a.Recommendation != "" {
+		l.Printf("Recommendation: %s\n", a.Recommendation)
+	}
+}
+
+// Path returns the path of the log file
+func (l *Log) Path() string {
+	if l == nil {
+		return ""
+	}
+	return l.path
+}
+
+// Close closes the log file
+func (l *Log) Close() error {
+	if l == nil {
+		return nil
+	}
+	return l.file.Close()
+}
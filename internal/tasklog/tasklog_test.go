@@ -0,0 +1,64 @@
+package tasklog
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"hermes/internal/analyzer"
+)
+
+func TestCreateNumbersAttempts(t *testing.T) {
+	dir := t.TempDir()
+	// A log of a task sharing the ID prefix must not count
+	os.MkdirAll(Dir(dir), 0755)
+	os.WriteFile(filepath.Join(Dir(dir), "T0012-attempt5.log"), nil, 0644)
+
+	for i := 0; i < 10; i++ {
+		l, err := Create(dir, "T001")
+		if err != nil {
+			t.Fatal(err)
+		}
+		l.Close()
+	}
+
+	logs, err := List(dir, "T001")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(logs) != 10 {
+		t.Fatalf("expected 10 logs, got %v", logs)
+	}
+	if filepath.Base(logs[1]) != "T001-attempt2.log" || filepath.Base(logs[9]) != "T001-attempt10.log" {
+		t.Errorf("expected logs ordered by attempt, got %v", logs)
+	}
+}
+
+func TestLogSections(t *testing.T) {
+	dir := t.TempDir()
+	l, err := Create(dir, "T003")
+	if err != nil {
+		t.Fatal(err)
+	}
+	l.Section("AI Output")
+	l.Write([]byte("streamed text\n"))
+	l.Analysis(&analyzer.AnalysisResult{Status: "COMPLETE", IsComplete: true, Recommendation: "Move on"})
+	l.Close()
+
+	data, _ := os.ReadFile(l.Path())
+	content := string(data)
+	for _, want := range []string{"Task T003, attempt 1", "=== AI Output", "streamed text", "=== Analysis", "status=COMPLETE", "Recommendation: Move on"} {
+		if !strings.Contains(content, want) {
+			t.Errorf("expected log to contain %q, got:\n%s", want, content)
+		}
+	}
+
+	// A nil log discards everything
+	var none *Log
+	none.Section("Prompt")
+	none.Printf("ignored")
+	if err := none.Close(); err != nil || none.Path() != "" {
+		t.Error("expected a nil log to be a no-op")
+	}
+}
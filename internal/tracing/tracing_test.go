@@ -0,0 +1,52 @@
+package tracing
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+	"hermes/internal/config"
+)
+
+func TestSetupDisabled(t *testing.T) {
+	shutdown, err := Setup(context.Background(), config.TracingConfig{}, "dev")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := shutdown(context.Background()); err != nil {
+		t.Error(err)
+	}
+}
+
+func TestStartAndEnd(t *testing.T) {
+	exporter := tracetest.NewInMemoryExporter()
+	provider := sdktrace.NewTracerProvider(sdktrace.WithSyncer(exporter))
+	previous := otel.GetTracerProvider()
+	otel.SetTracerProvider(provider)
+	defer otel.SetTracerProvider(previous)
+
+	ctx, run := Start(context.Background(), "run", attribute.String("run.mode", "sequential"))
+	_, task := Start(ctx, "task")
+	End(task, errors.New("boom"))
+	End(run, nil)
+
+	spans := exporter.GetSpans()
+	if len(spans) != 2 {
+		t.Fatalf("expected 2 spans, got %d", len(spans))
+	}
+	taskSpan, runSpan := spans[0], spans[1]
+	if taskSpan.Parent.SpanID() != runSpan.SpanContext.SpanID() {
+		t.Error("expected the task span to be a child of the run span")
+	}
+	if taskSpan.Status.Code != codes.Error || taskSpan.Status.Description != "boom" {
+		t.Errorf("expected an error status, got %+v", taskSpan.Status)
+	}
+	if runSpan.Status.Code == codes.Error {
+		t.Error("expected the run span to succeed")
+	}
+}
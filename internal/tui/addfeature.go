@@ -208,7 +208,7 @@ func (m *AddFeatureModel) addFeature() tea.Cmd {
 
 		cfg, err := config.Load(m.basePath)
 		if err != nil {
-			cfg = config.DefaultConfig()
+			return addFeatureResultMsg{err: err}
 		}
 
 		featureAnalyzer := analyzer.NewFeatureAnalyzer(m.basePath)
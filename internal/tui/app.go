@@ -73,7 +73,7 @@ type App struct {
 func NewApp(basePath string, version string) (*App, error) {
 	cfg, err := config.Load(basePath)
 	if err != nil {
-		cfg = config.DefaultConfig()
+		return nil, err
 	}
 
 	logger, _ := ui.NewLogger(basePath, false)
@@ -163,6 +163,8 @@ func (a App) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 			textInputFocused = a.addFeature.focusIndex == 0
 		case ScreenInit:
 			textInputFocused = a.initProj.focusIndex == 0
+		case ScreenSettings:
+			textInputFocused = a.settings.editing
 		}
 
 		// If text input is focused, let the submodel handle all keys except quit
@@ -339,6 +341,10 @@ func (a App) View() string {
 
 	// Wrap content in a container with proper sizing
 	contentHeight := a.height - 4 // Account for header and footer
+	budget := a.run.BudgetView()
+	if budget != "" {
+		contentHeight -= 2 // Budget bar and its border
+	}
 	if contentHeight < 10 {
 		contentHeight = 10
 	}
@@ -347,12 +353,12 @@ func (a App) View() string {
 		Height(contentHeight).
 		MaxHeight(contentHeight)
 
-	return lipgloss.JoinVertical(
-		lipgloss.Left,
-		a.headerView(),
-		contentStyle.Render(content),
-		a.footerView(),
-	)
+	sections := []string{a.headerView(), contentStyle.Render(content)}
+	if budget != "" {
+		sections = append(sections, budgetBarStyle.Width(a.width).Render(budget))
+	}
+	sections = append(sections, a.footerView())
+	return lipgloss.JoinVertical(lipgloss.Left, sections...)
 }
 
 func (a App) headerView() string {
@@ -414,6 +420,7 @@ Dashboard:
 
 Tasks:
   a/c/p/n/b   Filter: All/Completed/InProgress/NotStarted/Blocked
+  Shift+A     Archive completed features
   Enter       View task details
 
 Logs:
@@ -436,7 +443,8 @@ Add Feature:
 
 Settings:
   j/k         Navigate options
-  Space/Enter Toggle value or save
+  Space/Enter Toggle value, save or reset to defaults
+  e           Type a number or path (Enter applies, Esc cancels)
 
 Circuit Breaker:
   r           Refresh state
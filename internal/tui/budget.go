@@ -0,0 +1,120 @@
+package tui
+
+import (
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/charmbracelet/lipgloss"
+	"hermes/internal/circuit"
+	"hermes/internal/config"
+)
+
+// runBudget tracks live counters for the run loop's safety limits
+type runBudget struct {
+	mu        sync.Mutex
+	start     time.Time
+	calls     []time.Time
+	totalCost float64
+}
+
+// newRunBudget starts tracking a new run
+func newRunBudget() *runBudget {
+	return &runBudget{start: time.Now()}
+}
+
+// recordCall records an AI call and its cost
+func (b *runBudget) recordCall(cost float64) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.calls = append(b.calls, time.Now())
+	b.totalCost += cost
+}
+
+// callsLastHour returns the number of AI calls made in the past hour
+func (b *runBudget) callsLastHour(now time.Time) int {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	cutoff := now.Add(-time.Hour)
+	for len(b.calls) > 0 && b.calls[0].Before(cutoff) {
+		b.calls = b.calls[1:]
+	}
+	return len(b.calls)
+}
+
+// cost returns the total spend of the run
+func (b *runBudget) cost() float64 {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.totalCost
+}
+
+// elapsed returns the wall-clock time since the run started
+func (b *runBudget) elapsed() time.Duration {
+	return time.Since(b.start)
+}
+
+// view renders the budget widget as a single footer line
+func (b *runBudget) view(cfg *config.Config, state *circuit.BreakerState) string {
+	calls := b.callsLastHour(time.Now())
+	parts := []string{
+		budgetItem("Calls/h", fmt.Sprintf("%d", calls), fmt.Sprintf("%d", cfg.Loop.MaxCallsPerHour),
+			ratio(float64(calls), float64(cfg.Loop.MaxCallsPerHour))),
+		budgetItem("Spend", fmt.Sprintf("$%.2f", b.cost()), formatLimit(cfg.Loop.MaxRunCost > 0, fmt.Sprintf("$%.2f", cfg.Loop.MaxRunCost)),
+			ratio(b.cost(), cfg.Loop.MaxRunCost)),
+		budgetItem("Elapsed", formatMinutes(b.elapsed()), formatLimit(cfg.Loop.MaxRunMinutes > 0, fmt.Sprintf("%dm", cfg.Loop.MaxRunMinutes)),
+			ratio(b.elapsed().Minutes(), float64(cfg.Loop.MaxRunMinutes))),
+	}
+
+	noProgress := 0
+	if state != nil {
+		noProgress = state.ConsecutiveNoProgress
+	}
+	parts = append(parts, budgetItem("No progress", fmt.Sprintf("%d", noProgress), fmt.Sprintf("%d", circuit.OpenThreshold),
+		ratio(float64(noProgress), float64(circuit.OpenThreshold))))
+
+	return strings.Join(parts, MutedStyle.Render(" | "))
+}
+
+// budgetItem renders "Label value/limit" colored by how close value is to its limit
+func budgetItem(label, value, limit string, r float64) string {
+	style := SuccessStyle
+	switch {
+	case r >= 0.9:
+		style = ErrorStyle
+	case r >= 0.7:
+		style = WarningStyle
+	case r < 0:
+		style = ValueStyle
+	}
+	return MutedStyle.Render(label+" ") + style.Render(value) + MutedStyle.Render("/"+limit)
+}
+
+// ratio returns value/limit, or -1 when there is no limit
+func ratio(value, limit float64) float64 {
+	if limit <= 0 {
+		return -1
+	}
+	return value / limit
+}
+
+func formatLimit(limited bool, limit string) string {
+	if !limited {
+		return "∞"
+	}
+	return limit
+}
+
+func formatMinutes(d time.Duration) string {
+	if d < time.Hour {
+		return fmt.Sprintf("%dm", int(d.Minutes()))
+	}
+	return fmt.Sprintf("%dh%02dm", int(d.Hours()), int(d.Minutes())%60)
+}
+
+// budgetBarStyle is the footer bar holding the budget widget
+var budgetBarStyle = lipgloss.NewStyle().
+	BorderStyle(lipgloss.NormalBorder()).
+	BorderTop(true).
+	BorderForeground(lipgloss.Color("238"))
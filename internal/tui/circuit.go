@@ -6,28 +6,37 @@ import (
 
 	tea "github.com/charmbracelet/bubbletea"
 	"hermes/internal/circuit"
+	"hermes/internal/recovery"
 )
 
+// circuitHistoryLimit is the number of transitions the screen shows
+const circuitHistoryLimit = 10
+
 // CircuitBreakerModel is the model for the circuit breaker screen
 type CircuitBreakerModel struct {
-	width    int
-	height   int
-	basePath string
-	breaker  *circuit.Breaker
-	state    *circuit.BreakerState
-	err      error
-	message  string
+	width     int
+	height    int
+	basePath  string
+	breaker   *circuit.Breaker
+	state     *circuit.BreakerState
+	diagnosis *recovery.Diagnosis
+	history   []circuit.HistoryEntry // Recent transitions, newest first
+	err       error
+	message   string
 }
 
 // NewCircuitBreakerModel creates a new circuit breaker model
 func NewCircuitBreakerModel(basePath string) *CircuitBreakerModel {
 	breaker := circuit.New(basePath)
 	state, _ := breaker.GetState()
+	history, _ := breaker.RecentHistory(circuitHistoryLimit)
 
 	return &CircuitBreakerModel{
-		basePath: basePath,
-		breaker:  breaker,
-		state:    state,
+		basePath:  basePath,
+		breaker:   breaker,
+		state:     state,
+		diagnosis: recovery.ForBreaker(basePath, state),
+		history:   history,
 	}
 }
 
@@ -49,6 +58,8 @@ func (m *CircuitBreakerModel) Refresh() {
 		m.err = err
 	} else {
 		m.state = state
+		m.diagnosis = recovery.ForBreaker(m.basePath, state)
+		m.history, _ = m.breaker.RecentHistory(circuitHistoryLimit)
 		m.err = nil
 	}
 }
@@ -67,6 +78,7 @@ func (m *CircuitBreakerModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 				if err != nil {
 					m.err = err
 				} else {
+					recovery.Clear(m.basePath)
 					m.message = "Circuit breaker reset successfully!"
 					m.Refresh()
 				}
@@ -140,6 +152,45 @@ func (m *CircuitBreakerModel) View() string {
 	b.WriteString(ValueStyle.Render(m.state.LastUpdated.Format("2006-01-02 15:04:05")))
 	b.WriteString("\n\n")
 
+	if d := m.diagnosis; d != nil {
+		b.WriteString(SectionStyle.Render("Diagnosis"))
+		b.WriteString("\n\n")
+		if d.TaskID != "" {
+			b.WriteString(LabelStyle.Render("Task:"))
+			b.WriteString(ValueStyle.Render(fmt.Sprintf("%s - %s", d.TaskID, d.TaskName)))
+			b.WriteString("\n")
+		}
+		for _, f := range d.Findings {
+			b.WriteString(MutedStyle.Render("  - " + f))
+			b.WriteString("\n")
+		}
+		b.WriteString("\n")
+		b.WriteString(SectionStyle.Render("Suggested Remediations"))
+		b.WriteString("\n\n")
+		for i, s := range d.Suggestions {
+			b.WriteString(fmt.Sprintf("  %d. %s: %s\n", i+1, s.Title, s.Detail))
+			if s.Command != "" {
+				b.WriteString(ValueStyle.Render("     " + s.Command))
+				b.WriteString("\n")
+			}
+		}
+		b.WriteString("\n")
+	}
+
+	b.WriteString(SectionStyle.Render("Recent Transitions"))
+	b.WriteString("\n\n")
+	if len(m.history) == 0 {
+		b.WriteString(MutedStyle.Render("  No transitions recorded"))
+		b.WriteString("\n")
+	}
+	for _, e := range m.history {
+		b.WriteString(MutedStyle.Render(fmt.Sprintf("  %s  loop %-4d ", e.Timestamp.Format("2006-01-02 15:04:05"), e.LoopNumber)))
+		b.WriteString(fmt.Sprintf("%s -> %s ", stateLabel(e.FromState), stateLabel(e.ToState)))
+		b.WriteString(ValueStyle.Render(e.Reason))
+		b.WriteString("\n")
+	}
+	b.WriteString("\n")
+
 	if m.state.State != circuit.StateClosed {
 		b.WriteString(ButtonStyle.Render("Reset Circuit Breaker"))
 	} else {
@@ -162,3 +213,15 @@ func (m *CircuitBreakerModel) View() string {
 
 	return b.String()
 }
+
+// stateLabel renders a breaker state in its color
+func stateLabel(state circuit.State) string {
+	switch state {
+	case circuit.StateHalfOpen:
+		return WarningStyle.Render(string(state))
+	case circuit.StateOpen:
+		return ErrorStyle.Render(string(state))
+	default:
+		return SuccessStyle.Render(string(state))
+	}
+}
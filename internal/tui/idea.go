@@ -223,7 +223,7 @@ func (m *IdeaModel) generatePRD() tea.Cmd {
 
 		cfg, err := config.Load(m.basePath)
 		if err != nil {
-			cfg = config.DefaultConfig()
+			return ideaResultMsg{err: err}
 		}
 
 		var provider ai.Provider
@@ -249,7 +249,7 @@ func (m *IdeaModel) generatePRD() tea.Cmd {
 			DryRun:      false,
 			Interactive: m.interactive,
 			Language:    m.language,
-			Timeout:     cfg.AI.PrdTimeout,
+			Timeout:     cfg.AI.PlanningTimeout,
 		}
 
 		result, err := generator.Generate(ctx, opts)
@@ -224,7 +224,7 @@ func (m *InitModel) initProject(projectPath string) tea.Cmd {
 		}
 
 		configPath := filepath.Join(projectPath, ".hermes", "config.json")
-		if _, err := os.Stat(configPath); os.IsNotExist(err) {
+		if config.FindFile(filepath.Dir(configPath)) == "" {
 			cfg := config.DefaultConfig()
 			if err := config.Save(configPath, cfg); err != nil {
 				return initResultMsg{err: fmt.Errorf("failed to create config: %w", err)}
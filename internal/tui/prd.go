@@ -12,10 +12,17 @@ import (
 	"github.com/charmbracelet/bubbles/textinput"
 	tea "github.com/charmbracelet/bubbletea"
 	"hermes/internal/ai"
+	"hermes/internal/analyzer"
 	"hermes/internal/config"
+	"hermes/internal/prd"
+	"hermes/internal/prompt"
+	"hermes/internal/task"
 	"hermes/internal/ui"
 )
 
+// maxPrdProblems is the number of task file problems listed after a parse
+const maxPrdProblems = 8
+
 // PrdModel is the model for the PRD parser screen
 type PrdModel struct {
 	width        int
@@ -23,9 +30,11 @@ type PrdModel struct {
 	basePath     string
 	textInput    textinput.Model
 	dryRun       bool
+	update       bool
 	parsing      bool
 	result       string
 	filesCreated []string
+	problems     []task.Diagnostic
 	err          error
 	focusIndex   int
 	logger       *ui.Logger
@@ -33,8 +42,9 @@ type PrdModel struct {
 
 // prdResultMsg is sent when PRD parsing completes
 type prdResultMsg struct {
-	files []string
-	err   error
+	files    []string
+	problems []task.Diagnostic
+	err      error
 }
 
 // NewPrdModel creates a new PRD model
@@ -75,7 +85,7 @@ func (m *PrdModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 
 		switch msg.String() {
 		case "tab", "shift+tab":
-			m.focusIndex = (m.focusIndex + 1) % 3
+			m.focusIndex = (m.focusIndex + 1) % 4
 			if m.focusIndex == 0 {
 				m.textInput.Focus()
 			} else {
@@ -86,6 +96,8 @@ func (m *PrdModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 			case 1:
 				m.dryRun = !m.dryRun
 			case 2:
+				m.update = !m.update
+			case 3:
 				prdPath := m.textInput.Value()
 				if prdPath == "" {
 					prdPath = ".hermes/docs/PRD.md"
@@ -94,6 +106,7 @@ func (m *PrdModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 					m.parsing = true
 					m.result = ""
 					m.filesCreated = nil
+					m.problems = nil
 					m.err = nil
 					return m, m.parsePRD(prdPath)
 				} else {
@@ -108,8 +121,11 @@ func (m *PrdModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 			m.err = msg.err
 		} else {
 			m.filesCreated = msg.files
+			m.problems = msg.problems
 			if m.dryRun {
 				m.result = "Dry run completed - no files written"
+			} else if m.update {
+				m.result = fmt.Sprintf("Updated %d task files", len(msg.files))
 			} else {
 				m.result = fmt.Sprintf("Created %d task files", len(msg.files))
 			}
@@ -160,9 +176,21 @@ func (m *PrdModel) View() string {
 	} else {
 		b.WriteString("[ ] Dry Run (preview without writing)")
 	}
+	b.WriteString("\n")
+	b.WriteString("         ")
+	if m.focusIndex == 2 {
+		b.WriteString(SelectedStyle.Render("> "))
+	} else {
+		b.WriteString("  ")
+	}
+	if m.update {
+		b.WriteString("[x] Update (add new features and tasks to existing files)")
+	} else {
+		b.WriteString("[ ] Update (add new features and tasks to existing files)")
+	}
 	b.WriteString("\n\n")
 
-	if m.focusIndex == 2 {
+	if m.focusIndex == 3 {
 		b.WriteString(SelectedStyle.Render("> "))
 	} else {
 		b.WriteString("  ")
@@ -180,6 +208,26 @@ func (m *PrdModel) View() string {
 				b.WriteString("\n")
 			}
 		}
+
+		if len(m.problems) > 0 {
+			errors, warnings := task.CountDiagnostics(m.problems)
+			b.WriteString("\n")
+			b.WriteString(WarningStyle.Render(fmt.Sprintf("%d errors, %d warnings in task files:", errors, warnings)))
+			b.WriteString("\n")
+			for i, d := range m.problems {
+				if i == maxPrdProblems {
+					b.WriteString(MutedStyle.Render(fmt.Sprintf("  ... %d more, run 'hermes validate'", len(m.problems)-i)))
+					b.WriteString("\n")
+					break
+				}
+				style := WarningStyle
+				if d.Severity == task.SeverityError {
+					style = ErrorStyle
+				}
+				b.WriteString(style.Render("  " + d.String()))
+				b.WriteString("\n")
+			}
+		}
 	}
 
 	if m.err != nil {
@@ -197,9 +245,11 @@ func (m *PrdModel) View() string {
 func (m *PrdModel) Reset() {
 	m.textInput.SetValue("")
 	m.dryRun = false
+	m.update = false
 	m.parsing = false
 	m.result = ""
 	m.filesCreated = nil
+	m.problems = nil
 	m.err = nil
 	m.focusIndex = 0
 	m.textInput.Focus()
@@ -212,7 +262,7 @@ func (m *PrdModel) parsePRD(prdPath string) tea.Cmd {
 
 		cfg, err := config.Load(m.basePath)
 		if err != nil {
-			cfg = config.DefaultConfig()
+			return prdResultMsg{err: err}
 		}
 
 		prdContent, err := os.ReadFile(prdPath)
@@ -220,6 +270,11 @@ func (m *PrdModel) parsePRD(prdPath string) tea.Cmd {
 			return prdResultMsg{err: fmt.Errorf("failed to read PRD: %w", err)}
 		}
 
+		reader := task.NewReader(m.basePath)
+		if !m.update && reader.HasTasks() {
+			return prdResultMsg{err: fmt.Errorf("task files already exist, enable Update to merge new features and tasks")}
+		}
+
 		var provider ai.Provider
 		if cfg.AI.Planning != "" && cfg.AI.Planning != "auto" {
 			provider = ai.GetProvider(cfg.AI.Planning)
@@ -235,11 +290,27 @@ func (m *PrdModel) parsePRD(prdPath string) tea.Cmd {
 			m.logger.Info("Parsing PRD file: %s", prdPath)
 		}
 
-		prompt := buildPrdPromptForTUI(string(prdContent))
+		prompt, err := buildPrdPromptForTUI(m.basePath, string(prdContent))
+		if err != nil {
+			return prdResultMsg{err: err}
+		}
+		nextFeatureID, nextTaskID := 1, 1
+		if m.update {
+			existing, err := reader.GetAllFeatures()
+			if err != nil {
+				return prdResultMsg{err: err}
+			}
+			archived, _ := reader.GetArchivedFeatures()
+			existing = append(existing, archived...)
+			if f, t, err := analyzer.NewFeatureAnalyzer(m.basePath).GetNextIDs(); err == nil {
+				nextFeatureID, nextTaskID = f, t
+			}
+			prompt = buildPrdUpdatePromptForTUI(prompt, existing, nextFeatureID, nextTaskID)
+		}
 
 		result, err := ai.ExecuteWithRetry(ctx, provider, &ai.ExecuteOptions{
 			Prompt:       prompt,
-			Timeout:      cfg.AI.PrdTimeout,
+			Timeout:      cfg.AI.PlanningTimeout,
 			StreamOutput: false,
 		}, &ai.RetryConfig{
 			MaxRetries: cfg.AI.MaxRetries,
@@ -250,21 +321,45 @@ func (m *PrdModel) parsePRD(prdPath string) tea.Cmd {
 			return prdResultMsg{err: fmt.Errorf("AI execution failed: %w", err)}
 		}
 
-		if m.dryRun {
-			return prdResultMsg{files: []string{"(dry run)"}}
+		var files []string
+		if m.update {
+			plan, err := task.PlanMerge(m.basePath, parseFileContentsForTUI(result.Output), nextFeatureID, nextTaskID)
+			if err != nil {
+				return prdResultMsg{err: err}
+			}
+			if m.dryRun {
+				for _, f := range plan.NewFeatures {
+					files = append(files, fmt.Sprintf("new feature %s: %s", f.ID, f.Name))
+				}
+				for _, p := range plan.NewTasks {
+					files = append(files, fmt.Sprintf("new task %s: %s -> %s", p.Task.ID, p.Task.Name, p.Feature.ID))
+				}
+				return prdResultMsg{files: append(files, plan.Warnings...)}
+			}
+			if files, err = task.ApplyMerge(m.basePath, plan); err != nil {
+				return prdResultMsg{err: err}
+			}
+		} else {
+			if m.dryRun {
+				return prdResultMsg{files: []string{"(dry run)"}}
+			}
+			if files, err = writeTaskFilesForTUI(m.basePath, result.Output); err != nil {
+				return prdResultMsg{err: err}
+			}
 		}
 
-		files, err := writeTaskFilesForTUI(m.basePath, result.Output)
-		if err != nil {
-			return prdResultMsg{err: err}
-		}
+		// Remember PRD sections so 'hermes prd --update' can scope later parses
+		_, sections := prd.Split(string(prdContent), 0)
+		prd.NewSnapshot(prdPath, sections).Save(m.basePath)
 
-		return prdResultMsg{files: files}
+		problems, _ := task.NewReader(m.basePath).Validate()
+		return prdResultMsg{files: files, problems: problems}
 	}
 }
 
-func buildPrdPromptForTUI(prdContent string) string {
-	return fmt.Sprintf(`Parse this PRD into task files. Create files in .hermes/tasks/ directory.
+// prdPromptTemplate is the prompt parsing a PRD when the project has no
+// .hermes/prompts/prd.tmpl
+const prdPromptTemplate = `Parse this PRD into task files. Create files in .hermes/tasks/ directory.
 
 Use this format for each feature file:
 
@@ -288,6 +383,7 @@ Use this format for each feature file:
 **Status:** NOT_STARTED
 **Priority:** P1
 **Estimated Effort:** X days
+**PRD Section:** [Exact PRD heading this task was derived from]
 
 #### Description
 [Clear, detailed description of what this task accomplishes]
@@ -296,8 +392,8 @@ Use this format for each feature file:
 [Implementation notes, architecture decisions, code patterns to follow]
 
 #### Files to Touch
-- `+"`path/to/file.go`"+` (new)
-- `+"`path/to/existing.go`"+` (update)
+- ` + "`path/to/file.go`" + ` (new)
+- ` + "`path/to/existing.go`" + ` (update)
 
 #### Dependencies
 - TYYY (if depends on another task, use actual task ID like T001, T002)
@@ -320,6 +416,7 @@ IMPORTANT RULES:
 5. Do NOT use vague dependencies like "All backend features" or "Previous tasks"
 6. Success criteria must be specific and measurable
 7. Priority levels: P1=Critical, P2=High, P3=Medium, P4=Low
+8. PRD Section must be the exact heading text of the PRD section the task was derived from
 
 Output format:
 ---FILE: .hermes/tasks/001-feature-name.md---
@@ -328,7 +425,57 @@ Output format:
 
 PRD Content:
 
-%s`, prdContent)
+{{.PRD}}`
+
+// buildPrdPromptForTUI renders the prompt parsing a PRD from the project's prd
+// template or prdPromptTemplate
+func buildPrdPromptForTUI(basePath, prdContent string) (string, error) {
+	data, err := prompt.NewTemplateData(basePath, nil)
+	if err != nil {
+		return "", err
+	}
+	data.PRD = prdContent
+	return prompt.Render(basePath, prompt.PrdTemplate, prdPromptTemplate, data)
+}
+
+// buildPrdUpdatePromptForTUI adds the backlog and merge rules to the prompt
+// parsing a PRD, for PRDs parsed again after changes
+func buildPrdUpdatePromptForTUI(prompt string, existing []task.Feature, nextFeatureID, nextTaskID int) string {
+	var backlog strings.Builder
+	for _, f := range existing {
+		fmt.Fprintf(&backlog, "%s: %s\n", f.ID, f.Name)
+		for _, t := range f.Tasks {
+			fmt.Fprintf(&backlog, "  - %s: %s\n", t.ID, t.Name)
+		}
+	}
+
+	prompt = strings.Replace(prompt, "Create files in .hermes/tasks/ directory.", "Do NOT create or modify any files.", 1)
+	return strings.Replace(prompt, "Output format:", fmt.Sprintf(`Task files already exist for an earlier version of this PRD and will be
+merged with your output:
+
+%s
+MERGE RULES:
+1. Output every feature the PRD describes, including existing ones
+2. Reuse the EXACT names of existing features and tasks that still apply, so they can be matched
+3. Add new tasks for requirements the existing tasks do not cover
+4. Number new features from F%03d and new tasks from T%03d
+
+Output format:`, backlog.String(), nextFeatureID, nextTaskID), 1)
+}
+
+// parseFileContentsForTUI returns the contents of the ---FILE: markers in AI output
+func parseFileContentsForTUI(output string) []string {
+	fileRegex := regexp.MustCompile(`---FILE:\s*(.+?)---\s*([\s\S]*?)---END_FILE---`)
+	matches := fileRegex.FindAllStringSubmatch(output, -1)
+	if len(matches) == 0 {
+		matches = parseConsecutiveFileMarkersForTUI(output)
+	}
+
+	var contents []string
+	for _, match := range matches {
+		contents = append(contents, strings.TrimSpace(match[2]))
+	}
+	return contents
 }
 
 func writeTaskFilesForTUI(basePath, output string) ([]string, error) {
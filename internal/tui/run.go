@@ -3,7 +3,10 @@ package tui
 import (
 	"context"
 	"fmt"
+	"io"
+	"path/filepath"
 	"strings"
+	"sync"
 	"time"
 
 	tea "github.com/charmbracelet/bubbletea"
@@ -12,11 +15,19 @@ import (
 	"hermes/internal/analyzer"
 	"hermes/internal/circuit"
 	"hermes/internal/config"
+	"hermes/internal/converter"
 	"hermes/internal/git"
+	"hermes/internal/lock"
+	"hermes/internal/github"
+	"hermes/internal/hooks"
 	"hermes/internal/prompt"
+	"hermes/internal/recovery"
+	"hermes/internal/report"
 	"hermes/internal/scheduler"
 	"hermes/internal/task"
+	"hermes/internal/tasklog"
 	"hermes/internal/ui"
+	"hermes/internal/verify"
 )
 
 // RunModel is the model for the run screen
@@ -37,6 +48,7 @@ type RunModel struct {
 	currentTask string
 	startTime   time.Time
 	cancel      context.CancelFunc
+	lock        *lock.Lock // Project lock held while running
 
 	// Parallel execution state
 	parallelRunning    bool
@@ -48,11 +60,19 @@ type RunModel struct {
 	// Components
 	taskReader *task.Reader
 	breaker    *circuit.Breaker
+	recorder   *report.Recorder
+	recorderMu sync.Mutex // Guards recorder, read by task commands while Update stops the run
+	budget     *runBudget
+	eta        *report.Estimate // Remaining time of the current run
 
 	// Progress tracking
 	completedTasks int
 	totalTasks     int
 	taskHistory    []string
+	startCompleted int // Completed tasks when the run started
+
+	// Why the last attempt of each task failed, fed back to the AI, see prompt.Failure
+	feedback map[string]string
 }
 
 // runTickMsg for updating elapsed time
@@ -68,6 +88,11 @@ type runTaskCompleteMsg struct {
 // runStoppedMsg when run is stopped
 type runStoppedMsg struct{}
 
+// runPreRunMsg when the pre-run hooks finish
+type runPreRunMsg struct {
+	err error
+}
+
 // parallelCompleteMsg when parallel execution completes
 type parallelCompleteMsg struct {
 	successful int
@@ -92,8 +117,10 @@ func NewRunModel(basePath string, logger *ui.Logger) *RunModel {
 	}
 
 	breaker := circuit.New(basePath)
+	breaker.SetCooldown(cfg.BreakerCooldown())
 	reader := task.NewReader(basePath)
 	reader.SetImplicitDocDependencies(cfg.Parallel.ImplicitDocDependencies)
+	reader.SetPriorityAging(cfg.TaskMode.PriorityAgingLoops)
 
 	// Set up circuit breaker state change logging
 	if logger != nil {
@@ -125,6 +152,7 @@ func NewRunModel(basePath string, logger *ui.Logger) *RunModel {
 		completedTasks: completedTasks,
 		taskHistory:    make([]string, 0),
 		workerStatus:   make([]string, 0),
+		feedback:       make(map[string]string),
 	}
 }
 
@@ -206,6 +234,7 @@ func (m *RunModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 			// Reset circuit breaker
 			if m.isCircuitBreakerOpen() {
 				m.breaker.Reset("Manual reset from TUI")
+				recovery.Clear(m.basePath)
 				m.lastError = ""
 				m.Refresh()
 			}
@@ -225,6 +254,9 @@ func (m *RunModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 		if m.running && !m.paused && !m.parallelRunning {
 			return m, m.executeNextTask()
 		}
+		if !m.running {
+			m.releaseLock()
+		}
 
 	case parallelProgressMsg:
 		m.parallelBatch = msg.batch
@@ -237,6 +269,7 @@ func (m *RunModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 	case parallelCompleteMsg:
 		m.running = false
 		m.parallelRunning = false
+		m.releaseLock()
 		m.Refresh()
 		if msg.err != nil {
 			m.lastError = msg.err.Error()
@@ -249,11 +282,34 @@ func (m *RunModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 			m.taskHistory = append(m.taskHistory, entry)
 		}
 
+	case runPreRunMsg:
+		if !m.running {
+			break
+		}
+		if msg.err != nil {
+			m.running = false
+			m.status = "Failed"
+			m.lastError = msg.err.Error()
+			if m.logger != nil {
+				m.logger.Error("%v", msg.err)
+			}
+			m.releaseLock()
+			break
+		}
+		return m, m.beginRun()
+
 	case runStoppedMsg:
 		m.running = false
 		m.parallelRunning = false
+		m.releaseLock()
 		m.status = "Stopped"
 		m.currentTask = ""
+		m.recorderMu.Lock()
+		if m.recorder != nil {
+			m.recorder.Finish()
+			m.recorder = nil
+		}
+		m.recorderMu.Unlock()
 	}
 
 	return m, nil
@@ -282,10 +338,21 @@ func (m *RunModel) handleSelect() tea.Cmd {
 }
 
 func (m *RunModel) startRun() tea.Cmd {
+	l, err := lock.Acquire(m.basePath, "tui")
+	if err != nil {
+		m.status = "Locked"
+		m.lastError = err.Error()
+		return nil
+	}
+	m.lock = l
+
 	m.running = true
 	m.paused = false
 	m.loopCount = 0
 	m.startTime = time.Now()
+	m.budget = newRunBudget()
+	m.eta = m.newEstimate()
+	m.startCompleted = m.completedTasks
 	m.status = "Starting..."
 	m.lastError = ""
 	m.taskHistory = make([]string, 0)
@@ -299,9 +366,53 @@ func (m *RunModel) startRun() tea.Cmd {
 		}())
 	}
 
+	if len(m.config.Hooks.PreRun) > 0 {
+		m.status = "Running pre-run hooks..."
+		return m.runPreRunHooks()
+	}
+	return m.beginRun()
+}
+
+// newEstimate estimates the remaining time of a run from the tasks still to
+// do and the recorded runs
+func (m *RunModel) newEstimate() *report.Estimate {
+	var pending []task.Task
+	features, _ := m.taskReader.GetAllFeatures()
+	for _, f := range features {
+		for _, t := range f.Tasks {
+			if t.Status != task.StatusCompleted && t.Status != task.StatusBlocked {
+				pending = append(pending, t)
+			}
+		}
+	}
+	var stats *report.Stats
+	if runs, err := report.LoadRuns(m.basePath); err == nil && len(runs) > 0 {
+		stats = report.ComputeStats(runs)
+	}
+	workers := 1
+	if m.config.Parallel.Enabled {
+		workers = m.config.Parallel.MaxWorkers
+	}
+	return report.NewEstimate(stats, pending, workers)
+}
+
+// runPreRunHooks runs the pre-run hooks in the background
+func (m *RunModel) runPreRunHooks() tea.Cmd {
+	commands := m.config.Hooks.PreRun
+	timeout := time.Duration(m.config.Hooks.Timeout) * time.Second
+	return func() tea.Msg {
+		return runPreRunMsg{err: hooks.Run(context.Background(), m.basePath, hooks.PreRun, commands, nil, timeout, io.Discard)}
+	}
+}
+
+// beginRun starts executing tasks once the pre-run hooks passed
+func (m *RunModel) beginRun() tea.Cmd {
 	if m.config.Parallel.Enabled {
 		return m.startParallelRun()
 	}
+	m.recorderMu.Lock()
+	m.recorder = report.NewRecorder(m.basePath, "sequential", m.config.AI.Coding)
+	m.recorderMu.Unlock()
 	return tea.Batch(
 		m.executeNextTask(),
 		m.runTickCmd(),
@@ -377,6 +488,21 @@ func (m *RunModel) executeParallel() tea.Cmd {
 		parallelCfg := &m.config.Parallel
 		taskTimeout := time.Duration(m.config.AI.Timeout) * time.Second
 		sched := scheduler.NewWithTimeout(parallelCfg, provider, m.basePath, m.logger, taskTimeout)
+		sched.SetEffortTimeouts(m.config.AI.EffortTimeouts)
+		if parallelCfg.ProviderFallback {
+			sched.SetFallbackProviders(ai.FallbackProviders(provider.Name()))
+		}
+		sched.SetAnalyzerConfig(&m.config.Analyzer)
+		sched.SetTaskModeConfig(&m.config.TaskMode)
+		sched.SetProjectContext(&m.config.AI.ProjectContext)
+		sched.SetMemory(m.config.AI.Memory)
+		sched.SetGitHistory(m.config.AI.GitHistory)
+		sched.SetRelease(m.featureRelease())
+		sched.SetCommitPolicy(git.NewCommitPolicy(m.config.Git))
+		sched.SetSigning(git.NewSigning(m.config.Git))
+		sched.SetCommitFormat(git.NewCommitFormat(m.config.Git))
+		sched.SetBreakerCooldown(m.config.BreakerCooldown())
+		sched.SetResourceMonitor(scheduler.NewResourceMonitor(parallelCfg.MaxMemoryMB, parallelCfg.MaxCPUPercent, 0))
 
 		// Set up progress callback to update worker status
 		m.progressChan = make(chan scheduler.ProgressEvent, 100)
@@ -437,6 +563,25 @@ func (m *RunModel) Stop() {
 	if m.cancel != nil {
 		m.cancel()
 	}
+	m.releaseLock()
+}
+
+// releaseLock runs the post-run hooks and releases the project lock once
+// the run has ended
+func (m *RunModel) releaseLock() {
+	if m.lock != nil {
+		status := "success"
+		if m.lastError != "" {
+			status = "failed"
+		}
+		timeout := time.Duration(m.config.Hooks.Timeout) * time.Second
+		env := []string{"HERMES_RUN_STATUS=" + status}
+		if err := hooks.Run(context.Background(), m.basePath, hooks.PostRun, m.config.Hooks.PostRun, env, timeout, io.Discard); err != nil && m.logger != nil {
+			m.logger.Warn("%v", err)
+		}
+		m.lock.Release()
+		m.lock = nil
+	}
 }
 
 // DrainProgress drains progress events from the channel (called by App on tick)
@@ -446,6 +591,15 @@ func (m *RunModel) DrainProgress() {
 	}
 }
 
+// BudgetView renders live counters for the run's safety limits, or "" when idle
+func (m *RunModel) BudgetView() string {
+	if !m.running || m.budget == nil {
+		return ""
+	}
+	state, _ := m.breaker.GetState()
+	return m.budget.view(m.config, state)
+}
+
 // GetStatus returns the current run status for display in other screens
 func (m *RunModel) GetStatus() (running bool, parallel bool, status string, completed int, total int) {
 	return m.running, m.parallelRunning, m.status, m.completedTasks, m.totalTasks
@@ -473,13 +627,45 @@ func (m *RunModel) executeNextTask() tea.Cmd {
 		m.cancel = cancel
 		defer cancel()
 
-		// Check circuit breaker
+		// Check run duration and budget limits
+		if reason := m.config.RunLimitReached(m.budget.elapsed(), m.budget.cost()); reason != "" {
+			m.running = false
+			m.status = "Limit reached"
+			if m.logger != nil {
+				m.logger.Warn("Stopping run: %s", reason)
+			}
+			return runStoppedMsg{}
+		}
+
+		// Check circuit breaker, waiting for its cooldown when one is set
 		canExecute, _ := m.breaker.CanExecute()
+		if at, ok := m.breaker.RecoveryAt(); !canExecute && ok {
+			state, _ := m.breaker.GetState()
+			m.status = fmt.Sprintf("Circuit open, probing at %s", at.Format("15:04:05"))
+			if m.logger != nil {
+				m.logger.Warn("Circuit breaker OPEN: %s, probing again at %s", state.Reason, at.Format("15:04:05"))
+			}
+			waitCtx, waitCancel := context.WithDeadline(context.Background(), at)
+			m.cancel = waitCancel
+			<-waitCtx.Done()
+			waitCancel()
+			if waitCtx.Err() == context.Canceled {
+				return nil // Stopped while waiting
+			}
+			ctx, cancel = context.WithTimeout(context.Background(), timeout)
+			m.cancel = cancel
+			defer cancel()
+			m.status = "Running"
+			canExecute, _ = m.breaker.CanExecute()
+		}
 		if !canExecute {
 			m.running = false
 			state, _ := m.breaker.GetState()
 			if m.logger != nil {
 				m.logger.Error("Circuit breaker OPEN: %s", state.Reason)
+				if d := recovery.ForBreaker(m.basePath, state); d != nil {
+					m.logger.Info("Suggested remediations: %s", d.Summary())
+				}
 			}
 			return runTaskCompleteMsg{err: fmt.Errorf("circuit breaker open: %s", state.Reason)}
 		}
@@ -495,6 +681,28 @@ func (m *RunModel) executeNextTask() tea.Cmd {
 			return runStoppedMsg{}
 		}
 
+		// Block a task that keeps failing, counting the attempts of earlier runs
+		maxRetries := m.config.TaskMode.MaxRetries
+		if history, err := report.LoadTaskHistory(m.basePath, nextTask.ID); err == nil && history.RetriesExhausted(maxRetries) {
+			reason := fmt.Sprintf("%d attempts in a row failed (taskMode.maxRetries is %d)", history.Failures(), maxRetries)
+			if m.logger != nil {
+				m.logger.Warn("Task %s is BLOCKED: %s", nextTask.ID, reason)
+			}
+			if err := task.NewStatusUpdater(m.basePath).BlockTask(nextTask.ID, reason); err != nil {
+				return runTaskCompleteMsg{err: err}
+			}
+			m.breaker.TaskBlocked(nextTask.ID, m.loopCount)
+			return runTaskCompleteMsg{taskID: nextTask.ID, success: false}
+		}
+
+		// Give the task the time set for it or its effort
+		if taskTimeout := nextTask.ResolveTimeout(timeout, m.config.AI.EffortTimeouts); taskTimeout != timeout {
+			timeout = taskTimeout
+			ctx, cancel = context.WithTimeout(context.Background(), timeout)
+			m.cancel = cancel
+			defer cancel()
+		}
+
 		m.loopCount++
 		m.currentTask = nextTask.ID
 		m.status = fmt.Sprintf("Loop #%d: %s", m.loopCount, nextTask.ID)
@@ -506,6 +714,9 @@ func (m *RunModel) executeNextTask() tea.Cmd {
 				m.logger.Warn("Failed to set task IN_PROGRESS: %v", err)
 			}
 		}
+		if nextTask.BlockedReason != "" {
+			statusUpdater.SetBlockedReason(nextTask.ID, "")
+		}
 
 		if m.logger != nil {
 			m.logger.Info("Starting task: %s - %s", nextTask.ID, nextTask.Name)
@@ -513,6 +724,30 @@ func (m *RunModel) executeNextTask() tea.Cmd {
 
 		// Handle branching
 		gitOps := git.New(m.basePath)
+		gitOps.SetStageExcludes(m.config.GetStageExcludes()...)
+		gitOps.SetCommitPolicy(git.NewCommitPolicy(m.config.Git))
+		gitOps.SetSigning(git.NewSigning(m.config.Git))
+		if err := gitOps.SetCommitFormat(git.NewCommitFormat(m.config.Git)); err != nil && m.logger != nil {
+			m.logger.Warn("%v, using the default commit message", err)
+		}
+		taskLog, err := tasklog.Create(m.basePath, nextTask.ID)
+		if err != nil {
+			if m.logger != nil {
+				m.logger.Warn("Failed to create task log: %v", err)
+			}
+		} else {
+			gitOps.SetCommandLog(taskLog)
+			taskLog.Section("Git")
+		}
+		defer taskLog.Close()
+		if m.config.Git.TrackTasks {
+			if err := task.EnableStatusSidecar(m.basePath, m.config.Paths.TasksDir); err == nil {
+				sidecar := filepath.ToSlash(filepath.Join(m.config.Paths.TasksDir, task.StatusSidecarFile))
+				gitOps.EnsureAttribute(sidecar, "merge=union")
+			}
+		} else {
+			task.UseStatusSidecar("")
+		}
 		if m.config.TaskMode.AutoBranch && gitOps.IsRepository() {
 			feature, _ := m.taskReader.GetFeatureByID(nextTask.FeatureID)
 			if feature != nil {
@@ -525,7 +760,8 @@ func (m *RunModel) executeNextTask() tea.Cmd {
 
 		// Inject task into prompt
 		injector := prompt.NewInjector(m.basePath)
-		injector.AddTask(nextTask)
+		injector.SetStepSubtasks(true)
+		injector.AddTaskWithFeedback(nextTask, m.feedback[nextTask.ID])
 		promptContent, _ := injector.Read()
 
 		// Get provider from config
@@ -543,37 +779,79 @@ func (m *RunModel) executeNextTask() tea.Cmd {
 
 		// Execute AI (no streaming in TUI)
 		executor := ai.NewTaskExecutor(provider, m.basePath)
+		executor.SetProjectContext(converter.ProjectContext(m.basePath, m.config.AI.ProjectContext))
+		executor.SetGitHistory(gitOps.TaskHistory(m.config.AI.GitHistory))
+		if m.logger != nil {
+			executor.SetWarn(m.logger.Warn)
+		}
+		if m.config.AI.Memory {
+			executor.SetMemory(m.basePath)
+		}
+		if taskLog != nil {
+			executor.SetTranscript(taskLog)
+		}
+		taskStart := time.Now()
 		result, err := executor.ExecuteTask(ctx, nextTask, promptContent, false)
+		if result != nil {
+			m.budget.recordCall(result.Cost)
+		} else {
+			m.budget.recordCall(0)
+		}
+		taskRecord := report.TaskRecord{
+			TaskID:    nextTask.ID,
+			TaskName:  nextTask.Name,
+			FeatureID: nextTask.FeatureID,
+			Effort:    nextTask.EstimatedEffort,
+			Duration:  time.Since(taskStart),
+			Prompt:    promptContent,
+		}
 
 		if err != nil {
-			m.breaker.AddLoopResultWithErrorLimit(false, true, m.loopCount, m.config.TaskMode.MaxConsecutiveErrors)
+			m.breaker.AddTaskLoopResult(nextTask.ID, false, true, m.loopCount, m.config.TaskMode.MaxConsecutiveErrors, m.config.TaskMode.MaxRetries)
+			taskRecord.Outcome = report.OutcomeFailed
+			taskRecord.Error = err.Error()
+			m.recordTask(taskRecord)
+			m.feedback[nextTask.ID] = prompt.Failure{Reason: prompt.ReasonError, Details: err.Error()}.Feedback()
 			return runTaskCompleteMsg{taskID: nextTask.ID, err: err}
 		}
+		taskRecord.Cost = result.Cost
+		taskRecord.Output = result.Output
 
 		// Analyze response
-		respAnalyzer := analyzer.NewResponseAnalyzer()
+		respAnalyzer := analyzer.NewResponseAnalyzerWithConfig(&m.config.Analyzer)
 
 		// Check if HERMES_STATUS block is present - if not, treat as error
 		if !respAnalyzer.HasStatusBlock(result.Output) {
 			if m.logger != nil {
 				m.logger.Warn("Task %s missing HERMES_STATUS block - will retry", nextTask.ID)
 			}
-			m.breaker.AddLoopResultWithErrorLimit(false, true, m.loopCount, m.config.TaskMode.MaxConsecutiveErrors)
+			taskLog.Section("Analysis")
+			taskLog.Printf("Missing HERMES_STATUS block, the task will be retried\n")
+			m.breaker.AddTaskLoopResult(nextTask.ID, false, true, m.loopCount, m.config.TaskMode.MaxConsecutiveErrors, m.config.TaskMode.MaxRetries)
+			taskRecord.Outcome = report.OutcomeFailed
+			taskRecord.Error = "missing HERMES_STATUS block"
+			m.recordTask(taskRecord)
+			m.feedback[nextTask.ID] = prompt.Failure{Reason: prompt.ReasonNoStatusBlock, Output: result.Output}.Feedback()
 			return runTaskCompleteMsg{taskID: nextTask.ID, err: fmt.Errorf("missing HERMES_STATUS block")}
 		}
 
+		delete(m.feedback, nextTask.ID)
 		analysis := respAnalyzer.AnalyzeWithCriteria(result.Output, nextTask.SuccessCriteria)
+		taskLog.Analysis(analysis)
 
 		// Update circuit breaker
-		m.breaker.AddLoopResultWithErrorLimit(analysis.HasProgress, false, m.loopCount, m.config.TaskMode.MaxConsecutiveErrors)
+		m.breaker.AddTaskLoopResult(nextTask.ID, analysis.HasProgress, false, m.loopCount, m.config.TaskMode.MaxConsecutiveErrors, m.config.TaskMode.MaxRetries)
 
 		// Handle blocked status
 		if analysis.IsBlocked {
 			if m.logger != nil {
 				m.logger.Warn("Task %s is BLOCKED: %s", nextTask.ID, analysis.Recommendation)
 			}
-			statusUpdater.UpdateTaskStatus(nextTask.ID, task.StatusBlocked)
+			statusUpdater.BlockTask(nextTask.ID, analysis.Recommendation)
 			injector.RemoveTask()
+			taskRecord.Outcome = report.OutcomeBlocked
+			taskRecord.Error = analysis.Recommendation
+			m.recordTask(taskRecord)
 			return runTaskCompleteMsg{taskID: nextTask.ID, success: false}
 		}
 
@@ -592,26 +870,129 @@ func (m *RunModel) executeNextTask() tea.Cmd {
 			}
 			statusUpdater.UpdateTaskStatus(nextTask.ID, task.StatusPaused)
 			injector.RemoveTask()
+			taskRecord.Outcome = report.OutcomePaused
+			m.recordTask(taskRecord)
 			return runTaskCompleteMsg{taskID: nextTask.ID, success: false}
 		}
 
+		// A task with subtasks completes one subtask per loop and stays
+		// IN_PROGRESS until its last subtask is done
+		if analysis.IsComplete {
+			if sub, remaining, err := statusUpdater.CompleteNextSubtask(nextTask); err != nil {
+				if m.logger != nil {
+					m.logger.Warn("Failed to update subtask: %v", err)
+				}
+			} else if sub != nil && remaining > 0 {
+				if m.logger != nil {
+					m.logger.Success("Subtask %s completed, %d left", sub.ID, remaining)
+				}
+				taskRecord.Subtask = sub.ID
+				analysis.IsComplete = false
+			}
+		}
+
+		// Format the changes, then run the build, lint and test commands and the
+		// task's command criteria before accepting COMPLETE, letting the AI fix
+		// failures a bounded number of times, and check the coverage
+		verifyTimeout := time.Duration(m.config.TaskMode.VerifyTimeout) * time.Second
+		format := func() {
+			if err := verify.Format(context.Background(), m.basePath, m.config.TaskMode.FormatCommand, verifyTimeout, taskLog); err != nil && m.logger != nil {
+				m.logger.Warn("Format command failed: %v", err)
+			}
+		}
+		if analysis.IsComplete && m.config.TaskMode.FormatCommand != "" {
+			taskLog.Section("Format")
+			format()
+		}
+		if commands := nextTask.GateCommands(m.config.TaskMode.TestCommand, m.config.TaskMode.BuildCommand, m.config.TaskMode.LintCommand); analysis.IsComplete && (len(commands) > 0 || m.config.TaskMode.CoverageThreshold > 0) {
+			taskLog.Section("Verification")
+			fix := func(checks string) error {
+				if m.logger != nil {
+					m.logger.Warn("Task %s failed verification, asking the AI to fix it", nextTask.ID)
+				}
+				failure := prompt.Failure{Reason: prompt.ReasonVerification, Details: checks}
+				if err := injector.AddTaskWithFeedback(nextTask, failure.Feedback()); err != nil {
+					return err
+				}
+				fixPrompt, _ := injector.Read()
+				fixCtx, fixCancel := context.WithTimeout(context.Background(), timeout)
+				defer fixCancel()
+				fixResult, err := executor.ExecuteTask(fixCtx, nextTask, fixPrompt, false)
+				if fixResult != nil {
+					m.budget.recordCall(fixResult.Cost)
+					taskRecord.Cost += fixResult.Cost
+				}
+				if err != nil {
+					return err
+				}
+				format()
+				return nil
+			}
+			failed, _ := verify.Gate(context.Background(), m.basePath, commands, verifyTimeout, taskLog, m.config.TaskMode.TestFixAttempts, fix)
+			if tm := m.config.TaskMode; len(failed) == 0 && tm.CoverageThreshold > 0 {
+				taskLog.Section("Coverage")
+				if r := verify.Coverage(context.Background(), m.basePath, tm.CoverageCommand, tm.CoverageProfile, tm.CoverageThreshold, verifyTimeout, taskLog); r != nil {
+					failed = []verify.Result{*r}
+				}
+			}
+			taskRecord.Duration = time.Since(taskStart)
+			if len(failed) > 0 {
+				if m.logger != nil {
+					m.logger.Warn("Task %s failed verification, it will be retried: %s", nextTask.ID, verify.Summary(failed))
+				}
+				m.feedback[nextTask.ID] = prompt.Failure{Reason: prompt.ReasonVerification, Details: verify.Feedback(failed), Analysis: analysis}.Feedback()
+				taskRecord.Error = verify.Summary(failed)
+				analysis.IsComplete = false
+				analysis.Status = "IN_PROGRESS"
+			}
+		}
+
 		// Update task status if complete
 		if analysis.IsComplete {
 			injector.RemoveTask()
+			actual := taskRecord.Duration
+			if recorder := m.runRecorder(); recorder != nil {
+				actual += recorder.PendingDuration(nextTask.ID)
+			}
+			statusUpdater.SetActualDuration(nextTask.ID, actual)
 			statusUpdater.UpdateTaskStatus(nextTask.ID, task.StatusCompleted)
 
 			// Auto-commit
+			taskLog.Section("Git")
 			if m.config.TaskMode.AutoCommit && gitOps.HasUncommittedChanges() {
-				if err := gitOps.StageAll(); err == nil {
-					if err := gitOps.CommitTask(nextTask.ID, nextTask.Name); err != nil {
+				if outside, err := gitOps.StageTask(nextTask.ScopeFiles()); err == nil {
+					if len(outside) > 0 && m.logger != nil {
+						m.logger.Warn("Not committing changes outside the files of task %s: %s", nextTask.ID, strings.Join(outside, ", "))
+					}
+					if err := gitOps.CommitTaskInScope(nextTask); err != nil {
 						if m.logger != nil {
 							m.logger.Warn("Failed to commit: %v", err)
 						}
-					} else if m.logger != nil {
-						m.logger.Success("Committed task %s", nextTask.ID)
+					} else {
+						hash, _ := gitOps.GetLastCommitShortHash()
+						stat := ""
+						if s, err := gitOps.GetCommitDiffStat("HEAD"); err == nil {
+							stat = s.String()
+						}
+						if m.logger != nil {
+							m.logger.Success("Committed task %s", nextTask.ID)
+							if stat != "" {
+								m.logger.Info("Changes: %s", stat)
+							}
+						}
+						if recorder := m.runRecorder(); hash != "" && recorder != nil {
+							msg, _ := gitOps.GetLastCommitMessage()
+							recorder.RecordCommit(hash, msg, stat)
+						}
 					}
 				}
 			}
+			if m.config.TaskMode.AutoCommit && m.config.Git.TrackTasks {
+				tasksDir := m.config.Paths.TasksDir
+				if err := gitOps.CommitPaths("chore(tasks): update task status", tasksDir, ".gitattributes"); err != nil && m.logger != nil {
+					m.logger.Warn("Failed to commit task files: %v", err)
+				}
+			}
 
 			// Check if feature is complete and create tag
 			if featureComplete, _ := m.taskReader.IsFeatureComplete(nextTask.FeatureID); featureComplete {
@@ -621,9 +1002,22 @@ func (m *RunModel) executeNextTask() tea.Cmd {
 						m.logger.Success("Feature %s completed: %s", feature.ID, feature.Name)
 					}
 
-					// Merge feature branch to main if auto-branch is enabled
+					// Merge feature branch to main, or open a pull request for it, if auto-branch is enabled
+					prOpened := false
 					if m.config.TaskMode.AutoBranch && gitOps.IsRepository() {
-						if err := gitOps.MergeFeatureBranch(feature.ID, feature.Name); err != nil {
+						if m.config.Git.PullRequests {
+							url, err := m.openFeaturePullRequest(gitOps, feature)
+							if err != nil {
+								if m.logger != nil {
+									m.logger.Warn("Failed to open pull request: %v", err)
+								}
+							} else {
+								if m.logger != nil {
+									m.logger.Success("Opened pull request: %s", url)
+								}
+								prOpened = true
+							}
+						} else if err := gitOps.MergeFeatureBranch(feature.ID, feature.Name); err != nil {
 							if m.logger != nil {
 								m.logger.Warn("Failed to merge feature branch: %v", err)
 							}
@@ -632,26 +1026,119 @@ func (m *RunModel) executeNextTask() tea.Cmd {
 						}
 					}
 
-					// Create git tag if TargetVersion is set
-					if feature.TargetVersion != "" && gitOps.IsRepository() {
-						if err := gitOps.CreateFeatureTag(feature.ID, feature.Name, feature.TargetVersion); err != nil {
+					// Create git tag if TargetVersion is set; with a pull request the
+					// feature is not on the main branch yet, so tagging is left to the merge
+					if prOpened && feature.TargetVersion != "" {
+						if m.logger != nil {
+							m.logger.Info("Skipping tag %s until the pull request is merged", feature.TargetVersion)
+						}
+					} else if feature.TargetVersion != "" && gitOps.IsRepository() {
+						if err := gitOps.CreateFeatureRelease(feature, m.featureRelease()); err != nil {
 							if m.logger != nil {
 								m.logger.Warn("Failed to create tag: %v", err)
 							}
-						} else if m.logger != nil {
-							m.logger.Success("Created tag: %s", feature.TargetVersion)
+						} else {
+							if m.logger != nil {
+								m.logger.Success("Created tag: %s", feature.TargetVersion)
+							}
+							if recorder := m.runRecorder(); recorder != nil {
+								recorder.RecordTag(feature.TargetVersion)
+							}
 						}
 					}
 				}
 			}
 
+			// Push the task commit and any feature merge and tag for CI
+			if m.config.TaskMode.AutoCommit && m.config.Git.AutoPush && gitOps.IsRepository() {
+				if err := gitOps.PushHead(context.Background(), m.config.Git.Remote, m.config.Git.PushRetries); err != nil {
+					if m.logger != nil {
+						m.logger.Warn("%v", err)
+					}
+				} else if m.logger != nil {
+					m.logger.Success("Pushed to %s", m.config.Git.Remote)
+				}
+			}
+
 			m.completedTasks++
+			taskRecord.Outcome = report.OutcomeCompleted
+		} else if analysis.IsAtRisk {
+			taskRecord.Outcome = report.OutcomeAtRisk
+			taskRecord.Error = analysis.Recommendation
+		} else {
+			taskRecord.Outcome = report.OutcomeIncomplete
 		}
+		m.recordTask(taskRecord)
 
 		return runTaskCompleteMsg{taskID: nextTask.ID, success: analysis.IsComplete}
 	}
 }
 
+// openFeaturePullRequest opens a pull request for a completed feature branch,
+// described by the planning AI
+func (m *RunModel) openFeaturePullRequest(gitOps *git.Git, feature *task.Feature) (string, error) {
+	return github.OpenFeaturePullRequest(gitOps, &github.FeatureRequest{
+		Feature:   feature,
+		Remote:    m.config.Git.Remote,
+		Draft:     m.config.Git.PullRequestDraft,
+		Summarize: m.summarize,
+	})
+}
+
+// featureRelease returns the release notes settings of feature tags, nil
+// when tags keep their plain message
+func (m *RunModel) featureRelease() *git.Release {
+	if !m.config.Git.ReleaseNotes && m.config.Git.Changelog == "" {
+		return nil
+	}
+	release := &git.Release{Changelog: m.config.Git.Changelog}
+	if m.config.Git.ReleaseNotes {
+		release.Summarize = m.summarize
+	}
+	return release
+}
+
+// summarize runs a prompt on the planning provider, or any available one,
+// and returns its output
+func (m *RunModel) summarize(prompt string) (string, error) {
+	var provider ai.Provider
+	if m.config.AI.Planning != "" && m.config.AI.Planning != "auto" {
+		provider = ai.GetProvider(m.config.AI.Planning)
+	}
+	if provider == nil || !provider.IsAvailable() {
+		provider = ai.AutoDetectProvider()
+	}
+	if provider == nil {
+		return "", fmt.Errorf("no AI provider available")
+	}
+	result, err := ai.ExecuteWithRetry(context.Background(), provider, &ai.ExecuteOptions{
+		Prompt:  prompt,
+		Timeout: m.config.AI.Timeout,
+	}, &ai.RetryConfig{
+		MaxRetries: m.config.AI.MaxRetries,
+		Delay:      time.Duration(m.config.AI.RetryDelay) * time.Second,
+	})
+	if err != nil {
+		return "", err
+	}
+	return result.Output, nil
+}
+
+// recordTask records a task attempt for 'hermes report' when a run is being recorded
+func (m *RunModel) recordTask(rec report.TaskRecord) {
+	if recorder := m.runRecorder(); recorder != nil {
+		recorder.RecordTask(rec)
+	}
+}
+
+// runRecorder returns the recorder of the current run, nil when none is
+// recorded or the run stopped
+func (m *RunModel) runRecorder() *report.Recorder {
+	m.recorderMu.Lock()
+	defer m.recorderMu.Unlock()
+	return m.recorder
+}
+
 func (m *RunModel) handleTaskComplete(msg runTaskCompleteMsg) {
 	if msg.err != nil {
 		m.lastError = msg.err.Error()
@@ -661,6 +1148,9 @@ func (m *RunModel) handleTaskComplete(msg runTaskCompleteMsg) {
 			m.logger.Error("Task %s failed: %s", msg.taskID, msg.err.Error())
 		}
 	} else if msg.success {
+		if m.eta != nil {
+			m.eta.Complete(msg.taskID)
+		}
 		entry := fmt.Sprintf("[DONE] %s", msg.taskID)
 		m.taskHistory = append(m.taskHistory, entry)
 		if m.logger != nil {
@@ -706,6 +1196,9 @@ func (m *RunModel) drainProgressChannel() {
 			// Update completed tasks count
 			if event.Status == "completed" {
 				m.completedTasks++
+				if m.eta != nil {
+					m.eta.Complete(event.TaskID)
+				}
 			}
 		default:
 			// No more events
@@ -754,7 +1247,13 @@ func (m *RunModel) View() string {
 		if m.parallelRunning {
 			modeStr = fmt.Sprintf("Parallel (%d workers)", m.config.Parallel.MaxWorkers)
 		}
-		b.WriteString(fmt.Sprintf("  Mode: %s | Status: %s | Elapsed: %v\n", modeStr, SuccessStyle.Render(m.status), elapsed))
+		etaStr := "-"
+		if m.eta != nil {
+			if eta, ok := m.eta.Remaining(m.completedTasks-m.startCompleted, elapsed); ok {
+				etaStr = report.FormatETA(eta)
+			}
+		}
+		b.WriteString(fmt.Sprintf("  Mode: %s | Status: %s | Elapsed: %v | ETA: %s\n", modeStr, SuccessStyle.Render(m.status), elapsed, etaStr))
 		
 		if m.parallelRunning && len(m.workerStatus) > 0 {
 			b.WriteString("\n")
@@ -768,7 +1267,7 @@ func (m *RunModel) View() string {
 		}
 	} else {
 		// Show idle status when not running
-		b.WriteString(fmt.Sprintf("  Mode: - | Status: %s | Elapsed: -\n", MutedStyle.Render("Idle")))
+		b.WriteString(fmt.Sprintf("  Mode: - | Status: %s | Elapsed: - | ETA: -\n", MutedStyle.Render("Idle")))
 		b.WriteString("  \n") // Placeholder for Current line
 	}
 	b.WriteString("\n")
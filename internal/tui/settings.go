@@ -5,8 +5,10 @@ import (
 	"fmt"
 	"os"
 	"path/filepath"
+	"strconv"
 	"strings"
 
+	"github.com/charmbracelet/bubbles/textinput"
 	tea "github.com/charmbracelet/bubbletea"
 	"github.com/charmbracelet/lipgloss"
 	"hermes/internal/config"
@@ -24,21 +26,103 @@ type SettingsModel struct {
 	focusIndex int
 	scrollPos  int
 	saved      bool
+	reset      bool // Defaults were restored but not saved yet
 	err        error
+	loadErr    error // The config files cannot be loaded, saving would replace them
+	editing    bool  // The focused setting is being typed in
+	input      textinput.Model
 }
 
-const maxFocusIndex = 28 // Total number of settings + save button
+// Focus indexes of the buttons below the settings
+const (
+	saveButton    = 27
+	resetButton   = 28
+	maxFocusIndex = resetButton
+)
+
+// settingsField is a setting whose value can also be typed in
+type settingsField struct {
+	key   string // Option, e.g. ai.timeout, whose validation applies
+	value func(cfg *config.Config) string
+	set   func(cfg *config.Config, value string) error
+}
+
+// editableFields are the numeric and path settings by focus index
+var editableFields = map[int]settingsField{
+	3:  intField("ai.timeout", func(c *config.Config) *int { return &c.AI.Timeout }),
+	4:  intField("ai.planningTimeout", func(c *config.Config) *int { return &c.AI.PlanningTimeout }),
+	5:  intField("ai.maxRetries", func(c *config.Config) *int { return &c.AI.MaxRetries }),
+	6:  intField("ai.retryDelay", func(c *config.Config) *int { return &c.AI.RetryDelay }),
+	10: intField("taskMode.maxConsecutiveErrors", func(c *config.Config) *int { return &c.TaskMode.MaxConsecutiveErrors }),
+	11: intField("loop.maxCallsPerHour", func(c *config.Config) *int { return &c.Loop.MaxCallsPerHour }),
+	12: intField("loop.timeoutMinutes", func(c *config.Config) *int { return &c.Loop.TimeoutMinutes }),
+	13: intField("loop.errorDelay", func(c *config.Config) *int { return &c.Loop.ErrorDelay }),
+	14: pathField("paths.hermesDir", func(c *config.Config) *string { return &c.Paths.HermesDir }),
+	15: pathField("paths.tasksDir", func(c *config.Config) *string { return &c.Paths.TasksDir }),
+	16: pathField("paths.logsDir", func(c *config.Config) *string { return &c.Paths.LogsDir }),
+	17: pathField("paths.docsDir", func(c *config.Config) *string { return &c.Paths.DocsDir }),
+	19: intField("parallel.maxWorkers", func(c *config.Config) *int { return &c.Parallel.MaxWorkers }),
+	24: {
+		key:   "parallel.maxCostPerHour",
+		value: func(c *config.Config) string { return strconv.FormatFloat(c.Parallel.MaxCostPerHour, 'f', -1, 64) },
+		set: func(c *config.Config, value string) error {
+			f, err := strconv.ParseFloat(strings.TrimPrefix(value, "$"), 64)
+			if err != nil {
+				return fmt.Errorf("%q is not an amount, e.g. 12.50 (0 for no limit)", value)
+			}
+			c.Parallel.MaxCostPerHour = f
+			return nil
+		},
+	},
+	26: intField("parallel.maxRetries", func(c *config.Config) *int { return &c.Parallel.MaxRetries }),
+}
+
+func intField(key string, field func(c *config.Config) *int) settingsField {
+	return settingsField{
+		key:   key,
+		value: func(c *config.Config) string { return strconv.Itoa(*field(c)) },
+		set: func(c *config.Config, value string) error {
+			n, err := strconv.Atoi(value)
+			if err != nil {
+				return fmt.Errorf("%q is not a whole number", value)
+			}
+			*field(c) = n
+			return nil
+		},
+	}
+}
+
+func pathField(key string, field func(c *config.Config) *string) settingsField {
+	return settingsField{
+		key:   key,
+		value: func(c *config.Config) string { return *field(c) },
+		set: func(c *config.Config, value string) error {
+			if value == "" {
+				return fmt.Errorf("the path must not be empty")
+			}
+			*field(c) = filepath.ToSlash(filepath.Clean(value))
+			return nil
+		},
+	}
+}
 
 // NewSettingsModel creates a new settings model
 func NewSettingsModel(basePath string) *SettingsModel {
-	cfg, err := config.Load(basePath)
-	if err != nil {
+	cfg, loadErr := config.LoadFiles(basePath)
+	if loadErr != nil {
 		cfg = config.DefaultConfig()
 	}
 
+	input := textinput.New()
+	input.CharLimit = 200
+	input.Width = 30
+
 	return &SettingsModel{
 		basePath: basePath,
 		config:   cfg,
+		err:      loadErr,
+		loadErr:  loadErr,
+		input:    input,
 	}
 }
 
@@ -55,17 +139,35 @@ func (m *SettingsModel) SetSize(width, height int) {
 
 // Refresh reloads the configuration
 func (m *SettingsModel) Refresh() {
-	cfg, err := config.Load(m.basePath)
+	cfg, err := config.LoadFiles(m.basePath)
 	if err != nil {
 		cfg = config.DefaultConfig()
 	}
 	m.config = cfg
+	m.err, m.loadErr = err, err
+	m.editing = false
+	m.reset = false
 }
 
 // Update handles messages
 func (m *SettingsModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 	switch msg := msg.(type) {
 	case tea.KeyMsg:
+		if m.editing {
+			switch msg.String() {
+			case "enter":
+				m.applyEdit()
+			case "esc":
+				m.editing = false
+				m.err = nil
+			default:
+				var cmd tea.Cmd
+				m.input, cmd = m.input.Update(msg)
+				return m, cmd
+			}
+			return m, nil
+		}
+
 		switch msg.String() {
 		case "j", "down":
 			m.focusIndex++
@@ -83,13 +185,63 @@ func (m *SettingsModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 		case " ", "enter":
 			m.saved = false
 			m.err = nil
-			m.handleSelect()
+			return m, m.handleSelect()
+		case "e":
+			if field, ok := editableFields[m.focusIndex]; ok {
+				m.saved = false
+				m.err = nil
+				m.editing = true
+				m.input.SetValue(field.value(m.config))
+				m.input.CursorEnd()
+				m.input.Focus()
+				return m, textinput.Blink
+			}
 		}
 	}
 
 	return m, nil
 }
 
+// applyEdit sets the focused setting to the typed value, unless the value
+// is invalid for it
+func (m *SettingsModel) applyEdit() {
+	field := editableFields[m.focusIndex]
+	previous := *m.config
+	if err := field.set(m.config, strings.TrimSpace(m.input.Value())); err != nil {
+		m.err = err
+		return
+	}
+	for _, p := range m.config.Validate() {
+		if p.Key == field.key {
+			*m.config = previous
+			m.err = fmt.Errorf("%s", p.Message)
+			return
+		}
+	}
+	m.editing = false
+	m.input.Blur()
+	m.err = nil
+}
+
+// resetToDefaults restores the default of every setting on the screen; the
+// options edited elsewhere, such as git and profiles, are kept
+func (m *SettingsModel) resetToDefaults() {
+	d := config.DefaultConfig()
+	c := m.config
+	c.AI.Planning, c.AI.Coding, c.AI.StreamOutput = d.AI.Planning, d.AI.Coding, d.AI.StreamOutput
+	c.AI.Timeout, c.AI.PlanningTimeout = d.AI.Timeout, d.AI.PlanningTimeout
+	c.AI.MaxRetries, c.AI.RetryDelay = d.AI.MaxRetries, d.AI.RetryDelay
+	c.TaskMode.AutoBranch, c.TaskMode.AutoCommit, c.TaskMode.Autonomous = d.TaskMode.AutoBranch, d.TaskMode.AutoCommit, d.TaskMode.Autonomous
+	c.TaskMode.MaxConsecutiveErrors = d.TaskMode.MaxConsecutiveErrors
+	c.Loop.MaxCallsPerHour, c.Loop.TimeoutMinutes, c.Loop.ErrorDelay = d.Loop.MaxCallsPerHour, d.Loop.TimeoutMinutes, d.Loop.ErrorDelay
+	c.Paths = d.Paths
+	c.Parallel.Enabled, c.Parallel.MaxWorkers, c.Parallel.Strategy = d.Parallel.Enabled, d.Parallel.MaxWorkers, d.Parallel.Strategy
+	c.Parallel.ConflictResolution, c.Parallel.IsolatedWorkspaces = d.Parallel.ConflictResolution, d.Parallel.IsolatedWorkspaces
+	c.Parallel.MergeStrategy, c.Parallel.MaxCostPerHour = d.Parallel.MergeStrategy, d.Parallel.MaxCostPerHour
+	c.Parallel.FailureStrategy, c.Parallel.MaxRetries = d.Parallel.FailureStrategy, d.Parallel.MaxRetries
+	m.reset = true
+}
+
 func (m *SettingsModel) updateScroll() {
 	visibleLines := m.height - 10
 	if visibleLines < 10 {
@@ -111,7 +263,7 @@ func (m *SettingsModel) handleSelect() tea.Cmd {
 	strategies := []string{"continue", "fail-fast"}
 	parallelStrategies := []string{"branch-per-task", "worktree"}
 	conflictStrategies := []string{"ai-assisted", "manual", "auto-merge"}
-	mergeStrategies := []string{"sequential", "parallel"}
+	mergeStrategies := []string{"sequential", "parallel", "rebase"}
 
 	switch m.focusIndex {
 	// AI Configuration
@@ -123,8 +275,8 @@ func (m *SettingsModel) handleSelect() tea.Cmd {
 		m.config.AI.StreamOutput = !m.config.AI.StreamOutput
 	case 3: // Timeout
 		m.cycleIntOption(&m.config.AI.Timeout, []int{120, 300, 600, 900, 1200})
-	case 4: // PRD Timeout
-		m.cycleIntOption(&m.config.AI.PrdTimeout, []int{600, 900, 1200, 1800, 2400})
+	case 4: // Planning Timeout
+		m.cycleIntOption(&m.config.AI.PlanningTimeout, []int{600, 900, 1200, 1800, 2400})
 	case 5: // Max Retries
 		m.config.AI.MaxRetries++
 		if m.config.AI.MaxRetries > 15 {
@@ -197,15 +349,18 @@ func (m *SettingsModel) handleSelect() tea.Cmd {
 			m.config.Parallel.MaxRetries = 0
 		}
 
-	// Save Button
-	case 27, 28:
+	// Buttons
+	case saveButton:
 		err := m.saveConfig()
 		if err != nil {
 			m.err = err
 		} else {
 			m.saved = true
+			m.reset = false
 			return func() tea.Msg { return ConfigSavedMsg{} }
 		}
+	case resetButton:
+		m.resetToDefaults()
 	}
 	return nil
 }
@@ -250,7 +405,7 @@ func (m *SettingsModel) View() string {
 	m.renderOption(&b, 1, labelStyle, SelectedStyle, valueStyle, "Coding Provider:", m.config.AI.Coding)
 	m.renderBoolOption(&b, 2, labelStyle, SelectedStyle, "Stream Output:", m.config.AI.StreamOutput)
 	m.renderOption(&b, 3, labelStyle, SelectedStyle, valueStyle, "Timeout:", fmt.Sprintf("%ds", m.config.AI.Timeout))
-	m.renderOption(&b, 4, labelStyle, SelectedStyle, valueStyle, "PRD Timeout:", fmt.Sprintf("%ds", m.config.AI.PrdTimeout))
+	m.renderOption(&b, 4, labelStyle, SelectedStyle, valueStyle, "Planning Timeout:", fmt.Sprintf("%ds", m.config.AI.PlanningTimeout))
 	m.renderOption(&b, 5, labelStyle, SelectedStyle, valueStyle, "Max Retries:", fmt.Sprintf("%d", m.config.AI.MaxRetries))
 	m.renderOption(&b, 6, labelStyle, SelectedStyle, valueStyle, "Retry Delay:", fmt.Sprintf("%ds", m.config.AI.RetryDelay))
 
@@ -298,16 +453,34 @@ func (m *SettingsModel) View() string {
 	m.renderOption(&b, 25, labelStyle, SelectedStyle, valueStyle, "Failure Strategy:", m.config.Parallel.FailureStrategy)
 	m.renderOption(&b, 26, labelStyle, SelectedStyle, valueStyle, "Max Retries:", fmt.Sprintf("%d", m.config.Parallel.MaxRetries))
 
-	// Save Button
+	// Buttons
 	b.WriteString("\n\n")
-	if m.focusIndex == 27 || m.focusIndex == 28 {
-		b.WriteString(SelectedStyle.Render("> "))
-	} else {
-		b.WriteString("  ")
+	for _, button := range []struct {
+		index int
+		label string
+	}{{saveButton, "Save Configuration"}, {resetButton, "Reset to Defaults"}} {
+		if m.focusIndex == button.index {
+			b.WriteString(SelectedStyle.Render("> "))
+		} else {
+			b.WriteString("  ")
+		}
+		b.WriteString(ButtonStyle.Render(button.label))
+		b.WriteString(" ")
 	}
-	b.WriteString(ButtonStyle.Render("Save Configuration"))
 	b.WriteString("\n\n")
 
+	if m.editing {
+		b.WriteString(MutedStyle.Render("Enter: apply  Esc: cancel"))
+	} else if _, ok := editableFields[m.focusIndex]; ok {
+		b.WriteString(MutedStyle.Render("Space/Enter: next value  e: type a value"))
+	}
+	b.WriteString("\n")
+
+	if m.reset {
+		b.WriteString(WarningStyle.Render("Defaults restored, save to keep them"))
+		b.WriteString("\n")
+	}
+
 	if m.saved {
 		successStyle := lipgloss.NewStyle().Foreground(lipgloss.Color("82"))
 		b.WriteString(successStyle.Render("Configuration saved successfully!"))
@@ -330,7 +503,11 @@ func (m *SettingsModel) renderOption(b *strings.Builder, index int, labelStyle,
 		b.WriteString("  ")
 	}
 	b.WriteString(labelStyle.Render(label))
-	b.WriteString(valueStyle.Render(value))
+	if m.editing && m.focusIndex == index {
+		b.WriteString(m.input.View())
+	} else {
+		b.WriteString(valueStyle.Render(value))
+	}
 	b.WriteString("\n")
 }
 
@@ -350,13 +527,29 @@ func (m *SettingsModel) renderBoolOption(b *strings.Builder, index int, labelSty
 }
 
 func (m *SettingsModel) saveConfig() error {
+	if m.loadErr != nil {
+		return fmt.Errorf("fix the config before saving settings: %v", m.loadErr)
+	}
 	configPath := filepath.Join(m.basePath, ".hermes", "config.json")
+	// Rewriting a YAML or TOML config would lose its comments, and a new
+	// config.json would take precedence over it
+	if existing := config.FindFile(filepath.Dir(configPath)); existing != "" && existing != configPath {
+		return fmt.Errorf("settings are saved to config.json only, edit %s instead", filepath.Base(existing))
+	}
 
 	data, err := json.MarshalIndent(m.config, "", "  ")
 	if err != nil {
 		return fmt.Errorf("failed to marshal config: %w", err)
 	}
 
+	// Keep the profiles of the file, which are not part of the config
+	var existing struct {
+		Profiles json.RawMessage `json:"profiles"`
+	}
+	if old, err := os.ReadFile(configPath); err == nil && json.Unmarshal(old, &existing) == nil && existing.Profiles != nil {
+		data = append(data[:len(data)-len("\n}")], ",\n  \"profiles\": "+string(existing.Profiles)+"\n}"...)
+	}
+
 	if err := os.WriteFile(configPath, data, 0644); err != nil {
 		return fmt.Errorf("failed to write config: %w", err)
 	}
@@ -2,13 +2,24 @@ package tui
 
 import (
 	"fmt"
+	"os"
+	"path/filepath"
 	"strings"
+	"time"
 
 	tea "github.com/charmbracelet/bubbletea"
 	"github.com/charmbracelet/lipgloss"
+	"hermes/internal/report"
 	"hermes/internal/task"
+	"hermes/internal/tasklog"
 )
 
+// taskLogTailLines is how many lines of the latest task log the detail screen shows
+const taskLogTailLines = 40
+
+// taskDetailAttempts is how many of the latest attempts the detail screen shows
+const taskDetailAttempts = 10
+
 // TaskDetailModel is the task detail screen model
 type TaskDetailModel struct {
 	basePath string
@@ -16,6 +27,9 @@ type TaskDetailModel struct {
 	height   int
 	task     *task.Task
 	feature  *task.Feature
+	logs     []string // Per-task attempt logs, oldest first
+	history  *report.TaskHistory
+	showLog  bool
 	scroll   int
 }
 
@@ -30,10 +44,15 @@ func NewTaskDetailModel(basePath string) *TaskDetailModel {
 func (m *TaskDetailModel) SetTask(t *task.Task) {
 	m.task = t
 	m.scroll = 0
+	m.showLog = false
+	m.logs = nil
+	m.history = nil
 
 	if t != nil {
 		reader := task.NewReader(m.basePath)
 		m.feature, _ = reader.GetFeatureByID(t.FeatureID)
+		m.logs, _ = tasklog.List(m.basePath, t.ID)
+		m.history, _ = report.LoadTaskHistory(m.basePath, t.ID)
 	}
 }
 
@@ -59,6 +78,10 @@ func (m *TaskDetailModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 			if m.scroll > 0 {
 				m.scroll--
 			}
+		case "l":
+			if len(m.logs) > 0 {
+				m.showLog = !m.showLog
+			}
 		}
 	}
 	return m, nil
@@ -103,6 +126,11 @@ func (m *TaskDetailModel) View() string {
 	}
 	info.WriteString(statusStyle.Render(string(t.Status)))
 	info.WriteString("\n\n")
+	if t.BlockedReason != "" {
+		info.WriteString(boldStyle.Render("Blocked Reason: "))
+		info.WriteString(t.BlockedReason)
+		info.WriteString("\n\n")
+	}
 
 	// Priority and Effort on same line
 	info.WriteString(boldStyle.Render("Priority: "))
@@ -121,6 +149,11 @@ func (m *TaskDetailModel) View() string {
 		info.WriteString(boldStyle.Render("Effort: "))
 		info.WriteString(t.EstimatedEffort)
 	}
+	if t.ActualDuration > 0 {
+		info.WriteString("  |  ")
+		info.WriteString(boldStyle.Render("Actual: "))
+		info.WriteString(t.ActualDuration.String())
+	}
 	info.WriteString("\n\n")
 
 	// Feature
@@ -134,6 +167,13 @@ func (m *TaskDetailModel) View() string {
 	}
 	info.WriteString("\n\n")
 
+	// PRD traceability
+	if t.PRDSection != "" {
+		info.WriteString(boldStyle.Render("PRD Section: "))
+		info.WriteString(t.PRDSection)
+		info.WriteString("\n\n")
+	}
+
 	// Description
 	if t.Description != "" {
 		info.WriteString(SectionStyle.Render("Description"))
@@ -179,10 +219,85 @@ func (m *TaskDetailModel) View() string {
 		}
 	}
 
+	// Subtasks
+	if len(t.Subtasks) > 0 {
+		done, total := t.SubtaskProgress()
+		info.WriteString("\n")
+		info.WriteString(SectionStyle.Render(fmt.Sprintf("Subtasks (%d/%d)", done, total)))
+		info.WriteString("\n")
+		for _, s := range t.Subtasks {
+			box := " "
+			if s.Status == task.StatusCompleted {
+				box = "x"
+			}
+			info.WriteString(fmt.Sprintf("  [%s] %s: %s\n", box, s.ID, s.Name))
+		}
+	}
+
+	// Attempt history
+	if m.history != nil && len(m.history.Attempts) > 0 {
+		attempts := m.history.Attempts
+		info.WriteString("\n")
+		info.WriteString(SectionStyle.Render(fmt.Sprintf("Attempts (%d, %d failed in a row)", len(attempts), m.history.Failures())))
+		info.WriteString("\n")
+		if len(attempts) > taskDetailAttempts {
+			attempts = attempts[len(attempts)-taskDetailAttempts:]
+		}
+		for _, a := range attempts {
+			outcome := a.Outcome
+			if a.Subtask != "" {
+				outcome = a.Subtask + " done"
+			}
+			line := fmt.Sprintf("  %s  %-12s %8s", a.Time.Local().Format("2006-01-02 15:04"), outcome, a.Duration.Round(time.Second))
+			if msg := strings.ReplaceAll(a.Error, "\n", " "); msg != "" {
+				if len(msg) > 60 {
+					msg = msg[:57] + "..."
+				}
+				line += MutedStyle.Render("  " + msg)
+			}
+			info.WriteString(line + "\n")
+		}
+	}
+
+	// Attempt logs
+	if len(m.logs) > 0 {
+		info.WriteString("\n")
+		info.WriteString(SectionStyle.Render("Logs"))
+		info.WriteString("\n")
+		for i, path := range m.logs {
+			line := fmt.Sprintf("  %s", filepath.ToSlash(path))
+			if i == len(m.logs)-1 {
+				line += MutedStyle.Render(" (latest)")
+			}
+			info.WriteString(line + "\n")
+		}
+		if m.showLog {
+			info.WriteString("\n")
+			info.WriteString(m.renderLatestLog())
+		}
+	}
+
 	sb.WriteString(infoBox.Render(info.String()))
 	sb.WriteString("\n\n")
 
-	sb.WriteString(MutedStyle.Render("[Esc] Back to tasks | [j/k] Scroll"))
+	help := "[Esc] Back to tasks | [j/k] Scroll"
+	if len(m.logs) > 0 {
+		help += " | [l] Toggle latest log"
+	}
+	sb.WriteString(MutedStyle.Render(help))
 
 	return sb.String()
 }
+
+// renderLatestLog returns the last lines of the latest attempt log
+func (m *TaskDetailModel) renderLatestLog() string {
+	data, err := os.ReadFile(m.logs[len(m.logs)-1])
+	if err != nil {
+		return ErrorStyle.Render(fmt.Sprintf("Failed to read log: %v", err)) + "\n"
+	}
+	lines := strings.Split(strings.TrimRight(strings.ReplaceAll(string(data), "\r\n", "\n"), "\n"), "\n")
+	if len(lines) > taskLogTailLines {
+		lines = lines[len(lines)-taskLogTailLines:]
+	}
+	return MutedStyle.Render(strings.Join(lines, "\n")) + "\n"
+}
@@ -17,6 +17,7 @@ type TasksModel struct {
 	tasks    []task.Task
 	cursor   int
 	filter   task.Status
+	message  string
 }
 
 // NewTasksModel creates a new tasks model
@@ -50,6 +51,7 @@ func (m *TasksModel) Init() tea.Cmd {
 func (m *TasksModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 	switch msg := msg.(type) {
 	case tea.KeyMsg:
+		m.message = ""
 		switch msg.String() {
 		case "j", "down":
 			if m.cursor < len(m.filteredTasks())-1 {
@@ -74,6 +76,8 @@ func (m *TasksModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 		case "b":
 			m.filter = task.StatusBlocked
 			m.cursor = 0
+		case "A":
+			m.archiveCompleted()
 		}
 	}
 	return m, nil
@@ -95,12 +99,17 @@ func (m *TasksModel) View() string {
 	}
 
 	// Filter bar
-	filterBar := "[a]All [c]Completed [p]In Progress [n]Not Started [b]Blocked"
+	filterBar := "[a]All [c]Completed [p]In Progress [n]Not Started [b]Blocked [A]Archive Done"
 	if m.filter != "" {
 		filterBar += fmt.Sprintf(" | Filter: %s", m.filter)
 	}
 	sb.WriteString(MutedStyle.Render(filterBar))
-	sb.WriteString("\n\n")
+	sb.WriteString("\n")
+	if m.message != "" {
+		sb.WriteString(SuccessStyle.Render(m.message))
+		sb.WriteString("\n")
+	}
+	sb.WriteString("\n")
 
 	// Header
 	headerStyle := lipgloss.NewStyle().
@@ -183,9 +192,47 @@ func (m *TasksModel) View() string {
 		sb.WriteString(MutedStyle.Render(fmt.Sprintf("Showing %d tasks", len(tasks))))
 	}
 
+	// Why the selected task is blocked
+	if m.cursor < len(tasks) {
+		if t := tasks[m.cursor]; t.Status == task.StatusBlocked && t.BlockedReason != "" {
+			sb.WriteString("\n")
+			sb.WriteString(ErrorStyle.Render("Blocked: " + t.BlockedReason))
+		}
+	}
+
 	return sb.String()
 }
 
+// archiveCompleted moves all fully completed features into the archive
+func (m *TasksModel) archiveCompleted() {
+	features, err := task.NewReader(m.basePath).GetAllFeatures()
+	if err != nil {
+		m.message = fmt.Sprintf("Archive failed: %v", err)
+		return
+	}
+
+	var archived []string
+	for i := range features {
+		if !features[i].IsArchivable() {
+			continue
+		}
+		if _, err := task.ArchiveFeature(m.basePath, &features[i]); err != nil {
+			m.message = fmt.Sprintf("Archive failed: %v", err)
+			m.Refresh()
+			return
+		}
+		archived = append(archived, features[i].ID)
+	}
+
+	if len(archived) == 0 {
+		m.message = "No completed features to archive"
+		return
+	}
+	m.message = fmt.Sprintf("Archived %s", strings.Join(archived, ", "))
+	m.cursor = 0
+	m.Refresh()
+}
+
 func (m *TasksModel) filteredTasks() []task.Task {
 	if m.filter == "" {
 		return m.tasks
@@ -7,6 +7,7 @@ import (
 	"time"
 
 	"github.com/fatih/color"
+	"hermes/internal/logfile"
 )
 
 // LogLevel represents a logging level
@@ -30,7 +31,7 @@ var (
 
 // Logger provides logging to console and file
 type Logger struct {
-	logFile  *os.File
+	logFile  *logfile.File
 	logPath  string
 	minLevel LogLevel
 	debug    bool
@@ -45,7 +46,7 @@ func NewLogger(basePath string, debug bool) (*Logger, error) {
 	}
 
 	logPath := filepath.Join(logsDir, "hermes.log")
-	file, err := os.OpenFile(logPath, os.O_CREATE|os.O_APPEND|os.O_WRONLY, 0644)
+	file, err := logfile.Open(logPath)
 	if err != nil {
 		return nil, err
 	}
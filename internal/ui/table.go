@@ -163,6 +163,22 @@ func PrintTaskTable(tasks []task.Task) {
 	fmt.Print(formatSeparator("bottom"))
 }
 
+// PrintBlockedReasons prints why blocked tasks are blocked
+func PrintBlockedReasons(tasks []task.Task) {
+	printed := false
+	for _, t := range tasks {
+		if t.Status != task.StatusBlocked || t.BlockedReason == "" {
+			continue
+		}
+		if !printed {
+			fmt.Println()
+			color.New(color.Bold).Println("Blocked:")
+			printed = true
+		}
+		fmt.Printf("  %s: %s\n", t.ID, t.BlockedReason)
+	}
+}
+
 // FilterTasksByStatus filters tasks by status
 func FilterTasksByStatus(tasks []task.Task, status task.Status) []task.Task {
 	var filtered []task.Task
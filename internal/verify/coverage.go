@@ -0,0 +1,177 @@
+package verify
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"path"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// PackageCoverage is the statement coverage of a package in a cover profile
+type PackageCoverage struct {
+	Package    string
+	Statements int
+	Covered    int
+}
+
+// Percent returns the percentage of covered statements
+func (c PackageCoverage) Percent() float64 {
+	if c.Statements == 0 {
+		return 100
+	}
+	return 100 * float64(c.Covered) / float64(c.Statements)
+}
+
+// ParseCoverProfile returns the coverage of each package in a Go cover
+// profile, sorted by package. Blocks listed more than once count once.
+func ParseCoverProfile(r io.Reader) ([]PackageCoverage, error) {
+	type block struct {
+		statements int
+		covered    bool
+	}
+	blocks := make(map[string]block)
+	scanner := bufio.NewScanner(r)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "mode:") {
+			continue
+		}
+		// file.go:12.34,15.2 3 1
+		fields := strings.Fields(line)
+		if len(fields) != 3 {
+			return nil, fmt.Errorf("invalid cover profile line %q", line)
+		}
+		statements, err := strconv.Atoi(fields[1])
+		if err != nil {
+			return nil, fmt.Errorf("invalid cover profile line %q", line)
+		}
+		count, err := strconv.Atoi(fields[2])
+		if err != nil {
+			return nil, fmt.Errorf("invalid cover profile line %q", line)
+		}
+		b := blocks[fields[0]]
+		b.statements = statements
+		b.covered = b.covered || count > 0
+		blocks[fields[0]] = b
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+
+	byPackage := make(map[string]*PackageCoverage)
+	for key, b := range blocks {
+		file, _, _ := strings.Cut(key, ":")
+		pkg := path.Dir(file)
+		c := byPackage[pkg]
+		if c == nil {
+			c = &PackageCoverage{Package: pkg}
+			byPackage[pkg] = c
+		}
+		c.Statements += b.statements
+		if b.covered {
+			c.Covered += b.statements
+		}
+	}
+
+	coverage := make([]PackageCoverage, 0, len(byPackage))
+	for _, c := range byPackage {
+		coverage = append(coverage, *c)
+	}
+	sort.Slice(coverage, func(i, j int) bool {
+		return coverage[i].Package < coverage[j].Package
+	})
+	return coverage, nil
+}
+
+// Coverage runs command, which must write a Go cover profile to profile, and
+// checks the coverage of each package against threshold percent. It returns
+// nil when all packages reach it, and otherwise a failed result listing the
+// packages below it and their uncovered functions.
+func Coverage(ctx context.Context, dir, command, profile string, threshold float64, timeout time.Duration, out io.Writer) *Result {
+	profilePath := profile
+	if !filepath.IsAbs(profilePath) {
+		profilePath = filepath.Join(dir, profilePath)
+	}
+	if err := os.MkdirAll(filepath.Dir(profilePath), 0755); err != nil {
+		return &Result{Command: command, Err: err}
+	}
+	os.Remove(profilePath)
+
+	result := run(ctx, dir, command, timeout, out)
+	if !result.Passed() {
+		return &result
+	}
+
+	f, err := os.Open(profilePath)
+	if err != nil {
+		return &Result{Command: command, Err: fmt.Errorf("no cover profile written: %w", err)}
+	}
+	coverage, err := ParseCoverProfile(f)
+	f.Close()
+	if err != nil {
+		return &Result{Command: command, Err: err}
+	}
+
+	var below []PackageCoverage
+	for _, c := range coverage {
+		if c.Percent() < threshold {
+			below = append(below, c)
+		}
+	}
+	if len(below) == 0 {
+		fmt.Fprintf(out, "coverage of all packages is at least %.1f%%\n", threshold)
+		return nil
+	}
+
+	var sb strings.Builder
+	packages := make([]string, len(below))
+	for i, c := range below {
+		packages[i] = c.Package
+		fmt.Fprintf(&sb, "%s: %.1f%% of statements covered\n", c.Package, c.Percent())
+	}
+	if funcs := uncoveredFunctions(ctx, dir, profile, packages, timeout); len(funcs) > 0 {
+		sb.WriteString("\nUncovered functions:\n")
+		for _, fn := range funcs {
+			fmt.Fprintf(&sb, "- %s\n", fn)
+		}
+	}
+	fmt.Fprint(out, sb.String())
+	return &Result{
+		Command:  command,
+		Output:   sb.String(),
+		Err:      fmt.Errorf("coverage of %s is below %.1f%%", strings.Join(packages, ", "), threshold),
+		Duration: result.Duration,
+	}
+}
+
+// uncoveredFunctions returns the functions of the packages that no test runs,
+// as reported by go tool cover, e.g. "hermes/internal/verify/verify.go:33: Run"
+func uncoveredFunctions(ctx context.Context, dir, profile string, packages []string, timeout time.Duration) []string {
+	result := run(ctx, dir, "go tool cover -func="+profile, timeout, io.Discard)
+	if !result.Passed() {
+		return nil
+	}
+	var funcs []string
+	for _, line := range strings.Split(result.Output, "\n") {
+		// hermes/internal/verify/verify.go:33:	Run		85.7%
+		fields := strings.Fields(line)
+		if len(fields) != 3 || fields[2] != "0.0%" {
+			continue
+		}
+		file, _, _ := strings.Cut(fields[0], ":")
+		for _, pkg := range packages {
+			if path.Dir(file) == pkg {
+				funcs = append(funcs, fields[0]+" "+fields[1])
+				break
+			}
+		}
+	}
+	return funcs
+}
@@ -0,0 +1,145 @@
+package verify
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"os/exec"
+	"runtime"
+	"strings"
+	"time"
+
+	"hermes/internal/ai"
+)
+
+// maxFeedbackOutput bounds the command output fed back to the AI per command
+const maxFeedbackOutput = 4000
+
+// Result is the outcome of a verification command
+type Result struct {
+	Command  string
+	Output   string
+	Err      error
+	Duration time.Duration
+}
+
+// Passed returns true if the command exited successfully
+func (r Result) Passed() bool {
+	return r.Err == nil
+}
+
+// Run runs the commands one after another in dir through the shell, each
+// limited to timeout, and writes their output to out as it is produced
+func Run(ctx context.Context, dir string, commands []string, timeout time.Duration, out io.Writer) []Result {
+	results := make([]Result, 0, len(commands))
+	for _, command := range commands {
+		results = append(results, run(ctx, dir, command, timeout, out))
+	}
+	return results
+}
+
+func run(ctx context.Context, dir, command string, timeout time.Duration, out io.Writer) Result {
+	if timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, timeout)
+		defer cancel()
+	}
+
+	var cmd *exec.Cmd
+	if runtime.GOOS == "windows" {
+		cmd = exec.CommandContext(ctx, "cmd", "/C", command)
+	} else {
+		cmd = exec.CommandContext(ctx, "sh", "-c", command)
+	}
+	cmd.Dir = dir
+	// Don't wait for children of the shell still holding the output open
+	cmd.WaitDelay = time.Second
+	ai.ApplySandbox(cmd)
+
+	fmt.Fprintf(out, "$ %s\n", command)
+	var output bytes.Buffer
+	cmd.Stdout = io.MultiWriter(&output, out)
+	cmd.Stderr = cmd.Stdout
+
+	start := time.Now()
+	err := cmd.Run()
+	if ctx.Err() == context.DeadlineExceeded {
+		err = fmt.Errorf("timed out after %v", timeout)
+	}
+	result := Result{Command: command, Output: output.String(), Err: err, Duration: time.Since(start)}
+	if err != nil {
+		fmt.Fprintf(out, "FAILED: %v\n", err)
+	} else {
+		fmt.Fprintf(out, "passed in %v\n", result.Duration.Round(time.Millisecond))
+	}
+	return result
+}
+
+// Format runs a formatter whose changes become part of the task. A failing
+// formatter does not fail the task, its error is only returned for logging.
+func Format(ctx context.Context, dir, command string, timeout time.Duration, out io.Writer) error {
+	if strings.TrimSpace(command) == "" {
+		return nil
+	}
+	if failed := Failed(Run(ctx, dir, []string{command}, timeout, out)); len(failed) > 0 {
+		return fmt.Errorf("%s: %w", command, failed[0].Err)
+	}
+	return nil
+}
+
+// Gate runs the commands and, while any of them fails, calls fix with the
+// feedback of the failures and runs them again, at most fixAttempts times. It
+// returns the commands that still fail and the number of fix attempts made.
+// An error from fix ends the attempts.
+func Gate(ctx context.Context, dir string, commands []string, timeout time.Duration, out io.Writer, fixAttempts int, fix func(feedback string) error) ([]Result, int) {
+	failed := Failed(Run(ctx, dir, commands, timeout, out))
+	attempts := 0
+	for len(failed) > 0 && attempts < fixAttempts && ctx.Err() == nil {
+		attempts++
+		fmt.Fprintf(out, "\nFix attempt %d/%d\n", attempts, fixAttempts)
+		if err := fix(Feedback(failed)); err != nil {
+			fmt.Fprintf(out, "Fix attempt failed: %v\n", err)
+			break
+		}
+		failed = Failed(Run(ctx, dir, commands, timeout, out))
+	}
+	return failed, attempts
+}
+
+// Failed returns the results of the commands that failed
+func Failed(results []Result) []Result {
+	var failed []Result
+	for _, r := range results {
+		if !r.Passed() {
+			failed = append(failed, r)
+		}
+	}
+	return failed
+}
+
+// Summary describes failed commands in one line, e.g. for a task's attempt history
+func Summary(failed []Result) string {
+	parts := make([]string, len(failed))
+	for i, r := range failed {
+		parts[i] = fmt.Sprintf("%s (%v)", r.Command, r.Err)
+	}
+	return "verification failed: " + strings.Join(parts, "; ")
+}
+
+// Feedback describes failed commands and the end of their output for the
+// prompt of the next attempt
+func Feedback(failed []Result) string {
+	var sb strings.Builder
+	for _, r := range failed {
+		fmt.Fprintf(&sb, "`%s` failed: %v\n\n", r.Command, r.Err)
+		output := strings.TrimSpace(r.Output)
+		if len(output) > maxFeedbackOutput {
+			output = "..." + output[len(output)-maxFeedbackOutput:]
+		}
+		if output != "" {
+			fmt.Fprintf(&sb, "```\n%s\n```\n\n", output)
+		}
+	}
+	return strings.TrimSpace(sb.String())
+}
@@ -0,0 +1,153 @@
+package verify
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestRun(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("uses sh commands")
+	}
+	dir := t.TempDir()
+
+	var out strings.Builder
+	results := Run(context.Background(), dir, []string{
+		"echo ok > done.txt && cat done.txt",
+		"echo broken >&2; exit 3",
+		"sleep 5",
+	}, time.Second, &out)
+
+	if len(results) != 3 {
+		t.Fatalf("expected 3 results, got %d", len(results))
+	}
+	if !results[0].Passed() || strings.TrimSpace(results[0].Output) != "ok" {
+		t.Errorf("expected first command to pass in dir, got %+v", results[0])
+	}
+	if results[1].Passed() || !strings.Contains(results[1].Output, "broken") {
+		t.Errorf("expected second command to fail with its stderr, got %+v", results[1])
+	}
+	if results[2].Passed() || !strings.Contains(results[2].Err.Error(), "timed out") {
+		t.Errorf("expected third command to time out, got %+v", results[2])
+	}
+	if !strings.Contains(out.String(), "$ echo broken") {
+		t.Errorf("expected commands echoed to the log, got %q", out.String())
+	}
+
+	failed := Failed(results)
+	if len(failed) != 2 {
+		t.Fatalf("expected 2 failed commands, got %d", len(failed))
+	}
+	if s := Summary(failed); !strings.HasPrefix(s, "verification failed: echo broken") {
+		t.Errorf("unexpected summary %q", s)
+	}
+	if f := Feedback(failed); !strings.Contains(f, "`sleep 5` failed") || !strings.Contains(f, "```\nbroken\n```") {
+		t.Errorf("unexpected feedback %q", f)
+	}
+}
+
+func TestGate(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("uses sh commands")
+	}
+	dir := t.TempDir()
+
+	var out strings.Builder
+	var feedback []string
+	fix := func(f string) error {
+		feedback = append(feedback, f)
+		if len(feedback) == 2 {
+			return os.WriteFile(filepath.Join(dir, "fixed"), nil, 0644)
+		}
+		return nil
+	}
+	failed, attempts := Gate(context.Background(), dir, []string{"test -f fixed"}, time.Second, &out, 3, fix)
+	if len(failed) != 0 || attempts != 2 {
+		t.Errorf("expected gate to pass after 2 fix attempts, got %d failed after %d", len(failed), attempts)
+	}
+	if len(feedback) != 2 || !strings.Contains(feedback[0], "`test -f fixed` failed") {
+		t.Errorf("unexpected feedback %q", feedback)
+	}
+
+	failed, attempts = Gate(context.Background(), dir, []string{"exit 1"}, time.Second, &out, 1, func(string) error { return nil })
+	if len(failed) != 1 || attempts != 1 {
+		t.Errorf("expected gate to fail after 1 fix attempt, got %d failed after %d", len(failed), attempts)
+	}
+}
+
+func TestFormat(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("uses sh commands")
+	}
+	dir := t.TempDir()
+
+	var out strings.Builder
+	if err := Format(context.Background(), dir, "", time.Second, &out); err != nil || out.Len() > 0 {
+		t.Errorf("expected empty format command to be skipped, got %v", err)
+	}
+	if err := Format(context.Background(), dir, "touch formatted", time.Second, &out); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := os.Stat(filepath.Join(dir, "formatted")); err != nil {
+		t.Errorf("expected format command to run in dir: %v", err)
+	}
+	if err := Format(context.Background(), dir, "exit 2", time.Second, &out); err == nil || !strings.HasPrefix(err.Error(), "exit 2:") {
+		t.Errorf("expected format failure to be returned, got %v", err)
+	}
+}
+
+func TestParseCoverProfile(t *testing.T) {
+	profile := `mode: set
+example.com/app/api/user.go:10.2,12.3 3 1
+example.com/app/api/user.go:14.2,16.3 1 0
+example.com/app/api/user.go:14.2,16.3 1 1
+example.com/app/api/order.go:5.2,9.3 4 0
+example.com/app/db/db.go:3.2,4.3 2 1
+`
+	coverage, err := ParseCoverProfile(strings.NewReader(profile))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(coverage) != 2 {
+		t.Fatalf("expected 2 packages, got %+v", coverage)
+	}
+	if c := coverage[0]; c.Package != "example.com/app/api" || c.Statements != 8 || c.Covered != 4 || c.Percent() != 50 {
+		t.Errorf("unexpected api coverage %+v", c)
+	}
+	if c := coverage[1]; c.Package != "example.com/app/db" || c.Percent() != 100 {
+		t.Errorf("unexpected db coverage %+v", c)
+	}
+
+	if _, err := ParseCoverProfile(strings.NewReader("broken line")); err == nil {
+		t.Error("expected invalid profile to fail")
+	}
+}
+
+func TestCoverage(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("uses sh commands")
+	}
+	dir := t.TempDir()
+	command := `printf 'mode: set\nexample.com/app/api/user.go:10.2,12.3 3 1\nexample.com/app/api/user.go:14.2,16.3 1 0\n' > out/cover.out`
+
+	var out strings.Builder
+	if r := Coverage(context.Background(), dir, command, "out/cover.out", 75, time.Second, &out); r != nil {
+		t.Errorf("expected coverage of 75%% to pass, got %+v", r)
+	}
+	r := Coverage(context.Background(), dir, command, "out/cover.out", 80, time.Second, &out)
+	if r == nil || r.Passed() || !strings.Contains(r.Err.Error(), "example.com/app/api is below 80.0%") {
+		t.Fatalf("expected coverage of 75%% to fail a threshold of 80%%, got %+v", r)
+	}
+	if !strings.Contains(r.Output, "example.com/app/api: 75.0% of statements covered") {
+		t.Errorf("unexpected output %q", r.Output)
+	}
+
+	if r := Coverage(context.Background(), dir, "true", "out/cover.out", 80, time.Second, &out); r == nil || !strings.Contains(r.Err.Error(), "no cover profile") {
+		t.Errorf("expected missing profile to fail, got %+v", r)
+	}
+}
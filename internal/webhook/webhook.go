@@ -0,0 +1,191 @@
+package webhook
+
+import (
+	"bytes"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	"hermes/internal/config"
+)
+
+// Event types
+const (
+	RunStarted       = "run.started"
+	RunFinished      = "run.finished"
+	TaskStarted      = "task.started"
+	TaskCompleted    = "task.completed"
+	TaskFailed       = "task.failed"
+	TaskBlocked      = "task.blocked"
+	FeatureCompleted = "feature.completed"
+	BreakerChanged   = "breaker.changed"
+	BreakerOpened    = "breaker.opened"
+)
+
+// Event is the JSON payload posted to webhooks
+type Event struct {
+	Type  string         `json:"event"`
+	Time  time.Time      `json:"time"`
+	RunID string         `json:"runId,omitempty"`
+	Data  map[string]any `json:"data,omitempty"`
+}
+
+// Dispatcher posts events to the configured webhooks in the background
+type Dispatcher struct {
+	hooks      []config.WebhookConfig
+	httpClient *http.Client
+	onError    func(url string, err error)
+	wg         sync.WaitGroup
+	mu         sync.Mutex
+	runID      string // Run events without a run ID are attributed to
+}
+
+// NewDispatcher creates a dispatcher for the given webhooks
+func NewDispatcher(hooks []config.WebhookConfig) *Dispatcher {
+	return &Dispatcher{
+		hooks:      hooks,
+		httpClient: &http.Client{Timeout: 10 * time.Second},
+	}
+}
+
+// SetErrorHandler sets the function called when a delivery fails
+func (d *Dispatcher) SetErrorHandler(fn func(url string, err error)) {
+	d.onError = fn
+}
+
+// Send posts an event to every webhook subscribed to its type without
+// blocking the caller
+func (d *Dispatcher) Send(e Event) {
+	if e.Time.IsZero() {
+		e.Time = time.Now()
+	}
+	d.mu.Lock()
+	if e.Type == RunStarted {
+		d.runID = e.RunID
+	}
+	if e.RunID == "" {
+		e.RunID = d.runID
+	}
+	d.mu.Unlock()
+
+	payload, err := json.Marshal(e)
+	if err != nil {
+		return
+	}
+	for _, hook := range d.hooks {
+		if !Subscribed(hook.Events, e.Type) {
+			continue
+		}
+		d.wg.Add(1)
+		go func(hook config.WebhookConfig) {
+			defer d.wg.Done()
+			if err := d.post(hook, e.Type, payload); err != nil && d.onError != nil {
+				d.onError(hook.URL, err)
+			}
+		}(hook)
+	}
+}
+
+// Wait blocks until pending deliveries finish or the timeout passes
+func (d *Dispatcher) Wait(timeout time.Duration) {
+	done := make(chan struct{})
+	go func() {
+		d.wg.Wait()
+		close(done)
+	}()
+	select {
+	case <-done:
+	case <-time.After(timeout):
+	}
+}
+
+func (d *Dispatcher) post(hook config.WebhookConfig, eventType string, payload []byte) error {
+	req, err := http.NewRequest("POST", hook.URL, bytes.NewReader(payload))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("User-Agent", "Hermes-Webhook")
+	req.Header.Set("X-Hermes-Event", eventType)
+	if hook.Secret != "" {
+		req.Header.Set("X-Hermes-Signature", "sha256="+Sign(hook.Secret, payload))
+	}
+	for k, v := range hook.Headers {
+		req.Header.Set(k, v)
+	}
+
+	resp, err := d.httpClient.Do(req)
+	if err != nil {
+		return err
+	}
+	resp.Body.Close()
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return fmt.Errorf("webhook returned %s", resp.Status)
+	}
+	return nil
+}
+
+// Sign returns the hex HMAC-SHA256 of a payload, sent in X-Hermes-Signature
+// so receivers can verify deliveries
+func Sign(secret string, payload []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write(payload)
+	return hex.EncodeToString(mac.Sum(nil))
+}
+
+// Subscribed reports whether a webhook listening to events receives eventType.
+// An empty list receives everything; "task.*" receives all task events.
+func Subscribed(events []string, eventType string) bool {
+	if len(events) == 0 {
+		return true
+	}
+	for _, e := range events {
+		if e == eventType || e == "*" {
+			return true
+		}
+		if prefix, ok := strings.CutSuffix(e, "*"); ok && strings.HasPrefix(eventType, prefix) {
+			return true
+		}
+	}
+	return false
+}
+
+var (
+	defaultMu         sync.Mutex
+	defaultDispatcher *Dispatcher
+)
+
+// Configure sets the dispatcher used by Emit
+func Configure(d *Dispatcher) {
+	defaultMu.Lock()
+	defer defaultMu.Unlock()
+	defaultDispatcher = d
+}
+
+// Emit sends an event through the configured dispatcher. Without webhooks
+// configured it does nothing.
+func Emit(eventType, runID string, data map[string]any) {
+	defaultMu.Lock()
+	d := defaultDispatcher
+	defaultMu.Unlock()
+	if d != nil {
+		d.Send(Event{Type: eventType, RunID: runID, Data: data})
+	}
+}
+
+// Flush waits for pending deliveries of the configured dispatcher, e.g.
+// before the process exits
+func Flush(timeout time.Duration) {
+	defaultMu.Lock()
+	d := defaultDispatcher
+	defaultMu.Unlock()
+	if d != nil {
+		d.Wait(timeout)
+	}
+}
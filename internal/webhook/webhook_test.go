@@ -0,0 +1,106 @@
+package webhook
+
+import (
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	"hermes/internal/config"
+)
+
+func TestSubscribed(t *testing.T) {
+	tests := []struct {
+		events    []string
+		eventType string
+		want      bool
+	}{
+		{nil, TaskFailed, true},
+		{[]string{"*"}, RunStarted, true},
+		{[]string{RunFinished}, RunFinished, true},
+		{[]string{RunFinished}, RunStarted, false},
+		{[]string{"task.*"}, TaskBlocked, true},
+		{[]string{"task.*"}, FeatureCompleted, false},
+	}
+	for _, tt := range tests {
+		if got := Subscribed(tt.events, tt.eventType); got != tt.want {
+			t.Errorf("Subscribed(%v, %q) = %v, want %v", tt.events, tt.eventType, got, tt.want)
+		}
+	}
+}
+
+func TestDispatcherSend(t *testing.T) {
+	var mu sync.Mutex
+	var received []Event
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		if r.Header.Get("X-Hermes-Signature") != "sha256="+Sign("secret", body) {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		if r.Header.Get("X-Team") != "core" {
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+		var e Event
+		json.Unmarshal(body, &e)
+		if r.Header.Get("X-Hermes-Event") != e.Type {
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+		mu.Lock()
+		received = append(received, e)
+		mu.Unlock()
+	}))
+	defer server.Close()
+
+	d := NewDispatcher([]config.WebhookConfig{{
+		URL:     server.URL,
+		Events:  []string{RunStarted, "task.*"},
+		Secret:  "secret",
+		Headers: map[string]string{"X-Team": "core"},
+	}})
+	var failures int
+	d.SetErrorHandler(func(url string, err error) { failures++ })
+
+	d.Send(Event{Type: RunStarted, RunID: "20260101-120000"})
+	d.Wait(5 * time.Second)
+	d.Send(Event{Type: TaskCompleted, Data: map[string]any{"taskId": "T001"}})
+	d.Send(Event{Type: BreakerChanged})
+	d.Wait(5 * time.Second)
+
+	if failures != 0 {
+		t.Fatalf("expected no failed deliveries, got %d", failures)
+	}
+	if len(received) != 2 {
+		t.Fatalf("expected 2 events, got %d", len(received))
+	}
+	completed := received[1]
+	if completed.Type != TaskCompleted || completed.Data["taskId"] != "T001" {
+		t.Errorf("unexpected event %+v", completed)
+	}
+	// Events without a run ID belong to the current run
+	if completed.RunID != "20260101-120000" {
+		t.Errorf("expected the run ID of run.started, got %q", completed.RunID)
+	}
+}
+
+func TestDispatcherError(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusInternalServerError)
+	}))
+	defer server.Close()
+
+	d := NewDispatcher([]config.WebhookConfig{{URL: server.URL}})
+	var failed string
+	d.SetErrorHandler(func(url string, err error) { failed = url })
+	d.Send(Event{Type: RunFinished})
+	d.Wait(5 * time.Second)
+
+	if failed != server.URL {
+		t.Errorf("expected a failed delivery to %s, got %q", server.URL, failed)
+	}
+}